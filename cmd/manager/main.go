@@ -14,6 +14,7 @@ import (
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/simulator"
 	"k8s.io/cloud-provider-alibaba-cloud/version"
 	"k8s.io/klog/v2/klogr"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
@@ -24,6 +25,7 @@ import (
 	"k8s.io/cloud-provider-alibaba-cloud/cmd/health"
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/webhook"
 )
 
 var log = klogr.New()
@@ -67,7 +69,14 @@ func main() {
 	}
 
 	var cloud prvd.Provider
-	if ctrlCfg.ControllerCFG.DryRun {
+	if ctrlCfg.ControllerCFG.Simulator {
+		log.Info("using cloud simulator", "stateFile", ctrlCfg.ControllerCFG.SimulatorStateFile)
+		cloud, err = simulator.NewSimulatorCloud(ctrlCfg.ControllerCFG.SimulatorStateFile)
+		if err != nil {
+			log.Error(err, "fail to create cloud simulator")
+			os.Exit(1)
+		}
+	} else if ctrlCfg.ControllerCFG.DryRun {
 		log.Info("using DryRun Mode")
 		cloud = dryrun.NewDryRunCloud()
 	} else {
@@ -83,6 +92,11 @@ func main() {
 		log.Info(fmt.Sprintf("Loaded controllers: %v", ctrlCfg.ControllerCFG.Controllers))
 	}
 
+	if ctrlCfg.ControllerCFG.RuntimeConfig.WebhookBindPort != 0 {
+		log.Info("Registering Webhooks.")
+		webhook.AddToManager(mgr)
+	}
+
 	// Start the Cmd
 	log.Info("Starting the Cmd.")
 	if err := mgr.AddHealthzCheck("default", func(req *http.Request) error {
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/plan"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/klogr"
+)
+
+// plan renders the changes the controllers would make to the load balancers of the
+// Services, Ingresses and AlbConfigs in the manifests, based on a snapshot of the cloud resources.
+// It never calls the OpenAPIs, so it can run in CI without credentials.
+func main() {
+	var (
+		manifests    []string
+		snapshotPath string
+		output       string
+		featureGates string
+	)
+	klog.InitFlags(nil)
+	fs := pflag.NewFlagSet("plan", pflag.ExitOnError)
+	fs.AddGoFlagSet(flag.CommandLine)
+	fs.StringSliceVar(&manifests, "manifests", nil, "The yaml or json files, or directories of them, of the Services, Ingresses, AlbConfigs and their backends.")
+	fs.StringVar(&snapshotPath, "snapshot", "", "The json file of the remote state of the cloud resources.")
+	fs.StringVarP(&output, "output", "o", "text", "The output format, text or json.")
+	fs.StringVar(&featureGates, "feature-gates", "", "A set of key=value pairs that describe feature gates of the controllers.")
+	fs.IntVar(&ctrlCfg.ControllerCFG.ServerGroupBatchSize, "sg-batch-size", 40, "The batch size for syncing server group. The value range is 1-40")
+	fs.IntVar(&ctrlCfg.ControllerCFG.MaxConcurrentActions, "max-concurrent-actions", 10, "The max concurrent number of actions for listener and server group updates")
+	_ = fs.Parse(os.Args[1:])
+
+	if err := run(manifests, snapshotPath, output, featureGates); err != nil {
+		fmt.Fprintf(os.Stderr, "plan: %s\n", err.Error())
+		os.Exit(2)
+	}
+}
+
+func run(manifests []string, snapshotPath, output, featureGates string) error {
+	if len(manifests) == 0 || snapshotPath == "" {
+		return fmt.Errorf("--manifests and --snapshot are required")
+	}
+	if output != "text" && output != "json" {
+		return fmt.Errorf("unknown output format %s", output)
+	}
+	if err := utilfeature.DefaultMutableFeatureGate.Set(featureGates); err != nil {
+		return fmt.Errorf("parse feature gates: %s", err.Error())
+	}
+
+	snap, err := snapshot.LoadSnapshot(snapshotPath)
+	if err != nil {
+		return err
+	}
+
+	scheme, err := plan.NewScheme()
+	if err != nil {
+		return err
+	}
+	objs, err := plan.LoadManifests(scheme, manifests)
+	if err != nil {
+		return err
+	}
+
+	results, err := plan.NewPlanner(scheme, objs, snap, klogr.New()).Plan(context.Background())
+	if err != nil {
+		return err
+	}
+	if output == "json" {
+		if err := plan.PrintJSON(os.Stdout, results); err != nil {
+			return err
+		}
+	} else {
+		plan.PrintText(os.Stdout, results)
+	}
+	if plan.HasErrors(results) {
+		os.Exit(1)
+	}
+	return nil
+}
@@ -65,6 +65,57 @@ type IngressStatus struct {
 	// LoadBalancer contains the current status of the load-balancer.
 	// +optional
 	LoadBalancer LoadBalancerStatus `json:"loadBalancer,omitempty" protobuf:"bytes,1,opt,name=loadBalancer"`
+
+	// ObservedGeneration is the generation of the AlbConfig the status was computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" protobuf:"varint,2,opt,name=observedGeneration"`
+
+	// Conditions describe the reconcile state, see the AlbConfigCondition* types.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,3,rep,name=conditions"`
+
+	// Listeners contains the current status of the listeners.
+	// +optional
+	Listeners []ListenerStatus `json:"listeners,omitempty" protobuf:"bytes,4,rep,name=listeners"`
+
+	// Ingresses are the Ingresses currently bound to the AlbConfig.
+	// +optional
+	Ingresses []IngressReference `json:"ingresses,omitempty" protobuf:"bytes,5,rep,name=ingresses"`
+}
+
+const (
+	// AlbConfigConditionReady is true when the last reconcile of the AlbConfig succeeded.
+	AlbConfigConditionReady = "Ready"
+	// AlbConfigConditionLoadBalancerProvisioned is true when the load balancer exists.
+	AlbConfigConditionLoadBalancerProvisioned = "LoadBalancerProvisioned"
+	// AlbConfigConditionListenersSynced is true when all the listeners and rules are applied.
+	AlbConfigConditionListenersSynced = "ListenersSynced"
+	// AlbConfigConditionCertificatesResolved is true when every https listener has its certificates.
+	AlbConfigConditionCertificatesResolved = "CertificatesResolved"
+
+	// AlbConfigReasonReconciled is the reason of the conditions when the reconcile succeeded.
+	AlbConfigReasonReconciled = "Reconciled"
+	// AlbConfigReasonBuildFailed is the reason when the model of the AlbConfig can't be built.
+	AlbConfigReasonBuildFailed = "BuildFailed"
+	// AlbConfigReasonApplyFailed is the reason when the model can't be applied to ALB.
+	AlbConfigReasonApplyFailed = "ApplyFailed"
+	// AlbConfigReasonCertificateNotFound is the reason when a https listener has no certificate.
+	AlbConfigReasonCertificateNotFound = "CertificateNotFound"
+)
+
+// ListenerStatus represents the status of a listener.
+type ListenerStatus struct {
+	Id       string `json:"id,omitempty" protobuf:"bytes,1,opt,name=id"`
+	Port     int32  `json:"port" protobuf:"varint,2,opt,name=port"`
+	Protocol string `json:"protocol" protobuf:"bytes,3,opt,name=protocol"`
+	// Status is the listener status reported by ALB, empty until the listener is applied.
+	Status string `json:"status,omitempty" protobuf:"bytes,4,opt,name=status"`
+}
+
+// IngressReference refers to an Ingress bound to the AlbConfig.
+type IngressReference struct {
+	Namespace string `json:"namespace" protobuf:"bytes,1,opt,name=namespace"`
+	Name      string `json:"name" protobuf:"bytes,2,opt,name=name"`
 }
 
 // LoadBalancer is a nested struct in alb response
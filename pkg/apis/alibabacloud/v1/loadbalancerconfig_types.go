@@ -0,0 +1,201 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	SchemeBuilder.Register(&LoadBalancerConfig{}, &LoadBalancerConfigList{})
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=lbconfig
+
+// LoadBalancerConfig holds the CLB and NLB settings of the Services referring to it by the
+// service.beta.kubernetes.io/alibaba-cloud-loadbalancer-config annotation. Each field has the
+// same meaning as the annotation of the same name, and the annotations of the Service take precedence.
+type LoadBalancerConfig struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Spec is the load balancer settings of the Services.
+	// +optional
+	Spec LoadBalancerConfigSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LoadBalancerConfigList is a collection of LoadBalancerConfig.
+type LoadBalancerConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Items is the list of LoadBalancerConfig.
+	Items []LoadBalancerConfig `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// LoadBalancerConfigSpec describes the load balancer and the listeners of the Services.
+type LoadBalancerConfigSpec struct {
+	// LoadBalancer is the attributes of the load balancer instance.
+	// +optional
+	LoadBalancer *LoadBalancerAttributes `json:"loadBalancer,omitempty" protobuf:"bytes,1,opt,name=loadBalancer"`
+	// Listener is the attributes of all the listeners.
+	// +optional
+	Listener *ListenerAttributes `json:"listener,omitempty" protobuf:"bytes,2,opt,name=listener"`
+	// Ports overrides the attributes of the listeners of the given service ports.
+	// +optional
+	Ports []PortListenerAttributes `json:"ports,omitempty" protobuf:"bytes,3,rep,name=ports"`
+}
+
+// LoadBalancerAttributes describes the load balancer instance.
+type LoadBalancerAttributes struct {
+	// +kubebuilder:validation:Enum=internet;intranet
+	AddressType     string            `json:"addressType,omitempty" protobuf:"bytes,1,opt,name=addressType"`
+	ResourceGroupId string            `json:"resourceGroupId,omitempty" protobuf:"bytes,2,opt,name=resourceGroupId"`
+	AdditionalTags  map[string]string `json:"additionalTags,omitempty" protobuf:"bytes,3,rep,name=additionalTags"`
+	// DeletionProtection is on unless it is disabled explicitly.
+	DeletionProtection *bool `json:"deletionProtection,omitempty" protobuf:"varint,4,opt,name=deletionProtection"`
+	// +kubebuilder:validation:Enum=ConsoleProtection;NonProtection
+	ModificationProtection string `json:"modificationProtection,omitempty" protobuf:"bytes,5,opt,name=modificationProtection"`
+
+	// CLB only.
+	Spec string `json:"spec,omitempty" protobuf:"bytes,6,opt,name=spec"`
+	// +kubebuilder:validation:Enum=PayBySpec;PayByCLCU
+	InstanceChargeType string `json:"instanceChargeType,omitempty" protobuf:"bytes,7,opt,name=instanceChargeType"`
+	// +kubebuilder:validation:Enum=paybytraffic;paybybandwidth
+	InternetChargeType string `json:"internetChargeType,omitempty" protobuf:"bytes,8,opt,name=internetChargeType"`
+	// +kubebuilder:validation:Minimum=1
+	Bandwidth *int32 `json:"bandwidth,omitempty" protobuf:"varint,9,opt,name=bandwidth"`
+	// +kubebuilder:validation:Enum=classic;vpc
+	NetworkType  string `json:"networkType,omitempty" protobuf:"bytes,10,opt,name=networkType"`
+	VSwitchId    string `json:"vSwitchId,omitempty" protobuf:"bytes,11,opt,name=vSwitchId"`
+	MasterZoneId string `json:"masterZoneId,omitempty" protobuf:"bytes,12,opt,name=masterZoneId"`
+	SlaveZoneId  string `json:"slaveZoneId,omitempty" protobuf:"bytes,13,opt,name=slaveZoneId"`
+	// +kubebuilder:validation:Enum=ipv4;ipv6
+	IPVersion string `json:"ipVersion,omitempty" protobuf:"bytes,14,opt,name=ipVersion"`
+
+	// NLB only.
+	ZoneMappings       []LoadBalancerZoneMapping `json:"zoneMappings,omitempty" protobuf:"bytes,15,rep,name=zoneMappings"`
+	SecurityGroupIds   []string                  `json:"securityGroupIds,omitempty" protobuf:"bytes,16,rep,name=securityGroupIds"`
+	BandwidthPackageId *string                   `json:"bandwidthPackageId,omitempty" protobuf:"bytes,17,opt,name=bandwidthPackageId"`
+	// +kubebuilder:validation:Enum=internet;intranet
+	IPv6AddressType string `json:"ipv6AddressType,omitempty" protobuf:"bytes,18,opt,name=ipv6AddressType"`
+}
+
+// LoadBalancerZoneMapping is a zone of the NLB instance.
+type LoadBalancerZoneMapping struct {
+	ZoneId       string `json:"zoneId" protobuf:"bytes,1,opt,name=zoneId"`
+	VSwitchId    string `json:"vSwitchId" protobuf:"bytes,2,opt,name=vSwitchId"`
+	IPv4Address  string `json:"ipv4Address,omitempty" protobuf:"bytes,3,opt,name=ipv4Address"`
+	AllocationId string `json:"allocationId,omitempty" protobuf:"bytes,4,opt,name=allocationId"`
+}
+
+// ListenerAttributes describes the listeners, as well as the server groups of NLB.
+type ListenerAttributes struct {
+	Scheduler string `json:"scheduler,omitempty" protobuf:"bytes,1,opt,name=scheduler"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3600
+	PersistenceTimeout *int32 `json:"persistenceTimeout,omitempty" protobuf:"varint,2,opt,name=persistenceTimeout"`
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=900
+	EstablishedTimeout *int32 `json:"establishedTimeout,omitempty" protobuf:"varint,3,opt,name=establishedTimeout"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=900
+	IdleTimeout *int32 `json:"idleTimeout,omitempty" protobuf:"varint,4,opt,name=idleTimeout"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=180
+	RequestTimeout  *int32 `json:"requestTimeout,omitempty" protobuf:"varint,5,opt,name=requestTimeout"`
+	CertId          string `json:"certId,omitempty" protobuf:"bytes,6,opt,name=certId"`
+	CaCertId        string `json:"caCertId,omitempty" protobuf:"bytes,7,opt,name=caCertId"`
+	CaEnabled       *bool  `json:"caEnabled,omitempty" protobuf:"varint,8,opt,name=caEnabled"`
+	TLSCipherPolicy string `json:"tlsCipherPolicy,omitempty" protobuf:"bytes,9,opt,name=tlsCipherPolicy"`
+	HTTP2Enabled    *bool  `json:"http2Enabled,omitempty" protobuf:"varint,10,opt,name=http2Enabled"`
+	ProxyProtocol   *bool  `json:"proxyProtocol,omitempty" protobuf:"varint,11,opt,name=proxyProtocol"`
+	// +kubebuilder:validation:Minimum=0
+	Cps *int32 `json:"cps,omitempty" protobuf:"varint,12,opt,name=cps"`
+
+	Acl             *AclAttributes             `json:"acl,omitempty" protobuf:"bytes,13,opt,name=acl"`
+	ConnectionDrain *ConnectionDrainAttributes `json:"connectionDrain,omitempty" protobuf:"bytes,14,opt,name=connectionDrain"`
+	StickySession   *StickySessionAttributes   `json:"stickySession,omitempty" protobuf:"bytes,15,opt,name=stickySession"`
+	XForwardedFor   *XForwardedForAttributes   `json:"xForwardedFor,omitempty" protobuf:"bytes,16,opt,name=xForwardedFor"`
+	HealthCheck     *HealthCheckAttributes     `json:"healthCheck,omitempty" protobuf:"bytes,17,opt,name=healthCheck"`
+}
+
+// PortListenerAttributes overrides the attributes of the listener of a service port.
+type PortListenerAttributes struct {
+	// Port is the port of the Service.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port               int32 `json:"port" protobuf:"varint,1,opt,name=port"`
+	ListenerAttributes `json:",inline" protobuf:"bytes,2,opt,name=listenerAttributes"`
+}
+
+// AclAttributes describes the access control of the listeners.
+type AclAttributes struct {
+	Enabled *bool  `json:"enabled,omitempty" protobuf:"varint,1,opt,name=enabled"`
+	Id      string `json:"id,omitempty" protobuf:"bytes,2,opt,name=id"`
+	// +kubebuilder:validation:Enum=white;black
+	Type string `json:"type,omitempty" protobuf:"bytes,3,opt,name=type"`
+}
+
+// ConnectionDrainAttributes describes the connection draining of the listeners.
+type ConnectionDrainAttributes struct {
+	Enabled *bool `json:"enabled,omitempty" protobuf:"varint,1,opt,name=enabled"`
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=900
+	Timeout *int32 `json:"timeout,omitempty" protobuf:"varint,2,opt,name=timeout"`
+}
+
+// StickySessionAttributes describes the session persistence of the HTTP and HTTPS listeners.
+type StickySessionAttributes struct {
+	Enabled *bool `json:"enabled,omitempty" protobuf:"varint,1,opt,name=enabled"`
+	// +kubebuilder:validation:Enum=insert;server
+	Type   string `json:"type,omitempty" protobuf:"bytes,2,opt,name=type"`
+	Cookie string `json:"cookie,omitempty" protobuf:"bytes,3,opt,name=cookie"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=86400
+	CookieTimeout *int32 `json:"cookieTimeout,omitempty" protobuf:"varint,4,opt,name=cookieTimeout"`
+}
+
+// XForwardedForAttributes describes the headers added by the HTTP and HTTPS listeners.
+type XForwardedForAttributes struct {
+	Proto         *bool `json:"proto,omitempty" protobuf:"varint,1,opt,name=proto"`
+	SLBPort       *bool `json:"slbPort,omitempty" protobuf:"varint,2,opt,name=slbPort"`
+	ClientSrcPort *bool `json:"clientSrcPort,omitempty" protobuf:"varint,3,opt,name=clientSrcPort"`
+}
+
+// HealthCheckAttributes describes the health check of the backends.
+type HealthCheckAttributes struct {
+	// Enabled sets both the health-check-flag and the health-check-switch annotations.
+	Enabled   *bool    `json:"enabled,omitempty" protobuf:"varint,1,opt,name=enabled"`
+	Type      string   `json:"type,omitempty" protobuf:"bytes,2,opt,name=type"`
+	URI       string   `json:"uri,omitempty" protobuf:"bytes,3,opt,name=uri"`
+	Domain    string   `json:"domain,omitempty" protobuf:"bytes,4,opt,name=domain"`
+	HTTPCodes []string `json:"httpCodes,omitempty" protobuf:"bytes,5,rep,name=httpCodes"`
+	Method    string   `json:"method,omitempty" protobuf:"bytes,6,opt,name=method"`
+	// +kubebuilder:validation:Minimum=-520
+	// +kubebuilder:validation:Maximum=65535
+	ConnectPort *int32 `json:"connectPort,omitempty" protobuf:"varint,7,opt,name=connectPort"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=300
+	ConnectTimeout *int32 `json:"connectTimeout,omitempty" protobuf:"varint,8,opt,name=connectTimeout"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=300
+	Timeout *int32 `json:"timeout,omitempty" protobuf:"varint,9,opt,name=timeout"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=50
+	Interval *int32 `json:"interval,omitempty" protobuf:"varint,10,opt,name=interval"`
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=10
+	HealthyThreshold *int32 `json:"healthyThreshold,omitempty" protobuf:"varint,11,opt,name=healthyThreshold"`
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=10
+	UnhealthyThreshold *int32 `json:"unhealthyThreshold,omitempty" protobuf:"varint,12,opt,name=unhealthyThreshold"`
+}
@@ -6,6 +6,7 @@
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -106,6 +107,23 @@ func (in *AlbConfigSpec) DeepCopy() *AlbConfigSpec {
 func (in *IngressStatus) DeepCopyInto(out *IngressStatus) {
 	*out = *in
 	in.LoadBalancer.DeepCopyInto(&out.LoadBalancer)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = make([]ListenerStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ingresses != nil {
+		in, out := &in.Ingresses, &out.Ingresses
+		*out = make([]IngressReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -123,3 +141,391 @@ func (in *IngressStatus) DeepCopy() *IngressStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AclAttributes) DeepCopyInto(out *AclAttributes) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AclAttributes.
+func (in *AclAttributes) DeepCopy() *AclAttributes {
+	if in == nil {
+		return nil
+	}
+	out := new(AclAttributes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionDrainAttributes) DeepCopyInto(out *ConnectionDrainAttributes) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionDrainAttributes.
+func (in *ConnectionDrainAttributes) DeepCopy() *ConnectionDrainAttributes {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionDrainAttributes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheckAttributes) DeepCopyInto(out *HealthCheckAttributes) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HTTPCodes != nil {
+		in, out := &in.HTTPCodes, &out.HTTPCodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConnectPort != nil {
+		in, out := &in.ConnectPort, &out.ConnectPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ConnectTimeout != nil {
+		in, out := &in.ConnectTimeout, &out.ConnectTimeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HealthyThreshold != nil {
+		in, out := &in.HealthyThreshold, &out.HealthyThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UnhealthyThreshold != nil {
+		in, out := &in.UnhealthyThreshold, &out.UnhealthyThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckAttributes.
+func (in *HealthCheckAttributes) DeepCopy() *HealthCheckAttributes {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthCheckAttributes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerAttributes) DeepCopyInto(out *ListenerAttributes) {
+	*out = *in
+	if in.PersistenceTimeout != nil {
+		in, out := &in.PersistenceTimeout, &out.PersistenceTimeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EstablishedTimeout != nil {
+		in, out := &in.EstablishedTimeout, &out.EstablishedTimeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IdleTimeout != nil {
+		in, out := &in.IdleTimeout, &out.IdleTimeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RequestTimeout != nil {
+		in, out := &in.RequestTimeout, &out.RequestTimeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CaEnabled != nil {
+		in, out := &in.CaEnabled, &out.CaEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HTTP2Enabled != nil {
+		in, out := &in.HTTP2Enabled, &out.HTTP2Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProxyProtocol != nil {
+		in, out := &in.ProxyProtocol, &out.ProxyProtocol
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Cps != nil {
+		in, out := &in.Cps, &out.Cps
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Acl != nil {
+		in, out := &in.Acl, &out.Acl
+		*out = new(AclAttributes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionDrain != nil {
+		in, out := &in.ConnectionDrain, &out.ConnectionDrain
+		*out = new(ConnectionDrainAttributes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StickySession != nil {
+		in, out := &in.StickySession, &out.StickySession
+		*out = new(StickySessionAttributes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.XForwardedFor != nil {
+		in, out := &in.XForwardedFor, &out.XForwardedFor
+		*out = new(XForwardedForAttributes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(HealthCheckAttributes)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerAttributes.
+func (in *ListenerAttributes) DeepCopy() *ListenerAttributes {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerAttributes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerAttributes) DeepCopyInto(out *LoadBalancerAttributes) {
+	*out = *in
+	if in.AdditionalTags != nil {
+		in, out := &in.AdditionalTags, &out.AdditionalTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DeletionProtection != nil {
+		in, out := &in.DeletionProtection, &out.DeletionProtection
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ZoneMappings != nil {
+		in, out := &in.ZoneMappings, &out.ZoneMappings
+		*out = make([]LoadBalancerZoneMapping, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityGroupIds != nil {
+		in, out := &in.SecurityGroupIds, &out.SecurityGroupIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BandwidthPackageId != nil {
+		in, out := &in.BandwidthPackageId, &out.BandwidthPackageId
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerAttributes.
+func (in *LoadBalancerAttributes) DeepCopy() *LoadBalancerAttributes {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerAttributes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerConfig) DeepCopyInto(out *LoadBalancerConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerConfig.
+func (in *LoadBalancerConfig) DeepCopy() *LoadBalancerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerConfigList) DeepCopyInto(out *LoadBalancerConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LoadBalancerConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerConfigList.
+func (in *LoadBalancerConfigList) DeepCopy() *LoadBalancerConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerConfigSpec) DeepCopyInto(out *LoadBalancerConfigSpec) {
+	*out = *in
+	if in.LoadBalancer != nil {
+		in, out := &in.LoadBalancer, &out.LoadBalancer
+		*out = new(LoadBalancerAttributes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Listener != nil {
+		in, out := &in.Listener, &out.Listener
+		*out = new(ListenerAttributes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]PortListenerAttributes, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerConfigSpec.
+func (in *LoadBalancerConfigSpec) DeepCopy() *LoadBalancerConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortListenerAttributes) DeepCopyInto(out *PortListenerAttributes) {
+	*out = *in
+	in.ListenerAttributes.DeepCopyInto(&out.ListenerAttributes)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortListenerAttributes.
+func (in *PortListenerAttributes) DeepCopy() *PortListenerAttributes {
+	if in == nil {
+		return nil
+	}
+	out := new(PortListenerAttributes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StickySessionAttributes) DeepCopyInto(out *StickySessionAttributes) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CookieTimeout != nil {
+		in, out := &in.CookieTimeout, &out.CookieTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StickySessionAttributes.
+func (in *StickySessionAttributes) DeepCopy() *StickySessionAttributes {
+	if in == nil {
+		return nil
+	}
+	out := new(StickySessionAttributes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XForwardedForAttributes) DeepCopyInto(out *XForwardedForAttributes) {
+	*out = *in
+	if in.Proto != nil {
+		in, out := &in.Proto, &out.Proto
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SLBPort != nil {
+		in, out := &in.SLBPort, &out.SLBPort
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ClientSrcPort != nil {
+		in, out := &in.ClientSrcPort, &out.ClientSrcPort
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XForwardedForAttributes.
+func (in *XForwardedForAttributes) DeepCopy() *XForwardedForAttributes {
+	if in == nil {
+		return nil
+	}
+	out := new(XForwardedForAttributes)
+	in.DeepCopyInto(out)
+	return out
+}
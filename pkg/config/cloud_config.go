@@ -2,9 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/yaml"
@@ -14,6 +16,9 @@ const (
 	DefaultServiceMaxConcurrentReconciles = 3
 	DefaultNodeMaxConcurrentReconciles    = 1
 	DefaultRouteMaxConcurrentReconciles   = 1
+	DefaultOpenAPIQPS                     = 20
+	DefaultOpenAPIBurst                   = 40
+	DefaultRouteEntryQuota                = 200
 )
 
 var CloudCFG = &CloudConfig{}
@@ -43,17 +48,134 @@ type CloudConfig struct {
 		NodeMaxConcurrentReconciles int   `json:"nodeMaxConcurrentReconciles"`
 		NodeMonitorPeriod           int64 `json:"nodeMonitorPeriod"`
 		NodeAddrSyncPeriod          int64 `json:"nodeAddrSyncPeriod"`
+		// NodeLabelMappings projects the ecs tags and the instance attributes into the labels or
+		// the annotations of the nodes
+		NodeLabelMappings []NodeLabelMapping `json:"nodeLabelMappings"`
 
 		// route controller
 		RouteMaxConcurrentReconciles int    `json:"routeMaxConcurrentReconciles"`
 		RouteTableIDS                string `json:"routeTableIDs"`
+		// RouteTableMode is "vswitch" to write the routes of a node only into the route table its vswitch
+		// is associated with, by default the routes are written into all the route tables
+		RouteTableMode string `json:"routeTableMode"`
+		// RouteEntryQuota is the quota of the custom route entries of each route table
+		RouteEntryQuota int `json:"routeEntryQuota"`
+		// RouteAggregations summarizes the routes of the contiguous pod cidrs in the blocks into larger prefixes
+		RouteAggregations []RouteAggregation `json:"routeAggregations"`
 
 		// pvtz controller
 		PrivateZoneID        string `json:"privateZoneId"`
 		PrivateZoneRecordTTL int64  `json:"privateZoneRecordTTL"`
 
 		FeatureGates string `json:"featureGates"`
+
+		// openapi rate limit, applied to each action of each product. A negative qps disables it.
+		OpenAPIQPS   float64 `json:"openAPIQPS"`
+		OpenAPIBurst int     `json:"openAPIBurst"`
+		// OpenAPIRateLimits overrides the qps of a product or an action, keyed by "slb" or "slb.DescribeLoadBalancers".
+		// A qps of 0 is ignored.
+		OpenAPIRateLimits map[string]float64 `json:"openAPIRateLimits"`
+	}
+}
+
+// The modes of assigning the routes of the nodes to the route tables
+const (
+	RouteTableModeAll     = "all"
+	RouteTableModeVSwitch = "vswitch"
+)
+
+// The instance attributes a NodeLabelMapping projects
+const (
+	NodeAttributeInstanceTypeFamily = "instanceTypeFamily"
+	NodeAttributeCPU                = "cpu"
+	NodeAttributeMemory             = "memory"
+	NodeAttributeGPUAmount          = "gpuAmount"
+	NodeAttributeGPUSpec            = "gpuSpec"
+	NodeAttributeVSwitchID          = "vswitchId"
+	NodeAttributeSecurityGroupIDs   = "securityGroupIds"
+)
+
+// NodeLabelMapping sets the value of an ecs tag or an instance attribute as a label or an annotation
+// of the node, which is removed when the value disappears. One of Tag and Attribute is set, and one
+// of Label and Annotation.
+type NodeLabelMapping struct {
+	// Tag is the key of the ecs tag
+	Tag string `json:"tag"`
+	// Attribute is one of instanceTypeFamily, cpu, memory, gpuAmount, gpuSpec, vswitchId and securityGroupIds
+	Attribute  string `json:"attribute"`
+	Label      string `json:"label"`
+	Annotation string `json:"annotation"`
+}
+
+// Key returns the key of the label or the annotation.
+func (m NodeLabelMapping) Key() string {
+	if m.Label != "" {
+		return m.Label
+	}
+	return m.Annotation
+}
+
+func (m NodeLabelMapping) validate() error {
+	if (m.Tag == "") == (m.Attribute == "") {
+		return fmt.Errorf("one of tag and attribute must be set")
+	}
+	if (m.Label == "") == (m.Annotation == "") {
+		return fmt.Errorf("one of label and annotation must be set")
+	}
+	switch m.Attribute {
+	case "", NodeAttributeInstanceTypeFamily, NodeAttributeCPU, NodeAttributeMemory, NodeAttributeGPUAmount,
+		NodeAttributeGPUSpec, NodeAttributeVSwitchID, NodeAttributeSecurityGroupIDs:
+	default:
+		return fmt.Errorf("unknown attribute %s", m.Attribute)
+	}
+	if errs := validation.IsQualifiedName(m.Key()); len(errs) != 0 {
+		return fmt.Errorf("invalid key %s: %s", m.Key(), strings.Join(errs, "; "))
+	}
+	if isReservedKey(m.Key()) {
+		return fmt.Errorf("key %s is reserved by kubernetes", m.Key())
+	}
+	return nil
+}
+
+// reservedKeyDomains are owned by kubernetes, the keys under them, e.g. node.kubernetes.io/instance-type
+// and topology.kubernetes.io/zone, are set by the kubelet and the controllers.
+var reservedKeyDomains = []string{"kubernetes.io", "k8s.io"}
+
+func isReservedKey(key string) bool {
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return false
 	}
+	prefix := key[:i]
+	for _, d := range reservedKeyDomains {
+		if prefix == d || strings.HasSuffix(prefix, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// RouteAggregation routes the pod cidrs in CIDR which are fully allocated to the nodes through the gateway
+// with one route for each of the largest prefixes, instead of one route for each node. The nodes whose pod
+// cidrs can not be summarized keep their own routes. One of GatewayNode and GatewayENI is set.
+type RouteAggregation struct {
+	// CIDR is the block the pod cidrs of a node pool are allocated from, e.g. 172.20.64.0/18
+	CIDR string `json:"cidr"`
+	// GatewayNode is the name of the node the aggregated routes go through
+	GatewayNode string `json:"gatewayNode"`
+	// GatewayENI is the id of the eni the aggregated routes go through
+	GatewayENI string `json:"gatewayENI"`
+}
+
+func (a RouteAggregation) validate() (*net.IPNet, error) {
+	_, cidr, err := net.ParseCIDR(a.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cidr %s: %s", a.CIDR, err.Error())
+	}
+	if (a.GatewayNode == "") == (a.GatewayENI == "") {
+		return nil, fmt.Errorf("one of gatewayNode and gatewayENI must be set")
+	}
+	return cidr, nil
 }
 
 func (cc *CloudConfig) LoadCloudCFG() error {
@@ -66,6 +188,42 @@ func (cc *CloudConfig) LoadCloudCFG() error {
 		return err
 	}
 	CloudCFG.SetDefaultValue()
+	return CloudCFG.Validate()
+}
+
+// Validate checks the settings which can not be defaulted.
+func (cc *CloudConfig) Validate() error {
+	switch cc.Global.RouteTableMode {
+	case "", RouteTableModeAll, RouteTableModeVSwitch:
+	default:
+		return fmt.Errorf("unknown routeTableMode %s", cc.Global.RouteTableMode)
+	}
+	if cc.Global.RouteEntryQuota < 0 {
+		return fmt.Errorf("routeEntryQuota must not be negative")
+	}
+	var blocks []*net.IPNet
+	for i, a := range cc.Global.RouteAggregations {
+		cidr, err := a.validate()
+		if err != nil {
+			return fmt.Errorf("routeAggregations[%d]: %s", i, err.Error())
+		}
+		for _, b := range blocks {
+			if b.Contains(cidr.IP) || cidr.Contains(b.IP) {
+				return fmt.Errorf("routeAggregations[%d]: cidr %s overlaps %s", i, a.CIDR, b.String())
+			}
+		}
+		blocks = append(blocks, cidr)
+	}
+	keys := map[string]bool{}
+	for i, m := range cc.Global.NodeLabelMappings {
+		if err := m.validate(); err != nil {
+			return fmt.Errorf("nodeLabelMappings[%d]: %s", i, err.Error())
+		}
+		if keys[m.Key()] {
+			return fmt.Errorf("nodeLabelMappings[%d]: duplicated key %s", i, m.Key())
+		}
+		keys[m.Key()] = true
+	}
 	return nil
 }
 
@@ -79,6 +237,18 @@ func (cc *CloudConfig) SetDefaultValue() {
 	if cc.Global.RouteMaxConcurrentReconciles == 0 {
 		cc.Global.RouteMaxConcurrentReconciles = DefaultRouteMaxConcurrentReconciles
 	}
+	if cc.Global.OpenAPIQPS == 0 {
+		cc.Global.OpenAPIQPS = DefaultOpenAPIQPS
+	}
+	if cc.Global.OpenAPIBurst == 0 {
+		cc.Global.OpenAPIBurst = DefaultOpenAPIBurst
+	}
+	if cc.Global.RouteTableMode == "" {
+		cc.Global.RouteTableMode = RouteTableModeAll
+	}
+	if cc.Global.RouteEntryQuota == 0 {
+		cc.Global.RouteEntryQuota = DefaultRouteEntryQuota
+	}
 	CloudCFG.Global.ResourceGroupID = strings.TrimSpace(CloudCFG.Global.ResourceGroupID)
 	CloudCFG.Global.RouteTableIDS = strings.TrimSpace(CloudCFG.Global.RouteTableIDS)
 }
@@ -95,6 +265,10 @@ func (cc *CloudConfig) PrintInfo() {
 		klog.Infof("using user customized route table ids [%s]", cc.Global.RouteTableIDS)
 	}
 
+	if cc.Global.RouteTableMode == RouteTableModeVSwitch {
+		klog.Infof("writing the routes of the nodes into the route tables of their vswitches")
+	}
+
 	if cc.Global.ResourceGroupID != "" {
 		klog.Infof("using default resource group id [%s]", cc.Global.ResourceGroupID)
 	}
@@ -103,6 +277,14 @@ func (cc *CloudConfig) PrintInfo() {
 		klog.Infof("using feature gate: %s", cc.Global.FeatureGates)
 	}
 
+	for _, m := range cc.Global.NodeLabelMappings {
+		klog.Infof("using node label mapping %+v", m)
+	}
+
+	for _, a := range cc.Global.RouteAggregations {
+		klog.Infof("using route aggregation %+v", a)
+	}
+
 	klog.Infof("NodeMaxConcurrentReconciles: %d, ServiceMaxConcurrentReconciles: %d, RouteMaxConcurrentReconciles: %d",
 		cc.Global.NodeMaxConcurrentReconciles, cc.Global.ServiceMaxConcurrentReconciles, cc.Global.RouteMaxConcurrentReconciles)
 }
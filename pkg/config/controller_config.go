@@ -27,6 +27,8 @@ const (
 	flagNodeEventAggregationWaitSeconds = "node-event-aggregation-wait-seconds"
 
 	flagDryRun                         = "dry-run"
+	flagSimulator                      = "simulator"
+	flagSimulatorStateFile             = "simulator-state-file"
 	flagServiceMaxConcurrentReconciles = "concurrent-service-syncs"
 	flagRouteReconciliationPeriod      = "route-reconciliation-period"
 	flagNodeMonitorPeriod              = "node-monitor-period"
@@ -60,6 +62,8 @@ type ControllerConfig struct {
 	MaxConcurrentActions            int
 	LogLevel                        int
 	DryRun                          bool
+	Simulator                       bool
+	SimulatorStateFile              string
 	NetWork                         string
 	NodeReconcileBatchSize          int
 	RouteReconcileBatchSize         int
@@ -78,11 +82,14 @@ func (cfg *ControllerConfig) BindFlags(fs *pflag.FlagSet) {
 	fs.StringSliceVar(&cfg.Controllers, flagControllers, []string{"node", "route", "service", "nlb"}, "A list of controllers to enable.")
 	fs.BoolVar(&cfg.UseServiceAccountCredentials, flagUseServiceAccountCredentials, false, "If true, use individual service account credentials for each controller.")
 	fs.BoolVar(&cfg.ConfigureCloudRoutes, flagConfigureCloudRoutes, defaultConfigureCloudRoutes, "Should CIDRs allocated by allocate-node-cidrs be configured on the cloud provider.")
-	fs.StringVar(&cfg.ClusterCIDR, flagClusterCidr, "", "CIDR Range for Pods in cluster. Requires --allocate-node-cidrs to be true.")
+	fs.StringVar(&cfg.ClusterCIDR, flagClusterCidr, "", "CIDR Range for Pods in cluster, an ipv4 and an ipv6 cidr separated by comma for dual-stack clusters. Requires --allocate-node-cidrs to be true.")
 	fs.BoolVar(&cfg.AllocateNodeCIDRs, flagAllocateNodeCIDRs, false, "Should CIDRs for Pods be allocated and set on the cloud provider.")
 	fs.IntVar(&cfg.CloudConfig.Global.ServiceMaxConcurrentReconciles, flagServiceMaxConcurrentReconciles, defaultServiceMaxConcurrentReconciles,
 		"[Deprecated, please use cloud-config config file instead] Maximum number of concurrently running reconcile loops for service")
 	fs.BoolVar(&cfg.DryRun, flagDryRun, false, "whether to perform a dry run")
+	fs.BoolVar(&cfg.Simulator, flagSimulator, false, "whether to run against the local cloud simulator instead of the OpenAPIs")
+	fs.StringVar(&cfg.SimulatorStateFile, flagSimulatorStateFile, "",
+		"The path of the state file of the cloud simulator. Empty string to keep the state in memory.")
 	fs.StringVar(&cfg.NetWork, flagNetwork, defaultNetwork, "Set network type for controller.")
 	fs.DurationVar(&cfg.RouteReconciliationPeriod.Duration, flagRouteReconciliationPeriod, defaultRouteReconciliationPeriod,
 		"The period for reconciling routes created for nodes by cloud provider. The minimum value is 1 minute")
@@ -18,6 +18,7 @@ const (
 	EndpointSlice              featuregate.Feature = "EndpointSlice"
 	FilterServiceOnNodeChange  featuregate.Feature = "FilterServiceOnNodeChange"
 	LoadBalancerTypeAnnotation                     = "LoadBalancerTypeAnnotation"
+	LoadBalancerConfig         featuregate.Feature = "LoadBalancerConfig"
 )
 
 var CloudProviderFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
@@ -25,6 +26,7 @@ var CloudProviderFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	EndpointSlice:              {Default: true, PreRelease: featuregate.GA},
 	FilterServiceOnNodeChange:  {Default: false, PreRelease: featuregate.Alpha},
 	LoadBalancerTypeAnnotation: {Default: false, PreRelease: featuregate.Alpha},
+	LoadBalancerConfig:         {Default: false, PreRelease: featuregate.Alpha},
 }
 
 func init() {
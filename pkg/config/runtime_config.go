@@ -6,6 +6,7 @@ import (
 	discovery "k8s.io/api/discovery/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"time"
 )
 
@@ -22,6 +23,8 @@ const (
 	flagLeaderElectResourceName      = "leader-elect-resource-name"
 	flagLeaderElectResourceNamespace = "leader-elect-resource-namespace"
 	flagLeaderElectRetryPeriod       = "leader-elect-retry-period"
+	flagWebhookBindPort              = "webhook-bind-port"
+	flagWebhookCertDir               = "webhook-cert-dir"
 
 	defaultMetricsAddr                  = ":8080"
 	defaultHealthProbeBindAddr          = ":10258"
@@ -51,6 +54,8 @@ type RuntimeConfig struct {
 	LeaderElectResourceNamespace string
 	QPS                          float32
 	Burst                        int
+	WebhookBindPort              int
+	WebhookCertDir               string
 }
 
 func (c *RuntimeConfig) BindFlags(fs *pflag.FlagSet) {
@@ -79,10 +84,15 @@ func (c *RuntimeConfig) BindFlags(fs *pflag.FlagSet) {
 		"The name of resource object that is used for locking during leader election. ")
 	fs.StringVar(&c.LeaderElectResourceNamespace, flagLeaderElectResourceNamespace, defaultLeaderElectResourceNamespace,
 		"The namespace of resource object that is used for locking during leader election.")
+	fs.IntVar(&c.WebhookBindPort, flagWebhookBindPort, 0,
+		"The port the admission webhook server binds to. It can be set to 0 to disable the webhook server.")
+	fs.StringVar(&c.WebhookCertDir, flagWebhookCertDir, "",
+		"The directory containing tls.crt and tls.key of the admission webhook server. "+
+			"Empty string for the default directory of controller-runtime.")
 }
 
 func BuildRuntimeOptions(rtCfg RuntimeConfig) manager.Options {
-	return manager.Options{
+	opts := manager.Options{
 		ClientDisableCacheFor: []client.Object{
 			&v1.Node{},
 			&v1.Service{},
@@ -100,4 +110,11 @@ func BuildRuntimeOptions(rtCfg RuntimeConfig) manager.Options {
 		RenewDeadline:              &rtCfg.LeaderElectRenewDeadline,
 		RetryPeriod:                &rtCfg.LeaderElectRetryPeriod,
 	}
+	if rtCfg.WebhookBindPort != 0 {
+		opts.WebhookServer = webhook.NewServer(webhook.Options{
+			Port:    rtCfg.WebhookBindPort,
+			CertDir: rtCfg.WebhookCertDir,
+		})
+	}
+	return opts
 }
@@ -3,10 +3,12 @@ package controller
 import (
 	"fmt"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/context/shared"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/gateway"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/node"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/pvtz"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/route"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/albv3"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/clbv1"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/nlbv2"
 
@@ -21,6 +23,8 @@ func init() {
 		"ingress": ingress.Add,
 		"pvtz":    pvtz.Add,
 		"nlb":     nlbv2.Add,
+		"gateway": gateway.Add,
+		"alb":     albv3.Add,
 	}
 }
 
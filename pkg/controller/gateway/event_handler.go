@@ -0,0 +1,234 @@
+package gateway
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func NewEnqueueRequestForGatewayEvent() *enqueueRequestForGatewayEvent {
+	return &enqueueRequestForGatewayEvent{}
+}
+
+type enqueueRequestForGatewayEvent struct{}
+
+var _ handler.EventHandler = (*enqueueRequestForGatewayEvent)(nil)
+
+func (h *enqueueRequestForGatewayEvent) Create(_ context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	enqueueObject(queue, e.Object)
+}
+
+func (h *enqueueRequestForGatewayEvent) Update(_ context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldObj, newObj := e.ObjectOld, e.ObjectNew
+	// skip the status updates made by ourselves
+	if oldObj.GetGeneration() == newObj.GetGeneration() &&
+		reflect.DeepEqual(oldObj.GetAnnotations(), newObj.GetAnnotations()) &&
+		reflect.DeepEqual(oldObj.GetFinalizers(), newObj.GetFinalizers()) &&
+		oldObj.GetDeletionTimestamp().Equal(newObj.GetDeletionTimestamp()) {
+		return
+	}
+	enqueueObject(queue, newObj)
+}
+
+func (h *enqueueRequestForGatewayEvent) Delete(_ context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	// Gateways have the finalizer, the deletion is handled by the update event.
+}
+
+func (h *enqueueRequestForGatewayEvent) Generic(_ context.Context, e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+	enqueueObject(queue, e.Object)
+}
+
+func enqueueObject(queue workqueue.RateLimitingInterface, obj client.Object) {
+	queue.Add(reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()},
+	})
+}
+
+func NewEnqueueRequestForGatewayClassEvent(cache client.Reader, logger logr.Logger) *enqueueRequestForGatewayClassEvent {
+	return &enqueueRequestForGatewayClassEvent{cache: cache, logger: logger}
+}
+
+type enqueueRequestForGatewayClassEvent struct {
+	cache  client.Reader
+	logger logr.Logger
+}
+
+var _ handler.EventHandler = (*enqueueRequestForGatewayClassEvent)(nil)
+
+func (h *enqueueRequestForGatewayClassEvent) Create(ctx context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueueGateways(ctx, queue, e.Object)
+}
+
+func (h *enqueueRequestForGatewayClassEvent) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	if e.ObjectOld.GetGeneration() == e.ObjectNew.GetGeneration() {
+		return
+	}
+	h.enqueueGateways(ctx, queue, e.ObjectNew)
+}
+
+func (h *enqueueRequestForGatewayClassEvent) Delete(ctx context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueueGateways(ctx, queue, e.Object)
+}
+
+func (h *enqueueRequestForGatewayClassEvent) Generic(_ context.Context, e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+	// unknown type event, ignore
+}
+
+func (h *enqueueRequestForGatewayClassEvent) enqueueGateways(ctx context.Context, queue workqueue.RateLimitingInterface, class client.Object) {
+	list := newUnstructuredList(GatewayGVK)
+	if err := h.cache.List(ctx, list); err != nil {
+		h.logger.Error(err, "list gateways failed", "gatewayclass", class.GetName())
+		return
+	}
+	for i := range list.Items {
+		name, _, _ := unstructured.NestedString(list.Items[i].Object, "spec", "gatewayClassName")
+		if name == class.GetName() {
+			enqueueObject(queue, &list.Items[i])
+		}
+	}
+}
+
+func NewEnqueueRequestForRouteEvent() *enqueueRequestForRouteEvent {
+	return &enqueueRequestForRouteEvent{}
+}
+
+// enqueueRequestForRouteEvent enqueues the Gateways a route points to, before and
+// after the change, so that detached Gateways drop the route rules.
+type enqueueRequestForRouteEvent struct{}
+
+var _ handler.EventHandler = (*enqueueRequestForRouteEvent)(nil)
+
+func (h *enqueueRequestForRouteEvent) Create(_ context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	enqueueParentGateways(queue, e.Object)
+}
+
+func (h *enqueueRequestForRouteEvent) Update(_ context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	if e.ObjectOld.GetGeneration() == e.ObjectNew.GetGeneration() {
+		return
+	}
+	enqueueParentGateways(queue, e.ObjectOld)
+	enqueueParentGateways(queue, e.ObjectNew)
+}
+
+func (h *enqueueRequestForRouteEvent) Delete(_ context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	enqueueParentGateways(queue, e.Object)
+}
+
+func (h *enqueueRequestForRouteEvent) Generic(_ context.Context, e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+	enqueueParentGateways(queue, e.Object)
+}
+
+func enqueueParentGateways(queue workqueue.RateLimitingInterface, obj client.Object) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	var route *routeContext
+	var err error
+	if u.GetKind() == KindGRPCRoute {
+		route, err = newGRPCRouteContext(u)
+	} else {
+		route, err = newHTTPRouteContext(u)
+	}
+	if err != nil {
+		return
+	}
+	for _, key := range routeParentGateways(route) {
+		queue.Add(reconcile.Request{NamespacedName: key})
+	}
+}
+
+func routeParentGateways(route *routeContext) []types.NamespacedName {
+	var keys []types.NamespacedName
+	add := func(ref ParentReference) {
+		if (ref.Group != nil && *ref.Group != GroupName) || (ref.Kind != nil && *ref.Kind != KindGateway) {
+			return
+		}
+		key := types.NamespacedName{Namespace: route.Namespace, Name: ref.Name}
+		if ref.Namespace != nil && *ref.Namespace != "" {
+			key.Namespace = *ref.Namespace
+		}
+		keys = append(keys, key)
+	}
+	for _, ref := range route.ParentRefs {
+		add(ref)
+	}
+	for _, p := range route.Status.Parents {
+		if p.ControllerName == ControllerName {
+			add(p.ParentRef)
+		}
+	}
+	return keys
+}
+
+func NewEnqueueRequestForBackendEvent(cache client.Reader, routeGVKs []schema.GroupVersionKind, logger logr.Logger) *enqueueRequestForBackendEvent {
+	return &enqueueRequestForBackendEvent{cache: cache, routeGVKs: routeGVKs, logger: logger}
+}
+
+// enqueueRequestForBackendEvent maps Service and Endpoints events to the Gateways
+// of the routes using the Service as a backend.
+type enqueueRequestForBackendEvent struct {
+	cache     client.Reader
+	routeGVKs []schema.GroupVersionKind
+	logger    logr.Logger
+}
+
+var _ handler.EventHandler = (*enqueueRequestForBackendEvent)(nil)
+
+func (h *enqueueRequestForBackendEvent) Create(ctx context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueueGateways(ctx, queue, e.Object)
+}
+
+func (h *enqueueRequestForBackendEvent) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	if e.ObjectOld.GetResourceVersion() == e.ObjectNew.GetResourceVersion() {
+		return
+	}
+	h.enqueueGateways(ctx, queue, e.ObjectNew)
+}
+
+func (h *enqueueRequestForBackendEvent) Delete(ctx context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueueGateways(ctx, queue, e.Object)
+}
+
+func (h *enqueueRequestForBackendEvent) Generic(_ context.Context, e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+	// unknown type event, ignore
+}
+
+func (h *enqueueRequestForBackendEvent) enqueueGateways(ctx context.Context, queue workqueue.RateLimitingInterface, obj client.Object) {
+	routes, err := listRoutes(ctx, h.cache, h.routeGVKs)
+	if err != nil {
+		h.logger.Error(err, "list routes failed", "backend", obj.GetNamespace()+"/"+obj.GetName())
+		return
+	}
+	for _, route := range routes {
+		if !routeUsesService(route, obj.GetNamespace(), obj.GetName()) {
+			continue
+		}
+		for _, key := range routeParentGateways(route) {
+			queue.Add(reconcile.Request{NamespacedName: key})
+		}
+	}
+}
+
+func routeUsesService(route *routeContext, namespace, name string) bool {
+	if route.Namespace != namespace {
+		return false
+	}
+	for _, rule := range route.Rules {
+		for _, ref := range ruleServiceRefs(rule) {
+			if ref.Name == name && (ref.Namespace == nil || *ref.Namespace == namespace) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,438 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdkutils "github.com/aliyun/alibaba-cloud-sdk-go/sdk/utils"
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/context/shared"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/applier"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/backend"
+	servicemanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/service_manager"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/store"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	gatewayControllerName = "gateway-controller"
+
+	// ControllerName is the GatewayClass spec.controllerName handled by this controller.
+	ControllerName = "alibabacloud.com/alb"
+)
+
+func Add(mgr manager.Manager, ctx *shared.SharedContext) error {
+	logger := ctrl.Log.WithName("controllers").WithName(gatewayControllerName)
+	if _, err := mgr.GetRESTMapper().RESTMapping(GatewayGVK.GroupKind(), GatewayGVK.Version); err != nil {
+		logger.Info("gateway api crds are not installed, skip gateway controller", "error", err.Error())
+		return nil
+	}
+	r, err := newReconciler(mgr, ctx, logger)
+	if err != nil {
+		return fmt.Errorf("new gateway reconciler error: %s", err.Error())
+	}
+	return add(mgr, r)
+}
+
+func newReconciler(mgr manager.Manager, ctx *shared.SharedContext, logger logr.Logger) (*ReconcileGateway, error) {
+	r := &ReconcileGateway{
+		cloud:            ctx.Provider(),
+		kubeClient:       mgr.GetClient(),
+		cache:            mgr.GetCache(),
+		logger:           logger,
+		record:           mgr.GetEventRecorderFor(gatewayControllerName),
+		finalizerManager: helper.NewDefaultFinalizerManager(mgr.GetClient()),
+		builder:          NewDefaultModelBuilder(mgr.GetClient(), ctx.Provider(), logger),
+		serverApplier:    applier.NewServiceManagerApplier(mgr.GetClient(), ctx.Provider(), logger),
+	}
+	if _, err := mgr.GetRESTMapper().RESTMapping(GRPCRouteGVK.GroupKind(), GRPCRouteGVK.Version); err == nil {
+		r.grpcRouteEnabled = true
+	}
+
+	// the backend manager resolves endpoints and pods through the ingress store
+	sharedStore, err := store.Shared(ctx, mgr)
+	if err != nil {
+		return nil, err
+	}
+	r.store = sharedStore
+	r.stackApplier = applier.NewAlbConfigManagerApplier(r.store, mgr.GetClient(), ctx.Provider(), util.GatewayTagKeyPrefix, logger)
+	r.serverBuilder = servicemanager.NewDefaultServiceStackBuilder(backend.NewBackendManager(r.store, mgr.GetClient(), ctx.Provider(), logger))
+	return r, nil
+}
+
+type gatewayController struct {
+	c     controller.Controller
+	recon *ReconcileGateway
+}
+
+func (g gatewayController) Start(ctx context.Context) error {
+	if !g.recon.store.WaitForSync(ctx.Done()) {
+		return nil
+	}
+	return g.c.Start(ctx)
+}
+
+func add(mgr manager.Manager, r *ReconcileGateway) error {
+	rateLimit := workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(5*time.Second, 300*time.Second),
+		// 10 qps, 100 bucket size.  This is only for retry speed and its only the overall factor (not per item)
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+	recoverPanic := true
+	c, err := controller.NewUnmanaged(
+		gatewayControllerName, mgr,
+		controller.Options{
+			Reconciler:              r,
+			MaxConcurrentReconciles: ctrlCfg.CloudCFG.Global.ServiceMaxConcurrentReconciles,
+			RateLimiter:             rateLimit,
+			RecoverPanic:            &recoverPanic,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Watch(source.Kind(mgr.GetCache(), newUnstructured(GatewayGVK)),
+		NewEnqueueRequestForGatewayEvent()); err != nil {
+		return fmt.Errorf("watch resource gateway error: %s", err.Error())
+	}
+	if err := c.Watch(source.Kind(mgr.GetCache(), newUnstructured(GatewayClassGVK)),
+		NewEnqueueRequestForGatewayClassEvent(r.cache, r.logger)); err != nil {
+		return fmt.Errorf("watch resource gatewayclass error: %s", err.Error())
+	}
+	for _, gvk := range r.routeGVKs() {
+		if err := c.Watch(source.Kind(mgr.GetCache(), newUnstructured(gvk)),
+			NewEnqueueRequestForRouteEvent()); err != nil {
+			return fmt.Errorf("watch resource %s error: %s", gvk.Kind, err.Error())
+		}
+	}
+	if err := c.Watch(source.Kind(mgr.GetCache(), &corev1.Service{}),
+		NewEnqueueRequestForBackendEvent(r.cache, r.routeGVKs(), r.logger)); err != nil {
+		return fmt.Errorf("watch resource svc error: %s", err.Error())
+	}
+	if err := c.Watch(source.Kind(mgr.GetCache(), &corev1.Endpoints{}),
+		NewEnqueueRequestForBackendEvent(r.cache, r.routeGVKs(), r.logger)); err != nil {
+		return fmt.Errorf("watch resource endpoint error: %s", err.Error())
+	}
+
+	return mgr.Add(&gatewayController{c: c, recon: r})
+}
+
+var _ reconcile.Reconciler = &ReconcileGateway{}
+
+// ReconcileGateway programs one ALB instance per Gateway whose GatewayClass is
+// handled by ControllerName. The ALB resources are applied by the same appliers
+// as the AlbConfig stacks, tagged with their own prefix.
+type ReconcileGateway struct {
+	cloud      prvd.Provider
+	kubeClient client.Client
+	// cache serves the unstructured Gateway API lists without hitting the apiserver
+	cache  client.Reader
+	logger logr.Logger
+
+	record           record.EventRecorder
+	finalizerManager helper.FinalizerManager
+
+	builder       Builder
+	stackApplier  applier.AlbConfigManagerApplier
+	serverBuilder servicemanager.Builder
+	serverApplier applier.ServiceManagerApplier
+	store         *store.SharedStore
+
+	grpcRouteEnabled bool
+}
+
+func (r *ReconcileGateway) routeGVKs() []schema.GroupVersionKind {
+	gvks := []schema.GroupVersionKind{HTTPRouteGVK}
+	if r.grpcRouteEnabled {
+		gvks = append(gvks, GRPCRouteGVK)
+	}
+	return gvks
+}
+
+func (r *ReconcileGateway) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	traceID := sdkutils.GetTimeInFormatISO8601()
+	ctx = context.WithValue(ctx, util.TraceID, traceID)
+	return util.HandleReconcileResult(request, r.reconcile(ctx, request))
+}
+
+func (r *ReconcileGateway) reconcile(ctx context.Context, request reconcile.Request) error {
+	startTime := time.Now()
+	r.logger.Info("start reconcile gateway", "gateway", request.String(), "traceID", ctx.Value(util.TraceID))
+	defer func() {
+		r.logger.Info("finish reconcile gateway", "gateway", request.String(),
+			"traceID", ctx.Value(util.TraceID), "elapsedTime", time.Since(startTime).Milliseconds())
+	}()
+
+	u := newUnstructured(GatewayGVK)
+	if err := r.kubeClient.Get(ctx, request.NamespacedName, u); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	gw := &Gateway{}
+	if err := fromUnstructured(u, gw); err != nil {
+		return fmt.Errorf("decode gateway %s error: %s", request.String(), err.Error())
+	}
+
+	managed, err := r.acceptGatewayClass(ctx, gw.Spec.GatewayClassName)
+	if err != nil {
+		return err
+	}
+	if !managed && !helper.HasFinalizer(u, util.GatewayFinalizer) {
+		return nil
+	}
+
+	if !managed || !u.GetDeletionTimestamp().IsZero() {
+		return r.cleanupGatewayResources(ctx, u, gw)
+	}
+	return r.reconcileGatewayResources(ctx, u, gw)
+}
+
+// acceptGatewayClass reports whether the class belongs to this controller and marks it Accepted.
+func (r *ReconcileGateway) acceptGatewayClass(ctx context.Context, name string) (bool, error) {
+	u := newUnstructured(GatewayClassGVK)
+	if err := r.kubeClient.Get(ctx, types.NamespacedName{Name: name}, u); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	gwc := &GatewayClass{}
+	if err := fromUnstructured(u, gwc); err != nil {
+		return false, err
+	}
+	if gwc.Spec.ControllerName != ControllerName {
+		return false, nil
+	}
+	conditions := gwc.Status.Conditions
+	if !setCondition(&conditions, gwc.Generation, ConditionAccepted, true, ReasonAccepted, "") {
+		return true, nil
+	}
+	gwc.Status.Conditions = conditions
+	if err := updateStatus(ctx, r.kubeClient, u, gwc.Status); err != nil {
+		return true, fmt.Errorf("update gatewayclass %s status error: %s", name, err.Error())
+	}
+	return true, nil
+}
+
+func (r *ReconcileGateway) cleanupGatewayResources(ctx context.Context, u *unstructured.Unstructured, gw *Gateway) error {
+	if !helper.HasFinalizer(u, util.GatewayFinalizer) {
+		return nil
+	}
+	deleted := *gw
+	if deleted.DeletionTimestamp.IsZero() {
+		// the class was switched to another controller, release the resources all the same
+		now := metav1.Now()
+		deleted.DeletionTimestamp = &now
+	}
+	if _, _, _, err := r.buildAndApply(ctx, u, &deleted, nil); err != nil {
+		return err
+	}
+	if err := r.finalizerManager.RemoveFinalizers(ctx, u, util.GatewayFinalizer); err != nil {
+		r.record.Event(u, corev1.EventTypeWarning, helper.IngressEventReasonFailedRemoveFinalizer,
+			fmt.Sprintf("Failed remove finalizer due to %s", helper.GetLogMessage(err)))
+		return err
+	}
+	return nil
+}
+
+func (r *ReconcileGateway) reconcileGatewayResources(ctx context.Context, u *unstructured.Unstructured, gw *Gateway) error {
+	if err := r.finalizerManager.AddFinalizers(ctx, u, util.GatewayFinalizer); err != nil {
+		r.record.Event(u, corev1.EventTypeWarning, helper.IngressEventReasonFailedAddFinalizer,
+			fmt.Sprintf("Failed add finalizer due to %s", helper.GetLogMessage(err)))
+		return err
+	}
+
+	routes, err := r.listAttachedRoutes(ctx, gw)
+	if err != nil {
+		return err
+	}
+
+	lb, result, applyErr := func() (*albmodel.AlbLoadBalancer, *BuildResult, error) {
+		_, lb, result, err := r.buildAndApply(ctx, u, gw, routes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return lb, result, r.syncServers(ctx, result)
+	}()
+
+	if err := r.updateGatewayStatus(ctx, u, gw, lb, result, applyErr); err != nil {
+		r.record.Event(u, corev1.EventTypeWarning, helper.IngressEventReasonFailedUpdateStatus, helper.GetLogMessage(err))
+		return err
+	}
+	if result != nil {
+		if err := r.updateRouteStatus(ctx, gw, routes, result); err != nil {
+			return err
+		}
+	}
+	if applyErr != nil {
+		return applyErr
+	}
+	r.record.Event(u, corev1.EventTypeNormal, helper.IngressEventReasonSuccessfullyReconciled, "Successfully reconciled")
+	return nil
+}
+
+func (r *ReconcileGateway) buildAndApply(ctx context.Context, u *unstructured.Unstructured, gw *Gateway, routes []*routeContext) (interface{}, *albmodel.AlbLoadBalancer, *BuildResult, error) {
+	stack, lb, result, err := r.builder.Build(ctx, gw, routes)
+	if err != nil {
+		r.record.Event(u, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(err))
+		return nil, nil, nil, err
+	}
+	if err := r.stackApplier.Apply(ctx, stack); err != nil {
+		r.record.Event(u, corev1.EventTypeWarning, helper.IngressEventReasonFailedApplyModel, helper.GetLogMessage(err))
+		return nil, nil, nil, err
+	}
+	return stack, lb, result, nil
+}
+
+// listAttachedRoutes lists the routes with a parentRef pointing to the Gateway, and
+// the routes detached since the last reconcile so that their status gets cleaned.
+func (r *ReconcileGateway) listAttachedRoutes(ctx context.Context, gw *Gateway) ([]*routeContext, error) {
+	routes, err := listRoutes(ctx, r.cache, r.routeGVKs())
+	if err != nil {
+		return nil, err
+	}
+	var attached []*routeContext
+	for _, route := range routes {
+		if routeReferencesGateway(route, gw) {
+			attached = append(attached, route)
+		}
+	}
+	return attached, nil
+}
+
+func routeReferencesGateway(route *routeContext, gw *Gateway) bool {
+	for _, ref := range route.ParentRefs {
+		if parentRefMatches(ref, route.Namespace, gw, nil) {
+			return true
+		}
+	}
+	for _, p := range route.Status.Parents {
+		if p.ControllerName == ControllerName && parentRefMatches(p.ParentRef, route.Namespace, gw, nil) {
+			return true
+		}
+	}
+	return false
+}
+
+func listRoutes(ctx context.Context, reader client.Reader, gvks []schema.GroupVersionKind) ([]*routeContext, error) {
+	var routes []*routeContext
+	for _, gvk := range gvks {
+		list := newUnstructuredList(gvk)
+		if err := reader.List(ctx, list); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		for i := range list.Items {
+			var (
+				route *routeContext
+				err   error
+			)
+			if gvk.Kind == KindGRPCRoute {
+				route, err = newGRPCRouteContext(&list.Items[i])
+			} else {
+				route, err = newHTTPRouteContext(&list.Items[i])
+			}
+			if err != nil {
+				return nil, err
+			}
+			routes = append(routes, route)
+		}
+	}
+	return routes, nil
+}
+
+// syncServers registers the endpoints of every Service used by an accepted route
+// into the server groups of the routes, the same way Service events do for Ingresses.
+func (r *ReconcileGateway) syncServers(ctx context.Context, result *BuildResult) error {
+	svcPortToRoutes := make(map[types.NamespacedName]map[int32][]string)
+	for _, res := range result.Routes {
+		if !res.Accepted {
+			continue
+		}
+		for _, rule := range res.Route.Rules {
+			for _, ref := range ruleServiceRefs(rule) {
+				if ref.Port == nil || (ref.Namespace != nil && *ref.Namespace != res.Route.Namespace) {
+					continue
+				}
+				key := types.NamespacedName{Namespace: res.Route.Namespace, Name: ref.Name}
+				if _, ok := svcPortToRoutes[key]; !ok {
+					svcPortToRoutes[key] = make(map[int32][]string)
+				}
+				svcPortToRoutes[key][*ref.Port] = appendUnique(svcPortToRoutes[key][*ref.Port], res.Route.Key())
+			}
+		}
+	}
+
+	potentialReady := false
+	for key, portToRoutes := range svcPortToRoutes {
+		svcStackCtx := &albmodel.ServiceStackContext{
+			ClusterID:                 r.cloud.ClusterID(),
+			ServiceNamespace:          key.Namespace,
+			ServiceName:               key.Name,
+			ServicePortToIngressNames: portToRoutes,
+		}
+		svc := &corev1.Service{}
+		if err := r.kubeClient.Get(ctx, key, svc); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			svcStackCtx.IsServiceNotFound = true
+		} else {
+			svcStackCtx.Service = svc
+		}
+
+		serverStack, err := r.serverBuilder.Build(ctx, svcStackCtx)
+		if err != nil {
+			return fmt.Errorf("build service stack model error: %v", err)
+		}
+		if err := r.serverApplier.Apply(ctx, r.cloud, serverStack); err != nil {
+			return err
+		}
+		if serverStack.ContainsPotentialReadyEndpoints {
+			potentialReady = true
+		}
+	}
+	if potentialReady {
+		return util.NewReconcileNeedRequeue("retry potential ready endpoints")
+	}
+	return nil
+}
+
+// ruleServiceRefs returns the backends of the rule, including the RequestMirror targets.
+func ruleServiceRefs(rule HTTPRouteRule) []BackendRef {
+	refs := append([]BackendRef{}, rule.BackendRefs...)
+	for _, f := range rule.Filters {
+		if f.Type == FilterRequestMirror && f.RequestMirror != nil {
+			refs = append(refs, f.RequestMirror.BackendRef)
+		}
+	}
+	return refs
+}
+
+func appendUnique(s []string, v string) []string {
+	for _, e := range s {
+		if e == v {
+			return s
+		}
+	}
+	return append(s, v)
+}
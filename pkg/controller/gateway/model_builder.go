@@ -0,0 +1,952 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+	albconfigmanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ProtocolHTTP  = "HTTP"
+	ProtocolHTTPS = "HTTPS"
+
+	// TLSOptionCertIDs is a Gateway listener tls.options key holding a comma separated
+	// list of CAS certificate ids, the first one being the default certificate.
+	TLSOptionCertIDs = "alibabacloud.com/cert-ids"
+
+	ApplicationLoadBalancerResource = "ApplicationLoadBalancer"
+	ListenerRuleNamePrefix          = "gw"
+	// MaxListenerRulePriority is the largest rule priority accepted by ALB.
+	MaxListenerRulePriority = 10000
+
+	fakeDefaultServiceName = "fake-svc"
+	noBackendHTTPCode      = "500"
+)
+
+type Builder interface {
+	Build(ctx context.Context, gw *Gateway, routes []*routeContext) (core.Manager, *alb.AlbLoadBalancer, *BuildResult, error)
+}
+
+// BuildResult carries what the builder learnt about route attachment, so the
+// reconciler can report it in Gateway and route status.
+type BuildResult struct {
+	// AttachedRoutes counts the routes attached to each Gateway listener, by listener name.
+	AttachedRoutes map[string]int32
+	// Routes holds the per route outcome, by route kind/namespace/name.
+	Routes map[string]*RouteResult
+	// ListenerErrors holds listeners which could not be programmed, by listener name.
+	ListenerErrors map[string]error
+}
+
+type RouteResult struct {
+	Route    *routeContext
+	Accepted bool
+	// Reason and Message explain why the route was not accepted.
+	Reason  string
+	Message string
+	// UnresolvedRefs lists backend refs which could not be resolved.
+	UnresolvedRefs []string
+	RefReason      string
+}
+
+func routeResultKey(route *routeContext) string {
+	return fmt.Sprintf("%s/%s/%s", route.Kind, route.Namespace, route.Name)
+}
+
+var _ Builder = &defaultModelBuilder{}
+
+type defaultModelBuilder struct {
+	kubeClient client.Client
+	cloud      prvd.Provider
+	logger     logr.Logger
+}
+
+func NewDefaultModelBuilder(kubeClient client.Client, cloud prvd.Provider, logger logr.Logger) *defaultModelBuilder {
+	return &defaultModelBuilder{
+		kubeClient: kubeClient,
+		cloud:      cloud,
+		logger:     logger,
+	}
+}
+
+func (b *defaultModelBuilder) Build(ctx context.Context, gw *Gateway, routes []*routeContext) (core.Manager, *alb.AlbLoadBalancer, *BuildResult, error) {
+	stack := core.NewDefaultManager(core.StackID(types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}))
+	result := &BuildResult{
+		AttachedRoutes: make(map[string]int32),
+		Routes:         make(map[string]*RouteResult),
+		ListenerErrors: make(map[string]error),
+	}
+	if !gw.DeletionTimestamp.IsZero() {
+		return stack, nil, result, nil
+	}
+
+	vpcID, err := b.cloud.VpcID()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	task := &defaultModelBuildTask{
+		stack:      stack,
+		gateway:    gw,
+		routes:     routes,
+		result:     result,
+		kubeClient: b.kubeClient,
+		clusterID:  b.cloud.ClusterID(),
+		vpcID:      vpcID,
+
+		sgpByResID: make(map[string]*alb.ServerGroup),
+		services:   make(map[types.NamespacedName]*corev1.Service),
+
+		certDiscovery:   albconfigmanager.NewCASCertDiscovery(b.cloud, b.logger),
+		vSwitchResolver: albconfigmanager.NewDefaultVSwitchResolver(b.cloud, vpcID, b.logger),
+	}
+	if err := task.run(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+	return task.stack, task.loadBalancer, task.result, nil
+}
+
+type defaultModelBuildTask struct {
+	stack        core.Manager
+	loadBalancer *alb.AlbLoadBalancer
+	gateway      *Gateway
+	routes       []*routeContext
+	result       *BuildResult
+	kubeClient   client.Client
+
+	clusterID string
+	vpcID     string
+
+	sgpByResID map[string]*alb.ServerGroup
+	services   map[types.NamespacedName]*corev1.Service
+
+	certDiscovery   albconfigmanager.CertDiscovery
+	vSwitchResolver albconfigmanager.VSwitchResolver
+}
+
+// listenerGroup collects the Gateway listeners sharing one ALB listener port.
+type listenerGroup struct {
+	port      int32
+	protocol  string
+	listeners []*Listener
+}
+
+func (t *defaultModelBuildTask) run(ctx context.Context) error {
+	lb, err := t.buildAlbLoadBalancer(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, route := range t.routes {
+		t.result.Routes[routeResultKey(route)] = &RouteResult{
+			Route:  route,
+			Reason: "NoMatchingParent",
+			Message: fmt.Sprintf("no listener of gateway %s/%s accepts the route",
+				t.gateway.Namespace, t.gateway.Name),
+		}
+	}
+
+	for _, group := range t.groupListeners() {
+		// the build fails rather than dropping the listener, which would delete the live one with its rules
+		ls, err := t.buildListener(ctx, lb.LoadBalancerID(), group)
+		if err != nil {
+			return fmt.Errorf("build listener of port %d error: %w", group.port, err)
+		}
+		if err := t.buildListenerRules(ctx, ls, group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *defaultModelBuildTask) groupListeners() []*listenerGroup {
+	groups := make(map[int32]*listenerGroup)
+	for i := range t.gateway.Spec.Listeners {
+		ls := &t.gateway.Spec.Listeners[i]
+		group, ok := groups[ls.Port]
+		if !ok {
+			group = &listenerGroup{port: ls.Port, protocol: ls.Protocol}
+			groups[ls.Port] = group
+		}
+		if group.protocol != ls.Protocol {
+			t.result.ListenerErrors[ls.Name] = fmt.Errorf("protocol %s conflicts with protocol %s on port %d",
+				ls.Protocol, group.protocol, ls.Port)
+			continue
+		}
+		if ls.Protocol != ProtocolHTTP && ls.Protocol != ProtocolHTTPS {
+			t.result.ListenerErrors[ls.Name] = fmt.Errorf("unsupported protocol %s", ls.Protocol)
+			continue
+		}
+		group.listeners = append(group.listeners, ls)
+	}
+
+	var result []*listenerGroup
+	for _, g := range groups {
+		if len(g.listeners) != 0 {
+			result = append(result, g)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].port < result[j].port })
+	return result
+}
+
+var invalidLoadBalancerNamePattern = regexp.MustCompile("[[:^alnum:]]")
+
+func (t *defaultModelBuildTask) buildAlbLoadBalancerName() string {
+	uuidHash := sha256.New()
+	_, _ = uuidHash.Write([]byte(t.clusterID))
+	_, _ = uuidHash.Write([]byte(t.gateway.Namespace + "/" + t.gateway.Name))
+	uuid := hex.EncodeToString(uuidHash.Sum(nil))
+
+	sanitizedNamespace := invalidLoadBalancerNamePattern.ReplaceAllString(t.gateway.Namespace, "")
+	sanitizedName := invalidLoadBalancerNamePattern.ReplaceAllString(t.gateway.Name, "")
+	return fmt.Sprintf("k8s-gw-%s-%s-%.10s", sanitizedNamespace, sanitizedName, uuid)
+}
+
+// buildAlbLoadBalancer reads the load balancer settings from the same annotations
+// an Ingress uses to create its AlbConfig.
+func (t *defaultModelBuildTask) buildAlbLoadBalancer(ctx context.Context) (*alb.AlbLoadBalancer, error) {
+	anno := t.gateway.Annotations
+	lbModel := alb.ALBLoadBalancerSpec{}
+	lbModel.LoadBalancerId = anno[annotations.LoadBalancerId]
+	forceOverride := anno[annotations.OverrideListener] == "true"
+	lbModel.ForceOverride = &forceOverride
+	lbModel.LoadBalancerName = anno[annotations.LoadBalancerName]
+	if lbModel.LoadBalancerName == "" {
+		lbModel.LoadBalancerName = t.buildAlbLoadBalancerName()
+	}
+	lbModel.VpcId = t.vpcID
+	lbModel.AddressType = stringWithDefault(anno[annotations.AddressType], util.DefaultLoadBalancerAddressType)
+	lbModel.AddressAllocatedMode = stringWithDefault(anno[annotations.AddressAllocatedMode], util.DefaultLoadBalancerAddressAllocatedMode)
+	lbModel.LoadBalancerEdition = stringWithDefault(anno[annotations.LoadBalancerEdition], util.LoadBalancerEditionStandard)
+	lbModel.LoadBalancerBillingConfig = alb.LoadBalancerBillingConfig{
+		PayType: stringWithDefault(anno[annotations.ChargeType], util.DefaultLoadBalancerBillingConfigPayType),
+	}
+	lbModel.DeletionProtectionConfig = alb.DeletionProtectionConfig{
+		Enabled: util.DefaultLoadBalancerDeletionProtectionConfigEnabled,
+	}
+	lbModel.ModificationProtectionConfig = alb.ModificationProtectionConfig{
+		Status: util.DefaultLoadBalancerModificationProtectionConfigStatus,
+	}
+
+	if lbModel.LoadBalancerId == "" {
+		var vSwitchIds []string
+		if v := anno[annotations.VswitchIds]; v != "" {
+			vSwitchIds = strings.Split(v, ",")
+		}
+		zoneMappings, err := t.buildZoneMappings(ctx, vSwitchIds)
+		if err != nil {
+			return nil, err
+		}
+		lbModel.ZoneMapping = zoneMappings
+	}
+
+	lb := alb.NewAlbLoadBalancer(t.stack, ApplicationLoadBalancerResource, lbModel)
+	t.loadBalancer = lb
+	return lb, nil
+}
+
+func (t *defaultModelBuildTask) buildZoneMappings(ctx context.Context, vSwitchIds []string) ([]alb.ZoneMapping, error) {
+	var err error
+	var zoneMappings []alb.ZoneMapping
+	if len(vSwitchIds) != 0 {
+		vSwitches, err := t.vSwitchResolver.ResolveViaIDSlice(ctx, vSwitchIds)
+		if err != nil {
+			return nil, err
+		}
+		for _, vsw := range vSwitches {
+			zoneMappings = append(zoneMappings, alb.ZoneMapping{VSwitchId: vsw.VSwitchId, ZoneId: vsw.ZoneId})
+		}
+	} else {
+		vSwitches, err := t.vSwitchResolver.ResolveViaDiscovery(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, vsw := range vSwitches {
+			zoneMappings = append(zoneMappings, alb.ZoneMapping{VSwitchId: vsw.VSwitchId, ZoneId: vsw.ZoneId})
+		}
+	}
+	if len(zoneMappings) < 2 {
+		return nil, errors.New("unable to discover at least two vswitchs for alb")
+	}
+	return zoneMappings, err
+}
+
+func (t *defaultModelBuildTask) buildListener(ctx context.Context, lbID core.StringToken, group *listenerGroup) (*alb.Listener, error) {
+	defaultAction, err := t.buildListenerDefaultAction(ctx, group.port)
+	if err != nil {
+		return nil, err
+	}
+	spec := alb.ListenerSpec{LoadBalancerID: lbID}
+	spec.ListenerPort = int(group.port)
+	spec.ListenerProtocol = group.protocol
+	spec.ListenerDescription = fmt.Sprintf("%v-%v", ListenerRuleNamePrefix, group.port)
+	spec.DefaultActions = []alb.Action{defaultAction}
+	spec.IdleTimeout = util.DefaultListenerIdleTimeout
+	spec.RequestTimeout = util.DefaultListenerRequestTimeout
+	spec.GzipEnabled = util.DefaultListenerGzipEnabled
+
+	if group.protocol == ProtocolHTTPS {
+		certs, err := t.buildListenerCertificates(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+		spec.Certificates = certs
+		spec.SecurityPolicyId = util.DefaultListenerSecurityPolicyId
+		spec.Http2Enabled = util.DefaultListenerHttp2Enabled
+	}
+	return alb.NewListener(t.stack, fmt.Sprintf("%v", group.port), spec), nil
+}
+
+// buildListenerCertificates uses the certificate ids from the listener tls options,
+// and falls back to discovering CAS certificates by listener hostname.
+func (t *defaultModelBuildTask) buildListenerCertificates(ctx context.Context, group *listenerGroup) ([]alb.Certificate, error) {
+	var certIDs []string
+	var hosts []string
+	for _, ls := range group.listeners {
+		if ls.TLS != nil && ls.TLS.Mode != nil && *ls.TLS.Mode != "Terminate" {
+			return nil, fmt.Errorf("listener %s: tls mode %s is not supported", ls.Name, *ls.TLS.Mode)
+		}
+		if ls.TLS != nil && ls.TLS.Options[TLSOptionCertIDs] != "" {
+			for _, id := range strings.Split(ls.TLS.Options[TLSOptionCertIDs], ",") {
+				if id = strings.TrimSpace(id); id != "" {
+					certIDs = append(certIDs, id)
+				}
+			}
+			continue
+		}
+		if ls.Hostname != nil && *ls.Hostname != "" {
+			hosts = append(hosts, *ls.Hostname)
+		}
+	}
+	if len(hosts) != 0 {
+		discovered, err := t.certDiscovery.Discover(ctx, hosts)
+		if err != nil {
+			return nil, err
+		}
+		certIDs = append(certIDs, discovered...)
+	}
+	certIDs = sets.NewString(certIDs...).List()
+	if len(certIDs) == 0 {
+		return nil, fmt.Errorf("no certificate was found for https port %d", group.port)
+	}
+	var certs []alb.Certificate
+	for i, id := range certIDs {
+		certs = append(certs, alb.Certificate{CertificateId: id, IsDefault: i == 0})
+	}
+	return certs, nil
+}
+
+func (t *defaultModelBuildTask) buildListenerDefaultAction(ctx context.Context, port int32) (alb.Action, error) {
+	routeKey := t.gateway.Name + util.DefaultListenerFlag + strconv.Itoa(int(port))
+	sgp, err := t.buildServerGroup(ctx, routeKey, t.gateway.Namespace, fakeDefaultServiceName, int(port), false)
+	if err != nil {
+		return alb.Action{}, err
+	}
+	return alb.Action{
+		Type: util.RuleActionTypeForward,
+		ForwardConfig: &alb.ForwardActionConfig{
+			ServerGroups: []alb.ServerGroupTuple{{ServerGroupID: sgp.ServerGroupID()}},
+		},
+	}, nil
+}
+
+// ruleCandidate is one route match translated for a listener, before priorities are assigned.
+type ruleCandidate struct {
+	route      *routeContext
+	ruleIndex  int
+	matchIndex int
+	match      HTTPRouteMatch
+	conditions []alb.Condition
+	actions    []alb.Action
+}
+
+func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, ls *alb.Listener, group *listenerGroup) error {
+	var candidates []*ruleCandidate
+	for _, route := range t.routes {
+		res := t.result.Routes[routeResultKey(route)]
+		hostSet := sets.NewString()
+		anyHost := false
+		attached := false
+		for _, l := range group.listeners {
+			if !routeAttachesTo(route, t.gateway, l) {
+				continue
+			}
+			hosts, ok := routeHostnames(l, route)
+			if !ok {
+				continue
+			}
+			attached = true
+			t.result.AttachedRoutes[l.Name]++
+			if len(hosts) == 0 {
+				anyHost = true
+			}
+			hostSet.Insert(hosts...)
+		}
+		if !attached {
+			continue
+		}
+		res.Accepted = true
+		res.Reason, res.Message = "", ""
+
+		var hosts []string
+		if !anyHost {
+			hosts = hostSet.List()
+		}
+		for ri, rule := range route.Rules {
+			actions, err := t.buildRuleActions(ctx, route, rule, res)
+			if err != nil {
+				res.Accepted = false
+				res.Reason = "UnsupportedValue"
+				res.Message = err.Error()
+				break
+			}
+			matches := rule.Matches
+			if len(matches) == 0 {
+				matches = []HTTPRouteMatch{{}}
+			}
+			for mi, match := range matches {
+				conditions, err := buildRuleConditions(hosts, match)
+				if err != nil {
+					res.Accepted = false
+					res.Reason = "UnsupportedValue"
+					res.Message = err.Error()
+					break
+				}
+				candidates = append(candidates, &ruleCandidate{
+					route:      route,
+					ruleIndex:  ri,
+					matchIndex: mi,
+					match:      match,
+					conditions: conditions,
+					actions:    actions,
+				})
+			}
+		}
+	}
+
+	// drop the rules of routes rejected half way through
+	var accepted []*ruleCandidate
+	for _, c := range candidates {
+		if t.result.Routes[routeResultKey(c.route)].Accepted {
+			accepted = append(accepted, c)
+		}
+	}
+	sortRuleCandidates(accepted)
+	if len(accepted) > MaxListenerRulePriority {
+		return fmt.Errorf("port %d needs %d rules, exceeding the alb limit %d", group.port, len(accepted), MaxListenerRulePriority)
+	}
+
+	for i, c := range accepted {
+		priority := i + 1
+		spec := alb.ListenerRuleSpec{ListenerID: ls.ListenerID()}
+		spec.Priority = priority
+		spec.RuleName = fmt.Sprintf("%v-%v-%v", ListenerRuleNamePrefix, group.port, priority)
+		spec.RuleConditions = c.conditions
+		spec.RuleActions = c.actions
+		_ = alb.NewListenerRule(t.stack, fmt.Sprintf("%v:%v", group.port, priority), spec)
+	}
+	return nil
+}
+
+func routeAttachesTo(route *routeContext, gw *Gateway, ls *Listener) bool {
+	for _, ref := range route.ParentRefs {
+		if parentRefMatches(ref, route.Namespace, gw, ls) && listenerAllowsRoute(ls, gw, route) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortRuleCandidates orders rules following the Gateway API precedence: exact paths,
+// then longer prefixes, then method matches, then more header and query matches,
+// then the oldest route and its rule order.
+func sortRuleCandidates(candidates []*ruleCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if pa, pb := pathMatchRank(a.match), pathMatchRank(b.match); pa != pb {
+			return pa > pb
+		}
+		if la, lb := pathLength(a.match), pathLength(b.match); la != lb {
+			return la > lb
+		}
+		if ma, mb := a.match.Method != nil, b.match.Method != nil; ma != mb {
+			return ma
+		}
+		if len(a.match.Headers) != len(b.match.Headers) {
+			return len(a.match.Headers) > len(b.match.Headers)
+		}
+		if len(a.match.QueryParams) != len(b.match.QueryParams) {
+			return len(a.match.QueryParams) > len(b.match.QueryParams)
+		}
+		if !a.route.CreationTimestamp.Equal(&b.route.CreationTimestamp) {
+			return a.route.CreationTimestamp.Before(&b.route.CreationTimestamp)
+		}
+		if ka, kb := routeResultKey(a.route), routeResultKey(b.route); ka != kb {
+			return ka < kb
+		}
+		if a.ruleIndex != b.ruleIndex {
+			return a.ruleIndex < b.ruleIndex
+		}
+		return a.matchIndex < b.matchIndex
+	})
+}
+
+func pathMatchRank(m HTTPRouteMatch) int {
+	if m.Path == nil || m.Path.Type == nil {
+		return 0
+	}
+	switch *m.Path.Type {
+	case PathMatchExact:
+		return 2
+	case PathMatchRegularExpression:
+		return 1
+	}
+	return 0
+}
+
+func pathLength(m HTTPRouteMatch) int {
+	if m.Path == nil || m.Path.Value == nil {
+		return 0
+	}
+	return len(*m.Path.Value)
+}
+
+func buildRuleConditions(hosts []string, match HTTPRouteMatch) ([]alb.Condition, error) {
+	var conditions []alb.Condition
+	if len(hosts) != 0 {
+		conditions = append(conditions, alb.Condition{
+			Type:       util.RuleConditionFieldHost,
+			HostConfig: alb.HostConfig{Values: hosts},
+		})
+	}
+
+	paths, err := buildPathPatterns(match.Path)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) != 0 {
+		conditions = append(conditions, alb.Condition{
+			Type:       util.RuleConditionFieldPath,
+			PathConfig: alb.PathConfig{Values: paths},
+		})
+	}
+
+	for _, h := range match.Headers {
+		if h.Type != nil && *h.Type != HeaderMatchExact {
+			return nil, fmt.Errorf("header match type %s is not supported", *h.Type)
+		}
+		conditions = append(conditions, alb.Condition{
+			Type:         util.RuleConditionFieldHeader,
+			HeaderConfig: alb.HeaderConfig{Key: h.Name, Values: []string{h.Value}},
+		})
+	}
+
+	if len(match.QueryParams) != 0 {
+		var values []alb.Value
+		for _, q := range match.QueryParams {
+			if q.Type != nil && *q.Type != HeaderMatchExact {
+				return nil, fmt.Errorf("query param match type %s is not supported", *q.Type)
+			}
+			values = append(values, alb.Value{Key: q.Name, Value: q.Value})
+		}
+		conditions = append(conditions, alb.Condition{
+			Type:              util.RuleConditionFieldQueryString,
+			QueryStringConfig: alb.QueryStringConfig{Values: values},
+		})
+	}
+
+	if match.Method != nil && *match.Method != "" {
+		conditions = append(conditions, alb.Condition{
+			Type:         util.RuleConditionFieldMethod,
+			MethodConfig: alb.MethodConfig{Values: []string{*match.Method}},
+		})
+	}
+
+	if len(conditions) == 0 {
+		// ALB rules need at least one condition, match every path
+		conditions = append(conditions, alb.Condition{
+			Type:       util.RuleConditionFieldPath,
+			PathConfig: alb.PathConfig{Values: []string{"/*"}},
+		})
+	}
+	return conditions, nil
+}
+
+func buildPathPatterns(path *HTTPPathMatch) ([]string, error) {
+	if path == nil || path.Value == nil {
+		return nil, nil
+	}
+	pathType := PathMatchPathPrefix
+	if path.Type != nil {
+		pathType = *path.Type
+	}
+	value := *path.Value
+	switch pathType {
+	case PathMatchExact:
+		if strings.ContainsAny(value, "*?") {
+			return nil, errors.Errorf("exact path shouldn't contain wildcards: %v", value)
+		}
+		return []string{value}, nil
+	case PathMatchPathPrefix:
+		if value == "/" {
+			return []string{"/*"}, nil
+		}
+		if strings.ContainsAny(value, "*?") {
+			return nil, errors.Errorf("prefix path shouldn't contain wildcards: %v", value)
+		}
+		normalized := strings.TrimSuffix(value, "/")
+		return []string{normalized, normalized + "/*"}, nil
+	default:
+		return nil, errors.Errorf("path match type %s is not supported", pathType)
+	}
+}
+
+// buildRuleActions turns the rule filters into the actions run in front of the final
+// action of the rule. A RequestRedirect filter replaces the forward to the backends.
+func (t *defaultModelBuildTask) buildRuleActions(ctx context.Context, route *routeContext, rule HTTPRouteRule, res *RouteResult) ([]alb.Action, error) {
+	var actions []alb.Action
+	redirected := false
+	for _, f := range rule.Filters {
+		switch f.Type {
+		case FilterRequestRedirect:
+			if f.RequestRedirect == nil {
+				return nil, fmt.Errorf("filter %s without config", f.Type)
+			}
+			actions = append(actions, buildRedirectAction(f.RequestRedirect))
+			redirected = true
+		case FilterRequestHeaderModifier:
+			if f.RequestHeaderModifier == nil {
+				return nil, fmt.Errorf("filter %s without config", f.Type)
+			}
+			actions = append(actions, buildHeaderModifierActions(f.RequestHeaderModifier)...)
+		case FilterURLRewrite:
+			if f.URLRewrite == nil {
+				return nil, fmt.Errorf("filter %s without config", f.Type)
+			}
+			action, err := buildURLRewriteAction(f.URLRewrite)
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, action)
+		case FilterRequestMirror:
+			if f.RequestMirror == nil {
+				return nil, fmt.Errorf("filter %s without config", f.Type)
+			}
+			action, err := t.buildMirrorAction(ctx, route, f.RequestMirror)
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, action)
+		default:
+			return nil, fmt.Errorf("filter %s is not supported", f.Type)
+		}
+	}
+
+	if !redirected {
+		forward, err := t.buildForwardAction(ctx, route, rule.BackendRefs, res)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, forward)
+	}
+	return albconfigmanager.SortAndValidateRuleActions(actions)
+}
+
+// buildHeaderModifierActions maps set to InsertHeader actions overwriting the header,
+// add to InsertHeader actions keeping it, and remove to RemoveHeader actions.
+func buildHeaderModifierActions(m *HTTPHeaderFilter) []alb.Action {
+	var actions []alb.Action
+	insert := func(h HTTPHeader, cover bool) {
+		actions = append(actions, alb.Action{
+			Type: util.RuleActionTypeInsertHeader,
+			InsertHeaderConfig: &alb.InsertHeaderConfig{
+				CoverEnabled: cover,
+				Key:          h.Name,
+				Value:        h.Value,
+				ValueType:    albconfigmanager.InsertHeaderValueTypeUserDefined,
+			},
+		})
+	}
+	for _, h := range m.Set {
+		insert(h, true)
+	}
+	for _, h := range m.Add {
+		insert(h, false)
+	}
+	for _, name := range m.Remove {
+		actions = append(actions, alb.Action{
+			Type:               util.RuleActionTypeRemoveHeader,
+			RemoveHeaderConfig: &alb.RemoveHeaderConfig{Key: name},
+		})
+	}
+	return actions
+}
+
+// buildURLRewriteAction rewrites the host and the full path, ALB has no prefix replacement.
+func buildURLRewriteAction(r *HTTPURLRewriteFilter) (alb.Action, error) {
+	cfg := &alb.RewriteConfig{}
+	if r.Hostname != nil {
+		cfg.Host = *r.Hostname
+	}
+	if r.Path != nil {
+		if r.Path.Type != FullPathHTTPPathModifier || r.Path.ReplaceFullPath == nil {
+			return alb.Action{}, fmt.Errorf("filter %s path modifier %s is not supported", FilterURLRewrite, r.Path.Type)
+		}
+		cfg.Path = *r.Path.ReplaceFullPath
+	}
+	if cfg.Host == "" && cfg.Path == "" {
+		return alb.Action{}, fmt.Errorf("filter %s should set hostname or path", FilterURLRewrite)
+	}
+	return alb.Action{Type: util.RuleActionTypeRewrite, RewriteConfig: cfg}, nil
+}
+
+// buildMirrorAction mirrors the requests to a Service of the route namespace.
+func (t *defaultModelBuildTask) buildMirrorAction(ctx context.Context, route *routeContext, m *HTTPRequestMirror) (alb.Action, error) {
+	port, err := t.resolveBackendRef(ctx, route, m.BackendRef)
+	if err != nil {
+		return alb.Action{}, fmt.Errorf("filter %s backend %s: %s", FilterRequestMirror, m.BackendRef.Name, err.Error())
+	}
+	sgp, err := t.buildServerGroup(ctx, route.Key(), route.Namespace, m.BackendRef.Name, port, route.GRPC)
+	if err != nil {
+		return alb.Action{}, err
+	}
+	return alb.Action{
+		Type: util.RuleActionTypeTrafficMirror,
+		TrafficMirrorConfig: &alb.TrafficMirrorConfig{
+			TargetType: albconfigmanager.TrafficMirrorTargetType,
+			MirrorGroupConfig: alb.MirrorGroupConfig{
+				ServerGroupTuples: []alb.ServerGroupTuple{{ServerGroupID: sgp.ServerGroupID()}},
+			},
+		},
+	}, nil
+}
+
+func buildRedirectAction(r *HTTPRequestRedirect) alb.Action {
+	cfg := &alb.RedirectConfig{
+		Host:     "${host}",
+		Path:     "${path}",
+		Query:    "${query}",
+		Protocol: "${protocol}",
+		Port:     "${port}",
+		HttpCode: "302",
+	}
+	if r.Scheme != nil {
+		cfg.Protocol = strings.ToUpper(*r.Scheme)
+		if r.Port == nil {
+			cfg.Port = "80"
+			if cfg.Protocol == ProtocolHTTPS {
+				cfg.Port = "443"
+			}
+		}
+	}
+	if r.Hostname != nil {
+		cfg.Host = *r.Hostname
+	}
+	if r.Port != nil {
+		cfg.Port = strconv.Itoa(int(*r.Port))
+	}
+	if r.StatusCode != nil {
+		cfg.HttpCode = strconv.Itoa(*r.StatusCode)
+	}
+	if r.Path != nil && r.Path.Type == FullPathHTTPPathModifier && r.Path.ReplaceFullPath != nil {
+		cfg.Path = *r.Path.ReplaceFullPath
+	}
+	return alb.Action{Type: util.RuleActionTypeRedirect, RedirectConfig: cfg}
+}
+
+// buildForwardAction forwards to the route backends with their weights scaled to
+// the 0-100 range ALB accepts. Backends which cannot be resolved are reported in the
+// route result and answered with a 500, as the Gateway API requires.
+func (t *defaultModelBuildTask) buildForwardAction(ctx context.Context, route *routeContext, refs []BackendRef, res *RouteResult) (alb.Action, error) {
+	type weighted struct {
+		sgp    *alb.ServerGroup
+		weight int64
+	}
+	var backends []weighted
+	var total int64
+	for _, ref := range refs {
+		port, err := t.resolveBackendRef(ctx, route, ref)
+		if err != nil {
+			res.UnresolvedRefs = append(res.UnresolvedRefs, fmt.Sprintf("%s: %s", ref.Name, err.Error()))
+			if res.RefReason == "" {
+				res.RefReason = backendRefReason(err)
+			}
+			continue
+		}
+		weight := int64(1)
+		if ref.Weight != nil {
+			weight = int64(*ref.Weight)
+		}
+		sgp, err := t.buildServerGroup(ctx, route.Key(), route.Namespace, ref.Name, port, route.GRPC)
+		if err != nil {
+			return alb.Action{}, err
+		}
+		backends = append(backends, weighted{sgp: sgp, weight: weight})
+		total += weight
+	}
+
+	if total == 0 {
+		return alb.Action{
+			Type: util.RuleActionTypeFixedResponse,
+			FixedResponseConfig: &alb.FixedResponseConfig{
+				ContentType: "text/plain",
+				HttpCode:    noBackendHTTPCode,
+			},
+		}, nil
+	}
+
+	// the rounding remainder goes to the last weighted backend so the weights add up to 100
+	var tuples []alb.ServerGroupTuple
+	last, sum := -1, 0
+	for i, b := range backends {
+		weight := int(b.weight * 100 / total)
+		tuples = append(tuples, alb.ServerGroupTuple{
+			ServerGroupID: b.sgp.ServerGroupID(),
+			Weight:        weight,
+		})
+		sum += weight
+		if b.weight > 0 {
+			last = i
+		}
+	}
+	if last >= 0 {
+		tuples[last].Weight += 100 - sum
+	}
+	return alb.Action{
+		Type:          util.RuleActionTypeForward,
+		ForwardConfig: &alb.ForwardActionConfig{ServerGroups: tuples},
+	}, nil
+}
+
+type backendRefError struct {
+	reason string
+	msg    string
+}
+
+func (e *backendRefError) Error() string {
+	return e.msg
+}
+
+func backendRefReason(err error) string {
+	var refErr *backendRefError
+	if errors.As(err, &refErr) {
+		return refErr.reason
+	}
+	return "BackendNotFound"
+}
+
+// resolveBackendRef checks the backend is a Service of the route namespace and returns its port.
+// Cross namespace references need a ReferenceGrant, which is not supported.
+func (t *defaultModelBuildTask) resolveBackendRef(ctx context.Context, route *routeContext, ref BackendRef) (int, error) {
+	if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != KindService) {
+		return 0, &backendRefError{reason: "InvalidKind", msg: "only core Service backends are supported"}
+	}
+	if ref.Namespace != nil && *ref.Namespace != route.Namespace {
+		return 0, &backendRefError{reason: "RefNotPermitted", msg: "cross namespace backend is not supported"}
+	}
+	if ref.Port == nil {
+		return 0, &backendRefError{reason: "UnsupportedValue", msg: "backend port is required"}
+	}
+	key := types.NamespacedName{Namespace: route.Namespace, Name: ref.Name}
+	svc, ok := t.services[key]
+	if !ok {
+		svc = &corev1.Service{}
+		if err := t.kubeClient.Get(ctx, key, svc); err != nil {
+			return 0, &backendRefError{reason: "BackendNotFound", msg: err.Error()}
+		}
+		t.services[key] = svc
+	}
+	for _, p := range svc.Spec.Ports {
+		if p.Port == *ref.Port {
+			return int(p.Port), nil
+		}
+	}
+	return 0, &backendRefError{reason: "BackendNotFound", msg: fmt.Sprintf("service has no port %d", *ref.Port)}
+}
+
+func (t *defaultModelBuildTask) buildServerGroupResourceID(routeKey, namespace, svcName string, port int) string {
+	resourceID := fmt.Sprintf("%s/%s-%s:%v", namespace, routeKey, svcName, port)
+	uuidHash := sha256.New()
+	_, _ = uuidHash.Write([]byte(resourceID))
+	return hex.EncodeToString(uuidHash.Sum(nil))
+}
+
+func (t *defaultModelBuildTask) buildServerGroup(_ context.Context, routeKey, namespace, svcName string, port int, grpc bool) (*alb.ServerGroup, error) {
+	resID := t.buildServerGroupResourceID(routeKey, namespace, svcName, port)
+	if sgp, ok := t.sgpByResID[resID]; ok {
+		return sgp, nil
+	}
+
+	var spec alb.ServerGroupSpec
+	spec.ServerGroupNamedKey = alb.ServerGroupNamedKey{
+		ClusterID:   t.clusterID,
+		Namespace:   namespace,
+		IngressName: routeKey,
+		ServiceName: svcName,
+		ServicePort: port,
+	}
+	spec.Tags = []alb.ALBTag{
+		{Key: util.ServiceNamespaceTagKey, Value: namespace},
+		{Key: util.IngressNameTagKey, Value: routeKey},
+		{Key: util.ServiceNameTagKey, Value: svcName},
+		{Key: util.ServicePortTagKey, Value: fmt.Sprintf("%v", port)},
+	}
+	spec.ServerGroupName = fmt.Sprintf("%s-%s-%v", namespace, svcName, port)
+	spec.Scheduler = util.DefaultServerGroupScheduler
+	spec.Protocol = util.DefaultServerGroupProtocol
+	if grpc {
+		spec.Protocol = util.ServerGroupProtocolGRPC
+	}
+	spec.ServerGroupType = util.DefaultServerGroupType
+	spec.VpcId = t.vpcID
+	spec.HealthCheckConfig = alb.HealthCheckConfig{
+		HealthCheckConnectPort: util.DefaultServerGroupHealthCheckConnectPort,
+		HealthCheckEnabled:     util.DefaultServerGroupHealthCheckEnabled,
+		HealthCheckHost:        util.DefaultServerGroupHealthCheckHost,
+		HealthCheckHttpVersion: util.DefaultServerGroupHealthCheckHttpVersion,
+		HealthCheckInterval:    util.DefaultServerGroupHealthCheckInterval,
+		HealthCheckMethod:      util.DefaultServerGroupHealthCheckMethod,
+		HealthCheckPath:        util.DefaultServerGroupHealthCheckPath,
+		HealthCheckProtocol:    util.DefaultServerGroupHealthCheckProtocol,
+		HealthCheckTimeout:     util.DefaultServerGroupHealthCheckTimeout,
+		HealthyThreshold:       util.DefaultServerGroupHealthyThreshold,
+		UnhealthyThreshold:     util.DefaultServerGroupUnhealthyThreshold,
+		HealthCheckHttpCodes:   []string{util.DefaultServerGroupHealthCheckHTTPCodes},
+		HealthCheckCodes:       []string{util.DefaultServerGroupHealthCheckCodes},
+	}
+	spec.StickySessionConfig = alb.StickySessionConfig{
+		CookieTimeout:        util.DefaultServerGroupStickySessionCookieTimeout,
+		StickySessionEnabled: util.DefaultServerGroupStickySessionEnabled,
+		StickySessionType:    util.DefaultServerGroupStickySessionType,
+	}
+
+	sgp := alb.NewServerGroup(t.stack, resID, spec)
+	t.sgpByResID[resID] = sgp
+	return sgp, nil
+}
+
+func stringWithDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
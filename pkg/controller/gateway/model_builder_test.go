@@ -0,0 +1,156 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestBuildPathPatterns(t *testing.T) {
+	cases := []struct {
+		name   string
+		path   *HTTPPathMatch
+		expect []string
+		err    bool
+	}{
+		{name: "nil", path: nil, expect: nil},
+		{name: "root prefix", path: &HTTPPathMatch{Value: strPtr("/")}, expect: []string{"/*"}},
+		{name: "prefix", path: &HTTPPathMatch{Type: strPtr(PathMatchPathPrefix), Value: strPtr("/foo/")}, expect: []string{"/foo", "/foo/*"}},
+		{name: "exact", path: &HTTPPathMatch{Type: strPtr(PathMatchExact), Value: strPtr("/foo")}, expect: []string{"/foo"}},
+		{name: "regex", path: &HTTPPathMatch{Type: strPtr(PathMatchRegularExpression), Value: strPtr("/f.*")}, err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			paths, err := buildPathPatterns(c.path)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expect, paths)
+		})
+	}
+}
+
+func TestBuildRuleConditions(t *testing.T) {
+	conditions, err := buildRuleConditions([]string{"foo.example.com"}, HTTPRouteMatch{
+		Path:        &HTTPPathMatch{Type: strPtr(PathMatchExact), Value: strPtr("/api")},
+		Headers:     []HTTPHeaderMatch{{Name: "x-env", Value: "canary"}},
+		QueryParams: []HTTPQueryParamMatch{{Name: "v", Value: "2"}},
+		Method:      strPtr("GET"),
+	})
+	assert.NoError(t, err)
+	var types []string
+	for _, c := range conditions {
+		types = append(types, c.Type)
+	}
+	assert.Equal(t, []string{
+		util.RuleConditionFieldHost,
+		util.RuleConditionFieldPath,
+		util.RuleConditionFieldHeader,
+		util.RuleConditionFieldQueryString,
+		util.RuleConditionFieldMethod,
+	}, types)
+
+	conditions, err = buildRuleConditions(nil, HTTPRouteMatch{})
+	assert.NoError(t, err)
+	assert.Len(t, conditions, 1)
+	assert.Equal(t, []string{"/*"}, conditions[0].PathConfig.Values)
+}
+
+func TestSortRuleCandidates(t *testing.T) {
+	older := &routeContext{Kind: KindHTTPRoute, Namespace: "default", Name: "b",
+		CreationTimestamp: metav1.NewTime(time.Unix(100, 0))}
+	newer := &routeContext{Kind: KindHTTPRoute, Namespace: "default", Name: "a",
+		CreationTimestamp: metav1.NewTime(time.Unix(200, 0))}
+
+	prefix := func(route *routeContext, v string) *ruleCandidate {
+		return &ruleCandidate{route: route, match: HTTPRouteMatch{Path: &HTTPPathMatch{Type: strPtr(PathMatchPathPrefix), Value: strPtr(v)}}}
+	}
+	exact := &ruleCandidate{route: newer, match: HTTPRouteMatch{Path: &HTTPPathMatch{Type: strPtr(PathMatchExact), Value: strPtr("/a")}}}
+	short := prefix(older, "/a")
+	long := prefix(newer, "/a/b")
+	withHeader := prefix(newer, "/a")
+	withHeader.match.Headers = []HTTPHeaderMatch{{Name: "x", Value: "y"}}
+	sameButNewer := prefix(newer, "/a")
+
+	candidates := []*ruleCandidate{sameButNewer, short, withHeader, long, exact}
+	sortRuleCandidates(candidates)
+	assert.Equal(t, []*ruleCandidate{exact, long, withHeader, short, sameButNewer}, candidates)
+}
+
+func TestRouteHostnames(t *testing.T) {
+	ls := &Listener{Name: "http", Hostname: strPtr("*.example.com")}
+
+	hosts, ok := routeHostnames(ls, &routeContext{})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"*.example.com"}, hosts)
+
+	hosts, ok = routeHostnames(ls, &routeContext{Hostnames: []string{"foo.example.com", "foo.other.com"}})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"foo.example.com"}, hosts)
+
+	_, ok = routeHostnames(ls, &routeContext{Hostnames: []string{"example.com"}})
+	assert.False(t, ok)
+}
+
+func TestGRPCMatchToHTTPMatch(t *testing.T) {
+	m := grpcMatchToHTTPMatch(GRPCRouteMatch{Method: &GRPCMethodMatch{Service: strPtr("pkg.Svc"), Method: strPtr("Get")}})
+	assert.Equal(t, PathMatchExact, *m.Path.Type)
+	assert.Equal(t, "/pkg.Svc/Get", *m.Path.Value)
+
+	m = grpcMatchToHTTPMatch(GRPCRouteMatch{Method: &GRPCMethodMatch{Service: strPtr("pkg.Svc")}})
+	assert.Equal(t, PathMatchPathPrefix, *m.Path.Type)
+	assert.Equal(t, "/pkg.Svc", *m.Path.Value)
+}
+
+func TestListenerAllowsRoute(t *testing.T) {
+	gw := &Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "gw"}}
+	same := &Listener{Name: "http", Port: 80, Protocol: ProtocolHTTP}
+	all := &Listener{Name: "https", Port: 443, Protocol: ProtocolHTTPS,
+		AllowedRoutes: &AllowedRoutes{Namespaces: &RouteNamespaces{From: strPtr("All")}}}
+
+	route := &routeContext{Kind: KindHTTPRoute, Namespace: "app"}
+	assert.False(t, listenerAllowsRoute(same, gw, route))
+	assert.True(t, listenerAllowsRoute(all, gw, route))
+
+	grpc := &routeContext{Kind: KindGRPCRoute, Namespace: "app"}
+	assert.True(t, listenerAllowsRoute(all, gw, grpc))
+
+	ref := ParentReference{Name: "gw", Namespace: strPtr("infra"), SectionName: strPtr("https")}
+	assert.True(t, parentRefMatches(ref, "app", gw, all))
+	assert.False(t, parentRefMatches(ref, "app", gw, same))
+}
+
+func TestBuildHeaderModifierActions(t *testing.T) {
+	actions := buildHeaderModifierActions(&HTTPHeaderFilter{
+		Set:    []HTTPHeader{{Name: "x-set", Value: "1"}},
+		Add:    []HTTPHeader{{Name: "x-add", Value: "2"}},
+		Remove: []string{"x-remove"},
+	})
+	assert.Len(t, actions, 3)
+	assert.True(t, actions[0].InsertHeaderConfig.CoverEnabled)
+	assert.False(t, actions[1].InsertHeaderConfig.CoverEnabled)
+	assert.Equal(t, util.RuleActionTypeRemoveHeader, actions[2].Type)
+	assert.Equal(t, "x-remove", actions[2].RemoveHeaderConfig.Key)
+}
+
+func TestBuildURLRewriteAction(t *testing.T) {
+	action, err := buildURLRewriteAction(&HTTPURLRewriteFilter{
+		Hostname: strPtr("foo.example.com"),
+		Path:     &HTTPPathModifier{Type: FullPathHTTPPathModifier, ReplaceFullPath: strPtr("/bar")},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "foo.example.com", action.RewriteConfig.Host)
+	assert.Equal(t, "/bar", action.RewriteConfig.Path)
+
+	_, err = buildURLRewriteAction(&HTTPURLRewriteFilter{
+		Path: &HTTPPathModifier{Type: PrefixMatchHTTPPathModifier, ReplacePrefixMatch: strPtr("/bar")},
+	})
+	assert.Error(t, err)
+}
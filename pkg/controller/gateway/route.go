@@ -0,0 +1,218 @@
+package gateway
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	PathMatchExact             = "Exact"
+	PathMatchPathPrefix        = "PathPrefix"
+	PathMatchRegularExpression = "RegularExpression"
+
+	HeaderMatchExact = "Exact"
+
+	FilterRequestHeaderModifier = "RequestHeaderModifier"
+	FilterRequestRedirect       = "RequestRedirect"
+	FilterURLRewrite            = "URLRewrite"
+	FilterRequestMirror         = "RequestMirror"
+
+	FullPathHTTPPathModifier    = "ReplaceFullPath"
+	PrefixMatchHTTPPathModifier = "ReplacePrefixMatch"
+)
+
+// routeContext is the protocol independent view of an HTTPRoute or a GRPCRoute
+// used by the model builder. GRPCRoute method matches are turned into path matches,
+// since gRPC requests are plain HTTP/2 POSTs to /{service}/{method}.
+type routeContext struct {
+	Kind              string
+	Namespace         string
+	Name              string
+	Generation        int64
+	CreationTimestamp metav1.Time
+
+	ParentRefs []ParentReference
+	Hostnames  []string
+	Rules      []HTTPRouteRule
+
+	// GRPC marks routes whose backends must speak gRPC.
+	GRPC bool
+
+	Status RouteStatus
+}
+
+func (r *routeContext) NamespacedName() types.NamespacedName {
+	return types.NamespacedName{Namespace: r.Namespace, Name: r.Name}
+}
+
+// Key identifies the route among the server groups and tags of the ALB stack.
+func (r *routeContext) Key() string {
+	return fmt.Sprintf("%s-%s", strings.ToLower(r.Kind), r.Name)
+}
+
+func (r *routeContext) String() string {
+	return fmt.Sprintf("%s %s/%s", r.Kind, r.Namespace, r.Name)
+}
+
+func newHTTPRouteContext(u *unstructured.Unstructured) (*routeContext, error) {
+	route := &HTTPRoute{}
+	if err := fromUnstructured(u, route); err != nil {
+		return nil, fmt.Errorf("decode %s %s/%s error: %s", KindHTTPRoute, u.GetNamespace(), u.GetName(), err.Error())
+	}
+	return &routeContext{
+		Kind:              KindHTTPRoute,
+		Namespace:         route.Namespace,
+		Name:              route.Name,
+		Generation:        route.Generation,
+		CreationTimestamp: route.CreationTimestamp,
+		ParentRefs:        route.Spec.ParentRefs,
+		Hostnames:         route.Spec.Hostnames,
+		Rules:             route.Spec.Rules,
+		Status:            route.Status,
+	}, nil
+}
+
+func newGRPCRouteContext(u *unstructured.Unstructured) (*routeContext, error) {
+	route := &GRPCRoute{}
+	if err := fromUnstructured(u, route); err != nil {
+		return nil, fmt.Errorf("decode %s %s/%s error: %s", KindGRPCRoute, u.GetNamespace(), u.GetName(), err.Error())
+	}
+	rc := &routeContext{
+		Kind:              KindGRPCRoute,
+		Namespace:         route.Namespace,
+		Name:              route.Name,
+		Generation:        route.Generation,
+		CreationTimestamp: route.CreationTimestamp,
+		ParentRefs:        route.Spec.ParentRefs,
+		Hostnames:         route.Spec.Hostnames,
+		GRPC:              true,
+		Status:            route.Status,
+	}
+	for _, rule := range route.Spec.Rules {
+		httpRule := HTTPRouteRule{BackendRefs: rule.BackendRefs}
+		for _, m := range rule.Matches {
+			httpRule.Matches = append(httpRule.Matches, grpcMatchToHTTPMatch(m))
+		}
+		rc.Rules = append(rc.Rules, httpRule)
+	}
+	return rc, nil
+}
+
+func grpcMatchToHTTPMatch(m GRPCRouteMatch) HTTPRouteMatch {
+	match := HTTPRouteMatch{Headers: m.Headers}
+	if m.Method == nil || m.Method.Service == nil || *m.Method.Service == "" {
+		return match
+	}
+	pathType := PathMatchPathPrefix
+	path := "/" + *m.Method.Service
+	if m.Method.Method != nil && *m.Method.Method != "" {
+		pathType = PathMatchExact
+		path = path + "/" + *m.Method.Method
+	}
+	match.Path = &HTTPPathMatch{Type: &pathType, Value: &path}
+	return match
+}
+
+// parentRefMatches reports whether ref points to the given Gateway listener.
+// An empty listener name only checks the Gateway itself.
+func parentRefMatches(ref ParentReference, routeNamespace string, gw *Gateway, ls *Listener) bool {
+	if ref.Group != nil && *ref.Group != GroupName {
+		return false
+	}
+	if ref.Kind != nil && *ref.Kind != KindGateway {
+		return false
+	}
+	namespace := routeNamespace
+	if ref.Namespace != nil && *ref.Namespace != "" {
+		namespace = *ref.Namespace
+	}
+	if namespace != gw.Namespace || ref.Name != gw.Name {
+		return false
+	}
+	if ls == nil {
+		return true
+	}
+	if ref.SectionName != nil && *ref.SectionName != ls.Name {
+		return false
+	}
+	if ref.Port != nil && *ref.Port != ls.Port {
+		return false
+	}
+	return true
+}
+
+// listenerAllowsRoute applies the listener allowedRoutes namespace and kind policy.
+// Namespace selectors are not supported and never match.
+func listenerAllowsRoute(ls *Listener, gw *Gateway, route *routeContext) bool {
+	if !listenerSupportsKind(ls, route.Kind) {
+		return false
+	}
+	from := "Same"
+	if ls.AllowedRoutes != nil && ls.AllowedRoutes.Namespaces != nil && ls.AllowedRoutes.Namespaces.From != nil {
+		from = *ls.AllowedRoutes.Namespaces.From
+	}
+	switch from {
+	case "All":
+		return true
+	case "Same":
+		return route.Namespace == gw.Namespace
+	default:
+		return false
+	}
+}
+
+func listenerSupportsKind(ls *Listener, kind string) bool {
+	if ls.AllowedRoutes != nil && len(ls.AllowedRoutes.Kinds) != 0 {
+		for _, k := range ls.AllowedRoutes.Kinds {
+			if k.Kind == kind && (k.Group == nil || *k.Group == GroupName) {
+				return true
+			}
+		}
+		return false
+	}
+	switch ls.Protocol {
+	case ProtocolHTTP:
+		return kind == KindHTTPRoute
+	case ProtocolHTTPS:
+		return kind == KindHTTPRoute || kind == KindGRPCRoute
+	}
+	return false
+}
+
+// routeHostnames intersects the route hostnames with the listener hostname.
+// A nil result with ok=true means any host; ok=false means the route does not attach.
+func routeHostnames(ls *Listener, route *routeContext) ([]string, bool) {
+	if ls.Hostname == nil || *ls.Hostname == "" {
+		return route.Hostnames, true
+	}
+	if len(route.Hostnames) == 0 {
+		return []string{*ls.Hostname}, true
+	}
+	var hosts []string
+	for _, h := range route.Hostnames {
+		switch {
+		case hostnameMatches(*ls.Hostname, h):
+			hosts = append(hosts, h)
+		case hostnameMatches(h, *ls.Hostname):
+			hosts = append(hosts, *ls.Hostname)
+		}
+	}
+	return hosts, len(hosts) != 0
+}
+
+// hostnameMatches reports whether host is covered by pattern, where pattern may
+// carry a single leading "*." wildcard label.
+func hostnameMatches(pattern, host string) bool {
+	if pattern == host {
+		return true
+	}
+	if strings.HasPrefix(pattern, "*.") {
+		suffix := pattern[1:]
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix) && !strings.HasPrefix(host, "*.")
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ConditionAccepted     = "Accepted"
+	ConditionProgrammed   = "Programmed"
+	ConditionResolvedRefs = "ResolvedRefs"
+
+	ReasonAccepted     = "Accepted"
+	ReasonProgrammed   = "Programmed"
+	ReasonPending      = "Pending"
+	ReasonInvalid      = "Invalid"
+	ReasonResolvedRefs = "ResolvedRefs"
+
+	AddressTypeHostname = "Hostname"
+)
+
+// setCondition sets the condition of the given type and reports whether it changed.
+func setCondition(conditions *[]metav1.Condition, generation int64, conditionType string, status bool, reason, message string) bool {
+	s := metav1.ConditionFalse
+	if status {
+		s = metav1.ConditionTrue
+	}
+	old := meta.FindStatusCondition(*conditions, conditionType)
+	if old != nil && old.Status == s && old.Reason == reason &&
+		old.Message == message && old.ObservedGeneration == generation {
+		return false
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             s,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	return true
+}
+
+// updateStatus writes status into the status subresource of the unstructured object.
+func updateStatus(ctx context.Context, kubeClient client.Client, u *unstructured.Unstructured, status interface{}) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := newUnstructured(u.GroupVersionKind())
+		if err := kubeClient.Get(ctx, types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetName()}, latest); err != nil {
+			return err
+		}
+		if reflect.DeepEqual(latest.Object["status"], content) {
+			return nil
+		}
+		latest.Object["status"] = content
+		return kubeClient.Status().Update(ctx, latest)
+	})
+}
+
+func (r *ReconcileGateway) updateGatewayStatus(ctx context.Context, u *unstructured.Unstructured, gw *Gateway,
+	lb *albmodel.AlbLoadBalancer, result *BuildResult, applyErr error) error {
+	status := gw.Status
+	if lb != nil && lb.Status != nil && lb.Status.DNSName != "" {
+		addrType := AddressTypeHostname
+		status.Addresses = []GatewayStatusAddress{{Type: &addrType, Value: lb.Status.DNSName}}
+	}
+
+	setCondition(&status.Conditions, gw.Generation, ConditionAccepted, true, ReasonAccepted, "")
+	switch {
+	case applyErr != nil:
+		setCondition(&status.Conditions, gw.Generation, ConditionProgrammed, false, ReasonInvalid, applyErr.Error())
+	case len(status.Addresses) == 0:
+		setCondition(&status.Conditions, gw.Generation, ConditionProgrammed, false, ReasonPending, "waiting for the alb address")
+	default:
+		setCondition(&status.Conditions, gw.Generation, ConditionProgrammed, true, ReasonProgrammed, "")
+	}
+
+	oldListeners := make(map[string]ListenerStatus)
+	for _, ls := range gw.Status.Listeners {
+		oldListeners[ls.Name] = ls
+	}
+	status.Listeners = nil
+	for i := range gw.Spec.Listeners {
+		ls := &gw.Spec.Listeners[i]
+		lsStatus := ListenerStatus{Name: ls.Name, Conditions: oldListeners[ls.Name].Conditions}
+		if lsStatus.Conditions == nil {
+			lsStatus.Conditions = []metav1.Condition{}
+		}
+		for _, kind := range []string{KindHTTPRoute, KindGRPCRoute} {
+			if listenerSupportsKind(ls, kind) {
+				group := GroupName
+				lsStatus.SupportedKinds = append(lsStatus.SupportedKinds, RouteGroupKind{Group: &group, Kind: kind})
+			}
+		}
+		if lsStatus.SupportedKinds == nil {
+			lsStatus.SupportedKinds = []RouteGroupKind{}
+		}
+
+		var lsErr error
+		if result != nil {
+			lsStatus.AttachedRoutes = result.AttachedRoutes[ls.Name]
+			lsErr = result.ListenerErrors[ls.Name]
+		}
+		if lsErr == nil {
+			lsErr = applyErr
+		}
+		if lsErr != nil {
+			setCondition(&lsStatus.Conditions, gw.Generation, ConditionAccepted, false, ReasonInvalid, lsErr.Error())
+			setCondition(&lsStatus.Conditions, gw.Generation, ConditionProgrammed, false, ReasonInvalid, lsErr.Error())
+		} else {
+			setCondition(&lsStatus.Conditions, gw.Generation, ConditionAccepted, true, ReasonAccepted, "")
+			setCondition(&lsStatus.Conditions, gw.Generation, ConditionProgrammed, true, ReasonProgrammed, "")
+		}
+		setCondition(&lsStatus.Conditions, gw.Generation, ConditionResolvedRefs, true, ReasonResolvedRefs, "")
+		status.Listeners = append(status.Listeners, lsStatus)
+	}
+
+	return updateStatus(ctx, r.kubeClient, u, status)
+}
+
+// updateRouteStatus writes one parents entry per parentRef pointing to the Gateway,
+// leaving the entries of other Gateways and controllers untouched.
+func (r *ReconcileGateway) updateRouteStatus(ctx context.Context, gw *Gateway, routes []*routeContext, result *BuildResult) error {
+	var errs []string
+	for _, route := range routes {
+		res := result.Routes[routeResultKey(route)]
+		if res == nil {
+			continue
+		}
+		status := RouteStatus{}
+		for _, p := range route.Status.Parents {
+			if p.ControllerName == ControllerName && parentRefMatches(p.ParentRef, route.Namespace, gw, nil) {
+				continue
+			}
+			status.Parents = append(status.Parents, p)
+		}
+		for _, ref := range route.ParentRefs {
+			if !parentRefMatches(ref, route.Namespace, gw, nil) {
+				continue
+			}
+			parent := RouteParentStatus{ParentRef: ref, ControllerName: ControllerName}
+			for _, p := range route.Status.Parents {
+				if p.ControllerName == ControllerName && reflect.DeepEqual(p.ParentRef, ref) {
+					parent.Conditions = p.Conditions
+				}
+			}
+			if res.Accepted {
+				setCondition(&parent.Conditions, route.Generation, ConditionAccepted, true, ReasonAccepted, "")
+			} else {
+				setCondition(&parent.Conditions, route.Generation, ConditionAccepted, false, res.Reason, res.Message)
+			}
+			if len(res.UnresolvedRefs) != 0 {
+				setCondition(&parent.Conditions, route.Generation, ConditionResolvedRefs, false, res.RefReason,
+					strings.Join(res.UnresolvedRefs, "; "))
+			} else {
+				setCondition(&parent.Conditions, route.Generation, ConditionResolvedRefs, true, ReasonResolvedRefs, "")
+			}
+			status.Parents = append(status.Parents, parent)
+		}
+
+		gvk := HTTPRouteGVK
+		if route.GRPC {
+			gvk = GRPCRouteGVK
+		}
+		u := newUnstructured(gvk)
+		u.SetNamespace(route.Namespace)
+		u.SetName(route.Name)
+		if err := updateStatus(ctx, r.kubeClient, u, status); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", route.String(), err.Error()))
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("update route status error: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
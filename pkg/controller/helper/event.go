@@ -15,9 +15,11 @@ const (
 	FailedRemoveHash          = "FailedRemoveHash"
 	FailedUpdateStatus        = "FailedUpdateStatus"
 	FailedUpdateReadinessGate = "FailedUpdateReadinessGate"
+	FailedSyncDrainFinalizer  = "FailedSyncDrainFinalizer"
 	UnAvailableBackends       = "UnAvailableLoadBalancer"
 	SkipSyncBackends          = "SkipSyncBackends"
 	FailedSyncLB              = "SyncLoadBalancerFailed"
+	ThrottledSyncLB           = "SyncLoadBalancerThrottled"
 	SucceedCleanLB            = "CleanLoadBalancer"
 	FailedCleanLB             = "CleanLoadBalancerFailed"
 	SucceedSyncLB             = "EnsuredLoadBalancer"
@@ -26,6 +28,9 @@ const (
 	SpecChanged               = "ServiceSpecChanged"
 	DeleteTimestampChanged    = "DeleteTimestampChanged"
 	PreservedOnDelete         = "PreservedOnDelete"
+	SharedPortConflict        = "SharedLoadBalancerPortConflict"
+	SharedAttributeConflict   = "SharedLoadBalancerAttributeConflict"
+	ReleasingCLB              = "ReleasingLoadBalancer"
 )
 
 // NodeEventReason
@@ -35,6 +40,8 @@ const (
 	FailedSyncNode    = "SyncNodeFailed"
 	SucceedDeleteNode = "DeletedNode"
 	InitializedNode   = "InitializedNode"
+	ShutdownNode      = "NodeShutdown"
+	SpotInterruption  = "SpotInterruption"
 )
 
 // RouteEventReason
@@ -42,6 +49,8 @@ const (
 	FailedCreateRoute  = "CreateRouteFailed"
 	FailedSyncRoute    = "SyncRouteFailed"
 	SucceedCreateRoute = "CreatedRoute"
+	RouteQuotaWarning  = "RouteTableQuotaNearlyExhausted"
+	UnassignedRoute    = "RouteTableUnassigned"
 )
 
 var re = regexp.MustCompile(".*(Message:.*)")
@@ -0,0 +1,228 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// migration from CLB to NLB
+const (
+	// MigrateToNLB provisions an NLB for the CLB service, both of them serve the service until the cut-over.
+	MigrateToNLB = "service.beta.kubernetes.io/alibaba-cloud-loadbalancer-migrate-to-nlb"
+	// MigrationCutOver releases the CLB once the NLB is ready.
+	MigrationCutOver = "service.beta.kubernetes.io/alibaba-cloud-loadbalancer-migration-cutover"
+	// MigrationSoakPeriod releases the CLB when the NLB has been ready for the period, e.g. 24h.
+	MigrationSoakPeriod = "service.beta.kubernetes.io/alibaba-cloud-loadbalancer-migration-soak-period"
+
+	// LoadBalancerMigrationCondition is the condition of the service reporting the progress of the migration.
+	LoadBalancerMigrationCondition = "LoadBalancerMigration"
+	// MigrationReasonNLBReady means the NLB is ready, the soak period starts at the transition time.
+	MigrationReasonNLBReady = "NLBReady"
+	// MigrationReasonCutOver means the CLB is being released.
+	MigrationReasonCutOver = "CutOver"
+	// MigrationReasonCLBReleased means the migration is finished, the NLB serves the service alone.
+	MigrationReasonCLBReleased = "CLBReleased"
+
+	// LabelMigratedToNLB records that the CLB is released by the migration, so that the service stays with the NLB
+	// even if the migration condition is lost.
+	LabelMigratedToNLB = "service.k8s.alibaba/migrated-to-nlb"
+
+	// MigrationPollInterval is how often the CLB controller checks whether the NLB is ready for the cut-over.
+	MigrationPollInterval = time.Minute
+)
+
+// IsMigratingToNLB returns whether the CLB of the service is being migrated to NLB and is not released yet.
+func IsMigratingToNLB(svc *v1.Service) bool {
+	if svc.Spec.Type != v1.ServiceTypeLoadBalancer || svc.Spec.LoadBalancerClass != nil {
+		return false
+	}
+	return svc.Annotations[MigrateToNLB] == "true" && !isCLBReleased(svc)
+}
+
+// IsMigratedToNLB returns whether the CLB of the service has been released by the migration.
+func IsMigratedToNLB(svc *v1.Service) bool {
+	return svc.Annotations[MigrateToNLB] == "true" && isCLBReleased(svc)
+}
+
+func isCLBReleased(svc *v1.Service) bool {
+	return svc.Labels[LabelMigratedToNLB] == "true" || migrationReason(svc) == MigrationReasonCLBReleased
+}
+
+// MarkMigratedToNLB sets the label recording the release of the CLB on the service.
+func MarkMigratedToNLB(ctx context.Context, kubeClient client.Client, svc *v1.Service) error {
+	if svc.Labels[LabelMigratedToNLB] == "true" {
+		return nil
+	}
+	updated := svc.DeepCopy()
+	if updated.Labels == nil {
+		updated.Labels = map[string]string{}
+	}
+	updated.Labels[LabelMigratedToNLB] = "true"
+	if err := kubeClient.Patch(ctx, updated, client.MergeFrom(svc)); err != nil {
+		return fmt.Errorf("%s failed to add label %s, error: %s", util.Key(svc), LabelMigratedToNLB, err.Error())
+	}
+	svc.Labels = updated.Labels
+	return nil
+}
+
+// NeedReleaseCLB returns whether the migration has reached the cut-over, so that the CLB is deleted.
+func NeedReleaseCLB(svc *v1.Service) bool {
+	return IsMigratingToNLB(svc) && migrationReason(svc) == MigrationReasonCutOver
+}
+
+// IsCutOverDue returns whether the CLB can be released, which requires the NLB to be ready. Otherwise, it
+// returns how long to wait before checking again, or 0 if the cut-over is left to the user.
+func IsCutOverDue(svc *v1.Service, now time.Time) (bool, time.Duration, error) {
+	var soak time.Duration
+	if v := svc.Annotations[MigrationSoakPeriod]; v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return false, 0, fmt.Errorf("parse %s error: %s", MigrationSoakPeriod, err.Error())
+		}
+		soak = d
+	}
+	cutOver := svc.Annotations[MigrationCutOver] == "true"
+	if !cutOver && soak == 0 {
+		return false, 0, nil
+	}
+
+	cond := meta.FindStatusCondition(svc.Status.Conditions, LoadBalancerMigrationCondition)
+	if cond == nil || cond.Reason != MigrationReasonNLBReady {
+		return false, MigrationPollInterval, nil
+	}
+	if cutOver {
+		return true, 0, nil
+	}
+	if left := cond.LastTransitionTime.Add(soak).Sub(now); left > 0 {
+		return false, left, nil
+	}
+	return true, 0, nil
+}
+
+func migrationReason(svc *v1.Service) string {
+	cond := meta.FindStatusCondition(svc.Status.Conditions, LoadBalancerMigrationCondition)
+	if cond == nil {
+		return ""
+	}
+	return cond.Reason
+}
+
+// MergeMigrationIngress keeps the ingress of the other load balancer in the current status after
+// the own ones, so that both addresses are published during the migration.
+func MergeMigrationIngress(own, current []v1.LoadBalancerIngress, isOwn func(v1.LoadBalancerIngress) bool) []v1.LoadBalancerIngress {
+	ret := append([]v1.LoadBalancerIngress{}, own...)
+	for _, ing := range current {
+		if !isOwn(ing) {
+			ret = append(ret, ing)
+		}
+	}
+	return ret
+}
+
+// SetMigrationCondition sets the migration condition of the service, the service is updated with the
+// new conditions as well. The transition time is kept if the reason does not change.
+func SetMigrationCondition(ctx context.Context, kubeClient client.Client, svc *v1.Service, reason, message string) error {
+	cond := meta.FindStatusCondition(svc.Status.Conditions, LoadBalancerMigrationCondition)
+	if cond != nil && cond.Reason == reason && cond.Message == message {
+		return nil
+	}
+	return PatchServiceConditions(ctx, kubeClient, svc, func(conds *[]metav1.Condition) {
+		old := meta.FindStatusCondition(*conds, LoadBalancerMigrationCondition)
+		if old != nil && old.Reason != reason {
+			// the soak period starts when the reason changes to NLBReady
+			meta.RemoveStatusCondition(conds, LoadBalancerMigrationCondition)
+		}
+		meta.SetStatusCondition(conds, metav1.Condition{
+			Type:    LoadBalancerMigrationCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  reason,
+			Message: message,
+		})
+	})
+}
+
+// PatchServiceConditions patches the conditions of the service with the latest version, which may be
+// updated by the other controllers at the same time. The service is updated with the new conditions.
+func PatchServiceConditions(ctx context.Context, kubeClient client.Client, svc *v1.Service,
+	mutate func(conds *[]metav1.Condition)) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &v1.Service{}
+		if err := kubeClient.Get(ctx, types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}, latest); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		updated := latest.DeepCopy()
+		mutate(&updated.Status.Conditions)
+		if err := kubeClient.Status().Patch(ctx, updated, client.MergeFromWithOptions(latest, client.MergeFromWithOptimisticLock{})); err != nil {
+			return err
+		}
+		svc.Status.Conditions = updated.Status.Conditions
+		return nil
+	})
+}
+
+// IsMigrationAborted returns whether the migration annotation is removed before the CLB is released.
+func IsMigrationAborted(svc *v1.Service) bool {
+	reason := migrationReason(svc)
+	return svc.Annotations[MigrateToNLB] != "true" && reason != "" && !isCLBReleased(svc)
+}
+
+// RemoveMigrationCondition removes the migration condition of the service, so that the soak period starts
+// over if the migration is enabled again.
+func RemoveMigrationCondition(ctx context.Context, kubeClient client.Client, svc *v1.Service) error {
+	if meta.FindStatusCondition(svc.Status.Conditions, LoadBalancerMigrationCondition) == nil {
+		return nil
+	}
+	return PatchServiceConditions(ctx, kubeClient, svc, func(conds *[]metav1.Condition) {
+		meta.RemoveStatusCondition(conds, LoadBalancerMigrationCondition)
+	})
+}
+
+type clbAddressDescriber interface {
+	DescribeLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error
+	DescribeEipAddresses(ctx context.Context, instanceType string, instanceId string) ([]string, error)
+}
+
+// CLBAddresses returns the addresses the CLB controller publishes for the CLB of the id, which are the
+// address of the CLB and the elastic ips bound to it as eipInstanceType.
+func CLBAddresses(ctx context.Context, cloud clbAddressDescriber, eipInstanceType, lbId string) (sets.String, error) {
+	addresses := sets.NewString()
+	if lbId == "" {
+		return addresses, nil
+	}
+	lb := &model.LoadBalancer{LoadBalancerAttribute: model.LoadBalancerAttribute{LoadBalancerId: lbId}}
+	if err := cloud.DescribeLoadBalancer(ctx, lb); err != nil {
+		return nil, fmt.Errorf("describe clb %s error: %s", lbId, err.Error())
+	}
+	if lb.LoadBalancerAttribute.Address != "" {
+		addresses.Insert(lb.LoadBalancerAttribute.Address)
+	}
+	eips, err := cloud.DescribeEipAddresses(ctx, eipInstanceType, lbId)
+	if err != nil {
+		return nil, fmt.Errorf("describe eips of clb %s error: %s", lbId, err.Error())
+	}
+	return addresses.Insert(eips...), nil
+}
+
+// IsCLBIngress returns whether the ingress is published by the CLB controller, which publishes the
+// addresses of the CLB, or the hostname of the annotation.
+func IsCLBIngress(ing v1.LoadBalancerIngress, clbAddresses sets.String, hostname string) bool {
+	if ing.IP != "" {
+		return clbAddresses.Has(ing.IP)
+	}
+	return hostname != "" && ing.Hostname == hostname
+}
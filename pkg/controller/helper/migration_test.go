@@ -0,0 +1,183 @@
+package helper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getMigratingService(reason string, transition time.Time) *v1.Service {
+	svc := getDefaultService()
+	svc.Annotations[MigrateToNLB] = "true"
+	if reason != "" {
+		svc.Status.Conditions = []metav1.Condition{{
+			Type:               LoadBalancerMigrationCondition,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			LastTransitionTime: metav1.NewTime(transition),
+		}}
+	}
+	return svc
+}
+
+func TestNeedCLBAndNLBDuringMigration(t *testing.T) {
+	svc := getMigratingService("", time.Now())
+	assert.True(t, NeedCLB(svc))
+	assert.True(t, NeedNLB(svc))
+	assert.False(t, NeedReleaseCLB(svc))
+
+	svc = getMigratingService(MigrationReasonCutOver, time.Now())
+	assert.True(t, NeedCLB(svc))
+	assert.True(t, NeedNLB(svc))
+	assert.True(t, NeedReleaseCLB(svc))
+
+	svc = getMigratingService(MigrationReasonCLBReleased, time.Now())
+	assert.False(t, NeedCLB(svc))
+	assert.True(t, NeedNLB(svc))
+	assert.False(t, IsMigratingToNLB(svc))
+	assert.True(t, IsMigratedToNLB(svc))
+
+	// the label keeps the service with the nlb once the condition is lost
+	svc = getMigratingService("", time.Now())
+	svc.Labels = map[string]string{LabelMigratedToNLB: "true"}
+	assert.False(t, NeedCLB(svc))
+	assert.True(t, NeedNLB(svc))
+	assert.False(t, IsMigrationAborted(svc))
+}
+
+func TestIsCutOverDue(t *testing.T) {
+	now := time.Now()
+
+	due, after, err := IsCutOverDue(getMigratingService(MigrationReasonNLBReady, now), now)
+	assert.Nil(t, err)
+	assert.False(t, due)
+	assert.Equal(t, time.Duration(0), after)
+
+	// the nlb is not ready yet
+	svc := getMigratingService("", now)
+	svc.Annotations[MigrationCutOver] = "true"
+	due, after, err = IsCutOverDue(svc, now)
+	assert.Nil(t, err)
+	assert.False(t, due)
+	assert.Equal(t, MigrationPollInterval, after)
+
+	svc = getMigratingService(MigrationReasonNLBReady, now)
+	svc.Annotations[MigrationCutOver] = "true"
+	due, _, err = IsCutOverDue(svc, now)
+	assert.Nil(t, err)
+	assert.True(t, due)
+
+	svc = getMigratingService(MigrationReasonNLBReady, now.Add(-time.Hour))
+	svc.Annotations[MigrationSoakPeriod] = "2h"
+	due, after, err = IsCutOverDue(svc, now)
+	assert.Nil(t, err)
+	assert.False(t, due)
+	assert.Equal(t, time.Hour, after)
+	due, _, err = IsCutOverDue(svc, now.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.True(t, due)
+
+	svc.Annotations[MigrationSoakPeriod] = "1d"
+	_, _, err = IsCutOverDue(svc, now)
+	assert.NotNil(t, err)
+}
+
+func TestMergeMigrationIngress(t *testing.T) {
+	clb := v1.LoadBalancerIngress{IP: "1.1.1.1"}
+	nlb := v1.LoadBalancerIngress{Hostname: "nlb-xxx.cn-hangzhou.nlb.aliyuncs.com"}
+	nlbEip := v1.LoadBalancerIngress{IP: "3.3.3.3"}
+	addresses := sets.NewString("1.1.1.1", "2.2.2.2")
+	isCLB := func(ing v1.LoadBalancerIngress) bool { return IsCLBIngress(ing, addresses, "") }
+
+	ret := MergeMigrationIngress([]v1.LoadBalancerIngress{clb}, []v1.LoadBalancerIngress{{IP: "2.2.2.2"}, nlb, nlbEip}, isCLB)
+	assert.Equal(t, []v1.LoadBalancerIngress{clb, nlb, nlbEip}, ret)
+
+	isNLB := func(ing v1.LoadBalancerIngress) bool { return !IsCLBIngress(ing, addresses, "") }
+	ret = MergeMigrationIngress(nil, []v1.LoadBalancerIngress{clb, nlb, nlbEip}, isNLB)
+	assert.Equal(t, []v1.LoadBalancerIngress{clb}, ret)
+}
+
+func TestCLBAddresses(t *testing.T) {
+	cloud := snapshot.NewSnapshotCloud(&snapshot.Snapshot{
+		LoadBalancers: []model.LoadBalancer{{LoadBalancerAttribute: model.LoadBalancerAttribute{
+			LoadBalancerId: "lb-1", Address: "192.168.0.1"}}},
+		EipAddresses: []model.EipAddress{
+			{AllocationId: "eip-1", IpAddress: "1.1.1.1", InstanceType: "SlbInstance", InstanceId: "lb-1"},
+			{AllocationId: "eip-2", IpAddress: "2.2.2.2", InstanceType: "Nlb", InstanceId: "nlb-1"},
+		},
+	})
+	addresses, err := CLBAddresses(context.TODO(), cloud, "SlbInstance", "lb-1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.1.1.1", "192.168.0.1"}, addresses.List())
+
+	addresses, err = CLBAddresses(context.TODO(), cloud, "SlbInstance", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, addresses.Len())
+
+	_, err = CLBAddresses(context.TODO(), cloud, "SlbInstance", "lb-2")
+	assert.NotNil(t, err)
+}
+
+func TestRemoveMigrationCondition(t *testing.T) {
+	svc := getMigratingService(MigrationReasonNLBReady, time.Now().Add(-time.Hour))
+	assert.False(t, IsMigrationAborted(svc))
+	delete(svc.Annotations, MigrateToNLB)
+	assert.True(t, IsMigrationAborted(svc))
+
+	kubeClient := fake.NewClientBuilder().WithObjects(svc).Build()
+	assert.Nil(t, RemoveMigrationCondition(context.TODO(), kubeClient, svc))
+	assert.Nil(t, meta.FindStatusCondition(svc.Status.Conditions, LoadBalancerMigrationCondition))
+	assert.False(t, IsMigrationAborted(svc))
+
+	// the soak period starts over when the migration is enabled again
+	svc.Annotations[MigrateToNLB] = "true"
+	svc.Annotations[MigrationSoakPeriod] = "24h"
+	due, _, err := IsCutOverDue(svc, time.Now())
+	assert.Nil(t, err)
+	assert.False(t, due)
+
+	released := getMigratingService(MigrationReasonCLBReleased, time.Now())
+	delete(released.Annotations, MigrateToNLB)
+	assert.False(t, IsMigrationAborted(released))
+}
+
+func TestSetMigrationCondition(t *testing.T) {
+	svc := getMigratingService("", time.Now())
+	kubeClient := fake.NewClientBuilder().WithObjects(svc).Build()
+
+	assert.Nil(t, SetMigrationCondition(context.TODO(), kubeClient, svc, MigrationReasonNLBReady, "ready"))
+	cond := meta.FindStatusCondition(svc.Status.Conditions, LoadBalancerMigrationCondition)
+	assert.NotNil(t, cond)
+	assert.Equal(t, MigrationReasonNLBReady, cond.Reason)
+
+	// the transition time is reset when the reason changes
+	latest := &v1.Service{}
+	assert.Nil(t, kubeClient.Get(context.TODO(), client.ObjectKeyFromObject(svc), latest))
+	latest.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	assert.Nil(t, kubeClient.Status().Update(context.TODO(), latest))
+	assert.Nil(t, SetMigrationCondition(context.TODO(), kubeClient, svc, MigrationReasonCutOver, "cut-over"))
+	cond = meta.FindStatusCondition(svc.Status.Conditions, LoadBalancerMigrationCondition)
+	assert.Equal(t, MigrationReasonCutOver, cond.Reason)
+	assert.True(t, time.Since(cond.LastTransitionTime.Time) < time.Minute)
+}
+
+func TestMarkMigratedToNLB(t *testing.T) {
+	svc := getMigratingService(MigrationReasonCutOver, time.Now())
+	kubeClient := fake.NewClientBuilder().WithObjects(svc).Build()
+	assert.Nil(t, MarkMigratedToNLB(context.TODO(), kubeClient, svc))
+	assert.True(t, IsMigratedToNLB(svc))
+
+	latest := &v1.Service{}
+	assert.Nil(t, kubeClient.Get(context.TODO(), client.ObjectKeyFromObject(svc), latest))
+	assert.Equal(t, "true", latest.Labels[LabelMigratedToNLB])
+}
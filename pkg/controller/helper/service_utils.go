@@ -6,6 +6,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/client-go/util/retry"
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/hash"
@@ -20,6 +21,7 @@ import (
 const (
 	ServiceFinalizer = "service.k8s.alibaba/resources"
 	NLBFinalizer     = "service.k8s.alibaba/nlb"
+	ALBFinalizer     = "service.k8s.alibaba/alb"
 )
 
 // annotation
@@ -30,7 +32,10 @@ const (
 )
 
 // load balancer class
-const NLBClass = "alibabacloud.com/nlb"
+const (
+	NLBClass = "alibabacloud.com/nlb"
+	ALBClass = "alibabacloud.com/alb"
+)
 
 // label
 const (
@@ -101,6 +106,9 @@ func NeedCLB(service *v1.Service) bool {
 	if feature.DefaultFeatureGate.Enabled(ctrlCfg.LoadBalancerTypeAnnotation) && service.Annotations[LoadBalancerType] != "" {
 		return false
 	}
+	if IsMigratedToNLB(service) {
+		return false
+	}
 	return service.Annotations[LoadBalancerClass] == ""
 }
 
@@ -111,23 +119,41 @@ func NeedNLB(service *v1.Service) bool {
 	if service.Spec.LoadBalancerClass != nil && *service.Spec.LoadBalancerClass == NLBClass {
 		return true
 	}
+	if IsMigratingToNLB(service) || IsMigratedToNLB(service) {
+		return true
+	}
 	if feature.DefaultFeatureGate.Enabled(ctrlCfg.LoadBalancerTypeAnnotation) && service.Annotations[LoadBalancerType] == "nlb" {
 		return service.Annotations[LoadBalancerType] == "nlb"
 	}
 	return false
 }
 
-func GetServiceHash(svc *v1.Service) string {
+// NeedALB returns whether the service is exposed by an application load balancer.
+func NeedALB(service *v1.Service) bool {
+	return service.Spec.Type == v1.ServiceTypeLoadBalancer &&
+		service.Spec.LoadBalancerClass != nil && *service.Spec.LoadBalancerClass == ALBClass
+}
+
+// GetServiceHash returns the hash of the service, together with the spec of the LoadBalancerConfig
+// referred by the service if any, so that the changes of the config are synced as well.
+func GetServiceHash(svc *v1.Service, config *albv1.LoadBalancerConfig) string {
 	var op []interface{}
 	// ServiceSpec
 	op = append(op, svc.Spec.Ports, svc.Spec.Type, svc.Spec.ExternalTrafficPolicy, svc.Spec.LoadBalancerClass)
 	op = append(op, svc.Annotations, svc.DeletionTimestamp)
+	if config != nil {
+		op = append(op, config.Spec)
+	}
+	// appended only if set, so that the hash of the services without source ranges is kept
+	if len(svc.Spec.LoadBalancerSourceRanges) != 0 {
+		op = append(op, svc.Spec.LoadBalancerSourceRanges)
+	}
 	return hash.HashObject(op)
 }
 
-func IsServiceHashChanged(service *v1.Service) bool {
+func IsServiceHashChanged(service *v1.Service, config *albv1.LoadBalancerConfig) bool {
 	if oldHash, ok := service.Labels[LabelServiceHash]; ok {
-		newHash := GetServiceHash(service)
+		newHash := GetServiceHash(service, config)
 		return !strings.EqualFold(oldHash, newHash)
 	}
 	return true
@@ -5,33 +5,47 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
 	"testing"
 )
 
 func TestIsServiceHashChanged(t *testing.T) {
 	base := getDefaultService()
 	base.Annotations["service.beta.kubernetes.io/alibaba-cloud-loadbalancer-name"] = "slb-base"
-	baseHash := GetServiceHash(base)
+	baseHash := GetServiceHash(base, nil)
 
 	svcAnnoChanged := base.DeepCopy()
 	svcAnnoChanged.Annotations["service.beta.kubernetes.io/alibaba-cloud-loadbalancer-name"] = "slb-anno-changed"
-	annoHash := GetServiceHash(svcAnnoChanged)
+	annoHash := GetServiceHash(svcAnnoChanged, nil)
 	assert.NotEqual(t, baseHash, annoHash)
 
 	svcMetaChanged := base.DeepCopy()
 	svcMetaChanged.Labels = map[string]string{"app": "test"}
-	hash := GetServiceHash(svcMetaChanged)
+	hash := GetServiceHash(svcMetaChanged, nil)
 	assert.Equal(t, baseHash, hash)
 
 	svcSpecChanged := base.DeepCopy()
 	svcSpecChanged.Spec.ExternalTrafficPolicy = "Cluster"
-	hash = GetServiceHash(svcSpecChanged)
+	hash = GetServiceHash(svcSpecChanged, nil)
 	assert.NotEqual(t, baseHash, hash)
 
 	svcNewAttrChanged := base.DeepCopy()
 	svcNewAttrChanged.Spec.PublishNotReadyAddresses = true
-	hash = GetServiceHash(svcNewAttrChanged)
+	hash = GetServiceHash(svcNewAttrChanged, nil)
 	assert.Equal(t, baseHash, hash)
+
+	svcSourceRangesChanged := base.DeepCopy()
+	svcSourceRangesChanged.Spec.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+	hash = GetServiceHash(svcSourceRangesChanged, nil)
+	assert.NotEqual(t, baseHash, hash)
+
+	config := &albv1.LoadBalancerConfig{}
+	config.Spec.Listener = &albv1.ListenerAttributes{Scheduler: "wrr"}
+	configHash := GetServiceHash(base, config)
+	assert.NotEqual(t, baseHash, configHash)
+	config.Spec.Listener.Scheduler = "rr"
+	hash = GetServiceHash(base, config)
+	assert.NotEqual(t, configHash, hash)
 }
 
 func getDefaultService() *v1.Service {
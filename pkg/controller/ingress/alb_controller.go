@@ -3,17 +3,19 @@ package ingress
 import (
 	"context"
 	"encoding/json"
+	goerrors "errors"
 	"fmt"
 	sdkutils "github.com/aliyun/alibaba-cloud-sdk-go/sdk/utils"
-	"github.com/eapache/channels"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
 	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/context/shared"
@@ -35,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -46,7 +49,6 @@ const (
 )
 
 func NewAlbConfigReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*albconfigReconciler, error) {
-	config := Configuration{}
 	logger := ctrl.Log.WithName("controllers").WithName(albIngressControllerName)
 	logger.Info("start to register crds")
 	err := RegisterCRD(mgr.GetConfig())
@@ -54,10 +56,6 @@ func NewAlbConfigReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*al
 		logger.Error(err, "register crd: %s", err.Error())
 		return nil, err
 	}
-	client, err := kubernetes.NewForConfig(mgr.GetConfig())
-	if err != nil {
-		return nil, err
-	}
 	n := &albconfigReconciler{
 		cloud:            ctx.Provider(),
 		k8sClient:        mgr.GetClient(),
@@ -66,8 +64,8 @@ func NewAlbConfigReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*al
 		eventRecorder:    mgr.GetEventRecorderFor("ingress"),
 		stackMarshaller:  NewDefaultStackMarshaller(),
 		logger:           logger,
-		updateCh:         channels.NewRingChannel(1024),
 		albconfigBuilder: albconfigmanager.NewDefaultAlbConfigManagerBuilder(mgr.GetClient(), ctx.Provider(), logger),
+		secretCertMgr:    albconfigmanager.NewDefaultSecretCertManager(mgr.GetClient(), ctx.Provider(), logger),
 
 		serverApplier: applier.NewServiceManagerApplier(
 			mgr.GetClient(),
@@ -79,12 +77,11 @@ func NewAlbConfigReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*al
 
 		maxConcurrentReconciles: defaultMaxConcurrentReconciles,
 	}
-	n.store = store.New(
-		config.Namespace,
-		config.ResyncPeriod,
-		client,
-		n.updateCh,
-		config.DisableCatchAll)
+	// the store is shared with the other controllers resolving the backends through it
+	n.store, err = store.Shared(ctx, mgr)
+	if err != nil {
+		return nil, err
+	}
 	n.serverBuilder = servicemanager.NewDefaultServiceStackBuilder(backend.NewBackendManager(n.store, mgr.GetClient(), ctx.Provider(), logger))
 	n.albconfigApplier = applier.NewAlbConfigManagerApplier(n.store, mgr.GetClient(), ctx.Provider(), util.IngressTagKeyPrefix, logger)
 	n.syncQueue = helper.NewTaskQueue(n.syncIngress)
@@ -111,14 +108,14 @@ type albconfigReconciler struct {
 	eventRecorder    record.EventRecorder
 	stackMarshaller  StackMarshaller
 	logger           logr.Logger
-	store            store.Storer
+	store            *store.SharedStore
 	albconfigBuilder albconfigmanager.Builder
+	secretCertMgr    albconfigmanager.SecretCertManager
 	albconfigApplier applier.AlbConfigManagerApplier
 	serverBuilder    servicemanager.Builder
 	serverApplier    applier.ServiceManagerApplier
 	isShuttingDown   bool
 	stopCh           chan struct{}
-	updateCh         *channels.RingChannel
 	acEventChan      chan event.GenericEvent
 	// ngxErrCh is used to detect errors with the NGINX processes
 	ngxErrCh                chan error
@@ -141,6 +138,11 @@ func (g *albconfigReconciler) setupWatches(_ context.Context, c controller.Contr
 		return err
 	}
 
+	if err := c.Watch(source.Kind(mgr.GetCache(), &corev1.Secret{}),
+		NewEnqueueRequestsForSecretEvent(g.store, g.groupLoader, g.logger)); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -309,6 +311,16 @@ func (g *albconfigReconciler) getServicePortToIngressNames(request reconcile.Req
 				}
 			}
 		}
+
+		if ing.Namespace != request.Namespace {
+			continue
+		}
+		for _, port := range mirrorServicePorts(ing.Annotations, request.Name) {
+			if _, ok := servicePortToIngressNames[port]; !ok {
+				servicePortToIngressNames[port] = make(map[string]struct{})
+			}
+			servicePortToIngressNames[port][ing.Name] = struct{}{}
+		}
 	}
 
 	var servicePortToIngressNameList = make(map[int32][]string)
@@ -321,6 +333,32 @@ func (g *albconfigReconciler) getServicePortToIngressNames(request reconcile.Req
 	return servicePortToIngressNameList
 }
 
+// mirrorServicePorts returns the ports of the Service used as a traffic mirror target
+// in the actions annotations of an Ingress.
+func mirrorServicePorts(ingAnnotations map[string]string, svcName string) []int32 {
+	var ports []int32
+	for key, raw := range ingAnnotations {
+		if !strings.HasPrefix(key, annotations.AlbActions+".") {
+			continue
+		}
+		var actions []albmodel.Action
+		if err := json.Unmarshal([]byte(raw), &actions); err != nil {
+			continue
+		}
+		for _, action := range actions {
+			if action.Type != util.RuleActionTypeTrafficMirror || action.TrafficMirrorConfig == nil {
+				continue
+			}
+			for _, tuple := range action.TrafficMirrorConfig.MirrorGroupConfig.ServerGroupTuples {
+				if tuple.ServiceName == svcName && tuple.ServicePort != 0 {
+					ports = append(ports, int32(tuple.ServicePort))
+				}
+			}
+		}
+	}
+	return ports
+}
+
 func (g *albconfigReconciler) buildServiceStackContext(ctx context.Context, request reconcile.Request, serverPortToIngressNames map[int32][]string) (*albmodel.ServiceStackContext, error) {
 	var svcStackContext = &albmodel.ServiceStackContext{
 		ClusterID:                 g.cloud.ClusterID(),
@@ -521,9 +559,24 @@ func (g *albconfigReconciler) reconcile(ctx context.Context, request reconcile.R
 
 	g.recordIngressGroupEvent(ctx, ingGroup, corev1.EventTypeNormal, helper.IngressEventReasonSuccessfullyReconciled, "Successfully reconciled")
 
+	g.garbageCollectSecretCerts(ctx, ings)
+
 	return nil
 }
 
+// garbageCollectSecretCerts deletes the certificates uploaded from TLS Secrets that no
+// Ingress uses anymore. Failures are retried on the next reconcile.
+func (g *albconfigReconciler) garbageCollectSecretCerts(ctx context.Context, ings []*store.Ingress) {
+	ingList := make([]networking.Ingress, 0, len(ings))
+	for _, ing := range ings {
+		ingList = append(ingList, ing.Ingress)
+	}
+	if err := g.secretCertMgr.GarbageCollect(ctx, ingList); err != nil {
+		g.logger.Error(err, "failed to garbage collect secret certificates",
+			"traceID", ctx.Value(util.TraceID))
+	}
+}
+
 func (g *albconfigReconciler) cleanupAlbLoadBalancerResources(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group) error {
 	acFinalizer := albconfigmanager.GetIngressFinalizer()
 	if helper.HasFinalizer(albconfig, acFinalizer) {
@@ -556,12 +609,16 @@ func (g *albconfigReconciler) reconcileAlbLoadBalancerResources(ctx context.Cont
 		return err
 	}
 
-	_, lb, err := g.buildAndApply(ctx, albconfig, ingGroup)
+	stack, lb, err := g.buildAndApply(ctx, albconfig, ingGroup)
+	statusErr := g.updateAlbConfigStatus(ctx, albconfig, ingGroup, stack, lb, err)
+	if statusErr != nil {
+		g.logger.Error(statusErr, "AlbConfig Status Update", "albconfig", util.NamespacedName(albconfig).String())
+	}
 	if err != nil {
 		return err
 	}
 	if lb.Status == nil || lb.Status.DNSName == "" {
-		return nil
+		return statusErr
 	}
 	for _, ing := range ingGroup.Members {
 		if ing.Status.LoadBalancer.Ingress != nil && len(ing.Status.LoadBalancer.Ingress) > 0 && ing.Status.LoadBalancer.Ingress[0].Hostname == lb.Status.DNSName {
@@ -577,18 +634,99 @@ func (g *albconfigReconciler) reconcileAlbLoadBalancerResources(ctx context.Cont
 			continue
 		}
 	}
-	if albconfig.Status.LoadBalancer.DNSName == lb.Status.DNSName {
-		return nil
+	return statusErr
+}
+
+// updateAlbConfigStatus records the result of buildAndApply in the status of the AlbConfig.
+// stack is nil when the build failed, lb.Status is nil when the load balancer was not applied.
+func (g *albconfigReconciler) updateAlbConfigStatus(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group,
+	stack core.Manager, lb *albmodel.AlbLoadBalancer, reconcileErr error) error {
+	status := albconfig.Status.DeepCopy()
+	status.ObservedGeneration = albconfig.Generation
+	setCondition := func(conditionType string, ok bool, reason, message string) {
+		condStatus := metav1.ConditionFalse
+		if ok {
+			condStatus = metav1.ConditionTrue
+		}
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             condStatus,
+			ObservedGeneration: albconfig.Generation,
+			Reason:             reason,
+			Message:            message,
+		})
 	}
-	albconfig.Status.LoadBalancer.Id = lb.Status.LoadBalancerID
-	albconfig.Status.LoadBalancer.DNSName = lb.Status.DNSName
 
-	err = g.k8sClient.Status().Update(ctx, albconfig, &client.SubResourceUpdateOptions{})
-	if err != nil {
-		g.logger.Error(err, "LB Status Update %s, error: %s", albconfig.Name)
-		return err
+	var errMsg string
+	if reconcileErr != nil {
+		errMsg = helper.GetLogMessage(reconcileErr)
+	}
+	var certErr *albconfigmanager.CertificateResolveError
+	switch {
+	case reconcileErr == nil:
+		setCondition(v1.AlbConfigConditionLoadBalancerProvisioned, true, v1.AlbConfigReasonReconciled, "")
+		setCondition(v1.AlbConfigConditionCertificatesResolved, true, v1.AlbConfigReasonReconciled, "")
+		setCondition(v1.AlbConfigConditionListenersSynced, true, v1.AlbConfigReasonReconciled, "")
+		setCondition(v1.AlbConfigConditionReady, true, v1.AlbConfigReasonReconciled, "")
+	case stack == nil:
+		if goerrors.As(reconcileErr, &certErr) {
+			setCondition(v1.AlbConfigConditionCertificatesResolved, false, v1.AlbConfigReasonCertificateNotFound, errMsg)
+		}
+		setCondition(v1.AlbConfigConditionReady, false, v1.AlbConfigReasonBuildFailed, errMsg)
+	default:
+		setCondition(v1.AlbConfigConditionCertificatesResolved, true, v1.AlbConfigReasonReconciled, "")
+		if lb.Status == nil {
+			setCondition(v1.AlbConfigConditionLoadBalancerProvisioned, false, v1.AlbConfigReasonApplyFailed, errMsg)
+		} else {
+			setCondition(v1.AlbConfigConditionLoadBalancerProvisioned, true, v1.AlbConfigReasonReconciled, "")
+		}
+		setCondition(v1.AlbConfigConditionListenersSynced, false, v1.AlbConfigReasonApplyFailed, errMsg)
+		setCondition(v1.AlbConfigConditionReady, false, v1.AlbConfigReasonApplyFailed, errMsg)
 	}
-	return nil
+
+	if lb != nil && lb.Status != nil {
+		status.LoadBalancer.Id = lb.Status.LoadBalancerID
+		status.LoadBalancer.DNSName = lb.Status.DNSName
+	}
+	if stack != nil {
+		var resLSs []*albmodel.Listener
+		if err := stack.ListResources(&resLSs); err != nil {
+			return err
+		}
+		listeners := make([]v1.ListenerStatus, 0, len(resLSs))
+		for _, ls := range resLSs {
+			lsStatus := v1.ListenerStatus{
+				Port:     int32(ls.Spec.ListenerPort),
+				Protocol: ls.Spec.ListenerProtocol,
+			}
+			if ls.Status != nil {
+				lsStatus.Id = ls.Status.ListenerID
+				lsStatus.Status = ls.Status.ListenerStatus
+			}
+			listeners = append(listeners, lsStatus)
+		}
+		sort.Slice(listeners, func(i, j int) bool {
+			return listeners[i].Port < listeners[j].Port
+		})
+		status.Listeners = listeners
+	}
+	ingresses := make([]v1.IngressReference, 0, len(ingGroup.Members))
+	for _, ing := range ingGroup.Members {
+		ingresses = append(ingresses, v1.IngressReference{Namespace: ing.Namespace, Name: ing.Name})
+	}
+	sort.Slice(ingresses, func(i, j int) bool {
+		if ingresses[i].Namespace != ingresses[j].Namespace {
+			return ingresses[i].Namespace < ingresses[j].Namespace
+		}
+		return ingresses[i].Name < ingresses[j].Name
+	})
+	status.Ingresses = ingresses
+
+	if equality.Semantic.DeepEqual(albconfig.Status, *status) {
+		return nil
+	}
+	albconfig.Status = *status
+	return g.k8sClient.Status().Update(ctx, albconfig, &client.SubResourceUpdateOptions{})
 }
 
 func (g *albconfigReconciler) buildAndApply(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group) (core.Manager, *albmodel.AlbLoadBalancer, error) {
@@ -621,7 +759,8 @@ func (g *albconfigReconciler) buildAndApply(ctx context.Context, albconfig *v1.A
 	applyStartTime := time.Now()
 	if err := g.albconfigApplier.Apply(ctx, stack); err != nil {
 		g.recordIngressGroupEvent(ctx, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedApplyModel, helper.GetLogMessage(err))
-		return nil, nil, err
+		// the stack and lb keep the status of the resources applied before the failure
+		return stack, lb, err
 	}
 	g.logger.Info("successfully applied albconfig stack",
 		"albconfig", util.NamespacedName(albconfig).String(),
@@ -640,7 +779,9 @@ func (g *albconfigReconciler) recordIngressGroupEvent(_ context.Context, ingGrou
 // Start starts a new ALB master process running in the foreground.
 func (n *albconfigReconciler) Start() {
 	n.logger.Info("Starting ALB Ingress controller")
-	n.store.Run(n.stopCh)
+	if !n.store.WaitForSync(n.stopCh) {
+		return
+	}
 	go n.syncQueue.Run(1, time.Second, n.stopCh)
 	go n.syncServersQueue.Run(3, time.Second, n.stopCh)
 	for {
@@ -651,7 +792,7 @@ func (n *albconfigReconciler) Start() {
 			}
 			n.logger.Error(err, "ErrCh received")
 
-		case event := <-n.updateCh.Out():
+		case event := <-n.store.UpdateCh.Out():
 			if n.isShuttingDown {
 				break
 			}
@@ -0,0 +1,90 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+	albconfigmanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/store"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestUpdateAlbConfigStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1.SchemeBuilder.AddToScheme(scheme))
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Name: "default", Generation: 2}}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(albconfig).WithStatusSubresource(albconfig).Build()
+	g := &albconfigReconciler{k8sClient: kubeClient, logger: ctrl.Log.WithName("test")}
+	ctx := context.TODO()
+
+	ingGroup := &albconfigmanager.Group{Members: []*networking.Ingress{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "b"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "a"}},
+	}}
+	stack := core.NewDefaultManager(core.StackID{Name: "default"})
+	lb := albmodel.NewAlbLoadBalancer(stack, "lb", albmodel.ALBLoadBalancerSpec{})
+	lb.SetStatus(albmodel.LoadBalancerStatus{LoadBalancerID: "alb-1", DNSName: "alb-1.example.com"})
+	https := albmodel.NewListener(stack, "443", albmodel.ListenerSpec{LoadBalancerID: lb.LoadBalancerID(), ALBListenerSpec: albmodel.ALBListenerSpec{ListenerPort: 443, ListenerProtocol: "HTTPS"}})
+	https.SetStatus(albmodel.ListenerStatus{ListenerID: "lsn-443", ListenerStatus: "Running"})
+	albmodel.NewListener(stack, "80", albmodel.ListenerSpec{LoadBalancerID: lb.LoadBalancerID(), ALBListenerSpec: albmodel.ALBListenerSpec{ListenerPort: 80, ListenerProtocol: "HTTP"}})
+
+	// applying the listener on port 80 failed
+	assert.NoError(t, g.updateAlbConfigStatus(ctx, albconfig, ingGroup, stack, lb, fmt.Errorf("apply failed")))
+	got := &v1.AlbConfig{}
+	assert.NoError(t, kubeClient.Get(ctx, client.ObjectKeyFromObject(albconfig), got))
+	assert.Equal(t, int64(2), got.Status.ObservedGeneration)
+	assert.Equal(t, "alb-1", got.Status.LoadBalancer.Id)
+	assert.Equal(t, []v1.ListenerStatus{
+		{Port: 80, Protocol: "HTTP"},
+		{Id: "lsn-443", Port: 443, Protocol: "HTTPS", Status: "Running"},
+	}, got.Status.Listeners)
+	assert.Equal(t, []v1.IngressReference{{Namespace: "ns1", Name: "a"}, {Namespace: "ns2", Name: "b"}}, got.Status.Ingresses)
+	assert.True(t, meta.IsStatusConditionTrue(got.Status.Conditions, v1.AlbConfigConditionLoadBalancerProvisioned))
+	assert.True(t, meta.IsStatusConditionFalse(got.Status.Conditions, v1.AlbConfigConditionListenersSynced))
+	assert.True(t, meta.IsStatusConditionFalse(got.Status.Conditions, v1.AlbConfigConditionReady))
+
+	// the certificates can't be resolved, the last listeners are kept
+	certErr := &albconfigmanager.CertificateResolveError{Err: fmt.Errorf("no cert was discovered")}
+	assert.NoError(t, g.updateAlbConfigStatus(ctx, albconfig, ingGroup, nil, nil, certErr))
+	assert.NoError(t, kubeClient.Get(ctx, client.ObjectKeyFromObject(albconfig), got))
+	assert.Len(t, got.Status.Listeners, 2)
+	cond := meta.FindStatusCondition(got.Status.Conditions, v1.AlbConfigConditionCertificatesResolved)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, v1.AlbConfigReasonCertificateNotFound, cond.Reason)
+
+	assert.NoError(t, g.updateAlbConfigStatus(ctx, albconfig, ingGroup, stack, lb, nil))
+	assert.NoError(t, kubeClient.Get(ctx, client.ObjectKeyFromObject(albconfig), got))
+	for _, c := range got.Status.Conditions {
+		assert.Equal(t, metav1.ConditionTrue, c.Status, c.Type)
+	}
+}
+
+func TestGetServicePortToIngressNamesWithMirror(t *testing.T) {
+	g := &albconfigReconciler{logger: ctrl.Log.WithName("test")}
+	ing := &store.Ingress{Ingress: networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ing", Annotations: map[string]string{
+			annotations.AlbActions + ".web": `[{"type":"TrafficMirror","TrafficMirrorConfig":{"MirrorGroupConfig":{"ServerGroupTuples":[{"ServiceName":"mirror","ServicePort":8080}]}}}]`,
+		}},
+	}}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "mirror"}}
+	assert.Equal(t, map[int32][]string{8080: {"ing"}}, g.getServicePortToIngressNames(request, []*store.Ingress{ing}))
+
+	request.Namespace = "other"
+	assert.Empty(t, g.getServicePortToIngressNames(request, []*store.Ingress{ing}))
+}
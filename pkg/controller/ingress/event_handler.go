@@ -2,10 +2,17 @@ package ingress
 
 import (
 	"context"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	albconfigmanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/store"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -69,3 +76,69 @@ func (h *enqueueRequestsForAlbconfigEvent) enqueueAlbconfig(queue workqueue.Rate
 		NamespacedName: util.NamespacedName(albconfig),
 	})
 }
+
+// NewEnqueueRequestsForSecretEvent enqueues the AlbConfigs of the Ingresses referring to a
+// changed TLS Secret, so a rotated certificate is uploaded without waiting for a resync.
+func NewEnqueueRequestsForSecretEvent(store store.Storer, groupLoader albconfigmanager.GroupLoader, logger logr.Logger) *enqueueRequestsForSecretEvent {
+	return &enqueueRequestsForSecretEvent{
+		store:       store,
+		groupLoader: groupLoader,
+		logger:      logger,
+	}
+}
+
+var _ handler.EventHandler = (*enqueueRequestsForSecretEvent)(nil)
+
+type enqueueRequestsForSecretEvent struct {
+	store       store.Storer
+	groupLoader albconfigmanager.GroupLoader
+	logger      logr.Logger
+}
+
+func (h *enqueueRequestsForSecretEvent) Create(ctx context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	secret, ok := e.Object.(*corev1.Secret)
+	if ok {
+		h.enqueueReferringAlbconfigs(ctx, queue, secret)
+	}
+}
+
+func (h *enqueueRequestsForSecretEvent) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	secretOld, ok1 := e.ObjectOld.(*corev1.Secret)
+	secretNew, ok2 := e.ObjectNew.(*corev1.Secret)
+	if ok1 && ok2 && !reflect.DeepEqual(secretOld.Data, secretNew.Data) {
+		h.enqueueReferringAlbconfigs(ctx, queue, secretNew)
+	}
+}
+
+func (h *enqueueRequestsForSecretEvent) Delete(_ context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	// the listeners keep the last uploaded certificate until the secret is back
+}
+
+func (h *enqueueRequestsForSecretEvent) Generic(_ context.Context, e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+}
+
+func (h *enqueueRequestsForSecretEvent) enqueueReferringAlbconfigs(ctx context.Context, queue workqueue.RateLimitingInterface, secret *corev1.Secret) {
+	for _, ing := range h.store.ListIngresses() {
+		if ing.Namespace != secret.Namespace || !ingressRefersSecret(&ing.Ingress, secret.Name) {
+			continue
+		}
+		groupID, err := h.groupLoader.LoadGroupID(ctx, &ing.Ingress)
+		if err != nil || groupID == nil {
+			continue
+		}
+		h.logger.Info("controller: secret event", "secret", util.NamespacedName(secret).String(),
+			"ingress", util.NamespacedName(&ing.Ingress).String())
+		queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: groupID.Namespace, Name: groupID.Name},
+		})
+	}
+}
+
+func ingressRefersSecret(ing *networking.Ingress, secretName string) bool {
+	for _, tls := range ing.Spec.TLS {
+		if tls.SecretName == secretName {
+			return true
+		}
+	}
+	return false
+}
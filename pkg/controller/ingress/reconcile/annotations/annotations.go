@@ -63,6 +63,11 @@ const (
 	AlbCanaryByCookie      = AnnotationAlbPrefix + "canary-by-cookie"
 	AlbCanaryWeight        = AnnotationAlbPrefix + "canary-weight"
 	AlbSslRedirect         = AnnotationAlbPrefix + "ssl-redirect"
+	AlbActions             = AnnotationAlbPrefix + "actions"           // AlbActions.<service name> extra rule actions in json
+	AlbRewriteTarget       = AnnotationAlbPrefix + "rewrite-target"    // AlbRewriteTarget rewrite the request path before forwarding
+	AlbTrafficLimitQps     = AnnotationAlbPrefix + "traffic-limit-qps" // AlbTrafficLimitQps qps limit of each forwarding rule
+	AlbConditions          = AnnotationAlbPrefix + "conditions"        // AlbConditions.<service name> extra rule conditions in json
+	AlbResponseActions     = AnnotationAlbPrefix + "response-actions"  // AlbResponseActions.<service name> actions of the response rule in json
 )
 
 type ParseOptions struct {
@@ -3,6 +3,7 @@ package applier
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
@@ -180,42 +181,48 @@ func matchResAndSDKListeners(resLSs []*albmodel.Listener, sdkLSs []albsdk.Listen
 	var unmatchedResLSs []*albmodel.Listener
 	var unmatchedSDKLSs []albsdk.Listener
 
-	resLSByPort := mapResListenerByPort(resLSs)
-	sdkLSByPort := mapSDKListenerByPort(sdkLSs)
-	resLSPorts := sets.Int64KeySet(resLSByPort)
-	sdkLSPorts := sets.Int64KeySet(sdkLSByPort)
-	for _, port := range resLSPorts.Intersection(sdkLSPorts).List() {
-		resLS := resLSByPort[port]
-		sdkLS := sdkLSByPort[port]
+	resLSByKey := mapResListenerByPortAndProtocol(resLSs)
+	sdkLSByKey := mapSDKListenerByPortAndProtocol(sdkLSs)
+	resLSKeys := sets.StringKeySet(resLSByKey)
+	sdkLSKeys := sets.StringKeySet(sdkLSByKey)
+	for _, key := range resLSKeys.Intersection(sdkLSKeys).List() {
+		resLS := resLSByKey[key]
+		sdkLS := sdkLSByKey[key]
 		matchedResAndSDKLSs = append(matchedResAndSDKLSs, resAndSDKListenerPair{
 			resLS: resLS,
 			sdkLS: &sdkLS,
 		})
 	}
-	for _, port := range resLSPorts.Difference(sdkLSPorts).List() {
-		unmatchedResLSs = append(unmatchedResLSs, resLSByPort[port])
+	for _, key := range resLSKeys.Difference(sdkLSKeys).List() {
+		unmatchedResLSs = append(unmatchedResLSs, resLSByKey[key])
 	}
-	for _, port := range sdkLSPorts.Difference(resLSPorts).List() {
-		unmatchedSDKLSs = append(unmatchedSDKLSs, sdkLSByPort[port])
+	for _, key := range sdkLSKeys.Difference(resLSKeys).List() {
+		unmatchedSDKLSs = append(unmatchedSDKLSs, sdkLSByKey[key])
 	}
 
 	return matchedResAndSDKLSs, unmatchedResLSs, unmatchedSDKLSs
 }
 
-func mapResListenerByPort(resLSs []*albmodel.Listener) map[int64]*albmodel.Listener {
-	resLSByPort := make(map[int64]*albmodel.Listener, len(resLSs))
+// listenerKey identifies a listener by its port and protocol, as a https listener and a quic listener
+// can share a port.
+func listenerKey(port int, protocol string) string {
+	return fmt.Sprintf("%d/%s", port, strings.ToUpper(protocol))
+}
+
+func mapResListenerByPortAndProtocol(resLSs []*albmodel.Listener) map[string]*albmodel.Listener {
+	resLSByKey := make(map[string]*albmodel.Listener, len(resLSs))
 	for _, ls := range resLSs {
-		resLSByPort[int64(ls.Spec.ListenerPort)] = ls
+		resLSByKey[listenerKey(ls.Spec.ListenerPort, ls.Spec.ListenerProtocol)] = ls
 	}
-	return resLSByPort
+	return resLSByKey
 }
 
-func mapSDKListenerByPort(sdkLSs []albsdk.Listener) map[int64]albsdk.Listener {
-	sdkLSByPort := make(map[int64]albsdk.Listener, len(sdkLSs))
+func mapSDKListenerByPortAndProtocol(sdkLSs []albsdk.Listener) map[string]albsdk.Listener {
+	sdkLSByKey := make(map[string]albsdk.Listener, len(sdkLSs))
 	for _, ls := range sdkLSs {
-		sdkLSByPort[int64(ls.ListenerPort)] = ls
+		sdkLSByKey[listenerKey(ls.ListenerPort, ls.ListenerProtocol)] = ls
 	}
-	return sdkLSByPort
+	return sdkLSByKey
 }
 
 func mapResListenerByAlbLoadBalancerID(ctx context.Context, resLSs []*albmodel.Listener) (map[string][]*albmodel.Listener, error) {
@@ -175,6 +175,12 @@ func matchResAndSDKListenerRules(resLRs []*albmodel.ListenerRule, sdkLRs []albsd
 	for _, priority := range resLRPriorities.Intersection(sdkLRPriorities).List() {
 		resLR := resLRByPriority[priority]
 		sdkLR := sdkLRByPriority[priority]
+		// direction can't be updated, recreate the rule instead
+		if ruleDirection(resLR.Spec.Direction) != ruleDirection(sdkLR.Direction) {
+			unmatchedResLRs = append(unmatchedResLRs, resLR)
+			unmatchedSDKLRs = append(unmatchedSDKLRs, sdkLR)
+			continue
+		}
 		matchedResAndSDKLRs = append(matchedResAndSDKLRs, albmodel.ResAndSDKListenerRulePair{
 			ResLR: resLR,
 			SdkLR: &sdkLR,
@@ -190,6 +196,13 @@ func matchResAndSDKListenerRules(resLRs []*albmodel.ListenerRule, sdkLRs []albsd
 	return matchedResAndSDKLRs, unmatchedResLRs, unmatchedSDKLRs
 }
 
+func ruleDirection(direction string) string {
+	if direction == "" {
+		return util.RuleDirectionRequest
+	}
+	return direction
+}
+
 func mapResListenerRuleByPriority(resLRs []*albmodel.ListenerRule) map[int64]*albmodel.ListenerRule {
 	resLRByPriority := make(map[int64]*albmodel.ListenerRule)
 	for _, resLR := range resLRs {
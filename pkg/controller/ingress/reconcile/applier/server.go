@@ -177,7 +177,8 @@ func updateTargetHealthPodCondition(ctx context.Context, kubeClient client.Clien
 	endpoints []albmodel.BackendItem) error {
 	klog.Infof("start updateTargetHealthPodCondition")
 	for _, endpointAndTarget := range endpoints {
-		if len(endpointAndTarget.Pod.Spec.ReadinessGates) == 0 {
+		// the node port backends have no pod
+		if endpointAndTarget.Pod == nil || len(endpointAndTarget.Pod.Spec.ReadinessGates) == 0 {
 			continue
 		}
 		epsKey := types.NamespacedName{
@@ -0,0 +1,239 @@
+package albconfigmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	InsertHeaderValueTypeUserDefined = "UserDefined"
+	TrafficMirrorTargetType          = "ForwardGroupMirror"
+
+	// MaxHeaderActionsPerRule is the number of InsertHeader or RemoveHeader actions ALB accepts in one rule.
+	MaxHeaderActionsPerRule = 20
+)
+
+// ruleActionOrder is the order ALB executes the actions of a rule in. The final
+// action (ForwardGroup, Redirect or FixedResponse) always comes last.
+var ruleActionOrder = map[string]int{
+	util.RuleActionTypeTrafficLimit:  1,
+	util.RuleActionTypeRemoveHeader:  2,
+	util.RuleActionTypeInsertHeader:  3,
+	util.RuleActionTypeRewrite:       4,
+	util.RuleActionTypeTrafficMirror: 5,
+	util.RuleActionTypeForward:       6,
+	util.RuleActionTypeRedirect:      6,
+	util.RuleActionTypeFixedResponse: 6,
+}
+
+func isFinalRuleAction(actionType string) bool {
+	return actionType == util.RuleActionTypeForward ||
+		actionType == util.RuleActionTypeRedirect ||
+		actionType == util.RuleActionTypeFixedResponse
+}
+
+// SortAndValidateRuleActions puts the actions in the order ALB requires and checks
+// the combination is accepted: exactly one final action, at most one TrafficLimit,
+// Rewrite and TrafficMirror, and Rewrite and TrafficMirror only before a ForwardGroup.
+func SortAndValidateRuleActions(actions []alb.Action) ([]alb.Action, error) {
+	counts := make(map[string]int)
+	finalType := ""
+	for _, action := range actions {
+		if _, ok := ruleActionOrder[action.Type]; !ok {
+			return nil, errors.Errorf("unknown action type: %v", action.Type)
+		}
+		counts[action.Type]++
+		if isFinalRuleAction(action.Type) {
+			if finalType != "" {
+				return nil, errors.Errorf("only one of ForwardGroup, Redirect and FixedResponse is allowed, got %v and %v", finalType, action.Type)
+			}
+			finalType = action.Type
+		}
+	}
+	if finalType == "" {
+		return nil, errors.New("missing ForwardGroup, Redirect or FixedResponse action")
+	}
+	for _, t := range []string{util.RuleActionTypeTrafficLimit, util.RuleActionTypeRewrite, util.RuleActionTypeTrafficMirror} {
+		if counts[t] > 1 {
+			return nil, errors.Errorf("at most one %v action is allowed", t)
+		}
+	}
+	for _, t := range []string{util.RuleActionTypeInsertHeader, util.RuleActionTypeRemoveHeader} {
+		if counts[t] > MaxHeaderActionsPerRule {
+			return nil, errors.Errorf("at most %d %v actions are allowed", MaxHeaderActionsPerRule, t)
+		}
+	}
+	if finalType != util.RuleActionTypeForward {
+		for _, t := range []string{util.RuleActionTypeRewrite, util.RuleActionTypeTrafficMirror} {
+			if counts[t] != 0 {
+				return nil, errors.Errorf("%v action must be used with a ForwardGroup action, not %v", t, finalType)
+			}
+		}
+	}
+
+	sorted := make([]alb.Action, len(actions))
+	copy(sorted, actions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return ruleActionOrder[sorted[i].Type] < ruleActionOrder[sorted[j].Type]
+	})
+	return sorted, nil
+}
+
+// buildExtraActions collects the actions an Ingress adds in front of the final action
+// of a path: the rewrite-target and traffic-limit-qps shortcuts, and the actions.<service>
+// json annotation.
+func (t *defaultModelBuildTask) buildExtraActions(ctx context.Context, ing networking.Ingress, path networking.HTTPIngressPath) ([]alb.Action, error) {
+	var actions []alb.Action
+	if v, ok := ing.Annotations[annotations.AlbRewriteTarget]; ok && v != "" {
+		actions = append(actions, alb.Action{
+			Type:          util.RuleActionTypeRewrite,
+			RewriteConfig: &alb.RewriteConfig{Path: v},
+		})
+	}
+	if v, ok := ing.Annotations[annotations.AlbTrafficLimitQps]; ok && v != "" {
+		qps, err := strconv.Atoi(v)
+		if err != nil || qps <= 0 {
+			return nil, errors.Errorf("invalid %v: %v", annotations.AlbTrafficLimitQps, v)
+		}
+		actions = append(actions, alb.Action{
+			Type:               util.RuleActionTypeTrafficLimit,
+			TrafficLimitConfig: &alb.TrafficLimitConfig{QPS: qps},
+		})
+	}
+
+	if path.Backend.Service == nil {
+		return actions, nil
+	}
+	key := fmt.Sprintf("%s.%s", annotations.AlbActions, path.Backend.Service.Name)
+	raw, ok := ing.Annotations[key]
+	if !ok || raw == "" {
+		return actions, nil
+	}
+	var cfgs []alb.Action
+	if err := json.Unmarshal([]byte(raw), &cfgs); err != nil {
+		return nil, errors.Wrapf(err, "invalid %v", key)
+	}
+	for _, cfg := range cfgs {
+		action, err := t.buildExtraAction(ctx, ing, cfg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %v", key)
+		}
+		actions = append(actions, *action)
+	}
+	return actions, nil
+}
+
+func (t *defaultModelBuildTask) buildExtraAction(ctx context.Context, ing networking.Ingress, actionCfg alb.Action) (*alb.Action, error) {
+	switch actionCfg.Type {
+	case util.RuleActionTypeInsertHeader:
+		return t.buildInsertHeaderAction(ctx, actionCfg)
+	case util.RuleActionTypeRemoveHeader:
+		return t.buildRemoveHeaderAction(ctx, actionCfg)
+	case util.RuleActionTypeRewrite:
+		return t.buildRewriteAction(ctx, actionCfg)
+	case util.RuleActionTypeTrafficMirror:
+		return t.buildTrafficMirrorAction(ctx, ing, actionCfg)
+	case util.RuleActionTypeTrafficLimit:
+		return t.buildTrafficLimitAction(ctx, actionCfg)
+	}
+	return nil, errors.Errorf("unsupported action type: %v", actionCfg.Type)
+}
+
+func (t *defaultModelBuildTask) buildInsertHeaderAction(_ context.Context, actionCfg alb.Action) (*alb.Action, error) {
+	if actionCfg.InsertHeaderConfig == nil || len(actionCfg.InsertHeaderConfig.Key) == 0 {
+		return nil, errors.New("missing InsertHeaderConfig")
+	}
+	cfg := *actionCfg.InsertHeaderConfig
+	if cfg.ValueType == "" {
+		cfg.ValueType = InsertHeaderValueTypeUserDefined
+	}
+	return &alb.Action{
+		Type:               util.RuleActionTypeInsertHeader,
+		InsertHeaderConfig: &cfg,
+	}, nil
+}
+
+func (t *defaultModelBuildTask) buildRemoveHeaderAction(_ context.Context, actionCfg alb.Action) (*alb.Action, error) {
+	if actionCfg.RemoveHeaderConfig == nil || len(actionCfg.RemoveHeaderConfig.Key) == 0 {
+		return nil, errors.New("missing RemoveHeaderConfig")
+	}
+	return &alb.Action{
+		Type:               util.RuleActionTypeRemoveHeader,
+		RemoveHeaderConfig: &alb.RemoveHeaderConfig{Key: actionCfg.RemoveHeaderConfig.Key},
+	}, nil
+}
+
+func (t *defaultModelBuildTask) buildRewriteAction(_ context.Context, actionCfg alb.Action) (*alb.Action, error) {
+	if actionCfg.RewriteConfig == nil {
+		return nil, errors.New("missing RewriteConfig")
+	}
+	cfg := *actionCfg.RewriteConfig
+	if cfg.Host == "" && cfg.Path == "" && cfg.Query == "" {
+		return nil, errors.New("RewriteConfig should set at least one of Host, Path and Query")
+	}
+	return &alb.Action{
+		Type:          util.RuleActionTypeRewrite,
+		RewriteConfig: &cfg,
+	}, nil
+}
+
+func (t *defaultModelBuildTask) buildTrafficLimitAction(_ context.Context, actionCfg alb.Action) (*alb.Action, error) {
+	if actionCfg.TrafficLimitConfig == nil || actionCfg.TrafficLimitConfig.QPS <= 0 {
+		return nil, errors.New("missing TrafficLimitConfig")
+	}
+	return &alb.Action{
+		Type: util.RuleActionTypeTrafficLimit,
+		TrafficLimitConfig: &alb.TrafficLimitConfig{
+			QPS:      actionCfg.TrafficLimitConfig.QPS,
+			PerIpQps: actionCfg.TrafficLimitConfig.PerIpQps,
+		},
+	}, nil
+}
+
+// buildTrafficMirrorAction mirrors to Services of the Ingress namespace, referenced by
+// serviceName and servicePort in the mirror server group tuples.
+func (t *defaultModelBuildTask) buildTrafficMirrorAction(ctx context.Context, ing networking.Ingress, actionCfg alb.Action) (*alb.Action, error) {
+	if actionCfg.TrafficMirrorConfig == nil || len(actionCfg.TrafficMirrorConfig.MirrorGroupConfig.ServerGroupTuples) == 0 {
+		return nil, errors.New("missing TrafficMirrorConfig")
+	}
+	var serverGroupTuples []alb.ServerGroupTuple
+	for _, sgp := range actionCfg.TrafficMirrorConfig.MirrorGroupConfig.ServerGroupTuples {
+		if sgp.ServiceName == "" || sgp.ServicePort == 0 {
+			return nil, errors.New("mirror server group needs serviceName and servicePort")
+		}
+		svc := new(corev1.Service)
+		svc.Namespace = ing.Namespace
+		svc.Name = sgp.ServiceName
+		modelSgp, err := t.buildServerGroup(ctx, &ing, svc, sgp.ServicePort)
+		if err != nil {
+			return nil, err
+		}
+		serverGroupTuples = append(serverGroupTuples, alb.ServerGroupTuple{
+			ServerGroupID: modelSgp.ServerGroupID(),
+		})
+	}
+	targetType := actionCfg.TrafficMirrorConfig.TargetType
+	if targetType == "" {
+		targetType = TrafficMirrorTargetType
+	}
+	return &alb.Action{
+		Type: util.RuleActionTypeTrafficMirror,
+		TrafficMirrorConfig: &alb.TrafficMirrorConfig{
+			TargetType: targetType,
+			MirrorGroupConfig: alb.MirrorGroupConfig{
+				ServerGroupTuples: serverGroupTuples,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,55 @@
+package albconfigmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func TestSortAndValidateRuleActions(t *testing.T) {
+	forward := alb.Action{Type: util.RuleActionTypeForward}
+	redirect := alb.Action{Type: util.RuleActionTypeRedirect}
+	rewrite := alb.Action{Type: util.RuleActionTypeRewrite}
+	mirror := alb.Action{Type: util.RuleActionTypeTrafficMirror}
+	limit := alb.Action{Type: util.RuleActionTypeTrafficLimit}
+	insert := alb.Action{Type: util.RuleActionTypeInsertHeader}
+	remove := alb.Action{Type: util.RuleActionTypeRemoveHeader}
+
+	cases := []struct {
+		name    string
+		actions []alb.Action
+		expect  []string
+		err     bool
+	}{
+		{name: "forward only", actions: []alb.Action{forward}, expect: []string{util.RuleActionTypeForward}},
+		{
+			name:    "reorder",
+			actions: []alb.Action{mirror, rewrite, insert, remove, limit, forward},
+			expect: []string{util.RuleActionTypeTrafficLimit, util.RuleActionTypeRemoveHeader, util.RuleActionTypeInsertHeader,
+				util.RuleActionTypeRewrite, util.RuleActionTypeTrafficMirror, util.RuleActionTypeForward},
+		},
+		{name: "header with redirect", actions: []alb.Action{redirect, insert}, expect: []string{util.RuleActionTypeInsertHeader, util.RuleActionTypeRedirect}},
+		{name: "missing final action", actions: []alb.Action{insert}, err: true},
+		{name: "two final actions", actions: []alb.Action{forward, redirect}, err: true},
+		{name: "duplicate rewrite", actions: []alb.Action{rewrite, rewrite, forward}, err: true},
+		{name: "rewrite with redirect", actions: []alb.Action{rewrite, redirect}, err: true},
+		{name: "unknown type", actions: []alb.Action{{Type: "Foo"}, forward}, err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actions, err := SortAndValidateRuleActions(c.actions)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			var types []string
+			for _, a := range actions {
+				types = append(types, a.Type)
+			}
+			assert.Equal(t, c.expect, types)
+		})
+	}
+}
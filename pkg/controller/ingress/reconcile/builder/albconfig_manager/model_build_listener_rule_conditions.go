@@ -0,0 +1,180 @@
+package albconfigmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+
+	"github.com/pkg/errors"
+)
+
+func isResponseRuleCondition(conditionType string) bool {
+	return conditionType == util.RuleConditionFieldResponseHeader ||
+		conditionType == util.RuleConditionFieldResponseStatusCode
+}
+
+func validateRuleCondition(condition alb.Condition) error {
+	switch condition.Type {
+	case util.RuleConditionFieldHost:
+		if len(condition.HostConfig.Values) == 0 {
+			return errors.New("missing HostConfig")
+		}
+	case util.RuleConditionFieldPath:
+		if len(condition.PathConfig.Values) == 0 {
+			return errors.New("missing PathConfig")
+		}
+	case util.RuleConditionFieldHeader:
+		if condition.HeaderConfig.Key == "" || len(condition.HeaderConfig.Values) == 0 {
+			return errors.New("missing HeaderConfig")
+		}
+	case util.RuleConditionFieldQueryString:
+		if len(condition.QueryStringConfig.Values) == 0 {
+			return errors.New("missing QueryStringConfig")
+		}
+		for _, v := range condition.QueryStringConfig.Values {
+			if v.Value == "" {
+				return errors.New("QueryStringConfig value should not be empty")
+			}
+		}
+	case util.RuleConditionFieldMethod:
+		if len(condition.MethodConfig.Values) == 0 {
+			return errors.New("missing MethodConfig")
+		}
+	case util.RuleConditionFieldCookie:
+		if len(condition.CookieConfig.Values) == 0 {
+			return errors.New("missing CookieConfig")
+		}
+		for _, v := range condition.CookieConfig.Values {
+			if v.Value == "" {
+				return errors.New("CookieConfig value should not be empty")
+			}
+		}
+	case util.RuleConditionFieldSourceIp:
+		if len(condition.SourceIpConfig.Values) == 0 {
+			return errors.New("missing SourceIpConfig")
+		}
+	case util.RuleConditionFieldResponseHeader:
+		if condition.ResponseHeaderConfig.Key == "" || len(condition.ResponseHeaderConfig.Values) == 0 {
+			return errors.New("missing ResponseHeaderConfig")
+		}
+	case util.RuleConditionFieldResponseStatusCode:
+		if len(condition.ResponseStatusCodeConfig.Values) == 0 {
+			return errors.New("missing ResponseStatusCodeConfig")
+		}
+	default:
+		return errors.Errorf("unknown condition type: %v", condition.Type)
+	}
+	return nil
+}
+
+// buildExtraConditions parses the conditions.<service> json annotation of a path and
+// splits it into the conditions of the request rule and of the response rule.
+func (t *defaultModelBuildTask) buildExtraConditions(_ context.Context, ing networking.Ingress, path networking.HTTPIngressPath) ([]alb.Condition, []alb.Condition, error) {
+	if path.Backend.Service == nil {
+		return nil, nil, nil
+	}
+	key := fmt.Sprintf("%s.%s", annotations.AlbConditions, path.Backend.Service.Name)
+	raw, ok := ing.Annotations[key]
+	if !ok || raw == "" {
+		return nil, nil, nil
+	}
+	var cfgs []alb.Condition
+	if err := json.Unmarshal([]byte(raw), &cfgs); err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid %v", key)
+	}
+	var requestConditions, responseConditions []alb.Condition
+	for _, cfg := range cfgs {
+		if err := validateRuleCondition(cfg); err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid %v", key)
+		}
+		if isResponseRuleCondition(cfg.Type) {
+			responseConditions = append(responseConditions, cfg)
+		} else {
+			requestConditions = append(requestConditions, cfg)
+		}
+	}
+	return requestConditions, responseConditions, nil
+}
+
+// buildResponseRuleActions parses the response-actions.<service> json annotation of a path.
+// Rules of the response direction only accept header actions.
+func (t *defaultModelBuildTask) buildResponseRuleActions(ctx context.Context, ing networking.Ingress, path networking.HTTPIngressPath) ([]alb.Action, error) {
+	if path.Backend.Service == nil {
+		return nil, nil
+	}
+	key := fmt.Sprintf("%s.%s", annotations.AlbResponseActions, path.Backend.Service.Name)
+	raw, ok := ing.Annotations[key]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+	var cfgs []alb.Action
+	if err := json.Unmarshal([]byte(raw), &cfgs); err != nil {
+		return nil, errors.Wrapf(err, "invalid %v", key)
+	}
+	var actions []alb.Action
+	for _, cfg := range cfgs {
+		if cfg.Type != util.RuleActionTypeInsertHeader && cfg.Type != util.RuleActionTypeRemoveHeader {
+			return nil, errors.Errorf("invalid %v: unsupported response action type: %v", key, cfg.Type)
+		}
+		action, err := t.buildExtraAction(ctx, ing, cfg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %v", key)
+		}
+		actions = append(actions, *action)
+	}
+	if len(actions) == 0 {
+		return nil, errors.Errorf("invalid %v: no actions", key)
+	}
+	// validate against a placeholder final action, response rules have none
+	sorted, err := SortAndValidateRuleActions(append(actions, alb.Action{Type: util.RuleActionTypeForward}))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %v", key)
+	}
+	return sorted[:len(sorted)-1], nil
+}
+
+// buildResponseRule builds the rule that ALB runs in the response phase of a path,
+// or nil when the path has neither response conditions nor response actions.
+func (t *defaultModelBuildTask) buildResponseRule(ctx context.Context, ing networking.Ingress, path networking.HTTPIngressPath, conditions []alb.Condition) (*alb.ListenerRule, error) {
+	actions, err := t.buildResponseRuleActions(ctx, ing, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(actions) == 0 && len(conditions) == 0 {
+		return nil, nil
+	}
+	if len(actions) == 0 {
+		return nil, errors.Errorf("response conditions need %v.%v", annotations.AlbResponseActions, path.Backend.Service.Name)
+	}
+	if len(conditions) == 0 {
+		return nil, errors.Errorf("response actions need a %v or %v condition", util.RuleConditionFieldResponseHeader, util.RuleConditionFieldResponseStatusCode)
+	}
+	return &alb.ListenerRule{
+		Spec: alb.ListenerRuleSpec{
+			ALBListenerRuleSpec: alb.ALBListenerRuleSpec{
+				Direction:      util.RuleDirectionResponse,
+				RuleActions:    actions,
+				RuleConditions: conditions,
+			},
+		},
+	}, nil
+}
+
+// appendResponseRule adds the response rule unless the listener already has one with the
+// same conditions and actions. Response rules apply to the whole listener, so the paths
+// sharing a response rule need it only once.
+func appendResponseRule(rules []alb.ListenerRule, rule alb.ListenerRule) []alb.ListenerRule {
+	for _, r := range rules {
+		if reflect.DeepEqual(r.Spec.RuleConditions, rule.Spec.RuleConditions) &&
+			reflect.DeepEqual(r.Spec.RuleActions, rule.Spec.RuleActions) {
+			return rules
+		}
+	}
+	return append(rules, rule)
+}
@@ -0,0 +1,88 @@
+package albconfigmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func TestBuildExtraConditions(t *testing.T) {
+	path := networking.HTTPIngressPath{
+		Path: "/",
+		Backend: networking.IngressBackend{
+			Service: &networking.IngressServiceBackend{Name: "demo"},
+		},
+	}
+	cases := []struct {
+		name     string
+		raw      string
+		request  []string
+		response []string
+		err      bool
+	}{
+		{name: "no annotation"},
+		{
+			name: "request and response",
+			raw: `[{"Type":"Method","MethodConfig":{"Values":["GET"]}},
+				{"Type":"SourceIp","SourceIpConfig":{"Values":["10.0.0.0/8"]}},
+				{"Type":"ResponseStatusCode","ResponseStatusCodeConfig":{"Values":["404"]}}]`,
+			request:  []string{util.RuleConditionFieldMethod, util.RuleConditionFieldSourceIp},
+			response: []string{util.RuleConditionFieldResponseStatusCode},
+		},
+		{name: "missing query value", raw: `[{"Type":"QueryString","QueryStringConfig":{"Values":[{"Key":"a"}]}}]`, err: true},
+		{name: "missing response header key", raw: `[{"Type":"ResponseHeader","ResponseHeaderConfig":{"Values":["a"]}}]`, err: true},
+		{name: "unknown type", raw: `[{"Type":"Foo"}]`, err: true},
+		{name: "bad json", raw: `{`, err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ing := networking.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}}}
+			if c.raw != "" {
+				ing.Annotations[annotations.AlbConditions+".demo"] = c.raw
+			}
+			task := &defaultModelBuildTask{}
+			request, response, err := task.buildExtraConditions(context.TODO(), ing, path)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			var requestTypes, responseTypes []string
+			for _, cond := range request {
+				requestTypes = append(requestTypes, cond.Type)
+			}
+			for _, cond := range response {
+				responseTypes = append(responseTypes, cond.Type)
+			}
+			assert.Equal(t, c.request, requestTypes)
+			assert.Equal(t, c.response, responseTypes)
+		})
+	}
+}
+
+func TestAppendResponseRule(t *testing.T) {
+	newRule := func(code string) alb.ListenerRule {
+		return alb.ListenerRule{Spec: alb.ListenerRuleSpec{ALBListenerRuleSpec: alb.ALBListenerRuleSpec{
+			Direction: util.RuleDirectionResponse,
+			RuleConditions: []alb.Condition{{
+				Type:                     util.RuleConditionFieldResponseStatusCode,
+				ResponseStatusCodeConfig: alb.ResponseStatusCodeConfig{Values: []string{code}},
+			}},
+			RuleActions: []alb.Action{{
+				Type:               util.RuleActionTypeRemoveHeader,
+				RemoveHeaderConfig: &alb.RemoveHeaderConfig{Key: "server"},
+			}},
+		}}}
+	}
+	var rules []alb.ListenerRule
+	rules = appendResponseRule(rules, newRule("500"))
+	rules = appendResponseRule(rules, newRule("500"))
+	rules = appendResponseRule(rules, newRule("502"))
+	assert.Len(t, rules, 2)
+}
@@ -26,7 +26,7 @@ const (
 )
 
 func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID core.StringToken, port int32, ingList []networking.Ingress) error {
-	var rules []alb.ListenerRule
+	var rules, responseRules []alb.ListenerRule
 	carryWeight := make(map[string][]alb.ServerGroupTuple)
 	for _, ing := range ingList {
 		if v := annotations.GetStringAnnotationMutil(annotations.NginxCanary, annotations.AlbCanary, &ing); v == "true" {
@@ -100,10 +100,35 @@ func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID cor
 				if err != nil {
 					return errors.Wrapf(err, "ingress: %v", util.NamespacedName(&ing))
 				}
+				actions := []alb.Action{action2}
+				// the ssl redirect rule only sends clients to https, extra actions belong to the https rule
+				if action2.Type == util.RuleActionTypeForward {
+					extraActions, err := t.buildExtraActions(ctx, ing, path)
+					if err != nil {
+						return errors.Wrapf(err, "ingress: %v", util.NamespacedName(&ing))
+					}
+					actions, err = SortAndValidateRuleActions(append(extraActions, action2))
+					if err != nil {
+						return errors.Wrapf(err, "ingress: %v", util.NamespacedName(&ing))
+					}
+					requestConditions, responseConditions, err := t.buildExtraConditions(ctx, ing, path)
+					if err != nil {
+						return errors.Wrapf(err, "ingress: %v", util.NamespacedName(&ing))
+					}
+					conditions = append(conditions, requestConditions...)
+					responseRule, err := t.buildResponseRule(ctx, ing, path, responseConditions)
+					if err != nil {
+						return errors.Wrapf(err, "ingress: %v", util.NamespacedName(&ing))
+					}
+					if responseRule != nil {
+						responseRule.Spec.ListenerID = lsID
+						responseRules = appendResponseRule(responseRules, *responseRule)
+					}
+				}
 				lrs := alb.ListenerRuleSpec{
 					ListenerID: lsID,
 				}
-				lrs.RuleActions = []alb.Action{action2}
+				lrs.RuleActions = actions
 				lrs.RuleConditions = conditions
 				rules = append(rules, alb.ListenerRule{
 					Spec: lrs,
@@ -112,6 +137,8 @@ func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID cor
 		}
 	}
 
+	// response rules take the priorities after the request rules
+	rules = append(rules, responseRules...)
 	priority := 1
 	for _, rule := range rules {
 		ruleResID := fmt.Sprintf("%v:%v", port, priority)
@@ -120,6 +147,7 @@ func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID cor
 			ListenerID: lsID,
 		}
 		lrs.Priority = priority
+		lrs.Direction = rule.Spec.Direction
 		lrs.RuleConditions = rule.Spec.RuleConditions
 		lrs.RuleActions = rule.Spec.RuleActions
 		lrs.RuleName = fmt.Sprintf("%v-%v-%v", ListenerRuleNamePrefix, port, priority)
@@ -66,6 +66,7 @@ func (b defaultAlbConfigManagerBuilder) Build(ctx context.Context, albconfig *v1
 
 		annotationParser: annotations.NewSuffixAnnotationParser(annotations.DefaultAnnotationsPrefix),
 		certDiscovery:    NewCASCertDiscovery(b.cloud, b.logger),
+		secretCertMgr:    NewDefaultSecretCertManager(b.kubeClient, b.cloud, b.logger),
 		vSwitchResolver:  NewDefaultVSwitchResolver(b.cloud, vpcID, b.logger),
 
 		defaultServerGroupScheduler:     util.DefaultServerGroupScheduler,
@@ -99,6 +100,7 @@ type defaultModelBuildTask struct {
 
 	annotationParser annotations.Parser
 	certDiscovery    CertDiscovery
+	secretCertMgr    SecretCertManager
 	vSwitchResolver  VSwitchResolver
 
 	backendServices map[types.NamespacedName]*corev1.Service
@@ -151,6 +153,20 @@ func removeDuplicateElement(elements []string) []string {
 	return result
 }
 
+// CertificateResolveError is returned by Build when the certificates of a https listener
+// can't be resolved, so that it is reported apart from the other build errors.
+type CertificateResolveError struct {
+	Err error
+}
+
+func (e *CertificateResolveError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CertificateResolveError) Unwrap() error {
+	return e.Err
+}
+
 func (t *defaultModelBuildTask) run(ctx context.Context) error {
 	if !t.albconfig.DeletionTimestamp.IsZero() {
 		return nil
@@ -197,12 +213,12 @@ func (t *defaultModelBuildTask) run(ctx context.Context) error {
 					cert, err := t.computeIngressInferredTLSCertIDs(ctx, &ing)
 					if err != nil {
 						klog.Errorf("computeIngressInferredTLSCertARNs error: %s", err.Error())
-						return err
+						return &CertificateResolveError{Err: err}
 					}
 					certIDs = append(certIDs, cert...)
 				}
 				if len(certIDs) == 0 {
-					return fmt.Errorf("no cert was discovered: %v", certIDs)
+					return &CertificateResolveError{Err: fmt.Errorf("no cert was discovered: %v", certIDs)}
 				}
 				certIDs = removeDuplicateElement(certIDs)
 				sort.Strings(certIDs)
@@ -236,7 +252,7 @@ func (t *defaultModelBuildTask) run(ctx context.Context) error {
 				}
 			}
 			if !isDefaultCertExist {
-				return fmt.Errorf("https listener: %d must provider one default cert", ls.Spec.ListenerPort)
+				return &CertificateResolveError{Err: fmt.Errorf("https listener: %d must provider one default cert", ls.Spec.ListenerPort)}
 			}
 		}
 	}
@@ -245,16 +261,43 @@ func (t *defaultModelBuildTask) run(ctx context.Context) error {
 }
 
 func (t *defaultModelBuildTask) computeIngressInferredTLSCertIDs(ctx context.Context, ing *networking.Ingress) ([]string, error) {
+	var certIDs []string
+	secretHosts := sets.NewString()
 	hosts := sets.NewString()
+	secretForAllHosts := false
+	for _, tls := range ing.Spec.TLS {
+		if tls.SecretName != "" {
+			certID, found, err := t.secretCertMgr.Sync(ctx, types.NamespacedName{Namespace: ing.Namespace, Name: tls.SecretName})
+			if err != nil {
+				return nil, err
+			}
+			// fall back to discovery for secrets that don't exist, as before secrets were synced
+			if found {
+				certIDs = append(certIDs, certID)
+				secretHosts.Insert(tls.Hosts...)
+				// a secret without hosts serves every host of the ingress
+				if len(tls.Hosts) == 0 {
+					secretForAllHosts = true
+				}
+				continue
+			}
+		}
+		hosts.Insert(tls.Hosts...)
+	}
 	for _, r := range ing.Spec.Rules {
 		if len(r.Host) != 0 {
 			hosts.Insert(r.Host)
 		}
 	}
-	for _, t := range ing.Spec.TLS {
-		hosts.Insert(t.Hosts...)
+	hosts = hosts.Difference(secretHosts)
+	if hosts.Len() == 0 || secretForAllHosts {
+		return certIDs, nil
+	}
+	discovered, err := t.certDiscovery.Discover(ctx, hosts.List())
+	if err != nil {
+		return nil, err
 	}
-	return t.certDiscovery.Discover(ctx, hosts.List())
+	return append(certIDs, discovered...), nil
 }
 
 func ComputeIngressListenPorts(ing *networking.Ingress) (map[int32]Protocol, error) {
@@ -0,0 +1,167 @@
+package albconfigmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SecretCertNamePrefix is the name prefix of the CAS certificates uploaded from TLS Secrets.
+	// The name is <prefix>-<cluster hash>-<secret hash>-<content hash>.
+	SecretCertNamePrefix = "k8s"
+	secretCertHashLength = 8
+	// SecretCertTagKey tags the certificates uploaded from TLS Secrets, together with the
+	// cluster tag it tells the certificates the controller owns from the ones of users.
+	SecretCertTagKey = util.IngressTagKeyPrefix + "/secret_cert"
+)
+
+type SecretCertManager interface {
+	// Sync uploads the certificate of the TLS Secret to CAS when it is not there yet,
+	// and returns its CertIdentifier. found is false when the Secret does not exist.
+	Sync(ctx context.Context, secretKey types.NamespacedName) (certID string, found bool, err error)
+	// GarbageCollect deletes the certificates uploaded by this cluster which no longer
+	// match a TLS Secret referenced by the Ingresses.
+	GarbageCollect(ctx context.Context, ings []networking.Ingress) error
+}
+
+func NewDefaultSecretCertManager(kubeClient client.Client, cloud prvd.Provider, logger logr.Logger) *defaultSecretCertManager {
+	return &defaultSecretCertManager{
+		kubeClient: kubeClient,
+		cloud:      cloud,
+		logger:     logger,
+	}
+}
+
+var _ SecretCertManager = &defaultSecretCertManager{}
+
+type defaultSecretCertManager struct {
+	kubeClient client.Client
+	cloud      prvd.Provider
+	logger     logr.Logger
+}
+
+func shortHash(data ...string) string {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write([]byte(d))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:secretCertHashLength]
+}
+
+func secretCertNamePrefix(clusterID string) string {
+	return fmt.Sprintf("%s-%s-", SecretCertNamePrefix, shortHash(clusterID))
+}
+
+// BuildSecretCertName returns the CAS certificate name of a TLS Secret. Rotating the
+// Secret changes the name, so a new certificate is uploaded and the old one collected.
+func BuildSecretCertName(clusterID string, secretKey types.NamespacedName, cert, key []byte) string {
+	return fmt.Sprintf("%s%s-%s", secretCertNamePrefix(clusterID),
+		shortHash(secretKey.String()), shortHash(string(cert), string(key)))
+}
+
+func (m *defaultSecretCertManager) secretCertTags() []tag.Tag {
+	return []tag.Tag{
+		{Key: util.ClusterTagKey, Value: m.cloud.ClusterID()},
+		{Key: SecretCertTagKey, Value: "true"},
+	}
+}
+
+func (m *defaultSecretCertManager) loadSecretCertName(ctx context.Context, secretKey types.NamespacedName) (string, *corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := m.kubeClient.Get(ctx, secretKey, secret); err != nil {
+		return "", nil, err
+	}
+	cert, key := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if len(cert) == 0 || len(key) == 0 {
+		return "", nil, errors.Errorf("secret %v has no %v or %v", secretKey, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+	return BuildSecretCertName(m.cloud.ClusterID(), secretKey, cert, key), secret, nil
+}
+
+func (m *defaultSecretCertManager) Sync(ctx context.Context, secretKey types.NamespacedName) (string, bool, error) {
+	certName, secret, err := m.loadSecretCertName(ctx, secretKey)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	certs, err := m.cloud.DescribeSSLCertificateListByTags(ctx, m.secretCertTags())
+	if err != nil {
+		return "", false, err
+	}
+	for _, c := range certs {
+		if c.CertName == certName {
+			return c.CertIdentifier, true, nil
+		}
+	}
+
+	m.logger.Info("uploading certificate of secret", "secret", secretKey.String(), "certName", certName)
+	certID, err := m.cloud.UploadSSLCertificate(ctx, certName,
+		string(secret.Data[corev1.TLSCertKey]), string(secret.Data[corev1.TLSPrivateKeyKey]), m.secretCertTags())
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed to upload certificate of secret %v", secretKey)
+	}
+	return certID, true, nil
+}
+
+func (m *defaultSecretCertManager) GarbageCollect(ctx context.Context, ings []networking.Ingress) error {
+	inUse := sets.NewString()
+	for _, ing := range ings {
+		for _, tls := range ing.Spec.TLS {
+			if tls.SecretName == "" {
+				continue
+			}
+			secretKey := types.NamespacedName{Namespace: ing.Namespace, Name: tls.SecretName}
+			certName, _, err := m.loadSecretCertName(ctx, secretKey)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				// keep every certificate when a referenced Secret can't be read
+				return err
+			}
+			inUse.Insert(certName)
+		}
+	}
+
+	// only the certificates tagged by this cluster are collected, users may upload certificates
+	// named like the ones of the controller
+	certs, err := m.cloud.DescribeSSLCertificateListByTags(ctx, m.secretCertTags())
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for _, c := range certs {
+		if inUse.Has(c.CertName) {
+			continue
+		}
+		m.logger.Info("deleting unused certificate of secret", "certName", c.CertName, "certID", c.CertIdentifier)
+		// certificates still attached to a listener fail to delete and are retried next time
+		if err := m.cloud.DeleteSSLCertificate(ctx, c.CertIdentifier); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) != 0 {
+		return errors.Errorf("failed to delete certificates: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
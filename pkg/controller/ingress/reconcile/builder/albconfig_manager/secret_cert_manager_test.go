@@ -0,0 +1,62 @@
+package albconfigmanager
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBuildSecretCertName(t *testing.T) {
+	secretKey := types.NamespacedName{Namespace: "default", Name: "tls"}
+	name := BuildSecretCertName("c1", secretKey, []byte("cert"), []byte("key"))
+
+	assert.Equal(t, name, BuildSecretCertName("c1", secretKey, []byte("cert"), []byte("key")))
+	assert.True(t, strings.HasPrefix(name, secretCertNamePrefix("c1")))
+	assert.False(t, strings.HasPrefix(name, secretCertNamePrefix("c2")))
+	assert.NotEqual(t, name, BuildSecretCertName("c1", secretKey, []byte("cert2"), []byte("key")))
+	assert.NotEqual(t, name, BuildSecretCertName("c1", types.NamespacedName{Namespace: "default", Name: "tls2"}, []byte("cert"), []byte("key")))
+	assert.LessOrEqual(t, len(name), 64)
+}
+
+func TestSecretCertManager(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "tls"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+	kubeClient := fake.NewClientBuilder().WithObjects(secret).Build()
+	cloud := snapshot.NewSnapshotCloud(&snapshot.Snapshot{ClusterID: "c1"})
+	ctx := context.TODO()
+	secretKey := types.NamespacedName{Namespace: "default", Name: "tls"}
+	// a certificate of a user named like the ones of the controller
+	userCertName := BuildSecretCertName("c1", secretKey, []byte("old"), []byte("key"))
+	userCertID, err := cloud.UploadSSLCertificate(ctx, userCertName, "old", "key", nil)
+	assert.NoError(t, err)
+
+	mgr := NewDefaultSecretCertManager(kubeClient, cloud, ctrl.Log.WithName("test"))
+	certID, found, err := mgr.Sync(ctx, secretKey)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	again, _, err := mgr.Sync(ctx, secretKey)
+	assert.NoError(t, err)
+	assert.Equal(t, certID, again)
+
+	ing := networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ing"},
+		Spec:       networking.IngressSpec{TLS: []networking.IngressTLS{{SecretName: "tls"}}},
+	}
+	assert.NoError(t, mgr.GarbageCollect(ctx, []networking.Ingress{ing}))
+	assert.NoError(t, mgr.GarbageCollect(ctx, nil))
+	certs, err := cloud.DescribeSSLCertificateList(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, certs, 1)
+	assert.Equal(t, userCertID, certs[0].CertIdentifier)
+}
@@ -0,0 +1,155 @@
+package albconfigmanager
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+
+	"github.com/pkg/errors"
+)
+
+// newValidationTask returns a task which only builds the parts of the model that do not call the OpenAPIs.
+func newValidationTask(id string) *defaultModelBuildTask {
+	return &defaultModelBuildTask{
+		stack:      core.NewDefaultManager(core.StackID(types.NamespacedName{Name: id})),
+		sgpByResID: make(map[string]*alb.ServerGroup),
+	}
+}
+
+// ValidateIngress checks the ALB annotations of the Ingress with the builders of the model, without calling
+// the OpenAPIs. The values the builders ignore are returned as warnings.
+func ValidateIngress(ctx context.Context, ing *networking.Ingress) ([]string, error) {
+	var warnings []string
+	if _, err := ComputeIngressListenPorts(ing); err != nil {
+		return warnings, err
+	}
+	for _, key := range []string{
+		annotations.HealthCheckTimeout,
+		annotations.HealthCheckInterval,
+		annotations.HealthThreshold,
+		annotations.UnHealthThreshold,
+	} {
+		if v, ok := ing.Annotations[key]; ok {
+			if _, err := strconv.Atoi(v); err != nil {
+				warnings = append(warnings, fmt.Sprintf("annotation %s is ignored, it must be an integer: %s", key, v))
+			}
+		}
+	}
+	if v := annotations.GetStringAnnotationMutil(annotations.NginxCanary, annotations.AlbCanary, ing); v == "true" {
+		if w := annotations.GetStringAnnotationMutil(annotations.NginxCanaryWeight, annotations.AlbCanaryWeight, ing); w != "" {
+			if weight, err := strconv.Atoi(w); err != nil || weight < 0 || weight > 100 {
+				return warnings, errors.Errorf("canary weight must be an integer within [0, 100]: %v", w)
+			}
+		}
+	}
+
+	t := newValidationTask(util.NamespacedName(ing).String())
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if _, err := t.buildRuleConditions(ctx, rule, path, *ing); err != nil {
+				return warnings, errors.Wrapf(err, "path: %v", path.Path)
+			}
+			extraActions, err := t.buildExtraActions(ctx, *ing, path)
+			if err != nil {
+				return warnings, errors.Wrapf(err, "path: %v", path.Path)
+			}
+			// validate against a placeholder final action, the forward action is built from the backend
+			if _, err := SortAndValidateRuleActions(append(extraActions, alb.Action{Type: util.RuleActionTypeForward})); err != nil {
+				return warnings, errors.Wrapf(err, "path: %v", path.Path)
+			}
+			_, responseConditions, err := t.buildExtraConditions(ctx, *ing, path)
+			if err != nil {
+				return warnings, errors.Wrapf(err, "path: %v", path.Path)
+			}
+			if _, err := t.buildResponseRule(ctx, *ing, path, responseConditions); err != nil {
+				return warnings, errors.Wrapf(err, "path: %v", path.Path)
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// ValidateAlbConfig checks the AlbConfig with the builders of the model, without calling the OpenAPIs.
+// oldAlbConfig is the AlbConfig before the update, or nil on creation, and is used to reject the changes
+// the load balancer does not accept once created. The values which are likely to be a mistake are
+// returned as warnings.
+func ValidateAlbConfig(ctx context.Context, albconfig, oldAlbConfig *v1.AlbConfig) ([]string, error) {
+	var warnings []string
+	if !albconfig.DeletionTimestamp.IsZero() {
+		return warnings, nil
+	}
+	lb := albconfig.Spec.LoadBalancer
+	if lb == nil {
+		return warnings, errors.New("spec.config is required")
+	}
+	if lb.DeletionProtectionEnabled == nil {
+		return warnings, errors.New("spec.config.deletionProtectionEnabled is required")
+	}
+	if lb.Id == "" && len(lb.ZoneMappings) == 1 {
+		return warnings, errors.New("spec.config.zoneMappings needs at least two vswitches")
+	}
+	if lb.Id != "" && len(lb.ZoneMappings) != 0 {
+		warnings = append(warnings, "spec.config.zoneMappings is ignored when spec.config.id is set")
+	}
+
+	t := newValidationTask(albconfig.Name)
+	// the default actions of the listeners are built in the namespace of the AlbConfig, which is cluster scoped
+	t.albconfig = albconfig.DeepCopy()
+	if t.albconfig.Namespace == "" {
+		t.albconfig.Namespace = ALBConfigNamespace
+	}
+	t.defaultListenerPort = util.DefaultListenerPort
+	t.defaultListenerProtocol = util.DefaultListenerProtocol
+	ports := make(map[int]bool)
+	for _, ls := range albconfig.Spec.Listeners {
+		if ls == nil {
+			continue
+		}
+		spec, err := t.buildListenerSpec(ctx, core.LiteralStringToken(""), ls)
+		if err != nil {
+			return warnings, errors.Wrapf(err, "listener: %v", ls.Port.String())
+		}
+		if spec.ListenerPort < 1 || spec.ListenerPort > 65535 {
+			return warnings, errors.Errorf("listener port must be within [1, 65535]: %v", ls.Port.String())
+		}
+		if ports[spec.ListenerPort] {
+			return warnings, errors.Errorf("duplicate listener port: %v", spec.ListenerPort)
+		}
+		ports[spec.ListenerPort] = true
+		switch spec.ListenerProtocol {
+		case string(ProtocolHTTP):
+			if len(ls.Certificates) != 0 {
+				warnings = append(warnings, fmt.Sprintf("the certificates of listener %d are ignored, it is not an %v listener",
+					spec.ListenerPort, ProtocolHTTPS))
+			}
+		case string(ProtocolHTTPS), util.ListenerProtocolQUIC:
+		default:
+			return warnings, errors.Errorf("listener protocol must be within [%v, %v, %v]: %v",
+				ProtocolHTTP, ProtocolHTTPS, util.ListenerProtocolQUIC, spec.ListenerProtocol)
+		}
+	}
+
+	if oldAlbConfig == nil || oldAlbConfig.Spec.LoadBalancer == nil {
+		return warnings, nil
+	}
+	oldLb := oldAlbConfig.Spec.LoadBalancer
+	if oldLb.Id != "" && lb.Id != oldLb.Id {
+		return warnings, errors.Errorf("spec.config.id can not be changed, from [%v] to [%v]", oldLb.Id, lb.Id)
+	}
+	if albconfig.Status.LoadBalancer.Id != "" && oldLb.AddressAllocatedMode != lb.AddressAllocatedMode {
+		return warnings, errors.Errorf("spec.config.addressAllocatedMode can not be changed once the load balancer is created, from [%v] to [%v]",
+			oldLb.AddressAllocatedMode, lb.AddressAllocatedMode)
+	}
+	return warnings, nil
+}
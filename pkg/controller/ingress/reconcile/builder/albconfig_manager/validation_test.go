@@ -0,0 +1,111 @@
+package albconfigmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+)
+
+func getValidationIngress() *networking.Ingress {
+	pathType := networking.PathTypePrefix
+	return &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ing",
+			Namespace:   "default",
+			Annotations: map[string]string{},
+		},
+		Spec: networking.IngressSpec{
+			Rules: []networking.IngressRule{
+				{
+					Host: "example.com",
+					IngressRuleValue: networking.IngressRuleValue{
+						HTTP: &networking.HTTPIngressRuleValue{
+							Paths: []networking.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networking.IngressBackend{
+										Service: &networking.IngressServiceBackend{
+											Name: "svc",
+											Port: networking.ServiceBackendPort{Number: 80},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestValidateIngress(t *testing.T) {
+	ing := getValidationIngress()
+	warnings, err := ValidateIngress(context.TODO(), ing)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(warnings))
+
+	ing.Annotations[annotations.HealthCheckInterval] = "2s"
+	warnings, err = ValidateIngress(context.TODO(), ing)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(warnings))
+
+	ing = getValidationIngress()
+	ing.Annotations[annotations.AlbCanary] = "true"
+	ing.Annotations[annotations.AlbCanaryWeight] = "120"
+	_, err = ValidateIngress(context.TODO(), ing)
+	assert.Error(t, err)
+
+	ing = getValidationIngress()
+	ing.Annotations[annotations.AlbActions+".svc"] = "[{"
+	_, err = ValidateIngress(context.TODO(), ing)
+	assert.Error(t, err)
+}
+
+func getValidationAlbConfig() *v1.AlbConfig {
+	enabled := true
+	return &v1.AlbConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "alb"},
+		Spec: v1.AlbConfigSpec{
+			LoadBalancer: &v1.LoadBalancerSpec{
+				DeletionProtectionEnabled: &enabled,
+				ZoneMappings: []v1.ZoneMapping{
+					{VSwitchId: "vsw-1"},
+					{VSwitchId: "vsw-2"},
+				},
+			},
+			Listeners: []*v1.ListenerSpec{
+				{Port: intstr.FromInt(80), Protocol: "HTTP"},
+			},
+		},
+	}
+}
+
+func TestValidateAlbConfig(t *testing.T) {
+	albconfig := getValidationAlbConfig()
+	_, err := ValidateAlbConfig(context.TODO(), albconfig, nil)
+	assert.NoError(t, err)
+
+	albconfig.Spec.Listeners = append(albconfig.Spec.Listeners, &v1.ListenerSpec{Port: intstr.FromInt(80), Protocol: "HTTP"})
+	_, err = ValidateAlbConfig(context.TODO(), albconfig, nil)
+	assert.Error(t, err)
+
+	albconfig = getValidationAlbConfig()
+	albconfig.Spec.Listeners[0].Protocol = "TCP"
+	_, err = ValidateAlbConfig(context.TODO(), albconfig, nil)
+	assert.Error(t, err)
+
+	oldAlbConfig := getValidationAlbConfig()
+	oldAlbConfig.Spec.LoadBalancer.Id = "alb-1"
+	albconfig = getValidationAlbConfig()
+	albconfig.Spec.LoadBalancer.Id = "alb-2"
+	_, err = ValidateAlbConfig(context.TODO(), albconfig, oldAlbConfig)
+	assert.Error(t, err)
+}
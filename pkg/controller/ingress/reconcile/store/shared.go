@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"github.com/eapache/channels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/context/shared"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const sharedStoreKey = "IngressStore"
+
+var sharedStoreMu sync.Mutex
+
+// SharedStore is the store shared by the controllers of the manager, so that the informers of the endpoints,
+// pods and ingresses are not duplicated. It is started once as a runnable of the manager, and the controllers
+// wait for it to sync instead of running it. The updates of the store are sent to UpdateCh, which is consumed
+// by the ingress controller only.
+type SharedStore struct {
+	Storer
+	UpdateCh *channels.RingChannel
+
+	synced chan struct{}
+}
+
+// Shared returns the store shared by the controllers of the manager. The store is created and added to the
+// manager by the first controller asking for it.
+func Shared(ctx *shared.SharedContext, mgr manager.Manager) (*SharedStore, error) {
+	sharedStoreMu.Lock()
+	defer sharedStoreMu.Unlock()
+	if v, ok := ctx.Value(sharedStoreKey); ok {
+		return v.(*SharedStore), nil
+	}
+	client, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	updateCh := channels.NewRingChannel(1024)
+	s := &SharedStore{
+		Storer:   New("", 0, client, updateCh, false),
+		UpdateCh: updateCh,
+		synced:   make(chan struct{}),
+	}
+	if err := mgr.Add(s); err != nil {
+		return nil, err
+	}
+	ctx.SetKV(sharedStoreKey, s)
+	return s, nil
+}
+
+// Start runs the informers of the store until the manager stops.
+func (s *SharedStore) Start(ctx context.Context) error {
+	stopCh := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(stopCh)
+	}()
+	s.Storer.Run(stopCh)
+	close(s.synced)
+	<-ctx.Done()
+	return nil
+}
+
+// WaitForSync waits for the informers of the store to sync, it returns false if stopCh is closed first.
+func (s *SharedStore) WaitForSync(stopCh <-chan struct{}) bool {
+	select {
+	case <-s.synced:
+		return true
+	case <-stopCh:
+		return false
+	}
+}
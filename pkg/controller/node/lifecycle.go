@@ -0,0 +1,256 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+	"k8s.io/cloud-provider/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TaintSpotInterruption is set on the node of a spot instance which is about to be reclaimed
+	TaintSpotInterruption = "node.alibabacloud.com/spot-interruption"
+
+	instanceStatusStopped = "Stopped"
+	lockReasonRecycling   = "Recycling"
+	spotStrategyNoSpot    = "NoSpot"
+
+	lifecycleEventShutdown         = "shutdown"
+	lifecycleEventSpotInterruption = "spot_interruption"
+)
+
+// Interruption is the notice that an instance is about to be reclaimed.
+type Interruption struct {
+	Reason string
+	// Time is when the instance is reclaimed, zero if unknown
+	Time time.Time
+}
+
+// InterruptionSource reports the instances which are about to be reclaimed, besides the spot instances
+// the instance status tells, e.g. from the events of the cloud monitor or the instance metadata.
+type InterruptionSource interface {
+	// Interruptions returns the notices of the instances with the provider ids, keyed by the provider id
+	Interruptions(ctx context.Context, providerIds []string) (map[string]Interruption, error)
+}
+
+var (
+	sourceLock          sync.Mutex
+	interruptionSources []InterruptionSource
+)
+
+// RegisterInterruptionSource adds a source of the interruption notices, it is called before the
+// node controller is added to the manager.
+func RegisterInterruptionSource(s InterruptionSource) {
+	sourceLock.Lock()
+	defer sourceLock.Unlock()
+	interruptionSources = append(interruptionSources, s)
+}
+
+func registeredInterruptionSources() []InterruptionSource {
+	sourceLock.Lock()
+	defer sourceLock.Unlock()
+	return append([]InterruptionSource{}, interruptionSources...)
+}
+
+// spotInterruption returns the notice of the spot instance which is locked for recycling, or nil.
+func spotInterruption(ins *prvd.NodeAttribute) *Interruption {
+	if ins.SpotStrategy == "" || ins.SpotStrategy == spotStrategyNoSpot {
+		return nil
+	}
+	for _, r := range ins.LockReasons {
+		if r == lockReasonRecycling {
+			return &Interruption{Reason: r}
+		}
+	}
+	return nil
+}
+
+// syncNodeLifecycle taints the nodes of the stopped instances with the shutdown taint, and removes it once
+// the instances are running again. The nodes of the instances about to be reclaimed are tainted and cordoned,
+// which is not reverted, as the reclamation can not be cancelled. The labels of the node label mappings are
+// kept in sync as well. The instances are listed by the caller, and the spot ones are recorded to be
+// checked more often.
+func (m *ReconcileNode) syncNodeLifecycle(nodes []corev1.Node, instances map[string]*prvd.NodeAttribute) {
+	start := time.Now()
+	defer func() {
+		metric.NodeLatency.WithLabelValues("sync_lifecycle").Observe(metric.MsSince(start))
+	}()
+
+	m.recordSpotInstances(nodes, instances)
+
+	interruptions := map[string]Interruption{}
+	for _, s := range m.interruptionSources {
+		notices, err := s.Interruptions(context.TODO(), nodeids(nodes))
+		if err != nil {
+			log.Error(err, "get interruption notices error, wait for next retry")
+			continue
+		}
+		for id, n := range notices {
+			interruptions[id] = n
+		}
+	}
+
+	for i := range nodes {
+		node := &nodes[i]
+		cloudNode := instances[node.Spec.ProviderID]
+		if cloudNode == nil {
+			continue
+		}
+		nodeRef := &corev1.ObjectReference{
+			Kind:      "Node",
+			Name:      node.Name,
+			UID:       types.UID(node.Name),
+			Namespace: "",
+		}
+
+		shutdown := cloudNode.Status == instanceStatusStopped
+		interruption := spotInterruption(cloudNode)
+		if n, ok := interruptions[node.Spec.ProviderID]; ok {
+			interruption = &n
+		}
+
+		hasShutdownTaint := findTaint(node.Spec.Taints, api.TaintNodeShutdown) != nil
+		interrupted := findTaint(node.Spec.Taints, TaintSpotInterruption) != nil && node.Spec.Unschedulable
+		mappings := ctrlCfg.CloudCFG.Global.NodeLabelMappings
+		mapped := setMappedLabels(node.DeepCopy(), cloudNode, mappings)
+		if shutdown == hasShutdownTaint && (interruption == nil || interrupted) && !mapped {
+			continue
+		}
+
+		diff := func(copy runtime.Object) (client.Object, error) {
+			nins := copy.(*corev1.Node)
+			setMappedLabels(nins, cloudNode, mappings)
+			setShutdownTaint(nins, shutdown)
+			if interruption != nil {
+				setInterruptionTaint(nins, interruption)
+			}
+			return nins, nil
+		}
+		if err := helper.PatchM(m.client, node, diff, helper.PatchAll); err != nil {
+			log.Error(err, "patch node lifecycle taints error, wait for next retry", "node", node.Name)
+			m.record.Event(nodeRef, corev1.EventTypeWarning, helper.FailedSyncNode, err.Error())
+			continue
+		}
+
+		if shutdown && !hasShutdownTaint {
+			log.Info("instance is stopped, add shutdown taint", "node", node.Name, "prvdId", node.Spec.ProviderID)
+			metric.NodeLifecycleEvents.WithLabelValues(lifecycleEventShutdown).Inc()
+			m.record.Event(nodeRef, corev1.EventTypeWarning, helper.ShutdownNode,
+				fmt.Sprintf("Instance %s is stopped, node is tainted with %s", cloudNode.InstanceID, api.TaintNodeShutdown))
+		}
+		if !shutdown && hasShutdownTaint {
+			log.Info("instance is running again, remove shutdown taint", "node", node.Name, "prvdId", node.Spec.ProviderID)
+		}
+		if interruption != nil && !interrupted {
+			log.Info("instance is about to be reclaimed, taint and cordon node", "node", node.Name,
+				"prvdId", node.Spec.ProviderID, "reason", interruption.Reason)
+			metric.NodeLifecycleEvents.WithLabelValues(lifecycleEventSpotInterruption).Inc()
+			msg := fmt.Sprintf("Instance %s is about to be reclaimed: %s, node is cordoned", cloudNode.InstanceID, interruption.Reason)
+			if !interruption.Time.IsZero() {
+				msg = fmt.Sprintf("%s, reclaimed at %s", msg, interruption.Time.Format(time.RFC3339))
+			}
+			m.record.Event(nodeRef, corev1.EventTypeWarning, helper.SpotInterruption, msg)
+		}
+	}
+}
+
+// recordSpotInstances updates the spot instances of the nodes, the nodes without an instance are skipped.
+func (m *ReconcileNode) recordSpotInstances(nodes []corev1.Node, instances map[string]*prvd.NodeAttribute) {
+	m.spotLock.Lock()
+	defer m.spotLock.Unlock()
+	if m.spotInstances == nil {
+		m.spotInstances = sets.NewString()
+	}
+	for _, node := range nodes {
+		ins := instances[node.Spec.ProviderID]
+		if ins == nil {
+			continue
+		}
+		if ins.SpotStrategy == "" || ins.SpotStrategy == spotStrategyNoSpot {
+			m.spotInstances.Delete(node.Spec.ProviderID)
+		} else {
+			m.spotInstances.Insert(node.Spec.ProviderID)
+		}
+	}
+}
+
+// spotNodes returns the nodes of the spot instances recorded by syncNodeLifecycle.
+func (m *ReconcileNode) spotNodes(nodes []corev1.Node) []corev1.Node {
+	m.spotLock.Lock()
+	defer m.spotLock.Unlock()
+	var spot []corev1.Node
+	for _, node := range nodes {
+		if m.spotInstances.Has(node.Spec.ProviderID) {
+			spot = append(spot, node)
+		}
+	}
+	return spot
+}
+
+func setShutdownTaint(node *corev1.Node, shutdown bool) {
+	taint := corev1.Taint{Key: api.TaintNodeShutdown, Effect: corev1.TaintEffectNoSchedule}
+	if !shutdown {
+		node.Spec.Taints = excludeTaintFromList(node.Spec.Taints, taint)
+		return
+	}
+	if findTaint(node.Spec.Taints, api.TaintNodeShutdown) == nil {
+		node.Spec.Taints = append(node.Spec.Taints, taint)
+	}
+}
+
+func setInterruptionTaint(node *corev1.Node, interruption *Interruption) {
+	if findTaint(node.Spec.Taints, TaintSpotInterruption) == nil {
+		node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{
+			Key:    TaintSpotInterruption,
+			Value:  interruption.Reason,
+			Effect: corev1.TaintEffectNoSchedule,
+		})
+	}
+	node.Spec.Unschedulable = true
+}
+
+func findTaint(taints []corev1.Taint, key string) *corev1.Taint {
+	for i := range taints {
+		if taints[i].Key == key {
+			return &taints[i]
+		}
+	}
+	return nil
+}
+
+// PeriodicalSyncLifecycle checks the spot instances every spot monitor period, as they are reclaimed a few
+// minutes after the notice. The other instances are checked by syncNode, which lists them anyway.
+func (m *ReconcileNode) PeriodicalSyncLifecycle() {
+	syncSpot := func(nodes []corev1.Node, _ bool) error {
+		instances, err := m.cloud.ListInstances(context.TODO(), nodeids(nodes))
+		if err != nil {
+			return fmt.Errorf("[NodeLifecycle] list spot instances from api: %s", err.Error())
+		}
+		m.syncNodeLifecycle(nodes, instances)
+		return nil
+	}
+	syncLifecycle := func() {
+		nodes, err := NodeList(m.client)
+		if err != nil {
+			log.Error(err, "lifecycle sync error")
+			return
+		}
+		if err := batchOperate(m.spotNodes(nodes.Items), syncSpot); err != nil {
+			log.Error(err, "periodically sync spot node lifecycle error")
+		}
+	}
+
+	go wait.Until(syncLifecycle, m.spotMonitorPeriod, wait.NeverStop)
+}
@@ -22,11 +22,16 @@ import (
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider/api"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -50,6 +55,10 @@ const (
 	LabelNodePoolID         = "node.alibabacloud.com/nodepool-id"
 	LabelInstanceChargeType = "node.alibabacloud.com/instance-charge-type"
 	LabelSpotStrategy       = "node.alibabacloud.com/spot-strategy"
+
+	// AnnotationMappedKeys records the keys set by the node label mappings, so that only these keys are
+	// removed when their values disappear.
+	AnnotationMappedKeys = "node.alibabacloud.com/mapped-keys"
 )
 
 var ErrNotFound = errors.New("instance not found")
@@ -291,6 +300,13 @@ func setFields(node *v1.Node, ins *prvd.NodeAttribute, cfgRoute bool, removeTain
 		modifiers = append(modifiers, modify)
 	}
 
+	if mappings := ctrlCfg.CloudCFG.Global.NodeLabelMappings; len(mappings) != 0 {
+		modify := func(n *v1.Node) {
+			setMappedLabels(n, ins, mappings)
+		}
+		modifiers = append(modifiers, modify)
+	}
+
 	if removeTaints {
 		modifiers = append(modifiers, removeCloudTaints)
 	} else {
@@ -306,6 +322,99 @@ func setFields(node *v1.Node, ins *prvd.NodeAttribute, cfgRoute bool, removeTain
 	}
 }
 
+// setMappedLabels sets the labels and the annotations of the node label mappings, and removes the ones
+// whose value disappears if they are recorded in AnnotationMappedKeys. It returns whether the node is changed.
+func setMappedLabels(node *v1.Node, ins *prvd.NodeAttribute, mappings []ctrlCfg.NodeLabelMapping) bool {
+	changed := false
+	mapped := sets.NewString()
+	if v := node.Annotations[AnnotationMappedKeys]; v != "" {
+		mapped.Insert(strings.Split(v, ",")...)
+	}
+	recorded := mapped.Clone()
+	for _, m := range mappings {
+		values := &node.Annotations
+		if m.Label != "" {
+			values = &node.Labels
+		}
+		value := mappedValue(ins, m)
+		if m.Label != "" && value != "" {
+			if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+				klog.Warningf("node %s, value %q of label %s is invalid, skip it: %s",
+					node.Name, value, m.Label, strings.Join(errs, "; "))
+				value = ""
+			}
+		}
+		old, ok := (*values)[m.Key()]
+		if value == "" {
+			// the keys set by others, e.g. the user, are kept
+			if ok && mapped.Has(m.Key()) {
+				klog.V(5).Infof("node %s, removing mapped key %s", node.Name, m.Key())
+				delete(*values, m.Key())
+				changed = true
+			}
+			mapped.Delete(m.Key())
+			continue
+		}
+		mapped.Insert(m.Key())
+		if !ok || old != value {
+			klog.V(5).Infof("node %s, setting mapped key from cloud provider: %s=%s", node.Name, m.Key(), value)
+			if *values == nil {
+				*values = map[string]string{}
+			}
+			(*values)[m.Key()] = value
+			changed = true
+		}
+	}
+	if !mapped.Equal(recorded) {
+		if mapped.Len() == 0 {
+			delete(node.Annotations, AnnotationMappedKeys)
+		} else {
+			if node.Annotations == nil {
+				node.Annotations = map[string]string{}
+			}
+			node.Annotations[AnnotationMappedKeys] = strings.Join(mapped.List(), ",")
+		}
+		changed = true
+	}
+	return changed
+}
+
+// mappedValue returns the value of the ecs tag or the instance attribute of the mapping, or "" if it is absent.
+// The security group ids are joined with "_" for a label, and with "," for an annotation.
+func mappedValue(ins *prvd.NodeAttribute, m ctrlCfg.NodeLabelMapping) string {
+	if m.Tag != "" {
+		return ins.Tags[m.Tag]
+	}
+	switch m.Attribute {
+	case ctrlCfg.NodeAttributeInstanceTypeFamily:
+		return ins.InstanceTypeFamily
+	case ctrlCfg.NodeAttributeCPU:
+		return intValue(ins.CPU)
+	case ctrlCfg.NodeAttributeMemory:
+		return intValue(ins.Memory)
+	case ctrlCfg.NodeAttributeGPUAmount:
+		return strconv.Itoa(ins.GPUAmount)
+	case ctrlCfg.NodeAttributeGPUSpec:
+		return ins.GPUSpec
+	case ctrlCfg.NodeAttributeVSwitchID:
+		return ins.VSwitchID
+	case ctrlCfg.NodeAttributeSecurityGroupIDs:
+		sep := ","
+		if m.Label != "" {
+			sep = "_"
+		}
+		return strings.Join(ins.SecurityGroupIDs, sep)
+	}
+	return ""
+}
+
+func intValue(v int) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.Itoa(v)
+}
+
 func setNetworkUnavailable(n *v1.Node) {
 	var conditions []v1.NodeCondition
 
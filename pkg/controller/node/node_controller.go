@@ -8,6 +8,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
@@ -23,6 +24,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sync"
 	"time"
 )
 
@@ -72,6 +74,10 @@ func newReconciler(mgr manager.Manager, ctx *shared.SharedContext) *ReconcileNod
 		scheme:      mgr.GetScheme(),
 		record:      mgr.GetEventRecorderFor("node-controller"),
 		requestChan: make(chan *corev1.Node, ctrlCfg.ControllerCFG.NodeReconcileBatchSize),
+		// sources of the interruption notices besides the instance status
+		interruptionSources: registeredInterruptionSources(),
+		// the spot instances are reclaimed a few minutes after the notice
+		spotMonitorPeriod: 30 * time.Second,
 	}
 	return recon
 }
@@ -87,6 +93,7 @@ func (controller nodeController) Start(ctx context.Context) error {
 		go controller.recon.batchWorker(ctx, i)
 	}
 	controller.recon.PeriodicalSync()
+	controller.recon.PeriodicalSyncLifecycle()
 	return controller.c.Start(ctx)
 }
 
@@ -111,6 +118,14 @@ type ReconcileNode struct {
 	record record.EventRecorder
 
 	requestChan chan *corev1.Node
+
+	interruptionSources []InterruptionSource
+
+	// spotMonitorPeriod is how often the spot instances are checked for the reclamation
+	spotMonitorPeriod time.Duration
+	// spotInstances are the provider ids of the spot instances, which are checked by PeriodicalSyncLifecycle
+	spotLock      sync.Mutex
+	spotInstances sets.String
 }
 
 func (m *ReconcileNode) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
@@ -381,6 +396,8 @@ func (m *ReconcileNode) syncNode(nodes []corev1.Node, configCloudRoute bool) err
 			)
 		}
 	}
+	// the spot instances are checked more often by PeriodicalSyncLifecycle
+	m.syncNodeLifecycle(nodes, instances)
 	return nil
 }
 
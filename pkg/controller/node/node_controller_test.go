@@ -7,12 +7,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/vmock"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 	"k8s.io/cloud-provider/api"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"strings"
 	"testing"
 )
 
@@ -129,3 +131,168 @@ func getFakeKubeClient() client.Client {
 	objs := []runtime.Object{nodeList}
 	return fake.NewClientBuilder().WithRuntimeObjects(objs...).Build()
 }
+
+// lifecycleCloud serves the instances with the status and the lock reasons of the test
+type lifecycleCloud struct {
+	prvd.Provider
+	status      string
+	lockReasons []string
+}
+
+func (c lifecycleCloud) ListInstances(ctx context.Context, ids []string) (map[string]*prvd.NodeAttribute, error) {
+	mins, err := c.Provider.ListInstances(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, ins := range mins {
+		ins.Status = c.status
+		ins.LockReasons = c.lockReasons
+	}
+	return mins, nil
+}
+
+type fakeInterruptionSource map[string]Interruption
+
+func (s fakeInterruptionSource) Interruptions(ctx context.Context, ids []string) (map[string]Interruption, error) {
+	return s, nil
+}
+
+func syncLifecycle(t *testing.T, recon *ReconcileNode) *v1.Node {
+	nodes, err := NodeList(recon.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instances, err := recon.cloud.ListInstances(context.TODO(), nodeids(nodes.Items))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recon.syncNodeLifecycle(nodes.Items, instances)
+	node := &v1.Node{}
+	if err := recon.client.Get(context.TODO(), client.ObjectKey{Name: NodeName}, node); err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func TestSyncNodeLifecycle_Shutdown(t *testing.T) {
+	recon := getReconcileNode()
+	recon.cloud = lifecycleCloud{Provider: getMockCloudProvider(), status: "Stopped"}
+	node := syncLifecycle(t, recon)
+	if taint := findTaint(node.Spec.Taints, api.TaintNodeShutdown); taint == nil || taint.Effect != v1.TaintEffectNoSchedule {
+		t.Errorf("shutdown taint not added, taints: %+v", node.Spec.Taints)
+	}
+	if node.Spec.Unschedulable {
+		t.Error("stopped node should not be cordoned")
+	}
+
+	recon.cloud = lifecycleCloud{Provider: getMockCloudProvider(), status: "Running"}
+	node = syncLifecycle(t, recon)
+	if findTaint(node.Spec.Taints, api.TaintNodeShutdown) != nil {
+		t.Errorf("shutdown taint not removed, taints: %+v", node.Spec.Taints)
+	}
+	if findCloudTaint(node.Spec.Taints) == nil {
+		t.Errorf("other taints should be kept, taints: %+v", node.Spec.Taints)
+	}
+}
+
+func TestSyncNodeLifecycle_SpotInterruption(t *testing.T) {
+	recon := getReconcileNode()
+	recon.cloud = lifecycleCloud{Provider: getMockCloudProvider(), status: "Running", lockReasons: []string{"Recycling"}}
+	node := syncLifecycle(t, recon)
+	if findTaint(node.Spec.Taints, TaintSpotInterruption) == nil || !node.Spec.Unschedulable {
+		t.Errorf("spot interruption taint not added or node not cordoned, node: %+v", node.Spec)
+	}
+	nodes, err := NodeList(recon.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spot := recon.spotNodes(nodes.Items); len(spot) != 1 || spot[0].Name != NodeName {
+		t.Errorf("spot node not recorded, got %+v", spot)
+	}
+
+	// the notice of a source is handled as well
+	recon = getReconcileNode()
+	recon.interruptionSources = []InterruptionSource{
+		fakeInterruptionSource{"cn-hangzhou.ecs-id": {Reason: "PreemptionAndRecycle"}},
+	}
+	node = syncLifecycle(t, recon)
+	if taint := findTaint(node.Spec.Taints, TaintSpotInterruption); taint == nil || taint.Value != "PreemptionAndRecycle" {
+		t.Errorf("spot interruption taint not added, taints: %+v", node.Spec.Taints)
+	}
+	if !node.Spec.Unschedulable {
+		t.Error("interrupted node should be cordoned")
+	}
+}
+
+func TestSetMappedLabels(t *testing.T) {
+	mappings := []ctrlCfg.NodeLabelMapping{
+		{Tag: "team", Label: "example.com/team"},
+		{Attribute: ctrlCfg.NodeAttributeInstanceTypeFamily, Label: "example.com/family"},
+		{Attribute: ctrlCfg.NodeAttributeMemory, Label: "example.com/memory"},
+		{Attribute: ctrlCfg.NodeAttributeGPUAmount, Label: "example.com/gpu"},
+		{Attribute: ctrlCfg.NodeAttributeSecurityGroupIDs, Annotation: "example.com/security-groups"},
+		{Tag: "owner", Label: "example.com/owner"},
+	}
+	ins := &prvd.NodeAttribute{
+		Tags:               map[string]string{"team": "infra", "owner": "not a label value"},
+		InstanceTypeFamily: "ecs.g6",
+		Memory:             8192,
+		SecurityGroupIDs:   []string{"sg-1", "sg-2"},
+	}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: NodeName}}
+	if !setMappedLabels(node, ins, mappings) {
+		t.Error("node should be changed")
+	}
+	expect := map[string]string{
+		"example.com/team":   "infra",
+		"example.com/family": "ecs.g6",
+		"example.com/memory": "8192",
+		"example.com/gpu":    "0",
+	}
+	if fmt.Sprint(node.Labels) != fmt.Sprint(expect) {
+		t.Errorf("labels not equal, expect %v, got %v", expect, node.Labels)
+	}
+	if sgs := node.Annotations["example.com/security-groups"]; sgs != "sg-1,sg-2" {
+		t.Errorf("security groups annotation not equal, expect sg-1,sg-2, got %s", sgs)
+	}
+	if setMappedLabels(node, ins, mappings) {
+		t.Error("node should not be changed when the labels are in sync")
+	}
+
+	// the label is removed with its source tag, the other labels are kept
+	node.Labels["other"] = "value"
+	delete(ins.Tags, "team")
+	if !setMappedLabels(node, ins, mappings) {
+		t.Error("node should be changed")
+	}
+	if _, ok := node.Labels["example.com/team"]; ok {
+		t.Error("label of the removed tag should be removed")
+	}
+	if node.Labels["other"] != "value" {
+		t.Error("labels not mapped should be kept")
+	}
+
+	// the label not set by the mapping is kept without its source tag
+	node.Labels["example.com/team"] = "user"
+	setMappedLabels(node, ins, mappings)
+	if node.Labels["example.com/team"] != "user" {
+		t.Error("label set by the user should be kept")
+	}
+	if keys := node.Annotations[AnnotationMappedKeys]; strings.Contains(keys, "example.com/team") {
+		t.Errorf("removed key should not be recorded, got %s", keys)
+	}
+}
+
+func TestSyncNodeLifecycle_LabelMappings(t *testing.T) {
+	mappings := ctrlCfg.CloudCFG.Global.NodeLabelMappings
+	defer func() { ctrlCfg.CloudCFG.Global.NodeLabelMappings = mappings }()
+	ctrlCfg.CloudCFG.Global.NodeLabelMappings = []ctrlCfg.NodeLabelMapping{
+		{Tag: "ack.alibabacloud.com/nodepool-id", Label: "example.com/nodepool"},
+	}
+
+	recon := getReconcileNode()
+	node := syncLifecycle(t, recon)
+	if node.Labels["example.com/nodepool"] != vmock.NodePoolID {
+		t.Errorf("mapped label not equal, expect %s, got %s", vmock.NodePoolID, node.Labels["example.com/nodepool"])
+	}
+}
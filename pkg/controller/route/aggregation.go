@@ -0,0 +1,349 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+	"k8s.io/klog/v2"
+	"net"
+)
+
+// aggregation is a route aggregation of the cloud config, gateway is nil if the gateway node is not ready,
+// and the routes in the block are split into the routes of the nodes.
+type aggregation struct {
+	block *net.IPNet
+	// gateway carries the next hop of the aggregated routes
+	gateway *model.Route
+}
+
+// aggregationPlan is the aggregated routes of a route table.
+type aggregationPlan struct {
+	aggregations []aggregation
+	// routes are the aggregated routes, keyed by the destination cidr
+	routes map[string]*model.Route
+	// covered maps the pod cidr of a node to the destination cidr of the aggregated route covering it
+	covered map[string]string
+}
+
+func getAggregations(nodes []*v1.Node) []aggregation {
+	var aggs []aggregation
+	for _, a := range ctrlCfg.CloudCFG.Global.RouteAggregations {
+		_, block, err := net.ParseCIDR(a.CIDR)
+		if err != nil {
+			klog.Errorf("invalid cidr %s of route aggregation: %s", a.CIDR, err.Error())
+			continue
+		}
+		agg := aggregation{block: block}
+		if a.GatewayENI != "" {
+			agg.gateway = &model.Route{NextHopType: model.RouteNextHopTypeNetworkInterface, NextHopId: a.GatewayENI}
+		}
+		for _, n := range nodes {
+			if a.GatewayNode == "" || n.Name != a.GatewayNode {
+				continue
+			}
+			if n.Spec.ProviderID != "" && needSyncRoute(n) {
+				agg.gateway = &model.Route{ProviderId: n.Spec.ProviderID}
+			}
+		}
+		if agg.gateway == nil {
+			klog.Warningf("gateway node %s of route aggregation %s is not ready, split the aggregated routes", a.GatewayNode, a.CIDR)
+		}
+		aggs = append(aggs, agg)
+	}
+	return aggs
+}
+
+// planAggregation summarizes the pod cidrs of the nodes in each block into the largest prefixes which are fully
+// allocated to the nodes. A prefix is routed through the gateway if it covers more than one node.
+func planAggregation(aggs []aggregation, nodes []*v1.Node) *aggregationPlan {
+	plan := &aggregationPlan{aggregations: aggs, routes: map[string]*model.Route{}, covered: map[string]string{}}
+	for _, agg := range aggs {
+		if agg.gateway == nil {
+			continue
+		}
+		var cidrs []*net.IPNet
+		for _, n := range nodes {
+			cidr, _, err := getRouteForNode(n, cidrFamily(agg.block))
+			if err != nil || cidr == nil {
+				continue
+			}
+			if contains, _, _ := containsRoute(agg.block, cidr.String()); contains {
+				cidrs = append(cidrs, cidr)
+			}
+		}
+		for _, prefix := range summarize(agg.block, cidrs) {
+			var covered []string
+			for _, cidr := range cidrs {
+				if contains, _, _ := containsRoute(prefix, cidr.String()); contains {
+					covered = append(covered, cidr.String())
+				}
+			}
+			// a single node keeps its own route
+			if len(covered) < 2 {
+				continue
+			}
+			route := *agg.gateway
+			route.DestinationCIDR = prefix.String()
+			route.Name = fmt.Sprintf("%s%s-%s", route.ProviderId, route.NextHopId, route.DestinationCIDR)
+			route.Description = model.RouteDescriptionAggregation
+			plan.routes[route.DestinationCIDR] = &route
+			for _, cidr := range covered {
+				plan.covered[cidr] = route.DestinationCIDR
+			}
+		}
+	}
+	return plan
+}
+
+// inBlocks checks whether the route is in the block of a route aggregation.
+func (p *aggregationPlan) inBlocks(cidr string) bool {
+	for _, agg := range p.aggregations {
+		if contains, _, err := containsRoute(agg.block, cidr); err == nil && contains {
+			return true
+		}
+	}
+	return false
+}
+
+// owns checks whether the route is an aggregated route of the controller, i.e. it is created with the
+// description of the aggregated routes, or it goes through the gateway of the aggregation of its block.
+// The other routes in the blocks, e.g. the static routes to a vpn gateway, are left alone.
+func (p *aggregationPlan) owns(route *model.Route) bool {
+	if route.Description == model.RouteDescriptionAggregation {
+		return true
+	}
+	for _, agg := range p.aggregations {
+		if agg.gateway == nil {
+			continue
+		}
+		if contains, _, err := containsRoute(agg.block, route.DestinationCIDR); err == nil && contains && sameNextHop(route, agg.gateway) {
+			return true
+		}
+	}
+	return false
+}
+
+// uncover drops the aggregated route, the nodes it covers fall back to their own routes.
+func (p *aggregationPlan) uncover(cidr string) {
+	delete(p.routes, cidr)
+	for nodeCidr, aggregated := range p.covered {
+		if aggregated == cidr {
+			delete(p.covered, nodeCidr)
+		}
+	}
+}
+
+// summarize returns the largest prefixes in the block which are fully covered by the cidrs.
+func summarize(block *net.IPNet, cidrs []*net.IPNet) []*net.IPNet {
+	_, prefixes := cover(block, cidrs)
+	return prefixes
+}
+
+func cover(prefix *net.IPNet, cidrs []*net.IPNet) (bool, []*net.IPNet) {
+	var inside []*net.IPNet
+	for _, cidr := range cidrs {
+		if cidr.String() == prefix.String() {
+			return true, []*net.IPNet{prefix}
+		}
+		if _, contains, _ := containsRoute(prefix, cidr.String()); contains {
+			inside = append(inside, cidr)
+		}
+	}
+	ones, bits := prefix.Mask.Size()
+	if len(inside) == 0 || ones >= bits {
+		return false, nil
+	}
+	lower, upper := splitPrefix(prefix)
+	lowerFull, lowerPrefixes := cover(lower, inside)
+	upperFull, upperPrefixes := cover(upper, inside)
+	if lowerFull && upperFull {
+		return true, []*net.IPNet{prefix}
+	}
+	return false, append(lowerPrefixes, upperPrefixes...)
+}
+
+func splitPrefix(prefix *net.IPNet) (*net.IPNet, *net.IPNet) {
+	ones, bits := prefix.Mask.Size()
+	mask := net.CIDRMask(ones+1, bits)
+	lower := &net.IPNet{IP: prefix.IP.Mask(mask), Mask: mask}
+	upperIP := make(net.IP, len(lower.IP))
+	copy(upperIP, lower.IP)
+	upperIP[ones/8] |= 0x80 >> (ones % 8)
+	return lower, &net.IPNet{IP: upperIP, Mask: mask}
+}
+
+// coveredByAggregatedRoute checks whether the pod cidr is routed by an aggregated route in the routes.
+func coveredByAggregatedRoute(cidr string, routes []*model.Route) bool {
+	plan := &aggregationPlan{}
+	for _, a := range ctrlCfg.CloudCFG.Global.RouteAggregations {
+		if _, block, err := net.ParseCIDR(a.CIDR); err == nil {
+			agg := aggregation{block: block}
+			if a.GatewayENI != "" {
+				agg.gateway = &model.Route{NextHopType: model.RouteNextHopTypeNetworkInterface, NextHopId: a.GatewayENI}
+			}
+			plan.aggregations = append(plan.aggregations, agg)
+		}
+	}
+	if !plan.inBlocks(cidr) {
+		return false
+	}
+	for _, route := range routes {
+		_, dst, err := net.ParseCIDR(route.DestinationCIDR)
+		if err != nil || !plan.inBlocks(route.DestinationCIDR) || !plan.owns(route) {
+			continue
+		}
+		if _, contains, _ := containsRoute(dst, cidr); contains {
+			return true
+		}
+	}
+	return false
+}
+
+// createAggregatedRoutes creates the aggregated routes not in the table, the failed ones are dropped from
+// the plan so that the nodes they cover keep their own routes.
+func (r *ReconcileRoute) createAggregatedRoutes(ctx context.Context, table string, plan *aggregationPlan, routes []*model.Route) {
+	var toAdd []*model.Route
+	for cidr, aggregated := range plan.routes {
+		found := false
+		for _, route := range routes {
+			if route.DestinationCIDR == cidr && sameNextHop(route, aggregated) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			toAdd = append(toAdd, aggregated)
+		}
+	}
+	if len(toAdd) == 0 {
+		return
+	}
+
+	_, statuses, err := r.LockedCreateRoutes(ctx, "", table, toAdd)
+	if err != nil {
+		klog.Errorf("create aggregated routes in table %s error: %s", table, err.Error())
+		for _, route := range toAdd {
+			plan.uncover(route.DestinationCIDR)
+		}
+		return
+	}
+	for _, s := range statuses {
+		if s.Failed && s.FailedCode != "VPC_ROUTE_ENTRY_CIDR_BLOCK_DUPLICATE" {
+			klog.Errorf("create aggregated route %s in table %s error: %s, %s",
+				s.Route.DestinationCIDR, table, s.FailedCode, s.FailedMessage)
+			plan.uncover(s.Route.DestinationCIDR)
+			continue
+		}
+		klog.Infof("Created aggregated route %s in table %s", s.Route.Name, table)
+	}
+}
+
+// cleanupAggregatedRoutes removes the routes of the nodes covered by the aggregated routes, and the aggregated
+// routes of the controller which are not in the plan any more. An aggregated route is removed only if all the pod cidrs of the nodes
+// assigned to the table it covers are routed, i.e. the routes which replace it are created. The nodes which
+// are not synced, e.g. in unknown status, keep the aggregated route until they have their own routes.
+func (r *ReconcileRoute) cleanupAggregatedRoutes(
+	ctx context.Context, table string, plan *aggregationPlan, routes []*model.Route, nodes *v1.NodeList,
+	assignment tableAssignment, routed map[string]bool,
+) {
+	nodeCidrs := map[string]string{}
+	var tableCidrs []string
+	for i := range nodes.Items {
+		n := &nodes.Items[i]
+		for _, family := range []model.AddressIPVersionType{model.IPv4, model.IPv6} {
+			if _, cidr, err := getRouteForNode(n, family); err == nil && cidr != "" {
+				nodeCidrs[cidr] = n.Spec.ProviderID
+				if assignment.assigned(n.Name, table) {
+					tableCidrs = append(tableCidrs, cidr)
+				}
+			}
+		}
+	}
+
+	var toDelete []*model.Route
+	for _, route := range routes {
+		if !plan.inBlocks(route.DestinationCIDR) {
+			continue
+		}
+		if pvid, ok := nodeCidrs[route.DestinationCIDR]; ok {
+			// the route of a node covered by an aggregated route
+			if _, covered := plan.covered[route.DestinationCIDR]; covered && sameInstance(pvid, route.ProviderId) {
+				toDelete = append(toDelete, route)
+			}
+			continue
+		}
+		if aggregated, ok := plan.routes[route.DestinationCIDR]; ok && sameNextHop(route, aggregated) {
+			continue
+		}
+		if !plan.owns(route) {
+			continue
+		}
+		_, dst, err := net.ParseCIDR(route.DestinationCIDR)
+		if err != nil {
+			continue
+		}
+		ready := true
+		for _, cidr := range tableCidrs {
+			if contains, _, _ := containsRoute(dst, cidr); contains && !isRouted(cidr, plan, routes, routed) {
+				ready = false
+				break
+			}
+		}
+		if !ready {
+			klog.Infof("wait for the routes of the nodes before deleting aggregated route %s in table %s",
+				route.DestinationCIDR, table)
+			continue
+		}
+		toDelete = append(toDelete, route)
+	}
+	if len(toDelete) == 0 {
+		return
+	}
+
+	statuses, err := r.LockedDeleteRoutes(ctx, "", table, toDelete)
+	if err != nil {
+		klog.Errorf("delete routes replaced by aggregation in table %s error: %s", table, err.Error())
+		return
+	}
+	for _, s := range statuses {
+		if s.Failed && s.FailedCode != "VPC_ROUTER_ENTRY_NOT_EXIST" {
+			klog.Errorf("delete route %s in table %s error: %s, %s", s.Route.DestinationCIDR, table, s.FailedCode, s.FailedMessage)
+			continue
+		}
+		klog.Infof("Deleted route %s replaced by aggregation in table %s", s.Route.Name, table)
+	}
+}
+
+// isRouted checks whether the pod cidr is routed without the aggregated routes to be removed. The pod cidrs
+// of the nodes which are not synced are routed only if their own routes are in the table.
+func isRouted(cidr string, plan *aggregationPlan, routes []*model.Route, routed map[string]bool) bool {
+	if ok, synced := routed[cidr]; synced {
+		return ok
+	}
+	if _, covered := plan.covered[cidr]; covered {
+		return true
+	}
+	for _, route := range routes {
+		if route.DestinationCIDR == cidr {
+			return true
+		}
+	}
+	return false
+}
+
+func sameNextHop(a, b *model.Route) bool {
+	if a.NextHopType == model.RouteNextHopTypeNetworkInterface || b.NextHopType == model.RouteNextHopTypeNetworkInterface {
+		return a.NextHopType == b.NextHopType && a.NextHopId == b.NextHopId
+	}
+	return sameInstance(a.ProviderId, b.ProviderId)
+}
+
+// sameInstance compares the instances of the provider ids, the routes listed from the table have no prefix.
+func sameInstance(a, b string) bool {
+	_, insA, errA := util.NodeFromProviderID(a)
+	_, insB, errB := util.NodeFromProviderID(b)
+	return errA == nil && errB == nil && insA == insB
+}
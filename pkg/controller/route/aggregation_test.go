@@ -0,0 +1,192 @@
+package route
+
+import (
+	"context"
+	cmap "github.com/orcaman/concurrent-map"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
+	"net"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sort"
+	"testing"
+)
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	var ret []*net.IPNet
+	for _, c := range cidrs {
+		_, cidr, _ := net.ParseCIDR(c)
+		ret = append(ret, cidr)
+	}
+	return ret
+}
+
+func cidrStrings(cidrs []*net.IPNet) []string {
+	var ret []string
+	for _, c := range cidrs {
+		ret = append(ret, c.String())
+	}
+	return ret
+}
+
+func TestSummarize(t *testing.T) {
+	block := parseCIDRs("172.20.0.0/22")[0]
+	assert.Equal(t, []string{"172.20.0.0/22"},
+		cidrStrings(summarize(block, parseCIDRs("172.20.0.0/24", "172.20.1.0/24", "172.20.2.0/24", "172.20.3.0/24"))))
+	assert.Equal(t, []string{"172.20.0.0/23", "172.20.3.0/24"},
+		cidrStrings(summarize(block, parseCIDRs("172.20.0.0/24", "172.20.1.0/24", "172.20.3.0/24"))))
+	assert.Equal(t, []string{"172.20.0.0/24", "172.20.2.0/24"},
+		cidrStrings(summarize(block, parseCIDRs("172.20.0.0/24", "172.20.2.0/24"))))
+	assert.Empty(t, summarize(block, nil))
+
+	block = parseCIDRs("fd00::/110")[0]
+	assert.Equal(t, []string{"fd00::/111"},
+		cidrStrings(summarize(block, parseCIDRs("fd00::/112", "fd00::1:0/112"))))
+}
+
+func getAggregationNode(name, pvid, cidr string) v1.Node {
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.NodeSpec{ProviderID: pvid, PodCIDR: cidr},
+		Status: v1.NodeStatus{Conditions: []v1.NodeCondition{
+			{Type: v1.NodeReady, Status: v1.ConditionTrue},
+		}},
+	}
+}
+
+func TestPlanAggregation(t *testing.T) {
+	ctrlCfg.CloudCFG.Global.RouteAggregations = []ctrlCfg.RouteAggregation{
+		{CIDR: "172.20.0.0/22", GatewayNode: "node-1"},
+		{CIDR: "172.20.4.0/22", GatewayENI: "eni-1"},
+	}
+	defer func() { ctrlCfg.CloudCFG.Global.RouteAggregations = nil }()
+
+	var nodes []*v1.Node
+	for _, n := range []v1.Node{
+		getAggregationNode("node-1", "cn-hangzhou.i-1", "172.20.0.0/24"),
+		getAggregationNode("node-2", "cn-hangzhou.i-2", "172.20.1.0/24"),
+		getAggregationNode("node-3", "cn-hangzhou.i-3", "172.20.3.0/24"),
+		getAggregationNode("node-4", "cn-hangzhou.i-4", "172.20.4.0/24"),
+		getAggregationNode("node-5", "cn-hangzhou.i-5", "172.20.5.0/24"),
+		getAggregationNode("node-6", "cn-hangzhou.i-6", "172.20.8.0/24"),
+	} {
+		n := n
+		nodes = append(nodes, &n)
+	}
+
+	plan := planAggregation(getAggregations(nodes), nodes)
+	assert.Equal(t, map[string]*model.Route{
+		"172.20.0.0/23": {Name: "cn-hangzhou.i-1-172.20.0.0/23", DestinationCIDR: "172.20.0.0/23", ProviderId: "cn-hangzhou.i-1",
+			Description: model.RouteDescriptionAggregation},
+		"172.20.4.0/23": {Name: "eni-1-172.20.4.0/23", DestinationCIDR: "172.20.4.0/23",
+			NextHopType: model.RouteNextHopTypeNetworkInterface, NextHopId: "eni-1", Description: model.RouteDescriptionAggregation},
+	}, plan.routes)
+	assert.Equal(t, map[string]string{
+		"172.20.0.0/24": "172.20.0.0/23", "172.20.1.0/24": "172.20.0.0/23",
+		"172.20.4.0/24": "172.20.4.0/23", "172.20.5.0/24": "172.20.4.0/23",
+	}, plan.covered)
+	assert.True(t, plan.inBlocks("172.20.3.0/24"))
+	assert.False(t, plan.inBlocks("172.20.8.0/24"))
+
+	// only the routes through the gateways or with the description are aggregated routes
+	assert.True(t, plan.owns(&model.Route{DestinationCIDR: "172.20.2.0/23", ProviderId: "cn-hangzhou.i-1"}))
+	assert.True(t, plan.owns(&model.Route{DestinationCIDR: "172.20.6.0/23",
+		NextHopType: model.RouteNextHopTypeNetworkInterface, NextHopId: "eni-1"}))
+	assert.True(t, plan.owns(&model.Route{DestinationCIDR: "172.20.2.0/23", ProviderId: "cn-hangzhou.i-9", Description: model.RouteDescriptionAggregation}))
+	assert.False(t, plan.owns(&model.Route{DestinationCIDR: "172.20.2.0/23", ProviderId: "cn-hangzhou.i-9"}))
+	assert.False(t, plan.owns(&model.Route{DestinationCIDR: "172.20.6.0/23",
+		NextHopType: model.RouteNextHopTypeNetworkInterface, NextHopId: "eni-9"}))
+
+	plan.uncover("172.20.4.0/23")
+	assert.Len(t, plan.routes, 1)
+	assert.Len(t, plan.covered, 2)
+
+	// the aggregated routes are split if the gateway node is gone
+	plan = planAggregation(getAggregations(nodes[1:]), nodes[1:])
+	assert.Len(t, plan.routes, 1)
+	assert.Contains(t, plan.routes, "172.20.4.0/23")
+
+	routes := []*model.Route{
+		{DestinationCIDR: "172.20.0.0/23", ProviderId: "cn-hangzhou.i-1", Description: model.RouteDescriptionAggregation},
+		// a static route of the user
+		{DestinationCIDR: "172.20.2.0/23", ProviderId: "cn-hangzhou.i-9"},
+	}
+	assert.True(t, coveredByAggregatedRoute("172.20.1.0/24", routes))
+	assert.False(t, coveredByAggregatedRoute("172.20.3.0/24", routes))
+}
+
+func TestSyncTableRoutesAggregation(t *testing.T) {
+	ctrlCfg.CloudCFG.Global.RouteAggregations = []ctrlCfg.RouteAggregation{{CIDR: "172.20.0.0/22", GatewayNode: "node-1"}}
+	defer func() { ctrlCfg.CloudCFG.Global.RouteAggregations = nil }()
+
+	nodes := &v1.NodeList{Items: []v1.Node{
+		getAggregationNode("node-1", "cn-hangzhou.i-1", "172.20.0.0/24"),
+		getAggregationNode("node-2", "cn-hangzhou.i-2", "172.20.1.0/24"),
+		getAggregationNode("node-3", "cn-hangzhou.i-3", "172.20.2.0/24"),
+		getAggregationNode("node-4", "cn-hangzhou.i-4", "172.20.3.0/24"),
+	}}
+	var instances []prvd.NodeAttribute
+	for _, id := range []string{"i-1", "i-2", "i-3", "i-4"} {
+		instances = append(instances, prvd.NodeAttribute{InstanceID: id})
+	}
+	cloud := snapshot.NewSnapshotCloud(&snapshot.Snapshot{
+		Region:    "cn-hangzhou",
+		VpcID:     "vpc-1",
+		Instances: instances,
+		RouteTables: map[string][]model.Route{"vtb-1": {
+			{DestinationCIDR: "172.20.0.0/24", ProviderId: "cn-hangzhou.i-1"},
+			{DestinationCIDR: "172.20.1.0/24", ProviderId: "cn-hangzhou.i-2"},
+		}},
+	})
+	r := &ReconcileRoute{
+		cloud:     cloud,
+		client:    fake.NewClientBuilder().WithRuntimeObjects([]runtime.Object{nodes}...).Build(),
+		record:    record.NewFakeRecorder(100),
+		nodeCache: cmap.New(),
+	}
+	listRoutes := func() []string {
+		routes, err := cloud.ListRoute(context.TODO(), "vtb-1")
+		assert.NoError(t, err)
+		var ret []string
+		for _, route := range routes {
+			ret = append(ret, route.DestinationCIDR)
+		}
+		sort.Strings(ret)
+		return ret
+	}
+	assignment := tableAssignment{}
+	sync := func() {
+		for _, n := range nodes.Items {
+			assignment[n.Name] = []string{"vtb-1"}
+		}
+		assert.NoError(t, r.syncTableRoutes(context.TODO(), "vtb-1", nodes, assignment, map[string]routeResults{}))
+	}
+
+	// the routes of the nodes are replaced by the aggregated route
+	sync()
+	assert.Equal(t, []string{"172.20.0.0/22"}, listRoutes())
+
+	// the aggregated route is kept while it covers a node in unknown status without its own route
+	nodes.Items[3].Status.Conditions[0].Status = v1.ConditionUnknown
+	sync()
+	assert.Equal(t, []string{"172.20.0.0/22", "172.20.0.0/23", "172.20.2.0/24"}, listRoutes())
+	nodes.Items[3].Status.Conditions[0].Status = v1.ConditionTrue
+	sync()
+	assert.Equal(t, []string{"172.20.0.0/22"}, listRoutes())
+
+	// the aggregated route is split when a node leaves
+	nodes.Items = nodes.Items[:3]
+	sync()
+	assert.Equal(t, []string{"172.20.0.0/23", "172.20.2.0/24"}, listRoutes())
+
+	// the routes of the nodes are created before the aggregated routes are removed with the gateway
+	nodes.Items = nodes.Items[1:]
+	sync()
+	assert.Equal(t, []string{"172.20.1.0/24", "172.20.2.0/24"}, listRoutes())
+}
@@ -2,4 +2,6 @@ package route
 
 const (
 	updateNodeStatusMaxRetries = 3
+	// routeQuotaWarningRatio is the usage of the route entry quota of a route table to warn at
+	routeQuotaWarningRatio = 0.8
 )
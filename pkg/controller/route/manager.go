@@ -81,7 +81,8 @@ func getRouteTables(ctx context.Context, providerIns prvd.Provider) ([]string, e
 	if err != nil {
 		return nil, fmt.Errorf("can not found route table by id[%s], error: %v", ctrlCfg.CloudCFG.Global.VpcID, err)
 	}
-	if len(tables) > 1 {
+	// the nodes are assigned to one of the route tables by their vswitches in vswitch mode
+	if len(tables) > 1 && ctrlCfg.CloudCFG.Global.RouteTableMode != ctrlCfg.RouteTableModeVSwitch {
 		return nil, fmt.Errorf("multiple route tables found by vpc id[%s], length(tables)=%d", ctrlCfg.CloudCFG.Global.VpcID, len(tables))
 	}
 	if len(tables) == 0 {
@@ -90,21 +91,46 @@ func getRouteTables(ctx context.Context, providerIns prvd.Provider) ([]string, e
 	return tables, nil
 }
 
-func (r *ReconcileRoute) syncTableRoutes(ctx context.Context, table string, nodes *v1.NodeList) error {
+// syncTableRoutes syncs the routes of the nodes assigned to the table, the results of the nodes are merged
+// into results, so that the network conditions are updated once with the results of all the tables.
+func (r *ReconcileRoute) syncTableRoutes(
+	ctx context.Context, table string, nodes *v1.NodeList, assignment tableAssignment, results map[string]routeResults,
+) error {
 	routes, err := r.cloud.ListRoute(ctx, table)
 	if err != nil {
 		return fmt.Errorf("error listing routes: %v", err)
 	}
+	r.checkRouteQuota(table, len(routes))
 
-	var clusterCIDR *net.IPNet
-	if ctrlCfg.ControllerCFG.ClusterCIDR != "" {
-		_, clusterCIDR, err = net.ParseCIDR(ctrlCfg.ControllerCFG.ClusterCIDR)
+	var nodeRefs []*v1.Node
+	for i := range nodes.Items {
+		nodeRefs = append(nodeRefs, &nodes.Items[i])
+	}
+
+	clusterCIDRs, err := getClusterCIDRs(ctrlCfg.ControllerCFG.ClusterCIDR)
+	if err != nil {
+		return err
+	}
+
+	families := routeFamilies()
+	var vpcIPv6CIDRs []*net.IPNet
+	if len(families) > 1 {
+		vpcIPv6CIDRs, err = getVpcIPv6CIDRs(ctx, r.cloud)
 		if err != nil {
-			return fmt.Errorf("error parse cluster cidr %s: %s", ctrlCfg.ControllerCFG.ClusterCIDR, err)
+			klog.Errorf("error get ipv6 cidr of vpc, the ipv6 pod cidrs are not checked against it: %s", err.Error())
 		}
 	}
 
 	for _, route := range routes {
+		// the routes through an eni are aggregated routes, they are never conflicted
+		if route.NextHopType == model.RouteNextHopTypeNetworkInterface {
+			continue
+		}
+		family := routeFamily(route.DestinationCIDR)
+		if family == model.IPv6 && len(families) == 1 {
+			continue
+		}
+		clusterCIDR := clusterCIDRs[family]
 		contains, _, err := containsRoute(clusterCIDR, route.DestinationCIDR)
 		if err != nil {
 			klog.Errorf("error contains route %v <- %v, error %v ", clusterCIDR, route.DestinationCIDR, err)
@@ -113,59 +139,94 @@ func (r *ReconcileRoute) syncTableRoutes(ctx context.Context, table string, node
 		if !contains {
 			continue
 		}
-		if conflictWithNodes(route, nodes) {
+		if conflictWithNodes(route, nodes, vpcIPv6CIDRs) {
 			if err = deleteRouteForInstance(ctx, table, route.ProviderId, route.DestinationCIDR, r.cloud); err != nil {
 				klog.Errorf("Could not delete conflict route %s %s from table %s, %s", route.Name, route.DestinationCIDR, table, err.Error())
 				continue
 			}
 			klog.Infof("Delete conflict route %s, %s from table %s SUCCESS.", route.Name, route.DestinationCIDR, table)
+			continue
+		}
+		if misplacedRoute(route, table, nodeRefs, assignment) {
+			if err = deleteRouteForInstance(ctx, table, route.ProviderId, route.DestinationCIDR, r.cloud); err != nil {
+				klog.Errorf("Could not delete route %s %s of node assigned to other tables from table %s, %s",
+					route.Name, route.DestinationCIDR, table, err.Error())
+				continue
+			}
+			klog.Infof("Delete route %s, %s of node assigned to other tables from table %s SUCCESS.", route.Name, route.DestinationCIDR, table)
 		}
 	}
 
-	for _, node := range nodes.Items {
-		if !needSyncRoute(&node) {
+	var syncNodes []*v1.Node
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if !needSyncRoute(node) {
 			continue
 		}
-
-		prvdId := node.Spec.ProviderID
-		if prvdId == "" {
+		if node.Spec.ProviderID == "" || !assignment.assigned(node.Name, table) {
 			continue
 		}
+		syncNodes = append(syncNodes, node)
+	}
 
-		_, ipv4RouteCidr, err := getIPv4RouteForNode(&node)
-		if err != nil || ipv4RouteCidr == "" {
-			continue
-		}
+	// the aggregated routes are created before the routes they replace are deleted, and the routes of
+	// the nodes are created before the aggregated routes covering them are deleted
+	plan := planAggregation(getAggregations(nodeRefs), syncNodes)
+	r.createAggregatedRoutes(ctx, table, plan, routes)
 
-		err = r.addRouteForNode(ctx, table, ipv4RouteCidr, prvdId, &node, routes)
+	routed := map[string]bool{}
+	for _, node := range syncNodes {
+		routeCidrs, err := getRoutesForNode(node)
 		if err != nil {
 			continue
 		}
 
-		if err := r.updateNetworkingCondition(ctx, &node, true); err != nil {
-			klog.Errorf("update node %s network condition err: %s", node.Name, err.Error())
+		tableResults := routeResults{}
+		for family, cidr := range routeCidrs {
+			if _, covered := plan.covered[cidr]; covered {
+				tableResults[family] = true
+			} else {
+				tableResults[family] = r.addRouteForNode(ctx, table, cidr, node.Spec.ProviderID, node, routes) == nil
+			}
+			routed[cidr] = tableResults[family]
 		}
+		if results[node.Name] == nil {
+			results[node.Name] = routeResults{}
+		}
+		results[node.Name].merge(tableResults)
+	}
+
+	if len(plan.aggregations) != 0 {
+		r.cleanupAggregatedRoutes(ctx, table, plan, routes, nodes, assignment, routed)
 	}
 	return nil
 }
 
-func conflictWithNodes(route *model.Route, nodes *v1.NodeList) bool {
+// conflictWithNodes checks whether the route overlaps the pod cidr of the same ip family of any node, but
+// belongs to another instance. The ipv6 pod cidrs are allocated from the ipv6 cidr blocks of the vpc, so a
+// pod cidr covering a whole block is invalid and skipped, otherwise all the routes in the block conflict with it.
+func conflictWithNodes(route *model.Route, nodes *v1.NodeList, vpcIPv6CIDRs []*net.IPNet) bool {
+	family := routeFamily(route.DestinationCIDR)
 	for _, node := range nodes.Items {
-		ipv4Cidr, _, err := getIPv4RouteForNode(&node)
+		podCidr, _, err := getRouteForNode(&node, family)
 		if err != nil {
-			klog.Errorf("error get ipv4 cidr from node: %v", node.Name)
+			klog.Errorf("error get %s cidr from node: %v", family, node.Name)
+			continue
+		}
+		if podCidr == nil {
 			continue
 		}
-		if ipv4Cidr == nil {
+		if family == model.IPv6 && coversCIDRs(podCidr, vpcIPv6CIDRs) {
+			klog.Warningf("ipv6 pod cidr %v of node %v covers the ipv6 cidr of the vpc, skip it", podCidr, node.Name)
 			continue
 		}
-		equal, contains, err := containsRoute(ipv4Cidr, route.DestinationCIDR)
+		equal, contains, err := containsRoute(podCidr, route.DestinationCIDR)
 		if err != nil {
 			klog.Errorf("error get conflict state from node: %v and route: %v", node.Name, route)
 			continue
 		}
 		if contains || (equal && route.ProviderId != node.Spec.ProviderID) {
-			klog.Warningf("conflict route with node %v(%v) found, route: %+v", node.Name, ipv4Cidr, route)
+			klog.Warningf("conflict route with node %v(%v) found, route: %+v", node.Name, podCidr, route)
 			return true
 		}
 
@@ -173,6 +234,34 @@ func conflictWithNodes(route *model.Route, nodes *v1.NodeList) bool {
 	return false
 }
 
+// coversCIDRs checks whether the cidr equals or contains any of the cidrs.
+func coversCIDRs(cidr *net.IPNet, cidrs []*net.IPNet) bool {
+	for _, c := range cidrs {
+		if contains, _, err := containsRoute(cidr, c.String()); err == nil && contains {
+			return true
+		}
+	}
+	return false
+}
+
+func getVpcIPv6CIDRs(ctx context.Context, providerIns prvd.Provider) ([]*net.IPNet, error) {
+	vpcId, err := providerIns.VpcID()
+	if err != nil {
+		return nil, fmt.Errorf("get vpc id from metadata error: %s", err.Error())
+	}
+	cidrs, err := providerIns.DescribeVpcCIDRBlock(ctx, vpcId, model.IPv6)
+	if err != nil {
+		return nil, err
+	}
+	var ipv6CIDRs []*net.IPNet
+	for _, cidr := range cidrs {
+		if cidrFamily(cidr) == model.IPv6 {
+			ipv6CIDRs = append(ipv6CIDRs, cidr)
+		}
+	}
+	return ipv6CIDRs, nil
+}
+
 func findRoute(
 	ctx context.Context, table, pvid, cidr string, cachedRoutes []*model.Route, providerIns prvd.IVPC,
 ) (*model.Route, error) {
@@ -258,7 +347,10 @@ func (r *ReconcileRoute) LockedDeleteRoutes(ctx context.Context, reconcileID, ta
 	return r.cloud.DeleteRoutes(ctx, table, routes)
 }
 
-func (r *ReconcileRoute) batchAddRoutes(ctx context.Context, reconcileID string, table string, routes []*model.Route) error {
+// batchAddRoutes creates the routes in the table, and records the failed ones in the results of the nodes.
+func (r *ReconcileRoute) batchAddRoutes(
+	ctx context.Context, reconcileID string, table string, routes []*model.Route, results map[string]routeResults,
+) error {
 	if len(routes) == 0 {
 		return nil
 	}
@@ -281,22 +373,13 @@ func (r *ReconcileRoute) batchAddRoutes(ctx context.Context, reconcileID string,
 		}
 
 		if !s.Failed {
-			err = r.updateNetworkingCondition(ctx, s.Route.NodeReference, true)
-			if err != nil {
-				log.Error(err, "update node network condition error",
-					"node", s.Route.NodeReference.Name, "reconcileID", reconcileID)
-				continue
-			}
-
-			r.nodeCache.SetIfAbsent(s.Route.NodeReference.Name, s.Route)
-			r.rateLimiter.Forget(reconcile.Request{
-				NamespacedName: types.NamespacedName{
-					Name: s.Route.NodeReference.Name,
-				},
-			})
+			r.cacheRoute(s.Route.NodeReference.Name, s.Route)
 			continue
 		}
 
+		if res, ok := results[s.Route.NodeReference.Name]; ok {
+			res[routeFamily(s.Route.DestinationCIDR)] = false
+		}
 		log.Info("error creating route entry, requeue",
 			"node", s.Route.NodeReference.Name, "route", s.Route.DestinationCIDR, "table", table,
 			"message", s.FailedMessage, "code", s.FailedCode, "reconcileID", reconcileID)
@@ -326,7 +409,7 @@ func (r *ReconcileRoute) batchDeleteRoutes(ctx context.Context, reconcileID stri
 		}
 
 		if !s.Failed {
-			r.nodeCache.Remove(s.Route.NodeReference.Name)
+			r.uncacheRoute(s.Route.NodeReference.Name, s.Route.DestinationCIDR)
 			r.rateLimiter.Forget(reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Name: s.Route.NodeReference.Name,
@@ -344,3 +427,42 @@ func (r *ReconcileRoute) batchDeleteRoutes(ctx context.Context, reconcileID stri
 
 	return nil
 }
+
+// cacheRoute remembers the route of the node, the routes are deleted with the node.
+func (r *ReconcileRoute) cacheRoute(nodeName string, route *model.Route) {
+	r.nodeCache.Upsert(nodeName, route, func(exist bool, valueInMap interface{}, newValue interface{}) interface{} {
+		routes, _ := valueInMap.([]*model.Route)
+		for _, cached := range routes {
+			if cached.DestinationCIDR == route.DestinationCIDR {
+				return routes
+			}
+		}
+		return append(append([]*model.Route{}, routes...), route)
+	})
+}
+
+func (r *ReconcileRoute) uncacheRoute(nodeName, cidr string) {
+	r.nodeCache.Upsert(nodeName, nil, func(exist bool, valueInMap interface{}, newValue interface{}) interface{} {
+		routes, _ := valueInMap.([]*model.Route)
+		var left []*model.Route
+		for _, cached := range routes {
+			if cached.DestinationCIDR != cidr {
+				left = append(left, cached)
+			}
+		}
+		return left
+	})
+	r.nodeCache.RemoveCb(nodeName, func(key string, v interface{}, exists bool) bool {
+		routes, _ := v.([]*model.Route)
+		return exists && len(routes) == 0
+	})
+}
+
+func (r *ReconcileRoute) cachedRoutes(nodeName string) []*model.Route {
+	o, ok := r.nodeCache.Get(nodeName)
+	if !ok {
+		return nil
+	}
+	routes, _ := o.([]*model.Route)
+	return routes
+}
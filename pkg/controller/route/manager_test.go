@@ -3,7 +3,9 @@ package route
 import (
 	"context"
 	"fmt"
+	cmap "github.com/orcaman/concurrent-map"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
 	globalCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/vmock"
@@ -139,3 +141,54 @@ func TestFindRouteCached(t *testing.T) {
 		assert.Equal(t, testcase.err, err != nil)
 	}
 }
+
+func TestConflictWithNodes(t *testing.T) {
+	nodes := &v1.NodeList{Items: []v1.Node{
+		{Spec: v1.NodeSpec{
+			ProviderID: "a-123",
+			PodCIDR:    "192.168.0.0/24",
+			PodCIDRs:   []string{"192.168.0.0/24", "2408:4000:0:1::/80"},
+		}},
+	}}
+	vpcIPv6CIDRs := []*net.IPNet{{IP: net.ParseIP("2408:4000::"), Mask: net.CIDRMask(56, 128)}}
+
+	testcases := []struct {
+		route    *model.Route
+		conflict bool
+	}{
+		{route: &model.Route{DestinationCIDR: "192.168.0.0/24", ProviderId: "a-123"}, conflict: false},
+		{route: &model.Route{DestinationCIDR: "192.168.0.0/24", ProviderId: "a-234"}, conflict: true},
+		{route: &model.Route{DestinationCIDR: "192.168.0.0/26", ProviderId: "a-234"}, conflict: true},
+		{route: &model.Route{DestinationCIDR: "2408:4000:0:1::/80", ProviderId: "a-123"}, conflict: false},
+		{route: &model.Route{DestinationCIDR: "2408:4000:0:1::/80", ProviderId: "a-234"}, conflict: true},
+		{route: &model.Route{DestinationCIDR: "2408:4000:0:1::/96", ProviderId: "a-234"}, conflict: true},
+		{route: &model.Route{DestinationCIDR: "2408:4001:0:1::/80", ProviderId: "a-234"}, conflict: false},
+	}
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.conflict, conflictWithNodes(testcase.route, nodes, vpcIPv6CIDRs),
+			fmt.Sprintf("route: %+v", testcase.route))
+	}
+
+	// ipv6 routes are checked as well if the ipv6 cidr of the vpc is unknown
+	assert.True(t, conflictWithNodes(&model.Route{DestinationCIDR: "2408:4000:0:1::/80", ProviderId: "a-234"}, nodes, nil))
+
+	// the pod cidr covering the ipv6 cidr of the vpc is skipped
+	nodes.Items[0].Spec.PodCIDRs = []string{"192.168.0.0/24", "2408:4000::/48"}
+	assert.False(t, conflictWithNodes(&model.Route{DestinationCIDR: "2408:4000:0:1::/80", ProviderId: "a-234"}, nodes, vpcIPv6CIDRs))
+	assert.True(t, conflictWithNodes(&model.Route{DestinationCIDR: "2408:4000:0:1::/80", ProviderId: "a-234"}, nodes, nil))
+}
+
+func TestCacheRoute(t *testing.T) {
+	r := &ReconcileRoute{nodeCache: cmap.New()}
+	r.cacheRoute("node", &model.Route{DestinationCIDR: "192.168.0.0/24"})
+	r.cacheRoute("node", &model.Route{DestinationCIDR: "192.168.0.0/24"})
+	r.cacheRoute("node", &model.Route{DestinationCIDR: "2408:4000:0:1::/80"})
+	assert.Len(t, r.cachedRoutes("node"), 2)
+
+	r.uncacheRoute("node", "192.168.0.0/24")
+	assert.Len(t, r.cachedRoutes("node"), 1)
+	r.uncacheRoute("node", "2408:4000:0:1::/80")
+	assert.False(t, r.nodeCache.Has("node"))
+	r.uncacheRoute("other", "192.168.0.0/24")
+	assert.False(t, r.nodeCache.Has("other"))
+}
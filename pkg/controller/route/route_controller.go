@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -96,6 +97,7 @@ func newReconciler(mgr manager.Manager, ctx *shared.SharedContext, requeue chan<
 		requeueChan:     requeue,
 		requestChan:     make(chan reconcile.Request, ctrlCfg.ControllerCFG.RouteReconcileBatchSize*ctrlCfg.CloudCFG.Global.RouteMaxConcurrentReconciles),
 		rateLimiter:     rateLimiter,
+		resyncChan:      make(chan struct{}, 1),
 	}
 	return recon
 }
@@ -138,6 +140,11 @@ type ReconcileRoute struct {
 	requestChan chan reconcile.Request
 
 	rateLimiter workqueue.RateLimiter
+
+	// resyncChan requests a reconcile of the cluster, e.g. to split the aggregated routes of a deleted node
+	resyncChan chan struct{}
+	// clusterLock serializes the reconciles of the cluster
+	clusterLock sync.Mutex
 }
 
 func (r *ReconcileRoute) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
@@ -225,8 +232,8 @@ func (r *ReconcileRoute) syncCloudRoute(ctx context.Context, node *corev1.Node)
 		return nil
 	}
 
-	_, ipv4RouteCidr, err := getIPv4RouteForNode(node)
-	if err != nil || ipv4RouteCidr == "" {
+	routeCidrs, err := getRoutesForNode(node)
+	if err != nil {
 		klog.Warningf("node %s parse podCIDR %s error, skip creating route", node.Name, node.Spec.PodCIDR)
 		if err1 := r.updateNetworkingCondition(ctx, node, false); err1 != nil {
 			klog.Errorf("route, update network condition error: %v", err1)
@@ -238,23 +245,32 @@ func (r *ReconcileRoute) syncCloudRoute(ctx context.Context, node *corev1.Node)
 	if err != nil {
 		return err
 	}
+	assignment, err := r.assignRouteTables(ctx, tables, []*corev1.Node{node})
+	if err != nil {
+		return err
+	}
 	var tablesErr []error
-	for _, table := range tables {
-		tablesErr = append(tablesErr, r.addRouteForNode(ctx, table, ipv4RouteCidr, prvdId, node, nil))
+	results := routeResults{}
+	for family, cidr := range routeCidrs {
+		results[family] = len(assignment[node.Name]) != 0
+		for _, table := range assignment[node.Name] {
+			if err := r.addRouteForNode(ctx, table, cidr, prvdId, node, nil); err != nil {
+				tablesErr = append(tablesErr, err)
+				results[family] = false
+			}
+		}
 	}
-	if utilerrors.NewAggregate(tablesErr) != nil {
-		err := r.updateNetworkingCondition(ctx, node, false)
-		if err != nil {
-			klog.Errorf("update network condition for node %s, error: %v", node.Name, err.Error())
+	if err := r.updateNetworkingConditionByFamily(ctx, node, results); err != nil {
+		if len(tablesErr) == 0 {
+			return err
 		}
-		return utilerrors.NewAggregate(tablesErr)
-	} else {
-		return r.updateNetworkingCondition(ctx, node, true)
+		klog.Errorf("update network condition for node %s, error: %v", node.Name, err.Error())
 	}
+	return utilerrors.NewAggregate(tablesErr)
 }
 
 func (r *ReconcileRoute) addRouteForNode(
-	ctx context.Context, table, ipv4Cidr, prvdId string, node *corev1.Node, cachedRouteEntry []*model.Route,
+	ctx context.Context, table, cidr, prvdId string, node *corev1.Node, cachedRouteEntry []*model.Route,
 ) error {
 	var err error
 	nodeRef := &corev1.ObjectReference{
@@ -264,7 +280,7 @@ func (r *ReconcileRoute) addRouteForNode(
 		Namespace: "",
 	}
 
-	route, findErr := findRoute(ctx, table, prvdId, ipv4Cidr, cachedRouteEntry, r.cloud)
+	route, findErr := findRoute(ctx, table, prvdId, cidr, cachedRouteEntry, r.cloud)
 	if findErr != nil {
 		klog.Errorf("error found exist route for instance: %v, %v", prvdId, findErr)
 		r.record.Event(
@@ -277,10 +293,10 @@ func (r *ReconcileRoute) addRouteForNode(
 	}
 
 	// route not found, try to create route
-	if route == nil || route.DestinationCIDR != ipv4Cidr {
-		klog.Infof("create routes for node %s: %v - %v", node.Name, prvdId, ipv4Cidr)
+	if route == nil || route.DestinationCIDR != cidr {
+		klog.Infof("create routes for node %s: %v - %v", node.Name, prvdId, cidr)
 		start := time.Now()
-		route, err = createRouteForInstance(ctx, table, prvdId, ipv4Cidr, r.cloud)
+		route, err = createRouteForInstance(ctx, table, prvdId, cidr, r.cloud)
 		if err != nil {
 			klog.Errorf("error create route for node %v : instance id [%v], route [%v], err: %s", node.Name, prvdId, table, err.Error())
 			r.record.Event(
@@ -290,30 +306,41 @@ func (r *ReconcileRoute) addRouteForNode(
 				fmt.Sprintf("Error creating route entry in %s: %s", table, helper.GetLogMessage(err)),
 			)
 		} else {
-			klog.Infof("Created route for %s with %s - %s successfully", table, node.Name, ipv4Cidr)
+			klog.Infof("Created route for %s with %s - %s successfully", table, node.Name, cidr)
 			r.record.Event(
 				nodeRef,
 				corev1.EventTypeNormal,
 				helper.SucceedCreateRoute,
-				fmt.Sprintf("Created route for %s with %s -> %s successfully", table, node.Name, ipv4Cidr),
+				fmt.Sprintf("Created route for %s with %s -> %s successfully", table, node.Name, cidr),
 			)
 		}
 		metric.RouteLatency.WithLabelValues("create").Observe(metric.MsSince(start))
 	}
 	if route != nil {
-		r.nodeCache.SetIfAbsent(node.Name, route)
+		r.cacheRoute(node.Name, route)
 	}
 	return err
 }
 
 func (r *ReconcileRoute) updateNetworkingCondition(ctx context.Context, node *corev1.Node, routeCreated bool) error {
+	return r.updateNetworkingConditionByFamily(ctx, node, routeResults{model.IPv4: routeCreated})
+}
+
+// updateNetworkingConditionByFamily sets NodeNetworkUnavailable=false if the routes of all the ip families
+// of the node are created, the message tells the result of each family for dual-stack nodes.
+func (r *ReconcileRoute) updateNetworkingConditionByFamily(ctx context.Context, node *corev1.Node, results routeResults) error {
+	routeCreated := results.created()
+	message := results.message()
 	networkCondition, ok := helper.FindCondition(node.Status.Conditions, corev1.NodeNetworkUnavailable)
-	if routeCreated && ok && networkCondition.Status == corev1.ConditionFalse {
+	// the condition may be set by the network plugin, it is overwritten only if the status or the result of
+	// a family changes
+	sameResult := len(results) == 1 || networkCondition.Message == message
+	if routeCreated && ok && networkCondition.Status == corev1.ConditionFalse && sameResult {
 		klog.V(2).Infof("set node %v with NodeNetworkUnavailable=false was canceled because it is already set", node.Name)
 		return nil
 	}
 
-	if !routeCreated && ok && networkCondition.Status == corev1.ConditionTrue {
+	if !routeCreated && ok && networkCondition.Status == corev1.ConditionTrue && sameResult {
 		klog.V(2).Infof("set node %v with NodeNetworkUnavailable=true was canceled because it is already set", node.Name)
 		return nil
 	}
@@ -332,12 +359,11 @@ func (r *ReconcileRoute) updateNetworkingCondition(ctx context.Context, node *co
 			if routeCreated {
 				condition.Status = corev1.ConditionFalse
 				condition.Reason = "RouteCreated"
-				condition.Message = "RouteController created a route"
 			} else {
 				condition.Status = corev1.ConditionTrue
 				condition.Reason = "NoRouteCreated"
-				condition.Message = "RouteController failed to create a route"
 			}
+			condition.Message = message
 			if !ok {
 				nins.Status.Conditions = append(nins.Status.Conditions, *condition)
 			}
@@ -362,9 +388,25 @@ func (r *ReconcileRoute) periodicalSync() {
 		time.Sleep(r.reconcilePeriod)
 		wait.Until(r.reconcileForCluster, r.reconcilePeriod, wait.NeverStop)
 	}()
+	// the requests of the batch workers are merged while a reconcile is running
+	go func() {
+		for range r.resyncChan {
+			r.reconcileForCluster()
+		}
+	}()
+}
+
+// requestResync asks periodicalSync to reconcile the cluster, it does not block if a request is pending.
+func (r *ReconcileRoute) requestResync() {
+	select {
+	case r.resyncChan <- struct{}{}:
+	default:
+	}
 }
 
 func (r *ReconcileRoute) reconcileForCluster() {
+	r.clusterLock.Lock()
+	defer r.clusterLock.Unlock()
 	ctx := context.Background()
 	start := time.Now()
 	defer func() {
@@ -388,12 +430,47 @@ func (r *ReconcileRoute) reconcileForCluster() {
 		return
 	}
 
+	var nodeRefs []*corev1.Node
+	for i := range nodes.Items {
+		nodeRefs = append(nodeRefs, &nodes.Items[i])
+	}
+	assignment, err := r.assignRouteTables(ctx, tables, nodeRefs)
+	if err != nil {
+		klog.Errorf("sync route tables error: assign route tables: %v", err)
+		r.record.Event(
+			&corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "route-controller"}},
+			corev1.EventTypeWarning, helper.FailedSyncRoute,
+			fmt.Sprintf("Reconciling route error: %s", err.Error()),
+		)
+		return
+	}
+
 	var failedTableIds []string
+	results := map[string]routeResults{}
+	failedNodes := map[string]bool{}
 	for _, table := range tables {
 		// Sync for nodes
-		if err := r.syncTableRoutes(ctx, table, nodes); err != nil {
+		if err := r.syncTableRoutes(ctx, table, nodes, assignment, results); err != nil {
 			failedTableIds = append(failedTableIds, table)
 			klog.Errorf("sync route tables error: sync table [%s] error: %s", table, err.Error())
+			for _, n := range nodes.Items {
+				if assignment.assigned(n.Name, table) {
+					failedNodes[n.Name] = true
+				}
+			}
+		}
+	}
+
+	// the network conditions are updated with the results of all the tables, the nodes of the failed
+	// tables are left to the next sync
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		nodeResults, ok := results[node.Name]
+		if !ok || failedNodes[node.Name] || (len(nodeResults) == 1 && !nodeResults.created()) {
+			continue
+		}
+		if err := r.updateNetworkingConditionByFamily(ctx, node, nodeResults); err != nil {
+			klog.Errorf("update node %s network condition err: %s", node.Name, err.Error())
 		}
 	}
 
@@ -413,6 +490,7 @@ func (r *ReconcileRoute) batchSyncCloudRoutes(ctx context.Context, reconcileID s
 	var toAddedNodes []*corev1.Node
 	toAdd := map[string][]*model.Route{}
 	var toDelete []*model.Route
+	resplit := false
 
 	for _, request := range requests {
 		n := &corev1.Node{}
@@ -420,19 +498,19 @@ func (r *ReconcileRoute) batchSyncCloudRoutes(ctx context.Context, reconcileID s
 		if err != nil {
 			// todo: check deletion timestamp
 			if errors.IsNotFound(err) {
-				if o, ok := r.nodeCache.Get(request.Name); ok {
-					if route, ok := o.(*model.Route); ok {
-						toDelete = append(toDelete, &model.Route{
-							Name:            route.Name,
-							DestinationCIDR: route.DestinationCIDR,
-							ProviderId:      route.ProviderId,
-							NodeReference: &corev1.Node{
-								ObjectMeta: metav1.ObjectMeta{
-									Name: request.Name,
-								},
+				// the aggregated routes covering the node are split
+				resplit = len(ctrlCfg.CloudCFG.Global.RouteAggregations) != 0
+				for _, route := range r.cachedRoutes(request.Name) {
+					toDelete = append(toDelete, &model.Route{
+						Name:            route.Name,
+						DestinationCIDR: route.DestinationCIDR,
+						ProviderId:      route.ProviderId,
+						NodeReference: &corev1.Node{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: request.Name,
 							},
-						})
-					}
+						},
+					})
 				}
 				// node not found, ignore
 				continue
@@ -459,8 +537,7 @@ func (r *ReconcileRoute) batchSyncCloudRoutes(ctx context.Context, reconcileID s
 
 		}
 
-		_, ipv4RouteCidr, err := getIPv4RouteForNode(n)
-		if err != nil || ipv4RouteCidr == "" {
+		if _, err := getRoutesForNode(n); err != nil {
 			log.Error(err, "node parse podCIDR error, skip creating route",
 				"node", n.Name, "cidr", n.Spec.PodCIDR, "reconcileID", reconcileID)
 			if err1 := r.updateNetworkingCondition(ctx, n, false); err1 != nil {
@@ -473,6 +550,10 @@ func (r *ReconcileRoute) batchSyncCloudRoutes(ctx context.Context, reconcileID s
 		toAddedNodes = append(toAddedNodes, n)
 	}
 
+	if resplit {
+		defer r.requestResync()
+	}
+
 	if len(toAddedNodes) == 0 && len(toDelete) == 0 {
 		log.Info("no route need to be added.", "reconcileID", reconcileID)
 		return nil
@@ -484,6 +565,11 @@ func (r *ReconcileRoute) batchSyncCloudRoutes(ctx context.Context, reconcileID s
 		return err
 	}
 
+	assignment, err := r.assignRouteTables(ctx, tables, toAddedNodes)
+	if err != nil {
+		return err
+	}
+
 	cachedRoutes := map[string][]*model.Route{}
 	for _, t := range tables {
 		routes, err := r.cloud.ListRoute(ctx, t)
@@ -493,45 +579,51 @@ func (r *ReconcileRoute) batchSyncCloudRoutes(ctx context.Context, reconcileID s
 		cachedRoutes[t] = routes
 	}
 
+	// results records whether the routes of each family of the nodes are created in all the tables,
+	// the nodes which are requeued for errors are left to the next reconcile.
+	results := map[string]routeResults{}
+	requeued := map[string]bool{}
 	for _, n := range toAddedNodes {
-		for _, table := range tables {
-			_, ipv4RouteCidr, _ := getIPv4RouteForNode(n)
-
-			route, err := findRoute(ctx, table, n.Spec.ProviderID, ipv4RouteCidr, cachedRoutes[table], r.cloud)
-			if err != nil {
-				log.Error(err, "error find route existence for instance", "providerID", n.Spec.ProviderID, "reconcileID", reconcileID)
-				nodeRef := &corev1.ObjectReference{
-					Kind: "Node",
-					Name: n.Name,
-					UID:  n.UID,
+		routeCidrs, _ := getRoutesForNode(n)
+		results[n.Name] = routeResults{}
+		for family, cidr := range routeCidrs {
+			results[n.Name][family] = len(assignment[n.Name]) != 0
+			for _, table := range assignment[n.Name] {
+				route, err := findRoute(ctx, table, n.Spec.ProviderID, cidr, cachedRoutes[table], r.cloud)
+				if err != nil {
+					log.Error(err, "error find route existence for instance", "providerID", n.Spec.ProviderID, "reconcileID", reconcileID)
+					nodeRef := &corev1.ObjectReference{
+						Kind: "Node",
+						Name: n.Name,
+						UID:  n.UID,
+					}
+					r.record.Event(
+						nodeRef,
+						corev1.EventTypeWarning,
+						"DescriberRouteFailed",
+						fmt.Sprintf("Describe Route Failed for %s reason: %s", table, helper.GetLogMessage(err)),
+					)
+					r.requeueNode(n)
+					requeued[n.Name] = true
+					continue
 				}
-				r.record.Event(
-					nodeRef,
-					corev1.EventTypeWarning,
-					"DescriberRouteFailed",
-					fmt.Sprintf("Describe Route Failed for %s reason: %s", table, helper.GetLogMessage(err)),
-				)
-				r.requeueNode(n)
-				continue
-			}
 
-			if route == nil {
-				toAdd[table] = append(toAdd[table], &model.Route{
-					Name:            fmt.Sprintf("%s-%s", n.Spec.ProviderID, ipv4RouteCidr),
-					DestinationCIDR: ipv4RouteCidr,
-					ProviderId:      n.Spec.ProviderID,
-					NodeReference:   n,
-				})
-			} else {
-				r.rateLimiter.Forget(reconcile.Request{
-					NamespacedName: types.NamespacedName{
-						Name: n.Name,
-					},
-				})
+				if route == nil && !coveredByAggregatedRoute(cidr, cachedRoutes[table]) {
+					toAdd[table] = append(toAdd[table], &model.Route{
+						Name:            fmt.Sprintf("%s-%s", n.Spec.ProviderID, cidr),
+						DestinationCIDR: cidr,
+						ProviderId:      n.Spec.ProviderID,
+						NodeReference:   n,
+					})
+				}
 			}
 		}
 	}
 
+	for _, t := range tables {
+		r.checkRouteQuota(t, len(cachedRoutes[t])+len(toAdd[t]))
+	}
+
 	preparedTime := time.Now()
 	log.Info("Start sync routes", "reconcileID", reconcileID)
 
@@ -549,7 +641,7 @@ func (r *ReconcileRoute) batchSyncCloudRoutes(ctx context.Context, reconcileID s
 			}
 			errs = append(errs, err)
 		}
-		err = r.batchAddRoutes(ctx, reconcileID, t, toAdd[t])
+		err = r.batchAddRoutes(ctx, reconcileID, t, toAdd[t], results)
 		if err != nil {
 			var toAddNames []string
 			for _, d := range toAdd[t] {
@@ -558,12 +650,30 @@ func (r *ReconcileRoute) batchSyncCloudRoutes(ctx context.Context, reconcileID s
 			log.Error(err, "batch add routes failed, requeue all routes", "table", t, "entries", toAdd[t], "reconcileID", reconcileID)
 			for _, route := range toAdd[t] {
 				r.requeueNode(route.NodeReference)
+				requeued[route.NodeReference.Name] = true
 			}
 			errs = append(errs, err)
 			continue
 		}
 	}
 
+	for _, n := range toAddedNodes {
+		if requeued[n.Name] {
+			continue
+		}
+		if err := r.updateNetworkingConditionByFamily(ctx, n, results[n.Name]); err != nil {
+			log.Error(err, "update node network condition error", "node", n.Name, "reconcileID", reconcileID)
+			continue
+		}
+		if results[n.Name].created() {
+			r.rateLimiter.Forget(reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name: n.Name,
+				},
+			})
+		}
+	}
+
 	log.Info("Sync cloud routes done", "reconcileID", reconcileID,
 		"prepareTime", preparedTime.Sub(startTime).Seconds(), "syncTime", time.Now().Sub(preparedTime).Seconds())
 
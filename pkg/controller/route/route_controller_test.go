@@ -8,6 +8,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/vmock"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
@@ -50,6 +51,45 @@ func TestUpdateNetworkingCondition(t *testing.T) {
 
 }
 
+func TestUpdateNetworkingConditionByFamily(t *testing.T) {
+	r := getReconcileRoute()
+
+	node := &v1.Node{}
+	err := r.client.Get(context.TODO(), client.ObjectKey{Name: "cn-hangzhou.192.0.168.69"}, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.updateNetworkingConditionByFamily(context.TODO(), node, routeResults{model.IPv4: true, model.IPv6: false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.client.Get(context.TODO(), util.NamespacedName(node), node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	networkCondition, ok := helper.FindCondition(node.Status.Conditions, v1.NodeNetworkUnavailable)
+	if !ok || networkCondition.Status != v1.ConditionTrue || networkCondition.Reason != "NoRouteCreated" {
+		t.Errorf("node condition update failed: %+v", networkCondition)
+	}
+	if networkCondition.Message != "RouteController created the ipv4 route, failed to create the ipv6 route" {
+		t.Errorf("unexpected condition message: %s", networkCondition.Message)
+	}
+
+	err = r.updateNetworkingConditionByFamily(context.TODO(), node, routeResults{model.IPv4: true, model.IPv6: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.client.Get(context.TODO(), util.NamespacedName(node), node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	networkCondition, ok = helper.FindCondition(node.Status.Conditions, v1.NodeNetworkUnavailable)
+	if !ok || networkCondition.Status != v1.ConditionFalse || networkCondition.Reason != "RouteCreated" {
+		t.Errorf("node condition update failed: %+v", networkCondition)
+	}
+}
+
 func getReconcileRoute() *ReconcileRoute {
 	eventRecord := record.NewFakeRecorder(100)
 	recon := &ReconcileRoute{
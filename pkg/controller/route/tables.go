@@ -0,0 +1,123 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+	"k8s.io/klog/v2"
+)
+
+// tableAssignment maps the node name to the route tables the routes of the node are written into
+type tableAssignment map[string][]string
+
+func (a tableAssignment) assigned(nodeName, table string) bool {
+	for _, t := range a[nodeName] {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// assignRouteTables assigns the nodes to the route tables. By default the routes of each node are written
+// into all the tables. In vswitch mode they are written only into the table the vswitch of the node is
+// associated with, the nodes whose vswitch is associated with none of the tables are left unassigned.
+func (r *ReconcileRoute) assignRouteTables(ctx context.Context, tables []string, nodes []*corev1.Node) (tableAssignment, error) {
+	assignment := tableAssignment{}
+	if ctrlCfg.CloudCFG.Global.RouteTableMode != ctrlCfg.RouteTableModeVSwitch {
+		for _, n := range nodes {
+			assignment[n.Name] = tables
+		}
+		return assignment, nil
+	}
+
+	var ids []string
+	for _, n := range nodes {
+		if n.Spec.ProviderID != "" {
+			ids = append(ids, n.Spec.ProviderID)
+		}
+	}
+	if len(ids) == 0 {
+		return assignment, nil
+	}
+	instances, err := r.cloud.ListInstances(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("list instances of nodes error: %s", err.Error())
+	}
+	vpcId, err := r.cloud.VpcID()
+	if err != nil {
+		return nil, fmt.Errorf("get vpc id from metadata error: %s", err.Error())
+	}
+	vSwitches, err := r.cloud.DescribeVSwitches(ctx, vpcId)
+	if err != nil {
+		return nil, fmt.Errorf("describe vswitches of vpc %s error: %s", vpcId, err.Error())
+	}
+	vswTables := map[string]string{}
+	for _, vsw := range vSwitches {
+		vswTables[vsw.VSwitchId] = vsw.RouteTable.RouteTableId
+	}
+	managed := map[string]bool{}
+	for _, t := range tables {
+		managed[t] = true
+	}
+
+	for _, n := range nodes {
+		ins := instances[n.Spec.ProviderID]
+		if ins == nil {
+			klog.Warningf("instance of node %s not found, skip assigning route table", n.Name)
+			continue
+		}
+		table := vswTables[ins.VSwitchID]
+		if !managed[table] {
+			klog.Warningf("route table %s of vswitch %s of node %s is not managed, skip creating route",
+				table, ins.VSwitchID, n.Name)
+			r.record.Eventf(n, corev1.EventTypeWarning, helper.UnassignedRoute,
+				"Route table %s of vswitch %s is not one of the route tables [%v]", table, ins.VSwitchID, tables)
+			continue
+		}
+		assignment[n.Name] = []string{table}
+	}
+	return assignment, nil
+}
+
+// misplacedRoute checks whether the route belongs to a node which is assigned to other route tables, it is
+// only the case in vswitch mode, e.g. after the mode is switched or the vswitch is associated with another table.
+func misplacedRoute(route *model.Route, table string, nodes []*corev1.Node, assignment tableAssignment) bool {
+	for _, n := range nodes {
+		if n.Spec.ProviderID != route.ProviderId {
+			continue
+		}
+		_, cidr, err := getRouteForNode(n, routeFamily(route.DestinationCIDR))
+		if err != nil || cidr != route.DestinationCIDR {
+			continue
+		}
+		return len(assignment[n.Name]) != 0 && !assignment.assigned(n.Name, table)
+	}
+	return false
+}
+
+// checkRouteQuota records the usage of the route entry quota of the table, and warns before the quota is
+// exhausted. Only the routes pointing to instances are counted, other custom routes take the quota as well.
+func (r *ReconcileRoute) checkRouteQuota(table string, entries int) {
+	quota := ctrlCfg.CloudCFG.Global.RouteEntryQuota
+	if quota <= 0 {
+		quota = ctrlCfg.DefaultRouteEntryQuota
+	}
+	usage := float64(entries) / float64(quota)
+	metric.RouteTableEntries.WithLabelValues(table).Set(float64(entries))
+	metric.RouteTableQuotaUsage.WithLabelValues(table).Set(usage)
+	if usage < routeQuotaWarningRatio {
+		return
+	}
+	klog.Warningf("route table %s has %d route entries, %.0f%% of the quota %d", table, entries, usage*100, quota)
+	r.record.Eventf(
+		&corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "route-controller"}},
+		corev1.EventTypeWarning, helper.RouteQuotaWarning,
+		"Route table %s has %d route entries, %.0f%% of the quota %d", table, entries, usage*100, quota,
+	)
+}
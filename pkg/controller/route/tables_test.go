@@ -0,0 +1,114 @@
+package route
+
+import (
+	"context"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/vmock"
+	"testing"
+)
+
+func getShardedNodes() []*v1.Node {
+	var nodes []*v1.Node
+	for _, n := range []struct{ name, pvid, cidr string }{
+		{"node-1", "cn-hangzhou.i-1", "172.20.0.0/24"},
+		{"node-2", "cn-hangzhou.i-2", "172.20.1.0/24"},
+		{"node-3", "cn-hangzhou.i-3", "172.20.2.0/24"},
+	} {
+		nodes = append(nodes, &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: n.name},
+			Spec:       v1.NodeSpec{ProviderID: n.pvid, PodCIDR: n.cidr},
+		})
+	}
+	return nodes
+}
+
+func getShardedReconcileRoute() *ReconcileRoute {
+	vSwitch := func(id, table string) vpc.VSwitch {
+		vsw := vpc.VSwitch{VSwitchId: id}
+		vsw.RouteTable.RouteTableId = table
+		return vsw
+	}
+	return &ReconcileRoute{
+		cloud: snapshot.NewSnapshotCloud(&snapshot.Snapshot{
+			Region: "cn-hangzhou",
+			VpcID:  "vpc-1",
+			VSwitches: []vpc.VSwitch{
+				vSwitch("vsw-1", "vtb-1"), vSwitch("vsw-2", "vtb-2"), vSwitch("vsw-3", "vtb-system"),
+			},
+			Instances: []prvd.NodeAttribute{
+				{InstanceID: "i-1", VSwitchID: "vsw-1"},
+				{InstanceID: "i-2", VSwitchID: "vsw-2"},
+				{InstanceID: "i-3", VSwitchID: "vsw-3"},
+			},
+			RouteTables: map[string][]model.Route{"vtb-1": nil, "vtb-2": nil},
+		}),
+		record: record.NewFakeRecorder(100),
+	}
+}
+
+func TestAssignRouteTables(t *testing.T) {
+	r := getShardedReconcileRoute()
+	nodes := getShardedNodes()
+	tables := []string{"vtb-1", "vtb-2"}
+
+	assignment, err := r.assignRouteTables(context.TODO(), tables, nodes)
+	assert.NoError(t, err)
+	assert.Equal(t, tableAssignment{"node-1": tables, "node-2": tables, "node-3": tables}, assignment)
+
+	ctrlCfg.CloudCFG.Global.RouteTableMode = ctrlCfg.RouteTableModeVSwitch
+	defer func() { ctrlCfg.CloudCFG.Global.RouteTableMode = "" }()
+	assignment, err = r.assignRouteTables(context.TODO(), tables, nodes)
+	assert.NoError(t, err)
+	// the table of vsw-3 is not managed
+	assert.Equal(t, tableAssignment{"node-1": {"vtb-1"}, "node-2": {"vtb-2"}}, assignment)
+	assert.True(t, assignment.assigned("node-1", "vtb-1"))
+	assert.False(t, assignment.assigned("node-1", "vtb-2"))
+	assert.False(t, assignment.assigned("node-3", "vtb-1"))
+}
+
+func TestMisplacedRoute(t *testing.T) {
+	nodes := getShardedNodes()
+	assignment := tableAssignment{"node-1": {"vtb-1"}, "node-2": {"vtb-2"}}
+
+	route := &model.Route{DestinationCIDR: "172.20.0.0/24", ProviderId: "cn-hangzhou.i-1"}
+	assert.False(t, misplacedRoute(route, "vtb-1", nodes, assignment))
+	assert.True(t, misplacedRoute(route, "vtb-2", nodes, assignment))
+	// the routes of the unassigned nodes and the unknown routes are kept
+	route = &model.Route{DestinationCIDR: "172.20.2.0/24", ProviderId: "cn-hangzhou.i-3"}
+	assert.False(t, misplacedRoute(route, "vtb-1", nodes, assignment))
+	route = &model.Route{DestinationCIDR: "172.20.9.0/24", ProviderId: "cn-hangzhou.i-1"}
+	assert.False(t, misplacedRoute(route, "vtb-2", nodes, assignment))
+}
+
+func TestCheckRouteQuota(t *testing.T) {
+	r := getShardedReconcileRoute()
+	recorder := r.record.(*record.FakeRecorder)
+	ctrlCfg.CloudCFG.Global.RouteEntryQuota = 10
+	defer func() { ctrlCfg.CloudCFG.Global.RouteEntryQuota = 0 }()
+
+	r.checkRouteQuota("vtb-1", 7)
+	assert.Len(t, recorder.Events, 0)
+	r.checkRouteQuota("vtb-1", 8)
+	assert.Len(t, recorder.Events, 1)
+}
+
+func TestGetRouteTablesVSwitchMode(t *testing.T) {
+	multiRouteTableVPC := vmock.MockCloud{
+		MockVPC:   vmock.NewMockVPC(nil),
+		IMetaData: vmock.NewMockMetaData("vpc-multi-route-table"),
+	}
+	ctrlCfg.CloudCFG.Global.RouteTableIDS = ""
+	ctrlCfg.CloudCFG.Global.RouteTableMode = ctrlCfg.RouteTableModeVSwitch
+	defer func() { ctrlCfg.CloudCFG.Global.RouteTableMode = "" }()
+	tables, err := getRouteTables(context.Background(), multiRouteTableVPC)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"route-table-1", "route-table-2"}, tables)
+}
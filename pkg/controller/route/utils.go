@@ -3,27 +3,142 @@ package route
 import (
 	"fmt"
 	v1 "k8s.io/api/core/v1"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"net"
+	"strings"
 )
 
 func getIPv4RouteForNode(node *v1.Node) (*net.IPNet, string, error) {
-	var (
-		ipv4CIDR    *net.IPNet
-		ipv4CIDRStr string
-		err         error
-	)
+	return getRouteForNode(node, model.IPv4)
+}
+
+func getIPv6RouteForNode(node *v1.Node) (*net.IPNet, string, error) {
+	return getRouteForNode(node, model.IPv6)
+}
+
+// getRouteForNode returns the pod cidr of the node in the ip family, or nil if the node has none.
+func getRouteForNode(node *v1.Node, ipVersion model.AddressIPVersionType) (*net.IPNet, string, error) {
 	for _, podCidr := range append(node.Spec.PodCIDRs, node.Spec.PodCIDR) {
-		if podCidr != "" {
-			_, ipv4CIDR, err = net.ParseCIDR(podCidr)
-			if err != nil {
-				return nil, "", fmt.Errorf("invalid pod cidr on node spec: %v", podCidr)
-			}
-			ipv4CIDRStr = ipv4CIDR.String()
-			if len(ipv4CIDR.Mask) == net.IPv4len {
-				ipv4CIDRStr = ipv4CIDR.String()
-				break
-			}
-		}
-	}
-	return ipv4CIDR, ipv4CIDRStr, nil
+		if podCidr == "" {
+			continue
+		}
+		_, cidr, err := net.ParseCIDR(podCidr)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid pod cidr on node spec: %v", podCidr)
+		}
+		if cidrFamily(cidr) == ipVersion {
+			return cidr, cidr.String(), nil
+		}
+	}
+	return nil, "", nil
+}
+
+// routeFamilies returns the ip families the routes are created for, ipv6 routes are created
+// only if the IPv6DualStack feature gate is enabled.
+func routeFamilies() []model.AddressIPVersionType {
+	if utilfeature.DefaultFeatureGate.Enabled(ctrlCfg.IPv6DualStack) {
+		return []model.AddressIPVersionType{model.IPv4, model.IPv6}
+	}
+	return []model.AddressIPVersionType{model.IPv4}
+}
+
+// getRoutesForNode returns the pod cidrs of the node which need routes, keyed by the ip family.
+// An error is returned if the node has no pod cidr of any family.
+func getRoutesForNode(node *v1.Node) (map[model.AddressIPVersionType]string, error) {
+	cidrs := map[model.AddressIPVersionType]string{}
+	for _, family := range routeFamilies() {
+		_, cidr, err := getRouteForNode(node, family)
+		if err != nil {
+			return nil, err
+		}
+		if cidr != "" {
+			cidrs[family] = cidr
+		}
+	}
+	if len(cidrs) == 0 {
+		return nil, fmt.Errorf("node %s has no pod cidr", node.Name)
+	}
+	return cidrs, nil
+}
+
+func cidrFamily(cidr *net.IPNet) model.AddressIPVersionType {
+	if cidr.IP.To4() != nil {
+		return model.IPv4
+	}
+	return model.IPv6
+}
+
+func routeFamily(route string) model.AddressIPVersionType {
+	if strings.Contains(route, ":") {
+		return model.IPv6
+	}
+	return model.IPv4
+}
+
+// getClusterCIDRs parses the cluster cidr, which is a comma-separated list of at most one cidr
+// per ip family, e.g. "172.20.0.0/16,fd00::/104" for dual-stack clusters.
+func getClusterCIDRs(clusterCIDR string) (map[model.AddressIPVersionType]*net.IPNet, error) {
+	cidrs := map[model.AddressIPVersionType]*net.IPNet{}
+	if clusterCIDR == "" {
+		return cidrs, nil
+	}
+	for _, c := range strings.Split(clusterCIDR, ",") {
+		_, cidr, err := net.ParseCIDR(strings.TrimSpace(c))
+		if err != nil {
+			return nil, fmt.Errorf("error parse cluster cidr %s: %s", clusterCIDR, err)
+		}
+		family := cidrFamily(cidr)
+		if _, ok := cidrs[family]; ok {
+			return nil, fmt.Errorf("error parse cluster cidr %s: more than one %s cidr", clusterCIDR, family)
+		}
+		cidrs[family] = cidr
+	}
+	return cidrs, nil
+}
+
+// routeResults records whether the routes of each ip family of a node are created
+type routeResults map[model.AddressIPVersionType]bool
+
+func (r routeResults) created() bool {
+	for _, created := range r {
+		if !created {
+			return false
+		}
+	}
+	return len(r) != 0
+}
+
+// merge combines the results of another route table, a family is created only if it is created in both.
+func (r routeResults) merge(other routeResults) {
+	for family, created := range other {
+		if prev, ok := r[family]; ok {
+			created = created && prev
+		}
+		r[family] = created
+	}
+}
+
+// message reports the result of each family, the message of single-stack nodes is kept as it was.
+func (r routeResults) message() string {
+	if len(r) == 1 {
+		if r.created() {
+			return "RouteController created a route"
+		}
+		return "RouteController failed to create a route"
+	}
+	var results []string
+	for _, family := range []model.AddressIPVersionType{model.IPv4, model.IPv6} {
+		created, ok := r[family]
+		if !ok {
+			continue
+		}
+		if created {
+			results = append(results, fmt.Sprintf("created the %s route", family))
+		} else {
+			results = append(results, fmt.Sprintf("failed to create the %s route", family))
+		}
+	}
+	return "RouteController " + strings.Join(results, ", ")
 }
@@ -3,6 +3,9 @@ package route
 import (
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"testing"
 )
 
@@ -47,3 +50,75 @@ func TestGetIPv4RouteForNode(t *testing.T) {
 		assert.Equal(t, testcase.err, err != nil)
 	}
 }
+
+func TestGetIPv6RouteForNode(t *testing.T) {
+	node := v1.Node{Spec: v1.NodeSpec{PodCIDR: "192.168.0.0/24", PodCIDRs: []string{"192.168.0.0/24", "fd00::1:0/112"}}}
+	_, cidr, err := getIPv6RouteForNode(&node)
+	assert.NoError(t, err)
+	assert.Equal(t, "fd00::1:0/112", cidr)
+
+	// ipv6 cidrs are never taken as the ipv4 route
+	node = v1.Node{Spec: v1.NodeSpec{PodCIDR: "fd00::1:0/112", PodCIDRs: []string{"fd00::1:0/112"}}}
+	_, cidr, err = getIPv4RouteForNode(&node)
+	assert.NoError(t, err)
+	assert.Equal(t, "", cidr)
+	_, cidr, err = getIPv6RouteForNode(&node)
+	assert.NoError(t, err)
+	assert.Equal(t, "fd00::1:0/112", cidr)
+}
+
+func TestGetRoutesForNode(t *testing.T) {
+	node := v1.Node{Spec: v1.NodeSpec{PodCIDR: "192.168.0.0/24", PodCIDRs: []string{"192.168.0.0/24", "fd00::1:0/112"}}}
+	cidrs, err := getRoutesForNode(&node)
+	assert.NoError(t, err)
+	assert.Equal(t, map[model.AddressIPVersionType]string{model.IPv4: "192.168.0.0/24"}, cidrs)
+
+	assert.Nil(t, utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(ctrlCfg.IPv6DualStack): true}))
+	defer func() {
+		_ = utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(ctrlCfg.IPv6DualStack): false})
+	}()
+	cidrs, err = getRoutesForNode(&node)
+	assert.NoError(t, err)
+	assert.Equal(t, map[model.AddressIPVersionType]string{model.IPv4: "192.168.0.0/24", model.IPv6: "fd00::1:0/112"}, cidrs)
+
+	_, err = getRoutesForNode(&v1.Node{})
+	assert.Error(t, err)
+}
+
+func TestGetClusterCIDRs(t *testing.T) {
+	cidrs, err := getClusterCIDRs("")
+	assert.NoError(t, err)
+	assert.Empty(t, cidrs)
+
+	cidrs, err = getClusterCIDRs("172.20.0.0/16, fd00::/104")
+	assert.NoError(t, err)
+	assert.Equal(t, "172.20.0.0/16", cidrs[model.IPv4].String())
+	assert.Equal(t, "fd00::/104", cidrs[model.IPv6].String())
+
+	_, err = getClusterCIDRs("172.20.0.0/16,172.21.0.0/16")
+	assert.Error(t, err)
+	_, err = getClusterCIDRs("172.20.0.0")
+	assert.Error(t, err)
+}
+
+func TestRouteResults(t *testing.T) {
+	assert.False(t, routeResults{}.created())
+
+	results := routeResults{model.IPv4: true}
+	assert.True(t, results.created())
+	assert.Equal(t, "RouteController created a route", results.message())
+
+	results = routeResults{model.IPv4: true, model.IPv6: false}
+	assert.False(t, results.created())
+	assert.Equal(t, "RouteController created the ipv4 route, failed to create the ipv6 route", results.message())
+}
+
+func TestRouteResultsMerge(t *testing.T) {
+	results := routeResults{}
+	results.merge(routeResults{model.IPv4: true, model.IPv6: true})
+	results.merge(routeResults{model.IPv4: false, model.IPv6: true})
+	assert.Equal(t, routeResults{model.IPv4: false, model.IPv6: true}, results)
+
+	results.merge(routeResults{model.IPv4: true})
+	assert.False(t, results.created())
+}
@@ -0,0 +1,325 @@
+package albv3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/context/shared"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/applier"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/backend"
+	servicemanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/service_manager"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/store"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const albControllerName = "alb-service-controller"
+
+func Add(mgr manager.Manager, ctx *shared.SharedContext) error {
+	r, err := newReconciler(mgr, ctx)
+	if err != nil {
+		return fmt.Errorf("new alb service reconciler error: %s", err.Error())
+	}
+	return add(mgr, r)
+}
+
+func newReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*ReconcileALB, error) {
+	logger := ctrl.Log.WithName("controller").WithName(albControllerName)
+	r := &ReconcileALB{
+		cloud:            ctx.Provider(),
+		kubeClient:       mgr.GetClient(),
+		logger:           logger,
+		record:           mgr.GetEventRecorderFor(albControllerName),
+		finalizerManager: helper.NewDefaultFinalizerManager(mgr.GetClient()),
+		builder:          NewDefaultModelBuilder(ctx.Provider(), logger),
+		serverApplier:    applier.NewServiceManagerApplier(mgr.GetClient(), ctx.Provider(), logger),
+	}
+
+	// the backend manager resolves endpoints and pods through the ingress store
+	sharedStore, err := store.Shared(ctx, mgr)
+	if err != nil {
+		return nil, err
+	}
+	r.store = sharedStore
+	r.stackApplier = applier.NewAlbConfigManagerApplier(r.store, mgr.GetClient(), ctx.Provider(), util.ServiceALBTagKeyPrefix, logger)
+	r.serverBuilder = servicemanager.NewDefaultServiceStackBuilder(backend.NewBackendManager(r.store, mgr.GetClient(), ctx.Provider(), logger))
+	return r, nil
+}
+
+type albController struct {
+	c     controller.Controller
+	recon *ReconcileALB
+}
+
+func (a albController) Start(ctx context.Context) error {
+	if !a.recon.store.WaitForSync(ctx.Done()) {
+		return nil
+	}
+	return a.c.Start(ctx)
+}
+
+func add(mgr manager.Manager, r *ReconcileALB) error {
+	rateLimit := workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(5*time.Second, 300*time.Second),
+		// 10 qps, 100 bucket size.  This is only for retry speed and its only the overall factor (not per item)
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+	recoverPanic := true
+	c, err := controller.NewUnmanaged(
+		albControllerName, mgr,
+		controller.Options{
+			Reconciler:              r,
+			MaxConcurrentReconciles: ctrlCfg.CloudCFG.Global.ServiceMaxConcurrentReconciles,
+			RateLimiter:             rateLimit,
+			RecoverPanic:            &recoverPanic,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Watch(source.Kind(mgr.GetCache(), &v1.Service{}),
+		NewEnqueueRequestForServiceEvent(r.record)); err != nil {
+		return fmt.Errorf("watch resource svc error: %s", err.Error())
+	}
+	if err := c.Watch(source.Kind(mgr.GetCache(), &v1.Endpoints{}),
+		NewEnqueueRequestForEndpointEvent(mgr.GetClient())); err != nil {
+		return fmt.Errorf("watch resource endpoint error: %s", err.Error())
+	}
+	if utilfeature.DefaultFeatureGate.Enabled(ctrlCfg.LoadBalancerConfig) {
+		if err := c.Watch(source.Kind(mgr.GetCache(), &albv1.LoadBalancerConfig{}),
+			annotation.NewEnqueueRequestForConfigEvent(mgr.GetClient(), helper.NeedALB)); err != nil {
+			return fmt.Errorf("watch resource LoadBalancerConfig error: %s", err.Error())
+		}
+	}
+
+	return mgr.Add(&albController{c: c, recon: r})
+}
+
+var _ reconcile.Reconciler = &ReconcileALB{}
+
+// ReconcileALB programs one application load balancer for each Service of type LoadBalancer with the
+// loadBalancerClass helper.ALBClass. The ALB resources are applied by the same appliers as the
+// AlbConfig stacks, tagged with their own prefix.
+type ReconcileALB struct {
+	cloud      prvd.Provider
+	kubeClient client.Client
+	logger     logr.Logger
+
+	record           record.EventRecorder
+	finalizerManager helper.FinalizerManager
+
+	builder       Builder
+	stackApplier  applier.AlbConfigManagerApplier
+	serverBuilder servicemanager.Builder
+	serverApplier applier.ServiceManagerApplier
+	store         *store.SharedStore
+}
+
+func (m *ReconcileALB) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	return util.HandleReconcileResult(request, m.reconcile(ctx, request))
+}
+
+func (m *ReconcileALB) reconcile(c context.Context, request reconcile.Request) error {
+	startTime := time.Now()
+
+	reconcileID := controller.ReconcileIDFromContext(c)
+	albLog := util.ALBLog.WithValues("service", request.NamespacedName.String(), "reconcileID", reconcileID)
+	albLog.Info("starting reconcile service")
+
+	svc := &v1.Service{}
+	if err := m.kubeClient.Get(c, request.NamespacedName, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			m.logger.Info("service not found, skip", "service", request.NamespacedName)
+			return nil
+		}
+		return err
+	}
+
+	anno, err := annotation.NewAnnotationRequestWithConfig(c, m.kubeClient, svc)
+	if err != nil {
+		if helper.NeedALB(svc) {
+			m.record.Event(svc, v1.EventTypeWarning, helper.FailedSyncLB,
+				fmt.Sprintf("Error loading LoadBalancerConfig: %s", err.Error()))
+			return err
+		}
+		// the alb is cleaned up without the config
+		anno = annotation.NewAnnotationRequest(svc)
+	}
+	ctx := context.WithValue(context.Background(), util.TraceID, string(reconcileID))
+	reqCtx := &svcCtx.RequestContext{
+		Ctx:         ctx,
+		ReconcileID: string(reconcileID),
+		Service:     svc,
+		Anno:        anno,
+		Log:         albLog,
+		Recorder:    m.record,
+	}
+
+	if helper.NeedDeleteLoadBalancer(svc) || !helper.NeedALB(svc) {
+		err = m.cleanupLoadBalancerResources(reqCtx)
+	} else {
+		err = m.reconcileLoadBalancerResources(reqCtx)
+	}
+
+	var needRequeue *util.ReconcileNeedRequeue
+	if err != nil && !errors.As(err, &needRequeue) {
+		return err
+	}
+
+	reqCtx.Log.Info("successfully reconcile", "elapsedTime", time.Since(startTime).Seconds())
+	metric.SLBLatency.WithLabelValues(metric.ALBType, "reconcile").Observe(metric.MsSince(startTime))
+	if needRequeue != nil {
+		reqCtx.Log.Info("requeue needed", "reason", needRequeue.Error())
+		return needRequeue
+	}
+	return nil
+}
+
+func (m *ReconcileALB) cleanupLoadBalancerResources(reqCtx *svcCtx.RequestContext) error {
+	if !helper.HasFinalizer(reqCtx.Service, helper.ALBFinalizer) {
+		return nil
+	}
+	reqCtx.Log.Info("service do not need alb any more, try to delete it")
+	if _, err := m.buildAndApplyModel(reqCtx); err != nil {
+		m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedCleanLB,
+			fmt.Sprintf("Error deleting load balancer: %s", helper.GetLogMessage(err)))
+		return err
+	}
+
+	if err := m.updateServiceStatus(reqCtx, ""); err != nil {
+		m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedUpdateStatus,
+			fmt.Sprintf("Error removing load balancer status: %s", err.Error()))
+		return err
+	}
+
+	if err := m.finalizerManager.RemoveFinalizers(reqCtx.Ctx, reqCtx.Service, helper.ALBFinalizer); err != nil {
+		m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedRemoveFinalizer,
+			fmt.Sprintf("Error removing load balancer finalizer: %v", err.Error()))
+		return err
+	}
+	m.record.Event(reqCtx.Service, v1.EventTypeNormal, helper.SucceedCleanLB, "Clean load balancer")
+	return nil
+}
+
+func (m *ReconcileALB) reconcileLoadBalancerResources(reqCtx *svcCtx.RequestContext) error {
+	if err := m.finalizerManager.AddFinalizers(reqCtx.Ctx, reqCtx.Service, helper.ALBFinalizer); err != nil {
+		m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedAddFinalizer,
+			fmt.Sprintf("Error adding finalizer: %s", err.Error()))
+		return err
+	}
+
+	lb, err := m.buildAndApplyModel(reqCtx)
+	if err == nil {
+		err = m.syncServers(reqCtx)
+	}
+	var needRequeue *util.ReconcileNeedRequeue
+	if err != nil && !errors.As(err, &needRequeue) {
+		reason := helper.FailedSyncLB
+		if util.IsThrottlingError(err) {
+			reason = helper.ThrottledSyncLB
+		}
+		m.record.Event(reqCtx.Service, v1.EventTypeWarning, reason,
+			fmt.Sprintf("Error syncing load balancer: %s", helper.GetLogMessage(err)))
+		return err
+	}
+
+	if err := m.updateServiceStatus(reqCtx, lb.Status.DNSName); err != nil {
+		m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedUpdateStatus,
+			fmt.Sprintf("Error updating load balancer status: %s", err.Error()))
+		return err
+	}
+
+	m.record.Event(reqCtx.Service, v1.EventTypeNormal, helper.SucceedSyncLB,
+		fmt.Sprintf("Ensured load balancer [%s]", lb.Status.LoadBalancerID))
+	return err
+}
+
+func (m *ReconcileALB) buildAndApplyModel(reqCtx *svcCtx.RequestContext) (*albmodel.AlbLoadBalancer, error) {
+	stack, lb, err := m.builder.Build(reqCtx)
+	if err != nil {
+		return nil, fmt.Errorf("build alb model error: %w", err)
+	}
+	if err := m.stackApplier.Apply(reqCtx.Ctx, stack); err != nil {
+		return nil, fmt.Errorf("apply alb model error: %w", err)
+	}
+	if lb != nil && lb.Status == nil {
+		return nil, fmt.Errorf("alb of stack %s is not fulfilled", stack.StackID())
+	}
+	return lb, nil
+}
+
+// syncServers registers the endpoints of the service into the server groups of its ports,
+// the same way Service events do for Ingresses.
+func (m *ReconcileALB) syncServers(reqCtx *svcCtx.RequestContext) error {
+	svc := reqCtx.Service
+	portToKeys := make(map[int32][]string)
+	for _, port := range svc.Spec.Ports {
+		portToKeys[port.Port] = []string{ServerGroupKeyPrefix + svc.Name}
+	}
+	serverStack, err := m.serverBuilder.Build(reqCtx.Ctx, &albmodel.ServiceStackContext{
+		ClusterID:                 m.cloud.ClusterID(),
+		ServiceNamespace:          svc.Namespace,
+		ServiceName:               svc.Name,
+		Service:                   svc,
+		ServicePortToIngressNames: portToKeys,
+	})
+	if err != nil {
+		return fmt.Errorf("build service stack model error: %v", err)
+	}
+	if err := m.serverApplier.Apply(reqCtx.Ctx, m.cloud, serverStack); err != nil {
+		return err
+	}
+	if serverStack.ContainsPotentialReadyEndpoints {
+		return util.NewReconcileNeedRequeue("has potential ready backends")
+	}
+	return nil
+}
+
+// updateServiceStatus publishes the dns name of the alb in the status of the service,
+// the status is cleared if the dns name is empty.
+func (m *ReconcileALB) updateServiceStatus(reqCtx *svcCtx.RequestContext, dnsName string) error {
+	svc := reqCtx.Service
+	newStatus := &v1.LoadBalancerStatus{}
+	if dnsName != "" {
+		newStatus.Ingress = []v1.LoadBalancerIngress{{Hostname: dnsName}}
+	}
+	if v1helper.LoadBalancerStatusEqual(&svc.Status.LoadBalancer, newStatus) {
+		return nil
+	}
+	reqCtx.Log.Info(fmt.Sprintf("status: [%v] [%v]", svc.Status.LoadBalancer, newStatus))
+
+	latest := &v1.Service{}
+	if err := m.kubeClient.Get(reqCtx.Ctx, util.NamespacedName(svc), latest); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	updated := latest.DeepCopy()
+	updated.Status.LoadBalancer = *newStatus
+	if err := m.kubeClient.Status().Patch(reqCtx.Ctx, updated, client.MergeFrom(latest)); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	return nil
+}
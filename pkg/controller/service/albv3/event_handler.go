@@ -0,0 +1,169 @@
+package albv3
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func NewEnqueueRequestForServiceEvent(eventRecorder record.EventRecorder) *enqueueRequestForServiceEvent {
+	return &enqueueRequestForServiceEvent{eventRecorder: eventRecorder}
+}
+
+type enqueueRequestForServiceEvent struct {
+	eventRecorder record.EventRecorder
+}
+
+var _ handler.EventHandler = (*enqueueRequestForServiceEvent)(nil)
+
+func (h *enqueueRequestForServiceEvent) Create(_ context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	svc, ok := e.Object.(*v1.Service)
+	if ok && needAdd(svc) {
+		util.ALBLog.Info("controller: service create event", "service", util.Key(svc))
+		enqueueService(queue, svc)
+	}
+}
+
+func (h *enqueueRequestForServiceEvent) Update(_ context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldSvc, ok1 := e.ObjectOld.(*v1.Service)
+	newSvc, ok2 := e.ObjectNew.(*v1.Service)
+
+	if ok1 && ok2 && needUpdate(oldSvc, newSvc, h.eventRecorder) {
+		util.ALBLog.Info("controller: service update event", "service", util.Key(oldSvc))
+		enqueueService(queue, newSvc)
+	}
+}
+
+func (h *enqueueRequestForServiceEvent) Delete(_ context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	// Services have the finalizer. When a service is deleted, it will update the deletionTimestamp of the service.
+	// Since a delete event has changed to an update event, it is safe to ignore it.
+}
+
+func (h *enqueueRequestForServiceEvent) Generic(_ context.Context, e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+	// unknown type event, ignore
+}
+
+func enqueueService(queue workqueue.RateLimitingInterface, obj client.Object) {
+	queue.Add(reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		},
+	})
+	util.ALBLog.Info("enqueue", "service", util.Key(obj), "queueLen", queue.Len())
+}
+
+func needAdd(svc *v1.Service) bool {
+	return helper.NeedALB(svc) || helper.HasFinalizer(svc, helper.ALBFinalizer)
+}
+
+func needUpdate(oldSvc, newSvc *v1.Service, recorder record.EventRecorder) bool {
+	if !needAdd(oldSvc) && !needAdd(newSvc) {
+		return false
+	}
+
+	if helper.NeedALB(oldSvc) != helper.NeedALB(newSvc) {
+		util.ALBLog.Info(fmt.Sprintf("TypeChanged %v - %v", oldSvc.Spec.Type, newSvc.Spec.Type),
+			"service", util.Key(oldSvc))
+		recorder.Event(newSvc, v1.EventTypeNormal, helper.TypeChanged,
+			fmt.Sprintf("type change %v - %v", oldSvc.Spec.Type, newSvc.Spec.Type))
+		return true
+	}
+
+	if oldSvc.UID != newSvc.UID {
+		return true
+	}
+
+	if !reflect.DeepEqual(oldSvc.Annotations, newSvc.Annotations) {
+		recorder.Event(newSvc, v1.EventTypeNormal, helper.AnnoChanged,
+			"The service will be updated because the annotations has been changed.")
+		return true
+	}
+
+	if !reflect.DeepEqual(oldSvc.Spec, newSvc.Spec) {
+		recorder.Event(newSvc, v1.EventTypeNormal, helper.SpecChanged,
+			"The service will be updated because the spec has been changed.")
+		return true
+	}
+
+	if oldSvc.DeletionTimestamp.IsZero() != newSvc.DeletionTimestamp.IsZero() {
+		recorder.Event(newSvc, v1.EventTypeNormal, helper.DeleteTimestampChanged,
+			"The service will be updated because the delete timestamp has been changed.")
+		return true
+	}
+
+	return false
+}
+
+// NewEnqueueRequestForEndpointEvent enqueues the service of the endpoints, so that the servers of
+// the server groups follow the endpoints.
+func NewEnqueueRequestForEndpointEvent(client client.Client) *enqueueRequestForEndpointEvent {
+	return &enqueueRequestForEndpointEvent{client: client}
+}
+
+type enqueueRequestForEndpointEvent struct {
+	client client.Client
+}
+
+var _ handler.EventHandler = (*enqueueRequestForEndpointEvent)(nil)
+
+func (h *enqueueRequestForEndpointEvent) Create(_ context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	ep, ok := e.Object.(*v1.Endpoints)
+	if ok && isEndpointProcessNeeded(ep, h.client) {
+		enqueueService(queue, ep)
+	}
+}
+
+func (h *enqueueRequestForEndpointEvent) Update(_ context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	ep1, ok1 := e.ObjectOld.(*v1.Endpoints)
+	ep2, ok2 := e.ObjectNew.(*v1.Endpoints)
+
+	if ok1 && ok2 && !reflect.DeepEqual(ep1.Subsets, ep2.Subsets) && isEndpointProcessNeeded(ep2, h.client) {
+		util.ALBLog.Info(fmt.Sprintf("endpoints before [%s], after [%s]",
+			helper.LogEndpoints(ep1), helper.LogEndpoints(ep2)), "endpoint", util.Key(ep1))
+		enqueueService(queue, ep2)
+	}
+}
+
+func (h *enqueueRequestForEndpointEvent) Delete(_ context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	ep, ok := e.Object.(*v1.Endpoints)
+	if ok && isEndpointProcessNeeded(ep, h.client) {
+		enqueueService(queue, ep)
+	}
+}
+
+func (h *enqueueRequestForEndpointEvent) Generic(_ context.Context, e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+	// unknown event, ignore
+}
+
+func isEndpointProcessNeeded(ep *v1.Endpoints, client client.Client) bool {
+	if ep == nil {
+		return false
+	}
+	// skip eps which are used for leader election
+	if _, ok := ep.Annotations[resourcelock.LeaderElectionRecordAnnotationKey]; ok {
+		return false
+	}
+
+	svc := &v1.Service{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Namespace: ep.Namespace, Name: ep.Name}, svc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			util.ALBLog.Error(err, "fail to get service, skip reconcile endpoint", "service", util.Key(ep))
+		}
+		return false
+	}
+	return helper.NeedALB(svc)
+}
@@ -0,0 +1,405 @@
+package albv3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	albconfigmanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+const (
+	ApplicationLoadBalancerResource = "ApplicationLoadBalancer"
+
+	// ServerGroupKeyPrefix prefixes the name of the service in the ingress name of the server group keys,
+	// so that the server groups of the service never match the ones of an Ingress.
+	ServerGroupKeyPrefix = "service-"
+)
+
+type Builder interface {
+	Build(reqCtx *svcCtx.RequestContext) (core.Manager, *alb.AlbLoadBalancer, error)
+}
+
+var _ Builder = &defaultModelBuilder{}
+
+type defaultModelBuilder struct {
+	cloud  prvd.Provider
+	logger logr.Logger
+}
+
+func NewDefaultModelBuilder(cloud prvd.Provider, logger logr.Logger) *defaultModelBuilder {
+	return &defaultModelBuilder{
+		cloud:  cloud,
+		logger: logger,
+	}
+}
+
+// Build builds the stack of the application load balancer of the service, with one listener for each
+// port of the service forwarding to the server group of the port. The stack is empty if the service
+// does not need the load balancer any more, so that the resources are released by the appliers.
+func (b *defaultModelBuilder) Build(reqCtx *svcCtx.RequestContext) (core.Manager, *alb.AlbLoadBalancer, error) {
+	svc := reqCtx.Service
+	stack := core.NewDefaultManager(core.StackID(types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}))
+	if helper.NeedDeleteLoadBalancer(svc) || !helper.NeedALB(svc) {
+		return stack, nil, nil
+	}
+
+	vpcID, err := b.cloud.VpcID()
+	if err != nil {
+		return nil, nil, err
+	}
+	task := &defaultModelBuildTask{
+		stack:           stack,
+		service:         svc,
+		anno:            reqCtx.Anno,
+		clusterID:       b.cloud.ClusterID(),
+		vpcID:           vpcID,
+		vSwitchResolver: albconfigmanager.NewDefaultVSwitchResolver(b.cloud, vpcID, b.logger),
+	}
+	if err := task.run(reqCtx.Ctx); err != nil {
+		return nil, nil, err
+	}
+	return task.stack, task.loadBalancer, nil
+}
+
+type defaultModelBuildTask struct {
+	stack        core.Manager
+	loadBalancer *alb.AlbLoadBalancer
+	service      *v1.Service
+	anno         *annotation.AnnotationRequest
+
+	clusterID string
+	vpcID     string
+
+	vSwitchResolver albconfigmanager.VSwitchResolver
+}
+
+func (t *defaultModelBuildTask) run(ctx context.Context) error {
+	lb, err := t.buildAlbLoadBalancer(ctx)
+	if err != nil {
+		return err
+	}
+
+	ports := make(map[int32]bool)
+	for _, port := range t.service.Spec.Ports {
+		if port.Protocol != v1.ProtocolTCP {
+			return fmt.Errorf("port %d: protocol %s is not supported by alb", port.Port, port.Protocol)
+		}
+		if ports[port.Port] {
+			return fmt.Errorf("port %d is used by more than one listener", port.Port)
+		}
+		ports[port.Port] = true
+
+		protocols, err := listenerProtocols(t.anno.ForPort(port.Port).Get(annotation.ProtocolPort), port)
+		if err != nil {
+			return fmt.Errorf("build listener of port %d error: %w", port.Port, err)
+		}
+		sgp, err := t.buildServerGroup(ctx, port)
+		if err != nil {
+			return fmt.Errorf("build server group of port %d error: %w", port.Port, err)
+		}
+		for _, protocol := range protocols {
+			if _, err := t.buildListener(ctx, lb.LoadBalancerID(), port, protocol, sgp); err != nil {
+				return fmt.Errorf("build %s listener of port %d error: %w", protocol, port.Port, err)
+			}
+		}
+	}
+	return nil
+}
+
+var invalidLoadBalancerNamePattern = regexp.MustCompile("[[:^alnum:]]")
+
+func (t *defaultModelBuildTask) buildAlbLoadBalancerName() string {
+	uuidHash := sha256.New()
+	_, _ = uuidHash.Write([]byte(t.clusterID))
+	_, _ = uuidHash.Write([]byte(util.Key(t.service)))
+	uuid := hex.EncodeToString(uuidHash.Sum(nil))
+
+	sanitizedNamespace := invalidLoadBalancerNamePattern.ReplaceAllString(t.service.Namespace, "")
+	sanitizedName := invalidLoadBalancerNamePattern.ReplaceAllString(t.service.Name, "")
+	return fmt.Sprintf("k8s-svc-%s-%s-%.10s", sanitizedNamespace, sanitizedName, uuid)
+}
+
+func (t *defaultModelBuildTask) buildAlbLoadBalancer(ctx context.Context) (*alb.AlbLoadBalancer, error) {
+	lbModel := alb.ALBLoadBalancerSpec{}
+	lbModel.LoadBalancerId = t.anno.Get(annotation.LoadBalancerId)
+	forceOverride := t.anno.Get(annotation.OverrideListener) == "true"
+	lbModel.ForceOverride = &forceOverride
+	lbModel.LoadBalancerName = t.anno.Get(annotation.LoadBalancerName)
+	if lbModel.LoadBalancerName == "" {
+		lbModel.LoadBalancerName = t.buildAlbLoadBalancerName()
+	}
+	lbModel.VpcId = t.vpcID
+	lbModel.ResourceGroupId = t.anno.Get(annotation.ResourceGroupId)
+
+	switch strings.ToLower(t.anno.Get(annotation.AddressType)) {
+	case "", strings.ToLower(util.LoadBalancerAddressTypeInternet):
+		lbModel.AddressType = util.LoadBalancerAddressTypeInternet
+	case strings.ToLower(util.LoadBalancerAddressTypeIntranet):
+		lbModel.AddressType = util.LoadBalancerAddressTypeIntranet
+	default:
+		return nil, fmt.Errorf("address type must be internet or intranet, got %s", t.anno.Get(annotation.AddressType))
+	}
+	lbModel.AddressAllocatedMode = util.DefaultLoadBalancerAddressAllocatedMode
+	lbModel.LoadBalancerEdition = util.LoadBalancerEditionStandard
+	lbModel.LoadBalancerBillingConfig = alb.LoadBalancerBillingConfig{
+		PayType: util.DefaultLoadBalancerBillingConfigPayType,
+	}
+	lbModel.DeletionProtectionConfig = alb.DeletionProtectionConfig{
+		Enabled: t.anno.Get(annotation.DeleteProtection) != string(alb.OffFlag),
+	}
+	lbModel.ModificationProtectionConfig = alb.ModificationProtectionConfig{
+		Status: util.DefaultLoadBalancerModificationProtectionConfigStatus,
+	}
+
+	if lbModel.LoadBalancerId == "" {
+		zoneMappings, err := t.buildZoneMappings(ctx)
+		if err != nil {
+			return nil, err
+		}
+		lbModel.ZoneMapping = zoneMappings
+	}
+
+	lb := alb.NewAlbLoadBalancer(t.stack, ApplicationLoadBalancerResource, lbModel)
+	t.loadBalancer = lb
+	return lb, nil
+}
+
+// buildZoneMappings reads the zone mappings from the zone-maps annotation in the format of
+// zone-a:vsw-1,zone-b:vsw-2, and discovers the vswitches of the cluster if it is not set.
+func (t *defaultModelBuildTask) buildZoneMappings(ctx context.Context) ([]alb.ZoneMapping, error) {
+	var zoneMappings []alb.ZoneMapping
+	if v := t.anno.Get(annotation.ZoneMaps); v != "" {
+		for _, attr := range strings.Split(v, ",") {
+			items := strings.Split(attr, ":")
+			if len(items) != 2 || items[0] == "" || items[1] == "" {
+				return nil, fmt.Errorf("ZoneMapping format error, expect [zone-a:vsw-id-1,zone-b:vsw-id-2], got %s", v)
+			}
+			zoneMappings = append(zoneMappings, alb.ZoneMapping{ZoneId: items[0], VSwitchId: items[1]})
+		}
+	} else {
+		vSwitches, err := t.vSwitchResolver.ResolveViaDiscovery(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, vsw := range vSwitches {
+			zoneMappings = append(zoneMappings, alb.ZoneMapping{VSwitchId: vsw.VSwitchId, ZoneId: vsw.ZoneId})
+		}
+	}
+	if len(zoneMappings) < 2 {
+		return nil, errors.New("alb needs vswitches in at least two zones")
+	}
+	return zoneMappings, nil
+}
+
+func (t *defaultModelBuildTask) buildListener(_ context.Context, lbID core.StringToken, port v1.ServicePort, protocol string,
+	sgp *alb.ServerGroup) (*alb.Listener, error) {
+	anno := t.anno.ForPort(port.Port)
+	var err error
+	spec := alb.ListenerSpec{LoadBalancerID: lbID}
+	spec.ListenerPort = int(port.Port)
+	spec.ListenerProtocol = protocol
+	spec.ListenerDescription = fmt.Sprintf("%s-%v", util.Key(t.service), port.Port)
+	spec.DefaultActions = []alb.Action{{
+		Type: util.RuleActionTypeForward,
+		ForwardConfig: &alb.ForwardActionConfig{
+			ServerGroups: []alb.ServerGroupTuple{{ServerGroupID: sgp.ServerGroupID()}},
+		},
+	}}
+	spec.IdleTimeout, err = intWithDefault(anno.Get(annotation.IdleTimeout), util.DefaultListenerIdleTimeout)
+	if err != nil {
+		return nil, err
+	}
+	spec.RequestTimeout, err = intWithDefault(anno.Get(annotation.RequestTimeout), util.DefaultListenerRequestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	spec.GzipEnabled = util.DefaultListenerGzipEnabled
+
+	if protocol == util.ListenerProtocolHTTPS || protocol == util.ListenerProtocolQUIC {
+		var certIDs []string
+		for _, id := range strings.Split(anno.Get(annotation.CertID), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				certIDs = append(certIDs, id)
+			}
+		}
+		if len(certIDs) == 0 {
+			return nil, fmt.Errorf("annotation %s is required by %s listener", annotation.Annotation(annotation.CertID), protocol)
+		}
+		for i, id := range certIDs {
+			spec.Certificates = append(spec.Certificates, alb.Certificate{CertificateId: id, IsDefault: i == 0})
+		}
+		spec.SecurityPolicyId = util.DefaultListenerSecurityPolicyId
+		if v := anno.Get(annotation.TLSCipherPolicy); v != "" {
+			spec.SecurityPolicyId = v
+		}
+	}
+	if protocol == util.ListenerProtocolHTTPS {
+		spec.Http2Enabled = anno.Get(annotation.EnableHttp2) != string(alb.OffFlag)
+	}
+	return alb.NewListener(t.stack, fmt.Sprintf("%v-%s", port.Port, strings.ToLower(protocol)), spec), nil
+}
+
+// listenerProtocols returns the protocols of the listeners of the port from the protocol-port annotation,
+// e.g. https:443,quic:443,http:80, which is HTTP by default. Only a https listener and a quic listener
+// can share a port.
+func listenerProtocols(protocolPort string, port v1.ServicePort) ([]string, error) {
+	if protocolPort == "" {
+		return []string{util.ListenerProtocolHTTP}, nil
+	}
+	var protocols []string
+	seen := sets.NewString()
+	for _, v := range strings.Split(protocolPort, ",") {
+		pp := strings.Split(v, ":")
+		if len(pp) < 2 {
+			return nil, fmt.Errorf("port and protocol format must be like 'https:443' with colon separated. got=[%+v]", pp)
+		}
+		proto := strings.ToUpper(pp[0])
+		if proto != util.ListenerProtocolHTTP && proto != util.ListenerProtocolHTTPS && proto != util.ListenerProtocolQUIC {
+			return nil, fmt.Errorf("port protocol format must be either [HTTP|HTTPS|QUIC], protocol not supported with [%s]", pp[0])
+		}
+		if pp[1] == fmt.Sprintf("%d", port.Port) && !seen.Has(proto) {
+			seen.Insert(proto)
+			protocols = append(protocols, proto)
+		}
+	}
+	switch {
+	case len(protocols) == 0:
+		return []string{util.ListenerProtocolHTTP}, nil
+	case len(protocols) > 1 && !seen.Equal(sets.NewString(util.ListenerProtocolHTTPS, util.ListenerProtocolQUIC)):
+		return nil, fmt.Errorf("only a HTTPS listener and a QUIC listener can share port %d, got %v", port.Port, protocols)
+	}
+	return protocols, nil
+}
+
+func (t *defaultModelBuildTask) buildServerGroupResourceID(port int32) string {
+	resourceID := fmt.Sprintf("%s/%s%s:%v", t.service.Namespace, ServerGroupKeyPrefix, t.service.Name, port)
+	uuidHash := sha256.New()
+	_, _ = uuidHash.Write([]byte(resourceID))
+	return hex.EncodeToString(uuidHash.Sum(nil))
+}
+
+func (t *defaultModelBuildTask) buildServerGroup(_ context.Context, port v1.ServicePort) (*alb.ServerGroup, error) {
+	anno := t.anno.ForPort(port.Port)
+	ingressName := ServerGroupKeyPrefix + t.service.Name
+
+	var spec alb.ServerGroupSpec
+	spec.ServerGroupNamedKey = alb.ServerGroupNamedKey{
+		ClusterID:   t.clusterID,
+		Namespace:   t.service.Namespace,
+		IngressName: ingressName,
+		ServiceName: t.service.Name,
+		ServicePort: int(port.Port),
+	}
+	spec.Tags = []alb.ALBTag{
+		{Key: util.ServiceNamespaceTagKey, Value: t.service.Namespace},
+		{Key: util.IngressNameTagKey, Value: ingressName},
+		{Key: util.ServiceNameTagKey, Value: t.service.Name},
+		{Key: util.ServicePortTagKey, Value: fmt.Sprintf("%v", port.Port)},
+	}
+	spec.ServerGroupName = fmt.Sprintf("%s-%s-%v", t.service.Namespace, t.service.Name, port.Port)
+	spec.Protocol = util.DefaultServerGroupProtocol
+	spec.ServerGroupType = util.DefaultServerGroupType
+	spec.VpcId = t.vpcID
+	spec.ResourceGroupId = anno.Get(annotation.ResourceGroupId)
+
+	switch strings.ToLower(anno.Get(annotation.Scheduler)) {
+	case "", strings.ToLower(util.ServerGroupSchedulerWrr):
+		spec.Scheduler = util.ServerGroupSchedulerWrr
+	case strings.ToLower(util.ServerGroupSchedulerWlc):
+		spec.Scheduler = util.ServerGroupSchedulerWlc
+	case strings.ToLower(util.ServerGroupSchedulerSch):
+		spec.Scheduler = util.ServerGroupSchedulerSch
+	default:
+		return nil, fmt.Errorf("scheduler must be one of [wrr, wlc, sch], got %s", anno.Get(annotation.Scheduler))
+	}
+
+	healthCheck := alb.HealthCheckConfig{
+		HealthCheckConnectPort: util.DefaultServerGroupHealthCheckConnectPort,
+		HealthCheckEnabled:     anno.Get(annotation.HealthCheckFlag) == string(alb.OnFlag),
+		HealthCheckHost:        util.DefaultServerGroupHealthCheckHost,
+		HealthCheckHttpVersion: util.DefaultServerGroupHealthCheckHttpVersion,
+		HealthCheckMethod:      util.DefaultServerGroupHealthCheckMethod,
+		HealthCheckPath:        util.DefaultServerGroupHealthCheckPath,
+		HealthCheckProtocol:    util.DefaultServerGroupHealthCheckProtocol,
+		HealthCheckHttpCodes:   []string{util.DefaultServerGroupHealthCheckHTTPCodes},
+		HealthCheckCodes:       []string{util.DefaultServerGroupHealthCheckCodes},
+	}
+	if v := anno.Get(annotation.HealthCheckURI); v != "" {
+		healthCheck.HealthCheckPath = v
+	}
+	if v := anno.Get(annotation.HealthCheckDomain); v != "" {
+		healthCheck.HealthCheckHost = v
+	}
+	if v := anno.Get(annotation.HealthCheckMethod); v != "" {
+		healthCheck.HealthCheckMethod = strings.ToUpper(v)
+	}
+	if v := anno.Get(annotation.HealthCheckHTTPCode); v != "" {
+		healthCheck.HealthCheckHttpCodes = strings.Split(v, ",")
+		healthCheck.HealthCheckCodes = healthCheck.HealthCheckHttpCodes
+	}
+	var err error
+	for _, c := range []struct {
+		key    string
+		target *int
+		def    int
+	}{
+		{annotation.HealthCheckInterval, &healthCheck.HealthCheckInterval, util.DefaultServerGroupHealthCheckInterval},
+		{annotation.HealthCheckTimeout, &healthCheck.HealthCheckTimeout, util.DefaultServerGroupHealthCheckTimeout},
+		{annotation.HealthyThreshold, &healthCheck.HealthyThreshold, util.DefaultServerGroupHealthyThreshold},
+		{annotation.UnhealthyThreshold, &healthCheck.UnhealthyThreshold, util.DefaultServerGroupUnhealthyThreshold},
+	} {
+		if *c.target, err = intWithDefault(anno.Get(c.key), c.def); err != nil {
+			return nil, err
+		}
+	}
+	if err := anno.ValidateIntRanges(nil); err != nil {
+		return nil, err
+	}
+	spec.HealthCheckConfig = healthCheck
+
+	spec.StickySessionConfig = alb.StickySessionConfig{
+		CookieTimeout:        util.DefaultServerGroupStickySessionCookieTimeout,
+		StickySessionEnabled: anno.Get(annotation.SessionStick) == string(alb.OnFlag),
+		StickySessionType:    util.DefaultServerGroupStickySessionType,
+	}
+	if spec.StickySessionConfig.StickySessionEnabled {
+		if strings.EqualFold(anno.Get(annotation.SessionStickType), util.ServerGroupStickySessionTypeServer) {
+			spec.StickySessionConfig.StickySessionType = util.ServerGroupStickySessionTypeServer
+			spec.StickySessionConfig.Cookie = anno.Get(annotation.Cookie)
+		}
+		if spec.StickySessionConfig.CookieTimeout, err = intWithDefault(anno.Get(annotation.CookieTimeout),
+			util.DefaultServerGroupStickySessionCookieTimeout); err != nil {
+			return nil, err
+		}
+	}
+
+	return alb.NewServerGroup(t.stack, t.buildServerGroupResourceID(port.Port), spec), nil
+}
+
+func intWithDefault(v string, def int) (int, error) {
+	if v == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not an integer", v)
+	}
+	return i, nil
+}
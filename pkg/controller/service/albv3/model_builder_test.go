@@ -0,0 +1,192 @@
+package albv3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	vmock "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/vmock"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func getMockCloudProvider() prvd.Provider {
+	return vmock.MockCloud{
+		MockVPC:   vmock.NewMockVPC(nil),
+		IMetaData: vmock.NewMockMetaData("vpc-id"),
+	}
+}
+
+func getALBService() *v1.Service {
+	class := helper.ALBClass
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: v1.NamespaceDefault,
+			UID:       "uid",
+			Annotations: map[string]string{
+				annotation.Annotation(annotation.ZoneMaps):     "cn-hangzhou-k:vsw-k,cn-hangzhou-j:vsw-j",
+				annotation.Annotation(annotation.ProtocolPort): "http:80,https:443",
+				annotation.Annotation(annotation.CertID):       "cert-1,cert-2",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Type:              v1.ServiceTypeLoadBalancer,
+			LoadBalancerClass: &class,
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080), Protocol: v1.ProtocolTCP},
+				{Name: "https", Port: 443, TargetPort: intstr.FromInt(8080), Protocol: v1.ProtocolTCP},
+			},
+		},
+	}
+}
+
+func getReqCtx(svc *v1.Service) *svcCtx.RequestContext {
+	return &svcCtx.RequestContext{
+		Ctx:     context.TODO(),
+		Service: svc,
+		Anno:    annotation.NewAnnotationRequest(svc),
+		Log:     util.ALBLog.WithValues("service", util.Key(svc)),
+	}
+}
+
+func TestBuild(t *testing.T) {
+	builder := NewDefaultModelBuilder(getMockCloudProvider(), util.ALBLog)
+	stack, lb, err := builder.Build(getReqCtx(getALBService()))
+	assert.NoError(t, err)
+	assert.NotNil(t, lb)
+	assert.Equal(t, util.LoadBalancerAddressTypeInternet, lb.Spec.AddressType)
+	assert.Len(t, lb.Spec.ZoneMapping, 2)
+	assert.Contains(t, lb.Spec.LoadBalancerName, "k8s-svc-default-web-")
+
+	var listeners []*alb.Listener
+	assert.NoError(t, stack.ListResources(&listeners))
+	assert.Len(t, listeners, 2)
+	for _, ls := range listeners {
+		switch ls.Spec.ListenerPort {
+		case 80:
+			assert.Equal(t, util.ListenerProtocolHTTP, ls.Spec.ListenerProtocol)
+			assert.Empty(t, ls.Spec.Certificates)
+		case 443:
+			assert.Equal(t, util.ListenerProtocolHTTPS, ls.Spec.ListenerProtocol)
+			assert.Equal(t, []alb.Certificate{
+				{CertificateId: "cert-1", IsDefault: true},
+				{CertificateId: "cert-2"},
+			}, ls.Spec.Certificates)
+			assert.True(t, ls.Spec.Http2Enabled)
+		default:
+			t.Errorf("unexpected listener port %d", ls.Spec.ListenerPort)
+		}
+	}
+
+	var sgps []*alb.ServerGroup
+	assert.NoError(t, stack.ListResources(&sgps))
+	assert.Len(t, sgps, 2)
+	for _, sgp := range sgps {
+		assert.Equal(t, ServerGroupKeyPrefix+"web", sgp.Spec.ServerGroupNamedKey.IngressName)
+	}
+}
+
+func TestBuildEmptyStack(t *testing.T) {
+	builder := NewDefaultModelBuilder(getMockCloudProvider(), util.ALBLog)
+
+	svc := getALBService()
+	svc.Spec.LoadBalancerClass = nil
+	stack, lb, err := builder.Build(getReqCtx(svc))
+	assert.NoError(t, err)
+	assert.Nil(t, lb)
+	var listeners []*alb.Listener
+	assert.NoError(t, stack.ListResources(&listeners))
+	assert.Empty(t, listeners)
+
+	svc = getALBService()
+	now := metav1.Now()
+	svc.DeletionTimestamp = &now
+	_, lb, err = builder.Build(getReqCtx(svc))
+	assert.NoError(t, err)
+	assert.Nil(t, lb)
+}
+
+func TestBuildError(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(svc *v1.Service)
+	}{
+		{name: "udp port", mutate: func(svc *v1.Service) { svc.Spec.Ports[0].Protocol = v1.ProtocolUDP }},
+		{name: "https without cert", mutate: func(svc *v1.Service) {
+			delete(svc.Annotations, annotation.Annotation(annotation.CertID))
+		}},
+		{name: "single zone", mutate: func(svc *v1.Service) {
+			svc.Annotations[annotation.Annotation(annotation.ZoneMaps)] = "cn-hangzhou-k:vsw-k"
+		}},
+		{name: "invalid address type", mutate: func(svc *v1.Service) {
+			svc.Annotations[annotation.Annotation(annotation.AddressType)] = "vpc"
+		}},
+		{name: "invalid scheduler", mutate: func(svc *v1.Service) {
+			svc.Annotations[annotation.Annotation(annotation.Scheduler)] = "rr"
+		}},
+	}
+	builder := NewDefaultModelBuilder(getMockCloudProvider(), util.ALBLog)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc := getALBService()
+			c.mutate(svc)
+			_, _, err := builder.Build(getReqCtx(svc))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestListenerProtocols(t *testing.T) {
+	port := v1.ServicePort{Port: 443}
+	protocols, err := listenerProtocols("", port)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{util.ListenerProtocolHTTP}, protocols)
+
+	protocols, err = listenerProtocols("http:80,quic:443", port)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{util.ListenerProtocolQUIC}, protocols)
+
+	protocols, err = listenerProtocols("https:443,quic:443,http:80", port)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{util.ListenerProtocolHTTPS, util.ListenerProtocolQUIC}, protocols)
+
+	_, err = listenerProtocols("http:443,https:443", port)
+	assert.Error(t, err)
+	_, err = listenerProtocols("tcp:443", port)
+	assert.Error(t, err)
+	_, err = listenerProtocols("443", port)
+	assert.Error(t, err)
+}
+
+func TestBuildSharedPort(t *testing.T) {
+	svc := getALBService()
+	svc.Annotations[annotation.Annotation(annotation.ProtocolPort)] = "http:80,https:443,quic:443"
+	builder := NewDefaultModelBuilder(getMockCloudProvider(), util.ALBLog)
+	stack, _, err := builder.Build(getReqCtx(svc))
+	assert.NoError(t, err)
+
+	var listeners []*alb.Listener
+	assert.NoError(t, stack.ListResources(&listeners))
+	assert.Len(t, listeners, 3)
+	protocols := map[string]int{}
+	for _, ls := range listeners {
+		protocols[ls.Spec.ListenerProtocol] = ls.Spec.ListenerPort
+	}
+	assert.Equal(t, map[string]int{
+		util.ListenerProtocolHTTP:  80,
+		util.ListenerProtocolHTTPS: 443,
+		util.ListenerProtocolQUIC:  443,
+	}, protocols)
+
+	var sgps []*alb.ServerGroup
+	assert.NoError(t, stack.ListResources(&sgps))
+	assert.Len(t, sgps, 2)
+}
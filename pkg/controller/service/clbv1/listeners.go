@@ -6,8 +6,11 @@ import (
 	"github.com/alibabacloud-go/tea/tea"
 	"github.com/mohae/deepcopy"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/acl"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/certificate"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"time"
 
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
@@ -17,6 +20,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 
@@ -48,14 +52,18 @@ type DeleteAction struct {
 	listener model.ListenerAttribute
 }
 
-func NewListenerManager(cloud prvd.Provider) *ListenerManager {
+func NewListenerManager(kubeClient client.Client, cloud prvd.Provider) *ListenerManager {
 	return &ListenerManager{
-		cloud: cloud,
+		cloud:   cloud,
+		certMgr: certificate.NewManager(kubeClient, certificate.NewServerCertStore(cloud)),
+		aclMgr:  acl.NewCLBManager(cloud),
 	}
 }
 
 type ListenerManager struct {
-	cloud prvd.Provider
+	cloud   prvd.Provider
+	certMgr *certificate.Manager
+	aclMgr  *acl.CLBManager
 }
 
 func (mgr *ListenerManager) Create(reqCtx *svcCtx.RequestContext, action CreateAction) error {
@@ -100,26 +108,162 @@ func (mgr *ListenerManager) Describe(reqCtx *svcCtx.RequestContext, lbId string)
 }
 
 func (mgr *ListenerManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
+	aclType, cidrs, err := acl.GetCLBCidrs(reqCtx.Anno, mdl.LoadBalancerAttribute.AddressIPVersion)
+	if err != nil {
+		return err
+	}
+	mdl.LoadBalancerAttribute.AclCidrs = cidrs
 	for _, port := range reqCtx.Service.Spec.Ports {
 		listener, err := mgr.buildListenerFromServicePort(reqCtx, port, mdl.LoadBalancerAttribute.IsUserManaged)
 		if err != nil {
-			return fmt.Errorf("build listener from servicePort %d error: %s", port.Port, err.Error())
+			return fmt.Errorf("build listener from servicePort %d error: %w", port.Port, err)
+		}
+		// the acl id is set once the acl is synced by the applier
+		if len(cidrs) != 0 {
+			listener.AclStatus = model.OnFlag
+			listener.AclType = aclType
 		}
 		mdl.Listeners = append(mdl.Listeners, listener)
 	}
 	return nil
 }
 
+// applyAcl keeps the entries of the acl created for the cidrs of the local model, and binds it to the listeners.
+func (mgr *ListenerManager) applyAcl(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
+	if len(mdl.LoadBalancerAttribute.AclCidrs) == 0 {
+		return nil
+	}
+	aclId, err := mgr.aclMgr.Sync(reqCtx, mdl.LoadBalancerAttribute.AclCidrs, mdl.LoadBalancerAttribute.AddressIPVersion)
+	if err != nil {
+		return err
+	}
+	for i := range mdl.Listeners {
+		mdl.Listeners[i].AclId = aclId
+	}
+	return nil
+}
+
+// unbindStaleAcls turns off the acl of the listeners which are bound to the acls created for the cidrs the
+// service no longer declares, and returns the acls to delete once the listeners are applied.
+// The cidrs are removed by changing the service, so the acls are only listed when the service hash changed
+// and a listener keeps an acl that the local model does not set.
+func (mgr *ListenerManager) unbindStaleAcls(reqCtx *svcCtx.RequestContext, local, remote *model.LoadBalancer) ([]string, error) {
+	if acl.Declared(reqCtx.Anno) || !helper.IsServiceHashChanged(reqCtx.Service, reqCtx.Anno.Config()) {
+		return nil, nil
+	}
+	bound := false
+	for _, l := range local.Listeners {
+		for _, r := range remote.Listeners {
+			if r.ListenerPort == l.ListenerPort && r.AclStatus == model.OnFlag && l.AclStatus == "" {
+				bound = true
+			}
+		}
+	}
+	if !bound {
+		return nil, nil
+	}
+	acls, err := mgr.aclMgr.List(reqCtx)
+	if err != nil || len(acls) == 0 {
+		return nil, err
+	}
+	staleIds := sets.NewString()
+	for _, a := range acls {
+		staleIds.Insert(a.AclId)
+	}
+	for i := range local.Listeners {
+		l := &local.Listeners[i]
+		// the acl set by the annotations replaces the stale one
+		if l.AclStatus != "" {
+			continue
+		}
+		for _, r := range remote.Listeners {
+			if r.ListenerPort == l.ListenerPort && r.AclStatus == model.OnFlag && staleIds.Has(r.AclId) {
+				l.AclStatus = model.OffFlag
+			}
+		}
+	}
+	return staleIds.List(), nil
+}
+
+// GarbageCollectAcls deletes the acls created for the service, it is called when the service is deleted.
+// The listeners of the load balancer which is kept, as it is reused or preserved, are unbound from them first.
+func (mgr *ListenerManager) GarbageCollectAcls(reqCtx *svcCtx.RequestContext, remote *model.LoadBalancer) error {
+	acls, err := mgr.aclMgr.List(reqCtx)
+	if err != nil || len(acls) == 0 {
+		return err
+	}
+	ids := sets.NewString()
+	for _, a := range acls {
+		ids.Insert(a.AclId)
+	}
+	if remote != nil && remote.LoadBalancerAttribute.LoadBalancerId != "" {
+		lbId := remote.LoadBalancerAttribute.LoadBalancerId
+		listeners, err := mgr.Describe(reqCtx, lbId)
+		if err != nil {
+			return fmt.Errorf("DescribeLoadBalancerListeners error:%w", err)
+		}
+		for _, l := range listeners {
+			if l.AclStatus != model.OnFlag || !ids.Has(l.AclId) {
+				continue
+			}
+			local := deepcopy.Copy(l).(model.ListenerAttribute)
+			local.AclStatus = model.OffFlag
+			reqCtx.Log.Info(fmt.Sprintf("unbind acl %s from listener %d", l.AclId, l.ListenerPort))
+			if err := mgr.Update(reqCtx, UpdateAction{lbId: lbId, local: local, remote: l}); err != nil {
+				return fmt.Errorf("unbind acl %s from listener %d error: %w", l.AclId, l.ListenerPort, err)
+			}
+		}
+	}
+	return mgr.aclMgr.Delete(reqCtx, ids.List())
+}
+
+// applySecretCert uploads the tls secret of the cert-secret annotation as a server certificate,
+// and sets it to the https listeners of the local model.
+func (mgr *ListenerManager) applySecretCert(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
+	secretName := reqCtx.Anno.Get(annotation.CertSecret)
+	if secretName == "" || needDeleteLoadBalancer(reqCtx.Service) {
+		return nil
+	}
+	certId, err := mgr.certMgr.Sync(reqCtx.Ctx, reqCtx.Service, secretName)
+	if err != nil {
+		return err
+	}
+	for i := range mdl.Listeners {
+		if strings.ToLower(mdl.Listeners[i].Protocol) == model.HTTPS {
+			mdl.Listeners[i].CertId = certId
+		}
+	}
+	return nil
+}
+
+// GarbageCollectSecretCerts deletes the server certificates uploaded for the service which
+// the listeners no longer use. All of them are deleted when the service is deleted or the
+// cert-secret annotation is removed.
+func (mgr *ListenerManager) GarbageCollectSecretCerts(reqCtx *svcCtx.RequestContext) error {
+	secretName := reqCtx.Anno.Get(annotation.CertSecret)
+	// removing the annotation changes the service hash, skip listing the certificates otherwise
+	if secretName == "" && !needDeleteLoadBalancer(reqCtx.Service) &&
+		!helper.IsServiceHashChanged(reqCtx.Service, reqCtx.Anno.Config()) {
+		return nil
+	}
+	if needDeleteLoadBalancer(reqCtx.Service) {
+		secretName = ""
+	}
+	return mgr.certMgr.GarbageCollect(reqCtx.Ctx, reqCtx.Service, secretName)
+}
+
 func (mgr *ListenerManager) BuildRemoteModel(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
 	listeners, err := mgr.Describe(reqCtx, mdl.LoadBalancerAttribute.LoadBalancerId)
 	if err != nil {
-		return fmt.Errorf("DescribeLoadBalancerListeners error:%s", err.Error())
+		return fmt.Errorf("DescribeLoadBalancerListeners error:%w", err)
 	}
 	mdl.Listeners = listeners
 	return nil
 }
 
 func (mgr *ListenerManager) buildListenerFromServicePort(reqCtx *svcCtx.RequestContext, port v1.ServicePort, isUserManagedLB bool) (model.ListenerAttribute, error) {
+	// the listener attributes may be overridden for the port by the LoadBalancerConfig
+	anno := reqCtx.Anno.ForPort(port.Port)
 	listener := model.ListenerAttribute{
 		NamedKey: &model.ListenerNamedKey{
 			Prefix:      model.DEFAULT_PREFIX,
@@ -134,58 +278,58 @@ func (mgr *ListenerManager) buildListenerFromServicePort(reqCtx *svcCtx.RequestC
 	listener.Description = listener.NamedKey.Key()
 	listener.VGroupName = getVGroupNamedKey(reqCtx.Service, port).Key()
 
-	proto, err := protocol(reqCtx.Anno.Get(annotation.ProtocolPort), port)
+	proto, err := protocol(anno.Get(annotation.ProtocolPort), port)
 	if err != nil {
 		return listener, err
 	}
 	listener.Protocol = proto
 
-	if isUserManagedLB && reqCtx.Anno.Get(annotation.VGroupPort) != "" {
-		vGroupId, err := vgroup(reqCtx.Anno.Get(annotation.VGroupPort), port)
+	if isUserManagedLB && anno.Get(annotation.VGroupPort) != "" {
+		vGroupId, err := vgroup(anno.Get(annotation.VGroupPort), port)
 		if err != nil {
 			return listener, err
 		}
 		listener.VGroupId = vGroupId
 	}
 
-	if reqCtx.Anno.Get(annotation.Scheduler) != "" {
-		listener.Scheduler = reqCtx.Anno.Get(annotation.Scheduler)
+	if anno.Get(annotation.Scheduler) != "" {
+		listener.Scheduler = anno.Get(annotation.Scheduler)
 	}
 
-	if reqCtx.Anno.Get(annotation.PersistenceTimeout) != "" {
-		timeout, err := strconv.Atoi(reqCtx.Anno.Get(annotation.PersistenceTimeout))
+	if anno.Get(annotation.PersistenceTimeout) != "" {
+		timeout, err := strconv.Atoi(anno.Get(annotation.PersistenceTimeout))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation persistence timeout must be integer, but got [%s]. message=[%s] ",
-				reqCtx.Anno.Get(annotation.PersistenceTimeout), err.Error())
+				anno.Get(annotation.PersistenceTimeout), err.Error())
 		}
 		listener.PersistenceTimeout = &timeout
 	}
 
-	if reqCtx.Anno.Get(annotation.EstablishedTimeout) != "" {
-		establishedTimeout, err := strconv.Atoi(reqCtx.Anno.Get(annotation.EstablishedTimeout))
+	if anno.Get(annotation.EstablishedTimeout) != "" {
+		establishedTimeout, err := strconv.Atoi(anno.Get(annotation.EstablishedTimeout))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation EstablishedTimeout must be integer, but got [%s]. message=[%s] ",
-				reqCtx.Anno.Get(annotation.EstablishedTimeout), err.Error())
+				anno.Get(annotation.EstablishedTimeout), err.Error())
 		}
 		listener.EstablishedTimeout = establishedTimeout
 	}
 
-	listener.CertId = reqCtx.Anno.Get(annotation.CertID)
+	listener.CertId = anno.Get(annotation.CertID)
 
-	if reqCtx.Anno.Get(annotation.TLSCipherPolicy) != "" {
-		listener.TLSCipherPolicy = reqCtx.Anno.Get(annotation.TLSCipherPolicy)
+	if anno.Get(annotation.TLSCipherPolicy) != "" {
+		listener.TLSCipherPolicy = anno.Get(annotation.TLSCipherPolicy)
 	}
 
-	if reqCtx.Anno.Get(annotation.EnableHttp2) != "" {
-		listener.EnableHttp2 = model.FlagType(reqCtx.Anno.Get(annotation.EnableHttp2))
+	if anno.Get(annotation.EnableHttp2) != "" {
+		listener.EnableHttp2 = model.FlagType(anno.Get(annotation.EnableHttp2))
 	}
 
-	if reqCtx.Anno.Get(annotation.ProxyProtocol) != "" {
-		listener.EnableProxyProtocolV2 = tea.Bool(reqCtx.Anno.Get(annotation.ProxyProtocol) == string(model.OnFlag))
+	if anno.Get(annotation.ProxyProtocol) != "" {
+		listener.EnableProxyProtocolV2 = tea.Bool(anno.Get(annotation.ProxyProtocol) == string(model.OnFlag))
 	}
 
-	if reqCtx.Anno.Get(annotation.ForwardPort) != "" && listener.Protocol == model.HTTP {
-		fp, err := forwardPort(reqCtx.Anno.Get(annotation.ForwardPort), int(port.Port))
+	if anno.Get(annotation.ForwardPort) != "" && listener.Protocol == model.HTTP {
+		fp, err := forwardPort(anno.Get(annotation.ForwardPort), int(port.Port))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation ForwardPort error: %s ", err.Error())
 		}
@@ -195,148 +339,148 @@ func (mgr *ListenerManager) buildListenerFromServicePort(reqCtx *svcCtx.RequestC
 		}
 	}
 
-	if reqCtx.Anno.Get(annotation.IdleTimeout) != "" {
-		idleTimeout, err := strconv.Atoi(reqCtx.Anno.Get(annotation.IdleTimeout))
+	if anno.Get(annotation.IdleTimeout) != "" {
+		idleTimeout, err := strconv.Atoi(anno.Get(annotation.IdleTimeout))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation IdleTimeout must be integer, but got [%s]. message=[%s] ",
-				reqCtx.Anno.Get(annotation.IdleTimeout), err.Error())
+				anno.Get(annotation.IdleTimeout), err.Error())
 		}
 		listener.IdleTimeout = idleTimeout
 	}
 
-	if reqCtx.Anno.Get(annotation.RequestTimeout) != "" {
-		requestTimeout, err := strconv.Atoi(reqCtx.Anno.Get(annotation.RequestTimeout))
+	if anno.Get(annotation.RequestTimeout) != "" {
+		requestTimeout, err := strconv.Atoi(anno.Get(annotation.RequestTimeout))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation RequestTimeout must be integer, but got [%s]. message=[%s] ",
-				reqCtx.Anno.Get(annotation.RequestTimeout), err.Error())
+				anno.Get(annotation.RequestTimeout), err.Error())
 		}
 		listener.RequestTimeout = requestTimeout
 	}
 
 	// acl
-	if reqCtx.Anno.Get(annotation.AclStatus) != "" {
-		listener.AclStatus = model.FlagType(reqCtx.Anno.Get(annotation.AclStatus))
+	if anno.Get(annotation.AclStatus) != "" {
+		listener.AclStatus = model.FlagType(anno.Get(annotation.AclStatus))
 	}
-	if reqCtx.Anno.Get(annotation.AclType) != "" {
-		listener.AclType = reqCtx.Anno.Get(annotation.AclType)
+	if anno.Get(annotation.AclType) != "" {
+		listener.AclType = anno.Get(annotation.AclType)
 	}
-	if reqCtx.Anno.Get(annotation.AclID) != "" {
-		listener.AclId = reqCtx.Anno.Get(annotation.AclID)
+	if anno.Get(annotation.AclID) != "" {
+		listener.AclId = anno.Get(annotation.AclID)
 	}
 
 	// connection drain
-	if reqCtx.Anno.Get(annotation.ConnectionDrain) != "" {
-		listener.ConnectionDrain = model.FlagType(reqCtx.Anno.Get(annotation.ConnectionDrain))
+	if anno.Get(annotation.ConnectionDrain) != "" {
+		listener.ConnectionDrain = model.FlagType(anno.Get(annotation.ConnectionDrain))
 	}
-	if reqCtx.Anno.Get(annotation.ConnectionDrainTimeout) != "" {
-		timeout, err := strconv.Atoi(reqCtx.Anno.Get(annotation.ConnectionDrainTimeout))
+	if anno.Get(annotation.ConnectionDrainTimeout) != "" {
+		timeout, err := strconv.Atoi(anno.Get(annotation.ConnectionDrainTimeout))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation ConnectionDrainTimeout must be integer, but got [%s]. message=[%s] ",
-				reqCtx.Anno.Get(annotation.ConnectionDrainTimeout), err.Error())
+				anno.Get(annotation.ConnectionDrainTimeout), err.Error())
 		}
 		listener.ConnectionDrainTimeout = timeout
 	}
 
 	// cookie
-	if reqCtx.Anno.Get(annotation.Cookie) != "" {
-		listener.Cookie = reqCtx.Anno.Get(annotation.Cookie)
+	if anno.Get(annotation.Cookie) != "" {
+		listener.Cookie = anno.Get(annotation.Cookie)
 	}
-	if reqCtx.Anno.Get(annotation.CookieTimeout) != "" {
-		timeout, err := strconv.Atoi(reqCtx.Anno.Get(annotation.CookieTimeout))
+	if anno.Get(annotation.CookieTimeout) != "" {
+		timeout, err := strconv.Atoi(anno.Get(annotation.CookieTimeout))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation CookieTimeout must be integer, but got [%s]. message=[%s] ",
-				reqCtx.Anno.Get(annotation.CookieTimeout), err.Error())
+				anno.Get(annotation.CookieTimeout), err.Error())
 		}
 		listener.CookieTimeout = timeout
 	}
-	if reqCtx.Anno.Get(annotation.SessionStick) != "" {
-		listener.StickySession = model.FlagType(reqCtx.Anno.Get(annotation.SessionStick))
+	if anno.Get(annotation.SessionStick) != "" {
+		listener.StickySession = model.FlagType(anno.Get(annotation.SessionStick))
 	}
-	if reqCtx.Anno.Get(annotation.SessionStickType) != "" {
-		listener.StickySessionType = reqCtx.Anno.Get(annotation.SessionStickType)
+	if anno.Get(annotation.SessionStickType) != "" {
+		listener.StickySessionType = anno.Get(annotation.SessionStickType)
 	}
 
 	// x-forwarded-for
-	if reqCtx.Anno.Get(annotation.XForwardedForProto) != "" {
-		listener.XForwardedForProto = model.FlagType(reqCtx.Anno.Get(annotation.XForwardedForProto))
+	if anno.Get(annotation.XForwardedForProto) != "" {
+		listener.XForwardedForProto = model.FlagType(anno.Get(annotation.XForwardedForProto))
 	}
-	if reqCtx.Anno.Get(annotation.XForwardedForSLBPort) != "" {
-		listener.XForwardedForSLBPort = model.FlagType(reqCtx.Anno.Get(annotation.XForwardedForSLBPort))
+	if anno.Get(annotation.XForwardedForSLBPort) != "" {
+		listener.XForwardedForSLBPort = model.FlagType(anno.Get(annotation.XForwardedForSLBPort))
 	}
-	if reqCtx.Anno.Get(annotation.XForwardedForClientSrcPort) != "" {
-		listener.XForwardedForClientSrcPort = model.FlagType(reqCtx.Anno.Get(annotation.XForwardedForClientSrcPort))
+	if anno.Get(annotation.XForwardedForClientSrcPort) != "" {
+		listener.XForwardedForClientSrcPort = model.FlagType(anno.Get(annotation.XForwardedForClientSrcPort))
 	}
 
 	// health check
-	if reqCtx.Anno.Get(annotation.HealthyThreshold) != "" {
-		t, err := strconv.Atoi(reqCtx.Anno.Get(annotation.HealthyThreshold))
+	if anno.Get(annotation.HealthyThreshold) != "" {
+		t, err := strconv.Atoi(anno.Get(annotation.HealthyThreshold))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation HealthyThreshold must be integer, but got [%s]. message=[%s] ",
-				reqCtx.Anno.Get(annotation.HealthyThreshold), err.Error())
+				anno.Get(annotation.HealthyThreshold), err.Error())
 		}
 		listener.HealthyThreshold = t
 	}
-	if reqCtx.Anno.Get(annotation.UnhealthyThreshold) != "" {
-		t, err := strconv.Atoi(reqCtx.Anno.Get(annotation.UnhealthyThreshold))
+	if anno.Get(annotation.UnhealthyThreshold) != "" {
+		t, err := strconv.Atoi(anno.Get(annotation.UnhealthyThreshold))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation UnhealthyThreshold must be integer, but got [%s]. message=[%s] ",
-				reqCtx.Anno.Get(annotation.UnhealthyThreshold), err.Error())
+				anno.Get(annotation.UnhealthyThreshold), err.Error())
 		}
 		listener.UnhealthyThreshold = t
 	}
-	if reqCtx.Anno.Get(annotation.HealthCheckConnectTimeout) != "" {
-		timeout, err := strconv.Atoi(reqCtx.Anno.Get(annotation.HealthCheckConnectTimeout))
+	if anno.Get(annotation.HealthCheckConnectTimeout) != "" {
+		timeout, err := strconv.Atoi(anno.Get(annotation.HealthCheckConnectTimeout))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation HealthCheckConnectTimeout must be integer, but got [%s]. message=[%s] ",
-				reqCtx.Anno.Get(annotation.HealthCheckConnectTimeout), err.Error())
+				anno.Get(annotation.HealthCheckConnectTimeout), err.Error())
 		}
 		listener.HealthCheckConnectTimeout = timeout
 	}
-	if reqCtx.Anno.Get(annotation.HealthCheckConnectPort) != "" {
-		port, err := strconv.Atoi(reqCtx.Anno.Get(annotation.HealthCheckConnectPort))
+	if anno.Get(annotation.HealthCheckConnectPort) != "" {
+		port, err := strconv.Atoi(anno.Get(annotation.HealthCheckConnectPort))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation HealthCheckConnectPort must be integer, but got [%s]. message=[%s] ",
-				reqCtx.Anno.Get(annotation.HealthCheckConnectPort), err.Error())
+				anno.Get(annotation.HealthCheckConnectPort), err.Error())
 		}
 		listener.HealthCheckConnectPort = port
 	}
-	if reqCtx.Anno.Get(annotation.HealthCheckInterval) != "" {
-		t, err := strconv.Atoi(reqCtx.Anno.Get(annotation.HealthCheckInterval))
+	if anno.Get(annotation.HealthCheckInterval) != "" {
+		t, err := strconv.Atoi(anno.Get(annotation.HealthCheckInterval))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation HealthCheckInterval must be integer, but got [%s]. message=[%s] ",
-				reqCtx.Anno.Get(annotation.HealthCheckInterval), err.Error())
+				anno.Get(annotation.HealthCheckInterval), err.Error())
 		}
 		listener.HealthCheckInterval = t
 	}
-	if reqCtx.Anno.Get(annotation.HealthCheckDomain) != "" {
-		listener.HealthCheckDomain = reqCtx.Anno.Get(annotation.HealthCheckDomain)
+	if anno.Get(annotation.HealthCheckDomain) != "" {
+		listener.HealthCheckDomain = anno.Get(annotation.HealthCheckDomain)
 	}
-	if reqCtx.Anno.Get(annotation.HealthCheckURI) != "" {
-		listener.HealthCheckURI = reqCtx.Anno.Get(annotation.HealthCheckURI)
+	if anno.Get(annotation.HealthCheckURI) != "" {
+		listener.HealthCheckURI = anno.Get(annotation.HealthCheckURI)
 	}
-	if reqCtx.Anno.Get(annotation.HealthCheckHTTPCode) != "" {
-		listener.HealthCheckHttpCode = reqCtx.Anno.Get(annotation.HealthCheckHTTPCode)
+	if anno.Get(annotation.HealthCheckHTTPCode) != "" {
+		listener.HealthCheckHttpCode = anno.Get(annotation.HealthCheckHTTPCode)
 	}
-	if reqCtx.Anno.Get(annotation.HealthCheckType) != "" {
-		listener.HealthCheckType = reqCtx.Anno.Get(annotation.HealthCheckType)
+	if anno.Get(annotation.HealthCheckType) != "" {
+		listener.HealthCheckType = anno.Get(annotation.HealthCheckType)
 	}
-	if reqCtx.Anno.Get(annotation.HealthCheckFlag) != "" {
-		listener.HealthCheck = model.FlagType(reqCtx.Anno.Get(annotation.HealthCheckFlag))
+	if anno.Get(annotation.HealthCheckFlag) != "" {
+		listener.HealthCheck = model.FlagType(anno.Get(annotation.HealthCheckFlag))
 	}
-	if reqCtx.Anno.Get(annotation.HealthCheckTimeout) != "" {
-		timeout, err := strconv.Atoi(reqCtx.Anno.Get(annotation.HealthCheckTimeout))
+	if anno.Get(annotation.HealthCheckTimeout) != "" {
+		timeout, err := strconv.Atoi(anno.Get(annotation.HealthCheckTimeout))
 		if err != nil {
 			return listener, fmt.Errorf("Annotation HealthCheckTimeout must be integer, but got [%s]. message=[%s] ",
-				reqCtx.Anno.Get(annotation.HealthCheckTimeout), err.Error())
+				anno.Get(annotation.HealthCheckTimeout), err.Error())
 		}
 		listener.HealthCheckTimeout = timeout
 	}
-	if reqCtx.Anno.Get(annotation.HealthCheckMethod) != "" {
-		listener.HealthCheckMethod = reqCtx.Anno.Get(annotation.HealthCheckMethod)
+	if anno.Get(annotation.HealthCheckMethod) != "" {
+		listener.HealthCheckMethod = anno.Get(annotation.HealthCheckMethod)
 	}
 
-	if reqCtx.Anno.Get(annotation.HealthCheckSwitch) != "" {
-		listener.HealthCheckSwitch = model.FlagType(reqCtx.Anno.Get(annotation.HealthCheckSwitch))
+	if anno.Get(annotation.HealthCheckSwitch) != "" {
+		listener.HealthCheckSwitch = model.FlagType(anno.Get(annotation.HealthCheckSwitch))
 	}
 
 	return listener, nil
@@ -350,7 +494,7 @@ func (t *tcp) Create(reqCtx *svcCtx.RequestContext, action CreateAction) error {
 	setDefaultValueForListener(&action.listener)
 	err := t.mgr.cloud.CreateLoadBalancerTCPListener(reqCtx.Ctx, action.lbId, action.listener)
 	if err != nil {
-		return fmt.Errorf("create tcp listener %d error: %s", action.listener.ListenerPort, err.Error())
+		return fmt.Errorf("create tcp listener %d error: %w", action.listener.ListenerPort, err)
 	}
 	reqCtx.Log.Info(fmt.Sprintf("create listener tcp [%d]", action.listener.ListenerPort))
 	return t.mgr.cloud.StartLoadBalancerListener(reqCtx.Ctx, action.lbId, action.listener.ListenerPort, action.listener.Protocol)
@@ -360,7 +504,7 @@ func (t *tcp) Update(reqCtx *svcCtx.RequestContext, action UpdateAction) error {
 	if action.remote.Status == model.Stopped {
 		err := t.mgr.cloud.StartLoadBalancerListener(reqCtx.Ctx, action.lbId, action.local.ListenerPort, action.local.Protocol)
 		if err != nil {
-			return fmt.Errorf("start tcp listener %d error: %s", action.local.ListenerPort, err.Error())
+			return fmt.Errorf("start tcp listener %d error: %w", action.local.ListenerPort, err)
 		}
 	}
 	needUpdate, update := isNeedUpdate(reqCtx, action.local, action.remote)
@@ -379,7 +523,7 @@ func (t *udp) Create(reqCtx *svcCtx.RequestContext, action CreateAction) error {
 	setDefaultValueForListener(&action.listener)
 	err := t.mgr.cloud.CreateLoadBalancerUDPListener(reqCtx.Ctx, action.lbId, action.listener)
 	if err != nil {
-		return fmt.Errorf("create udp listener %d error: %s", action.listener.ListenerPort, err.Error())
+		return fmt.Errorf("create udp listener %d error: %w", action.listener.ListenerPort, err)
 	}
 	reqCtx.Log.Info(fmt.Sprintf("create listener udp [%d]", action.listener.ListenerPort))
 	return t.mgr.cloud.StartLoadBalancerListener(reqCtx.Ctx, action.lbId, action.listener.ListenerPort, action.listener.Protocol)
@@ -389,7 +533,7 @@ func (t *udp) Update(reqCtx *svcCtx.RequestContext, action UpdateAction) error {
 	if action.remote.Status == model.Stopped {
 		err := t.mgr.cloud.StartLoadBalancerListener(reqCtx.Ctx, action.lbId, action.local.ListenerPort, action.local.Protocol)
 		if err != nil {
-			return fmt.Errorf("start udp listener %d error: %s", action.local.ListenerPort, err.Error())
+			return fmt.Errorf("start udp listener %d error: %w", action.local.ListenerPort, err)
 		}
 	}
 	needUpdate, update := isNeedUpdate(reqCtx, action.local, action.remote)
@@ -408,7 +552,7 @@ func (t *http) Create(reqCtx *svcCtx.RequestContext, action CreateAction) error
 	setDefaultValueForListener(&action.listener)
 	err := t.mgr.cloud.CreateLoadBalancerHTTPListener(reqCtx.Ctx, action.lbId, action.listener)
 	if err != nil {
-		return fmt.Errorf("create http listener %d error: %s", action.listener.ListenerPort, err.Error())
+		return fmt.Errorf("create http listener %d error: %w", action.listener.ListenerPort, err)
 	}
 	reqCtx.Log.Info(fmt.Sprintf("create listener http [%d]", action.listener.ListenerPort))
 	return t.mgr.cloud.StartLoadBalancerListener(reqCtx.Ctx, action.lbId, action.listener.ListenerPort, action.listener.Protocol)
@@ -419,7 +563,7 @@ func (t *http) Update(reqCtx *svcCtx.RequestContext, action UpdateAction) error
 	if action.remote.Status == model.Stopped {
 		err := t.mgr.cloud.StartLoadBalancerListener(reqCtx.Ctx, action.lbId, action.local.ListenerPort, action.local.Protocol)
 		if err != nil {
-			return fmt.Errorf("start http listener %d error: %s", action.local.ListenerPort, err.Error())
+			return fmt.Errorf("start http listener %d error: %w", action.local.ListenerPort, err)
 		}
 	}
 
@@ -444,7 +588,7 @@ func (t *http) Update(reqCtx *svcCtx.RequestContext, action UpdateAction) error
 			listener: action.remote,
 		})
 		if err != nil {
-			return fmt.Errorf("delete port [%d] error: %s", action.remote.ListenerPort, err.Error())
+			return fmt.Errorf("delete port [%d] error: %w", action.remote.ListenerPort, err)
 		}
 
 		return t.Create(reqCtx, CreateAction{
@@ -475,7 +619,7 @@ func (t *https) Create(reqCtx *svcCtx.RequestContext, action CreateAction) error
 	setDefaultValueForListener(&action.listener)
 	err := t.mgr.cloud.CreateLoadBalancerHTTPSListener(reqCtx.Ctx, action.lbId, action.listener)
 	if err != nil {
-		return fmt.Errorf("create https listener %d error: %s", action.listener.ListenerPort, err.Error())
+		return fmt.Errorf("create https listener %d error: %w", action.listener.ListenerPort, err)
 	}
 	reqCtx.Log.Info(fmt.Sprintf("create listener https [%d]", action.listener.ListenerPort))
 	return t.mgr.cloud.StartLoadBalancerListener(reqCtx.Ctx, action.lbId, action.listener.ListenerPort, action.listener.Protocol)
@@ -486,7 +630,7 @@ func (t *https) Update(reqCtx *svcCtx.RequestContext, action UpdateAction) error
 	if action.remote.Status == model.Stopped {
 		err := t.mgr.cloud.StartLoadBalancerListener(reqCtx.Ctx, action.lbId, action.local.ListenerPort, action.local.Protocol)
 		if err != nil {
-			return fmt.Errorf("start https listener %d error: %s", action.local.ListenerPort, err.Error())
+			return fmt.Errorf("start https listener %d error: %w", action.local.ListenerPort, err)
 		}
 	}
 	needUpdate, update := isNeedUpdate(reqCtx, action.local, action.remote)
@@ -544,7 +688,7 @@ func buildActionsForListeners(reqCtx *svcCtx.RequestContext, local *model.LoadBa
 			continue
 		}
 		if err := findVServerGroup(remote.VServerGroups, &local.Listeners[i]); err != nil {
-			return createActions, updateActions, deleteActions, fmt.Errorf("find vservergroup error: %s", err.Error())
+			return createActions, updateActions, deleteActions, fmt.Errorf("find vservergroup error: %w", err)
 		}
 	}
 
@@ -1014,12 +1158,12 @@ func isPortManagedByMyService(local *model.LoadBalancer, n model.ListenerAttribu
 func checkCertValidity(cloudClient prvd.Provider, oldCertId, newCertId string) error {
 	oldCert, err := cloudClient.DescribeServerCertificateById(context.TODO(), oldCertId)
 	if err != nil {
-		return fmt.Errorf("describe old cert %s error: %s", oldCertId, err.Error())
+		return fmt.Errorf("describe old cert %s error: %w", oldCertId, err)
 	}
 
 	newCert, err := cloudClient.DescribeServerCertificateById(context.TODO(), newCertId)
 	if err != nil {
-		return fmt.Errorf("describe new cert %s error: %s", newCertId, err.Error())
+		return fmt.Errorf("describe new cert %s error: %w", newCertId, err)
 	}
 
 	if oldCert == nil {
@@ -9,6 +9,7 @@ import (
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/eip"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
@@ -22,12 +23,14 @@ func NewLoadBalancerManager(cloud prvd.Provider) *LoadBalancerManager {
 	return &LoadBalancerManager{
 		cloud:      cloud,
 		tokenCache: cmap.New(),
+		eipMgr:     eip.NewManager(cloud),
 	}
 }
 
 type LoadBalancerManager struct {
 	cloud      prvd.Provider
 	tokenCache cmap.ConcurrentMap
+	eipMgr     *eip.Manager
 }
 
 func (mgr *LoadBalancerManager) Find(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
@@ -53,7 +56,7 @@ func (mgr *LoadBalancerManager) Find(reqCtx *svcCtx.RequestContext, mdl *model.L
 
 func (mgr *LoadBalancerManager) Create(reqCtx *svcCtx.RequestContext, local *model.LoadBalancer) error {
 	if err := setModelDefaultValue(mgr, local, reqCtx.Anno); err != nil {
-		return fmt.Errorf("set model default value error: %s", err.Error())
+		return fmt.Errorf("set model default value error: %w", err)
 	}
 
 	key := reqCtx.Anno.GetDefaultLoadBalancerName()
@@ -67,7 +70,7 @@ func (mgr *LoadBalancerManager) Create(reqCtx *svcCtx.RequestContext, local *mod
 
 	err := mgr.cloud.CreateLoadBalancer(reqCtx.Ctx, local, clientToken)
 	if err != nil {
-		return fmt.Errorf("create slb error: %s", err.Error())
+		return fmt.Errorf("create slb error: %w", err)
 	}
 
 	return nil
@@ -109,7 +112,7 @@ func (mgr *LoadBalancerManager) Update(reqCtx *svcCtx.RequestContext, local, rem
 		lbTag = tag.Tag{Key: helper.TAGKEY, Value: reqCtx.Anno.GetDefaultLoadBalancerName()}
 	}
 	if err := mgr.addTagIfNotExist(reqCtx, *remote, lbTag); err != nil {
-		errs = append(errs, fmt.Errorf("AddTags: %s", err.Error()))
+		errs = append(errs, fmt.Errorf("AddTags: %w", err))
 	}
 
 	if local.LoadBalancerAttribute.MasterZoneId != "" &&
@@ -138,7 +141,7 @@ func (mgr *LoadBalancerManager) Update(reqCtx *svcCtx.RequestContext, local, rem
 
 	// update instanceChargeType && instanceSpec
 	if err := mgr.updateInstanceChargeTypeAndInstanceSpec(reqCtx, local, remote); err != nil {
-		errs = append(errs, fmt.Errorf("updateInstanceChargeTypeAndInstanceSpec error: %s", err.Error()))
+		errs = append(errs, fmt.Errorf("updateInstanceChargeTypeAndInstanceSpec error: %w", err))
 	}
 
 	// update internet chargeType & bandwidth
@@ -166,7 +169,7 @@ func (mgr *LoadBalancerManager) Update(reqCtx *svcCtx.RequestContext, local, rem
 			reqCtx.Log.Info(fmt.Sprintf("update lb: modify loadbalancer: chargeType=%s, bandwidth=%d", charge, bandwidth),
 				"lbId", lbId)
 			if err := mgr.cloud.ModifyLoadBalancerInternetSpec(reqCtx.Ctx, lbId, string(charge), bandwidth); err != nil {
-				errs = append(errs, fmt.Errorf("ModifyLoadBalancerInternetSpec: %s", err.Error()))
+				errs = append(errs, fmt.Errorf("ModifyLoadBalancerInternetSpec: %w", err))
 			}
 		} else {
 			reqCtx.Log.Info("update lb: only internet loadbalancer is allowed to modify bandwidth and pay type",
@@ -182,7 +185,7 @@ func (mgr *LoadBalancerManager) Update(reqCtx *svcCtx.RequestContext, local, rem
 			"lbId", lbId)
 		if err := mgr.cloud.SetLoadBalancerDeleteProtection(reqCtx.Ctx, lbId,
 			string(local.LoadBalancerAttribute.DeleteProtection)); err != nil {
-			errs = append(errs, fmt.Errorf("SetLoadBalancerDeleteProtection: %s", err.Error()))
+			errs = append(errs, fmt.Errorf("SetLoadBalancerDeleteProtection: %w", err))
 		}
 	}
 
@@ -195,7 +198,7 @@ func (mgr *LoadBalancerManager) Update(reqCtx *svcCtx.RequestContext, local, rem
 			"lbId", lbId)
 		if err := mgr.cloud.SetLoadBalancerModificationProtection(reqCtx.Ctx, lbId,
 			string(local.LoadBalancerAttribute.ModificationProtectionStatus)); err != nil {
-			errs = append(errs, fmt.Errorf("SetLoadBalancerModificationProtection: %s", err.Error()))
+			errs = append(errs, fmt.Errorf("SetLoadBalancerModificationProtection: %w", err))
 		}
 	}
 
@@ -208,7 +211,7 @@ func (mgr *LoadBalancerManager) Update(reqCtx *svcCtx.RequestContext, local, rem
 			"lbId", lbId)
 		if err := mgr.cloud.SetLoadBalancerName(reqCtx.Ctx, lbId,
 			local.LoadBalancerAttribute.LoadBalancerName); err != nil {
-			errs = append(errs, fmt.Errorf("SetLoadBalancerName: %s", err.Error()))
+			errs = append(errs, fmt.Errorf("SetLoadBalancerName: %w", err))
 		}
 	}
 
@@ -222,6 +225,21 @@ func (mgr *LoadBalancerManager) Update(reqCtx *svcCtx.RequestContext, local, rem
 	return utilerrors.NewAggregate(errs)
 }
 
+// SyncEip allocates the eip of the service and associates it with the intranet clb, if the service asks for it.
+func (mgr *LoadBalancerManager) SyncEip(reqCtx *svcCtx.RequestContext, local, remote *model.LoadBalancer) error {
+	opts, err := eip.GetCLBOptions(reqCtx.Anno, local.LoadBalancerAttribute.AddressType,
+		local.LoadBalancerAttribute.AddressIPVersion)
+	if err != nil || opts == nil {
+		return err
+	}
+	return mgr.eipMgr.SyncCLB(reqCtx, opts, remote.LoadBalancerAttribute.LoadBalancerId)
+}
+
+// GarbageCollectEips releases the eips allocated for the service, it is called when the service is deleted.
+func (mgr *LoadBalancerManager) GarbageCollectEips(reqCtx *svcCtx.RequestContext) error {
+	return mgr.eipMgr.GarbageCollect(reqCtx, nil)
+}
+
 // Build build load balancer attribute for local model
 func (mgr *LoadBalancerManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
 	mdl.LoadBalancerAttribute.AddressType = model.AddressType(reqCtx.Anno.Get(annotation.AddressType))
@@ -232,7 +250,7 @@ func (mgr *LoadBalancerManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, m
 	if bandwidth != "" {
 		i, err := strconv.Atoi(bandwidth)
 		if err != nil {
-			return fmt.Errorf("bandwidth must be integer, got [%s], error: %s", bandwidth, err.Error())
+			return fmt.Errorf("bandwidth must be integer, got [%s], error: %w", bandwidth, err)
 		}
 		mdl.LoadBalancerAttribute.Bandwidth = i
 	}
@@ -366,13 +384,13 @@ func setModelDefaultValue(mgr *LoadBalancerManager, mdl *model.LoadBalancer, ann
 	if mdl.LoadBalancerAttribute.AddressType == model.IntranetAddressType {
 		vpcId, err := mgr.cloud.VpcID()
 		if err != nil {
-			return fmt.Errorf("get vpc id from metadata error: %s", err.Error())
+			return fmt.Errorf("get vpc id from metadata error: %w", err)
 		}
 		mdl.LoadBalancerAttribute.VpcId = vpcId
 		if mdl.LoadBalancerAttribute.VSwitchId == "" {
 			vswId, err := mgr.cloud.VswitchID()
 			if err != nil {
-				return fmt.Errorf("get vsw id from metadata error: %s", err.Error())
+				return fmt.Errorf("get vsw id from metadata error: %w", err)
 			}
 			mdl.LoadBalancerAttribute.VSwitchId = vswId
 		}
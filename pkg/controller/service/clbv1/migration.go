@@ -0,0 +1,51 @@
+package clbv1
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/vpc"
+)
+
+// needDeleteLoadBalancer returns whether the clb of the service should be deleted, which includes
+// the clb released by the cut-over of the migration to nlb.
+func needDeleteLoadBalancer(svc *v1.Service) bool {
+	return helper.NeedDeleteLoadBalancer(svc) || helper.NeedReleaseCLB(svc)
+}
+
+// isCLBIngress returns whether the ingress is published by the clb controller for the clb of the id.
+func (m *ReconcileService) isCLBIngress(reqCtx *svcCtx.RequestContext, lbId string) (func(v1.LoadBalancerIngress) bool, error) {
+	addresses, err := helper.CLBAddresses(reqCtx.Ctx, m.cloud, string(vpc.SlbInstance), lbId)
+	if err != nil {
+		return nil, err
+	}
+	hostname := reqCtx.Anno.Get(annotation.HostName)
+	return func(ing v1.LoadBalancerIngress) bool {
+		return helper.IsCLBIngress(ing, addresses, hostname)
+	}, nil
+}
+
+// checkMigrationCutOver moves the migration to nlb to the cut-over once it is due, so that the clb
+// is released. Otherwise, it returns how long to wait before checking again.
+func (m *ReconcileService) checkMigrationCutOver(reqCtx *svcCtx.RequestContext) (time.Duration, error) {
+	svc := reqCtx.Service
+	if !helper.IsMigratingToNLB(svc) || helper.NeedReleaseCLB(svc) || helper.NeedDeleteLoadBalancer(svc) {
+		return 0, nil
+	}
+	due, after, err := helper.IsCutOverDue(svc, time.Now())
+	if err != nil || !due {
+		return after, err
+	}
+
+	reqCtx.Log.Info("migration to nlb reaches the cut-over, release the clb")
+	if err := helper.SetMigrationCondition(reqCtx.Ctx, m.kubeClient, svc, helper.MigrationReasonCutOver,
+		"releasing the clb"); err != nil {
+		return 0, fmt.Errorf("update migration condition error: %s", err.Error())
+	}
+	m.record.Event(svc, v1.EventTypeNormal, helper.ReleasingCLB, "Releasing the clb, the service is served by the nlb")
+	return 0, nil
+}
@@ -8,6 +8,7 @@ import (
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/certificate"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
@@ -39,7 +40,7 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *model.LoadBal
 
 	err := m.slbMgr.BuildRemoteModel(reqCtx, remote)
 	if err != nil {
-		return remote, fmt.Errorf("get load balancer attribute from cloud, error: %s", err.Error())
+		return remote, fmt.Errorf("get load balancer attribute from cloud, error: %w", err)
 	}
 	klog.Infof("%s find clb with result, reconcileID: %s\n%+v", util.Key(reqCtx.Service), reqCtx.ReconcileID, util.PrettyJson(remote))
 
@@ -48,7 +49,8 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *model.LoadBal
 			"The lb [%s] will be preserved after the service is deleted.", remote.LoadBalancerAttribute.LoadBalancerId)
 	}
 
-	serviceHashChanged := helper.IsServiceHashChanged(reqCtx.Service)
+	// the hash does not change when the soak period of the migration to nlb is over
+	serviceHashChanged := helper.IsServiceHashChanged(reqCtx.Service, reqCtx.Anno.Config()) || helper.NeedReleaseCLB(reqCtx.Service)
 	errs := []error{}
 	// apply sequence can not change, apply lb first, then vgroup, listener at last
 	if serviceHashChanged || ctrlCfg.ControllerCFG.DryRun {
@@ -56,7 +58,7 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *model.LoadBal
 			_, ok := err.(utilerrors.Aggregate)
 			if ok {
 				// if lb attr update failed, continue to sync vgroup & listener
-				errs = append(errs, fmt.Errorf("update lb attribute error: %s", err.Error()))
+				errs = append(errs, fmt.Errorf("update lb attribute error: %w", err))
 			} else {
 				return nil, err
 			}
@@ -65,7 +67,7 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *model.LoadBal
 
 	if remote.LoadBalancerAttribute.LoadBalancerId == "" {
 		// delete loadbalancer: return nil
-		if helper.NeedDeleteLoadBalancer(reqCtx.Service) {
+		if needDeleteLoadBalancer(reqCtx.Service) {
 			return remote, nil
 		}
 		// update loadbalancer: return error
@@ -75,28 +77,51 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *model.LoadBal
 	}
 	reqCtx.Ctx = context.WithValue(reqCtx.Ctx, dryrun.ContextSLB, remote.LoadBalancerAttribute.LoadBalancerId)
 
+	if (serviceHashChanged || ctrlCfg.ControllerCFG.DryRun) && !needDeleteLoadBalancer(reqCtx.Service) {
+		if err := m.slbMgr.SyncEip(reqCtx, local, remote); err != nil {
+			errs = append(errs, fmt.Errorf("sync eip error: %w", err))
+		}
+	}
+
 	if err := m.vGroupMgr.BuildRemoteModel(reqCtx, remote); err != nil {
-		errs = append(errs, fmt.Errorf("get lb backend from remote error: %s", err.Error()))
+		errs = append(errs, fmt.Errorf("get lb backend from remote error: %w", err))
 		return remote, utilerrors.NewAggregate(errs)
 	}
 	if err := m.applyVGroups(reqCtx, local, remote); err != nil {
-		errs = append(errs, fmt.Errorf("update lb backends error: %s", err.Error()))
+		errs = append(errs, fmt.Errorf("update lb backends error: %w", err))
 		return remote, utilerrors.NewAggregate(errs)
 	}
 
-	if serviceHashChanged || ctrlCfg.ControllerCFG.DryRun {
+	// listeners with certs of secrets are synced every time to catch the secret rotation
+	if serviceHashChanged || certificate.UseSecret(reqCtx.Anno) || ctrlCfg.ControllerCFG.DryRun {
 		if err := m.lisMgr.BuildRemoteModel(reqCtx, remote); err != nil {
-			errs = append(errs, fmt.Errorf("get lb listeners from cloud, error: %s", err.Error()))
+			errs = append(errs, fmt.Errorf("get lb listeners from cloud, error: %w", err))
+			return remote, utilerrors.NewAggregate(errs)
+		}
+		if err := m.lisMgr.applySecretCert(reqCtx, local); err != nil {
+			errs = append(errs, fmt.Errorf("sync certificate of secret error: %w", err))
+			return remote, utilerrors.NewAggregate(errs)
+		}
+		if err := m.lisMgr.applyAcl(reqCtx, local); err != nil {
+			errs = append(errs, fmt.Errorf("sync acl error: %w", err))
+			return remote, utilerrors.NewAggregate(errs)
+		}
+		staleAclIds, err := m.lisMgr.unbindStaleAcls(reqCtx, local, remote)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get stale acls error: %w", err))
 			return remote, utilerrors.NewAggregate(errs)
 		}
 		if err := m.applyListeners(reqCtx, local, remote); err != nil {
-			errs = append(errs, fmt.Errorf("update lb listeners error: %s", err.Error()))
+			errs = append(errs, fmt.Errorf("update lb listeners error: %w", err))
 			return remote, utilerrors.NewAggregate(errs)
 		}
+		if err := m.lisMgr.aclMgr.Delete(reqCtx, staleAclIds); err != nil {
+			reqCtx.Log.Error(err, "delete stale acls failed")
+		}
 	}
 
 	if err := m.cleanup(reqCtx, local, remote); err != nil {
-		errs = append(errs, fmt.Errorf("update lb listeners error: %s", err.Error()))
+		errs = append(errs, fmt.Errorf("update lb listeners error: %w", err))
 		return remote, utilerrors.NewAggregate(errs)
 	}
 
@@ -116,7 +141,7 @@ func (m *ModelApplier) applyLoadBalancerAttribute(reqCtx *svcCtx.RequestContext,
 	}
 
 	// delete slb
-	if helper.NeedDeleteLoadBalancer(reqCtx.Service) {
+	if needDeleteLoadBalancer(reqCtx.Service) {
 		if !local.LoadBalancerAttribute.IsUserManaged {
 			if local.LoadBalancerAttribute.PreserveOnDelete {
 				err := m.slbMgr.SetProtectionsOff(reqCtx, remote)
@@ -156,7 +181,7 @@ func (m *ModelApplier) applyLoadBalancerAttribute(reqCtx *svcCtx.RequestContext,
 		}
 
 		if err := m.slbMgr.Create(reqCtx, local); err != nil {
-			return fmt.Errorf("create lb error: %s", err.Error())
+			return fmt.Errorf("create lb error: %w", err)
 		}
 		reqCtx.Log.Info(fmt.Sprintf("successfully create lb %s", local.LoadBalancerAttribute.LoadBalancerId))
 		// update remote model
@@ -170,12 +195,12 @@ func (m *ModelApplier) applyLoadBalancerAttribute(reqCtx *svcCtx.RequestContext,
 
 	tags, err := m.slbMgr.cloud.ListCLBTagResources(reqCtx.Ctx, remote.LoadBalancerAttribute.LoadBalancerId)
 	if err != nil {
-		return fmt.Errorf("DescribeTags: %s", err.Error())
+		return fmt.Errorf("DescribeTags: %w", err)
 	}
 	remote.LoadBalancerAttribute.Tags = tags
 
 	// check whether slb can be reused
-	if !helper.NeedDeleteLoadBalancer(reqCtx.Service) && local.LoadBalancerAttribute.IsUserManaged {
+	if !needDeleteLoadBalancer(reqCtx.Service) && local.LoadBalancerAttribute.IsUserManaged {
 		if ok, reason := isLoadBalancerReusable(reqCtx, tags, remote.LoadBalancerAttribute.Address); !ok {
 			return fmt.Errorf("alicloud: the loadbalancer %s can not be reused, %s",
 				remote.LoadBalancerAttribute.LoadBalancerId, reason)
@@ -269,7 +294,7 @@ func (m *ModelApplier) applyListeners(reqCtx *svcCtx.RequestContext, local *mode
 	}
 	createActions, updateActions, deleteActions, err := buildActionsForListeners(reqCtx, local, remote)
 	if err != nil {
-		return fmt.Errorf("merge listener: %s", err.Error())
+		return fmt.Errorf("merge listener: %w", err)
 	}
 	// make https come first.
 	// ensure https listeners to be created first for http forward
@@ -292,21 +317,21 @@ func (m *ModelApplier) applyListeners(reqCtx *svcCtx.RequestContext, local *mode
 	for _, action := range deleteActions {
 		err := m.lisMgr.Delete(reqCtx, action)
 		if err != nil {
-			return fmt.Errorf("delete listener [%d] error: %s", action.listener.ListenerPort, err.Error())
+			return fmt.Errorf("delete listener [%d] error: %w", action.listener.ListenerPort, err)
 		}
 	}
 
 	for _, action := range createActions {
 		err := m.lisMgr.Create(reqCtx, action)
 		if err != nil {
-			return fmt.Errorf("create listener [%d] error: %s", action.listener.ListenerPort, err.Error())
+			return fmt.Errorf("create listener [%d] error: %w", action.listener.ListenerPort, err)
 		}
 	}
 
 	for _, action := range updateActions {
 		err := m.lisMgr.Update(reqCtx, action)
 		if err != nil {
-			return fmt.Errorf("update listener [%d] error: %s", action.local.ListenerPort, err.Error())
+			return fmt.Errorf("update listener [%d] error: %w", action.local.ListenerPort, err)
 		}
 	}
 
@@ -1,6 +1,7 @@
 package clbv1
 
 import (
+	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
@@ -12,7 +13,9 @@ import (
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/vmock"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"testing"
 	"time"
 )
@@ -24,11 +27,11 @@ func TestCreateLB(t *testing.T) {
 	}
 	builder := &ModelBuilder{
 		LoadBalancerMgr: NewLoadBalancerManager(getMockCloudProvider()),
-		ListenerMgr:     NewListenerManager(getMockCloudProvider()),
+		ListenerMgr:     NewListenerManager(getFakeKubeClient(), getMockCloudProvider()),
 		VGroupMgr:       vgm,
 	}
 	applier := NewModelApplier(NewLoadBalancerManager(getMockCloudProvider()),
-		NewListenerManager(getMockCloudProvider()), vgm)
+		NewListenerManager(getFakeKubeClient(), getMockCloudProvider()), vgm)
 
 	// create new lb
 	svc := getDefaultService()
@@ -82,12 +85,12 @@ func TestSyncLB(t *testing.T) {
 	}
 	builder := &ModelBuilder{
 		LoadBalancerMgr: NewLoadBalancerManager(getMockCloudProvider()),
-		ListenerMgr:     NewListenerManager(getMockCloudProvider()),
+		ListenerMgr:     NewListenerManager(getFakeKubeClient(), getMockCloudProvider()),
 		VGroupMgr:       vgm,
 	}
 
 	applier := NewModelApplier(NewLoadBalancerManager(getMockCloudProvider()),
-		NewListenerManager(getMockCloudProvider()), vgm)
+		NewListenerManager(getFakeKubeClient(), getMockCloudProvider()), vgm)
 
 	svc := getDefaultService()
 	svc.Spec.Ports = []v1.ServicePort{
@@ -181,19 +184,19 @@ func TestDeleteLB(t *testing.T) {
 	}
 	builder := &ModelBuilder{
 		LoadBalancerMgr: NewLoadBalancerManager(getMockCloudProvider()),
-		ListenerMgr:     NewListenerManager(getMockCloudProvider()),
+		ListenerMgr:     NewListenerManager(getFakeKubeClient(), getMockCloudProvider()),
 		VGroupMgr:       vgm,
 	}
 
 	applier := NewModelApplier(NewLoadBalancerManager(getMockCloudProvider()),
-		NewListenerManager(getMockCloudProvider()), vgm)
+		NewListenerManager(getFakeKubeClient(), getMockCloudProvider()), vgm)
 
 	// delete auto-created lb
 	svc := getDefaultService()
 	svc.UID = types.UID(SvcUID)
 	svc.ObjectMeta.Finalizers = []string{helper.ServiceFinalizer}
 	svc.ObjectMeta.DeletionTimestamp = &metav1.Time{Time: time.Now()}
-	svc.Labels = map[string]string{helper.LabelServiceHash: helper.GetServiceHash(svc)}
+	svc.Labels = map[string]string{helper.LabelServiceHash: helper.GetServiceHash(svc, nil)}
 	reqCtx := getReqCtx(svc)
 	localModel, err := builder.Instance(LocalModel).Build(reqCtx)
 	if err != nil {
@@ -228,7 +231,7 @@ func TestBuildRemoteModel(t *testing.T) {
 	}
 	builder := &ModelBuilder{
 		LoadBalancerMgr: NewLoadBalancerManager(getMockCloudProvider()),
-		ListenerMgr:     NewListenerManager(getMockCloudProvider()),
+		ListenerMgr:     NewListenerManager(getFakeKubeClient(), getMockCloudProvider()),
 		VGroupMgr:       vgm,
 	}
 	svc := getDefaultService()
@@ -246,12 +249,12 @@ func TestSyncVGroups(t *testing.T) {
 	}
 	builder := &ModelBuilder{
 		LoadBalancerMgr: NewLoadBalancerManager(getMockCloudProvider()),
-		ListenerMgr:     NewListenerManager(getMockCloudProvider()),
+		ListenerMgr:     NewListenerManager(getFakeKubeClient(), getMockCloudProvider()),
 		VGroupMgr:       vgm,
 	}
 
 	applier := NewModelApplier(NewLoadBalancerManager(getMockCloudProvider()),
-		NewListenerManager(getMockCloudProvider()), vgm)
+		NewListenerManager(getFakeKubeClient(), getMockCloudProvider()), vgm)
 
 	svc := getDefaultService()
 	svc.Spec.Ports = []v1.ServicePort{
@@ -401,3 +404,59 @@ func TestSyncVGroups(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestApplySecretCert(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: NS},
+		Data:       map[string][]byte{v1.TLSCertKey: []byte("cert"), v1.TLSPrivateKeyKey: []byte("key")},
+	}
+	cloud := snapshot.NewSnapshotCloud(&snapshot.Snapshot{ClusterID: "c1"})
+	mgr := NewListenerManager(fake.NewClientBuilder().WithObjects(secret).Build(), cloud)
+
+	svc := getDefaultService()
+	svc.Annotations[annotation.Annotation(annotation.CertSecret)] = "tls"
+	mdl := &model.LoadBalancer{
+		Listeners: []model.ListenerAttribute{
+			{ListenerPort: 80, Protocol: model.HTTP},
+			{ListenerPort: 443, Protocol: model.HTTPS},
+		},
+	}
+	assert.NoError(t, mgr.applySecretCert(getReqCtx(svc), mdl))
+	assert.Equal(t, "", mdl.Listeners[0].CertId)
+	assert.NotEqual(t, "", mdl.Listeners[1].CertId)
+
+	certs, err := cloud.ListServerCertificates(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(certs))
+
+	// the certificates are deleted once the annotation is removed
+	delete(svc.Annotations, annotation.Annotation(annotation.CertSecret))
+	assert.NoError(t, mgr.GarbageCollectSecretCerts(getReqCtx(svc)))
+	certs, err = cloud.ListServerCertificates(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(certs))
+}
+
+func TestApplyAcl(t *testing.T) {
+	cloud := snapshot.NewSnapshotCloud(&snapshot.Snapshot{ClusterID: "c1"})
+	mgr := NewListenerManager(getFakeKubeClient(), cloud)
+
+	svc := getDefaultService()
+	svc.Spec.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+	svc.Annotations[annotation.Annotation(annotation.AclSourceRanges)] = "on"
+	reqCtx := getReqCtx(svc)
+	mdl := &model.LoadBalancer{}
+	assert.NoError(t, mgr.BuildLocalModel(reqCtx, mdl))
+	// the model is built without touching the acl
+	assert.Empty(t, cloud.Operations())
+	assert.Equal(t, []string{"10.0.0.0/8"}, mdl.LoadBalancerAttribute.AclCidrs)
+	assert.Equal(t, model.OnFlag, mdl.Listeners[0].AclStatus)
+	assert.Equal(t, "", mdl.Listeners[0].AclId)
+
+	assert.NoError(t, mgr.applyAcl(reqCtx, mdl))
+	assert.NotEqual(t, "", mdl.Listeners[0].AclId)
+	acls, err := mgr.aclMgr.List(reqCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(acls))
+	assert.Equal(t, acls[0].AclId, mdl.Listeners[0].AclId)
+}
@@ -2,7 +2,6 @@ package clbv1
 
 import (
 	"fmt"
-	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
@@ -60,7 +59,7 @@ func (c localModel) Build(reqCtx *svcCtx.RequestContext) (*model.LoadBalancer, e
 		NamespacedName: util.NamespacedName(reqCtx.Service),
 	}
 	// if the service do not need loadbalancer any more, return directly.
-	if helper.NeedDeleteLoadBalancer(reqCtx.Service) {
+	if needDeleteLoadBalancer(reqCtx.Service) {
 		if reqCtx.Anno.Get(annotation.LoadBalancerId) != "" {
 			lbMdl.LoadBalancerAttribute.IsUserManaged = true
 		}
@@ -70,13 +69,13 @@ func (c localModel) Build(reqCtx *svcCtx.RequestContext) (*model.LoadBalancer, e
 		return lbMdl, nil
 	}
 	if err := c.LoadBalancerMgr.BuildLocalModel(reqCtx, lbMdl); err != nil {
-		return nil, fmt.Errorf("build lb attribute error: %s", err.Error())
+		return nil, fmt.Errorf("build lb attribute error: %w", err)
 	}
 	if err := c.VGroupMgr.BuildLocalModel(reqCtx, lbMdl); err != nil {
-		return nil, fmt.Errorf("build vserver groups error: %s", err.Error())
+		return nil, fmt.Errorf("build vserver groups error: %w", err)
 	}
 	if err := c.ListenerMgr.BuildLocalModel(reqCtx, lbMdl); err != nil {
-		return nil, fmt.Errorf("builid lb listener error: %s", err.Error())
+		return nil, fmt.Errorf("builid lb listener error: %w", err)
 	}
 
 	return lbMdl, nil
@@ -92,18 +91,18 @@ func (c remoteModel) Build(reqCtx *svcCtx.RequestContext) (*model.LoadBalancer,
 
 	err := c.LoadBalancerMgr.BuildRemoteModel(reqCtx, lbMdl)
 	if err != nil {
-		return nil, fmt.Errorf("can not get load balancer attribute from cloud, error: %s", err.Error())
+		return nil, fmt.Errorf("can not get load balancer attribute from cloud, error: %w", err)
 	}
 	if lbMdl.LoadBalancerAttribute.LoadBalancerId == "" {
 		return lbMdl, nil
 	}
 
 	if err := c.VGroupMgr.BuildRemoteModel(reqCtx, lbMdl); err != nil {
-		return nil, fmt.Errorf("build backend from remote error: %s", err.Error())
+		return nil, fmt.Errorf("build backend from remote error: %w", err)
 	}
 
 	if err := c.ListenerMgr.BuildRemoteModel(reqCtx, lbMdl); err != nil {
-		return nil, fmt.Errorf("can not build listener attribute from cloud, error: %s", err.Error())
+		return nil, fmt.Errorf("can not build listener attribute from cloud, error: %w", err)
 	}
 
 	return lbMdl, nil
@@ -20,7 +20,10 @@ import (
 	"k8s.io/klog/v2"
 
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/backend"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/certificate"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/eip"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
 
@@ -37,6 +40,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/context/shared"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
@@ -66,7 +70,7 @@ func newReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*ReconcileSe
 	}
 
 	slbManager := NewLoadBalancerManager(recon.cloud)
-	listenerManager := NewListenerManager(recon.cloud)
+	listenerManager := NewListenerManager(recon.kubeClient, recon.cloud)
 	vGroupManager, err := NewVGroupManager(recon.kubeClient, recon.cloud)
 	if err != nil {
 		return nil, err
@@ -134,6 +138,18 @@ func add(mgr manager.Manager, r *ReconcileService) error {
 		NewEnqueueRequestForNodeEvent(mgr.GetClient(), mgr.GetEventRecorderFor("service-controller"))); err != nil {
 		return fmt.Errorf("watch resource node error: %s", err.Error())
 	}
+
+	if err := c.Watch(source.Kind(mgr.GetCache(), &v1.Secret{}),
+		certificate.NewEnqueueRequestForSecretEvent(mgr.GetClient(), helper.NeedCLB)); err != nil {
+		return fmt.Errorf("watch resource secret error: %s", err.Error())
+	}
+
+	if utilfeature.DefaultFeatureGate.Enabled(ctrlCfg.LoadBalancerConfig) {
+		if err := c.Watch(source.Kind(mgr.GetCache(), &albv1.LoadBalancerConfig{}),
+			annotation.NewEnqueueRequestForConfigEvent(mgr.GetClient(), helper.NeedCLB)); err != nil {
+			return fmt.Errorf("watch resource LoadBalancerConfig error: %s", err.Error())
+		}
+	}
 	return mgr.Add(&serviceController{c: c, recon: r})
 }
 
@@ -200,7 +216,16 @@ func (m *ReconcileService) reconcile(c context.Context, request reconcile.Reques
 		util.ServiceLog.Error(err, "reconcile: get service failed", "service", request.NamespacedName)
 		return err
 	}
-	anno := &annotation.AnnotationRequest{Service: svc}
+	anno, err := annotation.NewAnnotationRequestWithConfig(context.Background(), m.kubeClient, svc)
+	if err != nil {
+		if helper.NeedCLB(svc) {
+			m.record.Event(svc, v1.EventTypeWarning, helper.FailedSyncLB,
+				fmt.Sprintf("Error loading LoadBalancerConfig: %s", err.Error()))
+			return err
+		}
+		// the clb is cleaned up without the config
+		anno = annotation.NewAnnotationRequest(svc)
+	}
 
 	// disable public address
 	if anno.Get(annotation.AddressType) == "" ||
@@ -238,7 +263,7 @@ func (m *ReconcileService) reconcile(c context.Context, request reconcile.Reques
 	}
 
 	var operation string
-	svcHash := helper.GetServiceHash(svc)
+	svcHash := helper.GetServiceHash(svc, anno.Config())
 	if svc.DeletionTimestamp != nil {
 		operation = metric.VerbDeletion
 	} else if svc.Status.LoadBalancer.Ingress == nil {
@@ -247,11 +272,22 @@ func (m *ReconcileService) reconcile(c context.Context, request reconcile.Reques
 		operation = metric.VerbUpdate
 	}
 
+	// check whether the migration to nlb reaches the cut-over, which releases the clb
+	cutOverAfter, err := m.checkMigrationCutOver(reqContext)
+	if err != nil {
+		m.record.Event(reqContext.Service, v1.EventTypeWarning, helper.FailedSyncLB,
+			fmt.Sprintf("Error checking the cut-over of the migration to nlb: %s", err.Error()))
+		return err
+	}
+
 	// check to see whither if loadbalancer deletion is needed
-	if helper.NeedDeleteLoadBalancer(svc) || !helper.NeedCLB(svc) {
+	if helper.NeedDeleteLoadBalancer(svc) || !helper.NeedCLB(svc) || helper.NeedReleaseCLB(svc) {
 		err = m.cleanupLoadBalancerResources(reqContext)
 	} else {
 		err = m.reconcileLoadBalancerResources(reqContext)
+		if err == nil && cutOverAfter > 0 {
+			err = util.NewReconcileNeedRequeueAfter("wait for the cut-over of the migration to nlb", cutOverAfter)
+		}
 	}
 
 	var needRequeue *util.ReconcileNeedRequeue
@@ -297,6 +333,26 @@ func (m *ReconcileService) reconcile(c context.Context, request reconcile.Reques
 func (m *ReconcileService) cleanupLoadBalancerResources(reqCtx *svcCtx.RequestContext) error {
 	reqCtx.Log.Info("service do not need lb any more, try to delete it")
 	if helper.HasFinalizer(reqCtx.Service, helper.ServiceFinalizer) {
+		// the addresses of the clb released by the migration are resolved before it is deleted, so that
+		// they are dropped from the status while the ones of the nlb are kept
+		var isCLBIngress func(v1.LoadBalancerIngress) bool
+		if helper.IsMigratingToNLB(reqCtx.Service) {
+			var err error
+			isCLBIngress, err = m.isCLBIngress(reqCtx, reqCtx.Service.Labels[helper.LabelLoadBalancerId])
+			if err != nil {
+				reqCtx.Log.Error(err, "resolve the addresses of the clb failed, keep them in the status")
+				isCLBIngress, _ = m.isCLBIngress(reqCtx, "")
+			}
+		}
+
+		// the eip is unassociated and released before the clb is deleted, it is retried on error, otherwise
+		// it leaks once the finalizer is removed
+		if err := m.builder.LoadBalancerMgr.GarbageCollectEips(reqCtx); err != nil {
+			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedCleanLB,
+				fmt.Sprintf("Error releasing eips: %s", err.Error()))
+			return err
+		}
+
 		lb, _, err := m.buildAndApplyModel(reqCtx)
 		if err != nil && !strings.Contains(err.Error(), "LoadBalancerId does not exist") {
 			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedCleanLB,
@@ -305,6 +361,17 @@ func (m *ReconcileService) cleanupLoadBalancerResources(reqCtx *svcCtx.RequestCo
 			return err
 		}
 
+		if err := m.builder.ListenerMgr.GarbageCollectSecretCerts(reqCtx); err != nil {
+			reqCtx.Log.Error(err, "garbage collect certs of secret failed")
+		}
+
+		// the acls still bound to the listeners are retried, otherwise they leak once the finalizer is removed
+		if err := m.builder.ListenerMgr.GarbageCollectAcls(reqCtx, lb); err != nil {
+			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedCleanLB,
+				fmt.Sprintf("Error deleting acls: %s", err.Error()))
+			return err
+		}
+
 		if err := m.removeServiceLabels(reqCtx.Service); err != nil {
 			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedRemoveHash,
 				fmt.Sprintf("Error removing service hash: %s", err.Error()))
@@ -313,18 +380,40 @@ func (m *ReconcileService) cleanupLoadBalancerResources(reqCtx *svcCtx.RequestCo
 
 		// When service type changes from LoadBalancer to NodePort,
 		// we need to clean Ingress attribute in service status
-		if err := m.removeServiceStatus(reqCtx, reqCtx.Service); err != nil {
+		if err := m.removeServiceStatus(reqCtx, reqCtx.Service, isCLBIngress); err != nil {
 			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedUpdateStatus,
 				fmt.Sprintf("Error removing load balancer status: %s", err.Error()))
 			return err
 		}
 
+		if err := backend.SyncDrainFinalizers(reqCtx, m.kubeClient, false, nil, nil); err != nil {
+			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedSyncDrainFinalizer,
+				fmt.Sprintf("Error removing drain finalizers of pods: %s", err.Error()))
+			return err
+		}
+
 		if err := m.finalizerManager.RemoveFinalizers(reqCtx.Ctx, reqCtx.Service, helper.ServiceFinalizer); err != nil {
 			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedRemoveFinalizer,
 				fmt.Sprintf("Error removing load balancer finalizer: %v", err.Error()))
 			return err
 		}
 	}
+
+	if helper.NeedReleaseCLB(reqCtx.Service) {
+		// the label is kept with the service, so that it is not handed back to the clb controller once
+		// the condition is lost
+		if err := helper.MarkMigratedToNLB(reqCtx.Ctx, m.kubeClient, reqCtx.Service); err != nil {
+			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedAddHash,
+				fmt.Sprintf("Error marking the migration to nlb: %s", err.Error()))
+			return err
+		}
+		if err := helper.SetMigrationCondition(reqCtx.Ctx, m.kubeClient, reqCtx.Service,
+			helper.MigrationReasonCLBReleased, "the clb is released, the service is served by the nlb"); err != nil {
+			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedUpdateStatus,
+				fmt.Sprintf("Error updating migration condition: %s", err.Error()))
+			return err
+		}
+	}
 	m.record.Event(reqCtx.Service, v1.EventTypeNormal, helper.SucceedCleanLB, "Clean load balancer")
 	return nil
 }
@@ -339,12 +428,23 @@ func (m *ReconcileService) reconcileLoadBalancerResources(req *svcCtx.RequestCon
 
 	lb, vservers, err := m.buildAndApplyModel(req)
 	if err != nil {
-		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedSyncLB,
+		reason := helper.FailedSyncLB
+		if util.IsThrottlingError(err) {
+			reason = helper.ThrottledSyncLB
+		}
+		m.record.Event(req.Service, v1.EventTypeWarning, reason,
 			fmt.Sprintf("Error syncing load balancer [%s]: %s",
 				lb.GetLoadBalancerId(), helper.GetLogMessage(err)))
 		return err
 	}
 
+	// the hash label is not updated until the certificates of a removed annotation are deleted
+	if err := m.builder.ListenerMgr.GarbageCollectSecretCerts(req); err != nil {
+		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedSyncLB,
+			fmt.Sprintf("Error deleting certificates of secrets: %s", err.Error()))
+		return err
+	}
+
 	err = m.updateReadinessCondition(req, vservers)
 	if err != nil {
 		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedUpdateReadinessGate,
@@ -352,7 +452,14 @@ func (m *ReconcileService) reconcileLoadBalancerResources(req *svcCtx.RequestCon
 		return err
 	}
 
-	if err := m.addServiceLabels(req.Service, lb.GetLoadBalancerId()); err != nil {
+	drainRequeueAfter, err := m.updateDrainFinalizers(req, vservers)
+	if err != nil {
+		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedSyncDrainFinalizer,
+			fmt.Sprintf("Error syncing drain finalizers of pods: %s", err.Error()))
+		return err
+	}
+
+	if err := m.addServiceLabels(req.Service, req.Anno.Config(), lb.GetLoadBalancerId()); err != nil {
 		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedAddHash,
 			fmt.Sprintf("Error adding service hash: %s", err.Error()))
 		return err
@@ -370,10 +477,43 @@ func (m *ReconcileService) reconcileLoadBalancerResources(req *svcCtx.RequestCon
 	if lb.ContainsPotentialReadyEndpoints {
 		return util.NewReconcileNeedRequeue("has potential ready backends")
 	}
+	if drainRequeueAfter > 0 {
+		return util.NewReconcileNeedRequeueAfter("wait for the deregistration delay of the draining backends", drainRequeueAfter)
+	}
 
 	return nil
 }
 
+// updateDrainFinalizers keeps the drain finalizer on the pods registered to the load balancer until they
+// are removed from the backends, and returns the duration until the next draining backend can be removed.
+func (m *ReconcileService) updateDrainFinalizers(reqCtx *svcCtx.RequestContext, vgroups []model.VServerGroup) (time.Duration, error) {
+	drain, err := backend.GetDrainOptions(reqCtx)
+	if err != nil {
+		return 0, err
+	}
+	var deadlines []time.Time
+	registered := make(map[types.NamespacedName]bool)
+	draining := make(map[types.NamespacedName]bool)
+	for _, vg := range vgroups {
+		for _, b := range vg.InitialBackends {
+			if b.TargetRef == nil || b.TargetRef.Kind != "Pod" {
+				continue
+			}
+			key := types.NamespacedName{Namespace: b.TargetRef.Namespace, Name: b.TargetRef.Name}
+			if b.DrainDeadline.IsZero() {
+				registered[key] = true
+				continue
+			}
+			draining[key] = true
+			deadlines = append(deadlines, b.DrainDeadline)
+		}
+	}
+	if err := backend.SyncDrainFinalizers(reqCtx, m.kubeClient, drain.Finalizer, registered, draining); err != nil {
+		return 0, err
+	}
+	return backend.DrainRequeueAfter(deadlines), nil
+}
+
 func (m *ReconcileService) buildAndApplyModel(reqCtx *svcCtx.RequestContext) (*model.LoadBalancer, []model.VServerGroup, error) {
 	// build local model
 	localModel, err := m.builder.BuildModel(reqCtx, LocalModel)
@@ -402,8 +542,8 @@ func (m *ReconcileService) updateServiceStatus(reqCtx *svcCtx.RequestContext, sv
 		return fmt.Errorf("lb not found, cannot not patch service status")
 	}
 
-	// EIP ExternalIPType, use the slb associated elastic ip as service external ip
-	if reqCtx.Anno.Get(annotation.ExternalIPType) == "eip" {
+	// EIP ExternalIPType or allocated eip, use the slb associated elastic ip as service external ip
+	if reqCtx.Anno.Get(annotation.ExternalIPType) == "eip" || eip.Declared(reqCtx.Anno) {
 		ingress, err := m.setEIPAsExternalIP(reqCtx.Ctx, lb.LoadBalancerAttribute.LoadBalancerId)
 		if err != nil {
 			reqCtx.Recorder.Event(svc, v1.EventTypeWarning, "FailedSetEIPAddress", "get eip error, set external ip to slb ip")
@@ -428,6 +568,15 @@ func (m *ReconcileService) updateServiceStatus(reqCtx *svcCtx.RequestContext, sv
 			})
 	}
 
+	// publish the address of the nlb as well during the migration
+	if helper.IsMigratingToNLB(svc) {
+		isCLBIngress, err := m.isCLBIngress(reqCtx, lb.LoadBalancerAttribute.LoadBalancerId)
+		if err != nil {
+			return err
+		}
+		newStatus.Ingress = helper.MergeMigrationIngress(newStatus.Ingress, preStatus.Ingress, isCLBIngress)
+	}
+
 	// Write the state if changed
 	// TODO: Be careful here ... what if there were other changes to the service?
 	if !v1helper.LoadBalancerStatusEqual(preStatus, newStatus) {
@@ -481,9 +630,14 @@ func (m *ReconcileService) updateServiceStatus(reqCtx *svcCtx.RequestContext, sv
 
 }
 
-func (m *ReconcileService) removeServiceStatus(reqCtx *svcCtx.RequestContext, svc *v1.Service) error {
+func (m *ReconcileService) removeServiceStatus(reqCtx *svcCtx.RequestContext, svc *v1.Service,
+	isCLBIngress func(v1.LoadBalancerIngress) bool) error {
 	preStatus := svc.Status.LoadBalancer.DeepCopy()
 	newStatus := &v1.LoadBalancerStatus{}
+	// keep the address of the nlb when the clb is released by the migration
+	if helper.IsMigratingToNLB(svc) && isCLBIngress != nil {
+		newStatus.Ingress = helper.MergeMigrationIngress(nil, preStatus.Ingress, isCLBIngress)
+	}
 
 	// Write the state if changed
 	// TODO: Be careful here ... what if there were other changes to the service?
@@ -535,12 +689,12 @@ func (m *ReconcileService) removeServiceStatus(reqCtx *svcCtx.RequestContext, sv
 
 }
 
-func (m *ReconcileService) addServiceLabels(svc *v1.Service, lbId string) error {
+func (m *ReconcileService) addServiceLabels(svc *v1.Service, config *albv1.LoadBalancerConfig, lbId string) error {
 	updated := svc.DeepCopy()
 	if updated.Labels == nil {
 		updated.Labels = make(map[string]string)
 	}
-	serviceHash := helper.GetServiceHash(svc)
+	serviceHash := helper.GetServiceHash(svc, config)
 	updated.Labels[helper.LabelServiceHash] = serviceHash
 	if lbId != "" {
 		updated.Labels[helper.LabelLoadBalancerId] = lbId
@@ -603,6 +757,9 @@ func (m *ReconcileService) updateReadinessCondition(reqCtx *svcCtx.RequestContex
 				reqCtx.Log.Info("backend TargetRef is nil, skip update readiness gates")
 				continue
 			}
+			if !b.DrainDeadline.IsZero() {
+				continue
+			}
 			key := types.NamespacedName{Namespace: b.TargetRef.Namespace, Name: b.TargetRef.Name}
 			if _, ok := a[key.String()]; ok {
 				continue
@@ -271,7 +271,7 @@ func getReconcileService() *ReconcileService {
 	}
 
 	slbManager := NewLoadBalancerManager(recon.cloud)
-	listenerManager := NewListenerManager(recon.cloud)
+	listenerManager := NewListenerManager(recon.kubeClient, recon.cloud)
 	vGroupManager, _ := NewVGroupManager(recon.kubeClient, recon.cloud)
 	recon.builder = NewModelBuilder(slbManager, listenerManager, vGroupManager)
 	recon.applier = NewModelApplier(slbManager, listenerManager, vGroupManager)
@@ -0,0 +1,124 @@
+package clbv1
+
+import (
+	"fmt"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/acl"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/eip"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+// ValidateService checks the annotations of the service with the builders of the local model, without
+// calling the OpenAPIs. oldAnno is the annotations before the update, or nil on creation, and is used to
+// reject the changes the load balancer does not accept once created. The values which are likely to be
+// a mistake are returned as warnings.
+func ValidateService(reqCtx *svcCtx.RequestContext, oldAnno *annotation.AnnotationRequest) ([]string, error) {
+	var warnings []string
+	if needDeleteLoadBalancer(reqCtx.Service) {
+		return warnings, nil
+	}
+
+	local, err := buildValidationModel(reqCtx)
+	if err != nil {
+		return warnings, err
+	}
+
+	certId := reqCtx.Anno.Get(annotation.CertID)
+	if certId != "" && reqCtx.Anno.Get(annotation.CertSecret) != "" {
+		return warnings, fmt.Errorf("annotation %s and %s can not be set at the same time",
+			annotation.CertID, annotation.CertSecret)
+	}
+	if certId != "" || reqCtx.Anno.Get(annotation.CertSecret) != "" {
+		hasHTTPS := false
+		for _, l := range local.Listeners {
+			if l.Protocol == model.HTTPS {
+				hasHTTPS = true
+			}
+		}
+		if !hasHTTPS {
+			warnings = append(warnings, fmt.Sprintf("the certificate is only used by https listeners, "+
+				"set annotation %s to use https for the ports", annotation.Annotation(annotation.ProtocolPort)))
+		}
+	}
+
+	if oldAnno == nil || !helper.HasFinalizer(oldAnno.Service, helper.ServiceFinalizer) ||
+		needDeleteLoadBalancer(oldAnno.Service) {
+		return warnings, nil
+	}
+	old, err := buildValidationModel(&svcCtx.RequestContext{
+		Ctx:     reqCtx.Ctx,
+		Service: oldAnno.Service,
+		Anno:    oldAnno,
+		Log:     reqCtx.Log,
+	})
+	if err != nil {
+		// the old annotations are invalid, there is nothing to compare with
+		return warnings, nil
+	}
+	oldAttr, newAttr := old.LoadBalancerAttribute, local.LoadBalancerAttribute
+	oldAddressType, newAddressType := oldAttr.AddressType, newAttr.AddressType
+	if oldAddressType == "" {
+		oldAddressType = model.InternetAddressType
+	}
+	if newAddressType == "" {
+		newAddressType = model.InternetAddressType
+	}
+	oldIPVersion, newIPVersion := oldAttr.AddressIPVersion, newAttr.AddressIPVersion
+	if oldIPVersion == "" {
+		oldIPVersion = model.IPv4
+	}
+	if newIPVersion == "" {
+		newIPVersion = model.IPv4
+	}
+	for _, c := range []struct{ k, old, new string }{
+		{annotation.AddressType, string(oldAddressType), string(newAddressType)},
+		{annotation.IPVersion, string(oldIPVersion), string(newIPVersion)},
+		{annotation.MasterZoneID, oldAttr.MasterZoneId, newAttr.MasterZoneId},
+		{annotation.SlaveZoneID, oldAttr.SlaveZoneId, newAttr.SlaveZoneId},
+		{annotation.ResourceGroupId, oldAttr.ResourceGroupId, newAttr.ResourceGroupId},
+		{annotation.IP, oldAttr.Address, newAttr.Address},
+	} {
+		warning, err := annotation.ValidateImmutable(c.k, c.old, c.new)
+		if err != nil {
+			return warnings, err
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings, nil
+}
+
+// buildValidationModel builds the attributes of the load balancer and the listeners of the local model,
+// which only depend on the annotations of the service.
+func buildValidationModel(reqCtx *svcCtx.RequestContext) (*model.LoadBalancer, error) {
+	mdl := &model.LoadBalancer{
+		NamespacedName: util.NamespacedName(reqCtx.Service),
+	}
+	if err := (&LoadBalancerManager{}).BuildLocalModel(reqCtx, mdl); err != nil {
+		return nil, fmt.Errorf("build lb attribute error: %s", err.Error())
+	}
+	lisMgr := &ListenerManager{}
+	for _, port := range reqCtx.Service.Spec.Ports {
+		listener, err := lisMgr.buildListenerFromServicePort(reqCtx, port, mdl.LoadBalancerAttribute.IsUserManaged)
+		if err != nil {
+			return nil, fmt.Errorf("build listener from servicePort %d error: %s", port.Port, err.Error())
+		}
+		if err := reqCtx.Anno.ForPort(port.Port).ValidateIntRanges(annotation.CLBIntRanges); err != nil {
+			return nil, fmt.Errorf("build listener from servicePort %d error: %s", port.Port, err.Error())
+		}
+		mdl.Listeners = append(mdl.Listeners, listener)
+	}
+	if _, _, err := acl.GetCLBCidrs(reqCtx.Anno, mdl.LoadBalancerAttribute.AddressIPVersion); err != nil {
+		return nil, fmt.Errorf("build acl error: %s", err.Error())
+	}
+	if _, err := eip.GetCLBOptions(reqCtx.Anno, mdl.LoadBalancerAttribute.AddressType,
+		mdl.LoadBalancerAttribute.AddressIPVersion); err != nil {
+		return nil, fmt.Errorf("build eip error: %s", err.Error())
+	}
+	return mdl, nil
+}
@@ -0,0 +1,75 @@
+package clbv1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+)
+
+func TestValidateService(t *testing.T) {
+	svc := getDefaultService()
+	svc.Annotations[annotation.Annotation(annotation.HealthCheckInterval)] = "100"
+	_, err := ValidateService(getReqCtx(svc), nil)
+	assert.Error(t, err)
+
+	svc = getDefaultService()
+	svc.Annotations[annotation.Annotation(annotation.CertID)] = "cert-id"
+	warnings, err := ValidateService(getReqCtx(svc), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(warnings))
+
+	svc.Annotations[annotation.Annotation(annotation.CertSecret)] = "secret"
+	_, err = ValidateService(getReqCtx(svc), nil)
+	assert.Error(t, err)
+
+	svc = getDefaultService()
+	svc.Spec.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+	_, err = ValidateService(getReqCtx(svc), nil)
+	assert.NoError(t, err)
+
+	svc.Annotations[annotation.Annotation(annotation.AclStatus)] = "off"
+	_, err = ValidateService(getReqCtx(svc), nil)
+	assert.NoError(t, err)
+
+	svc.Annotations[annotation.Annotation(annotation.AclSourceRanges)] = "on"
+	_, err = ValidateService(getReqCtx(svc), nil)
+	assert.Error(t, err)
+
+	svc = getDefaultService()
+	svc.Annotations[annotation.Annotation(annotation.AclCidrs)] = "2001:db8::/32"
+	_, err = ValidateService(getReqCtx(svc), nil)
+	assert.Error(t, err)
+
+	svc = getDefaultService()
+	svc.Annotations[annotation.Annotation(annotation.AllocateEip)] = "on"
+	svc.Annotations[annotation.Annotation(annotation.AddressType)] = "intranet"
+	_, err = ValidateService(getReqCtx(svc), nil)
+	assert.NoError(t, err)
+
+	svc.Annotations[annotation.Annotation(annotation.AddressType)] = "internet"
+	_, err = ValidateService(getReqCtx(svc), nil)
+	assert.Error(t, err)
+}
+
+func TestValidateService_Immutable(t *testing.T) {
+	oldSvc := getDefaultService()
+	oldSvc.Finalizers = []string{helper.ServiceFinalizer}
+	svc := oldSvc.DeepCopy()
+	svc.Annotations[annotation.Annotation(annotation.AddressType)] = "intranet"
+
+	// the load balancer is not created yet
+	creating := getDefaultService()
+	_, err := ValidateService(getReqCtx(svc), annotation.NewAnnotationRequest(creating))
+	assert.NoError(t, err)
+
+	_, err = ValidateService(getReqCtx(svc), annotation.NewAnnotationRequest(oldSvc))
+	assert.Error(t, err)
+
+	svc = oldSvc.DeepCopy()
+	svc.Annotations[annotation.Annotation(annotation.AddressType)] = "internet"
+	warnings, err := ValidateService(getReqCtx(svc), annotation.NewAnnotationRequest(oldSvc))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(warnings))
+}
@@ -71,7 +71,7 @@ func (mgr *VGroupManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, m *mode
 
 	vpcCIDRs, err := mgr.cloud.DescribeVpcCIDRBlock(reqCtx.Ctx, mgr.vpcId, candidates.AddressIPVersion)
 	if err != nil {
-		return fmt.Errorf("get vpc cidr error: %s", err.Error())
+		return fmt.Errorf("get vpc cidr error: %w", err)
 	}
 
 	vgs := make([]model.VServerGroup, len(reqCtx.Service.Spec.Ports))
@@ -81,7 +81,7 @@ func (mgr *VGroupManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, m *mode
 		port := reqCtx.Service.Spec.Ports[i]
 		vg, cpr, err := mgr.buildVGroupForServicePort(reqCtx, vpcCIDRs, port, candidates, m.LoadBalancerAttribute.IsUserManaged)
 		if err != nil {
-			errs[i] = fmt.Errorf("build vgroup for port %d error: %s", port.Port, err.Error())
+			errs[i] = fmt.Errorf("build vgroup for port %d error: %w", port.Port, err)
 			return
 		}
 		vgs[i] = vg
@@ -104,7 +104,7 @@ func (mgr *VGroupManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, m *mode
 func (mgr *VGroupManager) BuildRemoteModel(reqCtx *svcCtx.RequestContext, m *model.LoadBalancer) error {
 	vgs, err := mgr.DescribeVServerGroups(reqCtx, m.LoadBalancerAttribute.LoadBalancerId)
 	if err != nil {
-		return fmt.Errorf("DescribeVServerGroups error: %s", err.Error())
+		return fmt.Errorf("DescribeVServerGroups error: %w", err)
 	}
 	m.VServerGroups = vgs
 	return nil
@@ -340,13 +340,13 @@ func diff(remote, local model.VServerGroup) (
 			if l.Type == "eni" {
 				if l.ServerId == r.ServerId &&
 					l.ServerIp == r.ServerIp &&
-					(l.Port != r.Port || (!local.IgnoreWeightUpdate && l.Weight != r.Weight) || l.Description != r.Description) {
+					(l.Port != r.Port || ((!local.IgnoreWeightUpdate || !l.DrainDeadline.IsZero()) && l.Weight != r.Weight) || l.Description != r.Description) {
 					updates = append(updates, l)
 					break
 				}
 			} else {
 				if l.ServerId == r.ServerId &&
-					(l.Port != r.Port || (!local.IgnoreWeightUpdate && l.Weight != r.Weight) || l.Description != r.Description) {
+					(l.Port != r.Port || ((!local.IgnoreWeightUpdate || !l.DrainDeadline.IsZero()) && l.Weight != r.Weight) || l.Description != r.Description) {
 					updates = append(updates, l)
 					break
 				}
@@ -398,7 +398,7 @@ func (mgr *VGroupManager) buildVGroupForServicePort(reqCtx *svcCtx.RequestContex
 	if isUserManagedLB && reqCtx.Anno.Get(annotation.VGroupPort) != "" {
 		vgroupId, err := vgroup(reqCtx.Anno.Get(annotation.VGroupPort), port)
 		if err != nil {
-			return vg, false, fmt.Errorf("vgroupid parse error: %s", err.Error())
+			return vg, false, fmt.Errorf("vgroupid parse error: %w", err)
 		}
 		if vgroupId != "" {
 			v, err := getVgroupById(mgr, reqCtx, vgroupId)
@@ -448,24 +448,28 @@ func (mgr *VGroupManager) buildVGroupForServicePort(reqCtx *svcCtx.RequestContex
 		reqCtx.Log.Info(fmt.Sprintf("eni mode, build backends for %s", vg.NamedKey))
 		backends, err = mgr.buildENIBackends(reqCtx, vpcCIDRs, candidates, initialBackends, vg)
 		if err != nil {
-			return vg, false, fmt.Errorf("build eni backends error: %s", err.Error())
+			return vg, false, fmt.Errorf("build eni backends error: %w", err)
 		}
 	case helper.LocalTrafficPolicy:
 		reqCtx.Log.Info(fmt.Sprintf("local mode, build backends for %s", vg.NamedKey))
 		backends, err = mgr.buildLocalBackends(reqCtx, vpcCIDRs, candidates, initialBackends, vg)
 		if err != nil {
-			return vg, false, fmt.Errorf("build local backends error: %s", err.Error())
+			return vg, false, fmt.Errorf("build local backends error: %w", err)
 		}
 	case helper.ClusterTrafficPolicy:
 		reqCtx.Log.Info(fmt.Sprintf("cluster mode, build backends for %s", vg.NamedKey))
 		backends, err = mgr.buildClusterBackends(reqCtx, vpcCIDRs, candidates, initialBackends, vg)
 		if err != nil {
-			return vg, false, fmt.Errorf("build cluster backends error: %s", err.Error())
+			return vg, false, fmt.Errorf("build cluster backends error: %w", err)
 		}
 	default:
 		return vg, false, fmt.Errorf("not supported traffic policy [%s]", candidates.TrafficPolicy)
 	}
 
+	if candidates.Topology != nil {
+		backends = setZoneAwareWeights(candidates.TrafficPolicy, candidates.Topology, backends, initialBackends, vg.VGroupWeight)
+	}
+
 	if len(backends) == 0 {
 		reqCtx.Recorder.Event(
 			reqCtx.Service,
@@ -484,7 +488,7 @@ func getVgroupById(mgr *VGroupManager, reqCtx *svcCtx.RequestContext, vgroupId s
 	// check vgroup id is existed
 	vgroups, err := mgr.cloud.DescribeVServerGroups(reqCtx.Ctx, reqCtx.Anno.Get(annotation.LoadBalancerId))
 	if err != nil {
-		return nil, fmt.Errorf("cannot find vgroup by vgroupId %s error: %s", vgroupId, err.Error())
+		return nil, fmt.Errorf("cannot find vgroup by vgroupId %s error: %w", vgroupId, err)
 	}
 	for _, v := range vgroups {
 		if v.VGroupId == vgroupId {
@@ -594,6 +598,11 @@ func (mgr *VGroupManager) setBackendsFromEndpointSlices(reqCtx *svcCtx.RequestCo
 		return nil, false, nil
 	}
 
+	drain, err := backend.GetDrainOptions(reqCtx)
+	if err != nil {
+		return nil, false, err
+	}
+
 	for _, es := range candidates.EndpointSlices {
 		var backendPort int
 		if vgroup.ServicePort.TargetPort.Type == intstr.Int {
@@ -614,8 +623,29 @@ func (mgr *VGroupManager) setBackendsFromEndpointSlices(reqCtx *svcCtx.RequestCo
 		}
 
 		for _, ep := range es.Endpoints {
-			// ignore terminating pods
+			// ignore terminating pods, unless they are kept with weight 0 for the deregistration delay
 			if ep.Conditions.Terminating != nil && *ep.Conditions.Terminating {
+				deadline, err := backend.GetDrainDeadline(reqCtx.Ctx, mgr.kubeClient, ep, drain.Delay)
+				if err != nil {
+					return nil, false, err
+				}
+				if deadline.IsZero() {
+					continue
+				}
+				for _, addr := range ep.Addresses {
+					if _, ok := endpointMap[addr]; ok {
+						continue
+					}
+					endpointMap[addr] = true
+					backends = append(backends, model.BackendAttribute{
+						NodeName:      ep.NodeName,
+						ServerIp:      addr,
+						Port:          backendPort,
+						Description:   vgroup.VGroupName,
+						TargetRef:     ep.TargetRef,
+						DrainDeadline: deadline,
+					})
+				}
 				continue
 			}
 
@@ -692,7 +722,17 @@ func (mgr *VGroupManager) buildENIBackends(reqCtx *svcCtx.RequestContext, vpcCID
 		return backends, err
 	}
 
-	return setWeightBackends(helper.ENITrafficPolicy, backends, vgroup.VGroupWeight), nil
+	// the draining backends take no new connections and are left out of the weight calculation
+	var serving, draining []model.BackendAttribute
+	for _, b := range backends {
+		if b.DrainDeadline.IsZero() {
+			serving = append(serving, b)
+			continue
+		}
+		b.Weight = 0
+		draining = append(draining, b)
+	}
+	return append(setWeightBackends(helper.ENITrafficPolicy, serving, vgroup.VGroupWeight), draining...), nil
 }
 
 func (mgr *VGroupManager) buildLocalBackends(reqCtx *svcCtx.RequestContext, vpcCIDRs []*net.IPNet,
@@ -745,7 +785,7 @@ func (mgr *VGroupManager) buildLocalBackends(reqCtx *svcCtx.RequestContext, vpcC
 		reqCtx.Log.Info("add eciBackends")
 		eciBackends, err = updateENIBackends(reqCtx, mgr, vpcCIDRs, eciBackends, candidates.AddressIPVersion)
 		if err != nil {
-			return nil, fmt.Errorf("update eci backends error: %s", err.Error())
+			return nil, fmt.Errorf("update eci backends error: %w", err)
 		}
 	}
 
@@ -825,7 +865,7 @@ func (mgr *VGroupManager) buildClusterBackends(reqCtx *svcCtx.RequestContext, vp
 	if len(eciBackends) != 0 {
 		eciBackends, err = updateENIBackends(reqCtx, mgr, vpcCIDRs, eciBackends, candidates.AddressIPVersion)
 		if err != nil {
-			return nil, fmt.Errorf("update eci backends error: %s", err.Error())
+			return nil, fmt.Errorf("update eci backends error: %w", err)
 		}
 	}
 
@@ -843,7 +883,7 @@ func updateENIBackends(reqCtx *svcCtx.RequestContext, mgr *VGroupManager, vpcCID
 	}
 	result, err := mgr.cloud.DescribeNetworkInterfaces(mgr.vpcId, ips, ipVersion)
 	if err != nil {
-		return nil, fmt.Errorf("call DescribeNetworkInterfaces: %s", err.Error())
+		return nil, fmt.Errorf("call DescribeNetworkInterfaces: %w", err)
 	}
 
 	var skipIPs []string
@@ -912,6 +952,44 @@ func podNumberAlgorithm(mode helper.TrafficPolicy, backends []model.BackendAttri
 	return backends
 }
 
+// setZoneAwareWeights overrides the weights of the backends by the zone weight policy,
+// the draining backends keep weight 0.
+func setZoneAwareWeights(mode helper.TrafficPolicy, topology *backend.ZoneTopology,
+	backends, initBackends []model.BackendAttribute, weight *int) []model.BackendAttribute {
+	podsOnNode := make(map[string]int)
+	for _, b := range initBackends {
+		if b.NodeName != nil {
+			podsOnNode[*b.NodeName]++
+		}
+	}
+
+	var (
+		idx   []int
+		zones []string
+		units []int
+	)
+	for i, b := range backends {
+		if !b.DrainDeadline.IsZero() {
+			continue
+		}
+		isECS := b.Type == model.ECSBackendType
+		unit := 1
+		if isECS && mode == helper.LocalTrafficPolicy && b.NodeName != nil && podsOnNode[*b.NodeName] > 0 {
+			unit = podsOnNode[*b.NodeName]
+		}
+		idx = append(idx, i)
+		zones = append(zones, topology.BackendZone(isECS, b.ServerId, b.ServerIp, b.NodeName))
+		units = append(units, unit)
+	}
+
+	// in cluster mode the nodes forward the traffic to the pods of any zone
+	weights := topology.Weights(zones, units, weight, mode != helper.ClusterTrafficPolicy)
+	for j, i := range idx {
+		backends[i].Weight = weights[j]
+	}
+	return backends
+}
+
 // podPercentAlgorithm
 /*
 	Calculate node weight by percent.
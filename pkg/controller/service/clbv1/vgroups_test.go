@@ -4,11 +4,17 @@ import (
 	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/backend"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
 	"k8s.io/klog/v2/klogr"
 	"testing"
+	"time"
 )
 
 func TestVGroupManager_BatchSyncVServerGroupBackendServers(t *testing.T) {
@@ -63,3 +69,61 @@ func TestVGroupManager_BatchSyncVServerGroupBackendServers(t *testing.T) {
 
 	assert.Equal(t, len(vgroup.Backends), 200)
 }
+
+func TestDiffDrainingBackends(t *testing.T) {
+	remote := model.VServerGroup{
+		Backends: []model.BackendAttribute{
+			{ServerId: "eni-1", ServerIp: "192.168.0.1", Port: 80, Weight: 100, Type: "eni"},
+			{ServerId: "eni-2", ServerIp: "192.168.0.2", Port: 80, Weight: 100, Type: "eni"},
+		},
+	}
+	local := model.VServerGroup{
+		IgnoreWeightUpdate: true,
+		Backends: []model.BackendAttribute{
+			{ServerId: "eni-1", ServerIp: "192.168.0.1", Port: 80, Weight: 50, Type: "eni"},
+			{ServerId: "eni-2", ServerIp: "192.168.0.2", Port: 80, Weight: 0, Type: "eni",
+				DrainDeadline: time.Now().Add(time.Minute)},
+		},
+	}
+
+	// the weight of a draining backend is updated even if the weight updates are ignored
+	add, del, update := diff(remote, local)
+	assert.Empty(t, add)
+	assert.Empty(t, del)
+	assert.Len(t, update, 1)
+	assert.Equal(t, "eni-2", update[0].ServerId)
+	assert.Equal(t, 0, update[0].Weight)
+}
+
+func TestSetZoneAwareWeights(t *testing.T) {
+	nodeA, nodeB := "node-a", "node-b"
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:        "web",
+		Namespace:   v1.NamespaceDefault,
+		Annotations: map[string]string{annotation.Annotation(annotation.WeightPolicy): backend.WeightPolicyZone},
+	}}
+	candidates := &backend.EndpointWithENI{
+		Nodes: []v1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: nodeA, Labels: map[string]string{v1.LabelTopologyZone: "zone-a"}},
+				Spec: v1.NodeSpec{ProviderID: "cn-hangzhou.i-a"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: nodeB, Labels: map[string]string{v1.LabelTopologyZone: "zone-b"}},
+				Spec: v1.NodeSpec{ProviderID: "cn-hangzhou.i-b"}},
+		},
+	}
+	topology, err := backend.NewZoneTopology(&svcCtx.RequestContext{
+		Service: svc,
+		Anno:    annotation.NewAnnotationRequest(svc),
+		Log:     klogr.New(),
+	}, candidates)
+	assert.NoError(t, err)
+
+	// three pods on node-a and one pod on node-b, each zone takes the same weight
+	initBackends := []model.BackendAttribute{{NodeName: &nodeA}, {NodeName: &nodeA}, {NodeName: &nodeA}, {NodeName: &nodeB}}
+	backends := []model.BackendAttribute{
+		{ServerId: "i-a", Type: model.ECSBackendType, NodeName: &nodeA, Weight: 3},
+		{ServerId: "i-b", Type: model.ECSBackendType, NodeName: &nodeB, Weight: 1},
+	}
+	backends = setZoneAwareWeights(helper.LocalTrafficPolicy, topology, backends, initBackends, nil)
+	assert.Equal(t, 100, backends[0].Weight)
+	assert.Equal(t, 100, backends[1].Weight)
+}
@@ -6,6 +6,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	discovery "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
@@ -148,6 +149,80 @@ func needAdd(newService *v1.Service) bool {
 	return false
 }
 
+// NewEnqueueRequestForSharedGroupEvent enqueues the services of a shared group which lost a port to the changed
+// service, so that they take the port once the service leaves the group or releases the port. The services
+// are listed by the reader, the cache of the manager.
+func NewEnqueueRequestForSharedGroupEvent(client client.Client, reader client.Reader) *enqueueRequestForSharedGroupEvent {
+	return &enqueueRequestForSharedGroupEvent{client: client, reader: reader}
+}
+
+type enqueueRequestForSharedGroupEvent struct {
+	client client.Client
+	reader client.Reader
+}
+
+var _ handler.EventHandler = (*enqueueRequestForSharedGroupEvent)(nil)
+
+func (h *enqueueRequestForSharedGroupEvent) Create(_ context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	// a new service is younger than the members, it never takes their ports
+}
+
+func (h *enqueueRequestForSharedGroupEvent) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldSvc, ok1 := e.ObjectOld.(*v1.Service)
+	newSvc, ok2 := e.ObjectNew.(*v1.Service)
+	if !ok1 || !ok2 {
+		return
+	}
+	if reflect.DeepEqual(oldSvc.Spec, newSvc.Spec) && reflect.DeepEqual(oldSvc.Annotations, newSvc.Annotations) &&
+		oldSvc.DeletionTimestamp.IsZero() == newSvc.DeletionTimestamp.IsZero() {
+		return
+	}
+	h.enqueueConflictedMembers(ctx, queue, oldSvc)
+}
+
+func (h *enqueueRequestForSharedGroupEvent) Delete(ctx context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	// the listeners of the service are deleted before its finalizer is removed
+	svc, ok := e.Object.(*v1.Service)
+	if ok {
+		h.enqueueConflictedMembers(ctx, queue, svc)
+	}
+}
+
+func (h *enqueueRequestForSharedGroupEvent) Generic(_ context.Context, e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+	// unknown event, ignore
+}
+
+func (h *enqueueRequestForSharedGroupEvent) enqueueConflictedMembers(ctx context.Context,
+	queue workqueue.RateLimitingInterface, svc *v1.Service) {
+	group, err := sharedGroupOf(ctx, h.client, svc)
+	if err != nil || group == "" {
+		return
+	}
+	svcs := &v1.ServiceList{}
+	if err := h.reader.List(ctx, svcs); err != nil {
+		util.NLBLog.Error(err, "fail to list services of shared group", "group", group)
+		return
+	}
+	for i := range svcs.Items {
+		member := &svcs.Items[i]
+		if util.Key(member) == util.Key(svc) ||
+			!meta.IsStatusConditionTrue(member.Status.Conditions, SharedPortConflictCondition) {
+			continue
+		}
+		if memberGroup, err := sharedGroupOf(ctx, h.client, member); err != nil || memberGroup != group {
+			continue
+		}
+		queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: member.Namespace,
+				Name:      member.Name,
+			},
+		})
+		util.NLBLog.Info("shared group member changed, enqueue the conflicted member",
+			"service", util.Key(svc), "member", util.Key(member))
+	}
+}
+
 // NewEnqueueRequestForEndpointEvent, event handler for endpoint events
 func NewEnqueueRequestForEndpointEvent(client client.Client, eventRecorder record.EventRecorder) *enqueueRequestForEndpointEvent {
 	return &enqueueRequestForEndpointEvent{
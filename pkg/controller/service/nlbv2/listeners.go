@@ -6,8 +6,10 @@ import (
 	"github.com/alibabacloud-go/tea/tea"
 	"github.com/mohae/deepcopy"
 	v1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/certificate"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
@@ -17,6 +19,7 @@ import (
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strconv"
 	"strings"
 	"time"
@@ -26,14 +29,20 @@ const (
 	errorListenerOperationConflict = "Conflict.Lock"
 )
 
-func NewListenerManager(cloud prvd.Provider) *ListenerManager {
+func NewListenerManager(kubeClient client.Client, cloud prvd.Provider) *ListenerManager {
 	return &ListenerManager{
-		cloud: cloud,
+		kubeClient: kubeClient,
+		cloud:      cloud,
+		certMgr:    certificate.NewManager(kubeClient, certificate.NewCASCertStore(cloud)),
+		caCertMgr:  certificate.NewManager(kubeClient, certificate.NewCASCACertStore(cloud)),
 	}
 }
 
 type ListenerManager struct {
-	cloud prvd.Provider
+	kubeClient client.Client
+	cloud      prvd.Provider
+	certMgr    *certificate.Manager
+	caCertMgr  *certificate.Manager
 }
 
 type listenerActionType string
@@ -106,17 +115,80 @@ func (mgr *ListenerManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, mdl *
 	for _, port := range reqCtx.Service.Spec.Ports {
 		listener, err := mgr.buildListenerFromServicePort(reqCtx, port, mdl.LoadBalancerAttribute.IsUserManaged)
 		if err != nil {
-			return fmt.Errorf("build listener from servicePort %d error: %s", port.Port, err.Error())
+			return fmt.Errorf("build listener from servicePort %d error: %w", port.Port, err)
 		}
 		mdl.Listeners = append(mdl.Listeners, listener)
 	}
 	return checkListenersPortOverlap(mdl.Listeners)
 }
 
+// applySecretCerts uploads the secrets of the cert-secret and cacert-secret annotations to CAS,
+// and sets the certificates to the TCPSSL listeners of the local model.
+func (mgr *ListenerManager) applySecretCerts(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer) error {
+	if needDeleteLoadBalancer(reqCtx.Service) {
+		return nil
+	}
+	certId, err := mgr.syncSecretCert(reqCtx, mgr.certMgr, annotation.CertSecret)
+	if err != nil {
+		return err
+	}
+	caCertId, err := mgr.syncSecretCert(reqCtx, mgr.caCertMgr, annotation.CaCertSecret)
+	if err != nil {
+		return err
+	}
+	for _, listener := range mdl.Listeners {
+		if listener.ListenerProtocol != nlbmodel.TCPSSL {
+			continue
+		}
+		if certId != "" {
+			listener.CertificateIds = []string{certId}
+		}
+		if caCertId != "" {
+			listener.CaCertificateIds = []string{caCertId}
+		}
+	}
+	return nil
+}
+
+// syncSecretCert uploads the secret of the secretAnno annotation to CAS.
+func (mgr *ListenerManager) syncSecretCert(reqCtx *svcCtx.RequestContext, certMgr *certificate.Manager,
+	secretAnno string) (string, error) {
+	secretName := reqCtx.Anno.Get(secretAnno)
+	if secretName == "" {
+		return "", nil
+	}
+	return certMgr.Sync(reqCtx.Ctx, reqCtx.Service, secretName)
+}
+
+// GarbageCollectSecretCerts deletes the certificates uploaded for the service which the
+// listeners no longer use. All of them are deleted when the service is deleted or the
+// annotation is removed.
+func (mgr *ListenerManager) GarbageCollectSecretCerts(reqCtx *svcCtx.RequestContext) error {
+	// removing an annotation changes the service hash, skip listing the certificates otherwise
+	hashChanged := helper.IsServiceHashChanged(reqCtx.Service, reqCtx.Anno.Config())
+	var errs []error
+	for certMgr, anno := range map[*certificate.Manager]string{
+		mgr.certMgr:   annotation.CertSecret,
+		mgr.caCertMgr: annotation.CaCertSecret,
+	} {
+		secretName := reqCtx.Anno.Get(anno)
+		if secretName == "" && !needDeleteLoadBalancer(reqCtx.Service) && !hashChanged {
+			continue
+		}
+		if needDeleteLoadBalancer(reqCtx.Service) {
+			secretName = ""
+		}
+		if err := certMgr.GarbageCollect(reqCtx.Ctx, reqCtx.Service, secretName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 func (mgr *ListenerManager) BuildRemoteModel(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer) error {
 	listeners, err := mgr.ListListeners(reqCtx, mdl.LoadBalancerAttribute.LoadBalancerId)
 	if err != nil {
-		return fmt.Errorf("DescribeNLBListeners error:%s", err.Error())
+		return fmt.Errorf("DescribeNLBListeners error:%w", err)
 	}
 	mdl.Listeners = listeners
 	return nil
@@ -124,22 +196,24 @@ func (mgr *ListenerManager) BuildRemoteModel(reqCtx *svcCtx.RequestContext, mdl
 
 func (mgr *ListenerManager) buildListenerFromServicePort(reqCtx *svcCtx.RequestContext, port v1.ServicePort,
 	isUserManagedLB bool) (*nlbmodel.ListenerAttribute, error) {
+	// the listener attributes may be overridden for the port by the LoadBalancerConfig
+	anno := reqCtx.Anno.ForPort(port.Port)
 	listener := &nlbmodel.ListenerAttribute{
 		ServicePort:  &port,
 		ListenerPort: port.Port,
 	}
 
-	proto, err := nlbListenerProtocol(reqCtx.Anno.Get(annotation.ProtocolPort), port)
+	proto, err := nlbListenerProtocol(anno.Get(annotation.ProtocolPort), port)
 	if err != nil {
 		return listener, err
 	}
 	listener.ListenerProtocol = proto
 
-	if reqCtx.Anno.Get(annotation.ListenerPortRange) != "" {
+	if anno.Get(annotation.ListenerPortRange) != "" {
 		if !helper.IsENIBackendType(reqCtx.Service) {
 			return listener, fmt.Errorf("listener port range can only be used for eni backend type service")
 		}
-		startPort, endPort, err := portRange(reqCtx.Anno.Get(annotation.ListenerPortRange), port)
+		startPort, endPort, err := portRange(anno.Get(annotation.ListenerPortRange), port)
 		if err != nil {
 			return listener, err
 		}
@@ -170,62 +244,62 @@ func (mgr *ListenerManager) buildListenerFromServicePort(reqCtx *svcCtx.RequestC
 		listener.ServerGroupName = getAnyPortServerGroupNamedKey(reqCtx.Service, proto, listener.StartPort, listener.EndPort).Key()
 	}
 
-	if isUserManagedLB && reqCtx.Anno.Get(annotation.VGroupPort) != "" {
-		serverGroupId, err := serverGroup(reqCtx.Anno.Get(annotation.VGroupPort), port)
+	if isUserManagedLB && anno.Get(annotation.VGroupPort) != "" {
+		serverGroupId, err := serverGroup(anno.Get(annotation.VGroupPort), port)
 		if err != nil {
 			return listener, err
 		}
 		listener.ServerGroupId = serverGroupId
 	}
 
-	if reqCtx.Anno.Get(annotation.IdleTimeout) != "" {
-		idleTimeout, err := strconv.Atoi(reqCtx.Anno.Get(annotation.IdleTimeout))
+	if anno.Get(annotation.IdleTimeout) != "" {
+		idleTimeout, err := strconv.Atoi(anno.Get(annotation.IdleTimeout))
 		if err != nil {
-			return listener, fmt.Errorf("parse IdleTimeout error: %s", err.Error())
+			return listener, fmt.Errorf("parse IdleTimeout error: %w", err)
 		}
 		listener.IdleTimeout = int32(idleTimeout)
 	}
-	if reqCtx.Anno.Get(annotation.TLSCipherPolicy) != "" {
-		listener.SecurityPolicyId = reqCtx.Anno.Get(annotation.TLSCipherPolicy)
+	if anno.Get(annotation.TLSCipherPolicy) != "" {
+		listener.SecurityPolicyId = anno.Get(annotation.TLSCipherPolicy)
 	}
 
-	if reqCtx.Anno.Get(annotation.ProxyProtocol) != "" {
-		listener.ProxyProtocolEnabled = tea.Bool(strings.EqualFold(reqCtx.Anno.Get(annotation.ProxyProtocol), string(model.OnFlag)))
+	if anno.Get(annotation.ProxyProtocol) != "" {
+		listener.ProxyProtocolEnabled = tea.Bool(strings.EqualFold(anno.Get(annotation.ProxyProtocol), string(model.OnFlag)))
 	}
-	if reqCtx.Anno.Get(annotation.CertID) != "" {
-		listener.CertificateIds = strings.Split(reqCtx.Anno.Get(annotation.CertID), ",")
+	if anno.Get(annotation.CertID) != "" {
+		listener.CertificateIds = strings.Split(anno.Get(annotation.CertID), ",")
 	}
-	if reqCtx.Anno.Get(annotation.CaCertID) != "" {
-		listener.CaCertificateIds = strings.Split(reqCtx.Anno.Get(annotation.CaCertID), ",")
+	if anno.Get(annotation.CaCertID) != "" {
+		listener.CaCertificateIds = strings.Split(anno.Get(annotation.CaCertID), ",")
 	}
-	if reqCtx.Anno.Get(annotation.CaCert) != "" {
-		listener.CaEnabled = tea.Bool(strings.EqualFold(reqCtx.Anno.Get(annotation.CaCert), string(model.OnFlag)))
+	if anno.Get(annotation.CaCert) != "" {
+		listener.CaEnabled = tea.Bool(strings.EqualFold(anno.Get(annotation.CaCert), string(model.OnFlag)))
 	}
-	if reqCtx.Anno.Get(annotation.Cps) != "" {
-		cps, err := strconv.Atoi(reqCtx.Anno.Get(annotation.Cps))
+	if anno.Get(annotation.Cps) != "" {
+		cps, err := strconv.Atoi(anno.Get(annotation.Cps))
 		if err != nil {
-			return listener, fmt.Errorf("parse Mss error: %s", err.Error())
+			return listener, fmt.Errorf("parse Mss error: %w", err)
 		}
 		listener.Cps = tea.Int32(int32(cps))
 	}
 
-	if reqCtx.Anno.Get(annotation.Ppv2PrivateLinkEpIdEnabled) != "" {
-		listener.ProxyProtocolV2Config.PrivateLinkEpIdEnabled = tea.Bool(strings.EqualFold(reqCtx.Anno.Get(annotation.Ppv2PrivateLinkEpIdEnabled), string(model.OnFlag)))
+	if anno.Get(annotation.Ppv2PrivateLinkEpIdEnabled) != "" {
+		listener.ProxyProtocolV2Config.PrivateLinkEpIdEnabled = tea.Bool(strings.EqualFold(anno.Get(annotation.Ppv2PrivateLinkEpIdEnabled), string(model.OnFlag)))
 	}
-	if reqCtx.Anno.Get(annotation.Ppv2PrivateLinkEpsIdEnabled) != "" {
-		listener.ProxyProtocolV2Config.PrivateLinkEpsIdEnabled = tea.Bool(strings.EqualFold(reqCtx.Anno.Get(annotation.Ppv2PrivateLinkEpsIdEnabled), string(model.OnFlag)))
+	if anno.Get(annotation.Ppv2PrivateLinkEpsIdEnabled) != "" {
+		listener.ProxyProtocolV2Config.PrivateLinkEpsIdEnabled = tea.Bool(strings.EqualFold(anno.Get(annotation.Ppv2PrivateLinkEpsIdEnabled), string(model.OnFlag)))
 	}
-	if reqCtx.Anno.Get(annotation.Ppv2VpcIdEnabled) != "" {
-		listener.ProxyProtocolV2Config.VpcIdEnabled = tea.Bool(strings.EqualFold(reqCtx.Anno.Get(annotation.Ppv2VpcIdEnabled), string(model.OnFlag)))
+	if anno.Get(annotation.Ppv2VpcIdEnabled) != "" {
+		listener.ProxyProtocolV2Config.VpcIdEnabled = tea.Bool(strings.EqualFold(anno.Get(annotation.Ppv2VpcIdEnabled), string(model.OnFlag)))
 	}
 
 	if listener.ListenerProtocol == nlbmodel.TCPSSL {
-		if reqCtx.Anno.Get(annotation.AlpnEnabled) != "" {
-			listener.AlpnEnabled = tea.Bool(strings.EqualFold(reqCtx.Anno.Get(annotation.AlpnEnabled), string(model.OnFlag)))
+		if anno.Get(annotation.AlpnEnabled) != "" {
+			listener.AlpnEnabled = tea.Bool(strings.EqualFold(anno.Get(annotation.AlpnEnabled), string(model.OnFlag)))
 		}
 
-		if reqCtx.Anno.Get(annotation.AlpnPolicy) != "" {
-			listener.AlpnPolicy = reqCtx.Anno.Get(annotation.AlpnPolicy)
+		if anno.Get(annotation.AlpnPolicy) != "" {
+			listener.AlpnPolicy = anno.Get(annotation.AlpnPolicy)
 		}
 	}
 
@@ -302,7 +376,7 @@ func (mgr *ListenerManager) CreateListener(reqCtx *svcCtx.RequestContext, lbId s
 func (mgr *ListenerManager) UpdateNLBListener(reqCtx *svcCtx.RequestContext, local, remote *nlbmodel.ListenerAttribute) (string, error) {
 	if remote.ListenerStatus == nlbmodel.StoppedListenerStatus {
 		if err := mgr.cloud.StartNLBListener(reqCtx.Ctx, remote.ListenerId); err != nil {
-			return "", fmt.Errorf("start listener %s error: %s", remote.ListenerId, err.Error())
+			return "", fmt.Errorf("start listener %s error: %w", remote.ListenerId, err)
 		}
 	}
 
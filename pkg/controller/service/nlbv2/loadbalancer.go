@@ -8,10 +8,13 @@ import (
 
 	"github.com/alibabacloud-go/tea/tea"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/acl"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/eip"
 	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
@@ -22,12 +25,16 @@ func NewNLBManager(cloud prvd.Provider) *NLBManager {
 	return &NLBManager{
 		cloud:      cloud,
 		tokenCache: cmap.New(),
+		aclMgr:     acl.NewNLBManager(cloud),
+		eipMgr:     eip.NewManagerWithTags(cloud, defaultLoadBalancerTags),
 	}
 }
 
 type NLBManager struct {
 	cloud      prvd.Provider
 	tokenCache cmap.ConcurrentMap
+	aclMgr     *acl.NLBManager
+	eipMgr     *eip.Manager
 }
 
 func (mgr *NLBManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer) error {
@@ -92,14 +99,14 @@ func (mgr *NLBManager) Find(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.Network
 
 	// 2. set default loadbalancer name
 	// it's safe to set loadbalancer name which will be overwritten in FindLoadBalancer func
-	mdl.LoadBalancerAttribute.Name = reqCtx.Anno.GetDefaultLoadBalancerName()
+	mdl.LoadBalancerAttribute.Name = defaultLoadBalancerName(reqCtx.Anno)
 
 	// 3. set default loadbalancer tag
 	// filter tags using logic operator OR, so only TAGKEY tag can be added
 	mdl.LoadBalancerAttribute.Tags = []tag.Tag{
 		{
 			Key:   helper.TAGKEY,
-			Value: reqCtx.Anno.GetDefaultLoadBalancerName(),
+			Value: defaultLoadBalancerName(reqCtx.Anno),
 		},
 	}
 
@@ -109,10 +116,10 @@ func (mgr *NLBManager) Find(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.Network
 
 func (mgr *NLBManager) Create(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer) error {
 	if err := setDefaultValueForLoadBalancer(mgr, mdl, reqCtx.Anno); err != nil {
-		return fmt.Errorf("set model default value error: %s", err.Error())
+		return fmt.Errorf("set model default value error: %w", err)
 	}
 
-	key := reqCtx.Anno.GetDefaultLoadBalancerName()
+	key := defaultLoadBalancerName(reqCtx.Anno)
 	clientToken := ""
 	if t, ok := mgr.tokenCache.Get(key); ok {
 		clientToken = t.(string)
@@ -138,10 +145,10 @@ func (mgr *NLBManager) Delete(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.Netwo
 	err := mgr.cloud.UpdateLoadBalancerProtection(reqCtx.Ctx, mdl.LoadBalancerAttribute.LoadBalancerId,
 		&nlbmodel.DeletionProtectionConfig{Enabled: false}, nil)
 	if err != nil {
-		return fmt.Errorf("disable delete protection error: %s", err.Error())
+		return fmt.Errorf("disable delete protection error: %w", err)
 	}
 
-	key := reqCtx.Anno.GetDefaultLoadBalancerName()
+	key := defaultLoadBalancerName(reqCtx.Anno)
 	mgr.tokenCache.Remove(key)
 	return mgr.cloud.DeleteNLB(reqCtx.Ctx, mdl)
 }
@@ -170,7 +177,7 @@ func (mgr *NLBManager) Update(reqCtx *svcCtx.RequestContext, local, remote *nlbm
 		reqCtx.Log.Info(fmt.Sprintf("AddressType changed from [%s] to [%s]",
 			local.LoadBalancerAttribute.AddressType, remote.LoadBalancerAttribute.AddressType))
 		if err := mgr.cloud.UpdateNLBAddressType(reqCtx.Ctx, local); err != nil {
-			errs = append(errs, fmt.Errorf("UpdateNLBAddressType error: %s", err.Error()))
+			errs = append(errs, fmt.Errorf("UpdateNLBAddressType error: %w", err))
 		}
 	}
 
@@ -188,21 +195,24 @@ func (mgr *NLBManager) Update(reqCtx *svcCtx.RequestContext, local, remote *nlbm
 			reqCtx.Log.Info(fmt.Sprintf("ZoneMappings changed from [%s] to [%s]",
 				remote.LoadBalancerAttribute.ZoneMappings, local.LoadBalancerAttribute.ZoneMappings))
 			if err := mgr.cloud.UpdateNLBZones(reqCtx.Ctx, local); err != nil {
-				errs = append(errs, fmt.Errorf("update zone mappings error: %s", err.Error()))
+				errs = append(errs, fmt.Errorf("update zone mappings error: %w", err))
 			}
 			break
 		}
 	}
 
-	if local.LoadBalancerAttribute.SecurityGroupIds != nil &&
-		!util.IsStringSliceEqual(local.LoadBalancerAttribute.SecurityGroupIds, remote.LoadBalancerAttribute.SecurityGroupIds) {
+	securityGroupIds, staleAclSecurityGroupIds, err := mgr.desiredSecurityGroupIds(reqCtx, local, remote)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("get stale acl security groups error: %w", err))
+	} else if securityGroupIds != nil &&
+		!util.IsStringSliceEqual(securityGroupIds, remote.LoadBalancerAttribute.SecurityGroupIds) {
 		reqCtx.Log.Info(fmt.Sprintf("SecurityGroupIds changed from %v to %v",
-			remote.LoadBalancerAttribute.SecurityGroupIds, local.LoadBalancerAttribute.SecurityGroupIds))
+			remote.LoadBalancerAttribute.SecurityGroupIds, securityGroupIds))
 		// get difference
 		var added, removed []string
 		newMap := map[string]struct{}{}
 		oldMap := map[string]struct{}{}
-		for _, i := range local.LoadBalancerAttribute.SecurityGroupIds {
+		for _, i := range securityGroupIds {
 			newMap[i] = struct{}{}
 		}
 		for _, i := range remote.LoadBalancerAttribute.SecurityGroupIds {
@@ -211,7 +221,7 @@ func (mgr *NLBManager) Update(reqCtx *svcCtx.RequestContext, local, remote *nlbm
 				removed = append(removed, i)
 			}
 		}
-		for _, i := range local.LoadBalancerAttribute.SecurityGroupIds {
+		for _, i := range securityGroupIds {
 			if _, ok := oldMap[i]; !ok {
 				added = append(added, i)
 			}
@@ -219,7 +229,9 @@ func (mgr *NLBManager) Update(reqCtx *svcCtx.RequestContext, local, remote *nlbm
 
 		reqCtx.Log.Info(fmt.Sprintf("security groups added %v, removed %v", added, removed))
 		if err := mgr.cloud.UpdateNLBSecurityGroupIds(reqCtx.Ctx, local, added, removed); err != nil {
-			errs = append(errs, fmt.Errorf("update security group ids error: %s", err.Error()))
+			errs = append(errs, fmt.Errorf("update security group ids error: %w", err))
+		} else if err := mgr.aclMgr.Delete(reqCtx, staleAclSecurityGroupIds); err != nil {
+			reqCtx.Log.Error(err, "delete stale acl security groups failed")
 		}
 	}
 
@@ -228,7 +240,7 @@ func (mgr *NLBManager) Update(reqCtx *svcCtx.RequestContext, local, remote *nlbm
 		reqCtx.Log.Info(fmt.Sprintf("IPv6AddressType changed from [%s] to [%s]",
 			remote.LoadBalancerAttribute.IPv6AddressType, local.LoadBalancerAttribute.IPv6AddressType))
 		if err := mgr.cloud.UpdateNLBIPv6AddressType(reqCtx.Ctx, local); err != nil {
-			errs = append(errs, fmt.Errorf("UpdateNLBIPv6AddressType error: %s", err.Error()))
+			errs = append(errs, fmt.Errorf("UpdateNLBIPv6AddressType error: %w", err))
 		}
 	}
 
@@ -252,6 +264,170 @@ func (mgr *NLBManager) Update(reqCtx *svcCtx.RequestContext, local, remote *nlbm
 	return utilerrors.NewAggregate(errs)
 }
 
+// desiredSecurityGroupIds returns the security groups the nlb should join, or nil to keep the current ones.
+// The security group created for the acl cidrs of the service is joined along with the ones of the annotation,
+// and the ones created for the cidrs the service no longer declares are left, which are returned to be deleted.
+func (mgr *NLBManager) desiredSecurityGroupIds(reqCtx *svcCtx.RequestContext, local, remote *nlbmodel.NetworkLoadBalancer,
+) ([]string, []string, error) {
+	ids := local.LoadBalancerAttribute.SecurityGroupIds
+	aclSecurityGroupId := local.LoadBalancerAttribute.AclSecurityGroupId
+	if aclSecurityGroupId != "" {
+		if ids == nil {
+			ids = remote.LoadBalancerAttribute.SecurityGroupIds
+		}
+		if !sets.NewString(ids...).Has(aclSecurityGroupId) {
+			ids = append(append([]string{}, ids...), aclSecurityGroupId)
+		}
+		return ids, nil, nil
+	}
+
+	// only the joined security groups not in the annotation may be the stale ones
+	candidates := sets.NewString(remote.LoadBalancerAttribute.SecurityGroupIds...).
+		Difference(sets.NewString(ids...))
+	if acl.Declared(reqCtx.Anno) || candidates.Len() == 0 {
+		return ids, nil, nil
+	}
+	sgs, err := mgr.aclMgr.List(reqCtx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var stale []string
+	for _, sg := range sgs {
+		if candidates.Has(sg.SecurityGroupId) {
+			stale = append(stale, sg.SecurityGroupId)
+		}
+	}
+	if len(stale) == 0 {
+		return ids, nil, nil
+	}
+	if ids == nil {
+		ids = []string{}
+		for _, id := range remote.LoadBalancerAttribute.SecurityGroupIds {
+			if !sets.NewString(stale...).Has(id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, stale, nil
+}
+
+// buildAclRules sets the rules of the security group for the acl cidrs of the service on the ports of the
+// listeners of the local model.
+func (mgr *NLBManager) buildAclRules(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer) error {
+	rules, err := acl.GetNLBRules(reqCtx.Anno, mdl)
+	if err != nil {
+		return err
+	}
+	mdl.LoadBalancerAttribute.AclRules = rules
+	return nil
+}
+
+// syncAclSecurityGroup keeps the rules of the security group created for the acl cidrs of the service
+// as the ones of the local model, which the nlb joins.
+func (mgr *NLBManager) syncAclSecurityGroup(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer) error {
+	if len(mdl.LoadBalancerAttribute.AclRules) == 0 {
+		return nil
+	}
+	sgId, err := mgr.aclMgr.Sync(reqCtx, mdl.LoadBalancerAttribute.AclRules, mdl.LoadBalancerAttribute.VpcId)
+	if err != nil {
+		return err
+	}
+	mdl.LoadBalancerAttribute.AclSecurityGroupId = sgId
+	return nil
+}
+
+// syncEips allocates an eip for each zone of the local model if the service asks for it, and binds them to
+// the zone mappings, which turns the nlb to internet. The eips of a shared nlb are tagged with the nlb, so that
+// they are found by whichever member owns it.
+func (mgr *NLBManager) syncEips(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer) error {
+	opts, err := eip.GetNLBOptions(reqCtx.Anno, mdl)
+	if err != nil || opts == nil || len(mdl.LoadBalancerAttribute.ZoneMappings) == 0 {
+		return err
+	}
+	var zoneIds []string
+	for _, z := range mdl.LoadBalancerAttribute.ZoneMappings {
+		zoneIds = append(zoneIds, z.ZoneId)
+	}
+	allocationIds, err := mgr.eipMgr.SyncZones(reqCtx, opts, zoneIds)
+	if err != nil {
+		return err
+	}
+	for i := range mdl.LoadBalancerAttribute.ZoneMappings {
+		z := &mdl.LoadBalancerAttribute.ZoneMappings[i]
+		z.AllocationId = allocationIds[z.ZoneId]
+	}
+	mdl.LoadBalancerAttribute.AddressType = nlbmodel.InternetAddressType
+	return nil
+}
+
+// EipAddresses returns the addresses of the eips allocated for the service which are bound to the nlb.
+func (mgr *NLBManager) EipAddresses(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer) ([]string, error) {
+	if !eip.Declared(reqCtx.Anno) && reqCtx.Anno.Get(annotation.SharedGroup) == "" {
+		return nil, nil
+	}
+	eips, err := mgr.eipMgr.List(reqCtx)
+	if err != nil {
+		return nil, err
+	}
+	bound := sets.NewString()
+	for _, z := range mdl.LoadBalancerAttribute.ZoneMappings {
+		bound.Insert(z.AllocationId)
+	}
+	var addresses []string
+	for _, e := range eips {
+		if bound.Has(e.AllocationId) {
+			addresses = append(addresses, e.IpAddress)
+		}
+	}
+	return addresses, nil
+}
+
+// GarbageCollectEips releases the eips allocated for the service when it is deleted. The ones bound to the nlb
+// which is kept, as it is reused or preserved, are left. The eips of a shared nlb are kept until the last member
+// of the group deletes the nlb.
+func (mgr *NLBManager) GarbageCollectEips(reqCtx *svcCtx.RequestContext, remote *nlbmodel.NetworkLoadBalancer) error {
+	if reqCtx.Anno.Get(annotation.SharedGroup) != "" && remote != nil &&
+		remote.LoadBalancerAttribute.LoadBalancerId != "" {
+		reqCtx.Log.Info(fmt.Sprintf("nlb %s is shared with other services, skip releasing its eips",
+			remote.LoadBalancerAttribute.LoadBalancerId))
+		return nil
+	}
+	keep := map[string]bool{}
+	if remote != nil && (reqCtx.Anno.Get(annotation.LoadBalancerId) != "" ||
+		reqCtx.Anno.Get(annotation.PreserveLBOnDelete) != "") {
+		for _, z := range remote.LoadBalancerAttribute.ZoneMappings {
+			if z.AllocationId != "" {
+				keep[z.AllocationId] = true
+			}
+		}
+	}
+	return mgr.eipMgr.GarbageCollect(reqCtx, keep)
+}
+
+// GarbageCollectAclSecurityGroups deletes the security groups created for the acl cidrs of the service when it
+// is deleted. The nlb which is kept, as it is reused, preserved or shared, leaves them first.
+func (mgr *NLBManager) GarbageCollectAclSecurityGroups(reqCtx *svcCtx.RequestContext, remote *nlbmodel.NetworkLoadBalancer) error {
+	sgs, err := mgr.aclMgr.List(reqCtx)
+	if err != nil || len(sgs) == 0 {
+		return err
+	}
+	var ids, joined []string
+	for _, sg := range sgs {
+		ids = append(ids, sg.SecurityGroupId)
+		if remote != nil && remote.LoadBalancerAttribute.LoadBalancerId != "" &&
+			sets.NewString(remote.LoadBalancerAttribute.SecurityGroupIds...).Has(sg.SecurityGroupId) {
+			joined = append(joined, sg.SecurityGroupId)
+		}
+	}
+	if len(joined) != 0 {
+		reqCtx.Log.Info(fmt.Sprintf("leave acl security groups %v", joined))
+		if err := mgr.cloud.UpdateNLBSecurityGroupIds(reqCtx.Ctx, remote, nil, joined); err != nil {
+			return fmt.Errorf("leave acl security groups error: %w", err)
+		}
+	}
+	return mgr.aclMgr.Delete(reqCtx, ids)
+}
+
 func (mgr *NLBManager) updateLoadBalancerTags(reqCtx *svcCtx.RequestContext, local, remote *nlbmodel.NetworkLoadBalancer) error {
 	lbId := remote.LoadBalancerAttribute.LoadBalancerId
 
@@ -303,7 +479,7 @@ func (mgr *NLBManager) CleanupLoadBalancerTags(reqCtx *svcCtx.RequestContext, re
 		return nil
 	}
 
-	defaultTags := reqCtx.Anno.GetDefaultTags()
+	defaultTags := defaultLoadBalancerTags(reqCtx.Anno)
 	var removedTags []*string
 	for _, r := range remote.LoadBalancerAttribute.Tags {
 		for _, l := range defaultTags {
@@ -362,13 +538,13 @@ func setDefaultValueForLoadBalancer(mgr *NLBManager, mdl *nlbmodel.NetworkLoadBa
 	}
 
 	if mdl.LoadBalancerAttribute.Name == "" {
-		mdl.LoadBalancerAttribute.Name = anno.GetDefaultLoadBalancerName()
+		mdl.LoadBalancerAttribute.Name = defaultLoadBalancerName(anno)
 	}
 
 	if mdl.LoadBalancerAttribute.VpcId == "" {
 		vpcId, err := mgr.cloud.VpcID()
 		if err != nil {
-			return fmt.Errorf("get vpc id error: %s", err.Error())
+			return fmt.Errorf("get vpc id error: %w", err)
 		}
 		mdl.LoadBalancerAttribute.VpcId = vpcId
 	}
@@ -390,7 +566,7 @@ func setDefaultValueForLoadBalancer(mgr *NLBManager, mdl *nlbmodel.NetworkLoadBa
 		}
 	}
 
-	mdl.LoadBalancerAttribute.Tags = append(anno.GetDefaultTags(), mdl.LoadBalancerAttribute.Tags...)
+	mdl.LoadBalancerAttribute.Tags = append(defaultLoadBalancerTags(anno), mdl.LoadBalancerAttribute.Tags...)
 	return nil
 }
 
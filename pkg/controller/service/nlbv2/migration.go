@@ -0,0 +1,34 @@
+package nlbv2
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/vpc"
+)
+
+// needDeleteLoadBalancer returns whether the nlb of the service should be deleted. Besides the deleted
+// services, the nlb is deleted when the service is handed back to the clb controller, e.g. the migration
+// from clb to nlb is aborted before the cut-over.
+func needDeleteLoadBalancer(svc *v1.Service) bool {
+	return helper.NeedDeleteLoadBalancer(svc) || (helper.NeedCLB(svc) && !helper.NeedNLB(svc))
+}
+
+// isNLBIngress returns whether the ingress is not published by the clb controller during the migration.
+// The clb is found by the id in the labels of the service, which belong to the clb controller until the
+// clb is released.
+func (m *ReconcileNLB) isNLBIngress(reqCtx *svcCtx.RequestContext) (func(v1.LoadBalancerIngress) bool, error) {
+	var clbId string
+	if helper.IsMigratingToNLB(reqCtx.Service) || helper.IsMigrationAborted(reqCtx.Service) {
+		clbId = reqCtx.Service.Labels[helper.LabelLoadBalancerId]
+	}
+	addresses, err := helper.CLBAddresses(reqCtx.Ctx, m.cloud, string(vpc.SlbInstance), clbId)
+	if err != nil {
+		return nil, err
+	}
+	hostname := reqCtx.Anno.Get(annotation.HostName)
+	return func(ing v1.LoadBalancerIngress) bool {
+		return !helper.IsCLBIngress(ing, addresses, hostname)
+	}, nil
+}
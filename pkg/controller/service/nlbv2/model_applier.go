@@ -8,6 +8,7 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/certificate"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
 	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
@@ -39,7 +40,7 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *nlbmodel.Netw
 
 	err := m.nlbMgr.BuildRemoteModel(reqCtx, remote)
 	if err != nil {
-		return remote, fmt.Errorf("get nlb attribute from cloud error: %s", err.Error())
+		return remote, fmt.Errorf("get nlb attribute from cloud error: %w", err)
 	}
 	reqCtx.Ctx = context.WithValue(reqCtx.Ctx, dryrun.ContextNLB, remote.GetLoadBalancerId())
 
@@ -48,14 +49,15 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *nlbmodel.Netw
 			"The lb [%s] will be preserved after the service is deleted.", remote.LoadBalancerAttribute.LoadBalancerId)
 	}
 
-	serviceHashChanged := helper.IsServiceHashChanged(reqCtx.Service)
+	// the hash label belongs to the clb controller during the migration
+	serviceHashChanged := helper.IsServiceHashChanged(reqCtx.Service, reqCtx.Anno.Config()) || helper.IsMigratingToNLB(reqCtx.Service)
 	errs := []error{}
 	if serviceHashChanged || ctrlCfg.ControllerCFG.DryRun {
 		if err := m.applyLoadBalancerAttribute(reqCtx, local, remote); err != nil {
 			_, ok := err.(utilerrors.Aggregate)
 			if ok {
 				// if lb attr update failed, continue to sync vgroup & listener
-				errs = append(errs, fmt.Errorf("update nlb attribute error: %s", err.Error()))
+				errs = append(errs, fmt.Errorf("update nlb attribute error: %w", err))
 			} else {
 				return nil, err
 			}
@@ -63,35 +65,40 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *nlbmodel.Netw
 	}
 
 	if err := m.sgMgr.BuildRemoteModel(reqCtx, remote); err != nil {
-		errs = append(errs, fmt.Errorf("get server group from remote error: %s", err.Error()))
+		errs = append(errs, fmt.Errorf("get server group from remote error: %w", err))
 		return remote, utilerrors.NewAggregate(errs)
 	}
 	if err := m.applyVGroups(reqCtx, local, remote); err != nil {
-		errs = append(errs, fmt.Errorf("reconcile backends error: %s", err.Error()))
+		errs = append(errs, fmt.Errorf("reconcile backends error: %w", err))
 		return remote, utilerrors.NewAggregate(errs)
 	}
 
-	if serviceHashChanged || ctrlCfg.ControllerCFG.DryRun {
+	// listeners with certs of secrets are synced every time to catch the secret rotation
+	if serviceHashChanged || certificate.UseSecret(reqCtx.Anno) || ctrlCfg.ControllerCFG.DryRun {
 		if remote.LoadBalancerAttribute.LoadBalancerId != "" {
 			if err := m.lisMgr.BuildRemoteModel(reqCtx, remote); err != nil {
-				errs = append(errs, fmt.Errorf("get lb listeners from cloud, error: %s", err.Error()))
+				errs = append(errs, fmt.Errorf("get lb listeners from cloud, error: %w", err))
+				return remote, utilerrors.NewAggregate(errs)
+			}
+			if err := m.lisMgr.applySecretCerts(reqCtx, local); err != nil {
+				errs = append(errs, fmt.Errorf("sync certificates of secrets error: %w", err))
 				return remote, utilerrors.NewAggregate(errs)
 			}
 			if err := m.applyListeners(reqCtx, local, remote); err != nil {
-				errs = append(errs, fmt.Errorf("reconcile listeners error: %s", err.Error()))
+				errs = append(errs, fmt.Errorf("reconcile listeners error: %w", err))
 				return remote, utilerrors.NewAggregate(errs)
 			}
 		} else {
-			if !helper.NeedDeleteLoadBalancer(reqCtx.Service) {
+			if !needDeleteLoadBalancer(reqCtx.Service) {
 				errs = append(errs, fmt.Errorf("alicloud: can not find loadbalancer by tag [%s:%s]",
-					helper.TAGKEY, reqCtx.Anno.GetDefaultLoadBalancerName()))
+					helper.TAGKEY, defaultLoadBalancerName(reqCtx.Anno)))
 				return remote, utilerrors.NewAggregate(errs)
 			}
 		}
 	}
 
 	if err := m.cleanup(reqCtx, local, remote); err != nil {
-		errs = append(errs, fmt.Errorf("update lb listeners error: %s", err.Error()))
+		errs = append(errs, fmt.Errorf("update lb listeners error: %w", err))
 		return remote, utilerrors.NewAggregate(errs)
 	}
 
@@ -109,7 +116,12 @@ func (m *ModelApplier) applyLoadBalancerAttribute(reqCtx *svcCtx.RequestContext,
 	}
 
 	// delete nlb
-	if helper.NeedDeleteLoadBalancer(reqCtx.Service) {
+	if needDeleteLoadBalancer(reqCtx.Service) {
+		if len(local.LoadBalancerAttribute.SharedMembers) != 0 {
+			reqCtx.Log.Info(fmt.Sprintf("nlb %s is shared with %v, skip delete it",
+				remote.LoadBalancerAttribute.LoadBalancerId, local.LoadBalancerAttribute.SharedMembers))
+			return nil
+		}
 		if !local.LoadBalancerAttribute.IsUserManaged {
 			if local.LoadBalancerAttribute.PreserveOnDelete {
 				err := m.nlbMgr.SetProtectionsOff(reqCtx, remote)
@@ -141,6 +153,29 @@ func (m *ModelApplier) applyLoadBalancerAttribute(reqCtx *svcCtx.RequestContext,
 		return nil
 	}
 
+	// the other members of the shared group manage their own listeners only
+	if !isSharedOwner(reqCtx, local) {
+		owner := local.LoadBalancerAttribute.SharedOwner
+		if remote.LoadBalancerAttribute.LoadBalancerId == "" {
+			return fmt.Errorf("wait for %s, the owner of shared group %s, to create the nlb",
+				owner, local.LoadBalancerAttribute.SharedGroup)
+		}
+		if conflicts := sharedAttributeConflicts(local, remote); len(conflicts) != 0 {
+			reqCtx.Recorder.Eventf(reqCtx.Service, v1.EventTypeWarning, helper.SharedAttributeConflict,
+				"The attributes %v of the shared nlb [%s] are set by its owner %s, the ones of the service are ignored.",
+				conflicts, remote.LoadBalancerAttribute.LoadBalancerId, owner)
+		}
+		return nil
+	}
+
+	if err := m.nlbMgr.syncAclSecurityGroup(reqCtx, local); err != nil {
+		return fmt.Errorf("sync acl security group error: %w", err)
+	}
+
+	if err := m.nlbMgr.syncEips(reqCtx, local); err != nil {
+		return fmt.Errorf("sync eips error: %w", err)
+	}
+
 	// create nlb
 	if remote.LoadBalancerAttribute.LoadBalancerId == "" {
 		if helper.IsServiceOwnIngress(reqCtx.Service) {
@@ -149,7 +184,7 @@ func (m *ModelApplier) applyLoadBalancerAttribute(reqCtx *svcCtx.RequestContext,
 		}
 
 		if err := m.nlbMgr.Create(reqCtx, local); err != nil {
-			return fmt.Errorf("create nlb error: %s", err.Error())
+			return fmt.Errorf("create nlb error: %w", err)
 		}
 		reqCtx.Log.Info(fmt.Sprintf("successfully create lb %s", local.LoadBalancerAttribute.LoadBalancerId))
 		// update remote model
@@ -161,6 +196,7 @@ func (m *ModelApplier) applyLoadBalancerAttribute(reqCtx *svcCtx.RequestContext,
 		// need update nlb security groups
 		// or ipv6 address type
 		if len(local.LoadBalancerAttribute.SecurityGroupIds) != 0 ||
+			local.LoadBalancerAttribute.AclSecurityGroupId != "" ||
 			local.LoadBalancerAttribute.IPv6AddressType != "" {
 			err := m.nlbMgr.Update(reqCtx, local, remote)
 			if err != nil {
@@ -173,12 +209,12 @@ func (m *ModelApplier) applyLoadBalancerAttribute(reqCtx *svcCtx.RequestContext,
 
 	tags, err := m.nlbMgr.cloud.ListNLBTagResources(reqCtx.Ctx, remote.LoadBalancerAttribute.LoadBalancerId)
 	if err != nil {
-		return fmt.Errorf("ListNLBTagResources: %s", err.Error())
+		return fmt.Errorf("ListNLBTagResources: %w", err)
 	}
 	remote.LoadBalancerAttribute.Tags = tags
 
 	// check whether slb can be reused
-	if !helper.NeedDeleteLoadBalancer(reqCtx.Service) && local.LoadBalancerAttribute.IsUserManaged {
+	if !needDeleteLoadBalancer(reqCtx.Service) && local.LoadBalancerAttribute.IsUserManaged {
 		if ok, reason := isNLBReusable(reqCtx.Service, tags, remote.LoadBalancerAttribute.DNSName); !ok {
 			return fmt.Errorf("the loadbalancer %s can not be reused, %s",
 				remote.LoadBalancerAttribute.LoadBalancerId, reason)
@@ -275,6 +311,12 @@ func (m *ModelApplier) applyListeners(reqCtx *svcCtx.RequestContext, local, remo
 		}
 	}
 
+	if local.LoadBalancerAttribute.SharedGroup != "" {
+		if err := checkSharedListenerOwner(reqCtx, local, remote); err != nil {
+			return err
+		}
+	}
+
 	var actions []listenerAction
 
 	// associate listener and vGroup
@@ -283,7 +325,7 @@ func (m *ModelApplier) applyListeners(reqCtx *svcCtx.RequestContext, local, remo
 			continue
 		}
 		if err := findServerGroup(local.ServerGroups, local.Listeners[i]); err != nil {
-			return fmt.Errorf("find servergroup error: %s", err.Error())
+			return fmt.Errorf("find servergroup error: %w", err)
 		}
 	}
 
@@ -298,9 +340,9 @@ func (m *ModelApplier) applyListeners(reqCtx *svcCtx.RequestContext, local, remo
 		}
 
 		if !found {
-			if local.LoadBalancerAttribute.IsUserManaged {
+			if local.LoadBalancerAttribute.IsUserManaged || local.LoadBalancerAttribute.SharedGroup != "" {
 				if r.NamedKey == nil || !r.NamedKey.IsManagedByService(reqCtx.Service, base.CLUSTER_ID) {
-					reqCtx.Log.V(5).Info(fmt.Sprintf("listener %s is managed by user, skip delete", r.ListenerId))
+					reqCtx.Log.V(5).Info(fmt.Sprintf("listener %s is not managed by the service, skip delete", r.ListenerId))
 					continue
 				}
 			}
@@ -384,7 +426,7 @@ func (m *ModelApplier) cleanup(reqCtx *svcCtx.RequestContext, local, remote *nlb
 			reqCtx.Log.Info(fmt.Sprintf("delete server group [%s], %s", r.ServerGroupName, r.ServerGroupId))
 			err := m.sgMgr.DeleteServerGroup(reqCtx, r.ServerGroupId)
 			if err != nil {
-				return fmt.Errorf("delete server group %s failed, error: %s", r.ServerGroupId, err.Error())
+				return fmt.Errorf("delete server group %s failed, error: %w", r.ServerGroupId, err)
 			}
 		}
 	}
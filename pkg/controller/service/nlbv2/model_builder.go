@@ -2,11 +2,11 @@ package nlbv2
 
 import (
 	"fmt"
-	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
 	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type ModelType string
@@ -27,6 +27,10 @@ type ModelBuilder struct {
 	NLBMgr *NLBManager
 	LisMgr *ListenerManager
 	SGMgr  *ServerGroupManager
+
+	// ServiceReader lists the services of the shared groups, which are not cached by the kube client.
+	// The kube client of the server group manager is used if it is nil.
+	ServiceReader client.Reader
 }
 
 // NewDefaultModelBuilder construct a new defaultModelBuilder
@@ -60,8 +64,16 @@ func (c localModel) Build(reqCtx *svcCtx.RequestContext) (*nlbmodel.NetworkLoadB
 		NamespacedName:        util.NamespacedName(reqCtx.Service),
 		LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{},
 	}
+	reader := c.ServiceReader
+	if reader == nil {
+		reader = c.SGMgr.kubeClient
+	}
+	members, err := buildSharedGroup(reqCtx, c.SGMgr.kubeClient, reader, lbMdl)
+	if err != nil {
+		return nil, fmt.Errorf("build nlb shared group error: %w", err)
+	}
 	// if the service do not need loadbalancer anymore, return directly.
-	if helper.NeedDeleteLoadBalancer(reqCtx.Service) {
+	if needDeleteLoadBalancer(reqCtx.Service) {
 		if reqCtx.Anno.Get(annotation.LoadBalancerId) != "" {
 			lbMdl.LoadBalancerAttribute.IsUserManaged = true
 		}
@@ -71,13 +83,20 @@ func (c localModel) Build(reqCtx *svcCtx.RequestContext) (*nlbmodel.NetworkLoadB
 		return lbMdl, nil
 	}
 	if err := c.NLBMgr.BuildLocalModel(reqCtx, lbMdl); err != nil {
-		return nil, fmt.Errorf("build nlb attribute error: %s", err.Error())
+		return nil, fmt.Errorf("build nlb attribute error: %w", err)
 	}
 	if err := c.LisMgr.BuildLocalModel(reqCtx, lbMdl); err != nil {
-		return nil, fmt.Errorf("build nlb listener error: %s", err.Error())
+		return nil, fmt.Errorf("build nlb listener error: %w", err)
+	}
+	// the conflict is returned as is, so that it can be reported on the service
+	if err := c.LisMgr.checkSharedPortConflict(reqCtx, lbMdl, members); err != nil {
+		return nil, err
 	}
 	if err := c.SGMgr.BuildLocalModel(reqCtx, lbMdl); err != nil {
-		return nil, fmt.Errorf("builid nlb server group error: %s", err.Error())
+		return nil, fmt.Errorf("builid nlb server group error: %w", err)
+	}
+	if err := c.NLBMgr.buildAclRules(reqCtx, lbMdl); err != nil {
+		return nil, fmt.Errorf("build acl rules error: %w", err)
 	}
 
 	return lbMdl, nil
@@ -94,18 +113,18 @@ func (c remoteModel) Build(reqCtx *svcCtx.RequestContext) (*nlbmodel.NetworkLoad
 
 	err := c.NLBMgr.BuildRemoteModel(reqCtx, lbMdl)
 	if err != nil {
-		return nil, fmt.Errorf("can not get nlb attribute from cloud, error: %s", err.Error())
+		return nil, fmt.Errorf("can not get nlb attribute from cloud, error: %w", err)
 	}
 	if lbMdl.LoadBalancerAttribute.LoadBalancerId == "" {
 		return lbMdl, nil
 	}
 
 	if err := c.SGMgr.BuildRemoteModel(reqCtx, lbMdl); err != nil {
-		return nil, fmt.Errorf("build server group from remote error: %s", err.Error())
+		return nil, fmt.Errorf("build server group from remote error: %w", err)
 	}
 
 	if err := c.LisMgr.BuildRemoteModel(reqCtx, lbMdl); err != nil {
-		return nil, fmt.Errorf("can not build nlb listener attribute from cloud, error: %s", err.Error())
+		return nil, fmt.Errorf("can not build nlb listener attribute from cloud, error: %w", err)
 	}
 
 	return lbMdl, nil
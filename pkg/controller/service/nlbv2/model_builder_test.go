@@ -9,7 +9,7 @@ import (
 
 func TestModelBuilder_BuildModel(t *testing.T) {
 	nlbManager := NewNLBManager(getMockCloudProvider())
-	listenerManager := NewListenerManager(getMockCloudProvider())
+	listenerManager := NewListenerManager(getFakeKubeClient(), getMockCloudProvider())
 	serverGroupManager, err := NewServerGroupManager(getFakeKubeClient(), getMockCloudProvider())
 	if err != nil {
 		t.Error(err)
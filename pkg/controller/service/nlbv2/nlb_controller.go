@@ -13,6 +13,8 @@ import (
 	v1 "k8s.io/api/core/v1"
 	discovery "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -20,10 +22,13 @@ import (
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/context/shared"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	reconbackend "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/backend"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/certificate"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
 	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
@@ -59,12 +64,13 @@ func newReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*ReconcileNL
 	}
 
 	nlbManager := NewNLBManager(recon.cloud)
-	listenerManager := NewListenerManager(recon.cloud)
+	listenerManager := NewListenerManager(recon.kubeClient, recon.cloud)
 	serverGroupManager, err := NewServerGroupManager(recon.kubeClient, recon.cloud)
 	if err != nil {
 		return nil, fmt.Errorf("NewServerGroupManager error:%s", err.Error())
 	}
 	recon.builder = NewModelBuilder(nlbManager, listenerManager, serverGroupManager)
+	recon.builder.ServiceReader = mgr.GetCache()
 	recon.applier = NewModelApplier(nlbManager, listenerManager, serverGroupManager)
 	return recon, nil
 }
@@ -104,6 +110,11 @@ func add(mgr manager.Manager, r *ReconcileNLB) error {
 		return fmt.Errorf("watch resource svc error: %s", err.Error())
 	}
 
+	if err := c.Watch(source.Kind(mgr.GetCache(), &v1.Service{}),
+		NewEnqueueRequestForSharedGroupEvent(mgr.GetClient(), mgr.GetCache())); err != nil {
+		return fmt.Errorf("watch resource svc of shared groups error: %s", err.Error())
+	}
+
 	if utilfeature.DefaultFeatureGate.Enabled(ctrlCfg.EndpointSlice) {
 		// watch endpointslice
 		if err := c.Watch(source.Kind(mgr.GetCache(), &discovery.EndpointSlice{}),
@@ -123,6 +134,18 @@ func add(mgr manager.Manager, r *ReconcileNLB) error {
 		return fmt.Errorf("watch resource node error: %s", err.Error())
 	}
 
+	if err := c.Watch(source.Kind(mgr.GetCache(), &v1.Secret{}),
+		certificate.NewEnqueueRequestForSecretEvent(mgr.GetClient(), helper.NeedNLB)); err != nil {
+		return fmt.Errorf("watch resource secret error: %s", err.Error())
+	}
+
+	if utilfeature.DefaultFeatureGate.Enabled(ctrlCfg.LoadBalancerConfig) {
+		if err := c.Watch(source.Kind(mgr.GetCache(), &albv1.LoadBalancerConfig{}),
+			annotation.NewEnqueueRequestForConfigEvent(mgr.GetClient(), helper.NeedNLB)); err != nil {
+			return fmt.Errorf("watch resource LoadBalancerConfig error: %s", err.Error())
+		}
+	}
+
 	return mgr.Add(&nlbController{c: c, recon: r})
 }
 
@@ -165,7 +188,16 @@ func (m *ReconcileNLB) reconcile(c context.Context, request reconcile.Request) e
 		m.logger.Error(err, "reconcile: get service failed", "service", request.NamespacedName)
 	}
 
-	anno := &annotation.AnnotationRequest{Service: svc}
+	anno, err := annotation.NewAnnotationRequestWithConfig(context.Background(), m.kubeClient, svc)
+	if err != nil {
+		if helper.NeedNLB(svc) {
+			m.record.Event(svc, v1.EventTypeWarning, helper.FailedSyncLB,
+				fmt.Sprintf("Error loading LoadBalancerConfig: %s", err.Error()))
+			return err
+		}
+		// the nlb is cleaned up without the config
+		anno = annotation.NewAnnotationRequest(svc)
+	}
 	// new context for each request
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, dryrun.ContextService, svc)
@@ -214,12 +246,34 @@ func (m *ReconcileNLB) cleanupLoadBalancerResources(reqCtx *svcCtx.RequestContex
 			return err
 		}
 
-		if err := m.removeServiceLabels(reqCtx.Service); err != nil {
-			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedRemoveHash,
-				fmt.Sprintf("Error removing service hash: %s", err.Error()))
+		if err := m.builder.LisMgr.GarbageCollectSecretCerts(reqCtx); err != nil {
+			reqCtx.Log.Error(err, "garbage collect certs of secret failed")
+		}
+
+		// the security groups still joined by the load balancer are retried, otherwise they leak once the
+		// finalizer is removed
+		if err := m.builder.NLBMgr.GarbageCollectAclSecurityGroups(reqCtx, lb); err != nil {
+			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedCleanLB,
+				fmt.Sprintf("Error deleting acl security groups: %s", err.Error()))
+			return err
+		}
+
+		// the eips are retried on error, otherwise they leak once the finalizer is removed
+		if err := m.builder.NLBMgr.GarbageCollectEips(reqCtx, lb); err != nil {
+			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedCleanLB,
+				fmt.Sprintf("Error releasing eips: %s", err.Error()))
 			return err
 		}
 
+		// the labels belong to the clb controller once the service is handed back to it
+		if !helper.NeedCLB(reqCtx.Service) {
+			if err := m.removeServiceLabels(reqCtx.Service); err != nil {
+				m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedRemoveHash,
+					fmt.Sprintf("Error removing service hash: %s", err.Error()))
+				return err
+			}
+		}
+
 		// When service type changes from LoadBalancer to NodePort,
 		// we need to clean Ingress attribute in service status
 		if err := m.removeServiceStatus(reqCtx, reqCtx.Service); err != nil {
@@ -228,6 +282,21 @@ func (m *ReconcileNLB) cleanupLoadBalancerResources(reqCtx *svcCtx.RequestContex
 			return err
 		}
 
+		// the soak period starts over if the aborted migration is enabled again
+		if helper.IsMigrationAborted(reqCtx.Service) {
+			if err := helper.RemoveMigrationCondition(reqCtx.Ctx, m.kubeClient, reqCtx.Service); err != nil {
+				m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedUpdateStatus,
+					fmt.Sprintf("Error removing migration condition: %s", err.Error()))
+				return err
+			}
+		}
+
+		if err := reconbackend.SyncDrainFinalizers(reqCtx, m.kubeClient, false, nil, nil); err != nil {
+			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedSyncDrainFinalizer,
+				fmt.Sprintf("Error removing drain finalizers of pods: %s", err.Error()))
+			return err
+		}
+
 		if err := m.finalizerManager.RemoveFinalizers(reqCtx.Ctx, reqCtx.Service, helper.NLBFinalizer); err != nil {
 			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedRemoveFinalizer,
 				fmt.Sprintf("Error removing load balancer finalizer: %v", err.Error()))
@@ -247,47 +316,124 @@ func (m *ReconcileNLB) reconcileLoadBalancerResources(req *svcCtx.RequestContext
 	}
 
 	lb, sgs, err := m.buildAndApplyModel(req)
+	var conflict *SharedPortConflictError
+	if errors.As(err, &conflict) {
+		m.record.Event(req.Service, v1.EventTypeWarning, helper.SharedPortConflict, conflict.Error())
+		if err := m.updateSharedPortCondition(req, conflict); err != nil {
+			req.Log.Error(err, "update shared port conflict condition failed")
+		}
+		return err
+	}
 	if err != nil {
-		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedSyncLB,
+		reason := helper.FailedSyncLB
+		if util.IsThrottlingError(err) {
+			reason = helper.ThrottledSyncLB
+		}
+		m.record.Event(req.Service, v1.EventTypeWarning, reason,
 			fmt.Sprintf("Error syncing load balancer [%s]: %s",
 				lb.GetLoadBalancerId(), helper.GetLogMessage(err)))
 		return err
 	}
 
+	// the hash label is not updated until the certificates of a removed annotation are deleted
+	if err := m.builder.LisMgr.GarbageCollectSecretCerts(req); err != nil {
+		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedSyncLB,
+			fmt.Sprintf("Error deleting certificates of secrets: %s", err.Error()))
+		return err
+	}
+
 	if err := m.updateReadinessCondition(req, sgs); err != nil {
 		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedUpdateReadinessGate,
 			fmt.Sprintf("Error updating pod readiness gates for service [%s]: %s", util.Key(req.Service), err.Error()))
 		return err
 	}
 
-	if err := m.addServiceLabels(req.Service, lb.GetLoadBalancerId()); err != nil {
-		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedAddHash,
-			fmt.Sprintf("Error adding service hash: %s", err.Error()))
+	drainRequeueAfter, err := m.updateDrainFinalizers(req, sgs)
+	if err != nil {
+		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedSyncDrainFinalizer,
+			fmt.Sprintf("Error syncing drain finalizers of pods: %s", err.Error()))
 		return err
 	}
 
+	// the labels belong to the clb controller during the migration
+	if !helper.IsMigratingToNLB(req.Service) {
+		if err := m.addServiceLabels(req.Service, req.Anno.Config(), lb.GetLoadBalancerId()); err != nil {
+			m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedAddHash,
+				fmt.Sprintf("Error adding service hash: %s", err.Error()))
+			return err
+		}
+	}
+
 	if err := m.updateServiceStatus(req, req.Service, lb); err != nil {
 		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedUpdateStatus,
 			fmt.Sprintf("Error updating load balancer status: %s", err.Error()))
 		return err
 	}
 
+	if err := m.updateSharedPortCondition(req, nil); err != nil {
+		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedUpdateStatus,
+			fmt.Sprintf("Error updating shared port conflict condition: %s", err.Error()))
+		return err
+	}
+
+	if helper.IsMigratingToNLB(req.Service) && !helper.NeedReleaseCLB(req.Service) {
+		if err := helper.SetMigrationCondition(req.Ctx, m.kubeClient, req.Service, helper.MigrationReasonNLBReady,
+			fmt.Sprintf("nlb %s is ready, waiting for the cut-over", lb.GetLoadBalancerId())); err != nil {
+			m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedUpdateStatus,
+				fmt.Sprintf("Error updating migration condition: %s", err.Error()))
+			return err
+		}
+	}
+
 	m.record.Event(req.Service, v1.EventTypeNormal, helper.SucceedSyncLB,
 		fmt.Sprintf("Ensured load balancer [%s]", lb.LoadBalancerAttribute.LoadBalancerId))
 
 	if lb.ContainsPotentialReadyEndpoints {
 		return util.NewReconcileNeedRequeue("has potential ready backends")
 	}
+	if drainRequeueAfter > 0 {
+		return util.NewReconcileNeedRequeueAfter("wait for the deregistration delay of the draining backends", drainRequeueAfter)
+	}
 
 	return nil
 }
 
+// updateDrainFinalizers keeps the drain finalizer on the pods registered to the load balancer until they
+// are removed from the backends, and returns the duration until the next draining backend can be removed.
+func (m *ReconcileNLB) updateDrainFinalizers(reqCtx *svcCtx.RequestContext, sgs []*nlbmodel.ServerGroup) (time.Duration, error) {
+	drain, err := reconbackend.GetDrainOptions(reqCtx)
+	if err != nil {
+		return 0, err
+	}
+	var deadlines []time.Time
+	registered := make(map[types.NamespacedName]bool)
+	draining := make(map[types.NamespacedName]bool)
+	for _, sg := range sgs {
+		for _, b := range sg.InitialServers {
+			if b.TargetRef == nil || b.TargetRef.Kind != "Pod" {
+				continue
+			}
+			key := types.NamespacedName{Namespace: b.TargetRef.Namespace, Name: b.TargetRef.Name}
+			if b.DrainDeadline.IsZero() {
+				registered[key] = true
+				continue
+			}
+			draining[key] = true
+			deadlines = append(deadlines, b.DrainDeadline)
+		}
+	}
+	if err := reconbackend.SyncDrainFinalizers(reqCtx, m.kubeClient, drain.Finalizer, registered, draining); err != nil {
+		return 0, err
+	}
+	return reconbackend.DrainRequeueAfter(deadlines), nil
+}
+
 func (m *ReconcileNLB) buildAndApplyModel(reqCtx *svcCtx.RequestContext) (*nlbmodel.NetworkLoadBalancer, []*nlbmodel.ServerGroup, error) {
 
 	// build local model
 	localModel, err := m.builder.BuildModel(reqCtx, LocalModel)
 	if err != nil {
-		return nil, nil, fmt.Errorf("build lb local model error: %s", err.Error())
+		return nil, nil, fmt.Errorf("build lb local model error: %w", err)
 	}
 	mdlJson, err := json.Marshal(localModel)
 	if err != nil {
@@ -319,6 +465,24 @@ func (m *ReconcileNLB) updateServiceStatus(reqCtx *svcCtx.RequestContext, svc *v
 			})
 	}
 
+	// the eips allocated for the zones of the nlb are published after the dns name
+	addresses, err := m.builder.NLBMgr.EipAddresses(reqCtx, lb)
+	if err != nil {
+		reqCtx.Recorder.Event(svc, v1.EventTypeWarning, "FailedSetEIPAddress", "get eip error, publish the dns name only")
+	}
+	for _, address := range addresses {
+		newStatus.Ingress = append(newStatus.Ingress, v1.LoadBalancerIngress{IP: address})
+	}
+
+	// publish the address of the clb as well until it is released
+	if helper.IsMigratingToNLB(svc) {
+		isNLBIngress, err := m.isNLBIngress(reqCtx)
+		if err != nil {
+			return err
+		}
+		newStatus.Ingress = helper.MergeMigrationIngress(newStatus.Ingress, preStatus.Ingress, isNLBIngress)
+	}
+
 	// Write the state if changed
 	// TODO: Be careful here ... what if there were other changes to the service?
 	if !v1helper.LoadBalancerStatusEqual(preStatus, newStatus) {
@@ -372,9 +536,39 @@ func (m *ReconcileNLB) updateServiceStatus(reqCtx *svcCtx.RequestContext, svc *v
 
 }
 
+// updateSharedPortCondition sets the port conflict condition of the service in a shared group,
+// the condition is removed once the conflict is resolved.
+func (m *ReconcileNLB) updateSharedPortCondition(reqCtx *svcCtx.RequestContext, conflict *SharedPortConflictError) error {
+	cond := meta.FindStatusCondition(reqCtx.Service.Status.Conditions, SharedPortConflictCondition)
+	if (conflict == nil && cond == nil) || (conflict != nil && cond != nil && cond.Message == conflict.Error()) {
+		return nil
+	}
+	return helper.PatchServiceConditions(reqCtx.Ctx, m.kubeClient, reqCtx.Service, func(conds *[]metav1.Condition) {
+		if conflict == nil {
+			meta.RemoveStatusCondition(conds, SharedPortConflictCondition)
+			return
+		}
+		meta.SetStatusCondition(conds, metav1.Condition{
+			Type:               SharedPortConflictCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: reqCtx.Service.Generation,
+			Reason:             "PortInUse",
+			Message:            conflict.Error(),
+		})
+	})
+}
+
 func (m *ReconcileNLB) removeServiceStatus(reqCtx *svcCtx.RequestContext, svc *v1.Service) error {
 	preStatus := svc.Status.LoadBalancer.DeepCopy()
 	newStatus := &v1.LoadBalancerStatus{}
+	// keep the address of the clb if the service is handed back to the clb controller
+	if helper.NeedCLB(svc) {
+		isNLBIngress, err := m.isNLBIngress(reqCtx)
+		if err != nil {
+			return err
+		}
+		newStatus.Ingress = helper.MergeMigrationIngress(nil, preStatus.Ingress, isNLBIngress)
+	}
 
 	// Write the state if changed
 	// TODO: Be careful here ... what if there were other changes to the service?
@@ -426,12 +620,12 @@ func (m *ReconcileNLB) removeServiceStatus(reqCtx *svcCtx.RequestContext, svc *v
 
 }
 
-func (m *ReconcileNLB) addServiceLabels(svc *v1.Service, lbId string) error {
+func (m *ReconcileNLB) addServiceLabels(svc *v1.Service, config *albv1.LoadBalancerConfig, lbId string) error {
 	updated := svc.DeepCopy()
 	if updated.Labels == nil {
 		updated.Labels = make(map[string]string)
 	}
-	serviceHash := helper.GetServiceHash(svc)
+	serviceHash := helper.GetServiceHash(svc, config)
 	updated.Labels[helper.LabelServiceHash] = serviceHash
 	if lbId != "" {
 		updated.Labels[helper.LabelLoadBalancerId] = lbId
@@ -471,6 +665,9 @@ func (m *ReconcileNLB) updateReadinessCondition(reqCtx *svcCtx.RequestContext, s
 				reqCtx.Log.Info("backend TargetRef is nil, skip update readiness gates")
 				continue
 			}
+			if !b.DrainDeadline.IsZero() {
+				continue
+			}
 			key := types.NamespacedName{Namespace: b.TargetRef.Namespace, Name: b.TargetRef.Name}
 			if _, ok := a[key.String()]; ok {
 				continue
@@ -39,7 +39,7 @@ func getReconcileNLB() (*ReconcileNLB, error) {
 	}
 
 	nlbManager := NewNLBManager(recon.cloud)
-	listenerManager := NewListenerManager(recon.cloud)
+	listenerManager := NewListenerManager(recon.kubeClient, recon.cloud)
 	serverGroupManager, err := NewServerGroupManager(recon.kubeClient, recon.cloud)
 	if err != nil {
 		return nil, fmt.Errorf("NewServerGroupManager error:%s", err.Error())
@@ -317,6 +317,7 @@ func getMockCloudProvider() prvd.Provider {
 		MockVPC:   vmock.NewMockVPC(nil),
 		IMetaData: vmock.NewMockMetaData("vpc-id"),
 		MockNLB:   vmock.NewMockNLB(nil),
+		MockCAS:   vmock.NewMockCAS(nil),
 	}
 }
 
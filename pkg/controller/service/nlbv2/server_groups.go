@@ -31,6 +31,17 @@ import (
 
 const DefaultServerWeight = 100
 
+// SchedulerSch schedules the requests by the hash of the source ip, which keeps the session of a client.
+const SchedulerSch = "Sch"
+
+// the valid values of the server group annotations, in the case of the OpenAPI
+var (
+	nlbSchedulers           = []string{"Wrr", "Rr", SchedulerSch, "Tch", "Qch", "Wlc"}
+	nlbHealthCheckTypes     = []string{"TCP", "HTTP", "UDP"}
+	nlbHealthCheckMethods   = []string{"GET", "HEAD"}
+	nlbHealthCheckHttpCodes = []string{"http_2xx", "http_3xx", "http_4xx", "http_5xx"}
+)
+
 func NewServerGroupManager(kubeClient client.Client, cloud prvd.Provider) (*ServerGroupManager, error) {
 	manager := &ServerGroupManager{
 		kubeClient: kubeClient,
@@ -98,13 +109,13 @@ func (mgr *ServerGroupManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, md
 			sg.NamedKey = getAnyPortServerGroupNamedKey(reqCtx.Service, sg.Protocol, lis.StartPort, lis.EndPort)
 		}
 		sg.ServerGroupName = sg.NamedKey.Key()
-		if err := setServerGroupAttributeFromAnno(sg, reqCtx.Anno); err != nil {
+		if err := setServerGroupAttributeFromAnno(sg, reqCtx.Anno.ForPort(lis.ServicePort.Port)); err != nil {
 			errs[i] = err
 			return
 		}
 		cpr, err := mgr.setServerGroupServers(reqCtx, sg, candidates, mdl.LoadBalancerAttribute.IsUserManaged)
 		if err != nil {
-			errs[i] = fmt.Errorf("set ServerGroup for port %d error: %s", lis.ServicePort.Port, err.Error())
+			errs[i] = fmt.Errorf("set ServerGroup for port %d error: %w", lis.ServicePort.Port, err)
 			return
 		}
 		sgs[i] = sg
@@ -164,7 +175,7 @@ func (mgr *ServerGroupManager) ListNLBServerGroups(reqCtx *svcCtx.RequestContext
 func (mgr *ServerGroupManager) BuildRemoteModel(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer) error {
 	sgs, err := mgr.ListNLBServerGroups(reqCtx)
 	if err != nil {
-		return fmt.Errorf("DescribeVServerGroups error: %s", err.Error())
+		return fmt.Errorf("DescribeVServerGroups error: %w", err)
 	}
 	mdl.ServerGroups = sgs
 	return nil
@@ -242,14 +253,14 @@ func (mgr *ServerGroupManager) UpdateServerGroup(reqCtx *svcCtx.RequestContext,
 			needUpdate = true
 			update.ConnectionDrainEnabled = local.ConnectionDrainEnabled
 			updateDetail += fmt.Sprintf("ConnectionDrainEnabled %v should be changed to %v;",
-				*remote.ConnectionDrainEnabled, *local.ConnectionDrainEnabled)
+				tea.BoolValue(remote.ConnectionDrainEnabled), tea.BoolValue(local.ConnectionDrainEnabled))
 		}
-		if local.ConnectionDrainTimeout != 0 &&
-			local.ConnectionDrainTimeout != remote.ConnectionDrainTimeout {
+		if local.ConnectionDrainTimeout != nil &&
+			tea.Int32Value(local.ConnectionDrainTimeout) != tea.Int32Value(remote.ConnectionDrainTimeout) {
 			needUpdate = true
 			update.ConnectionDrainTimeout = local.ConnectionDrainTimeout
 			updateDetail += fmt.Sprintf("ConnectionDrainTimeout %v should be changed to %v;",
-				remote.ConnectionDrainTimeout, local.ConnectionDrainTimeout)
+				tea.Int32Value(remote.ConnectionDrainTimeout), tea.Int32Value(local.ConnectionDrainTimeout))
 		}
 		if local.PreserveClientIpEnabled != nil &&
 			tea.BoolValue(local.PreserveClientIpEnabled) != tea.BoolValue(remote.PreserveClientIpEnabled) {
@@ -274,7 +285,7 @@ func (mgr *ServerGroupManager) UpdateServerGroup(reqCtx *svcCtx.RequestContext,
 				needUpdate = true
 				update.HealthCheckConfig.HealthCheckEnabled = localHC.HealthCheckEnabled
 				updateDetail += fmt.Sprintf("HealthCheckEnabled %v should be changed to %v;",
-					*remoteHC.HealthCheckEnabled, *localHC.HealthCheckEnabled)
+					tea.BoolValue(remoteHC.HealthCheckEnabled), tea.BoolValue(localHC.HealthCheckEnabled))
 			}
 			if localHC.HealthCheckType != "" &&
 				!strings.EqualFold(localHC.HealthCheckType, remoteHC.HealthCheckType) {
@@ -315,7 +326,7 @@ func (mgr *ServerGroupManager) UpdateServerGroup(reqCtx *svcCtx.RequestContext,
 				localHC.HealthCheckInterval != remoteHC.HealthCheckInterval {
 				needUpdate = true
 				update.HealthCheckConfig.HealthCheckInterval = localHC.HealthCheckInterval
-				updateDetail += fmt.Sprintf("HealthCheckConnectTimeout %v should be changed to %v;",
+				updateDetail += fmt.Sprintf("HealthCheckInterval %v should be changed to %v;",
 					remoteHC.HealthCheckInterval, localHC.HealthCheckInterval)
 			}
 			if localHC.HealthCheckDomain != "" &&
@@ -353,7 +364,7 @@ func (mgr *ServerGroupManager) UpdateServerGroup(reqCtx *svcCtx.RequestContext,
 			reqCtx.Log.Info(fmt.Sprintf("update server group: %s [%s] changed, detail %s",
 				local.ServerGroupId, local.ServerGroupName, updateDetail))
 			if err := mgr.cloud.UpdateNLBServerGroup(reqCtx.Ctx, update); err != nil {
-				errs = append(errs, fmt.Errorf("UpdateNLBServerGroup error: %s", err.Error()))
+				errs = append(errs, fmt.Errorf("UpdateNLBServerGroup error: %w", err))
 			}
 		}
 	} else {
@@ -454,12 +465,12 @@ func (mgr *ServerGroupManager) setServerGroupServers(reqCtx *svcCtx.RequestConte
 		sgId, err := serverGroup(reqCtx.Anno.Get(annotation.VGroupPort), *sg.ServicePort)
 
 		if err != nil {
-			return false, fmt.Errorf("server group id parse error: %s", err.Error())
+			return false, fmt.Errorf("server group id parse error: %w", err)
 		}
 		if sgId != "" {
 			remoteSg, err := mgr.cloud.GetNLBServerGroup(reqCtx.Ctx, sgId)
 			if err != nil {
-				return false, fmt.Errorf("find server group id %s for nlb %s error: %s", sgId, reqCtx.Anno.Get(annotation.LoadBalancerId), err.Error())
+				return false, fmt.Errorf("find server group id %s for nlb %s error: %w", sgId, reqCtx.Anno.Get(annotation.LoadBalancerId), err)
 			}
 			if remoteSg == nil {
 				return false, fmt.Errorf("cannot find server group id %s for nlb %s", sgId, reqCtx.Anno.Get(annotation.LoadBalancerId))
@@ -492,24 +503,28 @@ func (mgr *ServerGroupManager) setServerGroupServers(reqCtx *svcCtx.RequestConte
 		reqCtx.Log.Info(fmt.Sprintf("eni mode, build backends for %s", sg.NamedKey))
 		backends, err = mgr.buildENIBackends(reqCtx, candidates, initialServers, *sg)
 		if err != nil {
-			return false, fmt.Errorf("build eni backends error: %s", err.Error())
+			return false, fmt.Errorf("build eni backends error: %w", err)
 		}
 	case helper.LocalTrafficPolicy:
 		reqCtx.Log.Info(fmt.Sprintf("local mode, build backends for %s", sg.NamedKey))
 		backends, err = mgr.buildLocalBackends(reqCtx, candidates, initialServers, *sg)
 		if err != nil {
-			return false, fmt.Errorf("build local backends error: %s", err.Error())
+			return false, fmt.Errorf("build local backends error: %w", err)
 		}
 	case helper.ClusterTrafficPolicy:
 		reqCtx.Log.Info(fmt.Sprintf("cluster mode, build backends for %s", sg.NamedKey))
 		backends, err = mgr.buildClusterBackends(reqCtx, candidates, initialServers, *sg)
 		if err != nil {
-			return false, fmt.Errorf("build cluster backends error: %s", err.Error())
+			return false, fmt.Errorf("build cluster backends error: %w", err)
 		}
 	default:
 		return false, fmt.Errorf("not supported traffic policy [%s]", candidates.TrafficPolicy)
 	}
 
+	if candidates.Topology != nil {
+		backends = setZoneAwareWeights(candidates.TrafficPolicy, candidates.Topology, backends, initialServers, sg.Weight)
+	}
+
 	if len(backends) == 0 {
 		reqCtx.Recorder.Event(
 			reqCtx.Service,
@@ -624,6 +639,11 @@ func (mgr *ServerGroupManager) setBackendsFromEndpointSlices(reqCtx *svcCtx.Requ
 		return nil, false, nil
 	}
 
+	drain, err := reconbackend.GetDrainOptions(reqCtx)
+	if err != nil {
+		return nil, false, err
+	}
+
 	for _, es := range candidates.EndpointSlices {
 		var backendPort int32
 		if sg.ServicePort.TargetPort.Type == intstr.Int {
@@ -644,8 +664,29 @@ func (mgr *ServerGroupManager) setBackendsFromEndpointSlices(reqCtx *svcCtx.Requ
 		}
 
 		for _, ep := range es.Endpoints {
-			// ignore terminating pods
+			// ignore terminating pods, unless they are kept with weight 0 for the deregistration delay
 			if ep.Conditions.Terminating != nil && *ep.Conditions.Terminating {
+				deadline, err := reconbackend.GetDrainDeadline(reqCtx.Ctx, mgr.kubeClient, ep, drain.Delay)
+				if err != nil {
+					return nil, false, err
+				}
+				if deadline.IsZero() {
+					continue
+				}
+				for _, addr := range ep.Addresses {
+					if _, ok := endpointMap[addr]; ok {
+						continue
+					}
+					endpointMap[addr] = true
+					backends = append(backends, nlbmodel.ServerGroupServer{
+						NodeName:      ep.NodeName,
+						ServerIp:      addr,
+						Port:          getBackendPort(backendPort, sg.AnyPortEnabled),
+						Description:   sg.ServerGroupName,
+						TargetRef:     ep.TargetRef,
+						DrainDeadline: deadline,
+					})
+				}
 				continue
 			}
 
@@ -736,7 +777,17 @@ func (mgr *ServerGroupManager) buildENIBackends(reqCtx *svcCtx.RequestContext, c
 		return backends, err
 	}
 
-	return setWeightBackends(helper.ENITrafficPolicy, backends, sg.Weight), nil
+	// the draining backends take no new connections and are left out of the weight calculation
+	var serving, draining []nlbmodel.ServerGroupServer
+	for _, b := range backends {
+		if b.DrainDeadline.IsZero() {
+			serving = append(serving, b)
+			continue
+		}
+		b.Weight = 0
+		draining = append(draining, b)
+	}
+	return append(setWeightBackends(helper.ENITrafficPolicy, serving, sg.Weight), draining...), nil
 }
 
 func (mgr *ServerGroupManager) buildLocalBackends(reqCtx *svcCtx.RequestContext, candidates *reconbackend.EndpointWithENI, initBackends []nlbmodel.ServerGroupServer,
@@ -778,7 +829,7 @@ func (mgr *ServerGroupManager) buildLocalBackends(reqCtx *svcCtx.RequestContext,
 		if sg.ServerGroupType == nlbmodel.IpServerGroupType {
 			ip, err := helper.GetNodeInternalIP(node)
 			if err != nil {
-				return nil, fmt.Errorf("get node address err: %s", err.Error())
+				return nil, fmt.Errorf("get node address err: %w", err)
 			}
 			backend.ServerId = ip
 			backend.ServerIp = ip
@@ -804,7 +855,7 @@ func (mgr *ServerGroupManager) buildLocalBackends(reqCtx *svcCtx.RequestContext,
 		reqCtx.Log.Info("add eciBackends")
 		eciBackends, err = updateENIBackends(mgr, eciBackends, candidates.AddressIPVersion, sg.ServerGroupType)
 		if err != nil {
-			return nil, fmt.Errorf("update eci backends error: %s", err.Error())
+			return nil, fmt.Errorf("update eci backends error: %w", err)
 		}
 	}
 
@@ -855,7 +906,7 @@ func (mgr *ServerGroupManager) buildClusterBackends(
 		if sg.ServerGroupType == nlbmodel.IpServerGroupType {
 			ip, err := helper.GetNodeInternalIP(&node)
 			if err != nil {
-				return nil, fmt.Errorf("get node address err: %s", err.Error())
+				return nil, fmt.Errorf("get node address err: %w", err)
 			}
 			backend.ServerId = ip
 			backend.ServerIp = ip
@@ -897,7 +948,7 @@ func (mgr *ServerGroupManager) buildClusterBackends(
 	if len(eciBackends) != 0 {
 		eciBackends, err = updateENIBackends(mgr, eciBackends, candidates.AddressIPVersion, sg.ServerGroupType)
 		if err != nil {
-			return nil, fmt.Errorf("update eci backends error: %s", err.Error())
+			return nil, fmt.Errorf("update eci backends error: %w", err)
 		}
 	}
 
@@ -944,7 +995,7 @@ func updateENIBackends(mgr *ServerGroupManager, backends []nlbmodel.ServerGroupS
 
 	result, err := mgr.cloud.DescribeNetworkInterfaces(mgr.vpcId, ips, ipVersion)
 	if err != nil {
-		return nil, fmt.Errorf("call DescribeNetworkInterfaces: %s", err.Error())
+		return nil, fmt.Errorf("call DescribeNetworkInterfaces: %w", err)
 	}
 
 	for i := range backends {
@@ -996,6 +1047,44 @@ func podNumberAlgorithm(mode helper.TrafficPolicy, backends []nlbmodel.ServerGro
 	return backends
 }
 
+// setZoneAwareWeights overrides the weights of the backends by the zone weight policy,
+// the draining backends keep weight 0.
+func setZoneAwareWeights(mode helper.TrafficPolicy, topology *reconbackend.ZoneTopology,
+	backends, initBackends []nlbmodel.ServerGroupServer, weight *int) []nlbmodel.ServerGroupServer {
+	podsOnNode := make(map[string]int)
+	for _, b := range initBackends {
+		if b.NodeName != nil {
+			podsOnNode[*b.NodeName]++
+		}
+	}
+
+	var (
+		idx   []int
+		zones []string
+		units []int
+	)
+	for i, b := range backends {
+		if !b.DrainDeadline.IsZero() {
+			continue
+		}
+		isECS := b.ServerType == nlbmodel.EcsServerType
+		unit := 1
+		if isECS && mode == helper.LocalTrafficPolicy && b.NodeName != nil && podsOnNode[*b.NodeName] > 0 {
+			unit = podsOnNode[*b.NodeName]
+		}
+		idx = append(idx, i)
+		zones = append(zones, topology.BackendZone(isECS, b.ServerId, b.ServerIp, b.NodeName))
+		units = append(units, unit)
+	}
+
+	// in cluster mode the nodes forward the traffic to the pods of any zone
+	weights := topology.Weights(zones, units, weight, mode != helper.ClusterTrafficPolicy)
+	for j, i := range idx {
+		backends[i].Weight = int32(weights[j])
+	}
+	return backends
+}
+
 // podPercentAlgorithm
 /*
 	Calculate node weight by percent.
@@ -1109,12 +1198,32 @@ func setServerGroupAttributeFromAnno(sg *nlbmodel.ServerGroup, anno *annotation.
 	if anno.Get(annotation.ConnectionDrainTimeout) != "" {
 		timeout, err := strconv.Atoi(anno.Get(annotation.ConnectionDrainTimeout))
 		if err != nil {
-			return fmt.Errorf("ConnectionDrainTimeout parse error: %s", err.Error())
+			return fmt.Errorf("ConnectionDrainTimeout parse error: %w", err)
+		}
+		sg.ConnectionDrainTimeout = tea.Int32(int32(timeout))
+	}
+
+	if scheduler := anno.Get(annotation.Scheduler); scheduler != "" {
+		v, err := getEnumValue(annotation.Scheduler, scheduler, nlbSchedulers)
+		if err != nil {
+			return err
 		}
-		sg.ConnectionDrainTimeout = int32(timeout)
+		sg.Scheduler = v
 	}
 
-	sg.Scheduler = anno.Get(annotation.Scheduler)
+	// nlb keeps the session by the source ip hash, which has no timeout, so the scheduler has to be set to
+	// sch explicitly rather than changed behind the user
+	if anno.Get(annotation.PersistenceTimeout) != "" {
+		timeout, err := strconv.Atoi(anno.Get(annotation.PersistenceTimeout))
+		if err != nil {
+			return fmt.Errorf("PersistenceTimeout parse error: %w", err)
+		}
+		if timeout > 0 && sg.Scheduler != SchedulerSch {
+			return fmt.Errorf("annotation %s requires %s [%s] on nlb, which keeps the session by the source ip, got [%s]",
+				annotation.Annotation(annotation.PersistenceTimeout), annotation.Annotation(annotation.Scheduler),
+				strings.ToLower(SchedulerSch), sg.Scheduler)
+		}
+	}
 
 	if anno.Get(annotation.PreserveClientIp) != "" {
 		sg.PreserveClientIpEnabled = tea.Bool(
@@ -1125,67 +1234,116 @@ func setServerGroupAttributeFromAnno(sg *nlbmodel.ServerGroup, anno *annotation.
 		sg.ResourceGroupId = rgID
 	}
 
-	// healthcheck
-	if anno.Get(annotation.HealthCheckFlag) != "" {
-		healthCheckConfig := &nlbmodel.HealthCheckConfig{
-			HealthCheckEnabled: tea.Bool(strings.EqualFold(anno.Get(annotation.HealthCheckFlag), string(model.OnFlag))),
-		}
+	if err := setHealthCheckConfigFromAnno(sg, anno); err != nil {
+		return err
+	}
 
-		if *healthCheckConfig.HealthCheckEnabled {
-			if anno.Get(annotation.HealthCheckType) != "" {
-				healthCheckConfig.HealthCheckType = anno.Get(annotation.HealthCheckType)
-			}
-			if anno.Get(annotation.HealthCheckConnectPort) != "" {
-				checkPort, err := strconv.Atoi(anno.Get(annotation.HealthCheckConnectPort))
-				if err != nil {
-					return fmt.Errorf("HealthCheckConnectPort parse error: [%s]", err.Error())
-				}
-				healthCheckConfig.HealthCheckConnectPort = int32(checkPort)
-			}
-			if anno.Get(annotation.HealthyThreshold) != "" {
-				healthyThreshold, err := strconv.Atoi(anno.Get(annotation.HealthyThreshold))
-				if err != nil {
-					return fmt.Errorf("HealthyThreshold parse error: [%s]", err.Error())
-				}
-				healthCheckConfig.HealthyThreshold = int32(healthyThreshold)
-			}
-			if anno.Get(annotation.UnhealthyThreshold) != "" {
-				unhealthyThreshold, err := strconv.Atoi(anno.Get(annotation.UnhealthyThreshold))
-				if err != nil {
-					return fmt.Errorf("UnhealthyThreshold parse error: [%s]", err.Error())
-				}
-				healthCheckConfig.UnhealthyThreshold = int32(unhealthyThreshold)
-			}
-			if anno.Get(annotation.HealthCheckConnectTimeout) != "" {
-				healthCheckConnectTimeout, err := strconv.Atoi(anno.Get(annotation.HealthCheckConnectTimeout))
-				if err != nil {
-					return fmt.Errorf("HealthCheckConnectTimeout parse error: [%s]", err.Error())
-				}
-				healthCheckConfig.HealthCheckConnectTimeout = int32(healthCheckConnectTimeout)
-			}
-			if anno.Get(annotation.HealthCheckInterval) != "" {
-				healthCheckInterval, err := strconv.Atoi(anno.Get(annotation.HealthCheckInterval))
-				if err != nil {
-					return fmt.Errorf("HealthCheckInterval parse error: [%s]", err.Error())
-				}
-				healthCheckConfig.HealthCheckInterval = int32(healthCheckInterval)
-			}
-			healthCheckConfig.HealthCheckDomain = anno.Get(annotation.HealthCheckDomain)
-			healthCheckConfig.HealthCheckUrl = anno.Get(annotation.HealthCheckURI)
-			healthCheckConfig.HttpCheckMethod = anno.Get(annotation.HealthCheckMethod)
-			if anno.Get(annotation.HealthCheckHTTPCode) != "" {
-				healthCheckConfig.HealthCheckHttpCode = strings.Split(anno.Get(annotation.HealthCheckHTTPCode), ",")
+	if strings.EqualFold(anno.Get(annotation.IgnoreWeightUpdate), string(model.OnFlag)) {
+		sg.IgnoreWeightUpdate = true
+	}
+	return nil
+}
+
+// setHealthCheckConfigFromAnno sets the health check of the server group. health-check-flag turns the health
+// check on or off, and health-check-switch of the clb tcp & udp listeners is accepted if it is not set. The
+// other settings are merged into the default health check, e.g. the connect port of the any-port server groups.
+func setHealthCheckConfigFromAnno(sg *nlbmodel.ServerGroup, anno *annotation.AnnotationRequest) error {
+	flag := anno.Get(annotation.HealthCheckFlag)
+	if flag == "" {
+		flag = anno.Get(annotation.HealthCheckSwitch)
+	}
+	hc := sg.HealthCheckConfig
+	if hc == nil {
+		hc = &nlbmodel.HealthCheckConfig{}
+	}
+	if flag != "" {
+		hc.HealthCheckEnabled = tea.Bool(strings.EqualFold(flag, string(model.OnFlag)))
+	}
+
+	if hc.HealthCheckEnabled != nil && !*hc.HealthCheckEnabled {
+		// the settings are ignored when the health check is off
+		sg.HealthCheckConfig = &nlbmodel.HealthCheckConfig{HealthCheckEnabled: hc.HealthCheckEnabled}
+		return nil
+	}
+
+	changed := flag != ""
+	if v := anno.Get(annotation.HealthCheckType); v != "" {
+		t, err := getEnumValue(annotation.HealthCheckType, v, nlbHealthCheckTypes)
+		if err != nil {
+			return err
+		}
+		hc.HealthCheckType = t
+		changed = true
+	}
+	for _, i := range []struct {
+		key   string
+		value *int32
+	}{
+		{annotation.HealthCheckConnectPort, &hc.HealthCheckConnectPort},
+		{annotation.HealthyThreshold, &hc.HealthyThreshold},
+		{annotation.UnhealthyThreshold, &hc.UnhealthyThreshold},
+		// health-check-timeout of the clb http listeners is taken as the connect timeout
+		{annotation.HealthCheckTimeout, &hc.HealthCheckConnectTimeout},
+		{annotation.HealthCheckConnectTimeout, &hc.HealthCheckConnectTimeout},
+		{annotation.HealthCheckInterval, &hc.HealthCheckInterval},
+	} {
+		v := anno.Get(i.key)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("annotation %s must be integer, got [%s]", annotation.Annotation(i.key), v)
+		}
+		*i.value = int32(n)
+		changed = true
+	}
+	if v := anno.Get(annotation.HealthCheckDomain); v != "" {
+		hc.HealthCheckDomain = v
+		changed = true
+	}
+	if v := anno.Get(annotation.HealthCheckURI); v != "" {
+		hc.HealthCheckUrl = v
+		changed = true
+	}
+	if v := anno.Get(annotation.HealthCheckMethod); v != "" {
+		m, err := getEnumValue(annotation.HealthCheckMethod, v, nlbHealthCheckMethods)
+		if err != nil {
+			return err
+		}
+		hc.HttpCheckMethod = m
+		changed = true
+	}
+	if v := anno.Get(annotation.HealthCheckHTTPCode); v != "" {
+		var codes []string
+		for _, c := range strings.Split(v, ",") {
+			code, err := getEnumValue(annotation.HealthCheckHTTPCode, strings.TrimSpace(c), nlbHealthCheckHttpCodes)
+			if err != nil {
+				return err
 			}
+			codes = append(codes, code)
 		}
-		sg.HealthCheckConfig = healthCheckConfig
+		hc.HealthCheckHttpCode = codes
+		changed = true
 	}
 
-	if strings.EqualFold(anno.Get(annotation.IgnoreWeightUpdate), string(model.OnFlag)) {
-		sg.IgnoreWeightUpdate = true
+	if changed || sg.HealthCheckConfig != nil {
+		sg.HealthCheckConfig = hc
 	}
 	return nil
 }
 
+// getEnumValue returns the value of the annotation in the case of the OpenAPI, or an error if it is not one
+// of the valid values.
+func getEnumValue(key, value string, valid []string) (string, error) {
+	for _, v := range valid {
+		if strings.EqualFold(value, v) {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("annotation %s must be one of %v, got [%s]", annotation.Annotation(key), valid, value)
+}
+
 func diff(remote, local *nlbmodel.ServerGroup) (
 	[]nlbmodel.ServerGroupServer, []nlbmodel.ServerGroupServer, []nlbmodel.ServerGroupServer) {
 
@@ -1225,7 +1383,7 @@ func diff(remote, local *nlbmodel.ServerGroup) (
 	for _, l := range local.Servers {
 		for _, r := range remote.Servers {
 			if isServerEqual(l, r) {
-				if l.Port != r.Port || (!local.IgnoreWeightUpdate && l.Weight != r.Weight) || l.Description != r.Description {
+				if l.Port != r.Port || ((!local.IgnoreWeightUpdate || !l.DrainDeadline.IsZero()) && l.Weight != r.Weight) || l.Description != r.Description {
 					updates = append(updates, l)
 				}
 			}
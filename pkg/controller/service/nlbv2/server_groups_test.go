@@ -0,0 +1,129 @@
+package nlbv2
+
+import (
+	"testing"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+)
+
+func getServerGroupAnno(anno map[string]string) *annotation.AnnotationRequest {
+	annotations := make(map[string]string)
+	for k, v := range anno {
+		annotations[annotation.Annotation(k)] = v
+	}
+	return annotation.NewAnnotationRequest(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: ServiceName, Namespace: v1.NamespaceDefault, Annotations: annotations},
+	})
+}
+
+func TestSetServerGroupAttributeFromAnno(t *testing.T) {
+	sg := &nlbmodel.ServerGroup{}
+	err := setServerGroupAttributeFromAnno(sg, getServerGroupAnno(map[string]string{
+		annotation.Scheduler:              "wrr",
+		annotation.ConnectionDrain:        "on",
+		annotation.ConnectionDrainTimeout: "30",
+		annotation.HealthCheckFlag:        "on",
+		annotation.HealthCheckType:        "http",
+		annotation.HealthCheckDomain:      "www.example.com",
+		annotation.HealthCheckURI:         "/healthz",
+		annotation.HealthCheckMethod:      "head",
+		annotation.HealthCheckHTTPCode:    "http_2xx,http_3xx",
+		annotation.HealthCheckConnectPort: "8080",
+		annotation.HealthCheckTimeout:     "5",
+		annotation.HealthyThreshold:       "3",
+		annotation.UnhealthyThreshold:     "4",
+		annotation.HealthCheckInterval:    "10",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "Wrr", sg.Scheduler)
+	assert.Equal(t, tea.Bool(true), sg.ConnectionDrainEnabled)
+	assert.Equal(t, tea.Int32(30), sg.ConnectionDrainTimeout)
+	assert.Equal(t, &nlbmodel.HealthCheckConfig{
+		HealthCheckEnabled:        tea.Bool(true),
+		HealthCheckType:           "HTTP",
+		HealthCheckConnectPort:    8080,
+		HealthyThreshold:          3,
+		UnhealthyThreshold:        4,
+		HealthCheckConnectTimeout: 5,
+		HealthCheckInterval:       10,
+		HealthCheckDomain:         "www.example.com",
+		HealthCheckUrl:            "/healthz",
+		HealthCheckHttpCode:       []string{"http_2xx", "http_3xx"},
+		HttpCheckMethod:           "HEAD",
+	}, sg.HealthCheckConfig)
+
+	// the settings are merged into the health check of the any-port server groups
+	sg = &nlbmodel.ServerGroup{
+		AnyPortEnabled:    true,
+		HealthCheckConfig: &nlbmodel.HealthCheckConfig{HealthCheckEnabled: tea.Bool(true), HealthCheckConnectPort: 80},
+	}
+	err = setServerGroupAttributeFromAnno(sg, getServerGroupAnno(map[string]string{
+		annotation.HealthCheckSwitch:         "on",
+		annotation.HealthCheckConnectTimeout: "3",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, &nlbmodel.HealthCheckConfig{
+		HealthCheckEnabled:        tea.Bool(true),
+		HealthCheckConnectPort:    80,
+		HealthCheckConnectTimeout: 3,
+	}, sg.HealthCheckConfig)
+
+	// the settings are ignored if the health check is off
+	sg = &nlbmodel.ServerGroup{}
+	err = setServerGroupAttributeFromAnno(sg, getServerGroupAnno(map[string]string{
+		annotation.HealthCheckFlag: "off",
+		annotation.HealthCheckType: "tcp",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, &nlbmodel.HealthCheckConfig{HealthCheckEnabled: tea.Bool(false)}, sg.HealthCheckConfig)
+
+	sg = &nlbmodel.ServerGroup{}
+	err = setServerGroupAttributeFromAnno(sg, getServerGroupAnno(nil))
+	assert.NoError(t, err)
+	assert.Nil(t, sg.HealthCheckConfig)
+
+	for _, anno := range []map[string]string{
+		{annotation.Scheduler: "lc"},
+		{annotation.HealthCheckType: "https"},
+		{annotation.HealthCheckMethod: "POST"},
+		{annotation.HealthCheckHTTPCode: "http_2xx,200"},
+		{annotation.HealthCheckInterval: "ten"},
+	} {
+		err = setServerGroupAttributeFromAnno(&nlbmodel.ServerGroup{}, getServerGroupAnno(anno))
+		assert.Error(t, err, anno)
+	}
+}
+
+func TestSetServerGroupAttributeFromAnno_Persistence(t *testing.T) {
+	sg := &nlbmodel.ServerGroup{}
+	err := setServerGroupAttributeFromAnno(sg, getServerGroupAnno(map[string]string{
+		annotation.PersistenceTimeout: "1800",
+		annotation.Scheduler:          "sch",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, SchedulerSch, sg.Scheduler)
+
+	// the scheduler is not changed behind the user
+	err = setServerGroupAttributeFromAnno(&nlbmodel.ServerGroup{}, getServerGroupAnno(map[string]string{
+		annotation.PersistenceTimeout: "1800",
+	}))
+	assert.Error(t, err)
+
+	sg = &nlbmodel.ServerGroup{}
+	err = setServerGroupAttributeFromAnno(sg, getServerGroupAnno(map[string]string{
+		annotation.PersistenceTimeout: "0",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "", sg.Scheduler)
+
+	err = setServerGroupAttributeFromAnno(&nlbmodel.ServerGroup{}, getServerGroupAnno(map[string]string{
+		annotation.PersistenceTimeout: "1800",
+		annotation.Scheduler:          "rr",
+	}))
+	assert.Error(t, err)
+}
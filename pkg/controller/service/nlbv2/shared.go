@@ -0,0 +1,250 @@
+package nlbv2
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alibabacloud-go/tea/tea"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SharedPortConflictCondition is the condition of the service whose ports are taken by
+// another service of the shared group.
+const SharedPortConflictCondition = "SharedLoadBalancerPortConflict"
+
+// SharedPortConflictError is returned when a listener of the service overlaps with a listener of
+// an older service of the same shared group. The older service keeps the port.
+type SharedPortConflictError struct {
+	Group    string
+	Listener string
+	Owner    string
+}
+
+func (e *SharedPortConflictError) Error() string {
+	return fmt.Sprintf("listener [%s] conflicts with service %s in shared group %s", e.Listener, e.Owner, e.Group)
+}
+
+// sharedLoadBalancerName is the name of the nlb of a shared group, as well as the value of the
+// TAGKEY tag to find it. The groups are unique in the cluster.
+func sharedLoadBalancerName(group string) string {
+	return fmt.Sprintf("k8s-shared-%s-%s", group, base.CLUSTER_ID)
+}
+
+// defaultLoadBalancerName returns the default name of the nlb, the services of a shared group
+// find and create the same nlb.
+func defaultLoadBalancerName(anno *annotation.AnnotationRequest) string {
+	if group := anno.Get(annotation.SharedGroup); group != "" {
+		return sharedLoadBalancerName(group)
+	}
+	return anno.GetDefaultLoadBalancerName()
+}
+
+func defaultLoadBalancerTags(anno *annotation.AnnotationRequest) []tag.Tag {
+	tags := anno.GetDefaultTags()
+	for i := range tags {
+		if tags[i].Key == helper.TAGKEY {
+			tags[i].Value = defaultLoadBalancerName(anno)
+		}
+	}
+	return tags
+}
+
+// buildSharedGroup sets the shared group of the service and the other members of the group, which are listed
+// by the reader.
+func buildSharedGroup(reqCtx *svcCtx.RequestContext, kubeClient client.Client, reader client.Reader,
+	mdl *nlbmodel.NetworkLoadBalancer) ([]v1.Service, error) {
+	group := reqCtx.Anno.Get(annotation.SharedGroup)
+	if group == "" {
+		return nil, nil
+	}
+	if errs := validation.IsDNS1123Label(group); len(errs) != 0 {
+		return nil, fmt.Errorf("invalid shared group %s: %v", group, errs)
+	}
+	if reqCtx.Anno.Get(annotation.LoadBalancerId) != "" {
+		return nil, fmt.Errorf("annotation %s and %s can not be set at the same time",
+			annotation.LoadBalancerId, annotation.SharedGroup)
+	}
+
+	members, err := sharedGroupMembers(reqCtx, kubeClient, reader, group)
+	if err != nil {
+		return nil, err
+	}
+	mdl.LoadBalancerAttribute.SharedGroup = group
+	mdl.LoadBalancerAttribute.SharedOwner = util.Key(reqCtx.Service)
+	for i := range members {
+		mdl.LoadBalancerAttribute.SharedMembers = append(mdl.LoadBalancerAttribute.SharedMembers, util.Key(&members[i]))
+	}
+	if len(members) != 0 && isOlderService(&members[0], reqCtx.Service) {
+		mdl.LoadBalancerAttribute.SharedOwner = util.Key(&members[0])
+	}
+	return members, nil
+}
+
+// isSharedOwner returns whether the service creates and updates the nlb, which is true unless the nlb is
+// shared with an older service.
+func isSharedOwner(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer) bool {
+	return mdl.LoadBalancerAttribute.SharedGroup == "" ||
+		mdl.LoadBalancerAttribute.SharedOwner == util.Key(reqCtx.Service)
+}
+
+// sharedGroupOf returns the shared group of the service, which may be set by its LoadBalancerConfig.
+func sharedGroupOf(ctx context.Context, kubeClient client.Client, svc *v1.Service) (string, error) {
+	anno, err := annotation.NewAnnotationRequestWithConfig(ctx, kubeClient, svc)
+	if err != nil {
+		return "", err
+	}
+	return anno.Get(annotation.SharedGroup), nil
+}
+
+// sharedGroupMembers returns the other services of the group which still need the nlb, the older ones first.
+// The services are listed by the reader, the cache of the manager, as the kube client does not cache them.
+func sharedGroupMembers(reqCtx *svcCtx.RequestContext, kubeClient client.Client, reader client.Reader,
+	group string) ([]v1.Service, error) {
+	svcs := &v1.ServiceList{}
+	if err := reader.List(reqCtx.Ctx, svcs); err != nil {
+		return nil, fmt.Errorf("list services of shared group %s error: %s", group, err.Error())
+	}
+	var members []v1.Service
+	for _, svc := range svcs.Items {
+		if svc.Namespace == reqCtx.Service.Namespace && svc.Name == reqCtx.Service.Name {
+			continue
+		}
+		if helper.NeedDeleteLoadBalancer(&svc) || !helper.NeedNLB(&svc) {
+			continue
+		}
+		memberGroup, err := sharedGroupOf(reqCtx.Ctx, kubeClient, &svc)
+		if err != nil {
+			return nil, fmt.Errorf("shared group of service %s error: %s", util.Key(&svc), err.Error())
+		}
+		if memberGroup != group {
+			continue
+		}
+		members = append(members, svc)
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return isOlderService(&members[i], &members[j])
+	})
+	return members, nil
+}
+
+func isOlderService(a, b *v1.Service) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return util.Key(a) < util.Key(b)
+}
+
+// checkSharedPortConflict checks the listeners of the service against the listeners of the older
+// members, so that the conflicts are found before any listener is changed.
+func (mgr *ListenerManager) checkSharedPortConflict(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer,
+	members []v1.Service) error {
+	for i := range members {
+		member := &members[i]
+		if !isOlderService(member, reqCtx.Service) {
+			break
+		}
+		memberAnno, err := annotation.NewAnnotationRequestWithConfig(reqCtx.Ctx, mgr.kubeClient, member)
+		if err != nil {
+			return fmt.Errorf("shared group member %s error: %s", util.Key(member), err.Error())
+		}
+		memberCtx := &svcCtx.RequestContext{
+			Ctx:     reqCtx.Ctx,
+			Service: member,
+			Anno:    memberAnno,
+			Log:     reqCtx.Log,
+		}
+		for _, port := range member.Spec.Ports {
+			owned, err := mgr.buildListenerFromServicePort(memberCtx, port, false)
+			if err != nil {
+				// the invalid ports are reported by the member itself
+				reqCtx.Log.V(5).Info("skip invalid port of shared group member", "member", util.Key(member),
+					"port", port.Port, "error", err.Error())
+				continue
+			}
+			for _, l := range mdl.Listeners {
+				if isListenerPortOverlapped(l, owned) {
+					return &SharedPortConflictError{
+						Group:    mdl.LoadBalancerAttribute.SharedGroup,
+						Listener: fmt.Sprintf("%s:%s", l.ListenerProtocol, l.PortString()),
+						Owner:    util.Key(member),
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkSharedListenerOwner makes sure the listeners of the service do not take over the listeners
+// of the nlb owned by other services or created by the user.
+func checkSharedListenerOwner(reqCtx *svcCtx.RequestContext, local, remote *nlbmodel.NetworkLoadBalancer) error {
+	for _, r := range remote.Listeners {
+		if r.NamedKey != nil && r.NamedKey.IsManagedByService(reqCtx.Service, base.CLUSTER_ID) {
+			continue
+		}
+		owner := "user"
+		if r.NamedKey != nil {
+			owner = fmt.Sprintf("%s/%s", r.NamedKey.Namespace, r.NamedKey.ServiceName)
+		}
+		for _, l := range local.Listeners {
+			if isListenerPortOverlapped(l, r) {
+				return fmt.Errorf("listener [%s:%s] is in use by %s, listener %s",
+					l.ListenerProtocol, l.PortString(), owner, r.ListenerId)
+			}
+		}
+	}
+	return nil
+}
+
+// sharedAttributeConflicts returns the attributes of the service which differ from the ones of the shared nlb.
+// They are ignored, as the nlb is updated only by the owner of the group.
+func sharedAttributeConflicts(local, remote *nlbmodel.NetworkLoadBalancer) []string {
+	l, r := local.LoadBalancerAttribute, remote.LoadBalancerAttribute
+	var conflicts []string
+	if l.AddressType != "" && !strings.EqualFold(l.AddressType, r.AddressType) {
+		conflicts = append(conflicts, "AddressType")
+	}
+	if l.AddressIpVersion != "" && !strings.EqualFold(l.AddressIpVersion, r.AddressIpVersion) {
+		conflicts = append(conflicts, "AddressIpVersion")
+	}
+	if l.IPv6AddressType != "" && !strings.EqualFold(l.IPv6AddressType, r.IPv6AddressType) {
+		conflicts = append(conflicts, "IPv6AddressType")
+	}
+	if l.ResourceGroupId != "" && l.ResourceGroupId != r.ResourceGroupId {
+		conflicts = append(conflicts, "ResourceGroupId")
+	}
+	if l.Name != "" && l.Name != r.Name {
+		conflicts = append(conflicts, "Name")
+	}
+	if l.BandwidthPackageId != nil && tea.StringValue(l.BandwidthPackageId) != tea.StringValue(r.BandwidthPackageId) {
+		conflicts = append(conflicts, "BandwidthPackageId")
+	}
+	if l.SecurityGroupIds != nil && !util.IsStringSliceEqual(l.SecurityGroupIds, r.SecurityGroupIds) {
+		conflicts = append(conflicts, "SecurityGroupIds")
+	}
+	if len(l.AclRules) != 0 {
+		conflicts = append(conflicts, "Acl")
+	}
+	zones := func(mappings []nlbmodel.ZoneMapping) []string {
+		var ret []string
+		for _, z := range mappings {
+			ret = append(ret, z.ZoneId+":"+z.VSwitchId)
+		}
+		return ret
+	}
+	if len(l.ZoneMappings) != 0 && !util.IsStringSliceEqual(zones(l.ZoneMappings), zones(r.ZoneMappings)) {
+		conflicts = append(conflicts, "ZoneMappings")
+	}
+	return conflicts
+}
@@ -0,0 +1,232 @@
+package nlbv2
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func sharedGroupService(namespace, name string, port int32, created time.Time) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			UID:               types.UID(namespace + "-" + name),
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations: map[string]string{
+				annotation.Annotation(annotation.SharedGroup): "web",
+				annotation.Annotation(annotation.ZoneMaps):    "cn-hangzhou-a:vsw-1,cn-hangzhou-b:vsw-2",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Type:              v1.ServiceTypeLoadBalancer,
+			LoadBalancerClass: tea.String(helper.NLBClass),
+			Ports: []v1.ServicePort{
+				{Name: "tcp", Port: port, TargetPort: intstr.FromInt(80), NodePort: 30080, Protocol: v1.ProtocolTCP},
+			},
+		},
+	}
+}
+
+func TestSharedLoadBalancer(t *testing.T) {
+	now := time.Now()
+	svcA := sharedGroupService("ns1", "a", 80, now.Add(-time.Hour))
+	svcB := sharedGroupService("ns2", "b", 80, now)
+	kubeClient := fake.NewClientBuilder().WithObjects(svcA, svcB).Build()
+	var state *snapshot.Snapshot
+	cloud := snapshot.NewSnapshotCloud(&snapshot.Snapshot{Region: "cn-hangzhou", VpcID: "vpc-id"},
+		snapshot.WithChangeHandler(func(s *snapshot.Snapshot, _ []snapshot.Operation) { state = s }))
+
+	nlbManager := NewNLBManager(cloud)
+	listenerManager := NewListenerManager(kubeClient, cloud)
+	serverGroupManager, err := NewServerGroupManager(kubeClient, cloud)
+	assert.Nil(t, err)
+	builder := NewModelBuilder(nlbManager, listenerManager, serverGroupManager)
+	applier := NewModelApplier(nlbManager, listenerManager, serverGroupManager)
+	apply := func(svc *v1.Service) error {
+		reqCtx := getReqCtx(svc)
+		reqCtx.Recorder = record.NewFakeRecorder(100)
+		local, err := builder.Instance(LocalModel).Build(reqCtx)
+		if err != nil {
+			return err
+		}
+		_, err = applier.Apply(reqCtx, local)
+		return err
+	}
+
+	// the newer service loses the port
+	assert.Nil(t, apply(svcA))
+	var conflict *SharedPortConflictError
+	assert.True(t, errors.As(apply(svcB), &conflict))
+	assert.Equal(t, "ns1/a", conflict.Owner)
+
+	// the newer service manages its own listeners only, its attributes of the nlb are ignored
+	svcB.Spec.Ports[0].Port = 443
+	svcB.Annotations[annotation.Annotation(annotation.LoadBalancerName)] = "b"
+	assert.Nil(t, kubeClient.Update(context.TODO(), svcB))
+	assert.Nil(t, apply(svcB))
+	lbs := state.NetworkLoadBalancers
+	assert.Equal(t, 1, len(lbs))
+	assert.Equal(t, sharedLoadBalancerName("web"), lbs[0].LoadBalancerAttribute.Name)
+	assert.Equal(t, 2, len(lbs[0].Listeners))
+
+	// the nlb is kept until the last member leaves
+	svcB.Spec.Type = v1.ServiceTypeClusterIP
+	assert.Nil(t, kubeClient.Update(context.TODO(), svcB))
+	assert.Nil(t, apply(svcB))
+	lbs = state.NetworkLoadBalancers
+	assert.Equal(t, 1, len(lbs))
+	assert.Equal(t, 1, len(lbs[0].Listeners))
+	assert.Equal(t, int32(80), lbs[0].Listeners[0].ListenerPort)
+
+	svcA.Spec.Type = v1.ServiceTypeClusterIP
+	assert.Nil(t, kubeClient.Update(context.TODO(), svcA))
+	assert.Nil(t, apply(svcA))
+	assert.Equal(t, 0, len(state.NetworkLoadBalancers))
+}
+
+func TestSharedLoadBalancerEips(t *testing.T) {
+	now := time.Now()
+	svcA := sharedGroupService("ns1", "a", 80, now.Add(-time.Hour))
+	svcB := sharedGroupService("ns2", "b", 443, now)
+	for _, svc := range []*v1.Service{svcA, svcB} {
+		svc.Annotations[annotation.Annotation(annotation.AllocateEip)] = "on"
+	}
+	kubeClient := fake.NewClientBuilder().WithObjects(svcA, svcB).Build()
+	var state *snapshot.Snapshot
+	cloud := snapshot.NewSnapshotCloud(&snapshot.Snapshot{Region: "cn-hangzhou", VpcID: "vpc-id"},
+		snapshot.WithChangeHandler(func(s *snapshot.Snapshot, _ []snapshot.Operation) { state = s }))
+
+	nlbManager := NewNLBManager(cloud)
+	listenerManager := NewListenerManager(kubeClient, cloud)
+	serverGroupManager, err := NewServerGroupManager(kubeClient, cloud)
+	assert.Nil(t, err)
+	builder := NewModelBuilder(nlbManager, listenerManager, serverGroupManager)
+	applier := NewModelApplier(nlbManager, listenerManager, serverGroupManager)
+	apply := func(svc *v1.Service) (*svcCtx.RequestContext, *nlbmodel.NetworkLoadBalancer) {
+		reqCtx := getReqCtx(svc)
+		reqCtx.Recorder = record.NewFakeRecorder(100)
+		local, err := builder.Instance(LocalModel).Build(reqCtx)
+		assert.Nil(t, err)
+		remote, err := applier.Apply(reqCtx, local)
+		assert.Nil(t, err)
+		return reqCtx, remote
+	}
+
+	// the eips are allocated by the owner only, the other member publishes the same ones
+	reqCtx, remote := apply(svcA)
+	assert.Equal(t, 2, len(state.EipAddresses))
+	addresses, err := nlbManager.EipAddresses(reqCtx, remote)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(addresses))
+	reqCtx, remote = apply(svcB)
+	assert.Equal(t, 2, len(state.EipAddresses))
+	shared, err := nlbManager.EipAddresses(reqCtx, remote)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, addresses, shared)
+
+	// the eips are kept while the nlb is shared, and released with it
+	svcB.Spec.Type = v1.ServiceTypeClusterIP
+	assert.Nil(t, kubeClient.Update(context.TODO(), svcB))
+	reqCtx, remote = apply(svcB)
+	assert.Nil(t, nlbManager.GarbageCollectEips(reqCtx, remote))
+	assert.Equal(t, 2, len(state.EipAddresses))
+
+	svcA.Spec.Type = v1.ServiceTypeClusterIP
+	assert.Nil(t, kubeClient.Update(context.TODO(), svcA))
+	reqCtx, remote = apply(svcA)
+	assert.Nil(t, nlbManager.GarbageCollectEips(reqCtx, remote))
+	assert.Equal(t, 0, len(state.EipAddresses))
+}
+
+func TestSharedAttributeConflicts(t *testing.T) {
+	local := &nlbmodel.NetworkLoadBalancer{LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{
+		AddressType:  nlbmodel.InternetAddressType,
+		ZoneMappings: []nlbmodel.ZoneMapping{{ZoneId: "cn-hangzhou-a", VSwitchId: "vsw-1"}},
+	}}
+	remote := &nlbmodel.NetworkLoadBalancer{LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{
+		AddressType:  nlbmodel.IntranetAddressType,
+		ZoneMappings: []nlbmodel.ZoneMapping{{ZoneId: "cn-hangzhou-a", VSwitchId: "vsw-1", AllocationId: "eip-1"}},
+		Name:         "shared",
+	}}
+	assert.Equal(t, []string{"AddressType"}, sharedAttributeConflicts(local, remote))
+	local.LoadBalancerAttribute.AddressType = nlbmodel.IntranetAddressType
+	assert.Empty(t, sharedAttributeConflicts(local, remote))
+}
+
+func TestEnqueueRequestForSharedGroupEvent(t *testing.T) {
+	now := time.Now()
+	owner := sharedGroupService("ns1", "a", 80, now.Add(-time.Hour))
+	conflicted := sharedGroupService("ns2", "b", 80, now)
+	conflicted.Status.Conditions = []metav1.Condition{{Type: SharedPortConflictCondition, Status: metav1.ConditionTrue}}
+	other := sharedGroupService("ns3", "c", 80, now)
+	other.Annotations[annotation.Annotation(annotation.SharedGroup)] = "api"
+	other.Status.Conditions = conflicted.Status.Conditions
+	kubeClient := fake.NewClientBuilder().WithObjects(owner, conflicted, other).Build()
+	h := NewEnqueueRequestForSharedGroupEvent(kubeClient, kubeClient)
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+
+	// nothing changes
+	h.Update(context.TODO(), event.UpdateEvent{ObjectOld: owner, ObjectNew: owner.DeepCopy()}, queue)
+	assert.Equal(t, 0, queue.Len())
+
+	h.Delete(context.TODO(), event.DeleteEvent{Object: owner}, queue)
+	assert.Equal(t, 1, queue.Len())
+	item, _ := queue.Get()
+	assert.Equal(t, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns2", Name: "b"}}, item)
+}
+
+func TestCheckSharedListenerOwner(t *testing.T) {
+	svc := sharedGroupService("ns1", "a", 80, time.Now())
+	local, err := NewListenerManager(fake.NewClientBuilder().Build(), getMockCloudProvider()).
+		buildListenerFromServicePort(getReqCtx(svc), svc.Spec.Ports[0], false)
+	assert.Nil(t, err)
+	other := sharedGroupService("ns2", "b", 80, time.Now())
+	remote, err := NewListenerManager(fake.NewClientBuilder().Build(), getMockCloudProvider()).
+		buildListenerFromServicePort(getReqCtx(other), other.Spec.Ports[0], false)
+	assert.Nil(t, err)
+
+	model := func(l *nlbmodel.ListenerAttribute) *nlbmodel.NetworkLoadBalancer {
+		return &nlbmodel.NetworkLoadBalancer{Listeners: []*nlbmodel.ListenerAttribute{l}}
+	}
+	assert.Nil(t, checkSharedListenerOwner(getReqCtx(svc), model(local), model(local)))
+	assert.NotNil(t, checkSharedListenerOwner(getReqCtx(svc), model(local), model(remote)))
+	remote.NamedKey = nil
+	assert.NotNil(t, checkSharedListenerOwner(getReqCtx(svc), model(local), model(remote)))
+}
+
+func TestReconcileNLB_updateSharedPortCondition(t *testing.T) {
+	svc := sharedGroupService("ns2", "b", 80, time.Now())
+	recon := &ReconcileNLB{kubeClient: fake.NewClientBuilder().WithObjects(svc).Build()}
+	conflict := &SharedPortConflictError{Group: "web", Listener: "TCP:80", Owner: "ns1/a"}
+
+	assert.Nil(t, recon.updateSharedPortCondition(getReqCtx(svc), conflict))
+	updated := &v1.Service{}
+	assert.Nil(t, recon.kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: "ns2", Name: "b"}, updated))
+	cond := meta.FindStatusCondition(updated.Status.Conditions, SharedPortConflictCondition)
+	assert.NotNil(t, cond)
+	assert.Equal(t, conflict.Error(), cond.Message)
+
+	assert.Nil(t, recon.updateSharedPortCondition(getReqCtx(updated), nil))
+	assert.Nil(t, recon.kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: "ns2", Name: "b"}, updated))
+	assert.Nil(t, meta.FindStatusCondition(updated.Status.Conditions, SharedPortConflictCondition))
+}
@@ -0,0 +1,123 @@
+package nlbv2
+
+import (
+	"fmt"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/acl"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/eip"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+// ValidateService checks the annotations of the service with the builders of the local model, without
+// calling the OpenAPIs. oldAnno is the annotations before the update, or nil on creation, and is used to
+// reject the changes the load balancer does not accept once created. The values which are likely to be
+// a mistake are returned as warnings.
+func ValidateService(reqCtx *svcCtx.RequestContext, oldAnno *annotation.AnnotationRequest) ([]string, error) {
+	var warnings []string
+	if needDeleteLoadBalancer(reqCtx.Service) {
+		return warnings, nil
+	}
+
+	local, err := buildValidationModel(reqCtx)
+	if err != nil {
+		return warnings, err
+	}
+
+	for _, c := range []struct{ secretAnno, idAnno string }{
+		{annotation.CertSecret, annotation.CertID},
+		{annotation.CaCertSecret, annotation.CaCertID},
+	} {
+		if reqCtx.Anno.Get(c.secretAnno) != "" && reqCtx.Anno.Get(c.idAnno) != "" {
+			return warnings, fmt.Errorf("annotation %s and %s can not be set at the same time", c.idAnno, c.secretAnno)
+		}
+	}
+	if reqCtx.Anno.Get(annotation.CertID) != "" || reqCtx.Anno.Get(annotation.CertSecret) != "" {
+		hasTCPSSL := false
+		for _, l := range local.Listeners {
+			if isTCPSSL(l.ListenerProtocol) {
+				hasTCPSSL = true
+			}
+		}
+		if !hasTCPSSL {
+			warnings = append(warnings, fmt.Sprintf("the certificate is only used by TCPSSL listeners, "+
+				"set annotation %s to use TCPSSL for the ports", annotation.Annotation(annotation.ProtocolPort)))
+		}
+	}
+
+	if oldAnno == nil || !helper.HasFinalizer(oldAnno.Service, helper.NLBFinalizer) ||
+		needDeleteLoadBalancer(oldAnno.Service) {
+		return warnings, nil
+	}
+	old, err := buildValidationModel(&svcCtx.RequestContext{
+		Ctx:     reqCtx.Ctx,
+		Service: oldAnno.Service,
+		Anno:    oldAnno,
+		Log:     reqCtx.Log,
+	})
+	if err != nil {
+		// the old annotations are invalid, there is nothing to compare with
+		return warnings, nil
+	}
+	oldAttr, newAttr := old.LoadBalancerAttribute, local.LoadBalancerAttribute
+	oldIPVersion, newIPVersion := oldAttr.AddressIpVersion, newAttr.AddressIpVersion
+	if oldIPVersion == "" {
+		oldIPVersion = nlbmodel.IPv4
+	}
+	if newIPVersion == "" {
+		newIPVersion = nlbmodel.IPv4
+	}
+	for _, c := range []struct{ k, old, new string }{
+		{annotation.IPVersion, oldIPVersion, newIPVersion},
+		{annotation.ResourceGroupId, oldAttr.ResourceGroupId, newAttr.ResourceGroupId},
+	} {
+		warning, err := annotation.ValidateImmutable(c.k, c.old, c.new)
+		if err != nil {
+			return warnings, err
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings, nil
+}
+
+// buildValidationModel builds the attributes of the load balancer, the listeners and the server groups
+// of the local model, which only depend on the annotations of the service.
+func buildValidationModel(reqCtx *svcCtx.RequestContext) (*nlbmodel.NetworkLoadBalancer, error) {
+	mdl := &nlbmodel.NetworkLoadBalancer{
+		NamespacedName:        util.NamespacedName(reqCtx.Service),
+		LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{},
+	}
+	if err := (&NLBManager{}).BuildLocalModel(reqCtx, mdl); err != nil {
+		return nil, fmt.Errorf("build nlb attribute error: %s", err.Error())
+	}
+	lisMgr := &ListenerManager{}
+	for _, port := range reqCtx.Service.Spec.Ports {
+		listener, err := lisMgr.buildListenerFromServicePort(reqCtx, port, mdl.LoadBalancerAttribute.IsUserManaged)
+		if err != nil {
+			return nil, fmt.Errorf("build listener from servicePort %d error: %s", port.Port, err.Error())
+		}
+		anno := reqCtx.Anno.ForPort(port.Port)
+		if err := setServerGroupAttributeFromAnno(&nlbmodel.ServerGroup{}, anno); err != nil {
+			return nil, fmt.Errorf("build server group from servicePort %d error: %s", port.Port, err.Error())
+		}
+		if err := anno.ValidateIntRanges(annotation.NLBIntRanges); err != nil {
+			return nil, fmt.Errorf("build listener from servicePort %d error: %s", port.Port, err.Error())
+		}
+		mdl.Listeners = append(mdl.Listeners, listener)
+	}
+	if err := checkListenersPortOverlap(mdl.Listeners); err != nil {
+		return nil, fmt.Errorf("build nlb listener error: %s", err.Error())
+	}
+	if _, err := acl.GetNLBRules(reqCtx.Anno, mdl); err != nil {
+		return nil, fmt.Errorf("build acl security group error: %s", err.Error())
+	}
+	if _, err := eip.GetNLBOptions(reqCtx.Anno, mdl); err != nil {
+		return nil, fmt.Errorf("build eip error: %s", err.Error())
+	}
+	return mdl, nil
+}
@@ -0,0 +1,88 @@
+package nlbv2
+
+import (
+	"testing"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+)
+
+func getValidationService() *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ServiceName,
+			Namespace: v1.NamespaceDefault,
+			Annotations: map[string]string{
+				annotation.Annotation(annotation.ZoneMaps): "cn-hangzhou-a:vsw-1,cn-hangzhou-b:vsw-2",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:       "tcp",
+					Port:       80,
+					TargetPort: intstr.FromInt(80),
+					NodePort:   80,
+					Protocol:   v1.ProtocolTCP,
+				},
+			},
+			Type:              v1.ServiceTypeLoadBalancer,
+			LoadBalancerClass: tea.String(helper.NLBClass),
+		},
+	}
+}
+
+func TestValidateService(t *testing.T) {
+	svc := getValidationService()
+	svc.Annotations[annotation.Annotation(annotation.ZoneMaps)] = "cn-hangzhou-a"
+	_, err := ValidateService(getReqCtx(svc), nil)
+	assert.Error(t, err)
+
+	svc = getValidationService()
+	svc.Annotations[annotation.Annotation(annotation.CertID)] = "cert-id"
+	warnings, err := ValidateService(getReqCtx(svc), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(warnings))
+
+	svc.Annotations[annotation.Annotation(annotation.ProtocolPort)] = "tcpssl:80"
+	warnings, err = ValidateService(getReqCtx(svc), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(warnings))
+
+	svc = getValidationService()
+	svc.Annotations[annotation.Annotation(annotation.AclCidrs)] = "10.0.0.0/8"
+	_, err = ValidateService(getReqCtx(svc), nil)
+	assert.NoError(t, err)
+
+	svc.Annotations[annotation.Annotation(annotation.AclCidrs)] = "2001:db8::/32"
+	_, err = ValidateService(getReqCtx(svc), nil)
+	assert.Error(t, err)
+
+	svc = getValidationService()
+	svc.Annotations[annotation.Annotation(annotation.AllocateEip)] = "on"
+	_, err = ValidateService(getReqCtx(svc), nil)
+	assert.NoError(t, err)
+
+	svc.Annotations[annotation.Annotation(annotation.EipBandwidth)] = "1000"
+	_, err = ValidateService(getReqCtx(svc), nil)
+	assert.Error(t, err)
+}
+
+func TestValidateService_Immutable(t *testing.T) {
+	oldSvc := getValidationService()
+	oldSvc.Finalizers = []string{helper.NLBFinalizer}
+	svc := oldSvc.DeepCopy()
+	svc.Annotations[annotation.Annotation(annotation.IPVersion)] = "DualStack"
+	_, err := ValidateService(getReqCtx(svc), annotation.NewAnnotationRequest(oldSvc))
+	assert.Error(t, err)
+
+	svc = oldSvc.DeepCopy()
+	svc.Annotations[annotation.Annotation(annotation.IPVersion)] = "ipv4"
+	_, err = ValidateService(getReqCtx(svc), annotation.NewAnnotationRequest(oldSvc))
+	assert.NoError(t, err)
+}
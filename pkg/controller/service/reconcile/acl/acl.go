@@ -0,0 +1,112 @@
+package acl
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+)
+
+const (
+	AclTypeWhite = "white"
+	AclTypeBlack = "black"
+)
+
+// Declared returns whether the service declares the cidrs of its acl, by spec.loadBalancerSourceRanges
+// or the acl-cidrs annotation. The acls of these services are created and deleted by the controller.
+func Declared(anno *annotation.AnnotationRequest) bool {
+	return len(getSourceRanges(anno)) != 0 || anno.Get(annotation.AclCidrs) != ""
+}
+
+// getSourceRanges returns spec.loadBalancerSourceRanges if the service turns on the acl-source-ranges
+// annotation. They were ignored before, so that the services setting them are not restricted silently.
+func getSourceRanges(anno *annotation.AnnotationRequest) []string {
+	if model.FlagType(anno.Get(annotation.AclSourceRanges)) != model.OnFlag {
+		return nil
+	}
+	return anno.Service.Spec.LoadBalancerSourceRanges
+}
+
+// GetCidrs returns the acl type and the normalized, deduplicated and sorted cidrs declared by the service.
+// spec.loadBalancerSourceRanges is a white list, the acl-cidrs annotation follows the acl-type annotation
+// and is a white list by default. It returns no cidrs if the service declares none.
+func GetCidrs(anno *annotation.AnnotationRequest) (string, []string, error) {
+	sourceRanges := getSourceRanges(anno)
+	annoCidrs := anno.Get(annotation.AclCidrs)
+	if len(sourceRanges) == 0 && annoCidrs == "" {
+		return "", nil, nil
+	}
+	if len(sourceRanges) != 0 && annoCidrs != "" {
+		return "", nil, fmt.Errorf("spec.loadBalancerSourceRanges and annotation %s can not be set at the same time",
+			annotation.Annotation(annotation.AclCidrs))
+	}
+	if anno.Get(annotation.AclID) != "" {
+		return "", nil, fmt.Errorf("annotation %s can not be set with the cidrs of the acl",
+			annotation.Annotation(annotation.AclID))
+	}
+
+	aclType := strings.ToLower(anno.Get(annotation.AclType))
+	var cidrs []string
+	if len(sourceRanges) != 0 {
+		if aclType != "" && aclType != AclTypeWhite {
+			return "", nil, fmt.Errorf("spec.loadBalancerSourceRanges is a white list, "+
+				"annotation %s must be %s, got [%s]", annotation.Annotation(annotation.AclType), AclTypeWhite, aclType)
+		}
+		aclType = AclTypeWhite
+		cidrs = sourceRanges
+	} else {
+		if aclType == "" {
+			aclType = AclTypeWhite
+		}
+		if aclType != AclTypeWhite && aclType != AclTypeBlack {
+			return "", nil, fmt.Errorf("annotation %s must be %s or %s, got [%s]",
+				annotation.Annotation(annotation.AclType), AclTypeWhite, AclTypeBlack, aclType)
+		}
+		cidrs = strings.Split(annoCidrs, ",")
+	}
+
+	ret, err := normalizeCidrs(cidrs)
+	if err != nil {
+		return "", nil, err
+	}
+	return aclType, ret, nil
+}
+
+func normalizeCidrs(cidrs []string) ([]string, error) {
+	seen := map[string]bool{}
+	var ret []string
+	for _, c := range cidrs {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr [%s] of the acl: %s", c, err.Error())
+		}
+		if !seen[ipNet.String()] {
+			seen[ipNet.String()] = true
+			ret = append(ret, ipNet.String())
+		}
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// isIPv6 returns whether the normalized cidr is an ipv6 one.
+func isIPv6(cidr string) bool {
+	return strings.Contains(cidr, ":")
+}
+
+// buildName returns the name of the acl or the security group created for the service.
+func buildName(svc *v1.Service, maxLength int) string {
+	name := fmt.Sprintf("k8s-%s-%s", svc.Namespace, svc.Name)
+	if len(name) > maxLength {
+		name = name[:maxLength]
+	}
+	return name
+}
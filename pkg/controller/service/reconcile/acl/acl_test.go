@@ -0,0 +1,214 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func getService(sourceRanges []string, anno map[string]string) *v1.Service {
+	annotations := make(map[string]string)
+	if sourceRanges != nil {
+		annotations[annotation.Annotation(annotation.AclSourceRanges)] = string(model.OnFlag)
+	}
+	for k, v := range anno {
+		annotations[annotation.Annotation(k)] = v
+	}
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "acl", Namespace: v1.NamespaceDefault, UID: "uid-acl", Annotations: annotations},
+		Spec:       v1.ServiceSpec{LoadBalancerSourceRanges: sourceRanges},
+	}
+}
+
+func getReqCtx(svc *v1.Service) *svcCtx.RequestContext {
+	return &svcCtx.RequestContext{
+		Ctx:     context.TODO(),
+		Service: svc,
+		Anno:    annotation.NewAnnotationRequest(svc),
+		Log:     util.ServiceLog.WithValues("service", util.Key(svc)),
+	}
+}
+
+func TestGetCidrs(t *testing.T) {
+	aclType, cidrs, err := GetCidrs(annotation.NewAnnotationRequest(getService(
+		[]string{"10.0.0.1/8", "192.168.0.0/16", "10.0.0.0/8"}, nil)))
+	assert.NoError(t, err)
+	assert.Equal(t, AclTypeWhite, aclType)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.0.0/16"}, cidrs)
+
+	aclType, cidrs, err = GetCidrs(annotation.NewAnnotationRequest(getService(nil, map[string]string{
+		annotation.AclCidrs: "1.1.1.1/32, 2001:db8::/32",
+		annotation.AclType:  "black",
+	})))
+	assert.NoError(t, err)
+	assert.Equal(t, AclTypeBlack, aclType)
+	assert.Equal(t, []string{"1.1.1.1/32", "2001:db8::/32"}, cidrs)
+
+	_, cidrs, err = GetCidrs(annotation.NewAnnotationRequest(getService(nil, nil)))
+	assert.NoError(t, err)
+	assert.Nil(t, cidrs)
+
+	// the source ranges are ignored unless the service turns on the acl-source-ranges annotation
+	svc := getService([]string{"10.0.0.0/8"}, map[string]string{annotation.AclID: "acl-id"})
+	delete(svc.Annotations, annotation.Annotation(annotation.AclSourceRanges))
+	_, cidrs, err = GetCidrs(annotation.NewAnnotationRequest(svc))
+	assert.NoError(t, err)
+	assert.Nil(t, cidrs)
+	assert.False(t, Declared(annotation.NewAnnotationRequest(svc)))
+
+	for _, svc := range []*v1.Service{
+		getService([]string{"10.0.0.0/8"}, map[string]string{annotation.AclCidrs: "10.0.0.0/8"}),
+		getService([]string{"10.0.0.0/8"}, map[string]string{annotation.AclType: "black"}),
+		getService(nil, map[string]string{annotation.AclCidrs: "10.0.0.0/8", annotation.AclID: "acl-id"}),
+		getService(nil, map[string]string{annotation.AclCidrs: "10.0.0.0/8", annotation.AclType: "gray"}),
+		getService(nil, map[string]string{annotation.AclCidrs: "10.0.0.1"}),
+	} {
+		_, _, err = GetCidrs(annotation.NewAnnotationRequest(svc))
+		assert.Error(t, err, svc.Annotations)
+	}
+}
+
+func TestCLBManager(t *testing.T) {
+	cloud := snapshot.NewSnapshotCloud(&snapshot.Snapshot{})
+	mgr := NewCLBManager(cloud)
+
+	reqCtx := getReqCtx(getService([]string{"10.0.0.0/8", "192.168.0.0/16"}, nil))
+	aclType, cidrs, err := GetCLBCidrs(reqCtx.Anno, "")
+	assert.NoError(t, err)
+	assert.Equal(t, AclTypeWhite, aclType)
+	aclId, err := mgr.Sync(reqCtx, cidrs, "")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, aclId)
+	acls, err := mgr.List(reqCtx)
+	assert.NoError(t, err)
+	assert.Len(t, acls, 1)
+	assert.Equal(t, "k8s-default-acl", acls[0].AclName)
+	assert.Equal(t, "ipv4", acls[0].AddressIPVersion)
+	cloud.Operations()
+
+	// only the changed entries are added and removed
+	reqCtx = getReqCtx(getService([]string{"10.0.0.0/8", "172.16.0.0/12"}, nil))
+	id, err := mgr.Sync(reqCtx, []string{"10.0.0.0/8", "172.16.0.0/12"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, aclId, id)
+	ops := cloud.Operations()
+	assert.Len(t, ops, 1)
+	assert.Equal(t, []string{`AclEntry: + "172.16.0.0/12"`, `AclEntry: - "192.168.0.0/16"`}, ops[0].Changes)
+	entries, err := cloud.DescribeCLBAccessControlListEntries(reqCtx.Ctx, aclId)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.AclEntry{{Entry: "10.0.0.0/8"}, {Entry: "172.16.0.0/12"}}, entries)
+
+	// nothing changes if the entries are in sync
+	_, err = mgr.Sync(reqCtx, []string{"10.0.0.0/8", "172.16.0.0/12"}, "")
+	assert.NoError(t, err)
+	assert.Empty(t, cloud.Operations())
+
+	// the entries are removed first if the quota can not hold the old and the new ones
+	cidrs = nil
+	for i := 0; i < MaxAclEntries; i++ {
+		cidrs = append(cidrs, fmt.Sprintf("10.%d.%d.0/24", i/256, i%256))
+	}
+	_, err = mgr.Sync(reqCtx, cidrs, "")
+	assert.NoError(t, err)
+	ops = cloud.Operations()
+	assert.Len(t, ops, 1)
+	assert.Contains(t, ops[0].Changes[0], "AclEntry: -")
+
+	// the quota is checked before any call
+	_, _, err = GetCLBCidrs(getReqCtx(getService(append(cidrs, "192.168.0.0/24"), nil)).Anno, "")
+	assert.Error(t, err)
+
+	_, _, err = GetCLBCidrs(getReqCtx(getService([]string{"2001:db8::/32"}, nil)).Anno, model.IPv4)
+	assert.Error(t, err)
+
+	assert.NoError(t, mgr.GarbageCollect(reqCtx))
+	acls, err = mgr.List(reqCtx)
+	assert.NoError(t, err)
+	assert.Empty(t, acls)
+}
+
+func getNLB(listeners ...*nlbmodel.ListenerAttribute) *nlbmodel.NetworkLoadBalancer {
+	return &nlbmodel.NetworkLoadBalancer{
+		LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{AddressIpVersion: nlbmodel.IPv4},
+		Listeners:             listeners,
+	}
+}
+
+func TestBuildRules(t *testing.T) {
+	listeners := []*nlbmodel.ListenerAttribute{
+		{ListenerProtocol: nlbmodel.TCPSSL, ListenerPort: 443},
+		{ListenerProtocol: nlbmodel.UDP, StartPort: 1000, EndPort: 2000},
+	}
+	assert.Equal(t, []model.SecurityGroupRule{
+		{Policy: "accept", IpProtocol: "tcp", PortRange: "443/443", SourceCidrIp: "10.0.0.0/8", Priority: 1},
+		{Policy: "accept", IpProtocol: "udp", PortRange: "1000/2000", SourceCidrIp: "10.0.0.0/8", Priority: 1},
+	}, BuildRules(AclTypeWhite, []string{"10.0.0.0/8"}, listeners, false))
+
+	assert.Equal(t, []model.SecurityGroupRule{
+		{Policy: "drop", IpProtocol: "tcp", PortRange: "443/443", SourceCidrIp: "10.0.0.0/8", Priority: 1},
+		{Policy: "accept", IpProtocol: "tcp", PortRange: "443/443", SourceCidrIp: "0.0.0.0/0", Priority: 100},
+		{Policy: "accept", IpProtocol: "tcp", PortRange: "443/443", SourceCidrIp: "::/0", Priority: 100},
+	}, BuildRules(AclTypeBlack, []string{"10.0.0.0/8"}, listeners[:1], true))
+}
+
+func TestNLBManager(t *testing.T) {
+	cloud := snapshot.NewSnapshotCloud(&snapshot.Snapshot{VpcID: "vpc-1"})
+	mgr := NewNLBManager(cloud)
+
+	reqCtx := getReqCtx(getService(nil, map[string]string{annotation.AclCidrs: "10.0.0.0/8,192.168.0.0/16"}))
+	rules, err := GetNLBRules(reqCtx.Anno, getNLB(&nlbmodel.ListenerAttribute{ListenerProtocol: nlbmodel.TCP, ListenerPort: 80}))
+	assert.NoError(t, err)
+	sgId, err := mgr.Sync(reqCtx, rules, "")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, sgId)
+	sgs, err := mgr.List(reqCtx)
+	assert.NoError(t, err)
+	assert.Len(t, sgs, 1)
+	assert.Equal(t, "vpc-1", sgs[0].VpcId)
+	remote, err := cloud.DescribeSecurityGroupRules(reqCtx.Ctx, sgId)
+	assert.NoError(t, err)
+	assert.Len(t, remote, 2)
+	cloud.Operations()
+
+	// the rules of the new port are authorized, the ones of the removed cidr revoked
+	reqCtx = getReqCtx(getService(nil, map[string]string{annotation.AclCidrs: "10.0.0.0/8"}))
+	rules, err = GetNLBRules(reqCtx.Anno, getNLB(
+		&nlbmodel.ListenerAttribute{ListenerProtocol: nlbmodel.TCP, ListenerPort: 80},
+		&nlbmodel.ListenerAttribute{ListenerProtocol: nlbmodel.TCP, ListenerPort: 443}))
+	assert.NoError(t, err)
+	id, err := mgr.Sync(reqCtx, rules, "")
+	assert.NoError(t, err)
+	assert.Equal(t, sgId, id)
+	ops := cloud.Operations()
+	assert.Len(t, ops, 1)
+	assert.Equal(t, []string{
+		`Rule: + "accept/tcp/443/443/10.0.0.0/8/1"`,
+		`Rule: - "accept/tcp/80/80/192.168.0.0/16/1"`,
+	}, ops[0].Changes)
+
+	// the quota is checked before any call
+	var listeners []*nlbmodel.ListenerAttribute
+	for port := int32(1); port <= MaxSecurityGroupRules+1; port++ {
+		listeners = append(listeners, &nlbmodel.ListenerAttribute{ListenerProtocol: nlbmodel.TCP, ListenerPort: port})
+	}
+	_, err = GetNLBRules(reqCtx.Anno, getNLB(listeners...))
+	assert.Error(t, err)
+
+	_, err = GetNLBRules(getReqCtx(getService([]string{"2001:db8::/32"}, nil)).Anno, getNLB())
+	assert.Error(t, err)
+
+	assert.NoError(t, mgr.GarbageCollect(reqCtx))
+	sgs, err = mgr.List(reqCtx)
+	assert.NoError(t, err)
+	assert.Empty(t, sgs)
+}
@@ -0,0 +1,182 @@
+package acl
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+)
+
+const (
+	// MaxAclEntries is the quota of the entries of a clb acl
+	MaxAclEntries    = 300
+	maxAclNameLength = 80
+)
+
+func NewCLBManager(cloud prvd.Provider) *CLBManager {
+	return &CLBManager{cloud: cloud}
+}
+
+// CLBManager keeps the entries of the acl created for a service as the cidrs it declares.
+// The acls are tagged with the default tags of the service.
+type CLBManager struct {
+	cloud prvd.Provider
+}
+
+// GetCLBCidrs returns the acl type and the cidrs declared by the service, which are checked against
+// the ip version of the load balancer and the entry quota of the acl.
+func GetCLBCidrs(anno *annotation.AnnotationRequest, ipVersion model.AddressIPVersionType) (string, []string, error) {
+	aclType, cidrs, err := GetCidrs(anno)
+	if err != nil || len(cidrs) == 0 {
+		return "", nil, err
+	}
+	if model.FlagType(anno.Get(annotation.AclStatus)) == model.OffFlag {
+		return "", nil, fmt.Errorf("annotation %s can not be off with the cidrs of the acl",
+			annotation.Annotation(annotation.AclStatus))
+	}
+	if ipVersion == "" {
+		ipVersion = model.IPv4
+	}
+	for _, c := range cidrs {
+		if isIPv6(c) != (ipVersion == model.IPv6) {
+			return "", nil, fmt.Errorf("cidr [%s] of the acl does not match the %s load balancer", c, ipVersion)
+		}
+	}
+	if len(cidrs) > MaxAclEntries {
+		return "", nil, fmt.Errorf("the acl has %d cidrs, exceeds the quota of %d entries", len(cidrs), MaxAclEntries)
+	}
+	return aclType, cidrs, nil
+}
+
+// Sync creates the acl of the service if it does not exist, and adds and removes the entries which differ
+// from the cidrs. It returns the acl id for the listeners.
+func (m *CLBManager) Sync(reqCtx *svcCtx.RequestContext, cidrs []string, ipVersion model.AddressIPVersionType) (string, error) {
+	if ipVersion == "" {
+		ipVersion = model.IPv4
+	}
+
+	acls, err := m.List(reqCtx)
+	if err != nil {
+		return "", err
+	}
+	var acl model.AccessControlList
+	if len(acls) != 0 {
+		acl = acls[0]
+	} else {
+		acl = model.AccessControlList{
+			AclName:          buildName(reqCtx.Service, maxAclNameLength),
+			AddressIPVersion: string(ipVersion),
+			Tags:             reqCtx.Anno.GetDefaultTags(),
+		}
+		reqCtx.Log.Info(fmt.Sprintf("create acl %s", acl.AclName))
+		if err := m.cloud.CreateCLBAccessControlList(reqCtx.Ctx, &acl); err != nil {
+			return "", fmt.Errorf("create acl %s error: %s", acl.AclName, err.Error())
+		}
+	}
+
+	entries, err := m.cloud.DescribeCLBAccessControlListEntries(reqCtx.Ctx, acl.AclId)
+	if err != nil {
+		return "", fmt.Errorf("describe entries of acl %s error: %s", acl.AclId, err.Error())
+	}
+	add, remove := diffEntries(cidrs, entries)
+	addEntries := func() error {
+		if len(add) == 0 {
+			return nil
+		}
+		reqCtx.Log.Info(fmt.Sprintf("add entries %s to acl %s", entryString(add), acl.AclId))
+		if err := m.cloud.AddCLBAccessControlListEntries(reqCtx.Ctx, acl.AclId, add); err != nil {
+			return fmt.Errorf("add entries of acl %s error: %s", acl.AclId, err.Error())
+		}
+		return nil
+	}
+	removeEntries := func() error {
+		if len(remove) == 0 {
+			return nil
+		}
+		reqCtx.Log.Info(fmt.Sprintf("remove entries %s from acl %s", entryString(remove), acl.AclId))
+		if err := m.cloud.RemoveCLBAccessControlListEntries(reqCtx.Ctx, acl.AclId, remove); err != nil {
+			return fmt.Errorf("remove entries of acl %s error: %s", acl.AclId, err.Error())
+		}
+		return nil
+	}
+	// add first, so that the listeners never miss the new cidrs while they are replaced,
+	// unless the quota can not hold the old and the new entries at the same time
+	steps := []func() error{addEntries, removeEntries}
+	if len(entries)+len(add) > MaxAclEntries {
+		steps = []func() error{removeEntries, addEntries}
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return "", err
+		}
+	}
+	return acl.AclId, nil
+}
+
+// List returns the acls created for the service.
+func (m *CLBManager) List(reqCtx *svcCtx.RequestContext) ([]model.AccessControlList, error) {
+	acls, err := m.cloud.ListCLBAccessControlLists(reqCtx.Ctx, reqCtx.Anno.GetDefaultTags())
+	if err != nil {
+		return nil, fmt.Errorf("list acls error: %s", err.Error())
+	}
+	return acls, nil
+}
+
+// Delete deletes the acls created for the service, the acls still bound to a listener fail to delete.
+func (m *CLBManager) Delete(reqCtx *svcCtx.RequestContext, aclIds []string) error {
+	var errs []string
+	for _, id := range aclIds {
+		reqCtx.Log.Info(fmt.Sprintf("delete unused acl %s", id))
+		if err := m.cloud.DeleteCLBAccessControlList(reqCtx.Ctx, id); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("delete acls error: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// GarbageCollect deletes all the acls created for the service, it is called when the service is deleted.
+func (m *CLBManager) GarbageCollect(reqCtx *svcCtx.RequestContext) error {
+	acls, err := m.List(reqCtx)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for _, acl := range acls {
+		ids = append(ids, acl.AclId)
+	}
+	return m.Delete(reqCtx, ids)
+}
+
+func diffEntries(cidrs []string, entries []model.AclEntry) ([]model.AclEntry, []model.AclEntry) {
+	want := map[string]bool{}
+	for _, c := range cidrs {
+		want[c] = true
+	}
+	var add, remove []model.AclEntry
+	for _, e := range entries {
+		if !want[e.Entry] {
+			remove = append(remove, e)
+		}
+		delete(want, e.Entry)
+	}
+	for _, c := range cidrs {
+		if want[c] {
+			add = append(add, model.AclEntry{Entry: c})
+		}
+	}
+	return add, remove
+}
+
+func entryString(entries []model.AclEntry) string {
+	var ret []string
+	for _, e := range entries {
+		ret = append(ret, e.Entry)
+	}
+	return strings.Join(ret, ",")
+}
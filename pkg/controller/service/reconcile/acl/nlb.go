@@ -0,0 +1,234 @@
+package acl
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+)
+
+const (
+	// MaxSecurityGroupRules is the quota of the rules of a security group
+	MaxSecurityGroupRules      = 200
+	maxSecurityGroupNameLength = 128
+
+	// the rules of the cidrs take precedence over the rule of all the other addresses
+	cidrRulePriority    = 1
+	defaultRulePriority = 100
+)
+
+func NewNLBManager(cloud prvd.Provider) *NLBManager {
+	return &NLBManager{cloud: cloud}
+}
+
+// NLBManager keeps the ingress rules of the security group created for a service as the cidrs it
+// declares, on the ports of its listeners. The security groups are tagged with the default tags of the service.
+type NLBManager struct {
+	cloud prvd.Provider
+}
+
+// BuildRules returns the ingress rules of the cidrs on the ports of the listeners. The white list accepts the
+// cidrs, which leaves the other addresses to the default policy of the security group that drops them. The black
+// list drops the cidrs and accepts all the other addresses.
+func BuildRules(aclType string, cidrs []string, listeners []*nlbmodel.ListenerAttribute, dualStack bool) []model.SecurityGroupRule {
+	var rules []model.SecurityGroupRule
+	for _, l := range listeners {
+		protocol := "tcp"
+		if strings.EqualFold(l.ListenerProtocol, nlbmodel.UDP) {
+			protocol = "udp"
+		}
+		portRange := fmt.Sprintf("%d/%d", l.ListenerPort, l.ListenerPort)
+		if l.ListenerPort == 0 {
+			portRange = fmt.Sprintf("%d/%d", l.StartPort, l.EndPort)
+		}
+
+		policy := model.SecurityGroupPolicyAccept
+		if aclType == AclTypeBlack {
+			policy = model.SecurityGroupPolicyDrop
+		}
+		for _, c := range cidrs {
+			rules = append(rules, model.SecurityGroupRule{
+				Policy:       policy,
+				IpProtocol:   protocol,
+				PortRange:    portRange,
+				SourceCidrIp: c,
+				Priority:     cidrRulePriority,
+			})
+		}
+		if aclType == AclTypeBlack {
+			all := []string{"0.0.0.0/0"}
+			if dualStack {
+				all = append(all, "::/0")
+			}
+			for _, c := range all {
+				rules = append(rules, model.SecurityGroupRule{
+					Policy:       model.SecurityGroupPolicyAccept,
+					IpProtocol:   protocol,
+					PortRange:    portRange,
+					SourceCidrIp: c,
+					Priority:     defaultRulePriority,
+				})
+			}
+		}
+	}
+	return rules
+}
+
+// GetNLBRules returns the security group rules of the cidrs declared by the service on the ports of the
+// listeners of the model, which are checked against the ip version of the nlb and the rule quota.
+func GetNLBRules(anno *annotation.AnnotationRequest, mdl *nlbmodel.NetworkLoadBalancer) ([]model.SecurityGroupRule, error) {
+	aclType, cidrs, err := GetCidrs(anno)
+	if err != nil || len(cidrs) == 0 {
+		return nil, err
+	}
+	dualStack := strings.EqualFold(mdl.LoadBalancerAttribute.AddressIpVersion, nlbmodel.DualStack)
+	for _, c := range cidrs {
+		if isIPv6(c) && !dualStack {
+			return nil, fmt.Errorf("cidr [%s] of the acl needs a %s load balancer", c, nlbmodel.DualStack)
+		}
+	}
+	rules := BuildRules(aclType, cidrs, mdl.Listeners, dualStack)
+	if len(rules) > MaxSecurityGroupRules {
+		return nil, fmt.Errorf("the acl needs %d security group rules for the cidrs and the listeners, "+
+			"exceeds the quota of %d rules", len(rules), MaxSecurityGroupRules)
+	}
+	return rules, nil
+}
+
+// Sync creates the security group of the service in the vpc if it does not exist, and authorizes and revokes
+// the rules which differ from the given ones. It returns the security group id for the nlb to join.
+func (m *NLBManager) Sync(reqCtx *svcCtx.RequestContext, rules []model.SecurityGroupRule, vpcId string) (string, error) {
+	sgs, err := m.List(reqCtx)
+	if err != nil {
+		return "", err
+	}
+	var sg model.SecurityGroup
+	if len(sgs) != 0 {
+		sg = sgs[0]
+	} else {
+		if vpcId == "" {
+			vpcId, err = m.cloud.VpcID()
+			if err != nil {
+				return "", fmt.Errorf("get vpc id error: %s", err.Error())
+			}
+		}
+		sg = model.SecurityGroup{
+			SecurityGroupName: buildName(reqCtx.Service, maxSecurityGroupNameLength),
+			Description:       fmt.Sprintf("acl of service %s/%s", reqCtx.Service.Namespace, reqCtx.Service.Name),
+			VpcId:             vpcId,
+			Tags:              reqCtx.Anno.GetDefaultTags(),
+		}
+		reqCtx.Log.Info(fmt.Sprintf("create security group %s", sg.SecurityGroupName))
+		if err := m.cloud.CreateSecurityGroup(reqCtx.Ctx, &sg); err != nil {
+			return "", fmt.Errorf("create security group %s error: %s", sg.SecurityGroupName, err.Error())
+		}
+	}
+
+	remote, err := m.cloud.DescribeSecurityGroupRules(reqCtx.Ctx, sg.SecurityGroupId)
+	if err != nil {
+		return "", fmt.Errorf("describe rules of security group %s error: %s", sg.SecurityGroupId, err.Error())
+	}
+	authorize, revoke := diffRules(rules, remote)
+	authorizeRules := func() error {
+		if len(authorize) == 0 {
+			return nil
+		}
+		reqCtx.Log.Info(fmt.Sprintf("authorize rules %s of security group %s", ruleString(authorize), sg.SecurityGroupId))
+		if err := m.cloud.AuthorizeSecurityGroupRules(reqCtx.Ctx, sg.SecurityGroupId, authorize); err != nil {
+			return fmt.Errorf("authorize rules of security group %s error: %s", sg.SecurityGroupId, err.Error())
+		}
+		return nil
+	}
+	revokeRules := func() error {
+		if len(revoke) == 0 {
+			return nil
+		}
+		reqCtx.Log.Info(fmt.Sprintf("revoke rules %s of security group %s", ruleString(revoke), sg.SecurityGroupId))
+		if err := m.cloud.RevokeSecurityGroupRules(reqCtx.Ctx, sg.SecurityGroupId, revoke); err != nil {
+			return fmt.Errorf("revoke rules of security group %s error: %s", sg.SecurityGroupId, err.Error())
+		}
+		return nil
+	}
+	// authorize first, so that the listeners never drop the new cidrs while they are replaced,
+	// unless the quota can not hold the old and the new rules at the same time
+	steps := []func() error{authorizeRules, revokeRules}
+	if len(remote)+len(authorize) > MaxSecurityGroupRules {
+		steps = []func() error{revokeRules, authorizeRules}
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return "", err
+		}
+	}
+	return sg.SecurityGroupId, nil
+}
+
+// List returns the security groups created for the service.
+func (m *NLBManager) List(reqCtx *svcCtx.RequestContext) ([]model.SecurityGroup, error) {
+	sgs, err := m.cloud.ListSecurityGroups(reqCtx.Ctx, reqCtx.Anno.GetDefaultTags())
+	if err != nil {
+		return nil, fmt.Errorf("list security groups error: %s", err.Error())
+	}
+	return sgs, nil
+}
+
+// Delete deletes the security groups created for the service, the ones still joined by a nlb fail to delete.
+func (m *NLBManager) Delete(reqCtx *svcCtx.RequestContext, sgIds []string) error {
+	var errs []string
+	for _, id := range sgIds {
+		reqCtx.Log.Info(fmt.Sprintf("delete unused security group %s", id))
+		if err := m.cloud.DeleteSecurityGroup(reqCtx.Ctx, id); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("delete security groups error: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// GarbageCollect deletes all the security groups created for the service, it is called when the service is deleted.
+func (m *NLBManager) GarbageCollect(reqCtx *svcCtx.RequestContext) error {
+	sgs, err := m.List(reqCtx)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for _, sg := range sgs {
+		ids = append(ids, sg.SecurityGroupId)
+	}
+	return m.Delete(reqCtx, ids)
+}
+
+func diffRules(rules, remote []model.SecurityGroupRule) ([]model.SecurityGroupRule, []model.SecurityGroupRule) {
+	want := map[string]bool{}
+	for _, r := range rules {
+		want[r.Key()] = true
+	}
+	var authorize, revoke []model.SecurityGroupRule
+	for _, r := range remote {
+		if !want[r.Key()] {
+			revoke = append(revoke, r)
+		}
+		delete(want, r.Key())
+	}
+	for _, r := range rules {
+		if want[r.Key()] {
+			authorize = append(authorize, r)
+			delete(want, r.Key())
+		}
+	}
+	return authorize, revoke
+}
+
+func ruleString(rules []model.SecurityGroupRule) string {
+	var ret []string
+	for _, r := range rules {
+		ret = append(ret, r.Key())
+	}
+	return strings.Join(ret, ",")
+}
@@ -7,6 +7,7 @@ import (
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
 
 	v1 "k8s.io/api/core/v1"
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
@@ -36,6 +37,7 @@ const (
 	ModificationProtection = AnnotationLoadBalancerPrefix + "modification-protection"  // ModificationProtection modification type
 
 	CertID          = AnnotationLoadBalancerPrefix + "cert-id"           // CertID cert id
+	CertSecret      = AnnotationLoadBalancerPrefix + "cert-secret"       // CertSecret name of the tls secret uploaded as the listener cert
 	ProtocolPort    = AnnotationLoadBalancerPrefix + "protocol-port"     // ProtocolPort protocol port
 	IdleTimeout     = AnnotationLoadBalancerPrefix + "idle-timeout"      // IdleTimeout idle timeout for L7
 	TLSCipherPolicy = AnnotationLoadBalancerPrefix + "tls-cipher-policy" //TLSCipherPolicy TLS security policy for https
@@ -46,6 +48,15 @@ const (
 	IgnoreWeightUpdate     = AnnotationLoadBalancerPrefix + "ignore-weight-update"
 
 	PreserveLBOnDelete = AnnotationLoadBalancerPrefix + "preserve-lb-on-delete"
+
+	AllocateEip           = AnnotationLoadBalancerPrefix + "allocate-eip"             // AllocateEip on to allocate the eips of the load balancer
+	EipBandwidth          = AnnotationLoadBalancerPrefix + "eip-bandwidth"            // EipBandwidth bandwidth of the allocated eips in Mbps
+	EipISP                = AnnotationLoadBalancerPrefix + "eip-isp"                  // EipISP line type of the allocated eips, BGP or BGP_PRO
+	EipInternetChargeType = AnnotationLoadBalancerPrefix + "eip-internet-charge-type" // EipInternetChargeType PayByTraffic or PayByBandwidth
+	EipBandwidthPackageId = AnnotationLoadBalancerPrefix + "eip-bandwidth-package-id" // EipBandwidthPackageId common bandwidth package the allocated eips join
+	PreserveEipOnDelete   = AnnotationLoadBalancerPrefix + "preserve-eip-on-delete"   // PreserveEipOnDelete keep the allocated eips when the service is deleted
+
+	Config = AnnotationLoadBalancerPrefix + "config" // Config name of the LoadBalancerConfig in the namespace of the service
 )
 
 // classic load balancer
@@ -69,6 +80,8 @@ const (
 	AclStatus                  = AnnotationLoadBalancerPrefix + "acl-status"                   // AclStatus enable or disable acl on all listener
 	AclID                      = AnnotationLoadBalancerPrefix + "acl-id"                       // AclID acl id
 	AclType                    = AnnotationLoadBalancerPrefix + "acl-type"                     // AclType acl type, black or white
+	AclCidrs                   = AnnotationLoadBalancerPrefix + "acl-cidrs"                    // AclCidrs cidrs of the acl managed for the service
+	AclSourceRanges            = AnnotationLoadBalancerPrefix + "acl-source-ranges"            // AclSourceRanges on to manage the acl by spec.loadBalancerSourceRanges
 	ForwardPort                = AnnotationLoadBalancerPrefix + "forward-port"                 // ForwardPort loadbalancer forward port
 	EnableHttp2                = AnnotationLoadBalancerPrefix + "http2-enabled"                // EnableHttp2 enable http2 on https port
 	HealthCheckSwitch          = AnnotationLoadBalancerPrefix + "health-check-switch"          // HealthCheckSwitch health check switch flag, only for tcp & udp
@@ -103,6 +116,11 @@ const (
 	BackendIPVersion  = AnnotationLoadBalancerPrefix + "backend-ip-version"         // BackendIPVersion backend ip version
 	RemoveUnscheduled = AnnotationLoadBalancerPrefix + "remove-unscheduled-backend" // RemoveUnscheduled remove unscheduled node from backends
 	VGroupWeight      = AnnotationLoadBalancerPrefix + "weight"                     // Weight total weight of the load balancer
+
+	DeregistrationDelay     = AnnotationLoadBalancerPrefix + "deregistration-delay"     // DeregistrationDelay seconds to keep terminating pods in the backends with weight 0
+	DeregistrationFinalizer = AnnotationLoadBalancerPrefix + "deregistration-finalizer" // DeregistrationFinalizer block pod deletion until the pod is removed from the backends
+	WeightPolicy            = AnnotationLoadBalancerPrefix + "weight-policy"            // WeightPolicy pod or zone, how the weights of the backends are computed
+	ZoneHealthyThreshold    = AnnotationLoadBalancerPrefix + "zone-healthy-threshold"   // ZoneHealthyThreshold percentage of ready pods below which a zone takes no traffic
 )
 
 // network load balancer
@@ -111,10 +129,12 @@ const (
 	SecurityGroupIds   = AnnotationLoadBalancerPrefix + "security-group-ids"
 	BandwidthPackageId = AnnotationLoadBalancerPrefix + "bandwidth-package-id"
 	IPv6AddressType    = AnnotationLoadBalancerPrefix + "ipv6-address-type"
+	SharedGroup        = AnnotationLoadBalancerPrefix + "shared-group" // SharedGroup services of the same group share one nlb
 
-	CaCertID = AnnotationLoadBalancerPrefix + "cacert-id" // CertID cert id
-	CaCert   = AnnotationLoadBalancerPrefix + "cacert"    // CaCert enable ca
-	Cps      = AnnotationLoadBalancerPrefix + "cps"
+	CaCertID     = AnnotationLoadBalancerPrefix + "cacert-id"     // CertID cert id
+	CaCertSecret = AnnotationLoadBalancerPrefix + "cacert-secret" // CaCertSecret name of the secret holding the ca bundle in ca.crt
+	CaCert       = AnnotationLoadBalancerPrefix + "cacert"        // CaCert enable ca
+	Cps          = AnnotationLoadBalancerPrefix + "cps"
 
 	PreserveClientIp = AnnotationLoadBalancerPrefix + "preserve-client-ip"
 	ServerGroupType  = AnnotationLoadBalancerPrefix + "server-group-type" // ServerGroupType, ECS/ECI; IP
@@ -137,56 +157,53 @@ var DefaultValue = map[string]string{
 	composite(AnnotationPrefix, ModificationProtection): string(model.ConsoleProtection),
 }
 
-type AnnotationRequest struct{ Service *v1.Service }
+type AnnotationRequest struct {
+	Service *v1.Service
+
+	// the LoadBalancerConfig referred by the service, see SetConfig
+	config     *albv1.LoadBalancerConfig
+	values     map[string]string
+	portValues map[int32]map[string]string
+	port       int32
+}
 
 func NewAnnotationRequest(svc *v1.Service) *AnnotationRequest {
-	return &AnnotationRequest{svc}
+	return &AnnotationRequest{Service: svc}
 }
 
 func (n *AnnotationRequest) Get(k string) string {
-	if n.Service == nil {
-		return ""
-	}
-
-	if n.Service.Annotations == nil {
-		return ""
-	}
-
-	key := composite(AnnotationPrefix, k)
-	v, ok := n.Service.Annotations[key]
-	if ok {
-		return v
-	}
-
-	lkey := composite(AnnotationLegacyPrefix, k)
-	v, ok = n.Service.Annotations[lkey]
-	if ok {
-		return v
-	}
-
-	return ""
+	v, _ := n.lookup(k)
+	return v
 }
 
 func (n *AnnotationRequest) Has(k string) bool {
+	_, ok := n.lookup(k)
+	return ok
+}
+
+// lookup finds the annotation of the service first, then the LoadBalancerConfig of the port and the service.
+func (n *AnnotationRequest) lookup(k string) (string, bool) {
 	if n.Service == nil {
-		return false
+		return "", false
 	}
 
-	if n.Service.Annotations == nil {
-		return false
-	}
+	if n.Service.Annotations != nil {
+		key := composite(AnnotationPrefix, k)
+		if v, ok := n.Service.Annotations[key]; ok {
+			return v, true
+		}
 
-	key := composite(AnnotationPrefix, k)
-	if _, ok := n.Service.Annotations[key]; ok {
-		return true
+		lkey := composite(AnnotationLegacyPrefix, k)
+		if v, ok := n.Service.Annotations[lkey]; ok {
+			return v, true
+		}
 	}
 
-	key = composite(AnnotationLegacyPrefix, k)
-	if _, ok := n.Service.Annotations[key]; ok {
-		return true
+	if v, ok := n.portValues[n.port][k]; ok {
+		return v, true
 	}
-
-	return false
+	v, ok := n.values[k]
+	return v, ok
 }
 
 func (n *AnnotationRequest) GetDefaultValue(k string) string {
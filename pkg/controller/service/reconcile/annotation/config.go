@@ -0,0 +1,200 @@
+package annotation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewAnnotationRequestWithConfig returns the AnnotationRequest of the service, which falls back to the
+// LoadBalancerConfig referred by the service. A missing config is ignored when the load balancer is deleted.
+func NewAnnotationRequestWithConfig(ctx context.Context, kubeClient client.Client, svc *v1.Service) (*AnnotationRequest, error) {
+	anno := NewAnnotationRequest(svc)
+	name := anno.Get(Config)
+	if name == "" || !utilfeature.DefaultFeatureGate.Enabled(ctrlCfg.LoadBalancerConfig) {
+		return anno, nil
+	}
+
+	config := &albv1.LoadBalancerConfig{}
+	if err := kubeClient.Get(ctx, types.NamespacedName{Namespace: svc.Namespace, Name: name}, config); err != nil {
+		if apierrors.IsNotFound(err) && helper.NeedDeleteLoadBalancer(svc) {
+			return anno, nil
+		}
+		return nil, fmt.Errorf("get LoadBalancerConfig %s/%s error: %s", svc.Namespace, name, err.Error())
+	}
+	anno.SetConfig(config)
+	return anno, nil
+}
+
+// SetConfig sets the LoadBalancerConfig the annotations fall back to.
+func (n *AnnotationRequest) SetConfig(config *albv1.LoadBalancerConfig) {
+	n.config = config
+	n.values = map[string]string{}
+	n.portValues = map[int32]map[string]string{}
+	if config == nil {
+		return
+	}
+	setLoadBalancerValues(n.values, config.Spec.LoadBalancer)
+	setListenerValues(n.values, config.Spec.Listener)
+	for i := range config.Spec.Ports {
+		p := &config.Spec.Ports[i]
+		if n.portValues[p.Port] == nil {
+			n.portValues[p.Port] = map[string]string{}
+		}
+		setListenerValues(n.portValues[p.Port], &p.ListenerAttributes)
+	}
+}
+
+// Config returns the LoadBalancerConfig referred by the service, or nil.
+func (n *AnnotationRequest) Config() *albv1.LoadBalancerConfig {
+	return n.config
+}
+
+// ForPort returns the AnnotationRequest of the listener of the service port, which falls back to the
+// overrides of the port in the LoadBalancerConfig before the attributes of all the listeners.
+func (n *AnnotationRequest) ForPort(port int32) *AnnotationRequest {
+	ret := *n
+	ret.port = port
+	return &ret
+}
+
+func setLoadBalancerValues(values map[string]string, lb *albv1.LoadBalancerAttributes) {
+	if lb == nil {
+		return
+	}
+	setString(values, AddressType, lb.AddressType)
+	setString(values, ResourceGroupId, lb.ResourceGroupId)
+	if len(lb.AdditionalTags) != 0 {
+		var tags []string
+		for k, v := range lb.AdditionalTags {
+			tags = append(tags, fmt.Sprintf("%s=%s", k, v))
+		}
+		sort.Strings(tags)
+		values[AdditionalTags] = strings.Join(tags, ",")
+	}
+	setFlag(values, DeleteProtection, lb.DeletionProtection)
+	setString(values, ModificationProtection, lb.ModificationProtection)
+
+	setString(values, Spec, lb.Spec)
+	setString(values, InstanceChargeType, lb.InstanceChargeType)
+	setString(values, ChargeType, lb.InternetChargeType)
+	setInt(values, Bandwidth, lb.Bandwidth)
+	setString(values, SLBNetworkType, lb.NetworkType)
+	setString(values, VswitchId, lb.VSwitchId)
+	setString(values, MasterZoneID, lb.MasterZoneId)
+	setString(values, SlaveZoneID, lb.SlaveZoneId)
+	setString(values, IPVersion, lb.IPVersion)
+
+	if len(lb.ZoneMappings) != 0 {
+		var zones []string
+		for _, z := range lb.ZoneMappings {
+			items := []string{z.ZoneId, z.VSwitchId}
+			if z.IPv4Address != "" || z.AllocationId != "" {
+				items = append(items, z.IPv4Address)
+			}
+			if z.AllocationId != "" {
+				items = append(items, z.AllocationId)
+			}
+			zones = append(zones, strings.Join(items, ":"))
+		}
+		values[ZoneMaps] = strings.Join(zones, ",")
+	}
+	if lb.SecurityGroupIds != nil {
+		values[SecurityGroupIds] = strings.Join(lb.SecurityGroupIds, ",")
+	}
+	if lb.BandwidthPackageId != nil {
+		values[BandwidthPackageId] = *lb.BandwidthPackageId
+	}
+	setString(values, IPv6AddressType, lb.IPv6AddressType)
+}
+
+func setListenerValues(values map[string]string, l *albv1.ListenerAttributes) {
+	if l == nil {
+		return
+	}
+	setString(values, Scheduler, l.Scheduler)
+	setInt(values, PersistenceTimeout, l.PersistenceTimeout)
+	setInt(values, EstablishedTimeout, l.EstablishedTimeout)
+	setInt(values, IdleTimeout, l.IdleTimeout)
+	setInt(values, RequestTimeout, l.RequestTimeout)
+	setString(values, CertID, l.CertId)
+	setString(values, CaCertID, l.CaCertId)
+	setFlag(values, CaCert, l.CaEnabled)
+	setString(values, TLSCipherPolicy, l.TLSCipherPolicy)
+	setFlag(values, EnableHttp2, l.HTTP2Enabled)
+	setFlag(values, ProxyProtocol, l.ProxyProtocol)
+	setInt(values, Cps, l.Cps)
+
+	if acl := l.Acl; acl != nil {
+		setFlag(values, AclStatus, acl.Enabled)
+		setString(values, AclID, acl.Id)
+		setString(values, AclType, acl.Type)
+	}
+	if drain := l.ConnectionDrain; drain != nil {
+		setFlag(values, ConnectionDrain, drain.Enabled)
+		setInt(values, ConnectionDrainTimeout, drain.Timeout)
+	}
+	if sticky := l.StickySession; sticky != nil {
+		setFlag(values, SessionStick, sticky.Enabled)
+		setString(values, SessionStickType, sticky.Type)
+		setString(values, Cookie, sticky.Cookie)
+		setInt(values, CookieTimeout, sticky.CookieTimeout)
+	}
+	if xff := l.XForwardedFor; xff != nil {
+		setFlag(values, XForwardedForProto, xff.Proto)
+		setFlag(values, XForwardedForSLBPort, xff.SLBPort)
+		setFlag(values, XForwardedForClientSrcPort, xff.ClientSrcPort)
+	}
+	if hc := l.HealthCheck; hc != nil {
+		setFlag(values, HealthCheckFlag, hc.Enabled)
+		setFlag(values, HealthCheckSwitch, hc.Enabled)
+		setString(values, HealthCheckType, hc.Type)
+		setString(values, HealthCheckURI, hc.URI)
+		setString(values, HealthCheckDomain, hc.Domain)
+		if len(hc.HTTPCodes) != 0 {
+			values[HealthCheckHTTPCode] = strings.Join(hc.HTTPCodes, ",")
+		}
+		setString(values, HealthCheckMethod, hc.Method)
+		setInt(values, HealthCheckConnectPort, hc.ConnectPort)
+		setInt(values, HealthCheckConnectTimeout, hc.ConnectTimeout)
+		setInt(values, HealthCheckTimeout, hc.Timeout)
+		setInt(values, HealthCheckInterval, hc.Interval)
+		setInt(values, HealthyThreshold, hc.HealthyThreshold)
+		setInt(values, UnhealthyThreshold, hc.UnhealthyThreshold)
+	}
+}
+
+func setString(values map[string]string, k, v string) {
+	if v != "" {
+		values[k] = v
+	}
+}
+
+func setInt(values map[string]string, k string, v *int32) {
+	if v != nil {
+		values[k] = strconv.Itoa(int(*v))
+	}
+}
+
+func setFlag(values map[string]string, k string, v *bool) {
+	if v == nil {
+		return
+	}
+	if *v {
+		values[k] = string(model.OnFlag)
+	} else {
+		values[k] = string(model.OffFlag)
+	}
+}
@@ -0,0 +1,80 @@
+package annotation
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// NewEnqueueRequestForConfigEvent enqueues the services referring to the changed LoadBalancerConfig,
+// filtered by needService to the services of the controller.
+func NewEnqueueRequestForConfigEvent(client client.Client, needService func(*v1.Service) bool) *enqueueRequestForConfigEvent {
+	return &enqueueRequestForConfigEvent{
+		client:      client,
+		needService: needService,
+	}
+}
+
+type enqueueRequestForConfigEvent struct {
+	client      client.Client
+	needService func(*v1.Service) bool
+}
+
+var _ handler.EventHandler = (*enqueueRequestForConfigEvent)(nil)
+
+func (h *enqueueRequestForConfigEvent) Create(_ context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	config, ok := e.Object.(*albv1.LoadBalancerConfig)
+	if ok {
+		h.enqueueReferringServices(queue, config)
+	}
+}
+
+func (h *enqueueRequestForConfigEvent) Update(_ context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldConfig, ok1 := e.ObjectOld.(*albv1.LoadBalancerConfig)
+	newConfig, ok2 := e.ObjectNew.(*albv1.LoadBalancerConfig)
+	if ok1 && ok2 && oldConfig.Generation != newConfig.Generation {
+		h.enqueueReferringServices(queue, newConfig)
+	}
+}
+
+func (h *enqueueRequestForConfigEvent) Delete(_ context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	// the services report the missing config
+	config, ok := e.Object.(*albv1.LoadBalancerConfig)
+	if ok {
+		h.enqueueReferringServices(queue, config)
+	}
+}
+
+func (h *enqueueRequestForConfigEvent) Generic(_ context.Context, e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+	// unknown event, ignore
+}
+
+func (h *enqueueRequestForConfigEvent) enqueueReferringServices(queue workqueue.RateLimitingInterface, config *albv1.LoadBalancerConfig) {
+	svcs := v1.ServiceList{}
+	if err := h.client.List(context.TODO(), &svcs, client.InNamespace(config.Namespace)); err != nil {
+		klog.Errorf("fail to list services for LoadBalancerConfig %s/%s: %s", config.Namespace, config.Name, err.Error())
+		return
+	}
+	for i := range svcs.Items {
+		svc := &svcs.Items[i]
+		if !h.needService(svc) || NewAnnotationRequest(svc).Get(Config) != config.Name {
+			continue
+		}
+		queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: svc.Namespace,
+				Name:      svc.Name,
+			},
+		})
+		klog.Info(fmt.Sprintf("LoadBalancerConfig change: enqueue service %s/%s", svc.Namespace, svc.Name))
+	}
+}
@@ -0,0 +1,114 @@
+package annotation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getLoadBalancerConfig() *albv1.LoadBalancerConfig {
+	return &albv1.LoadBalancerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: albv1.LoadBalancerConfigSpec{
+			LoadBalancer: &albv1.LoadBalancerAttributes{
+				AddressType:        "intranet",
+				AdditionalTags:     map[string]string{"team": "web", "env": "prod"},
+				DeletionProtection: tea.Bool(false),
+				ZoneMappings: []albv1.LoadBalancerZoneMapping{
+					{ZoneId: "cn-hangzhou-a", VSwitchId: "vsw-1"},
+					{ZoneId: "cn-hangzhou-b", VSwitchId: "vsw-2", IPv4Address: "192.168.0.10"},
+				},
+				SecurityGroupIds: []string{},
+			},
+			Listener: &albv1.ListenerAttributes{
+				Scheduler: "wrr",
+				HealthCheck: &albv1.HealthCheckAttributes{
+					Enabled:   tea.Bool(true),
+					HTTPCodes: []string{"http_2xx", "http_3xx"},
+					Interval:  tea.Int32(5),
+				},
+			},
+			Ports: []albv1.PortListenerAttributes{
+				{
+					Port: 443,
+					ListenerAttributes: albv1.ListenerAttributes{
+						Scheduler: "rr",
+						CertId:    "cert-id",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestAnnotationRequest_SetConfig(t *testing.T) {
+	svc := getDefaultService()
+	anno := NewAnnotationRequest(svc)
+	anno.SetConfig(getLoadBalancerConfig())
+
+	assert.Equal(t, "intranet", anno.Get(AddressType))
+	assert.Equal(t, "env=prod,team=web", anno.Get(AdditionalTags))
+	assert.Equal(t, "off", anno.Get(DeleteProtection))
+	assert.Equal(t, "cn-hangzhou-a:vsw-1,cn-hangzhou-b:vsw-2:192.168.0.10", anno.Get(ZoneMaps))
+	assert.True(t, anno.Has(SecurityGroupIds))
+	assert.False(t, anno.Has(BandwidthPackageId))
+	assert.Equal(t, "on", anno.Get(HealthCheckFlag))
+	assert.Equal(t, "on", anno.Get(HealthCheckSwitch))
+	assert.Equal(t, "http_2xx,http_3xx", anno.Get(HealthCheckHTTPCode))
+	assert.Equal(t, "5", anno.Get(HealthCheckInterval))
+
+	// the port overrides the listener attributes
+	assert.Equal(t, "wrr", anno.Get(Scheduler))
+	assert.Equal(t, "wrr", anno.ForPort(80).Get(Scheduler))
+	assert.Equal(t, "rr", anno.ForPort(443).Get(Scheduler))
+	assert.Equal(t, "cert-id", anno.ForPort(443).Get(CertID))
+	assert.Equal(t, "5", anno.ForPort(443).Get(HealthCheckInterval))
+
+	// the annotations of the service take precedence
+	svc.Annotations[Annotation(Scheduler)] = "wlc"
+	assert.Equal(t, "wlc", anno.ForPort(443).Get(Scheduler))
+}
+
+func TestNewAnnotationRequestWithConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, albv1.SchemeBuilder.AddToScheme(scheme))
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(getLoadBalancerConfig()).Build()
+
+	svc := getDefaultService()
+	svc.Annotations[Annotation(Config)] = "web"
+
+	// the config is ignored unless the feature gate is enabled
+	anno, err := NewAnnotationRequestWithConfig(context.TODO(), kubeClient, svc)
+	assert.Nil(t, err)
+	assert.Nil(t, anno.Config())
+
+	assert.Nil(t, utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(ctrlCfg.LoadBalancerConfig): true}))
+	defer func() {
+		_ = utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(ctrlCfg.LoadBalancerConfig): false})
+	}()
+	anno, err = NewAnnotationRequestWithConfig(context.TODO(), kubeClient, svc)
+	assert.Nil(t, err)
+	assert.NotNil(t, anno.Config())
+	assert.Equal(t, "intranet", anno.Get(AddressType))
+
+	svc.Annotations[Annotation(Config)] = "not-found"
+	_, err = NewAnnotationRequestWithConfig(context.TODO(), kubeClient, svc)
+	assert.NotNil(t, err)
+
+	// the load balancer is deleted without the config
+	now := metav1.Now()
+	svc.DeletionTimestamp = &now
+	anno, err = NewAnnotationRequestWithConfig(context.TODO(), kubeClient, svc)
+	assert.Nil(t, err)
+	assert.Nil(t, anno.Config())
+}
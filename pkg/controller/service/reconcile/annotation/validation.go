@@ -0,0 +1,63 @@
+package annotation
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// IntRange is the range an OpenAPI accepts for an integer annotation.
+type IntRange struct {
+	Key      string
+	Min, Max int
+}
+
+// intRanges are the ranges the OpenAPIs accept for the integer annotations of both CLB and NLB.
+var intRanges = []IntRange{
+	{PersistenceTimeout, 0, 3600},
+	{CookieTimeout, 1, 86400},
+	{HealthyThreshold, 2, 10},
+	{UnhealthyThreshold, 2, 10},
+	{HealthCheckInterval, 1, 50},
+	{HealthCheckConnectTimeout, 1, 300},
+	{HealthCheckTimeout, 1, 300},
+}
+
+// CLBIntRanges and NLBIntRanges are the ranges of the integer annotations which differ between CLB and NLB.
+var (
+	CLBIntRanges = []IntRange{{ConnectionDrainTimeout, 10, 900}}
+	NLBIntRanges = []IntRange{{ConnectionDrainTimeout, 0, 900}}
+)
+
+// ValidateIntRanges checks the integer annotations are within the ranges the OpenAPIs accept, including the
+// ranges of the product. The values which are not integers are left to the model builders.
+func (n *AnnotationRequest) ValidateIntRanges(productRanges []IntRange) error {
+	for _, r := range append(append([]IntRange{}, intRanges...), productRanges...) {
+		v := n.Get(r.Key)
+		if v == "" {
+			continue
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			continue
+		}
+		if i < r.Min || i > r.Max {
+			return fmt.Errorf("annotation %s must be within [%d, %d], got [%s]", Annotation(r.Key), r.Min, r.Max, v)
+		}
+	}
+	return nil
+}
+
+// ValidateImmutable checks the change of annotation k, which can not be changed once the load balancer is created.
+// Changing a value is an error. Setting one is a warning, as it has to match the existing load balancer.
+// Removing one is accepted, as the controllers ignore the empty values.
+func ValidateImmutable(k, oldValue, newValue string) (string, error) {
+	if newValue == "" || newValue == oldValue {
+		return "", nil
+	}
+	if oldValue == "" {
+		return fmt.Sprintf("annotation %s can not be changed once the load balancer is created, "+
+			"it must match the existing load balancer", Annotation(k)), nil
+	}
+	return "", fmt.Errorf("annotation %s can not be changed once the load balancer is created, from [%s] to [%s]",
+		Annotation(k), oldValue, newValue)
+}
@@ -0,0 +1,35 @@
+package annotation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateIntRanges(t *testing.T) {
+	svc := getDefaultService()
+	svc.Annotations[Annotation(HealthyThreshold)] = "3"
+	assert.NoError(t, NewAnnotationRequest(svc).ValidateIntRanges(nil))
+
+	svc.Annotations[Annotation(HealthyThreshold)] = "11"
+	assert.Error(t, NewAnnotationRequest(svc).ValidateIntRanges(nil))
+
+	// the connection drain timeout of nlb can be lower than the one of clb
+	svc = getDefaultService()
+	svc.Annotations[Annotation(ConnectionDrainTimeout)] = "0"
+	assert.NoError(t, NewAnnotationRequest(svc).ValidateIntRanges(NLBIntRanges))
+	assert.Error(t, NewAnnotationRequest(svc).ValidateIntRanges(CLBIntRanges))
+}
+
+func TestValidateImmutable(t *testing.T) {
+	warning, err := ValidateImmutable(AddressType, "internet", "internet")
+	assert.NoError(t, err)
+	assert.Equal(t, "", warning)
+
+	warning, err = ValidateImmutable(AddressType, "", "intranet")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "", warning)
+
+	_, err = ValidateImmutable(AddressType, "internet", "intranet")
+	assert.Error(t, err)
+}
@@ -48,6 +48,12 @@ func NewEndpointWithENI(reqCtx *svcCtx.RequestContext, kubeClient client.Client)
 		reqCtx.Log.Info("backend details", "endpoints", helper.LogEndpoints(eps))
 	}
 
+	topology, err := NewZoneTopology(reqCtx, endpointWithENI)
+	if err != nil {
+		return nil, fmt.Errorf("build zone topology error: %s", err.Error())
+	}
+	endpointWithENI.Topology = topology
+
 	return endpointWithENI, nil
 }
 
@@ -72,6 +78,9 @@ type EndpointWithENI struct {
 	// EndpointSlices
 	// contains all the endpointslices of a service
 	EndpointSlices []discovery.EndpointSlice
+	// Topology
+	// resolves the zones of the backends, it is nil unless the zone weight policy is used
+	Topology *ZoneTopology
 }
 
 func (e *EndpointWithENI) setTrafficPolicy(reqCtx *svcCtx.RequestContext) {
@@ -0,0 +1,191 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DrainFinalizerPrefix prefixes the pod finalizers which keep a terminating pod
+// until it is removed from the backends of the service named in the finalizer.
+const DrainFinalizerPrefix = "drain.service.k8s.alibaba"
+
+// LabelDrainFinalizer marks the service which may have added the drain finalizer to the pods, so that the
+// pods are only listed for the stale finalizers of these services.
+const LabelDrainFinalizer = "service.k8s.alibaba/drain-finalizer"
+
+// MaxDeregistrationDelay is the upper bound of the deregistration-delay annotation in seconds.
+const MaxDeregistrationDelay = 3600
+
+func DrainFinalizer(svc *v1.Service) string {
+	return fmt.Sprintf("%s/%s", DrainFinalizerPrefix, svc.Name)
+}
+
+// DrainOptions controls how the terminating pods are removed from the backends.
+type DrainOptions struct {
+	// Delay keeps the terminating pods in the backends with weight 0 for the duration
+	// since their deletion before they are removed.
+	Delay time.Duration
+	// Finalizer adds the drain finalizer to the registered pods, which is removed
+	// once the pods are removed from the backends.
+	Finalizer bool
+}
+
+// GetDrainOptions parses the drain options of the service. The terminating pods are only
+// known through the conditions of the EndpointSlices, so the options are ignored unless
+// the pods are the backends and the EndpointSlice feature gate is enabled.
+func GetDrainOptions(reqCtx *svcCtx.RequestContext) (DrainOptions, error) {
+	var opts DrainOptions
+	if v := reqCtx.Anno.Get(annotation.DeregistrationDelay); v != "" {
+		delay, err := strconv.Atoi(v)
+		if err != nil || delay < 0 || delay > MaxDeregistrationDelay {
+			return opts, fmt.Errorf("%s must be integer in range [0, %d], got [%s]",
+				annotation.Annotation(annotation.DeregistrationDelay), MaxDeregistrationDelay, v)
+		}
+		opts.Delay = time.Duration(delay) * time.Second
+	}
+	opts.Finalizer = strings.EqualFold(reqCtx.Anno.Get(annotation.DeregistrationFinalizer), string(model.OnFlag))
+
+	if !helper.IsENIBackendType(reqCtx.Service) ||
+		!utilfeature.DefaultMutableFeatureGate.Enabled(ctrlCfg.EndpointSlice) {
+		return DrainOptions{}, nil
+	}
+	return opts, nil
+}
+
+// GetDrainDeadline returns the time until which the terminating endpoint is kept in the backends,
+// counted from the deletion of its pod. It is zero if the endpoint should be removed now.
+func GetDrainDeadline(ctx context.Context, kubeClient client.Client, ep discovery.Endpoint, delay time.Duration) (time.Time, error) {
+	if delay == 0 || ep.TargetRef == nil || ep.TargetRef.Kind != "Pod" {
+		return time.Time{}, nil
+	}
+	pod := &v1.Pod{}
+	err := kubeClient.Get(ctx, types.NamespacedName{Namespace: ep.TargetRef.Namespace, Name: ep.TargetRef.Name}, pod)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	if pod.DeletionTimestamp == nil {
+		return time.Time{}, nil
+	}
+	deadline := pod.DeletionTimestamp.Add(delay)
+	if !deadline.After(time.Now()) {
+		return time.Time{}, nil
+	}
+	return deadline, nil
+}
+
+// DrainRequeueAfter returns the duration until the earliest of the deadlines, or 0 if there is none.
+func DrainRequeueAfter(deadlines []time.Time) time.Duration {
+	var after time.Duration
+	for _, d := range deadlines {
+		if d.IsZero() {
+			continue
+		}
+		// requeue a second later so that the deadline is over when the backends are rebuilt
+		remaining := time.Until(d) + time.Second
+		if after == 0 || remaining < after {
+			after = remaining
+		}
+	}
+	return after
+}
+
+// SyncDrainFinalizers adds the drain finalizer of the service to the registered pods, and removes it
+// from the deleted pods which are not draining any more. All the finalizers of the service are
+// removed if the finalizer is disabled, e.g. when the load balancer is deleted. The pods are not listed
+// if the finalizer is disabled and the service is not marked by LabelDrainFinalizer.
+func SyncDrainFinalizers(reqCtx *svcCtx.RequestContext, kubeClient client.Client, enabled bool,
+	registered, draining map[types.NamespacedName]bool) error {
+	marked := reqCtx.Service.Labels[LabelDrainFinalizer] == "true"
+	if !enabled && !marked {
+		return nil
+	}
+	finalizer := DrainFinalizer(reqCtx.Service)
+	finalizerMgr := helper.NewDefaultFinalizerManager(kubeClient)
+	var errs []error
+
+	if enabled {
+		// the service is marked before any pod gets the finalizer
+		if !marked {
+			if err := setDrainFinalizerLabel(reqCtx, kubeClient, true); err != nil {
+				return err
+			}
+		}
+		for key := range registered {
+			pod := &v1.Pod{}
+			if err := kubeClient.Get(reqCtx.Ctx, key, pod); err != nil {
+				if !apierrors.IsNotFound(err) {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			if pod.DeletionTimestamp != nil || helper.HasFinalizer(pod, finalizer) {
+				continue
+			}
+			if err := finalizerMgr.AddFinalizers(reqCtx.Ctx, pod, finalizer); err != nil {
+				errs = append(errs, fmt.Errorf("add finalizer to pod %s error: %s", key, err.Error()))
+			}
+		}
+	}
+
+	pods := &v1.PodList{}
+	if err := kubeClient.List(reqCtx.Ctx, pods, client.InNamespace(reqCtx.Service.Namespace)); err != nil {
+		return utilerrors.NewAggregate(append(errs, err))
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !helper.HasFinalizer(pod, finalizer) {
+			continue
+		}
+		key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+		if enabled && (pod.DeletionTimestamp == nil || draining[key]) {
+			continue
+		}
+		reqCtx.Log.Info("pod is removed from the backends, remove drain finalizer", "pod", key.String())
+		if err := finalizerMgr.RemoveFinalizers(reqCtx.Ctx, pod, finalizer); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("remove finalizer from pod %s error: %s", key, err.Error()))
+		}
+	}
+	if !enabled && len(errs) == 0 {
+		if err := setDrainFinalizerLabel(reqCtx, kubeClient, false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func setDrainFinalizerLabel(reqCtx *svcCtx.RequestContext, kubeClient client.Client, marked bool) error {
+	svc := reqCtx.Service
+	updated := svc.DeepCopy()
+	if marked {
+		if updated.Labels == nil {
+			updated.Labels = map[string]string{}
+		}
+		updated.Labels[LabelDrainFinalizer] = "true"
+	} else {
+		delete(updated.Labels, LabelDrainFinalizer)
+	}
+	if err := kubeClient.Patch(reqCtx.Ctx, updated, client.MergeFrom(svc)); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("update label %s of service error: %s", LabelDrainFinalizer, err.Error())
+	}
+	svc.Labels = updated.Labels
+	return nil
+}
@@ -0,0 +1,165 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getDrainService(anno map[string]string) *v1.Service {
+	annotations := map[string]string{helper.BackendType: model.ENIBackendType}
+	for k, v := range anno {
+		annotations[k] = v
+	}
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   v1.NamespaceDefault,
+			Annotations: annotations,
+		},
+		Spec: v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+}
+
+func getDrainReqCtx(svc *v1.Service) *svcCtx.RequestContext {
+	return &svcCtx.RequestContext{
+		Ctx:     context.TODO(),
+		Service: svc,
+		Anno:    annotation.NewAnnotationRequest(svc),
+		Log:     util.ServiceLog.WithValues("service", util.Key(svc)),
+	}
+}
+
+func getPod(name string, deletedAt *metav1.Time, finalizers ...string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         v1.NamespaceDefault,
+			DeletionTimestamp: deletedAt,
+			Finalizers:        finalizers,
+		},
+	}
+}
+
+func TestGetDrainOptions(t *testing.T) {
+	_ = utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(ctrlCfg.EndpointSlice): true})
+	defer func() {
+		_ = utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(ctrlCfg.EndpointSlice): false})
+	}()
+
+	svc := getDrainService(map[string]string{
+		annotation.Annotation(annotation.DeregistrationDelay):     "30",
+		annotation.Annotation(annotation.DeregistrationFinalizer): "on",
+	})
+	opts, err := GetDrainOptions(getDrainReqCtx(svc))
+	assert.NoError(t, err)
+	assert.Equal(t, DrainOptions{Delay: 30 * time.Second, Finalizer: true}, opts)
+
+	svc.Annotations[annotation.Annotation(annotation.DeregistrationDelay)] = "3601"
+	_, err = GetDrainOptions(getDrainReqCtx(svc))
+	assert.Error(t, err)
+
+	// the options are ignored if the nodes are the backends
+	svc.Annotations[annotation.Annotation(annotation.DeregistrationDelay)] = "30"
+	svc.Annotations[helper.BackendType] = model.ECSBackendType
+	opts, err = GetDrainOptions(getDrainReqCtx(svc))
+	assert.NoError(t, err)
+	assert.Equal(t, DrainOptions{}, opts)
+}
+
+func TestGetDrainDeadline(t *testing.T) {
+	deletedAt := metav1.NewTime(time.Now().Add(-10 * time.Second))
+	expiredAt := metav1.NewTime(time.Now().Add(-time.Minute))
+	kubeClient := fake.NewClientBuilder().WithObjects(
+		getPod("deleting", &deletedAt, "test/hold"),
+		getPod("expired", &expiredAt, "test/hold"),
+	).Build()
+	ep := func(name string) discovery.Endpoint {
+		return discovery.Endpoint{TargetRef: &v1.ObjectReference{Kind: "Pod", Namespace: v1.NamespaceDefault, Name: name}}
+	}
+
+	deadline, err := GetDrainDeadline(context.TODO(), kubeClient, ep("deleting"), 30*time.Second)
+	assert.NoError(t, err)
+	// the deletion timestamp is stored in seconds
+	assert.WithinDuration(t, deletedAt.Add(30*time.Second), deadline, time.Second)
+
+	deadline, err = GetDrainDeadline(context.TODO(), kubeClient, ep("expired"), 30*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, deadline.IsZero())
+
+	deadline, err = GetDrainDeadline(context.TODO(), kubeClient, ep("not-found"), 30*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, deadline.IsZero())
+
+	deadline, err = GetDrainDeadline(context.TODO(), kubeClient, ep("deleting"), 0)
+	assert.NoError(t, err)
+	assert.True(t, deadline.IsZero())
+}
+
+func TestDrainRequeueAfter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), DrainRequeueAfter(nil))
+	after := DrainRequeueAfter([]time.Time{{}, time.Now().Add(time.Minute), time.Now().Add(10 * time.Second)})
+	assert.True(t, after > 10*time.Second && after <= 11*time.Second)
+}
+
+func TestSyncDrainFinalizers(t *testing.T) {
+	svc := getDrainService(nil)
+	finalizer := DrainFinalizer(svc)
+	assert.Equal(t, "drain.service.k8s.alibaba/web", finalizer)
+
+	deletedAt := metav1.Now()
+	kubeClient := fake.NewClientBuilder().WithObjects(
+		svc,
+		getPod("ready", nil),
+		getPod("draining", &deletedAt, finalizer),
+		getPod("drained", &deletedAt, finalizer),
+		getPod("other", &deletedAt, "drain.service.k8s.alibaba/other"),
+	).Build()
+	key := func(name string) types.NamespacedName {
+		return types.NamespacedName{Namespace: v1.NamespaceDefault, Name: name}
+	}
+	finalizers := func(name string) []string {
+		pod := &v1.Pod{}
+		if err := kubeClient.Get(context.TODO(), key(name), pod); err != nil {
+			return nil
+		}
+		return pod.Finalizers
+	}
+
+	// the pods are not listed unless the service is marked
+	err := SyncDrainFinalizers(getDrainReqCtx(svc), kubeClient, false, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{finalizer}, finalizers("drained"))
+
+	err = SyncDrainFinalizers(getDrainReqCtx(svc), kubeClient, true,
+		map[types.NamespacedName]bool{key("ready"): true},
+		map[types.NamespacedName]bool{key("draining"): true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{finalizer}, finalizers("ready"))
+	assert.Equal(t, []string{finalizer}, finalizers("draining"))
+	assert.Empty(t, finalizers("drained"))
+	assert.Equal(t, []string{"drain.service.k8s.alibaba/other"}, finalizers("other"))
+	assert.Equal(t, "true", svc.Labels[LabelDrainFinalizer])
+
+	// all the finalizers of the service are removed once it is disabled
+	err = SyncDrainFinalizers(getDrainReqCtx(svc), kubeClient, false, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, finalizers("ready"))
+	assert.Empty(t, finalizers("draining"))
+	assert.Equal(t, []string{"drain.service.k8s.alibaba/other"}, finalizers("other"))
+	assert.Empty(t, svc.Labels[LabelDrainFinalizer])
+}
@@ -0,0 +1,223 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+)
+
+// The policies of the backend weights, set by the weight-policy annotation.
+const (
+	// WeightPolicyPod weights the backends by the number of pods, which is the default.
+	WeightPolicyPod = "pod"
+	// WeightPolicyZone shares the weight equally among the zones of the backends,
+	// and takes the zones whose pods are unhealthy out of the rotation.
+	WeightPolicyZone = "zone"
+)
+
+const (
+	// DefaultZoneHealthyThreshold is the default percentage of ready pods below which a zone is unhealthy.
+	DefaultZoneHealthyThreshold = 50
+	// maxZoneWeight is the weight of a zone if the total weight is not set.
+	maxZoneWeight = 100
+)
+
+// ZoneTopology resolves the zones of the backends of a service for the zone-aware weights.
+type ZoneTopology struct {
+	// nodeZones maps the node names to zones
+	nodeZones map[string]string
+	// instanceZones maps the ecs instance ids to zones
+	instanceZones map[string]string
+	// endpointZones maps the endpoint addresses to zones, which follow the topology hints
+	endpointZones map[string]string
+	// unhealthyZones are the zones whose ready pods are below the threshold
+	unhealthyZones map[string]bool
+}
+
+// NewZoneTopology builds the topology of the candidates if the service uses the zone weight policy,
+// and returns nil otherwise.
+func NewZoneTopology(reqCtx *svcCtx.RequestContext, candidates *EndpointWithENI) (*ZoneTopology, error) {
+	switch policy := reqCtx.Anno.Get(annotation.WeightPolicy); policy {
+	case "", WeightPolicyPod:
+		return nil, nil
+	case WeightPolicyZone:
+	default:
+		return nil, fmt.Errorf("%s must be one of [%s, %s], got [%s]",
+			annotation.Annotation(annotation.WeightPolicy), WeightPolicyPod, WeightPolicyZone, policy)
+	}
+
+	threshold := DefaultZoneHealthyThreshold
+	if v := reqCtx.Anno.Get(annotation.ZoneHealthyThreshold); v != "" {
+		t, err := strconv.Atoi(v)
+		if err != nil || t < 0 || t > 100 {
+			return nil, fmt.Errorf("%s must be integer in range [0, 100], got [%s]",
+				annotation.Annotation(annotation.ZoneHealthyThreshold), v)
+		}
+		threshold = t
+	}
+
+	t := &ZoneTopology{
+		nodeZones:      make(map[string]string),
+		instanceZones:  make(map[string]string),
+		endpointZones:  make(map[string]string),
+		unhealthyZones: make(map[string]bool),
+	}
+	for _, n := range candidates.Nodes {
+		zone := n.Labels[v1.LabelTopologyZone]
+		if zone == "" {
+			continue
+		}
+		t.nodeZones[n.Name] = zone
+		if _, id, err := helper.NodeFromProviderID(n.Spec.ProviderID); err == nil {
+			t.instanceZones[id] = zone
+		}
+	}
+
+	// the health of a zone is counted by the zone the pods run in, regardless of the hints
+	ready, total := make(map[string]int), make(map[string]int)
+	count := func(zone string, isReady bool) {
+		if zone == "" {
+			return
+		}
+		total[zone]++
+		if isReady {
+			ready[zone]++
+		}
+	}
+	if len(candidates.EndpointSlices) != 0 {
+		seen := make(map[string]bool)
+		for _, es := range candidates.EndpointSlices {
+			for _, ep := range es.Endpoints {
+				if ep.Conditions.Terminating != nil && *ep.Conditions.Terminating {
+					continue
+				}
+				zone := t.endpointZone(ep)
+				for _, addr := range ep.Addresses {
+					if seen[addr] {
+						continue
+					}
+					seen[addr] = true
+					t.endpointZones[addr] = hintZone(ep, zone)
+					count(zone, ep.Conditions.Ready == nil || *ep.Conditions.Ready)
+				}
+			}
+		}
+	} else if candidates.Endpoints != nil {
+		for _, subset := range candidates.Endpoints.Subsets {
+			for _, addr := range subset.Addresses {
+				count(t.nodeZone(addr.NodeName), true)
+			}
+			for _, addr := range subset.NotReadyAddresses {
+				count(t.nodeZone(addr.NodeName), false)
+			}
+		}
+	}
+	for zone := range total {
+		if ready[zone]*100 < total[zone]*threshold {
+			t.unhealthyZones[zone] = true
+		}
+	}
+	if len(t.unhealthyZones) != 0 {
+		reqCtx.Log.Info("zones with unhealthy backends", "zones", t.UnhealthyZones())
+	}
+	return t, nil
+}
+
+func (t *ZoneTopology) nodeZone(nodeName *string) string {
+	if nodeName == nil {
+		return ""
+	}
+	return t.nodeZones[*nodeName]
+}
+
+func (t *ZoneTopology) endpointZone(ep discovery.Endpoint) string {
+	if ep.Zone != nil && *ep.Zone != "" {
+		return *ep.Zone
+	}
+	return t.nodeZone(ep.NodeName)
+}
+
+// hintZone returns the zone the endpoint is allocated to by the topology hints of the EndpointSlice
+// controller, which are set for the services with topology aware routing, e.g. trafficDistribution.
+func hintZone(ep discovery.Endpoint, zone string) string {
+	if ep.Hints != nil && len(ep.Hints.ForZones) != 0 && ep.Hints.ForZones[0].Name != "" {
+		return ep.Hints.ForZones[0].Name
+	}
+	return zone
+}
+
+// BackendZone returns the zone of a backend, which is the zone of the ecs instance for the ecs backends,
+// and the zone of the endpoint for the pods. It is empty if the zone is unknown.
+func (t *ZoneTopology) BackendZone(isECS bool, serverId, serverIp string, nodeName *string) string {
+	if isECS {
+		if zone, ok := t.instanceZones[serverId]; ok {
+			return zone
+		}
+		return t.nodeZone(nodeName)
+	}
+	if zone, ok := t.endpointZones[serverIp]; ok && zone != "" {
+		return zone
+	}
+	return t.nodeZone(nodeName)
+}
+
+func (t *ZoneTopology) UnhealthyZones() []string {
+	var zones []string
+	for z := range t.unhealthyZones {
+		zones = append(zones, z)
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+// Weights shares the weight equally among the zones of the backends, and then among the backends of
+// a zone in proportion to their units, e.g. the number of pods on a node. The zone weight is 100 if
+// weight is nil, and weight divided by the number of zones otherwise. If checkHealth is set, the
+// backends of the unhealthy zones get weight 0, unless no zone is healthy. The backends whose zone is
+// unknown are taken as a zone of their own.
+func (t *ZoneTopology) Weights(zones []string, units []int, weight *int, checkHealth bool) []int {
+	weights := make([]int, len(zones))
+	zoneUnits := make(map[string]int)
+	for i, z := range zones {
+		zoneUnits[z] += units[i]
+	}
+
+	serving := make(map[string]bool)
+	for z := range zoneUnits {
+		if !checkHealth || !t.unhealthyZones[z] {
+			serving[z] = true
+		}
+	}
+	if len(serving) == 0 {
+		for z := range zoneUnits {
+			serving[z] = true
+		}
+	}
+	if len(serving) == 0 {
+		return weights
+	}
+
+	zoneWeight := maxZoneWeight
+	if weight != nil {
+		if *weight == 0 {
+			return weights
+		}
+		zoneWeight = *weight / len(serving)
+	}
+	for i, z := range zones {
+		if !serving[z] || zoneUnits[z] == 0 {
+			continue
+		}
+		weights[i] = zoneWeight * units[i] / zoneUnits[z]
+		if weights[i] < 1 {
+			weights[i] = 1
+		}
+	}
+	return weights
+}
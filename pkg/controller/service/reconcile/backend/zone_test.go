@@ -0,0 +1,93 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+)
+
+func getZoneNode(name, zone string) v1.Node {
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{v1.LabelTopologyZone: zone}},
+		Spec:       v1.NodeSpec{ProviderID: "cn-hangzhou.i-" + name},
+	}
+}
+
+func getZoneEndpoint(addr, node string, ready bool, hint string) discovery.Endpoint {
+	ep := discovery.Endpoint{
+		Addresses:  []string{addr},
+		NodeName:   &node,
+		Conditions: discovery.EndpointConditions{Ready: &ready},
+	}
+	if hint != "" {
+		ep.Hints = &discovery.EndpointHints{ForZones: []discovery.ForZone{{Name: hint}}}
+	}
+	return ep
+}
+
+func TestNewZoneTopology(t *testing.T) {
+	svc := getDrainService(map[string]string{annotation.Annotation(annotation.WeightPolicy): WeightPolicyZone})
+	candidates := &EndpointWithENI{
+		Nodes: []v1.Node{getZoneNode("a", "zone-a"), getZoneNode("b", "zone-b")},
+		EndpointSlices: []discovery.EndpointSlice{{
+			Endpoints: []discovery.Endpoint{
+				getZoneEndpoint("10.0.0.1", "a", true, ""),
+				getZoneEndpoint("10.0.0.2", "a", true, "zone-b"),
+				getZoneEndpoint("10.0.1.1", "b", false, ""),
+				getZoneEndpoint("10.0.1.2", "b", false, ""),
+				getZoneEndpoint("10.0.1.3", "b", true, ""),
+			},
+		}},
+	}
+
+	topology, err := NewZoneTopology(getDrainReqCtx(svc), candidates)
+	assert.NoError(t, err)
+	assert.Equal(t, "zone-a", topology.BackendZone(false, "eni-1", "10.0.0.1", nil))
+	// the endpoint follows the hint
+	assert.Equal(t, "zone-b", topology.BackendZone(false, "eni-2", "10.0.0.2", nil))
+	assert.Equal(t, "zone-b", topology.BackendZone(true, "i-b", "", nil))
+	assert.Equal(t, "", topology.BackendZone(true, "i-c", "", nil))
+	// one of the three pods of zone-b is ready
+	assert.Equal(t, []string{"zone-b"}, topology.UnhealthyZones())
+
+	svc.Annotations[annotation.Annotation(annotation.ZoneHealthyThreshold)] = "30"
+	topology, err = NewZoneTopology(getDrainReqCtx(svc), candidates)
+	assert.NoError(t, err)
+	assert.Empty(t, topology.UnhealthyZones())
+
+	svc.Annotations[annotation.Annotation(annotation.WeightPolicy)] = WeightPolicyPod
+	topology, err = NewZoneTopology(getDrainReqCtx(svc), candidates)
+	assert.NoError(t, err)
+	assert.Nil(t, topology)
+
+	svc.Annotations[annotation.Annotation(annotation.WeightPolicy)] = "region"
+	_, err = NewZoneTopology(getDrainReqCtx(svc), candidates)
+	assert.Error(t, err)
+}
+
+func TestZoneTopologyWeights(t *testing.T) {
+	topology := &ZoneTopology{unhealthyZones: map[string]bool{"zone-c": true}}
+
+	// zone-a and zone-b take the same weight regardless of the number of backends
+	weights := topology.Weights([]string{"zone-a", "zone-a", "zone-b", "zone-c"}, []int{1, 1, 1, 1}, nil, true)
+	assert.Equal(t, []int{50, 50, 100, 0}, weights)
+
+	// the units share the weight of a zone
+	weights = topology.Weights([]string{"zone-a", "zone-a", "zone-b"}, []int{3, 1, 2}, nil, true)
+	assert.Equal(t, []int{75, 25, 100}, weights)
+
+	// the total weight is shared among the zones
+	total := 60
+	weights = topology.Weights([]string{"zone-a", "zone-b", "zone-b", "zone-c"}, []int{1, 1, 1, 1}, &total, true)
+	assert.Equal(t, []int{30, 15, 15, 0}, weights)
+
+	// the unhealthy zones are kept without health check, or if no zone is healthy
+	weights = topology.Weights([]string{"zone-a", "zone-c"}, []int{1, 1}, nil, false)
+	assert.Equal(t, []int{100, 100}, weights)
+	weights = topology.Weights([]string{"zone-c", "zone-c"}, []int{1, 1}, nil, true)
+	assert.Equal(t, []int{50, 50}, weights)
+}
@@ -0,0 +1,82 @@
+package certificate
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// NewEnqueueRequestForSecretEvent enqueues the services referring to the changed secret,
+// filtered by needService to the services of the controller.
+func NewEnqueueRequestForSecretEvent(client client.Client, needService func(*v1.Service) bool) *enqueueRequestForSecretEvent {
+	return &enqueueRequestForSecretEvent{
+		client:      client,
+		needService: needService,
+	}
+}
+
+type enqueueRequestForSecretEvent struct {
+	client      client.Client
+	needService func(*v1.Service) bool
+}
+
+var _ handler.EventHandler = (*enqueueRequestForSecretEvent)(nil)
+
+func (h *enqueueRequestForSecretEvent) Create(_ context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	secret, ok := e.Object.(*v1.Secret)
+	if ok {
+		h.enqueueReferringServices(queue, secret)
+	}
+}
+
+func (h *enqueueRequestForSecretEvent) Update(_ context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldSecret, ok1 := e.ObjectOld.(*v1.Secret)
+	newSecret, ok2 := e.ObjectNew.(*v1.Secret)
+	if ok1 && ok2 && !reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+		h.enqueueReferringServices(queue, newSecret)
+	}
+}
+
+func (h *enqueueRequestForSecretEvent) Delete(_ context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	// the listeners keep the last uploaded certificate, nothing to do until the secret is back
+}
+
+func (h *enqueueRequestForSecretEvent) Generic(_ context.Context, e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+	// unknown event, ignore
+}
+
+func (h *enqueueRequestForSecretEvent) enqueueReferringServices(queue workqueue.RateLimitingInterface, secret *v1.Secret) {
+	svcs := v1.ServiceList{}
+	if err := h.client.List(context.TODO(), &svcs, client.InNamespace(secret.Namespace)); err != nil {
+		klog.Errorf("fail to list services for secret %s/%s: %s", secret.Namespace, secret.Name, err.Error())
+		return
+	}
+	for i := range svcs.Items {
+		svc := &svcs.Items[i]
+		if !h.needService(svc) || !isSecretReferred(svc, secret.Name) {
+			continue
+		}
+		queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: svc.Namespace,
+				Name:      svc.Name,
+			},
+		})
+		klog.Info(fmt.Sprintf("secret change: enqueue service %s/%s", svc.Namespace, svc.Name))
+	}
+}
+
+func isSecretReferred(svc *v1.Service, secretName string) bool {
+	anno := &annotation.AnnotationRequest{Service: svc}
+	return anno.Get(annotation.CertSecret) == secretName || anno.Get(annotation.CaCertSecret) == secretName
+}
@@ -0,0 +1,158 @@
+package certificate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SecretCertNamePrefix marks the certificates uploaded from the secrets of services. The name is
+	// <prefix>-<cluster hash>-<service hash>-<content hash>, so every service owns its certificates.
+	SecretCertNamePrefix = "k8s-svc"
+	secretCertHashLength = 8
+
+	// CACertKey is the key of the ca bundle in the secret referenced by the cacert-secret annotation
+	CACertKey = "ca.crt"
+)
+
+// UseSecret returns whether the listeners of the service refer to certificates stored in secrets.
+func UseSecret(anno *annotation.AnnotationRequest) bool {
+	return anno.Get(annotation.CertSecret) != "" || anno.Get(annotation.CaCertSecret) != ""
+}
+
+func shortHash(data ...[]byte) string {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:secretCertHashLength]
+}
+
+func secretCertNamePrefix(clusterID string, svc *v1.Service) string {
+	return fmt.Sprintf("%s-%s-%s-", SecretCertNamePrefix, shortHash([]byte(clusterID)), shortHash([]byte(util.Key(svc))))
+}
+
+// BuildSecretCertName returns the certificate name of the secret data. Rotating the secret
+// changes the name, so a new certificate is uploaded and the old one collected.
+func BuildSecretCertName(clusterID string, svc *v1.Service, data ...[]byte) string {
+	return secretCertNamePrefix(clusterID, svc) + shortHash(data...)
+}
+
+// Store is where the certificates of secrets are uploaded to.
+type Store interface {
+	// DataKeys returns the keys of the secret data to upload
+	DataKeys() []string
+	// Find returns the id of the certificate named certName, or "" if it does not exist
+	Find(ctx context.Context, certName string) (string, error)
+	// List returns the ids of the certificates whose name starts with prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+	Upload(ctx context.Context, certName string, data [][]byte) (string, error)
+	Delete(ctx context.Context, certID string) error
+}
+
+func NewManager(kubeClient client.Client, store Store) *Manager {
+	return &Manager{
+		kubeClient: kubeClient,
+		store:      store,
+	}
+}
+
+// Manager uploads the secrets referenced by a service to the store and deletes the
+// certificates the service no longer uses.
+type Manager struct {
+	kubeClient client.Client
+	store      Store
+}
+
+func (m *Manager) loadSecretCertName(ctx context.Context, svc *v1.Service, secretName string) (string, [][]byte, error) {
+	secret := &v1.Secret{}
+	key := types.NamespacedName{Namespace: svc.Namespace, Name: secretName}
+	if err := m.kubeClient.Get(ctx, key, secret); err != nil {
+		return "", nil, err
+	}
+	var data [][]byte
+	for _, k := range m.store.DataKeys() {
+		if len(secret.Data[k]) == 0 {
+			return "", nil, fmt.Errorf("secret %s has no %s", key, k)
+		}
+		data = append(data, secret.Data[k])
+	}
+	return BuildSecretCertName(base.CLUSTER_ID, svc, data...), data, nil
+}
+
+// Sync uploads the secret when its certificate does not exist yet, and returns the certificate id.
+func (m *Manager) Sync(ctx context.Context, svc *v1.Service, secretName string) (string, error) {
+	certName, data, err := m.loadSecretCertName(ctx, svc, secretName)
+	if err != nil {
+		return "", fmt.Errorf("load secret %s error: %s", secretName, err.Error())
+	}
+	certID, err := m.store.Find(ctx, certName)
+	if err != nil {
+		return "", fmt.Errorf("find cert %s error: %s", certName, err.Error())
+	}
+	if certID != "" {
+		return certID, nil
+	}
+
+	klog.Infof("%s: upload cert %s of secret %s", util.Key(svc), certName, secretName)
+	certID, err = m.store.Upload(ctx, certName, data)
+	if err != nil {
+		return "", fmt.Errorf("upload cert %s of secret %s error: %s", certName, secretName, err.Error())
+	}
+	return certID, nil
+}
+
+// GarbageCollect deletes the certificates of the service except the one of secretName.
+// An empty secretName deletes all of them.
+func (m *Manager) GarbageCollect(ctx context.Context, svc *v1.Service, secretName string) error {
+	inUse := sets.NewString()
+	if secretName != "" {
+		certName, _, err := m.loadSecretCertName(ctx, svc, secretName)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				// the listener may still use the last uploaded certificate
+				return nil
+			}
+			return err
+		}
+		certID, err := m.store.Find(ctx, certName)
+		if err != nil {
+			return err
+		}
+		inUse.Insert(certID)
+	}
+
+	certIDs, err := m.store.List(ctx, secretCertNamePrefix(base.CLUSTER_ID, svc))
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for _, certID := range certIDs {
+		if inUse.Has(certID) {
+			continue
+		}
+		klog.Infof("%s: delete unused cert %s", util.Key(svc), certID)
+		// certs still attached to a listener fail to delete and are retried next time
+		if err := m.store.Delete(ctx, certID); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("delete certs error: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package certificate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeStore struct {
+	certs map[string]string // id -> name
+	next  int
+}
+
+func (s *fakeStore) DataKeys() []string {
+	return []string{v1.TLSCertKey, v1.TLSPrivateKeyKey}
+}
+
+func (s *fakeStore) Find(ctx context.Context, certName string) (string, error) {
+	for id, name := range s.certs {
+		if name == certName {
+			return id, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *fakeStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var ids []string
+	for id, name := range s.certs {
+		if strings.HasPrefix(name, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *fakeStore) Upload(ctx context.Context, certName string, data [][]byte) (string, error) {
+	s.next++
+	id := fmt.Sprintf("cert-%d", s.next)
+	s.certs[id] = certName
+	return id, nil
+}
+
+func (s *fakeStore) Delete(ctx context.Context, certID string) error {
+	delete(s.certs, certID)
+	return nil
+}
+
+func TestManager(t *testing.T) {
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "https"}}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "tls"},
+		Data:       map[string][]byte{v1.TLSCertKey: []byte("cert"), v1.TLSPrivateKeyKey: []byte("key")},
+	}
+	kubeClient := fake.NewClientBuilder().WithObjects(secret).Build()
+	store := &fakeStore{certs: map[string]string{"user": "user-cert"}}
+	mgr := NewManager(kubeClient, store)
+	ctx := context.TODO()
+
+	certID, err := mgr.Sync(ctx, svc, "tls")
+	assert.NoError(t, err)
+	again, err := mgr.Sync(ctx, svc, "tls")
+	assert.NoError(t, err)
+	assert.Equal(t, certID, again)
+	assert.Len(t, store.certs, 2)
+
+	// rotate the secret
+	secret.Data[v1.TLSCertKey] = []byte("cert2")
+	assert.NoError(t, kubeClient.Update(ctx, secret))
+	rotated, err := mgr.Sync(ctx, svc, "tls")
+	assert.NoError(t, err)
+	assert.NotEqual(t, certID, rotated)
+
+	assert.NoError(t, mgr.GarbageCollect(ctx, svc, "tls"))
+	assert.Equal(t, map[string]string{"user": "user-cert", rotated: store.certs[rotated]}, store.certs)
+
+	// the service is deleted
+	assert.NoError(t, mgr.GarbageCollect(ctx, svc, ""))
+	assert.Equal(t, map[string]string{"user": "user-cert"}, store.certs)
+
+	_, err = mgr.Sync(ctx, svc, "missing")
+	assert.Error(t, err)
+}
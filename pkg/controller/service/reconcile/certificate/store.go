@@ -0,0 +1,152 @@
+package certificate
+
+import (
+	"context"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+)
+
+// NewServerCertStore stores the tls secrets as SLB server certificates, used by CLB listeners.
+func NewServerCertStore(cloud prvd.Provider) Store {
+	return &serverCertStore{cloud: cloud}
+}
+
+type serverCertStore struct {
+	cloud prvd.Provider
+}
+
+func (s *serverCertStore) DataKeys() []string {
+	return []string{v1.TLSCertKey, v1.TLSPrivateKeyKey}
+}
+
+func (s *serverCertStore) Find(ctx context.Context, certName string) (string, error) {
+	certs, err := s.cloud.ListServerCertificates(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range certs {
+		if c.ServerCertificateName == certName {
+			return c.ServerCertificateId, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *serverCertStore) List(ctx context.Context, prefix string) ([]string, error) {
+	certs, err := s.cloud.ListServerCertificates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, c := range certs {
+		if strings.HasPrefix(c.ServerCertificateName, prefix) {
+			ids = append(ids, c.ServerCertificateId)
+		}
+	}
+	return ids, nil
+}
+
+func (s *serverCertStore) Upload(ctx context.Context, certName string, data [][]byte) (string, error) {
+	return s.cloud.UploadServerCertificate(ctx, certName, string(data[0]), string(data[1]))
+}
+
+func (s *serverCertStore) Delete(ctx context.Context, certID string) error {
+	return s.cloud.DeleteServerCertificate(ctx, certID)
+}
+
+// NewCASCertStore stores the tls secrets as CAS certificates, used by NLB listeners.
+func NewCASCertStore(cloud prvd.Provider) Store {
+	return &casCertStore{cloud: cloud}
+}
+
+type casCertStore struct {
+	cloud prvd.Provider
+}
+
+func (s *casCertStore) DataKeys() []string {
+	return []string{v1.TLSCertKey, v1.TLSPrivateKeyKey}
+}
+
+func (s *casCertStore) Find(ctx context.Context, certName string) (string, error) {
+	certs, err := s.cloud.DescribeSSLCertificateList(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range certs {
+		if c.CertName == certName {
+			return c.CertIdentifier, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *casCertStore) List(ctx context.Context, prefix string) ([]string, error) {
+	certs, err := s.cloud.DescribeSSLCertificateList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, c := range certs {
+		if strings.HasPrefix(c.CertName, prefix) {
+			ids = append(ids, c.CertIdentifier)
+		}
+	}
+	return ids, nil
+}
+
+func (s *casCertStore) Upload(ctx context.Context, certName string, data [][]byte) (string, error) {
+	return s.cloud.UploadSSLCertificate(ctx, certName, string(data[0]), string(data[1]), nil)
+}
+
+func (s *casCertStore) Delete(ctx context.Context, certID string) error {
+	return s.cloud.DeleteSSLCertificate(ctx, certID)
+}
+
+// NewCASCACertStore stores the ca bundles as CAS CA certificates, used by NLB listeners
+// with mutual authentication.
+func NewCASCACertStore(cloud prvd.Provider) Store {
+	return &casCACertStore{cloud: cloud}
+}
+
+type casCACertStore struct {
+	cloud prvd.Provider
+}
+
+func (s *casCACertStore) DataKeys() []string {
+	return []string{CACertKey}
+}
+
+// Find relies on the keyword search, the CA certificate list does not return names.
+// The content hash in the name makes the full name match a single certificate.
+func (s *casCACertStore) Find(ctx context.Context, certName string) (string, error) {
+	certs, err := s.cloud.DescribeCACertificateList(ctx, certName)
+	if err != nil {
+		return "", err
+	}
+	if len(certs) == 0 {
+		return "", nil
+	}
+	return certs[0].CertIdentifier, nil
+}
+
+func (s *casCACertStore) List(ctx context.Context, prefix string) ([]string, error) {
+	certs, err := s.cloud.DescribeCACertificateList(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, c := range certs {
+		ids = append(ids, c.CertIdentifier)
+	}
+	return ids, nil
+}
+
+func (s *casCACertStore) Upload(ctx context.Context, certName string, data [][]byte) (string, error) {
+	return s.cloud.UploadCACertificate(ctx, certName, string(data[0]))
+}
+
+func (s *casCACertStore) Delete(ctx context.Context, certID string) error {
+	return s.cloud.DeleteCACertificate(ctx, certID)
+}
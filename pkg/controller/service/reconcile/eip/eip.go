@@ -0,0 +1,335 @@
+package eip
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/vpc"
+)
+
+const (
+	DefaultBandwidth = 5
+	MaxBandwidth     = 500
+	maxNameLength    = 128
+
+	// ZoneTagKey tags the eips allocated for the zones of a network load balancer with the zone id
+	ZoneTagKey = "ack.aliyun.com/eip-zone"
+)
+
+// Options are the attributes of the eips allocated for a service.
+type Options struct {
+	Bandwidth          int
+	ISP                string
+	InternetChargeType string
+	BandwidthPackageId string
+}
+
+// Declared returns whether the service asks the controller to allocate the eips of its load balancer.
+func Declared(anno *annotation.AnnotationRequest) bool {
+	return model.FlagType(anno.Get(annotation.AllocateEip)) == model.OnFlag
+}
+
+// GetOptions returns the attributes of the eips from the annotations, or nil if the service does not
+// allocate eips.
+func GetOptions(anno *annotation.AnnotationRequest) (*Options, error) {
+	if !Declared(anno) {
+		return nil, nil
+	}
+	opts := &Options{
+		Bandwidth:          DefaultBandwidth,
+		ISP:                model.EipISPBGP,
+		InternetChargeType: model.EipInternetChargeTypePayByTraffic,
+		BandwidthPackageId: anno.Get(annotation.EipBandwidthPackageId),
+	}
+	if v := anno.Get(annotation.EipBandwidth); v != "" {
+		bandwidth, err := strconv.Atoi(v)
+		if err != nil || bandwidth < 1 || bandwidth > MaxBandwidth {
+			return nil, fmt.Errorf("annotation %s must be an integer in [1, %d], got [%s]",
+				annotation.Annotation(annotation.EipBandwidth), MaxBandwidth, v)
+		}
+		opts.Bandwidth = bandwidth
+	}
+	if v := anno.Get(annotation.EipISP); v != "" {
+		if v != model.EipISPBGP && v != model.EipISPBGPPro {
+			return nil, fmt.Errorf("annotation %s must be %s or %s, got [%s]",
+				annotation.Annotation(annotation.EipISP), model.EipISPBGP, model.EipISPBGPPro, v)
+		}
+		opts.ISP = v
+	}
+	if v := anno.Get(annotation.EipInternetChargeType); v != "" {
+		switch {
+		case strings.EqualFold(v, model.EipInternetChargeTypePayByTraffic):
+			opts.InternetChargeType = model.EipInternetChargeTypePayByTraffic
+		case strings.EqualFold(v, model.EipInternetChargeTypePayByBandwidth):
+			opts.InternetChargeType = model.EipInternetChargeTypePayByBandwidth
+		default:
+			return nil, fmt.Errorf("annotation %s must be %s or %s, got [%s]",
+				annotation.Annotation(annotation.EipInternetChargeType),
+				model.EipInternetChargeTypePayByTraffic, model.EipInternetChargeTypePayByBandwidth, v)
+		}
+	}
+	return opts, nil
+}
+
+// GetCLBOptions returns the attributes of the eip associated with the clb, which must be an intranet ipv4 one.
+func GetCLBOptions(anno *annotation.AnnotationRequest, addressType model.AddressType,
+	ipVersion model.AddressIPVersionType) (*Options, error) {
+	opts, err := GetOptions(anno)
+	if err != nil || opts == nil {
+		return nil, err
+	}
+	if addressType != model.IntranetAddressType {
+		return nil, fmt.Errorf("the eip can only be associated with an intranet clb, set annotation %s to %s",
+			annotation.Annotation(annotation.AddressType), model.IntranetAddressType)
+	}
+	if ipVersion == model.IPv6 {
+		return nil, fmt.Errorf("the eip can not be associated with an %s clb", model.IPv6)
+	}
+	return opts, nil
+}
+
+// GetNLBOptions returns the attributes of the eips bound to the zones of the nlb, which turns to internet.
+func GetNLBOptions(anno *annotation.AnnotationRequest, mdl *nlbmodel.NetworkLoadBalancer) (*Options, error) {
+	opts, err := GetOptions(anno)
+	if err != nil || opts == nil {
+		return nil, err
+	}
+	if mdl.LoadBalancerAttribute.AddressType == nlbmodel.IntranetAddressType {
+		return nil, fmt.Errorf("the eips are bound to an internet nlb, annotation %s can not be %s",
+			annotation.Annotation(annotation.AddressType), nlbmodel.IntranetAddressType)
+	}
+	for _, z := range mdl.LoadBalancerAttribute.ZoneMappings {
+		if z.AllocationId != "" {
+			return nil, fmt.Errorf("the eip of zone %s is allocated by the controller, "+
+				"it can not be set in annotation %s", z.ZoneId, annotation.Annotation(annotation.ZoneMaps))
+		}
+	}
+	return opts, nil
+}
+
+func NewManager(cloud prvd.Provider) *Manager {
+	return NewManagerWithTags(cloud, (*annotation.AnnotationRequest).GetDefaultTags)
+}
+
+// NewManagerWithTags returns a manager which tags the eips with the given tags instead of the default tags
+// of the service, e.g. the tags of a load balancer shared by several services.
+func NewManagerWithTags(cloud prvd.Provider, tags func(anno *annotation.AnnotationRequest) []tag.Tag) *Manager {
+	return &Manager{cloud: cloud, tags: tags}
+}
+
+// Manager allocates the eips of a service, keeps their attributes as the annotations, and releases them
+// when the service is deleted. The eips are tagged with the default tags of the service.
+type Manager struct {
+	cloud prvd.Provider
+	tags  func(anno *annotation.AnnotationRequest) []tag.Tag
+}
+
+// List returns the eips allocated for the service.
+func (m *Manager) List(reqCtx *svcCtx.RequestContext) ([]model.EipAddress, error) {
+	eips, err := m.cloud.ListEipAddresses(reqCtx.Ctx, m.tags(reqCtx.Anno))
+	if err != nil {
+		return nil, fmt.Errorf("list eips error: %s", err.Error())
+	}
+	return eips, nil
+}
+
+// SyncCLB allocates the eip of the service if there is none, and associates it with the clb.
+func (m *Manager) SyncCLB(reqCtx *svcCtx.RequestContext, opts *Options, lbId string) error {
+	eips, err := m.List(reqCtx)
+	if err != nil {
+		return err
+	}
+	var eip *model.EipAddress
+	for i := range eips {
+		if zoneOf(eips[i]) != "" {
+			continue
+		}
+		if eips[i].InstanceId == lbId {
+			eip = &eips[i]
+			break
+		}
+		if eip == nil && eips[i].InstanceId == "" {
+			eip = &eips[i]
+		}
+	}
+	if eip == nil {
+		allocated, err := m.allocate(reqCtx, opts, buildName(reqCtx.Service, ""), nil)
+		if err != nil {
+			return err
+		}
+		eip = &allocated
+	}
+	if err := m.update(reqCtx, opts, eip); err != nil {
+		return err
+	}
+	if eip.InstanceId != lbId {
+		reqCtx.Log.Info(fmt.Sprintf("associate eip %s with clb %s", eip.AllocationId, lbId))
+		if err := m.cloud.AssociateEipAddress(reqCtx.Ctx, eip.AllocationId, string(vpc.SlbInstance), lbId); err != nil {
+			return fmt.Errorf("associate eip %s with clb %s error: %s", eip.AllocationId, lbId, err.Error())
+		}
+	}
+	return nil
+}
+
+// SyncZones allocates an eip for each of the zones if there is none, and returns the allocation ids of the
+// zones. The eips are bound to the zone mappings of the nlb by the caller.
+func (m *Manager) SyncZones(reqCtx *svcCtx.RequestContext, opts *Options, zoneIds []string) (map[string]string, error) {
+	eips, err := m.List(reqCtx)
+	if err != nil {
+		return nil, err
+	}
+	byZone := map[string]model.EipAddress{}
+	for _, e := range eips {
+		if z := zoneOf(e); z != "" {
+			if _, ok := byZone[z]; !ok {
+				byZone[z] = e
+			}
+		}
+	}
+	ret := map[string]string{}
+	for _, zoneId := range zoneIds {
+		eip, ok := byZone[zoneId]
+		if !ok {
+			eip, err = m.allocate(reqCtx, opts, buildName(reqCtx.Service, zoneId),
+				[]tag.Tag{{Key: ZoneTagKey, Value: zoneId}})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := m.update(reqCtx, opts, &eip); err != nil {
+			return nil, err
+		}
+		ret[zoneId] = eip.AllocationId
+	}
+	return ret, nil
+}
+
+// GarbageCollect releases the eips allocated for the service when it is deleted, unless the service preserves
+// them. The eips associated with a clb are unassociated first, the ones in keep are left, as they are still
+// bound to the zones of a nlb which is kept.
+func (m *Manager) GarbageCollect(reqCtx *svcCtx.RequestContext, keep map[string]bool) error {
+	if reqCtx.Anno.Get(annotation.PreserveEipOnDelete) != "" {
+		return nil
+	}
+	eips, err := m.List(reqCtx)
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for _, e := range eips {
+		if keep[e.AllocationId] {
+			reqCtx.Log.Info(fmt.Sprintf("eip %s is bound to the nlb which is kept, skip releasing it", e.AllocationId))
+			continue
+		}
+		if err := m.release(reqCtx, e); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("release eips error: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (m *Manager) allocate(reqCtx *svcCtx.RequestContext, opts *Options, name string, tags []tag.Tag) (model.EipAddress, error) {
+	eip := model.EipAddress{
+		Name:               name,
+		Description:        fmt.Sprintf("eip of service %s/%s", reqCtx.Service.Namespace, reqCtx.Service.Name),
+		Bandwidth:          opts.Bandwidth,
+		ISP:                opts.ISP,
+		InternetChargeType: opts.InternetChargeType,
+		Tags:               append(m.tags(reqCtx.Anno), tags...),
+	}
+	reqCtx.Log.Info(fmt.Sprintf("allocate eip %s", eip.Name))
+	if err := m.cloud.AllocateEipAddress(reqCtx.Ctx, &eip); err != nil {
+		return eip, fmt.Errorf("allocate eip %s error: %s", eip.Name, err.Error())
+	}
+	return eip, nil
+}
+
+// update keeps the bandwidth and the bandwidth package of the eip as the options, the bandwidth of an eip
+// in a bandwidth package is the one of the package. The isp and the charge type can not be changed.
+func (m *Manager) update(reqCtx *svcCtx.RequestContext, opts *Options, eip *model.EipAddress) error {
+	if eip.ISP != "" && eip.ISP != opts.ISP {
+		return fmt.Errorf("the isp of eip %s can not be changed from %s to %s", eip.AllocationId, eip.ISP, opts.ISP)
+	}
+	if eip.InternetChargeType != "" && eip.InternetChargeType != opts.InternetChargeType {
+		return fmt.Errorf("the internet charge type of eip %s can not be changed from %s to %s",
+			eip.AllocationId, eip.InternetChargeType, opts.InternetChargeType)
+	}
+	if eip.BandwidthPackageId != opts.BandwidthPackageId {
+		if eip.BandwidthPackageId != "" {
+			reqCtx.Log.Info(fmt.Sprintf("remove eip %s from bandwidth package %s", eip.AllocationId, eip.BandwidthPackageId))
+			if err := m.cloud.RemoveCommonBandwidthPackageIp(reqCtx.Ctx, eip.BandwidthPackageId, eip.AllocationId); err != nil {
+				return fmt.Errorf("remove eip %s from bandwidth package %s error: %s",
+					eip.AllocationId, eip.BandwidthPackageId, err.Error())
+			}
+		}
+		if opts.BandwidthPackageId != "" {
+			reqCtx.Log.Info(fmt.Sprintf("add eip %s to bandwidth package %s", eip.AllocationId, opts.BandwidthPackageId))
+			if err := m.cloud.AddCommonBandwidthPackageIp(reqCtx.Ctx, opts.BandwidthPackageId, eip.AllocationId); err != nil {
+				return fmt.Errorf("add eip %s to bandwidth package %s error: %s",
+					eip.AllocationId, opts.BandwidthPackageId, err.Error())
+			}
+		}
+		eip.BandwidthPackageId = opts.BandwidthPackageId
+	}
+	if opts.BandwidthPackageId == "" && eip.Bandwidth != opts.Bandwidth {
+		reqCtx.Log.Info(fmt.Sprintf("bandwidth of eip %s changed from %d to %d", eip.AllocationId, eip.Bandwidth, opts.Bandwidth))
+		if err := m.cloud.ModifyEipAddressBandwidth(reqCtx.Ctx, eip.AllocationId, opts.Bandwidth); err != nil {
+			return fmt.Errorf("modify bandwidth of eip %s error: %s", eip.AllocationId, err.Error())
+		}
+		eip.Bandwidth = opts.Bandwidth
+	}
+	return nil
+}
+
+func (m *Manager) release(reqCtx *svcCtx.RequestContext, eip model.EipAddress) error {
+	if eip.InstanceId != "" {
+		reqCtx.Log.Info(fmt.Sprintf("unassociate eip %s from %s", eip.AllocationId, eip.InstanceId))
+		if err := m.cloud.UnassociateEipAddress(reqCtx.Ctx, eip.AllocationId, eip.InstanceType, eip.InstanceId); err != nil {
+			return fmt.Errorf("unassociate eip %s error: %s", eip.AllocationId, err.Error())
+		}
+	}
+	if eip.BandwidthPackageId != "" {
+		reqCtx.Log.Info(fmt.Sprintf("remove eip %s from bandwidth package %s", eip.AllocationId, eip.BandwidthPackageId))
+		if err := m.cloud.RemoveCommonBandwidthPackageIp(reqCtx.Ctx, eip.BandwidthPackageId, eip.AllocationId); err != nil {
+			return fmt.Errorf("remove eip %s from bandwidth package error: %s", eip.AllocationId, err.Error())
+		}
+	}
+	reqCtx.Log.Info(fmt.Sprintf("release eip %s", eip.AllocationId))
+	if err := m.cloud.ReleaseEipAddress(reqCtx.Ctx, eip.AllocationId); err != nil {
+		return fmt.Errorf("release eip %s error: %s", eip.AllocationId, err.Error())
+	}
+	return nil
+}
+
+// zoneOf returns the zone of the eip allocated for a nlb, or "" for the one of a clb.
+func zoneOf(eip model.EipAddress) string {
+	for _, t := range eip.Tags {
+		if t.Key == ZoneTagKey {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+// buildName returns the name of the eip allocated for the service, and for the zone of a nlb.
+func buildName(svc *v1.Service, zoneId string) string {
+	name := fmt.Sprintf("k8s-%s-%s", svc.Namespace, svc.Name)
+	if zoneId != "" {
+		name = fmt.Sprintf("%s-%s", name, zoneId)
+	}
+	if len(name) > maxNameLength {
+		name = name[:maxNameLength]
+	}
+	return name
+}
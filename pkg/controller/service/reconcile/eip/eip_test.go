@@ -0,0 +1,191 @@
+package eip
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func getService(anno map[string]string) *v1.Service {
+	annotations := map[string]string{annotation.Annotation(annotation.AllocateEip): "on"}
+	for k, v := range anno {
+		annotations[annotation.Annotation(k)] = v
+	}
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "eip", Namespace: v1.NamespaceDefault, UID: "uid-eip", Annotations: annotations},
+	}
+}
+
+func getReqCtx(svc *v1.Service) *svcCtx.RequestContext {
+	return &svcCtx.RequestContext{
+		Ctx:     context.TODO(),
+		Service: svc,
+		Anno:    annotation.NewAnnotationRequest(svc),
+		Log:     util.ServiceLog.WithValues("service", util.Key(svc)),
+	}
+}
+
+func getOptions(t *testing.T, svc *v1.Service) *Options {
+	opts, err := GetOptions(annotation.NewAnnotationRequest(svc))
+	assert.NoError(t, err)
+	return opts
+}
+
+func TestGetOptions(t *testing.T) {
+	assert.Equal(t, &Options{
+		Bandwidth:          DefaultBandwidth,
+		ISP:                model.EipISPBGP,
+		InternetChargeType: model.EipInternetChargeTypePayByTraffic,
+	}, getOptions(t, getService(nil)))
+
+	assert.Equal(t, &Options{
+		Bandwidth:          100,
+		ISP:                model.EipISPBGPPro,
+		InternetChargeType: model.EipInternetChargeTypePayByBandwidth,
+		BandwidthPackageId: "cbwp-1",
+	}, getOptions(t, getService(map[string]string{
+		annotation.EipBandwidth:          "100",
+		annotation.EipISP:                "BGP_PRO",
+		annotation.EipInternetChargeType: "paybybandwidth",
+		annotation.EipBandwidthPackageId: "cbwp-1",
+	})))
+
+	assert.Nil(t, getOptions(t, getService(map[string]string{annotation.AllocateEip: "off"})))
+
+	for _, anno := range []map[string]string{
+		{annotation.EipBandwidth: "0"},
+		{annotation.EipBandwidth: "501"},
+		{annotation.EipBandwidth: "abc"},
+		{annotation.EipISP: "CMCC"},
+		{annotation.EipInternetChargeType: "PrePaid"},
+	} {
+		_, err := GetOptions(annotation.NewAnnotationRequest(getService(anno)))
+		assert.Error(t, err, anno)
+	}
+}
+
+func TestGetCLBOptions(t *testing.T) {
+	anno := annotation.NewAnnotationRequest(getService(nil))
+	opts, err := GetCLBOptions(anno, model.IntranetAddressType, model.IPv4)
+	assert.NoError(t, err)
+	assert.NotNil(t, opts)
+
+	_, err = GetCLBOptions(anno, model.InternetAddressType, model.IPv4)
+	assert.Error(t, err)
+	_, err = GetCLBOptions(anno, model.IntranetAddressType, model.IPv6)
+	assert.Error(t, err)
+}
+
+func TestGetNLBOptions(t *testing.T) {
+	anno := annotation.NewAnnotationRequest(getService(nil))
+	mdl := &nlbmodel.NetworkLoadBalancer{LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{
+		ZoneMappings: []nlbmodel.ZoneMapping{{ZoneId: "cn-hangzhou-h", VSwitchId: "vsw-1"}},
+	}}
+	opts, err := GetNLBOptions(anno, mdl)
+	assert.NoError(t, err)
+	assert.NotNil(t, opts)
+
+	mdl.LoadBalancerAttribute.AddressType = nlbmodel.IntranetAddressType
+	_, err = GetNLBOptions(anno, mdl)
+	assert.Error(t, err)
+
+	mdl.LoadBalancerAttribute.AddressType = nlbmodel.InternetAddressType
+	mdl.LoadBalancerAttribute.ZoneMappings[0].AllocationId = "eip-1"
+	_, err = GetNLBOptions(anno, mdl)
+	assert.Error(t, err)
+}
+
+func TestSyncCLB(t *testing.T) {
+	cloud := snapshot.NewSnapshotCloud(&snapshot.Snapshot{})
+	mgr := NewManager(cloud)
+
+	svc := getService(nil)
+	reqCtx := getReqCtx(svc)
+	assert.NoError(t, mgr.SyncCLB(reqCtx, getOptions(t, svc), "lb-1"))
+	eips, err := mgr.List(reqCtx)
+	assert.NoError(t, err)
+	assert.Len(t, eips, 1)
+	assert.Equal(t, "k8s-default-eip", eips[0].Name)
+	assert.Equal(t, DefaultBandwidth, eips[0].Bandwidth)
+	assert.Equal(t, "lb-1", eips[0].InstanceId)
+	addresses, err := cloud.DescribeEipAddresses(reqCtx.Ctx, eips[0].InstanceType, "lb-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{eips[0].IpAddress}, addresses)
+	cloud.Operations()
+
+	// nothing changes if the eip is in sync
+	assert.NoError(t, mgr.SyncCLB(reqCtx, getOptions(t, svc), "lb-1"))
+	assert.Empty(t, cloud.Operations())
+
+	// the bandwidth is modified in place
+	svc = getService(map[string]string{annotation.EipBandwidth: "20"})
+	reqCtx = getReqCtx(svc)
+	assert.NoError(t, mgr.SyncCLB(reqCtx, getOptions(t, svc), "lb-1"))
+	assert.Len(t, cloud.Operations(), 1)
+	eips, err = mgr.List(reqCtx)
+	assert.NoError(t, err)
+	assert.Len(t, eips, 1)
+	assert.Equal(t, 20, eips[0].Bandwidth)
+
+	// the eip joins the bandwidth package, and its bandwidth is the one of the package
+	svc = getService(map[string]string{annotation.EipBandwidthPackageId: "cbwp-1"})
+	reqCtx = getReqCtx(svc)
+	assert.NoError(t, mgr.SyncCLB(reqCtx, getOptions(t, svc), "lb-1"))
+	eips, err = mgr.List(reqCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, "cbwp-1", eips[0].BandwidthPackageId)
+	assert.Equal(t, 20, eips[0].Bandwidth)
+
+	// the isp can not be changed
+	svc = getService(map[string]string{annotation.EipBandwidthPackageId: "cbwp-1", annotation.EipISP: "BGP_PRO"})
+	assert.Error(t, mgr.SyncCLB(getReqCtx(svc), getOptions(t, svc), "lb-1"))
+
+	// the eip leaves the package and is released with the service
+	assert.NoError(t, mgr.GarbageCollect(reqCtx, nil))
+	eips, err = mgr.List(reqCtx)
+	assert.NoError(t, err)
+	assert.Empty(t, eips)
+}
+
+func TestSyncZones(t *testing.T) {
+	cloud := snapshot.NewSnapshotCloud(&snapshot.Snapshot{})
+	mgr := NewManager(cloud)
+
+	svc := getService(nil)
+	reqCtx := getReqCtx(svc)
+	ids, err := mgr.SyncZones(reqCtx, getOptions(t, svc), []string{"cn-hangzhou-h", "cn-hangzhou-i"})
+	assert.NoError(t, err)
+	assert.Len(t, ids, 2)
+	assert.NotEqual(t, ids["cn-hangzhou-h"], ids["cn-hangzhou-i"])
+	cloud.Operations()
+
+	// the eips of the existing zones are reused, the new zone gets one
+	again, err := mgr.SyncZones(reqCtx, getOptions(t, svc), []string{"cn-hangzhou-h", "cn-hangzhou-j"})
+	assert.NoError(t, err)
+	assert.Equal(t, ids["cn-hangzhou-h"], again["cn-hangzhou-h"])
+	assert.NotEmpty(t, again["cn-hangzhou-j"])
+	assert.Len(t, cloud.Operations(), 1)
+
+	// the eips bound to the kept nlb are left
+	assert.NoError(t, mgr.GarbageCollect(reqCtx, map[string]bool{ids["cn-hangzhou-h"]: true}))
+	eips, err := mgr.List(reqCtx)
+	assert.NoError(t, err)
+	assert.Len(t, eips, 1)
+	assert.Equal(t, ids["cn-hangzhou-h"], eips[0].AllocationId)
+
+	// the eips are retained with the annotation
+	reqCtx = getReqCtx(getService(map[string]string{annotation.PreserveEipOnDelete: "true"}))
+	assert.NoError(t, mgr.GarbageCollect(reqCtx, nil))
+	eips, err = mgr.List(reqCtx)
+	assert.NoError(t, err)
+	assert.Len(t, eips, 1)
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"fmt"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+)
+
+// AccessControlList is a clb access control list, which is bound to the listeners.
+type AccessControlList struct {
+	AclId            string
+	AclName          string
+	AddressIPVersion string
+	Tags             []tag.Tag
+}
+
+// AclEntry is an entry of a clb access control list.
+type AclEntry struct {
+	Entry   string `json:"entry"`
+	Comment string `json:"comment,omitempty"`
+}
+
+const (
+	SecurityGroupPolicyAccept = "accept"
+	SecurityGroupPolicyDrop   = "drop"
+)
+
+// SecurityGroup is an ecs security group, which is joined by the network load balancers.
+type SecurityGroup struct {
+	SecurityGroupId   string
+	SecurityGroupName string
+	Description       string
+	VpcId             string
+	Tags              []tag.Tag
+}
+
+// SecurityGroupRule is an ingress rule of a security group.
+type SecurityGroupRule struct {
+	// RuleId is set for the rules of the remote security groups
+	RuleId       string
+	Policy       string
+	IpProtocol   string
+	PortRange    string
+	SourceCidrIp string
+	Priority     int
+	Description  string
+}
+
+// Key identifies the rule by its attributes, the rules with the same key are the same rule.
+func (r SecurityGroupRule) Key() string {
+	return fmt.Sprintf("%s/%s/%s/%s/%d", r.Policy, r.IpProtocol, r.PortRange, r.SourceCidrIp, r.Priority)
+}
@@ -46,6 +46,7 @@ type ALBListenerRuleSpec struct {
 	RuleId         string      `json:"RuleId" xml:"RuleId"`
 	RuleName       string      `json:"RuleName" xml:"RuleName"`
 	RuleStatus     string      `json:"RuleStatus" xml:"RuleStatus"`
+	Direction      string      `json:"Direction" xml:"Direction"`
 	RuleActions    []Action    `json:"RuleActions" xml:"RuleActions"`
 	RuleConditions []Condition `json:"RuleConditions" xml:"RuleConditions"`
 }
@@ -220,7 +221,8 @@ type MirrorGroupConfig struct {
 	ServerGroupTuples []ServerGroupTuple `json:"ServerGroupTuples" xml:"ServerGroupTuples"`
 }
 type TrafficLimitConfig struct {
-	QPS int `json:"QPS" xml:"QPS"`
+	QPS      int `json:"QPS" xml:"QPS"`
+	PerIpQps int `json:"PerIpQps" xml:"PerIpQps"`
 }
 type FixedResponseConfig struct {
 	Content     string `json:"Content" xml:"Content"`
@@ -55,5 +55,6 @@ type ListenerSpec struct {
 	ALBListenerSpec
 }
 type ListenerStatus struct {
-	ListenerID string `json:"listenerID"`
+	ListenerID     string `json:"listenerID"`
+	ListenerStatus string `json:"listenerStatus,omitempty"`
 }
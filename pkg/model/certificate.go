@@ -1,5 +1,7 @@
 package model
 
+import "k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+
 // CertificateInfo is a nested struct in cas response
 type CertificateInfo struct {
 	CommonName      string `json:"CommonName" xml:"CommonName"`
@@ -16,4 +18,6 @@ type CertificateInfo struct {
 	Md5             string `json:"Md5" xml:"Md5"`
 	SerialNo        string `json:"SerialNo" xml:"SerialNo"`
 	Sans            string `json:"Sans" xml:"Sans"`
+	// Tags are not returned by the list api, they are set by the simulated providers
+	Tags []tag.Tag `json:"Tags,omitempty" xml:"Tags"`
 }
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+)
+
+const (
+	EipInternetChargeTypePayByTraffic   = "PayByTraffic"
+	EipInternetChargeTypePayByBandwidth = "PayByBandwidth"
+
+	EipISPBGP    = "BGP"
+	EipISPBGPPro = "BGP_PRO"
+)
+
+// EipAddress is an elastic ip address, which is associated with an intranet clb,
+// or bound to a zone mapping of a network load balancer.
+type EipAddress struct {
+	AllocationId       string
+	IpAddress          string
+	Name               string
+	Description        string
+	Bandwidth          int
+	ISP                string
+	InternetChargeType string
+	// BandwidthPackageId is the common bandwidth package the eip joins
+	BandwidthPackageId string
+	// InstanceType and InstanceId are the instance the eip is associated with
+	InstanceType string
+	InstanceId   string
+	Tags         []tag.Tag
+}
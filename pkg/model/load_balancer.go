@@ -8,6 +8,7 @@ import (
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ListenerStatus string
@@ -112,6 +113,9 @@ type LoadBalancerAttribute struct {
 	Tags                         []tag.Tag
 	Address                      string
 	PreserveOnDelete             bool
+	// AclCidrs are the entries of the acl created for the cidrs declared by the service,
+	// which the listeners are bound to
+	AclCidrs []string
 
 	// parameters are immutable
 	RegionId                     string
@@ -218,13 +222,16 @@ type BackendAttribute struct {
 	Port        int    `json:"port"`
 	Type        string `json:"type"`
 	TargetRef   *v1.ObjectReference
+	// DrainDeadline is set for the terminating pods kept with weight 0 until the deregistration delay is over
+	DrainDeadline time.Time `json:"-"`
 }
 
 type CertAttribute struct {
-	CreateTimeStamp     int64
-	ExpireTimeStamp     int64
-	ServerCertificateId string
-	CommonName          string // The domain name of the certificate.
+	CreateTimeStamp       int64
+	ExpireTimeStamp       int64
+	ServerCertificateId   string
+	ServerCertificateName string
+	CommonName            string // The domain name of the certificate.
 }
 
 // DEFAULT_PREFIX default prefix for listener
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -114,20 +115,32 @@ func (l *NetworkLoadBalancer) GetLoadBalancerId() string {
 type LoadBalancerAttribute struct {
 	IsUserManaged bool
 
-	Name                         string
-	AddressType                  string
-	AddressIpVersion             string
-	IPv6AddressType              string
-	VpcId                        string
-	ZoneMappings                 []ZoneMapping
-	ResourceGroupId              string
-	Tags                         []tag.Tag
-	SecurityGroupIds             []string
+	Name             string
+	AddressType      string
+	AddressIpVersion string
+	IPv6AddressType  string
+	VpcId            string
+	ZoneMappings     []ZoneMapping
+	ResourceGroupId  string
+	Tags             []tag.Tag
+	SecurityGroupIds []string
+	// AclSecurityGroupId is the security group created for the acl cidrs of the service,
+	// which is joined along with SecurityGroupIds
+	AclSecurityGroupId string
+	// AclRules are the rules of the acl security group for the cidrs declared by the service
+	AclRules                     []model.SecurityGroupRule
 	BandwidthPackageId           *string
 	DeletionProtectionConfig     *DeletionProtectionConfig
 	ModificationProtectionConfig *ModificationProtectionConfig
 	PreserveOnDelete             bool
 
+	// SharedGroup is the group of the services sharing the nlb, SharedMembers are the other
+	// services of the group which still need it, the nlb is deleted with the last member.
+	// SharedOwner is the oldest service of the group, which creates and updates the nlb.
+	SharedGroup   string
+	SharedMembers []string
+	SharedOwner   string
+
 	// auto-generated parameters
 	LoadBalancerId             string
 	LoadBalancerStatus         string
@@ -201,7 +214,7 @@ type ServerGroup struct {
 	AddressIPVersion        string
 	Protocol                string
 	ConnectionDrainEnabled  *bool
-	ConnectionDrainTimeout  int32 // 10-900
+	ConnectionDrainTimeout  *int32 // 0-900
 	Scheduler               string
 	PreserveClientIpEnabled *bool
 	HealthCheckConfig       *HealthCheckConfig
@@ -241,6 +254,8 @@ type ServerGroupServer struct {
 	Status        string
 
 	TargetRef *v1.ObjectReference
+	// DrainDeadline is set for the terminating pods kept with weight 0 until the deregistration delay is over
+	DrainDeadline time.Time
 }
 
 type ZoneMapping struct {
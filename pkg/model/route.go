@@ -5,7 +5,11 @@ import v1 "k8s.io/api/core/v1"
 const (
 	RouteMaxQueryRouteEntry  = 500
 	RouteNextHopTypeInstance = "Instance"
-	RouteEntryTypeCustom     = "Custom"
+	// RouteNextHopTypeNetworkInterface is the next hop type of the aggregated routes through an eni
+	RouteNextHopTypeNetworkInterface = "NetworkInterface"
+	RouteEntryTypeCustom             = "Custom"
+	// RouteDescriptionAggregation is the description of the aggregated routes created by the route controller
+	RouteDescriptionAggregation = "kubernetes.io/route-aggregation"
 )
 
 // Route external route for node
@@ -14,4 +18,9 @@ type Route struct {
 	DestinationCIDR string
 	ProviderId      string
 	NodeReference   *v1.Node
+	// NextHopType is Instance if empty, and the next hop is the instance of ProviderId. For NetworkInterface,
+	// the next hop is the eni of NextHopId.
+	NextHopType string
+	NextHopId   string
+	Description string
 }
@@ -0,0 +1,119 @@
+package plan
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/apis"
+)
+
+// NewScheme returns the scheme of the objects the plan command reads from the manifests.
+func NewScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := apis.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+// LoadManifests reads the objects from yaml or json files, the directories are walked for
+// the files with .yaml, .yml or .json extension. A file may contain several documents or a List.
+func LoadManifests(scheme *runtime.Scheme, paths []string) ([]runtime.Object, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("read manifests %s: %s", path, err.Error())
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.Walk(path, func(p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(filepath.Ext(p)) {
+			case ".yaml", ".yml", ".json":
+				if !fi.IsDir() {
+					files = append(files, p)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("read manifests %s: %s", path, err.Error())
+		}
+	}
+	sort.Strings(files)
+
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	var objs []runtime.Object
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read manifest %s: %s", file, err.Error())
+		}
+		fileObjs, err := decodeManifest(decoder, data)
+		if err != nil {
+			return nil, fmt.Errorf("parse manifest %s: %s", file, err.Error())
+		}
+		objs = append(objs, fileObjs...)
+	}
+	return objs, nil
+}
+
+func decodeManifest(decoder runtime.Decoder, data []byte) ([]runtime.Object, error) {
+	var objs []runtime.Object
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		// a document of comments only is decoded to null
+		jsonDoc, err := utilyaml.ToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		if string(bytes.TrimSpace(jsonDoc)) == "null" {
+			continue
+		}
+		obj, _, err := decoder.Decode(jsonDoc, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := obj.(*corev1.List)
+		if !ok {
+			objs = append(objs, obj)
+			continue
+		}
+		for _, item := range list.Items {
+			itemObj, _, err := decoder.Decode(item.Raw, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, itemObj)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/applier"
+	albconfigmanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/clbv1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/nlbv2"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	KindCLB       = "CLB"
+	KindNLB       = "NLB"
+	KindAlbConfig = "AlbConfig"
+)
+
+// Result is the plan of one object of the manifests.
+type Result struct {
+	Kind       string               `json:"kind"`
+	Namespace  string               `json:"namespace,omitempty"`
+	Name       string               `json:"name"`
+	Operations []snapshot.Operation `json:"operations"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// Planner runs the model builders and appliers of the controllers against a snapshot of the
+// cloud resources, and records the operations they would make.
+type Planner struct {
+	kubeClient client.Client
+	cloud      *snapshot.SnapshotCloud
+	logger     logr.Logger
+	recorder   record.EventRecorder
+}
+
+// NewPlanner returns a planner working on the objects of the manifests and a copy of the snapshot.
+// The cluster settings of the global cloud config are taken from the snapshot.
+func NewPlanner(scheme *runtime.Scheme, objs []runtime.Object, snap *snapshot.Snapshot, logger logr.Logger) *Planner {
+	ctrlCfg.CloudCFG.Global.ClusterID = snap.ClusterID
+	ctrlCfg.CloudCFG.Global.Region = snap.Region
+	ctrlCfg.CloudCFG.Global.VpcID = snap.VpcID
+	ctrlCfg.CloudCFG.Global.VswitchID = snap.VswitchID
+	ctrlCfg.CloudCFG.Global.ZoneID = snap.ZoneID
+	if snap.ClusterID != "" {
+		base.CLUSTER_ID = snap.ClusterID
+	}
+	return &Planner{
+		kubeClient: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		cloud:      snapshot.NewSnapshotCloud(snap),
+		logger:     logger,
+		// events are dropped, the operations and errors are reported in the results
+		recorder: &record.FakeRecorder{},
+	}
+}
+
+// Plan returns the results of the Services and then the AlbConfigs, in the order of their names.
+// Objects that do not need a load balancer are left out.
+func (p *Planner) Plan(ctx context.Context) ([]Result, error) {
+	svcList := &v1.ServiceList{}
+	if err := p.kubeClient.List(ctx, svcList); err != nil {
+		return nil, fmt.Errorf("list services: %s", err.Error())
+	}
+	svcs := svcList.Items
+	sort.Slice(svcs, func(i, j int) bool {
+		return util.Key(&svcs[i]) < util.Key(&svcs[j])
+	})
+
+	var results []Result
+	for i := range svcs {
+		svc := &svcs[i]
+		// the controllers only clean up the load balancers of the services with their finalizers
+		if (helper.NeedCLB(svc) && svc.DeletionTimestamp == nil) || helper.HasFinalizer(svc, helper.ServiceFinalizer) {
+			results = append(results, p.result(KindCLB, svc.Namespace, svc.Name, func() error { return p.planCLB(ctx, svc) }))
+		}
+		if (helper.NeedNLB(svc) && svc.DeletionTimestamp == nil) || helper.HasFinalizer(svc, helper.NLBFinalizer) {
+			results = append(results, p.result(KindNLB, svc.Namespace, svc.Name, func() error { return p.planNLB(ctx, svc) }))
+		}
+	}
+
+	acList := &albv1.AlbConfigList{}
+	if err := p.kubeClient.List(ctx, acList); err != nil {
+		return nil, fmt.Errorf("list albconfigs: %s", err.Error())
+	}
+	acs := acList.Items
+	sort.Slice(acs, func(i, j int) bool {
+		return util.NamespacedName(&acs[i]).String() < util.NamespacedName(&acs[j]).String()
+	})
+	for i := range acs {
+		if !acs[i].DeletionTimestamp.IsZero() && !helper.HasFinalizer(&acs[i], albconfigmanager.GetIngressFinalizer()) {
+			continue
+		}
+		results = append(results, p.result(KindAlbConfig, acs[i].Namespace, acs[i].Name, func() error { return p.planAlbConfig(ctx, &acs[i]) }))
+	}
+	return results, nil
+}
+
+// result runs the plan of an object and collects the operations it recorded.
+// The builders expect the defaults set by the apiserver, a panic on an incomplete manifest
+// is reported as the error of the object.
+func (p *Planner) result(kind, namespace, name string, plan func() error) Result {
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return plan()
+	}()
+	r := Result{
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+		Operations: p.cloud.Operations(),
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// requestContext returns the context the service controllers reconcile the service with.
+// The hash label is removed so that the appliers compare all the attributes with the snapshot.
+func (p *Planner) requestContext(ctx context.Context, svc *v1.Service, kind string) (*svcCtx.RequestContext, error) {
+	svc = svc.DeepCopy()
+	delete(svc.Labels, helper.LabelServiceHash)
+	if svc.UID == "" {
+		svc.UID = types.UID(fmt.Sprintf("plan-%s-%s", svc.Namespace, svc.Name))
+	}
+	anno, err := annotation.NewAnnotationRequestWithConfig(ctx, p.kubeClient, svc)
+	if err != nil {
+		return nil, err
+	}
+	return &svcCtx.RequestContext{
+		Ctx:         ctx,
+		ReconcileID: "plan",
+		Service:     svc,
+		Anno:        anno,
+		Log:         p.logger.WithValues("kind", kind, "service", util.Key(svc)),
+		Recorder:    p.recorder,
+	}, nil
+}
+
+func (p *Planner) planCLB(ctx context.Context, svc *v1.Service) error {
+	slbManager := clbv1.NewLoadBalancerManager(p.cloud)
+	listenerManager := clbv1.NewListenerManager(p.kubeClient, p.cloud)
+	vGroupManager, err := clbv1.NewVGroupManager(p.kubeClient, p.cloud)
+	if err != nil {
+		return err
+	}
+	builder := clbv1.NewModelBuilder(slbManager, listenerManager, vGroupManager)
+	modelApplier := clbv1.NewModelApplier(slbManager, listenerManager, vGroupManager)
+
+	reqCtx, err := p.requestContext(ctx, svc, KindCLB)
+	if err != nil {
+		return err
+	}
+	localModel, err := builder.BuildModel(reqCtx, clbv1.LocalModel)
+	if err != nil {
+		return fmt.Errorf("build lb local model error: %s", err.Error())
+	}
+	if _, err := modelApplier.Apply(reqCtx, localModel); err != nil {
+		return fmt.Errorf("apply model error: %s", err.Error())
+	}
+	return nil
+}
+
+func (p *Planner) planNLB(ctx context.Context, svc *v1.Service) error {
+	nlbManager := nlbv2.NewNLBManager(p.cloud)
+	listenerManager := nlbv2.NewListenerManager(p.kubeClient, p.cloud)
+	serverGroupManager, err := nlbv2.NewServerGroupManager(p.kubeClient, p.cloud)
+	if err != nil {
+		return err
+	}
+	builder := nlbv2.NewModelBuilder(nlbManager, listenerManager, serverGroupManager)
+	modelApplier := nlbv2.NewModelApplier(nlbManager, listenerManager, serverGroupManager)
+
+	reqCtx, err := p.requestContext(ctx, svc, KindNLB)
+	if err != nil {
+		return err
+	}
+	localModel, err := builder.BuildModel(reqCtx, nlbv2.LocalModel)
+	if err != nil {
+		return fmt.Errorf("build lb local model error: %s", err.Error())
+	}
+	if _, err := modelApplier.Apply(reqCtx, localModel); err != nil {
+		return fmt.Errorf("apply model error: %s", err.Error())
+	}
+	return nil
+}
+
+func (p *Planner) planAlbConfig(ctx context.Context, albconfig *albv1.AlbConfig) error {
+	ctx = context.WithValue(ctx, util.TraceID, "plan")
+	ingStore := &clientStore{kubeClient: p.kubeClient}
+	groupLoader := albconfigmanager.NewDefaultGroupLoader(p.kubeClient, annotations.NewSuffixAnnotationParser(annotations.DefaultAnnotationsPrefix))
+	ingGroup, err := groupLoader.Load(ctx, albconfigmanager.GroupID(util.NamespacedName(albconfig)), ingStore.ListIngresses())
+	if err != nil {
+		return err
+	}
+
+	logger := p.logger.WithValues("kind", KindAlbConfig, "albconfig", util.NamespacedName(albconfig).String())
+	stack, _, err := albconfigmanager.NewDefaultAlbConfigManagerBuilder(p.kubeClient, p.cloud, logger).Build(ctx, albconfig, ingGroup)
+	if err != nil {
+		return err
+	}
+	return applier.NewAlbConfigManagerApplier(ingStore, p.kubeClient, p.cloud, util.IngressTagKeyPrefix, logger).Apply(ctx, stack)
+}
+
+// HasErrors returns true if the plan of any object failed.
+func HasErrors(results []Result) bool {
+	for _, r := range results {
+		if r.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintText prints the operations of each object, followed by the count of each action.
+func PrintText(w io.Writer, results []Result) {
+	count := map[string]int{}
+	for _, r := range results {
+		target := r.Name
+		if r.Namespace != "" {
+			target = r.Namespace + "/" + r.Name
+		}
+		switch {
+		case r.Error != "":
+			fmt.Fprintf(w, "%s %s: error: %s\n", r.Kind, target, r.Error)
+		case len(r.Operations) == 0:
+			fmt.Fprintf(w, "%s %s: no changes\n", r.Kind, target)
+		default:
+			fmt.Fprintf(w, "%s %s:\n", r.Kind, target)
+		}
+		for _, op := range r.Operations {
+			count[op.Action]++
+			fmt.Fprintf(w, "  %s %s\n", actionSymbol(op.Action), op.String())
+			for _, c := range op.Changes {
+				fmt.Fprintf(w, "      %s\n", c)
+			}
+		}
+	}
+	fmt.Fprintf(w, "\nPlan: %d to create, %d to update, %d to delete.\n",
+		count[snapshot.ActionCreate], count[snapshot.ActionUpdate], count[snapshot.ActionDelete])
+}
+
+// PrintJSON prints the results as a json array.
+func PrintJSON(w io.Writer, results []Result) error {
+	if results == nil {
+		results = []Result{}
+	}
+	for i := range results {
+		if results[i].Operations == nil {
+			results[i].Operations = []snapshot.Operation{}
+		}
+	}
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func actionSymbol(action string) string {
+	switch action {
+	case snapshot.ActionCreate:
+		return "+"
+	case snapshot.ActionDelete:
+		return "-"
+	default:
+		return "~"
+	}
+}
@@ -0,0 +1,121 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const manifests = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  namespace: default
+  annotations:
+    service.beta.kubernetes.io/alibaba-cloud-loadbalancer-spec: slb.s1.small
+spec:
+  type: LoadBalancer
+  selector:
+    app: nginx
+  ports:
+  - name: http
+    port: 80
+    targetPort: 80
+    nodePort: 30080
+    protocol: TCP
+---
+# the backends of the service
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: cn-hangzhou.192.168.0.1
+  spec:
+    providerID: cn-hangzhou.ecs-id-1
+  status:
+    conditions:
+    - type: Ready
+      status: "True"
+- apiVersion: v1
+  kind: Endpoints
+  metadata:
+    name: nginx
+    namespace: default
+  subsets:
+  - addresses:
+    - ip: 10.0.0.5
+      nodeName: cn-hangzhou.192.168.0.1
+    ports:
+    - name: http
+      port: 80
+      protocol: TCP
+`
+
+func getPlanner(t *testing.T) *Planner {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "nginx.yaml"), []byte(manifests), 0644))
+	scheme, err := NewScheme()
+	assert.Nil(t, err)
+	objs, err := LoadManifests(scheme, []string{dir})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(objs))
+
+	snap := &snapshot.Snapshot{Region: "cn-hangzhou", VpcID: "vpc-id", ClusterID: "cluster-id"}
+	return NewPlanner(scheme, objs, snap, ctrl.Log.WithName("plan"))
+}
+
+func TestPlanner_Plan(t *testing.T) {
+	p := getPlanner(t)
+	// the backends are read from the endpoints in the manifests
+	assert.Nil(t, utilfeature.DefaultMutableFeatureGate.Set("EndpointSlice=false"))
+	defer func() {
+		_ = utilfeature.DefaultMutableFeatureGate.Set("EndpointSlice=true")
+	}()
+
+	results, err := p.Plan(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, KindCLB, results[0].Kind)
+	assert.Equal(t, "", results[0].Error)
+	var resources []string
+	for _, op := range results[0].Operations {
+		assert.Equal(t, snapshot.ActionCreate, op.Action)
+		resources = append(resources, op.Resource)
+	}
+	assert.Equal(t, []string{snapshot.ResourceCLB, snapshot.ResourceCLBVServerGroup, snapshot.ResourceCLBListener}, resources)
+
+	// the created resources are applied to the snapshot, planning again makes no changes
+	results, err = p.Plan(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(results[0].Operations))
+
+	svc := &v1.Service{}
+	assert.Nil(t, p.kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: v1.NamespaceDefault, Name: "nginx"}, svc))
+	svc.Annotations[annotation.Annotation(annotation.Spec)] = "slb.s2.small"
+	assert.Nil(t, p.kubeClient.Update(context.TODO(), svc))
+	results, err = p.Plan(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results[0].Operations))
+	op := results[0].Operations[0]
+	assert.Equal(t, snapshot.ActionUpdate, op.Action)
+	assert.Equal(t, snapshot.ResourceCLB, op.Resource)
+	assert.Equal(t, []string{`LoadBalancerSpec: "slb.s1.small" -> "slb.s2.small"`}, op.Changes)
+
+	buf := &bytes.Buffer{}
+	PrintText(buf, results)
+	assert.Contains(t, buf.String(), "Plan: 0 to create, 1 to update, 0 to delete.")
+}
@@ -0,0 +1,79 @@
+package plan
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/store"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ store.Storer = &clientStore{}
+
+// clientStore serves the ingress store from the objects of the manifests instead of the informers.
+type clientStore struct {
+	kubeClient client.Client
+}
+
+func (s *clientStore) get(key string, obj client.Object) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	err = s.kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, obj)
+	if apierrors.IsNotFound(err) {
+		return store.NotExistsError(key)
+	}
+	return err
+}
+
+func (s *clientStore) GetService(key string) (*corev1.Service, error) {
+	svc := &corev1.Service{}
+	if err := s.get(key, svc); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+func (s *clientStore) GetServiceEndpoints(key string) (*corev1.Endpoints, error) {
+	eps := &corev1.Endpoints{}
+	if err := s.get(key, eps); err != nil {
+		return nil, err
+	}
+	return eps, nil
+}
+
+func (s *clientStore) GetPod(key string) (*corev1.Pod, error) {
+	pod := &corev1.Pod{}
+	if err := s.get(key, pod); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+func (s *clientStore) ListIngresses() []*store.Ingress {
+	ingList := &networking.IngressList{}
+	if err := s.kubeClient.List(context.TODO(), ingList); err != nil {
+		return nil
+	}
+	ingresses := make([]*store.Ingress, 0)
+	for _, ing := range ingList.Items {
+		if store.IsValid(&ing) {
+			ingresses = append(ingresses, &store.Ingress{Ingress: ing})
+		}
+	}
+	sort.SliceStable(ingresses, func(i, j int) bool {
+		if ingresses[i].Namespace != ingresses[j].Namespace {
+			return ingresses[i].Namespace < ingresses[j].Namespace
+		}
+		return ingresses[i].Name < ingresses[j].Name
+	})
+	return ingresses
+}
+
+func (s *clientStore) Run(stopCh chan struct{}) {}
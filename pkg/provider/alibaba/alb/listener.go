@@ -105,7 +105,11 @@ func (m *ALBProvider) CreateALBListener(ctx context.Context, resLS *albmodel.Lis
 			return albmodel.ListenerStatus{}, errors.Wrap(err, "failed to update listener extra certificates")
 		}
 	}
-	return buildResListenerStatus(createLsResp.ListenerId), nil
+	var lsStatus string
+	if getLsResp != nil {
+		lsStatus = getLsResp.ListenerStatus
+	}
+	return buildResListenerStatus(createLsResp.ListenerId, lsStatus), nil
 }
 
 func isListenerListenerStatusRunning(status string) bool {
@@ -198,7 +202,7 @@ func (m *ALBProvider) UpdateALBListener(ctx context.Context, resLS *albmodel.Lis
 		}
 	}
 
-	return buildResListenerStatus(sdkLS.ListenerId), nil
+	return buildResListenerStatus(sdkLS.ListenerId, sdkLS.ListenerStatus), nil
 }
 
 func (m *ALBProvider) DeleteALBListener(ctx context.Context, sdkLSId string) error {
@@ -971,8 +975,9 @@ func isListenerIdleTimeoutValid(idleTimeout int) bool {
 	return true
 }
 
-func buildResListenerStatus(lsID string) albmodel.ListenerStatus {
+func buildResListenerStatus(lsID, lsStatus string) albmodel.ListenerStatus {
 	return albmodel.ListenerStatus{
-		ListenerID: lsID,
+		ListenerID:     lsID,
+		ListenerStatus: lsStatus,
 	}
 }
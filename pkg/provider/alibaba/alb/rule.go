@@ -123,40 +123,44 @@ func (m *ALBProvider) ListALBListenerRules(ctx context.Context, lsID string) ([]
 		return nil, fmt.Errorf("invalid listener id: %s for listing rules", lsID)
 	}
 
-	var (
-		nextToken string
-		rules     []albsdk.Rule
-	)
-
-	listRuleReq := albsdk.CreateListRulesRequest()
-	listRuleReq.ListenerId = lsID
-
-	for {
-		listRuleReq.NextToken = nextToken
-
-		startTime := time.Now()
-		m.logger.V(util.MgrLogLevel).Info("listing rules",
-			"listenerID", lsID,
-			"traceID", traceID,
-			"startTime", startTime,
-			util.Action, util.ListALBRules)
-		listRuleResp, err := m.auth.ALB.ListRules(listRuleReq)
-		if err != nil {
-			return nil, err
-		}
-		m.logger.V(util.MgrLogLevel).Info("listed rules",
-			"listenerID", lsID,
-			"traceID", traceID,
-			"requestID", listRuleResp.RequestId,
-			"elapsedTime", time.Since(startTime).Milliseconds(),
-			util.Action, util.ListALBRules)
+	var rules []albsdk.Rule
+
+	// response rules are only listed when asked for explicitly
+	for _, direction := range []string{util.RuleDirectionRequest, util.RuleDirectionResponse} {
+		listRuleReq := albsdk.CreateListRulesRequest()
+		listRuleReq.ListenerId = lsID
+		listRuleReq.Direction = direction
+
+		var nextToken string
+		for {
+			listRuleReq.NextToken = nextToken
+
+			startTime := time.Now()
+			m.logger.V(util.MgrLogLevel).Info("listing rules",
+				"listenerID", lsID,
+				"direction", direction,
+				"traceID", traceID,
+				"startTime", startTime,
+				util.Action, util.ListALBRules)
+			listRuleResp, err := m.auth.ALB.ListRules(listRuleReq)
+			if err != nil {
+				return nil, err
+			}
+			m.logger.V(util.MgrLogLevel).Info("listed rules",
+				"listenerID", lsID,
+				"direction", direction,
+				"traceID", traceID,
+				"requestID", listRuleResp.RequestId,
+				"elapsedTime", time.Since(startTime).Milliseconds(),
+				util.Action, util.ListALBRules)
 
-		rules = append(rules, listRuleResp.Rules...)
+			rules = append(rules, listRuleResp.Rules...)
 
-		if listRuleResp.NextToken == "" {
-			break
-		} else {
-			nextToken = listRuleResp.NextToken
+			if listRuleResp.NextToken == "" {
+				break
+			} else {
+				nextToken = listRuleResp.NextToken
+			}
 		}
 	}
 
@@ -480,6 +484,213 @@ func transModelForwardActionConfigToSDKRule(forwardActionConf alb.ForwardActionC
 	return r, nil
 }
 
+func transSDKInsertHeaderConfigToCreateRules(insertHeaderConf alb.InsertHeaderConfig) albsdk.CreateRulesRulesRuleActionsItemInsertHeaderConfig {
+	return albsdk.CreateRulesRulesRuleActionsItemInsertHeaderConfig{
+		Key:          insertHeaderConf.Key,
+		Value:        insertHeaderConf.Value,
+		ValueType:    insertHeaderConf.ValueType,
+		CoverEnabled: strconv.FormatBool(insertHeaderConf.CoverEnabled),
+	}
+}
+func transSDKInsertHeaderConfigToUpdateRules(insertHeaderConf alb.InsertHeaderConfig) albsdk.UpdateRulesAttributeRulesRuleActionsItemInsertHeaderConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleActionsItemInsertHeaderConfig{
+		Key:          insertHeaderConf.Key,
+		Value:        insertHeaderConf.Value,
+		ValueType:    insertHeaderConf.ValueType,
+		CoverEnabled: strconv.FormatBool(insertHeaderConf.CoverEnabled),
+	}
+}
+func transSDKInsertHeaderConfigToCreateRule(insertHeaderConf alb.InsertHeaderConfig) albsdk.CreateRuleRuleActionsInsertHeaderConfig {
+	return albsdk.CreateRuleRuleActionsInsertHeaderConfig{
+		Key:          insertHeaderConf.Key,
+		Value:        insertHeaderConf.Value,
+		ValueType:    insertHeaderConf.ValueType,
+		CoverEnabled: strconv.FormatBool(insertHeaderConf.CoverEnabled),
+	}
+}
+func transSDKInsertHeaderConfigToUpdateRule(insertHeaderConf alb.InsertHeaderConfig) albsdk.UpdateRuleAttributeRuleActionsInsertHeaderConfig {
+	return albsdk.UpdateRuleAttributeRuleActionsInsertHeaderConfig{
+		Key:          insertHeaderConf.Key,
+		Value:        insertHeaderConf.Value,
+		ValueType:    insertHeaderConf.ValueType,
+		CoverEnabled: strconv.FormatBool(insertHeaderConf.CoverEnabled),
+	}
+}
+func transSDKRemoveHeaderConfigToCreateRules(removeHeaderConf alb.RemoveHeaderConfig) albsdk.CreateRulesRulesRuleActionsItemRemoveHeaderConfig {
+	return albsdk.CreateRulesRulesRuleActionsItemRemoveHeaderConfig{
+		Key: removeHeaderConf.Key,
+	}
+}
+func transSDKRemoveHeaderConfigToUpdateRules(removeHeaderConf alb.RemoveHeaderConfig) albsdk.UpdateRulesAttributeRulesRuleActionsItemRemoveHeaderConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleActionsItemRemoveHeaderConfig{
+		Key: removeHeaderConf.Key,
+	}
+}
+func transSDKRemoveHeaderConfigToCreateRule(removeHeaderConf alb.RemoveHeaderConfig) albsdk.CreateRuleRuleActionsRemoveHeaderConfig {
+	return albsdk.CreateRuleRuleActionsRemoveHeaderConfig{
+		Key: removeHeaderConf.Key,
+	}
+}
+func transSDKRemoveHeaderConfigToUpdateRule(removeHeaderConf alb.RemoveHeaderConfig) albsdk.UpdateRuleAttributeRuleActionsRemoveHeaderConfig {
+	return albsdk.UpdateRuleAttributeRuleActionsRemoveHeaderConfig{
+		Key: removeHeaderConf.Key,
+	}
+}
+func transSDKRewriteConfigToCreateRules(rewriteConf alb.RewriteConfig) albsdk.CreateRulesRulesRuleActionsItemRewriteConfig {
+	return albsdk.CreateRulesRulesRuleActionsItemRewriteConfig{
+		Host:  rewriteConf.Host,
+		Path:  rewriteConf.Path,
+		Query: rewriteConf.Query,
+	}
+}
+func transSDKRewriteConfigToUpdateRules(rewriteConf alb.RewriteConfig) albsdk.UpdateRulesAttributeRulesRuleActionsItemRewriteConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleActionsItemRewriteConfig{
+		Host:  rewriteConf.Host,
+		Path:  rewriteConf.Path,
+		Query: rewriteConf.Query,
+	}
+}
+func transSDKRewriteConfigToCreateRule(rewriteConf alb.RewriteConfig) albsdk.CreateRuleRuleActionsRewriteConfig {
+	return albsdk.CreateRuleRuleActionsRewriteConfig{
+		Host:  rewriteConf.Host,
+		Path:  rewriteConf.Path,
+		Query: rewriteConf.Query,
+	}
+}
+func transSDKRewriteConfigToUpdateRule(rewriteConf alb.RewriteConfig) albsdk.UpdateRuleAttributeRuleActionsRewriteConfig {
+	return albsdk.UpdateRuleAttributeRuleActionsRewriteConfig{
+		Host:  rewriteConf.Host,
+		Path:  rewriteConf.Path,
+		Query: rewriteConf.Query,
+	}
+}
+func transSDKTrafficLimitConfigToCreateRules(trafficLimitConf alb.TrafficLimitConfig) albsdk.CreateRulesRulesRuleActionsItemTrafficLimitConfig {
+	r := albsdk.CreateRulesRulesRuleActionsItemTrafficLimitConfig{
+		QPS: strconv.Itoa(trafficLimitConf.QPS),
+	}
+	if trafficLimitConf.PerIpQps > 0 {
+		r.PerIpQps = strconv.Itoa(trafficLimitConf.PerIpQps)
+	}
+	return r
+}
+func transSDKTrafficLimitConfigToUpdateRules(trafficLimitConf alb.TrafficLimitConfig) albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficLimitConfig {
+	r := albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficLimitConfig{
+		QPS: strconv.Itoa(trafficLimitConf.QPS),
+	}
+	if trafficLimitConf.PerIpQps > 0 {
+		r.PerIpQps = strconv.Itoa(trafficLimitConf.PerIpQps)
+	}
+	return r
+}
+func transSDKTrafficLimitConfigToCreateRule(trafficLimitConf alb.TrafficLimitConfig) albsdk.CreateRuleRuleActionsTrafficLimitConfig {
+	r := albsdk.CreateRuleRuleActionsTrafficLimitConfig{
+		QPS: strconv.Itoa(trafficLimitConf.QPS),
+	}
+	if trafficLimitConf.PerIpQps > 0 {
+		r.PerIpQps = strconv.Itoa(trafficLimitConf.PerIpQps)
+	}
+	return r
+}
+func transSDKTrafficLimitConfigToUpdateRule(trafficLimitConf alb.TrafficLimitConfig) albsdk.UpdateRuleAttributeRuleActionsTrafficLimitConfig {
+	r := albsdk.UpdateRuleAttributeRuleActionsTrafficLimitConfig{
+		QPS: strconv.Itoa(trafficLimitConf.QPS),
+	}
+	if trafficLimitConf.PerIpQps > 0 {
+		r.PerIpQps = strconv.Itoa(trafficLimitConf.PerIpQps)
+	}
+	return r
+}
+func transModelTrafficMirrorConfigToSDKCreateRules(trafficMirrorConf alb.TrafficMirrorConfig) (*albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfig, error) {
+	var sdkSGPs []albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem
+	for _, m := range trafficMirrorConf.MirrorGroupConfig.ServerGroupTuples {
+		serverGroupID, err := m.ServerGroupID.Resolve(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		sdkSGPs = append(sdkSGPs, albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem{
+			ServerGroupId: serverGroupID,
+		})
+	}
+	return &albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfig{
+		TargetType: trafficMirrorConf.TargetType,
+		MirrorGroupConfig: albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfig{
+			ServerGroupTuples: &sdkSGPs,
+		},
+	}, nil
+}
+func transModelTrafficMirrorConfigToSDKUpdateRules(trafficMirrorConf alb.TrafficMirrorConfig) (*albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficMirrorConfig, error) {
+	var sdkSGPs []albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem
+	for _, m := range trafficMirrorConf.MirrorGroupConfig.ServerGroupTuples {
+		serverGroupID, err := m.ServerGroupID.Resolve(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		sdkSGPs = append(sdkSGPs, albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem{
+			ServerGroupId: serverGroupID,
+		})
+	}
+	return &albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficMirrorConfig{
+		TargetType: trafficMirrorConf.TargetType,
+		MirrorGroupConfig: albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfig{
+			ServerGroupTuples: &sdkSGPs,
+		},
+	}, nil
+}
+func transModelTrafficMirrorConfigToSDKCreateRule(trafficMirrorConf alb.TrafficMirrorConfig) (*albsdk.CreateRuleRuleActionsTrafficMirrorConfig, error) {
+	var sdkSGPs []albsdk.CreateRuleRuleActionsTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem
+	for _, m := range trafficMirrorConf.MirrorGroupConfig.ServerGroupTuples {
+		serverGroupID, err := m.ServerGroupID.Resolve(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		sdkSGPs = append(sdkSGPs, albsdk.CreateRuleRuleActionsTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem{
+			ServerGroupId: serverGroupID,
+		})
+	}
+	return &albsdk.CreateRuleRuleActionsTrafficMirrorConfig{
+		TargetType: trafficMirrorConf.TargetType,
+		MirrorGroupConfig: albsdk.CreateRuleRuleActionsTrafficMirrorConfigMirrorGroupConfig{
+			ServerGroupTuples: &sdkSGPs,
+		},
+	}, nil
+}
+func transModelTrafficMirrorConfigToSDKUpdateRule(trafficMirrorConf alb.TrafficMirrorConfig) (*albsdk.UpdateRuleAttributeRuleActionsTrafficMirrorConfig, error) {
+	var sdkSGPs []albsdk.UpdateRuleAttributeRuleActionsTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem
+	for _, m := range trafficMirrorConf.MirrorGroupConfig.ServerGroupTuples {
+		serverGroupID, err := m.ServerGroupID.Resolve(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		sdkSGPs = append(sdkSGPs, albsdk.UpdateRuleAttributeRuleActionsTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem{
+			ServerGroupId: serverGroupID,
+		})
+	}
+	return &albsdk.UpdateRuleAttributeRuleActionsTrafficMirrorConfig{
+		TargetType: trafficMirrorConf.TargetType,
+		MirrorGroupConfig: albsdk.UpdateRuleAttributeRuleActionsTrafficMirrorConfigMirrorGroupConfig{
+			ServerGroupTuples: &sdkSGPs,
+		},
+	}, nil
+}
+func transModelTrafficMirrorConfigToSDKRule(trafficMirrorConf alb.TrafficMirrorConfig) (*albsdk.TrafficMirrorConfig, error) {
+	var sdkSGPs []albsdk.ServerGroupTuple
+	for _, m := range trafficMirrorConf.MirrorGroupConfig.ServerGroupTuples {
+		serverGroupID, err := m.ServerGroupID.Resolve(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		sdkSGPs = append(sdkSGPs, albsdk.ServerGroupTuple{
+			ServerGroupId: serverGroupID,
+		})
+	}
+	return &albsdk.TrafficMirrorConfig{
+		TargetType: trafficMirrorConf.TargetType,
+		MirrorGroupConfig: albsdk.MirrorGroupConfig{
+			ServerGroupTuples: sdkSGPs,
+		},
+	}, nil
+}
+
 func transModelActionToSDKCreateRules(action alb.Action) (*albsdk.CreateRulesRulesRuleActionsItem, error) {
 	sdkObj := &albsdk.CreateRulesRulesRuleActionsItem{}
 	sdkObj.Type = action.Type
@@ -495,6 +706,20 @@ func transModelActionToSDKCreateRules(action alb.Action) (*albsdk.CreateRulesRul
 			return nil, err
 		}
 		sdkObj.ForwardGroupConfig = *forwardActionConfig
+	case util.RuleActionTypeInsertHeader:
+		sdkObj.InsertHeaderConfig = transSDKInsertHeaderConfigToCreateRules(*action.InsertHeaderConfig)
+	case util.RuleActionTypeRemoveHeader:
+		sdkObj.RemoveHeaderConfig = transSDKRemoveHeaderConfigToCreateRules(*action.RemoveHeaderConfig)
+	case util.RuleActionTypeRewrite:
+		sdkObj.RewriteConfig = transSDKRewriteConfigToCreateRules(*action.RewriteConfig)
+	case util.RuleActionTypeTrafficLimit:
+		sdkObj.TrafficLimitConfig = transSDKTrafficLimitConfigToCreateRules(*action.TrafficLimitConfig)
+	case util.RuleActionTypeTrafficMirror:
+		trafficMirrorConfig, err := transModelTrafficMirrorConfigToSDKCreateRules(*action.TrafficMirrorConfig)
+		if err != nil {
+			return nil, err
+		}
+		sdkObj.TrafficMirrorConfig = *trafficMirrorConfig
 	}
 	return sdkObj, nil
 }
@@ -513,6 +738,20 @@ func transModelActionToSDKUpdateRules(action alb.Action) (*albsdk.UpdateRulesAtt
 			return nil, err
 		}
 		sdkObj.ForwardGroupConfig = *forwardActionConfig
+	case util.RuleActionTypeInsertHeader:
+		sdkObj.InsertHeaderConfig = transSDKInsertHeaderConfigToUpdateRules(*action.InsertHeaderConfig)
+	case util.RuleActionTypeRemoveHeader:
+		sdkObj.RemoveHeaderConfig = transSDKRemoveHeaderConfigToUpdateRules(*action.RemoveHeaderConfig)
+	case util.RuleActionTypeRewrite:
+		sdkObj.RewriteConfig = transSDKRewriteConfigToUpdateRules(*action.RewriteConfig)
+	case util.RuleActionTypeTrafficLimit:
+		sdkObj.TrafficLimitConfig = transSDKTrafficLimitConfigToUpdateRules(*action.TrafficLimitConfig)
+	case util.RuleActionTypeTrafficMirror:
+		trafficMirrorConfig, err := transModelTrafficMirrorConfigToSDKUpdateRules(*action.TrafficMirrorConfig)
+		if err != nil {
+			return nil, err
+		}
+		sdkObj.TrafficMirrorConfig = *trafficMirrorConfig
 	}
 	return sdkObj, nil
 }
@@ -531,6 +770,20 @@ func transModelActionToSDKCreateRule(action alb.Action) (*albsdk.CreateRuleRuleA
 			return nil, err
 		}
 		sdkObj.ForwardGroupConfig = *forwardActionConfig
+	case util.RuleActionTypeInsertHeader:
+		sdkObj.InsertHeaderConfig = transSDKInsertHeaderConfigToCreateRule(*action.InsertHeaderConfig)
+	case util.RuleActionTypeRemoveHeader:
+		sdkObj.RemoveHeaderConfig = transSDKRemoveHeaderConfigToCreateRule(*action.RemoveHeaderConfig)
+	case util.RuleActionTypeRewrite:
+		sdkObj.RewriteConfig = transSDKRewriteConfigToCreateRule(*action.RewriteConfig)
+	case util.RuleActionTypeTrafficLimit:
+		sdkObj.TrafficLimitConfig = transSDKTrafficLimitConfigToCreateRule(*action.TrafficLimitConfig)
+	case util.RuleActionTypeTrafficMirror:
+		trafficMirrorConfig, err := transModelTrafficMirrorConfigToSDKCreateRule(*action.TrafficMirrorConfig)
+		if err != nil {
+			return nil, err
+		}
+		sdkObj.TrafficMirrorConfig = *trafficMirrorConfig
 	}
 	return sdkObj, nil
 }
@@ -549,6 +802,20 @@ func transModelActionToSDKUpdateRule(action alb.Action) (*albsdk.UpdateRuleAttri
 			return nil, err
 		}
 		sdkObj.ForwardGroupConfig = *forwardActionConfig
+	case util.RuleActionTypeInsertHeader:
+		sdkObj.InsertHeaderConfig = transSDKInsertHeaderConfigToUpdateRule(*action.InsertHeaderConfig)
+	case util.RuleActionTypeRemoveHeader:
+		sdkObj.RemoveHeaderConfig = transSDKRemoveHeaderConfigToUpdateRule(*action.RemoveHeaderConfig)
+	case util.RuleActionTypeRewrite:
+		sdkObj.RewriteConfig = transSDKRewriteConfigToUpdateRule(*action.RewriteConfig)
+	case util.RuleActionTypeTrafficLimit:
+		sdkObj.TrafficLimitConfig = transSDKTrafficLimitConfigToUpdateRule(*action.TrafficLimitConfig)
+	case util.RuleActionTypeTrafficMirror:
+		trafficMirrorConfig, err := transModelTrafficMirrorConfigToSDKUpdateRule(*action.TrafficMirrorConfig)
+		if err != nil {
+			return nil, err
+		}
+		sdkObj.TrafficMirrorConfig = *trafficMirrorConfig
 	}
 	return sdkObj, nil
 }
@@ -639,6 +906,34 @@ func transModelActionToSDK(action alb.Action) (*albsdk.Action, error) {
 			return nil, err
 		}
 		sdkObj.ForwardGroupConfig = *forwardActionConfig
+	case util.RuleActionTypeInsertHeader:
+		sdkObj.InsertHeaderConfig = albsdk.InsertHeaderConfig{
+			Key:          action.InsertHeaderConfig.Key,
+			Value:        action.InsertHeaderConfig.Value,
+			ValueType:    action.InsertHeaderConfig.ValueType,
+			CoverEnabled: action.InsertHeaderConfig.CoverEnabled,
+		}
+	case util.RuleActionTypeRemoveHeader:
+		sdkObj.RemoveHeaderConfig = albsdk.RemoveHeaderConfig{
+			Key: action.RemoveHeaderConfig.Key,
+		}
+	case util.RuleActionTypeRewrite:
+		sdkObj.RewriteConfig = albsdk.RewriteConfig{
+			Host:  action.RewriteConfig.Host,
+			Path:  action.RewriteConfig.Path,
+			Query: action.RewriteConfig.Query,
+		}
+	case util.RuleActionTypeTrafficLimit:
+		sdkObj.TrafficLimitConfig = albsdk.TrafficLimitConfig{
+			QPS:      action.TrafficLimitConfig.QPS,
+			PerIpQps: action.TrafficLimitConfig.PerIpQps,
+		}
+	case util.RuleActionTypeTrafficMirror:
+		trafficMirrorConfig, err := transModelTrafficMirrorConfigToSDKRule(*action.TrafficMirrorConfig)
+		if err != nil {
+			return nil, err
+		}
+		sdkObj.TrafficMirrorConfig = *trafficMirrorConfig
 	}
 	return sdkObj, nil
 }
@@ -892,6 +1187,73 @@ func transSDKPathConfigToUpdateRule(pathConfig alb.PathConfig) albsdk.UpdateRule
 		Values: &pathConfig.Values,
 	}
 }
+func transSDKSourceIpConfigToCreateRules(sourceIpConfig alb.SourceIpConfig) albsdk.CreateRulesRulesRuleConditionsItemSourceIpConfig {
+	return albsdk.CreateRulesRulesRuleConditionsItemSourceIpConfig{
+		Values: &sourceIpConfig.Values,
+	}
+}
+func transSDKSourceIpConfigToUpdateRules(sourceIpConfig alb.SourceIpConfig) albsdk.UpdateRulesAttributeRulesRuleConditionsItemSourceIpConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleConditionsItemSourceIpConfig{
+		Values: &sourceIpConfig.Values,
+	}
+}
+func transSDKSourceIpConfigToCreateRule(sourceIpConfig alb.SourceIpConfig) albsdk.CreateRuleRuleConditionsSourceIpConfig {
+	return albsdk.CreateRuleRuleConditionsSourceIpConfig{
+		Values: &sourceIpConfig.Values,
+	}
+}
+func transSDKSourceIpConfigToUpdateRule(sourceIpConfig alb.SourceIpConfig) albsdk.UpdateRuleAttributeRuleConditionsSourceIpConfig {
+	return albsdk.UpdateRuleAttributeRuleConditionsSourceIpConfig{
+		Values: &sourceIpConfig.Values,
+	}
+}
+
+func transSDKResponseStatusCodeConfigToCreateRules(statusCodeConfig alb.ResponseStatusCodeConfig) albsdk.CreateRulesRulesRuleConditionsItemResponseStatusCodeConfig {
+	return albsdk.CreateRulesRulesRuleConditionsItemResponseStatusCodeConfig{
+		Values: &statusCodeConfig.Values,
+	}
+}
+func transSDKResponseStatusCodeConfigToUpdateRules(statusCodeConfig alb.ResponseStatusCodeConfig) albsdk.UpdateRulesAttributeRulesRuleConditionsItemResponseStatusCodeConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleConditionsItemResponseStatusCodeConfig{
+		Values: &statusCodeConfig.Values,
+	}
+}
+func transSDKResponseStatusCodeConfigToCreateRule(statusCodeConfig alb.ResponseStatusCodeConfig) albsdk.CreateRuleRuleConditionsResponseStatusCodeConfig {
+	return albsdk.CreateRuleRuleConditionsResponseStatusCodeConfig{
+		Values: &statusCodeConfig.Values,
+	}
+}
+func transSDKResponseStatusCodeConfigToUpdateRule(statusCodeConfig alb.ResponseStatusCodeConfig) albsdk.UpdateRuleAttributeRuleConditionsResponseStatusCodeConfig {
+	return albsdk.UpdateRuleAttributeRuleConditionsResponseStatusCodeConfig{
+		Values: &statusCodeConfig.Values,
+	}
+}
+
+func transSDKResponseHeaderConfigToCreateRules(headerConfig alb.ResponseHeaderConfig) albsdk.CreateRulesRulesRuleConditionsItemResponseHeaderConfig {
+	return albsdk.CreateRulesRulesRuleConditionsItemResponseHeaderConfig{
+		Values: &headerConfig.Values,
+		Key:    headerConfig.Key,
+	}
+}
+func transSDKResponseHeaderConfigToUpdateRules(headerConfig alb.ResponseHeaderConfig) albsdk.UpdateRulesAttributeRulesRuleConditionsItemResponseHeaderConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleConditionsItemResponseHeaderConfig{
+		Values: &headerConfig.Values,
+		Key:    headerConfig.Key,
+	}
+}
+func transSDKResponseHeaderConfigToCreateRule(headerConfig alb.ResponseHeaderConfig) albsdk.CreateRuleRuleConditionsResponseHeaderConfig {
+	return albsdk.CreateRuleRuleConditionsResponseHeaderConfig{
+		Values: &headerConfig.Values,
+		Key:    headerConfig.Key,
+	}
+}
+func transSDKResponseHeaderConfigToUpdateRule(headerConfig alb.ResponseHeaderConfig) albsdk.UpdateRuleAttributeRuleConditionsResponseHeaderConfig {
+	return albsdk.UpdateRuleAttributeRuleConditionsResponseHeaderConfig{
+		Values: &headerConfig.Values,
+		Key:    headerConfig.Key,
+	}
+}
+
 func transSDKConditionToCreateRules(condition alb.Condition) *albsdk.CreateRulesRulesRuleConditionsItem {
 	resCondition := &albsdk.CreateRulesRulesRuleConditionsItem{}
 	resCondition.Type = condition.Type
@@ -909,6 +1271,12 @@ func transSDKConditionToCreateRules(condition alb.Condition) *albsdk.CreateRules
 		resCondition.QueryStringConfig = transSDKQueryStringConfigToCreateRules(condition.QueryStringConfig)
 	case util.RuleConditionFieldCookie:
 		resCondition.CookieConfig = transSDKCookieConfigToCreateRules(condition.CookieConfig)
+	case util.RuleConditionFieldSourceIp:
+		resCondition.SourceIpConfig = transSDKSourceIpConfigToCreateRules(condition.SourceIpConfig)
+	case util.RuleConditionFieldResponseStatusCode:
+		resCondition.ResponseStatusCodeConfig = transSDKResponseStatusCodeConfigToCreateRules(condition.ResponseStatusCodeConfig)
+	case util.RuleConditionFieldResponseHeader:
+		resCondition.ResponseHeaderConfig = transSDKResponseHeaderConfigToCreateRules(condition.ResponseHeaderConfig)
 	}
 
 	return resCondition
@@ -930,6 +1298,12 @@ func transSDKConditionToUpdateRules(condition alb.Condition) *albsdk.UpdateRules
 		resCondition.QueryStringConfig = transSDKQueryStringConfigToUpdateRules(condition.QueryStringConfig)
 	case util.RuleConditionFieldCookie:
 		resCondition.CookieConfig = transSDKCookieConfigToUpdateRules(condition.CookieConfig)
+	case util.RuleConditionFieldSourceIp:
+		resCondition.SourceIpConfig = transSDKSourceIpConfigToUpdateRules(condition.SourceIpConfig)
+	case util.RuleConditionFieldResponseStatusCode:
+		resCondition.ResponseStatusCodeConfig = transSDKResponseStatusCodeConfigToUpdateRules(condition.ResponseStatusCodeConfig)
+	case util.RuleConditionFieldResponseHeader:
+		resCondition.ResponseHeaderConfig = transSDKResponseHeaderConfigToUpdateRules(condition.ResponseHeaderConfig)
 	}
 
 	return resCondition
@@ -952,6 +1326,12 @@ func transSDKConditionToCreateRule(condition alb.Condition) *albsdk.CreateRuleRu
 		resCondition.QueryStringConfig = transSDKQueryStringConfigToCreateRule(condition.QueryStringConfig)
 	case util.RuleConditionFieldCookie:
 		resCondition.CookieConfig = transSDKCookieConfigToCreateRule(condition.CookieConfig)
+	case util.RuleConditionFieldSourceIp:
+		resCondition.SourceIpConfig = transSDKSourceIpConfigToCreateRule(condition.SourceIpConfig)
+	case util.RuleConditionFieldResponseStatusCode:
+		resCondition.ResponseStatusCodeConfig = transSDKResponseStatusCodeConfigToCreateRule(condition.ResponseStatusCodeConfig)
+	case util.RuleConditionFieldResponseHeader:
+		resCondition.ResponseHeaderConfig = transSDKResponseHeaderConfigToCreateRule(condition.ResponseHeaderConfig)
 	}
 
 	return resCondition
@@ -973,6 +1353,12 @@ func transSDKConditionToUpdateRule(condition alb.Condition) *albsdk.UpdateRuleAt
 		resCondition.QueryStringConfig = transSDKQueryStringConfigToUpdateRule(condition.QueryStringConfig)
 	case util.RuleConditionFieldCookie:
 		resCondition.CookieConfig = transSDKCookieConfigToUpdateRule(condition.CookieConfig)
+	case util.RuleConditionFieldSourceIp:
+		resCondition.SourceIpConfig = transSDKSourceIpConfigToUpdateRule(condition.SourceIpConfig)
+	case util.RuleConditionFieldResponseStatusCode:
+		resCondition.ResponseStatusCodeConfig = transSDKResponseStatusCodeConfigToUpdateRule(condition.ResponseStatusCodeConfig)
+	case util.RuleConditionFieldResponseHeader:
+		resCondition.ResponseHeaderConfig = transSDKResponseHeaderConfigToUpdateRule(condition.ResponseHeaderConfig)
 	}
 
 	return resCondition
@@ -1033,6 +1419,7 @@ func buildSDKCreateListenerRuleRequest(lrSpec alb.ListenerRuleSpec) (*albsdk.Cre
 	ruleReq := albsdk.CreateCreateRuleRequest()
 	ruleReq.RuleName = lrSpec.RuleName
 	ruleReq.ListenerId = lsID
+	ruleReq.Direction = lrSpec.Direction
 	ruleReq.RuleConditions = transSDKConditionsToCreateRule(lrSpec.RuleConditions)
 	actions, err := transModelActionsToSDKCreateRule(lrSpec.RuleActions)
 	if err != nil {
@@ -1190,6 +1577,7 @@ func transModelRulesToSDKCreateRules(resLRs []*alb.ListenerRule) ([]albsdk.Creat
 			RuleName:       resLR.Spec.RuleName,
 			Priority:       strconv.Itoa(resLR.Spec.Priority),
 			RuleActions:    actions,
+			Direction:      resLR.Spec.Direction,
 		})
 	}
 	return creatRules, nil
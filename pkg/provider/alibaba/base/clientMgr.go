@@ -21,7 +21,6 @@ import (
 	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
 	"github.com/alibabacloud-go/tea/tea"
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/auth/credentials"
-	"net/http"
 	"net/url"
 	"os"
 	"strings"
@@ -85,6 +84,8 @@ func NewClientMgr() (*ClientMgr, error) {
 	if err != nil {
 		return nil, fmt.Errorf("can not determin region: %s", err.Error())
 	}
+	openAPILimiter = NewOpenAPIRateLimiter(ctrlCfg.CloudCFG.Global.OpenAPIQPS,
+		ctrlCfg.CloudCFG.Global.OpenAPIBurst, ctrlCfg.CloudCFG.Global.OpenAPIRateLimits)
 
 	credential := &credentials.StsTokenCredential{
 		AccessKeyId:       "key",
@@ -92,56 +93,56 @@ func NewClientMgr() (*ClientMgr, error) {
 		AccessKeyStsToken: "",
 	}
 
-	ecli, err := ecs.NewClientWithOptions(region, clientCfg(), credential)
+	ecli, err := ecs.NewClientWithOptions(region, clientCfg(ProductECS), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba ecs client: %s", err.Error())
 	}
 	ecli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	ecli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	vpcli, err := vpc.NewClientWithOptions(region, clientCfg(), credential)
+	vpcli, err := vpc.NewClientWithOptions(region, clientCfg(ProductVPC), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba vpc client: %s", err.Error())
 	}
 	vpcli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	vpcli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	slbcli, err := slb.NewClientWithOptions(region, clientCfg(), credential)
+	slbcli, err := slb.NewClientWithOptions(region, clientCfg(ProductSLB), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba slb client: %s", err.Error())
 	}
 	slbcli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	slbcli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	albcli, err := alb.NewClientWithOptions(region, clientCfg(), credential)
+	albcli, err := alb.NewClientWithOptions(region, clientCfg(ProductALB), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba alb client: %s", err.Error())
 	}
 	albcli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	albcli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	slscli, err := sls.NewClientWithOptions(region, clientCfg(), credential)
+	slscli, err := sls.NewClientWithOptions(region, clientCfg(ProductSLS), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba sls client: %s", err.Error())
 	}
 	slscli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	slscli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	cascli, err := cas.NewClientWithOptions(region, clientCfg(), credential)
+	cascli, err := cas.NewClientWithOptions(region, clientCfg(ProductCAS), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba cas client: %s", err.Error())
 	}
 	cascli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	cascli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	pvtzcli, err := pvtz.NewClientWithOptions(region, clientCfg(), credential)
+	pvtzcli, err := pvtz.NewClientWithOptions(region, clientCfg(ProductPVTZ), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba pvtz client: %s", err.Error())
 	}
 	pvtzcli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	pvtzcli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	esscli, err := ess.NewClientWithOptions(region, clientCfg(), credential)
+	esscli, err := ess.NewClientWithOptions(region, clientCfg(ProductESS), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba pvtz client: %s", err.Error())
 	}
@@ -238,43 +239,44 @@ func (mgr *ClientMgr) GetTokenAuth() TokenAuth {
 
 func RefreshToken(mgr *ClientMgr, token *DefaultToken) error {
 	log.V(5).Info("refresh token", "region", token.Region)
+	observeTokenExpiration(token)
 	credential := &credentials.StsTokenCredential{
 		AccessKeyId:       token.AccessKeyId,
 		AccessKeySecret:   token.AccessKeySecret,
 		AccessKeyStsToken: token.SecurityToken,
 	}
 
-	err := mgr.ECS.InitWithOptions(token.Region, clientCfg(), credential)
+	err := mgr.ECS.InitWithOptions(token.Region, clientCfg(ProductECS), credential)
 	if err != nil {
 		return fmt.Errorf("init ecs sts token config: %s", err.Error())
 	}
 
-	err = mgr.VPC.InitWithOptions(token.Region, clientCfg(), credential)
+	err = mgr.VPC.InitWithOptions(token.Region, clientCfg(ProductVPC), credential)
 	if err != nil {
 		return fmt.Errorf("init vpc sts token config: %s", err.Error())
 	}
 
-	err = mgr.SLB.InitWithOptions(token.Region, clientCfg(), credential)
+	err = mgr.SLB.InitWithOptions(token.Region, clientCfg(ProductSLB), credential)
 	if err != nil {
 		return fmt.Errorf("init slb sts token config: %s", err.Error())
 	}
 
-	err = mgr.ALB.InitWithOptions(token.Region, clientCfg(), credential)
+	err = mgr.ALB.InitWithOptions(token.Region, clientCfg(ProductALB), credential)
 	if err != nil {
 		return fmt.Errorf("init alb sts token config: %s", err.Error())
 	}
 
-	err = mgr.SLS.InitWithOptions(token.Region, clientCfg(), credential)
+	err = mgr.SLS.InitWithOptions(token.Region, clientCfg(ProductSLS), credential)
 	if err != nil {
 		return fmt.Errorf("init sls sts token config: %s", err.Error())
 	}
 
-	err = mgr.CAS.InitWithOptions(token.Region, clientCfg(), credential)
+	err = mgr.CAS.InitWithOptions(token.Region, clientCfg(ProductCAS), credential)
 	if err != nil {
 		return fmt.Errorf("init cas sts token config: %s", err.Error())
 	}
 
-	err = mgr.PVTZ.InitWithOptions(token.Region, clientCfg(), credential)
+	err = mgr.PVTZ.InitWithOptions(token.Region, clientCfg(ProductPVTZ), credential)
 	if err != nil {
 		return fmt.Errorf("init pvtz sts token config: %s", err.Error())
 	}
@@ -334,14 +336,14 @@ func parseURL(str string) (string, error) {
 	return u.Host, nil
 }
 
-func clientCfg() *sdk.Config {
+func clientCfg(product string) *sdk.Config {
 	scheme := "HTTPS"
 	if os.Getenv("ALICLOUD_CLIENT_SCHEME") == "HTTP" {
 		scheme = "HTTP"
 	}
 	return &sdk.Config{
 		Timeout:   20 * time.Second,
-		Transport: http.DefaultTransport,
+		Transport: newOpenAPITransport(product),
 		Scheme:    scheme,
 	}
 }
@@ -0,0 +1,134 @@
+package base
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+)
+
+// products of the sdk clients, used as the product label of the OpenAPI metrics
+const (
+	ProductECS  = "ecs"
+	ProductVPC  = "vpc"
+	ProductSLB  = "slb"
+	ProductALB  = "alb"
+	ProductNLB  = "nlb"
+	ProductPVTZ = "pvtz"
+	ProductCAS  = "cas"
+	ProductSLS  = "sls"
+	ProductESS  = "ess"
+)
+
+const (
+	// ErrorCodeNetwork is recorded when the request does not get a response
+	ErrorCodeNetwork = "NetworkError"
+	// ErrorCodeClient is recorded when the sdk fails without an error code from the server
+	ErrorCodeClient = "ClientError"
+
+	// the size of the error response read to find the error code
+	maxErrorBodySize = 64 * 1024
+)
+
+// openAPITransport rate limits and records every OpenAPI call of an sdk client, retries included.
+type openAPITransport struct {
+	product string
+	next    http.RoundTripper
+}
+
+func newOpenAPITransport(product string) http.RoundTripper {
+	return &openAPITransport{
+		product: product,
+		next:    http.DefaultTransport,
+	}
+}
+
+func (t *openAPITransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	action := req.Header.Get("x-acs-action")
+	if action == "" {
+		action = req.URL.Query().Get("Action")
+	}
+	if err := waitOpenAPI(req.Context(), t.product, action); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		metric.ObserveCloudAPI(t.product, action, start, ErrorCodeNetwork)
+		return resp, err
+	}
+	var code string
+	if resp.StatusCode >= http.StatusBadRequest {
+		code = responseErrorCode(resp)
+	}
+	metric.ObserveCloudAPI(t.product, action, start, code)
+	observeOpenAPI(t.product, action, code)
+	return resp, nil
+}
+
+// responseErrorCode reads the error code from the body of a failed response, and puts
+// the body back for the sdk.
+func responseErrorCode(resp *http.Response) string {
+	code := fmt.Sprintf("HTTP%d", resp.StatusCode)
+	if resp.Body == nil {
+		return code
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	rest := resp.Body
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), rest), rest}
+	if err != nil {
+		return code
+	}
+	errResp := struct {
+		Code string `json:"Code"`
+	}{}
+	if json.Unmarshal(body, &errResp) == nil && errResp.Code != "" {
+		return errResp.Code
+	}
+	return code
+}
+
+// CallOpenAPI calls an OpenAPI of the tea based sdk clients, e.g. NLB, with the rate limit, and
+// records it. These clients create their own http transport, so they are instrumented per call.
+func CallOpenAPI[Req, Resp any](product, action string, call func(Req) (Resp, error), req Req) (Resp, error) {
+	if err := waitOpenAPI(context.TODO(), product, action); err != nil {
+		var resp Resp
+		return resp, err
+	}
+	start := time.Now()
+	resp, err := call(req)
+	code := teaErrorCode(err)
+	metric.ObserveCloudAPI(product, action, start, code)
+	observeOpenAPI(product, action, code)
+	return resp, err
+}
+
+func teaErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var sdkErr *tea.SDKError
+	if errors.As(err, &sdkErr) && tea.StringValue(sdkErr.Code) != "" {
+		return tea.StringValue(sdkErr.Code)
+	}
+	return ErrorCodeClient
+}
+
+// observeTokenExpiration exports the expiration time of the token, AK tokens do not expire.
+func observeTokenExpiration(token *DefaultToken) {
+	if token.Expiration.IsZero() {
+		metric.TokenExpiration.Set(0)
+		return
+	}
+	metric.TokenExpiration.Set(float64(token.Expiration.Unix()))
+}
@@ -0,0 +1,58 @@
+package base
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alibabacloud-go/tea/tea"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+)
+
+func requestCount(t *testing.T, product, action, result, code string) float64 {
+	m := &dto.Metric{}
+	assert.NoError(t, metric.CloudAPIRequests.WithLabelValues(product, action, result, code).Write(m))
+	return m.GetCounter().GetValue()
+}
+
+func TestOpenAPITransport(t *testing.T) {
+	body := `{"RequestId":"req-1","Code":"Throttling.User","Message":"Request was denied due to user flow control."}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("Action") == "DescribeLoadBalancers" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: newOpenAPITransport(ProductSLB)}
+
+	resp, err := client.Get(server.URL + "/?Action=DescribeLoadBalancers")
+	assert.NoError(t, err)
+	got, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	// the sdk still reads the error response
+	assert.Equal(t, body, string(got))
+	assert.Equal(t, float64(1), requestCount(t, ProductSLB, "DescribeLoadBalancers", metric.ResultFail, "Throttling.User"))
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+	assert.NoError(t, err)
+	req.Header.Set("x-acs-action", "CreateLoadBalancer")
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, float64(1), requestCount(t, ProductSLB, "CreateLoadBalancer", metric.ResultSuccess, ""))
+}
+
+func TestTeaErrorCode(t *testing.T) {
+	assert.Equal(t, "", teaErrorCode(nil))
+	sdkErr := tea.NewSDKError(map[string]interface{}{"code": "Throttling", "message": "throttled"})
+	assert.Equal(t, "Throttling", teaErrorCode(sdkErr))
+	assert.Equal(t, ErrorCodeClient, teaErrorCode(fmt.Errorf("validate error")))
+}
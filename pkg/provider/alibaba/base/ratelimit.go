@@ -0,0 +1,129 @@
+package base
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+const (
+	// throttlingBackoffBase is the backoff of an action after it is throttled once,
+	// it doubles with every consecutive throttled call up to throttlingBackoffMax.
+	throttlingBackoffBase = 1 * time.Second
+	throttlingBackoffMax  = 30 * time.Second
+)
+
+// openAPILimiter is shared by all the sdk clients, it is set up by NewClientMgr.
+var openAPILimiter *OpenAPIRateLimiter
+
+// OpenAPIRateLimiter limits the calls of every OpenAPI action with a token bucket, and
+// holds the calls of an action back with a jittered exponential backoff once it is throttled.
+type OpenAPIRateLimiter struct {
+	qps    float64
+	burst  int
+	limits map[string]float64
+
+	lock    sync.Mutex
+	actions map[string]*actionLimiter
+}
+
+type actionLimiter struct {
+	limiter      *rate.Limiter
+	throttled    int
+	blockedUntil time.Time
+}
+
+// NewOpenAPIRateLimiter returns a limiter allowing qps calls per second with burst for each action.
+// limits overrides the qps of a product or an action, keyed by "slb" or "slb.DescribeLoadBalancers", unless it is 0.
+// A negative qps disables the token bucket, the throttling backoff still applies.
+func NewOpenAPIRateLimiter(qps float64, burst int, limits map[string]float64) *OpenAPIRateLimiter {
+	return &OpenAPIRateLimiter{
+		qps:     qps,
+		burst:   burst,
+		limits:  limits,
+		actions: make(map[string]*actionLimiter),
+	}
+}
+
+func (l *OpenAPIRateLimiter) actionLimiter(product, action string) *actionLimiter {
+	key := product + "." + action
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if a, ok := l.actions[key]; ok {
+		return a
+	}
+
+	// a zero limit is taken as unset like the default qps, a zero token bucket would block the action forever
+	qps, burst := l.qps, l.burst
+	if v := l.limits[product]; v != 0 {
+		qps, burst = v, int(math.Ceil(v*2))
+	}
+	if v := l.limits[key]; v != 0 {
+		qps, burst = v, int(math.Ceil(v*2))
+	}
+	limit := rate.Limit(qps)
+	if qps < 0 {
+		limit = rate.Inf
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	a := &actionLimiter{limiter: rate.NewLimiter(limit, burst)}
+	l.actions[key] = a
+	return a
+}
+
+// Wait blocks until the action is allowed to be called.
+func (l *OpenAPIRateLimiter) Wait(ctx context.Context, product, action string) error {
+	a := l.actionLimiter(product, action)
+	l.lock.Lock()
+	backoff := time.Until(a.blockedUntil)
+	l.lock.Unlock()
+	if backoff > 0 {
+		timer := time.NewTimer(backoff)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return a.limiter.Wait(ctx)
+}
+
+// Observe updates the backoff of the action with the error code of a call, empty for success.
+func (l *OpenAPIRateLimiter) Observe(product, action, code string) {
+	a := l.actionLimiter(product, action)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if !util.IsThrottlingCode(code) {
+		if code == "" {
+			a.throttled = 0
+		}
+		return
+	}
+	a.throttled++
+	backoff := throttlingBackoffMax
+	if a.throttled < 6 {
+		backoff = throttlingBackoffBase << (a.throttled - 1)
+	}
+	a.blockedUntil = time.Now().Add(wait.Jitter(backoff/2, 1))
+}
+
+func waitOpenAPI(ctx context.Context, product, action string) error {
+	if openAPILimiter == nil {
+		return nil
+	}
+	return openAPILimiter.Wait(ctx, product, action)
+}
+
+func observeOpenAPI(product, action, code string) {
+	if openAPILimiter != nil {
+		openAPILimiter.Observe(product, action, code)
+	}
+}
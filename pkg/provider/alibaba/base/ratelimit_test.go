@@ -0,0 +1,54 @@
+package base
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestOpenAPIRateLimiter(t *testing.T) {
+	l := NewOpenAPIRateLimiter(20, 40, map[string]float64{
+		ProductSLB:                        5,
+		ProductSLB + ".DescribeZones":     -1,
+		ProductNLB + ".ListLoadBalancers": 1,
+		ProductVPC:                        0,
+	})
+
+	cases := []struct {
+		product string
+		action  string
+		limit   rate.Limit
+		burst   int
+	}{
+		{ProductECS, "DescribeInstances", 20, 40},
+		{ProductSLB, "DescribeLoadBalancers", 5, 10},
+		{ProductSLB, "DescribeZones", rate.Inf, 1},
+		{ProductNLB, "ListLoadBalancers", 1, 2},
+		{ProductVPC, "DescribeRouteTableList", 20, 40},
+	}
+	for _, c := range cases {
+		a := l.actionLimiter(c.product, c.action)
+		assert.Equal(t, c.limit, a.limiter.Limit(), c.action)
+		assert.Equal(t, c.burst, a.limiter.Burst(), c.action)
+	}
+
+	// throttled calls hold the action back, other actions are not affected
+	l.Observe(ProductSLB, "DescribeLoadBalancers", "Throttling.User")
+	a := l.actionLimiter(ProductSLB, "DescribeLoadBalancers")
+	assert.True(t, a.blockedUntil.After(time.Now()))
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, l.Wait(ctx, ProductSLB, "DescribeLoadBalancers"))
+	assert.NoError(t, l.Wait(context.TODO(), ProductECS, "DescribeInstances"))
+
+	l.Observe(ProductSLB, "DescribeLoadBalancers", "Throttling.User")
+	assert.Equal(t, 2, a.throttled)
+	// other errors keep the backoff, a success resets it
+	l.Observe(ProductSLB, "DescribeLoadBalancers", "InvalidParameter")
+	assert.Equal(t, 2, a.throttled)
+	l.Observe(ProductSLB, "DescribeLoadBalancers", "")
+	assert.Equal(t, 0, a.throttled)
+}
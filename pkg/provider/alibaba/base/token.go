@@ -24,6 +24,8 @@ type DefaultToken struct {
 	AccessKeyId     string
 	AccessKeySecret string
 	SecurityToken   string
+	// Expiration is zero for tokens which do not expire
+	Expiration time.Time
 }
 
 // TokenAuth is an interface of Token auth method
@@ -72,6 +74,7 @@ func (f *RamRoleToken) NextToken() (*DefaultToken, error) {
 		AccessKeyId:     role.AccessKeyId,
 		AccessKeySecret: role.AccessKeySecret,
 		SecurityToken:   role.SecurityToken,
+		Expiration:      role.Expiration,
 	}, nil
 }
 
@@ -107,12 +110,19 @@ func (f *ServiceToken) NextToken() (*DefaultToken, error) {
 	if err = json.Unmarshal([]byte(strings.Join(status.Stdout, "")), &st); err != nil {
 		return nil, fmt.Errorf("unmarshal ServiceToken output %+v error: %s", status, err.Error())
 	}
+	var expiration time.Time
+	if st.Expiration != "" {
+		if expiration, err = time.Parse("2006-01-02T15:04:05Z", st.Expiration); err != nil {
+			log.Error(err, "Expiration parse error")
+		}
+	}
 
 	return &DefaultToken{
 		Region:          f.Region,
 		AccessKeyId:     st.AccessKey,
 		AccessKeySecret: st.AccessSecret,
 		SecurityToken:   st.Token,
+		Expiration:      expiration,
 	}, nil
 }
 
@@ -166,6 +176,7 @@ func (f *AddonToken) NextToken() (*DefaultToken, error) {
 		AccessKeyId:     string(ak),
 		AccessKeySecret: string(sk),
 		SecurityToken:   string(token),
+		Expiration:      t,
 	}, nil
 }
 
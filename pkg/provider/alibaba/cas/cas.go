@@ -3,8 +3,10 @@ package cas
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/pkg/errors"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,8 +17,10 @@ import (
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/responses"
+	cassdk "github.com/aliyun/alibaba-cloud-sdk-go/services/cas"
 	"github.com/go-logr/logr"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -46,12 +50,20 @@ type CASProvider struct {
 const (
 	CASDomain   = "cas.aliyuncs.com"
 	CASShowSize = 50
+	// CASTagResourceType is the resource type of the uploaded certificates in the tag apis
+	CASTagResourceType = "UserCertificate"
 )
 
 const (
 	certsCacheKey                         = "CertificateInfo"
 	DescribeSSLCertificateList            = "DescribeSSLCertificateList"
 	DescribeSSLCertificatePublicKeyDetail = "DescribeSSLCertificatePublicKeyDetail"
+	UploadUserCertificate                 = "UploadUserCertificate"
+	ListTagResources                      = "ListTagResources"
+	DeleteUserCertificate                 = "DeleteUserCertificate"
+	UploadPCACert                         = "UploadPCACert"
+	ListCert                              = "ListCert"
+	DeletePCACert                         = "DeletePCACert"
 	DefaultSSLCertificatePollInterval     = 30 * time.Second
 	DefaultSSLCertificateTimeout          = 60 * time.Second
 )
@@ -177,3 +189,261 @@ func (c CASProvider) DescribeSSLCertificateList(ctx context.Context) ([]model.Ce
 	c.certsCache.Set(certsCacheKey, certificateInfos, c.certsCacheTTL)
 	return certificateInfos, nil
 }
+
+// UploadSSLCertificate uploads the certificate and private key in pem format, and returns
+// the CertIdentifier that ALB listeners refer to.
+func (c CASProvider) UploadSSLCertificate(ctx context.Context, certName, cert, key string, tags []tag.Tag) (string, error) {
+	traceID := ctx.Value(util.TraceID)
+
+	req := cassdk.CreateUploadUserCertificateRequest()
+	req.Domain = CASDomain
+	req.Name = certName
+	req.Cert = cert
+	req.Key = key
+	// the sdk request has no tags field yet
+	for i, t := range tags {
+		req.QueryParams[fmt.Sprintf("Tags.%d.Key", i+1)] = t.Key
+		req.QueryParams[fmt.Sprintf("Tags.%d.Value", i+1)] = t.Value
+	}
+
+	startTime := time.Now()
+	c.logger.Info("uploading ssl certificate",
+		"traceID", traceID,
+		"certName", certName,
+		"startTime", startTime,
+		"action", UploadUserCertificate)
+	resp, err := c.auth.CAS.UploadUserCertificate(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to upload ssl certificate")
+	}
+	c.logger.Info("uploaded ssl certificate",
+		"traceID", traceID,
+		"certName", certName,
+		"certID", resp.CertId,
+		"requestID", resp.RequestId,
+		"elapsedTime", time.Since(startTime).Milliseconds(),
+		"action", UploadUserCertificate)
+
+	// the upload api only returns the numeric id, look up the identifier with region suffix
+	c.certsCache.Delete(certsCacheKey)
+	certs, err := c.DescribeSSLCertificateList(ctx)
+	if err != nil {
+		return "", err
+	}
+	prefix := fmt.Sprintf("%d-", resp.CertId)
+	for _, cert := range certs {
+		if strings.HasPrefix(cert.CertIdentifier, prefix) {
+			return cert.CertIdentifier, nil
+		}
+	}
+	return "", fmt.Errorf("uploaded ssl certificate %d not found", resp.CertId)
+}
+
+// DescribeSSLCertificateListByTags lists the tagged certificate ids with ListTagResources, and
+// returns the certificates of DescribeSSLCertificateList with those ids.
+func (c CASProvider) DescribeSSLCertificateListByTags(ctx context.Context, tags []tag.Tag) ([]model.CertificateInfo, error) {
+	traceID := ctx.Value(util.TraceID)
+
+	rpcRequest := &requests.RpcRequest{}
+	rpcRequest.InitWithApiInfo("cas", "2020-04-07", ListTagResources, "cas", "openAPI")
+	rpcRequest.Method = requests.POST
+	rpcRequest.Domain = CASDomain
+
+	certIDs := make(map[string]bool)
+	nextToken := ""
+	for {
+		rpcRequest.QueryParams = map[string]string{
+			"ResourceType": CASTagResourceType,
+		}
+		for i, t := range tags {
+			rpcRequest.QueryParams[fmt.Sprintf("Tag.%d.Key", i+1)] = t.Key
+			rpcRequest.QueryParams[fmt.Sprintf("Tag.%d.Value", i+1)] = t.Value
+		}
+		if nextToken != "" {
+			rpcRequest.QueryParams["NextToken"] = nextToken
+		}
+
+		startTime := time.Now()
+		c.logger.Info("listing ssl certificate tags",
+			"traceID", traceID,
+			"startTime", startTime,
+			"action", ListTagResources)
+		response := responses.NewCommonResponse()
+		if err := c.casDoAction(rpcRequest, response); err != nil {
+			return nil, errors.Wrap(err, "failed to list ssl certificate tags")
+		}
+		c.logger.Info("listed ssl certificate tags",
+			"traceID", traceID,
+			"elapsedTime", time.Since(startTime).Milliseconds(),
+			"action", ListTagResources)
+
+		resp := struct {
+			NextToken    string `json:"NextToken"`
+			TagResources []struct {
+				ResourceId string `json:"ResourceId"`
+			} `json:"TagResources"`
+		}{}
+		if err := json.Unmarshal(response.GetHttpContentBytes(), &resp); err != nil {
+			return nil, err
+		}
+		for _, r := range resp.TagResources {
+			certIDs[r.ResourceId] = true
+		}
+		if resp.NextToken == "" {
+			break
+		}
+		nextToken = resp.NextToken
+	}
+	if len(certIDs) == 0 {
+		return nil, nil
+	}
+
+	certs, err := c.DescribeSSLCertificateList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ret []model.CertificateInfo
+	for _, cert := range certs {
+		// the CertIdentifier is the numeric certificate id with a region suffix
+		if certIDs[strings.Split(cert.CertIdentifier, "-")[0]] {
+			ret = append(ret, cert)
+		}
+	}
+	return ret, nil
+}
+
+func (c CASProvider) DeleteSSLCertificate(ctx context.Context, certIdentifier string) error {
+	traceID := ctx.Value(util.TraceID)
+
+	certID, err := strconv.ParseInt(strings.Split(certIdentifier, "-")[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid cert identifier: %s", certIdentifier)
+	}
+
+	req := cassdk.CreateDeleteUserCertificateRequest()
+	req.Domain = CASDomain
+	req.CertId = requests.NewInteger64(certID)
+
+	startTime := time.Now()
+	c.logger.Info("deleting ssl certificate",
+		"traceID", traceID,
+		"certIdentifier", certIdentifier,
+		"startTime", startTime,
+		"action", DeleteUserCertificate)
+	resp, err := c.auth.CAS.DeleteUserCertificate(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete ssl certificate")
+	}
+	c.logger.Info("deleted ssl certificate",
+		"traceID", traceID,
+		"certIdentifier", certIdentifier,
+		"requestID", resp.RequestId,
+		"elapsedTime", time.Since(startTime).Milliseconds(),
+		"action", DeleteUserCertificate)
+
+	c.certsCache.Delete(certsCacheKey)
+	return nil
+}
+
+// UploadCACertificate uploads the CA certificate in pem format, and returns the identifier
+// that NLB listeners refer to as CaCertificateIds.
+func (c CASProvider) UploadCACertificate(ctx context.Context, certName, cert string) (string, error) {
+	traceID := ctx.Value(util.TraceID)
+
+	req := cassdk.CreateUploadPCACertRequest()
+	req.Domain = CASDomain
+	req.Name = certName
+	req.Cert = cert
+
+	startTime := time.Now()
+	c.logger.Info("uploading ca certificate",
+		"traceID", traceID,
+		"certName", certName,
+		"startTime", startTime,
+		"action", UploadPCACert)
+	resp, err := c.auth.CAS.UploadPCACert(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to upload ca certificate")
+	}
+	c.logger.Info("uploaded ca certificate",
+		"traceID", traceID,
+		"certName", certName,
+		"certID", resp.Identifier,
+		"requestID", resp.RequestId,
+		"elapsedTime", time.Since(startTime).Milliseconds(),
+		"action", UploadPCACert)
+	return resp.Identifier, nil
+}
+
+// DescribeCACertificateList lists the uploaded CA certificates whose name contains keyword.
+func (c CASProvider) DescribeCACertificateList(ctx context.Context, keyword string) ([]model.CertificateInfo, error) {
+	traceID := ctx.Value(util.TraceID)
+
+	req := cassdk.CreateListCertRequest()
+	req.Domain = CASDomain
+	req.CertType = "CA"
+	req.SourceType = "upload"
+	req.KeyWord = keyword
+	req.ShowSize = requests.NewInteger(CASShowSize)
+
+	var certificateInfos []model.CertificateInfo
+	for pageNumber := 1; ; pageNumber++ {
+		req.CurrentPage = requests.NewInteger(pageNumber)
+
+		startTime := time.Now()
+		c.logger.Info("listing ca certificate",
+			"traceID", traceID,
+			"keyword", keyword,
+			"startTime", startTime,
+			"action", ListCert)
+		resp, err := c.auth.CAS.ListCert(req)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list ca certificate")
+		}
+		c.logger.Info("listed ca certificate",
+			"traceID", traceID,
+			"requestID", resp.RequestId,
+			"elapsedTime", time.Since(startTime).Milliseconds(),
+			"action", ListCert)
+		for _, cert := range resp.CertList {
+			certificateInfos = append(certificateInfos, model.CertificateInfo{
+				CommonName:     cert.CommonName,
+				Issuer:         cert.Issuer,
+				CertIdentifier: cert.Identifier,
+				BeforeDate:     cert.BeforeDate,
+				AfterDate:      cert.AfterDate,
+				Sans:           cert.Sans,
+			})
+		}
+		if int64(pageNumber*CASShowSize) >= resp.TotalCount {
+			break
+		}
+	}
+	return certificateInfos, nil
+}
+
+func (c CASProvider) DeleteCACertificate(ctx context.Context, certIdentifier string) error {
+	traceID := ctx.Value(util.TraceID)
+
+	req := cassdk.CreateDeletePCACertRequest()
+	req.Domain = CASDomain
+	req.Identifier = certIdentifier
+
+	startTime := time.Now()
+	c.logger.Info("deleting ca certificate",
+		"traceID", traceID,
+		"certIdentifier", certIdentifier,
+		"startTime", startTime,
+		"action", DeletePCACert)
+	resp, err := c.auth.CAS.DeletePCACert(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete ca certificate")
+	}
+	c.logger.Info("deleted ca certificate",
+		"traceID", traceID,
+		"certIdentifier", certIdentifier,
+		"requestID", resp.RequestId,
+		"elapsedTime", time.Since(startTime).Milliseconds(),
+		"action", DeletePCACert)
+	return nil
+}
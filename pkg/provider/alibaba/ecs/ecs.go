@@ -81,6 +81,15 @@ func (e *ECSProvider) ListInstances(ctx context.Context, ids []string) (map[stri
 					SpotStrategy:              n.SpotStrategy,
 					PrimaryNetworkInterfaceID: primaryNetworkInterface,
 					Tags:                      tags,
+					Status:                    n.Status,
+					InstanceTypeFamily:        n.InstanceTypeFamily,
+					CPU:                       n.Cpu,
+					Memory:                    n.Memory,
+					GPUAmount:                 n.GPUAmount,
+					GPUSpec:                   n.GPUSpec,
+					VSwitchID:                 n.VpcAttributes.VSwitchId,
+					SecurityGroupIDs:          n.SecurityGroupIds.SecurityGroupId,
+					LockReasons:               lockReasons(&n),
 				}
 				break
 			}
@@ -133,9 +142,26 @@ func (e *ECSProvider) GetInstancesByIP(ctx context.Context, ips []string) (*prvd
 		InstanceChargeType: ins.InstanceChargeType,
 		SpotStrategy:       ins.SpotStrategy,
 		Tags:               tags,
+		Status:             ins.Status,
+		InstanceTypeFamily: ins.InstanceTypeFamily,
+		CPU:                ins.Cpu,
+		Memory:             ins.Memory,
+		GPUAmount:          ins.GPUAmount,
+		GPUSpec:            ins.GPUSpec,
+		VSwitchID:          ins.VpcAttributes.VSwitchId,
+		SecurityGroupIDs:   ins.SecurityGroupIds.SecurityGroupId,
+		LockReasons:        lockReasons(&ins),
 	}, nil
 }
 
+func lockReasons(ins *ecs.Instance) []string {
+	var reasons []string
+	for _, l := range ins.OperationLocks.LockReason {
+		reasons = append(reasons, l.LockReason)
+	}
+	return reasons
+}
+
 func (e *ECSProvider) getInstances(ids []string, region string) ([]ecs.Instance, error) {
 	bids, err := json.Marshal(ids)
 	if err != nil {
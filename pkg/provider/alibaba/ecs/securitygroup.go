@@ -0,0 +1,189 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/ecs"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// MaxSecurityGroupRulesPerRequest is the max count of the rules authorized or revoked by one request
+	MaxSecurityGroupRulesPerRequest = 100
+	securityGroupDirectionIngress   = "ingress"
+)
+
+func (e *ECSProvider) ListSecurityGroups(ctx context.Context, tags []tag.Tag) ([]model.SecurityGroup, error) {
+	req := ecs.CreateDescribeSecurityGroupsRequest()
+	var reqTags []ecs.DescribeSecurityGroupsTag
+	for _, t := range tags {
+		reqTags = append(reqTags, ecs.DescribeSecurityGroupsTag{Key: t.Key, Value: t.Value})
+	}
+	req.Tag = &reqTags
+	req.MaxResults = requests.NewInteger(100)
+
+	var sgs []model.SecurityGroup
+	for {
+		resp, err := e.auth.ECS.DescribeSecurityGroups(req)
+		if err != nil {
+			return nil, util.SDKError("DescribeSecurityGroups", err)
+		}
+		klog.V(5).Infof("RequestId: %s, API: %s", resp.RequestId, "DescribeSecurityGroups")
+		for _, s := range resp.SecurityGroups.SecurityGroup {
+			sg := model.SecurityGroup{
+				SecurityGroupId:   s.SecurityGroupId,
+				SecurityGroupName: s.SecurityGroupName,
+				Description:       s.Description,
+				VpcId:             s.VpcId,
+			}
+			for _, t := range s.Tags.Tag {
+				sg.Tags = append(sg.Tags, tag.Tag{Key: t.TagKey, Value: t.TagValue})
+			}
+			sgs = append(sgs, sg)
+		}
+		if resp.NextToken == "" {
+			break
+		}
+		req.NextToken = resp.NextToken
+	}
+	return sgs, nil
+}
+
+func (e *ECSProvider) CreateSecurityGroup(ctx context.Context, sg *model.SecurityGroup) error {
+	req := ecs.CreateCreateSecurityGroupRequest()
+	req.VpcId = sg.VpcId
+	req.SecurityGroupName = sg.SecurityGroupName
+	req.Description = sg.Description
+	var reqTags []ecs.CreateSecurityGroupTag
+	for _, t := range sg.Tags {
+		reqTags = append(reqTags, ecs.CreateSecurityGroupTag{Key: t.Key, Value: t.Value})
+	}
+	req.Tag = &reqTags
+	resp, err := e.auth.ECS.CreateSecurityGroup(req)
+	if err != nil {
+		return util.SDKError("CreateSecurityGroup", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s, securityGroupName: %s",
+		resp.RequestId, "CreateSecurityGroup", sg.SecurityGroupName)
+	sg.SecurityGroupId = resp.SecurityGroupId
+	return nil
+}
+
+func (e *ECSProvider) DeleteSecurityGroup(ctx context.Context, sgId string) error {
+	req := ecs.CreateDeleteSecurityGroupRequest()
+	req.SecurityGroupId = sgId
+	_, err := e.auth.ECS.DeleteSecurityGroup(req)
+	if err != nil {
+		return util.SDKError("DeleteSecurityGroup", err)
+	}
+	return nil
+}
+
+func (e *ECSProvider) DescribeSecurityGroupRules(ctx context.Context, sgId string) ([]model.SecurityGroupRule, error) {
+	req := ecs.CreateDescribeSecurityGroupAttributeRequest()
+	req.SecurityGroupId = sgId
+	req.Direction = securityGroupDirectionIngress
+	req.MaxResults = requests.NewInteger(1000)
+
+	var rules []model.SecurityGroupRule
+	for {
+		resp, err := e.auth.ECS.DescribeSecurityGroupAttribute(req)
+		if err != nil {
+			return nil, util.SDKError("DescribeSecurityGroupAttribute", err)
+		}
+		for _, p := range resp.Permissions.Permission {
+			priority, err := strconv.Atoi(p.Priority)
+			if err != nil {
+				return nil, fmt.Errorf("parse priority %s of security group rule %s error: %s",
+					p.Priority, p.SecurityGroupRuleId, err.Error())
+			}
+			rule := model.SecurityGroupRule{
+				RuleId:       p.SecurityGroupRuleId,
+				Policy:       strings.ToLower(p.Policy),
+				IpProtocol:   strings.ToLower(p.IpProtocol),
+				PortRange:    p.PortRange,
+				SourceCidrIp: p.SourceCidrIp,
+				Priority:     priority,
+				Description:  p.Description,
+			}
+			if rule.SourceCidrIp == "" {
+				rule.SourceCidrIp = p.Ipv6SourceCidrIp
+			}
+			rules = append(rules, rule)
+		}
+		if resp.NextToken == "" {
+			break
+		}
+		req.NextToken = resp.NextToken
+	}
+	return rules, nil
+}
+
+func (e *ECSProvider) AuthorizeSecurityGroupRules(ctx context.Context, sgId string, rules []model.SecurityGroupRule) error {
+	for begin := 0; begin < len(rules); begin += MaxSecurityGroupRulesPerRequest {
+		last := len(rules)
+		if begin+MaxSecurityGroupRulesPerRequest < last {
+			last = begin + MaxSecurityGroupRulesPerRequest
+		}
+
+		var permissions []ecs.AuthorizeSecurityGroupPermissions
+		for _, r := range rules[begin:last] {
+			p := ecs.AuthorizeSecurityGroupPermissions{
+				Policy:      r.Policy,
+				Priority:    strconv.Itoa(r.Priority),
+				IpProtocol:  r.IpProtocol,
+				PortRange:   r.PortRange,
+				Description: r.Description,
+			}
+			if strings.Contains(r.SourceCidrIp, ":") {
+				p.Ipv6SourceCidrIp = r.SourceCidrIp
+			} else {
+				p.SourceCidrIp = r.SourceCidrIp
+			}
+			permissions = append(permissions, p)
+		}
+
+		req := ecs.CreateAuthorizeSecurityGroupRequest()
+		req.SecurityGroupId = sgId
+		req.Permissions = &permissions
+		resp, err := e.auth.ECS.AuthorizeSecurityGroup(req)
+		if err != nil {
+			return util.SDKError("AuthorizeSecurityGroup", err)
+		}
+		klog.V(5).Infof("RequestId: %s, API: %s, securityGroupId: %s, rules[%d:%d]",
+			resp.RequestId, "AuthorizeSecurityGroup", sgId, begin, last)
+	}
+	return nil
+}
+
+func (e *ECSProvider) RevokeSecurityGroupRules(ctx context.Context, sgId string, rules []model.SecurityGroupRule) error {
+	for begin := 0; begin < len(rules); begin += MaxSecurityGroupRulesPerRequest {
+		last := len(rules)
+		if begin+MaxSecurityGroupRulesPerRequest < last {
+			last = begin + MaxSecurityGroupRulesPerRequest
+		}
+
+		var ruleIds []string
+		for _, r := range rules[begin:last] {
+			ruleIds = append(ruleIds, r.RuleId)
+		}
+
+		req := ecs.CreateRevokeSecurityGroupRequest()
+		req.SecurityGroupId = sgId
+		req.SecurityGroupRuleId = &ruleIds
+		resp, err := e.auth.ECS.RevokeSecurityGroup(req)
+		if err != nil {
+			return util.SDKError("RevokeSecurityGroup", err)
+		}
+		klog.V(5).Infof("RequestId: %s, API: %s, securityGroupId: %s, rules[%d:%d]",
+			resp.RequestId, "RevokeSecurityGroup", sgId, begin, last)
+	}
+	return nil
+}
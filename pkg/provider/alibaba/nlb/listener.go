@@ -6,6 +6,7 @@ import (
 	nlb "github.com/alibabacloud-go/nlb-20220430/v3/client"
 	"github.com/alibabacloud-go/tea/tea"
 	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
 	"k8s.io/klog/v2"
 	"strconv"
@@ -20,7 +21,7 @@ func (p *NLBProvider) ListNLBListeners(ctx context.Context, lbId string) ([]*nlb
 		req.MaxResults = tea.Int32(100)
 		req.NextToken = tea.String(nextToken)
 
-		resp, err := p.auth.NLB.ListListeners(req)
+		resp, err := base.CallOpenAPI(base.ProductNLB, "ListListeners", p.auth.NLB.ListListeners, req)
 		if err != nil {
 			return nil, util.SDKError("ListListeners", err)
 		}
@@ -127,7 +128,7 @@ func (p *NLBProvider) StartNLBListener(ctx context.Context, listenerId string) e
 	req := &nlb.StartListenerRequest{}
 	req.ListenerId = tea.String(listenerId)
 
-	resp, err := p.auth.NLB.StartListener(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "StartListener", p.auth.NLB.StartListener, req)
 	if err != nil {
 		return util.SDKError("StartListener", err)
 	}
@@ -182,7 +183,7 @@ func (p *NLBProvider) CreateNLBListenerAsync(ctx context.Context, lbId string, l
 	}
 	req.CaEnabled = lis.CaEnabled
 
-	resp, err := p.auth.NLB.CreateListener(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "CreateListener", p.auth.NLB.CreateListener, req)
 	if err != nil {
 		return "", util.SDKError("CreateListener", err)
 	}
@@ -227,7 +228,7 @@ func (p *NLBProvider) UpdateNLBListenerAsync(ctx context.Context, lis *nlbmodel.
 	}
 	req.CaEnabled = lis.CaEnabled
 
-	resp, err := p.auth.NLB.UpdateListenerAttribute(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "UpdateListenerAttribute", p.auth.NLB.UpdateListenerAttribute, req)
 	if err != nil {
 		return "", util.SDKError("UpdateListenerAttribute", err)
 	}
@@ -242,7 +243,7 @@ func (p *NLBProvider) DeleteNLBListenerAsync(ctx context.Context, listenerId str
 	req := &nlb.DeleteListenerRequest{}
 	req.ListenerId = tea.String(listenerId)
 
-	resp, err := p.auth.NLB.DeleteListener(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "DeleteListener", p.auth.NLB.DeleteListener, req)
 	if err != nil {
 		return "", util.SDKError("DeleteNLBListener", err)
 	}
@@ -148,7 +148,7 @@ func (p *NLBProvider) CreateNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBa
 		req.BandwidthPackageId = mdl.LoadBalancerAttribute.BandwidthPackageId
 	}
 
-	resp, err := p.auth.NLB.CreateLoadBalancer(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "CreateLoadBalancer", p.auth.NLB.CreateLoadBalancer, req)
 	if err != nil {
 		return util.SDKError("CreateLoadBalancer", err)
 	}
@@ -164,7 +164,7 @@ func (p *NLBProvider) CreateNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBa
 func (p *NLBProvider) DeleteNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
 	req := &nlb.DeleteLoadBalancerRequest{}
 	req.LoadBalancerId = tea.String(mdl.LoadBalancerAttribute.LoadBalancerId)
-	resp, err := p.auth.NLB.DeleteLoadBalancer(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "DeleteLoadBalancer", p.auth.NLB.DeleteLoadBalancer, req)
 	if err != nil {
 		return util.SDKError("DeleteLoadBalancer", err)
 	}
@@ -182,7 +182,7 @@ func (p *NLBProvider) UpdateNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBa
 	if mdl.LoadBalancerAttribute.Name != "" {
 		req.LoadBalancerName = tea.String(mdl.LoadBalancerAttribute.Name)
 	}
-	resp, err := p.auth.NLB.UpdateLoadBalancerAttribute(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "UpdateLoadBalancerAttribute", p.auth.NLB.UpdateLoadBalancerAttribute, req)
 	if err != nil {
 		return util.SDKError("UpdateLoadBalancerAttribute", err)
 	}
@@ -198,8 +198,22 @@ func (p *NLBProvider) UpdateNLBAddressType(ctx context.Context, mdl *nlbmodel.Ne
 	req := &nlb.UpdateLoadBalancerAddressTypeConfigRequest{}
 	req.LoadBalancerId = tea.String(mdl.LoadBalancerAttribute.LoadBalancerId)
 	req.AddressType = tea.String(mdl.LoadBalancerAttribute.AddressType)
+	// the eips of the zones are bound when the nlb turns to internet
+	if mdl.LoadBalancerAttribute.AddressType == nlbmodel.InternetAddressType {
+		for _, z := range mdl.LoadBalancerAttribute.ZoneMappings {
+			if z.AllocationId == "" {
+				continue
+			}
+			req.ZoneMappings = append(req.ZoneMappings, &nlb.UpdateLoadBalancerAddressTypeConfigRequestZoneMappings{
+				AllocationId: tea.String(z.AllocationId),
+				EipType:      tea.String("Common"),
+				VSwitchId:    tea.String(z.VSwitchId),
+				ZoneId:       tea.String(z.ZoneId),
+			})
+		}
+	}
 
-	resp, err := p.auth.NLB.UpdateLoadBalancerAddressTypeConfig(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "UpdateLoadBalancerAddressTypeConfig", p.auth.NLB.UpdateLoadBalancerAddressTypeConfig, req)
 	if err != nil {
 		return util.SDKError("UpdateNLBAddressType", err)
 	}
@@ -226,7 +240,7 @@ func (p *NLBProvider) enableIPv6Internet(ctx context.Context, mdl *nlbmodel.Netw
 	req := &nlb.EnableLoadBalancerIpv6InternetRequest{}
 	req.LoadBalancerId = tea.String(mdl.LoadBalancerAttribute.LoadBalancerId)
 
-	resp, err := p.auth.NLB.EnableLoadBalancerIpv6Internet(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "EnableLoadBalancerIpv6Internet", p.auth.NLB.EnableLoadBalancerIpv6Internet, req)
 	if err != nil {
 		return util.SDKError("EnableLoadBalancerIpv6Internet", err)
 	}
@@ -241,7 +255,7 @@ func (p *NLBProvider) disableIPv6Internet(ctx context.Context, mdl *nlbmodel.Net
 	req := &nlb.DisableLoadBalancerIpv6InternetRequest{}
 	req.LoadBalancerId = tea.String(mdl.LoadBalancerAttribute.LoadBalancerId)
 
-	resp, err := p.auth.NLB.DisableLoadBalancerIpv6Internet(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "DisableLoadBalancerIpv6Internet", p.auth.NLB.DisableLoadBalancerIpv6Internet, req)
 	if err != nil {
 		return util.SDKError("DisableLoadBalancerIpv6Internet", err)
 	}
@@ -270,7 +284,7 @@ func (p *NLBProvider) UpdateNLBZones(ctx context.Context, mdl *nlbmodel.NetworkL
 		req.ZoneMappings = append(req.ZoneMappings, zoneMapping)
 	}
 
-	resp, err := p.auth.NLB.UpdateLoadBalancerZones(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "UpdateLoadBalancerZones", p.auth.NLB.UpdateLoadBalancerZones, req)
 	if err != nil {
 		return util.SDKError("UpdateLoadBalancerZones", err)
 	}
@@ -288,7 +302,7 @@ func (p *NLBProvider) UpdateNLBSecurityGroupIds(ctx context.Context, mdl *nlbmod
 		req := &nlb.LoadBalancerLeaveSecurityGroupRequest{}
 		req.LoadBalancerId = tea.String(mdl.LoadBalancerAttribute.LoadBalancerId)
 		req.SecurityGroupIds = tea.StringSlice(removed)
-		resp, err := p.auth.NLB.LoadBalancerLeaveSecurityGroup(req)
+		resp, err := base.CallOpenAPI(base.ProductNLB, "LoadBalancerLeaveSecurityGroup", p.auth.NLB.LoadBalancerLeaveSecurityGroup, req)
 		if err != nil {
 			return util.SDKError("LoadBalancerLeaveSecurityGroup", err)
 		}
@@ -307,7 +321,7 @@ func (p *NLBProvider) UpdateNLBSecurityGroupIds(ctx context.Context, mdl *nlbmod
 		req := &nlb.LoadBalancerJoinSecurityGroupRequest{}
 		req.LoadBalancerId = tea.String(mdl.LoadBalancerAttribute.LoadBalancerId)
 		req.SecurityGroupIds = tea.StringSlice(added)
-		resp, err := p.auth.NLB.LoadBalancerJoinSecurityGroup(req)
+		resp, err := base.CallOpenAPI(base.ProductNLB, "LoadBalancerJoinSecurityGroup", p.auth.NLB.LoadBalancerJoinSecurityGroup, req)
 		if err != nil {
 			return util.SDKError("LoadBalancerJoinSecurityGroup", err)
 		}
@@ -342,7 +356,7 @@ func (p *NLBProvider) UpdateLoadBalancerProtection(ctx context.Context, lbId str
 		}
 	}
 
-	resp, err := p.auth.NLB.UpdateLoadBalancerProtection(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "UpdateLoadBalancerProtection", p.auth.NLB.UpdateLoadBalancerProtection, req)
 	if err != nil {
 		return util.SDKError("UpdateLoadBalancerProtection", err)
 	}
@@ -359,7 +373,7 @@ func (p *NLBProvider) AttachCommonBandwidthPackageToLoadBalancer(ctx context.Con
 	req.LoadBalancerId = tea.String(lbId)
 	req.BandwidthPackageId = tea.String(bandwidthPackageId)
 
-	resp, err := p.auth.NLB.AttachCommonBandwidthPackageToLoadBalancer(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "AttachCommonBandwidthPackageToLoadBalancer", p.auth.NLB.AttachCommonBandwidthPackageToLoadBalancer, req)
 	if err != nil {
 		return util.SDKError("AttachCommonBandwidthPackageToLoadBalancer", err)
 	}
@@ -376,7 +390,7 @@ func (p *NLBProvider) DetachCommonBandwidthPackageFromLoadBalancer(ctx context.C
 	req.LoadBalancerId = tea.String(lbId)
 	req.BandwidthPackageId = tea.String(bandwidthPackageId)
 
-	resp, err := p.auth.NLB.DetachCommonBandwidthPackageFromLoadBalancer(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "DetachCommonBandwidthPackageFromLoadBalancer", p.auth.NLB.DetachCommonBandwidthPackageFromLoadBalancer, req)
 	if err != nil {
 		return util.SDKError("DetachCommonBandwidthPackageFromLoadBalancer", err)
 	}
@@ -405,7 +419,7 @@ func (p *NLBProvider) TagNLBResource(ctx context.Context, resourceId string, res
 		})
 	}
 
-	resp, err := p.auth.NLB.TagResources(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "TagResources", p.auth.NLB.TagResources, req)
 	if err != nil {
 		return util.SDKError("TagResources", err)
 	}
@@ -421,7 +435,7 @@ func (p *NLBProvider) ListNLBTagResources(ctx context.Context, lbId string) ([]t
 	req.ResourceType = tea.String("loadbalancer")
 	req.ResourceId = []*string{tea.String(lbId)}
 
-	resp, err := p.auth.NLB.ListTagResources(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "ListTagResources", p.auth.NLB.ListTagResources, req)
 	if err != nil {
 		return nil, fmt.Errorf("list nlb %s tag error: %s", lbId, util.SDKError("ListTagResources", err))
 	}
@@ -453,7 +467,7 @@ func (p *NLBProvider) findNLBByTag(mdl *nlbmodel.NetworkLoadBalancer) error {
 			},
 		)
 	}
-	resp, err := p.auth.NLB.ListLoadBalancers(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "ListLoadBalancers", p.auth.NLB.ListLoadBalancers, req)
 	if err != nil {
 		return fmt.Errorf("[%s] find nlb by tag error: %s", mdl.NamespacedName, util.SDKError("ListLoadBalancers", err))
 	}
@@ -507,7 +521,7 @@ func (p *NLBProvider) findNLBByName(mdl *nlbmodel.NetworkLoadBalancer) error {
 		mdl.NamespacedName, mdl.LoadBalancerAttribute.Name)
 	req := &nlb.ListLoadBalancersRequest{}
 	req.LoadBalancerNames = []*string{tea.String(mdl.LoadBalancerAttribute.Name)}
-	resp, err := p.auth.NLB.ListLoadBalancers(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "ListLoadBalancers", p.auth.NLB.ListLoadBalancers, req)
 	if err != nil {
 		return fmt.Errorf("[%s] find loadbalancer by name %s error: %s", mdl.NamespacedName,
 			mdl.LoadBalancerAttribute.Name, util.SDKError("ListLoadBalancers", err))
@@ -559,12 +573,14 @@ func loadResponse(resp interface{}, lb *nlbmodel.NetworkLoadBalancer) error {
 		lb.LoadBalancerAttribute.BandwidthPackageId = resp.BandwidthPackageId
 
 		for _, z := range resp.ZoneMappings {
-			lb.LoadBalancerAttribute.ZoneMappings = append(lb.LoadBalancerAttribute.ZoneMappings,
-				nlbmodel.ZoneMapping{
-					ZoneId:    tea.StringValue(z.ZoneId),
-					VSwitchId: tea.StringValue(z.VSwitchId),
-				},
-			)
+			zoneMapping := nlbmodel.ZoneMapping{
+				ZoneId:    tea.StringValue(z.ZoneId),
+				VSwitchId: tea.StringValue(z.VSwitchId),
+			}
+			if len(z.LoadBalancerAddresses) != 0 {
+				zoneMapping.AllocationId = tea.StringValue(z.LoadBalancerAddresses[0].AllocationId)
+			}
+			lb.LoadBalancerAttribute.ZoneMappings = append(lb.LoadBalancerAttribute.ZoneMappings, zoneMapping)
 		}
 
 		var tags []tag.Tag
@@ -590,12 +606,14 @@ func loadResponse(resp interface{}, lb *nlbmodel.NetworkLoadBalancer) error {
 		lb.LoadBalancerAttribute.BandwidthPackageId = resp.BandwidthPackageId
 
 		for _, z := range resp.ZoneMappings {
-			lb.LoadBalancerAttribute.ZoneMappings = append(lb.LoadBalancerAttribute.ZoneMappings,
-				nlbmodel.ZoneMapping{
-					ZoneId:    tea.StringValue(z.ZoneId),
-					VSwitchId: tea.StringValue(z.VSwitchId),
-				},
-			)
+			zoneMapping := nlbmodel.ZoneMapping{
+				ZoneId:    tea.StringValue(z.ZoneId),
+				VSwitchId: tea.StringValue(z.VSwitchId),
+			}
+			if len(z.LoadBalancerAddresses) != 0 {
+				zoneMapping.AllocationId = tea.StringValue(z.LoadBalancerAddresses[0].AllocationId)
+			}
+			lb.LoadBalancerAttribute.ZoneMappings = append(lb.LoadBalancerAttribute.ZoneMappings, zoneMapping)
 		}
 
 		var tags []tag.Tag
@@ -634,7 +652,7 @@ func (p *NLBProvider) waitJobFinish(api, jobId string, args ...time.Duration) er
 	_ = wait.PollImmediate(interval, timeout, func() (bool, error) {
 		req := &nlb.GetJobStatusRequest{}
 		req.JobId = tea.String(jobId)
-		resp, retErr = p.auth.NLB.GetJobStatus(req)
+		resp, retErr = base.CallOpenAPI(base.ProductNLB, "GetJobStatus", p.auth.NLB.GetJobStatus, req)
 		if retErr != nil {
 			retErr = util.SDKError(fmt.Sprintf("%s-GetJobStatus", api), retErr)
 			return false, retErr
@@ -671,7 +689,7 @@ func (p *NLBProvider) BatchWaitJobsFinish(ctx context.Context, api string, jobId
 		for _, j := range currentJobs {
 			req := &nlb.GetJobStatusRequest{}
 			req.JobId = tea.String(j)
-			resp, retErr := p.auth.NLB.GetJobStatus(req)
+			resp, retErr := base.CallOpenAPI(base.ProductNLB, "GetJobStatus", p.auth.NLB.GetJobStatus, req)
 			if retErr != nil {
 				errs = append(errs, util.SDKError(fmt.Sprintf("%s-GetJobStatus", api), retErr))
 				continue
@@ -713,7 +731,7 @@ func (p *NLBProvider) waitNLBActive(lbId string) (*nlb.GetLoadBalancerAttributeR
 		req := &nlb.GetLoadBalancerAttributeRequest{}
 		req.LoadBalancerId = tea.String(lbId)
 
-		resp, retErr = p.auth.NLB.GetLoadBalancerAttribute(req)
+		resp, retErr = base.CallOpenAPI(base.ProductNLB, "GetLoadBalancerAttribute", p.auth.NLB.GetLoadBalancerAttribute, req)
 		if retErr != nil {
 			retErr = util.SDKError("GetLoadBalancerAttribute", retErr)
 			return false, retErr
@@ -741,7 +759,7 @@ func (p *NLBProvider) waitNLBActive(lbId string) (*nlb.GetLoadBalancerAttributeR
 func (p *NLBProvider) NLBRegionIds() ([]string, error) {
 	req := &nlb.DescribeRegionsRequest{}
 
-	resp, err := p.auth.NLB.DescribeRegions(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "DescribeRegions", p.auth.NLB.DescribeRegions, req)
 	if err != nil {
 		return nil, fmt.Errorf("describe nlb regions error: %s", err.Error())
 	}
@@ -765,7 +783,7 @@ func (p *NLBProvider) NLBZoneIds(regionId string) ([]string, error) {
 	req := &nlb.DescribeZonesRequest{}
 	req.RegionId = tea.String(regionId)
 
-	resp, err := p.auth.NLB.DescribeZones(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "DescribeZones", p.auth.NLB.DescribeZones, req)
 	if err != nil {
 		return nil, fmt.Errorf("describe nlb zones error: %s", err.Error())
 	}
@@ -790,7 +808,7 @@ func (p *NLBProvider) UntagNLBResources(ctx context.Context, resourceId string,
 	req.ResourceType = tea.String(string(resourceType))
 	req.TagKey = tagKey
 
-	resp, err := p.auth.NLB.UntagResources(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "UntagResources", p.auth.NLB.UntagResources, req)
 	if err != nil {
 		return util.SDKError("UntagResources", err)
 	}
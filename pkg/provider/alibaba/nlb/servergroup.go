@@ -10,6 +10,7 @@ import (
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/parallel"
 	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
 	"k8s.io/klog/v2"
 	"time"
@@ -25,7 +26,7 @@ func (p *NLBProvider) toLocalServerGroup(ctx context.Context, remote *nlb.ListSe
 		Scheduler:               tea.StringValue(remote.Scheduler),
 		Protocol:                tea.StringValue(remote.Protocol),
 		ConnectionDrainEnabled:  remote.ConnectionDrainEnabled,
-		ConnectionDrainTimeout:  tea.Int32Value(remote.ConnectionDrainTimeout),
+		ConnectionDrainTimeout:  remote.ConnectionDrainTimeout,
 		ResourceGroupId:         tea.StringValue(remote.ResourceGroupId),
 		PreserveClientIpEnabled: remote.PreserveClientIpEnabled,
 		AnyPortEnabled:          tea.BoolValue(remote.AnyPortEnabled),
@@ -80,7 +81,7 @@ func (p *NLBProvider) ListNLBServerGroups(ctx context.Context, tags []tag.Tag) (
 				Value: tea.String(t.Value),
 			})
 		}
-		resp, err := p.auth.NLB.ListServerGroups(req)
+		resp, err := base.CallOpenAPI(base.ProductNLB, "ListServerGroups", p.auth.NLB.ListServerGroups, req)
 		if err != nil {
 			return nil, util.SDKError("ListServerGroups", err)
 		}
@@ -141,7 +142,7 @@ func (p *NLBProvider) parallelListNLBServers(ctx context.Context, sgId []string)
 func (p *NLBProvider) GetNLBServerGroup(ctx context.Context, sgId string) (*nlbmodel.ServerGroup, error) {
 	req := &nlb.ListServerGroupsRequest{}
 	req.ServerGroupIds = []*string{tea.String(sgId)}
-	resp, err := p.auth.NLB.ListServerGroups(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "ListServerGroups", p.auth.NLB.ListServerGroups, req)
 	if err != nil {
 		return nil, util.SDKError("ListServerGroups", err)
 	}
@@ -216,7 +217,7 @@ func (p *NLBProvider) ListNLBServers(ctx context.Context, sgId string) ([]nlbmod
 		req.MaxResults = tea.Int32(100)
 		req.NextToken = tea.String(nextToken)
 
-		resp, err := p.auth.NLB.ListServerGroupServers(req)
+		resp, err := base.CallOpenAPI(base.ProductNLB, "ListServerGroupServers", p.auth.NLB.ListServerGroupServers, req)
 		if err != nil {
 			return nil, util.SDKError("ListServerGroupServers", err)
 		}
@@ -266,7 +267,7 @@ func (p *NLBProvider) CreateNLBServerGroupAsync(ctx context.Context, sg *nlbmode
 	}
 	if sg.ConnectionDrainEnabled != nil {
 		req.ConnectionDrainEnabled = sg.ConnectionDrainEnabled
-		req.ConnectionDrainTimeout = tea.Int32(sg.ConnectionDrainTimeout)
+		req.ConnectionDrainTimeout = sg.ConnectionDrainTimeout
 	}
 	if sg.Scheduler != "" {
 		req.Scheduler = tea.String(sg.Scheduler)
@@ -320,7 +321,7 @@ func (p *NLBProvider) CreateNLBServerGroupAsync(ctx context.Context, sg *nlbmode
 		}
 	}
 
-	resp, err := p.auth.NLB.CreateServerGroup(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "CreateServerGroup", p.auth.NLB.CreateServerGroup, req)
 	if err != nil {
 		return "", util.SDKError("CreateServerGroup", err)
 	}
@@ -336,7 +337,7 @@ func (p *NLBProvider) CreateNLBServerGroupAsync(ctx context.Context, sg *nlbmode
 func (p *NLBProvider) DeleteNLBServerGroupAsync(ctx context.Context, sgId string) (string, error) {
 	req := &nlb.DeleteServerGroupRequest{}
 	req.ServerGroupId = tea.String(sgId)
-	resp, err := p.auth.NLB.DeleteServerGroup(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "DeleteServerGroup", p.auth.NLB.DeleteServerGroup, req)
 	if err != nil {
 		return "", util.SDKError("DeleteServerGroup", err)
 	}
@@ -357,7 +358,7 @@ func (p *NLBProvider) UpdateNLBServerGroupAsync(ctx context.Context, sg *nlbmode
 	}
 	if sg.ConnectionDrainEnabled != nil {
 		req.ConnectionDrainEnabled = sg.ConnectionDrainEnabled
-		req.ConnectionDrainTimeout = tea.Int32(sg.ConnectionDrainTimeout)
+		req.ConnectionDrainTimeout = sg.ConnectionDrainTimeout
 	}
 	if sg.Scheduler != "" {
 		req.Scheduler = tea.String(sg.Scheduler)
@@ -402,7 +403,7 @@ func (p *NLBProvider) UpdateNLBServerGroupAsync(ctx context.Context, sg *nlbmode
 		}
 	}
 
-	resp, err := p.auth.NLB.UpdateServerGroupAttribute(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "UpdateServerGroupAttribute", p.auth.NLB.UpdateServerGroupAttribute, req)
 	if err != nil {
 		return "", util.SDKError("UpdateServerGroupAttribute", err)
 	}
@@ -430,7 +431,7 @@ func (p *NLBProvider) AddNLBServersAsync(ctx context.Context, sgId string, backe
 		req.Servers = append(req.Servers, reqServer)
 	}
 
-	resp, err := p.auth.NLB.AddServersToServerGroup(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "AddServersToServerGroup", p.auth.NLB.AddServersToServerGroup, req)
 	if err != nil {
 		return "", util.SDKError("AddServersToServerGroup", err)
 	}
@@ -457,7 +458,7 @@ func (p *NLBProvider) RemoveNLBServersAsync(ctx context.Context, sgId string, ba
 		req.Servers = append(req.Servers, reqServer)
 	}
 
-	resp, err := p.auth.NLB.RemoveServersFromServerGroup(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "RemoveServersFromServerGroup", p.auth.NLB.RemoveServersFromServerGroup, req)
 	if err != nil {
 		return "", util.SDKError("RemoveServersFromServerGroup", err)
 	}
@@ -487,7 +488,7 @@ func (p *NLBProvider) UpdateNLBServersAsync(ctx context.Context, sgId string, ba
 		req.Servers = append(req.Servers, reqServer)
 	}
 
-	resp, err := p.auth.NLB.UpdateServerGroupServersAttribute(req)
+	resp, err := base.CallOpenAPI(base.ProductNLB, "UpdateServerGroupServersAttribute", p.auth.NLB.UpdateServerGroupServersAttribute, req)
 	if err != nil {
 		return "", util.SDKError("UpdateServerGroupServersAttribute", err)
 	}
@@ -39,7 +39,7 @@ func TestNLBProvider_ListNLBServerGroups(t *testing.T) {
 			AddressIPVersion:       *ret.AddressIPVersion,
 			Scheduler:              *ret.Scheduler,
 			ConnectionDrainEnabled: ret.ConnectionDrainEnabled,
-			ConnectionDrainTimeout: *ret.ConnectionDrainTimeout,
+			ConnectionDrainTimeout: ret.ConnectionDrainTimeout,
 			ResourceGroupId:        *ret.ResourceGroupId,
 		}
 
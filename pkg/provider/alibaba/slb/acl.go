@@ -0,0 +1,143 @@
+package slb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/slb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// MaxAclEntriesPerRequest is the max count of the entries added or removed by one request
+	MaxAclEntriesPerRequest = 50
+	aclPageSize             = 50
+)
+
+func (p SLBProvider) ListCLBAccessControlLists(ctx context.Context, tags []tag.Tag) ([]model.AccessControlList, error) {
+	req := slb.CreateDescribeAccessControlListsRequest()
+	var reqTags []slb.DescribeAccessControlListsTag
+	for _, t := range tags {
+		reqTags = append(reqTags, slb.DescribeAccessControlListsTag{Key: t.Key, Value: t.Value})
+	}
+	req.Tag = &reqTags
+	req.PageSize = requests.NewInteger(aclPageSize)
+
+	var acls []model.AccessControlList
+	for page := 1; ; page++ {
+		req.PageNumber = requests.NewInteger(page)
+		resp, err := p.auth.SLB.DescribeAccessControlLists(req)
+		if err != nil {
+			return nil, util.SDKError("DescribeAccessControlLists", err)
+		}
+		klog.V(5).Infof("RequestId: %s, API: %s, page: %d", resp.RequestId, "DescribeAccessControlLists", page)
+		for _, a := range resp.Acls.Acl {
+			acl := model.AccessControlList{
+				AclId:            a.AclId,
+				AclName:          a.AclName,
+				AddressIPVersion: a.AddressIPVersion,
+			}
+			for _, t := range a.Tags.Tag {
+				acl.Tags = append(acl.Tags, tag.Tag{Key: t.TagKey, Value: t.TagValue})
+			}
+			acls = append(acls, acl)
+		}
+		if len(resp.Acls.Acl) < aclPageSize || page*aclPageSize >= resp.TotalCount {
+			break
+		}
+	}
+	return acls, nil
+}
+
+func (p SLBProvider) CreateCLBAccessControlList(ctx context.Context, acl *model.AccessControlList) error {
+	req := slb.CreateCreateAccessControlListRequest()
+	req.AclName = acl.AclName
+	req.AddressIPVersion = acl.AddressIPVersion
+	var reqTags []slb.CreateAccessControlListTag
+	for _, t := range acl.Tags {
+		reqTags = append(reqTags, slb.CreateAccessControlListTag{Key: t.Key, Value: t.Value})
+	}
+	req.Tag = &reqTags
+	resp, err := p.auth.SLB.CreateAccessControlList(req)
+	if err != nil {
+		return util.SDKError("CreateAccessControlList", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s, aclName: %s", resp.RequestId, "CreateAccessControlList", acl.AclName)
+	acl.AclId = resp.AclId
+	return nil
+}
+
+func (p SLBProvider) DeleteCLBAccessControlList(ctx context.Context, aclId string) error {
+	req := slb.CreateDeleteAccessControlListRequest()
+	req.AclId = aclId
+	_, err := p.auth.SLB.DeleteAccessControlList(req)
+	if err != nil {
+		return util.SDKError("DeleteAccessControlList", err)
+	}
+	return nil
+}
+
+func (p SLBProvider) DescribeCLBAccessControlListEntries(ctx context.Context, aclId string) ([]model.AclEntry, error) {
+	req := slb.CreateDescribeAccessControlListAttributeRequest()
+	req.AclId = aclId
+	req.PageSize = requests.NewInteger(aclPageSize)
+
+	var entries []model.AclEntry
+	for page := 1; ; page++ {
+		req.Page = requests.NewInteger(page)
+		resp, err := p.auth.SLB.DescribeAccessControlListAttribute(req)
+		if err != nil {
+			return nil, util.SDKError("DescribeAccessControlListAttribute", err)
+		}
+		for _, e := range resp.AclEntrys.AclEntry {
+			entries = append(entries, model.AclEntry{Entry: e.AclEntryIP, Comment: e.AclEntryComment})
+		}
+		if len(resp.AclEntrys.AclEntry) < aclPageSize || len(entries) >= resp.TotalAclEntry {
+			break
+		}
+	}
+	return entries, nil
+}
+
+func (p SLBProvider) AddCLBAccessControlListEntries(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	return batchAclEntries(entries, func(batch string) error {
+		req := slb.CreateAddAccessControlListEntryRequest()
+		req.AclId = aclId
+		req.AclEntrys = batch
+		_, err := p.auth.SLB.AddAccessControlListEntry(req)
+		return util.SDKError("AddAccessControlListEntry", err)
+	})
+}
+
+func (p SLBProvider) RemoveCLBAccessControlListEntries(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	return batchAclEntries(entries, func(batch string) error {
+		req := slb.CreateRemoveAccessControlListEntryRequest()
+		req.AclId = aclId
+		req.AclEntrys = batch
+		_, err := p.auth.SLB.RemoveAccessControlListEntry(req)
+		return util.SDKError("RemoveAccessControlListEntry", err)
+	})
+}
+
+// batchAclEntries calls the api with the json of at most MaxAclEntriesPerRequest entries at a time.
+func batchAclEntries(entries []model.AclEntry, call func(batch string) error) error {
+	for begin := 0; begin < len(entries); begin += MaxAclEntriesPerRequest {
+		end := begin + MaxAclEntriesPerRequest
+		if end > len(entries) {
+			end = len(entries)
+		}
+		batch, err := json.Marshal(entries[begin:end])
+		if err != nil {
+			return fmt.Errorf("marshal acl entries error: %s", err.Error())
+		}
+		if err := call(string(batch)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -34,6 +34,48 @@ func (p SLBProvider) DescribeServerCertificateById(ctx context.Context, serverCe
 
 }
 
+// ListServerCertificates lists all the server certificates in the region
+func (p SLBProvider) ListServerCertificates(ctx context.Context) ([]model.CertAttribute, error) {
+	req := slb.CreateDescribeServerCertificatesRequest()
+	resp, err := p.auth.SLB.DescribeServerCertificates(req)
+	if err != nil {
+		return nil, util.SDKError("DescribeServerCertificates", err)
+	}
+	var certs []model.CertAttribute
+	for _, cert := range resp.ServerCertificates.ServerCertificate {
+		certs = append(certs, model.CertAttribute{
+			CreateTimeStamp:       cert.CreateTimeStamp,
+			ExpireTimeStamp:       cert.ExpireTimeStamp,
+			ServerCertificateId:   cert.ServerCertificateId,
+			ServerCertificateName: cert.ServerCertificateName,
+			CommonName:            cert.CommonName,
+		})
+	}
+	return certs, nil
+}
+
+func (p SLBProvider) UploadServerCertificate(ctx context.Context, certName, cert, key string) (string, error) {
+	req := slb.CreateUploadServerCertificateRequest()
+	req.ServerCertificateName = certName
+	req.ServerCertificate = cert
+	req.PrivateKey = key
+	resp, err := p.auth.SLB.UploadServerCertificate(req)
+	if err != nil {
+		return "", util.SDKError("UploadServerCertificate", err)
+	}
+	return resp.ServerCertificateId, nil
+}
+
+func (p SLBProvider) DeleteServerCertificate(ctx context.Context, serverCertificateId string) error {
+	req := slb.CreateDeleteServerCertificateRequest()
+	req.ServerCertificateId = serverCertificateId
+	_, err := p.auth.SLB.DeleteServerCertificate(req)
+	if err != nil {
+		return util.SDKError("DeleteServerCertificate", err)
+	}
+	return nil
+}
+
 // DescribeServerCertificates used for e2etest
 func (p SLBProvider) DescribeServerCertificates(ctx context.Context) ([]string, error) {
 	req := slb.CreateDescribeServerCertificatesRequest()
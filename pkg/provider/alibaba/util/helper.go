@@ -68,9 +68,22 @@ func SDKError(api string, err error) error {
 			api, tea.IntValue(err.StatusCode), tea.StringValue(err.Code), attr[1], attr[0]))
 		return err
 	case *errors.ServerError:
-		return fmt.Errorf("[SDKError] API: %s, ErrorCode: %s, RequestId: %s, Message: %s",
-			api, err.ErrorCode(), err.RequestId(), err.Message())
+		return &serverError{
+			msg: fmt.Sprintf("[SDKError] API: %s, ErrorCode: %s, RequestId: %s, Message: %s",
+				api, err.ErrorCode(), err.RequestId(), err.Message()),
+			err: err,
+		}
 	default:
 		return err
 	}
 }
+
+// serverError formats the server error of the sdk, which is kept for the callers checking the error code.
+type serverError struct {
+	msg string
+	err *errors.ServerError
+}
+
+func (e *serverError) Error() string { return e.msg }
+
+func (e *serverError) Unwrap() error { return e.err }
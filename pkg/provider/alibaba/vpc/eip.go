@@ -0,0 +1,208 @@
+package vpc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+	"k8s.io/klog/v2"
+)
+
+const (
+	eipPageSize           = 50
+	eipResourceType       = "EIP"
+	eipStatusAvailable    = "Available"
+	eipStatusPollInterval = 2 * time.Second
+	eipStatusPollTimeout  = 1 * time.Minute
+)
+
+func (r *VPCProvider) ListEipAddresses(ctx context.Context, tags []tag.Tag) ([]model.EipAddress, error) {
+	req := vpc.CreateDescribeEipAddressesRequest()
+	var reqTags []vpc.DescribeEipAddressesTag
+	for _, t := range tags {
+		reqTags = append(reqTags, vpc.DescribeEipAddressesTag{Key: t.Key, Value: t.Value})
+	}
+	req.Tag = &reqTags
+	req.PageSize = requests.NewInteger(eipPageSize)
+
+	var eips []model.EipAddress
+	for page := 1; ; page++ {
+		req.PageNumber = requests.NewInteger(page)
+		resp, err := r.auth.VPC.DescribeEipAddresses(req)
+		if err != nil {
+			return nil, util.SDKError("DescribeEipAddresses", err)
+		}
+		klog.V(5).Infof("RequestId: %s, API: %s, page: %d", resp.RequestId, "DescribeEipAddresses", page)
+		for _, e := range resp.EipAddresses.EipAddress {
+			eips = append(eips, toEipAddress(e))
+		}
+		if len(resp.EipAddresses.EipAddress) < eipPageSize || page*eipPageSize >= resp.TotalCount {
+			break
+		}
+	}
+	return eips, nil
+}
+
+// AllocateEipAddress allocates a pay-as-you-go eip and tags it, the eip is released if it fails to be tagged,
+// as it could not be found by the tags afterwards. It returns when the eip is available.
+func (r *VPCProvider) AllocateEipAddress(ctx context.Context, eip *model.EipAddress) error {
+	req := vpc.CreateAllocateEipAddressRequest()
+	req.Name = eip.Name
+	req.Description = eip.Description
+	req.Bandwidth = strconv.Itoa(eip.Bandwidth)
+	req.ISP = eip.ISP
+	req.InternetChargeType = eip.InternetChargeType
+	req.InstanceChargeType = "PostPaid"
+	resp, err := r.auth.VPC.AllocateEipAddress(req)
+	if err != nil {
+		return util.SDKError("AllocateEipAddress", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s, eipName: %s", resp.RequestId, "AllocateEipAddress", eip.Name)
+	eip.AllocationId = resp.AllocationId
+	eip.IpAddress = resp.EipAddress
+
+	if len(eip.Tags) != 0 {
+		tagReq := vpc.CreateTagResourcesRequest()
+		tagReq.ResourceType = eipResourceType
+		tagReq.ResourceId = &[]string{eip.AllocationId}
+		var reqTags []vpc.TagResourcesTag
+		for _, t := range eip.Tags {
+			reqTags = append(reqTags, vpc.TagResourcesTag{Key: t.Key, Value: t.Value})
+		}
+		tagReq.Tag = &reqTags
+		if _, err := r.auth.VPC.TagResources(tagReq); err != nil {
+			if rerr := r.ReleaseEipAddress(ctx, eip.AllocationId); rerr != nil {
+				klog.Errorf("release untagged eip %s error: %s", eip.AllocationId, rerr.Error())
+			}
+			return util.SDKError("TagResources", err)
+		}
+	}
+	return r.waitEipAvailable(eip.AllocationId)
+}
+
+func (r *VPCProvider) ReleaseEipAddress(ctx context.Context, allocationId string) error {
+	req := vpc.CreateReleaseEipAddressRequest()
+	req.AllocationId = allocationId
+	resp, err := r.auth.VPC.ReleaseEipAddress(req)
+	if err != nil {
+		return util.SDKError("ReleaseEipAddress", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s, allocationId: %s", resp.RequestId, "ReleaseEipAddress", allocationId)
+	return nil
+}
+
+func (r *VPCProvider) ModifyEipAddressBandwidth(ctx context.Context, allocationId string, bandwidth int) error {
+	req := vpc.CreateModifyEipAddressAttributeRequest()
+	req.AllocationId = allocationId
+	req.Bandwidth = strconv.Itoa(bandwidth)
+	resp, err := r.auth.VPC.ModifyEipAddressAttribute(req)
+	if err != nil {
+		return util.SDKError("ModifyEipAddressAttribute", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s, allocationId: %s", resp.RequestId, "ModifyEipAddressAttribute", allocationId)
+	return nil
+}
+
+func (r *VPCProvider) AssociateEipAddress(ctx context.Context, allocationId, instanceType, instanceId string) error {
+	req := vpc.CreateAssociateEipAddressRequest()
+	req.AllocationId = allocationId
+	req.InstanceType = instanceType
+	req.InstanceId = instanceId
+	resp, err := r.auth.VPC.AssociateEipAddress(req)
+	if err != nil {
+		return util.SDKError("AssociateEipAddress", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s, allocationId: %s, instanceId: %s",
+		resp.RequestId, "AssociateEipAddress", allocationId, instanceId)
+	return nil
+}
+
+// UnassociateEipAddress returns when the eip is available, so that it can be released or associated again.
+func (r *VPCProvider) UnassociateEipAddress(ctx context.Context, allocationId, instanceType, instanceId string) error {
+	req := vpc.CreateUnassociateEipAddressRequest()
+	req.AllocationId = allocationId
+	req.InstanceType = instanceType
+	req.InstanceId = instanceId
+	resp, err := r.auth.VPC.UnassociateEipAddress(req)
+	if err != nil {
+		return util.SDKError("UnassociateEipAddress", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s, allocationId: %s, instanceId: %s",
+		resp.RequestId, "UnassociateEipAddress", allocationId, instanceId)
+	return r.waitEipAvailable(allocationId)
+}
+
+func (r *VPCProvider) AddCommonBandwidthPackageIp(ctx context.Context, bandwidthPackageId, allocationId string) error {
+	req := vpc.CreateAddCommonBandwidthPackageIpRequest()
+	req.BandwidthPackageId = bandwidthPackageId
+	req.IpInstanceId = allocationId
+	req.IpType = eipResourceType
+	resp, err := r.auth.VPC.AddCommonBandwidthPackageIp(req)
+	if err != nil {
+		return util.SDKError("AddCommonBandwidthPackageIp", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s, bandwidthPackageId: %s, allocationId: %s",
+		resp.RequestId, "AddCommonBandwidthPackageIp", bandwidthPackageId, allocationId)
+	return nil
+}
+
+func (r *VPCProvider) RemoveCommonBandwidthPackageIp(ctx context.Context, bandwidthPackageId, allocationId string) error {
+	req := vpc.CreateRemoveCommonBandwidthPackageIpRequest()
+	req.BandwidthPackageId = bandwidthPackageId
+	req.IpInstanceId = allocationId
+	resp, err := r.auth.VPC.RemoveCommonBandwidthPackageIp(req)
+	if err != nil {
+		return util.SDKError("RemoveCommonBandwidthPackageIp", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s, bandwidthPackageId: %s, allocationId: %s",
+		resp.RequestId, "RemoveCommonBandwidthPackageIp", bandwidthPackageId, allocationId)
+	return nil
+}
+
+func (r *VPCProvider) waitEipAvailable(allocationId string) error {
+	req := vpc.CreateDescribeEipAddressesRequest()
+	req.AllocationId = allocationId
+	var status string
+	err := wait.PollImmediate(eipStatusPollInterval, eipStatusPollTimeout, func() (bool, error) {
+		resp, err := r.auth.VPC.DescribeEipAddresses(req)
+		if err != nil {
+			return false, util.SDKError("DescribeEipAddresses", err)
+		}
+		if len(resp.EipAddresses.EipAddress) == 0 {
+			return false, fmt.Errorf("eip %s not found", allocationId)
+		}
+		status = resp.EipAddresses.EipAddress[0].Status
+		return status == eipStatusAvailable, nil
+	})
+	if err != nil {
+		return fmt.Errorf("wait eip %s to be available, status %s: %s", allocationId, status, err.Error())
+	}
+	return nil
+}
+
+func toEipAddress(e vpc.EipAddress) model.EipAddress {
+	bandwidth, _ := strconv.Atoi(e.Bandwidth)
+	eip := model.EipAddress{
+		AllocationId:       e.AllocationId,
+		IpAddress:          e.IpAddress,
+		Name:               e.Name,
+		Description:        e.Description,
+		Bandwidth:          bandwidth,
+		ISP:                e.ISP,
+		InternetChargeType: e.InternetChargeType,
+		BandwidthPackageId: e.BandwidthPackageId,
+		InstanceType:       e.InstanceType,
+		InstanceId:         e.InstanceId,
+	}
+	for _, t := range e.Tags.Tag {
+		eip.Tags = append(eip.Tags, tag.Tag{Key: t.Key, Value: t.Value})
+	}
+	return eip
+}
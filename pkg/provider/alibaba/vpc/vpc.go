@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
@@ -127,15 +128,16 @@ func (r *VPCProvider) CreateRoutes(ctx context.Context, table string, routes []*
 
 	var routeEntries []vpc.CreateRouteEntriesRouteEntries
 	for _, r := range routes {
-		_, ins, err := util.NodeFromProviderID(r.ProviderId)
+		hopType, hop, err := nextHop(r)
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid provider id: %v, err: %v", r.ProviderId, err)
+			return nil, nil, err
 		}
 		routeEntries = append(routeEntries, vpc.CreateRouteEntriesRouteEntries{
 			RouteTableId: table,
 			DstCidrBlock: r.DestinationCIDR,
-			NextHop:      ins,
-			NextHopType:  model.RouteNextHopTypeInstance,
+			NextHop:      hop,
+			NextHopType:  hopType,
+			Description:  r.Description,
 		})
 	}
 
@@ -152,12 +154,13 @@ func (r *VPCProvider) CreateRoutes(ctx context.Context, table string, routes []*
 	var statuses []prvd.RouteUpdateStatus
 	for _, r := range routes {
 		foundFailed := false
-		_, ins, err := util.NodeFromProviderID(r.ProviderId)
+		_, hop, err := nextHop(r)
 		if err != nil {
 			continue
 		}
 		for _, f := range resp.FailedRouteEntries {
-			if f.NextHop == ins {
+			// the aggregated routes share the next hop with the route of the gateway node
+			if f.NextHop == hop && (f.DstCidrBlock == "" || f.DstCidrBlock == r.DestinationCIDR) {
 				foundFailed = true
 				statuses = append(statuses, prvd.RouteUpdateStatus{
 					Route:         r,
@@ -206,14 +209,14 @@ func (r *VPCProvider) DeleteRoutes(ctx context.Context, table string, routes []*
 
 	var routeEntries []vpc.DeleteRouteEntriesRouteEntries
 	for _, r := range routes {
-		_, ins, err := util.NodeFromProviderID(r.ProviderId)
+		_, hop, err := nextHop(r)
 		if err != nil {
-			return nil, fmt.Errorf("invalid provider id: %v, err: %v", r.ProviderId, err)
+			return nil, err
 		}
 		routeEntries = append(routeEntries, vpc.DeleteRouteEntriesRouteEntries{
 			RouteTableId: table,
 			DstCidrBlock: r.DestinationCIDR,
-			NextHop:      ins,
+			NextHop:      hop,
 		})
 	}
 
@@ -231,12 +234,13 @@ func (r *VPCProvider) DeleteRoutes(ctx context.Context, table string, routes []*
 	var statuses []prvd.RouteUpdateStatus
 	for _, r := range routes {
 		foundFailed := false
-		_, ins, err := util.NodeFromProviderID(r.ProviderId)
+		_, hop, err := nextHop(r)
 		if err != nil {
 			continue
 		}
 		for _, f := range resp.FailedRouteEntries {
-			if f.NextHop == ins {
+			// the aggregated routes share the next hop with the route of the gateway node
+			if f.NextHop == hop && (f.DstCidrBlock == "" || f.DstCidrBlock == r.DestinationCIDR) {
 				foundFailed = true
 				statuses = append(statuses, prvd.RouteUpdateStatus{
 					Route:         r,
@@ -268,7 +272,11 @@ func (r *VPCProvider) ListRoute(ctx context.Context, table string) (routes []*mo
 
 func (r *VPCProvider) listRouteBatch(table, nextToken string, routes *[]*model.Route) error {
 	routeEntryListRequest := vpc.CreateDescribeRouteEntryListRequest()
-	routeEntryListRequest.NextHopType = model.RouteNextHopTypeInstance
+	// the aggregated routes may go through an eni, the routes of other next hops are listed only if
+	// route aggregation is configured
+	if len(ctrlCfg.CloudCFG.Global.RouteAggregations) == 0 {
+		routeEntryListRequest.NextHopType = model.RouteNextHopTypeInstance
+	}
 	routeEntryListRequest.RouteEntryType = model.RouteEntryTypeCustom
 	routeEntryListRequest.RouteTableId = table
 	routeEntryListRequest.NextToken = nextToken
@@ -289,13 +297,27 @@ func (r *VPCProvider) listRouteBatch(table, nextToken string, routes *[]*model.R
 		if e.Type != model.RouteEntryTypeCustom ||
 			// ECMP is not supported yet, skip next hop not equals 1
 			len(e.NextHops.NextHop) != 1 ||
-			// skip none Instance route
-			strings.ToLower(e.NextHops.NextHop[0].NextHopType) != "instance" ||
 			// skip DNAT route
 			e.DestinationCidrBlock == "0.0.0.0/0" {
 			continue
 		}
-		pvid, err := r.providerIDFromInstanceId(e.NextHops.NextHop[0].NextHopId)
+		hop := e.NextHops.NextHop[0]
+		// the aggregated routes may go through an eni
+		if strings.EqualFold(hop.NextHopType, model.RouteNextHopTypeNetworkInterface) {
+			*routes = append(*routes, &model.Route{
+				Name:            fmt.Sprintf("%s-%s", hop.NextHopId, e.DestinationCidrBlock),
+				DestinationCIDR: e.DestinationCidrBlock,
+				NextHopType:     model.RouteNextHopTypeNetworkInterface,
+				NextHopId:       hop.NextHopId,
+				Description:     e.Description,
+			})
+			continue
+		}
+		// skip none Instance route
+		if strings.ToLower(hop.NextHopType) != "instance" {
+			continue
+		}
+		pvid, err := r.providerIDFromInstanceId(hop.NextHopId)
 		if err != nil {
 			return err
 		}
@@ -303,6 +325,7 @@ func (r *VPCProvider) listRouteBatch(table, nextToken string, routes *[]*model.R
 			Name:            fmt.Sprintf("%s-%s", pvid, e.DestinationCidrBlock),
 			DestinationCIDR: e.DestinationCidrBlock,
 			ProviderId:      pvid,
+			Description:     e.Description,
 		}
 		*routes = append(*routes, route)
 	}
@@ -460,3 +483,15 @@ func (r *VPCProvider) DescribeRouteTableList(ctx context.Context, vpcId string)
 	}
 	return rtIds, nil
 }
+
+// nextHop returns the next hop type and id of the route, which is the instance of the provider id by default.
+func nextHop(route *model.Route) (string, string, error) {
+	if route.NextHopType == model.RouteNextHopTypeNetworkInterface {
+		return route.NextHopType, route.NextHopId, nil
+	}
+	_, ins, err := util.NodeFromProviderID(route.ProviderId)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid provider id: %v, err: %v", route.ProviderId, err)
+	}
+	return model.RouteNextHopTypeInstance, ins, nil
+}
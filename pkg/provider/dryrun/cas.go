@@ -3,6 +3,7 @@ package dryrun
 import (
 	"context"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
 
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
@@ -29,3 +30,27 @@ func (c DryRunCAS) DescribeSSLCertificatePublicKeyDetail(ctx context.Context, ce
 func (c DryRunCAS) DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
 	return c.cas.DescribeSSLCertificateList(ctx)
 }
+
+func (c DryRunCAS) DescribeSSLCertificateListByTags(ctx context.Context, tags []tag.Tag) ([]model.CertificateInfo, error) {
+	return c.cas.DescribeSSLCertificateListByTags(ctx, tags)
+}
+
+func (c DryRunCAS) UploadSSLCertificate(ctx context.Context, certName, cert, key string, tags []tag.Tag) (string, error) {
+	return "", nil
+}
+
+func (c DryRunCAS) DeleteSSLCertificate(ctx context.Context, certIdentifier string) error {
+	return nil
+}
+
+func (c DryRunCAS) UploadCACertificate(ctx context.Context, certName, cert string) (string, error) {
+	return "", nil
+}
+
+func (c DryRunCAS) DescribeCACertificateList(ctx context.Context, keyword string) ([]model.CertificateInfo, error) {
+	return c.cas.DescribeCACertificateList(ctx, keyword)
+}
+
+func (c DryRunCAS) DeleteCACertificate(ctx context.Context, certIdentifier string) error {
+	return nil
+}
@@ -2,10 +2,14 @@ package dryrun
 
 import (
 	"context"
+	"fmt"
+
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/ecs"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 )
 
 func NewDryRunECS(
@@ -41,3 +45,39 @@ func (d *DryRunECS) DescribeNetworkInterfacesByIDs(ids []string) ([]*prvd.EniAtt
 func (d *DryRunECS) ModifyNetworkInterfaceSourceDestCheck(id string, enabled bool) error {
 	panic("implement me!")
 }
+
+func (d *DryRunECS) ListSecurityGroups(ctx context.Context, tags []tag.Tag) ([]model.SecurityGroup, error) {
+	return d.ecs.ListSecurityGroups(ctx, tags)
+}
+
+func (d *DryRunECS) CreateSecurityGroup(ctx context.Context, sg *model.SecurityGroup) error {
+	mtype := "CreateSecurityGroup"
+	svc := getService(ctx)
+	AddEvent(ECS, util.Key(svc), sg.SecurityGroupName, "CreateSecurityGroup", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("security group %s should be created", sg.SecurityGroupName))
+}
+
+func (d *DryRunECS) DeleteSecurityGroup(ctx context.Context, sgId string) error {
+	mtype := "DeleteSecurityGroup"
+	svc := getService(ctx)
+	AddEvent(ECS, util.Key(svc), sgId, "DeleteSecurityGroup", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("security group %s should be deleted", sgId))
+}
+
+func (d *DryRunECS) DescribeSecurityGroupRules(ctx context.Context, sgId string) ([]model.SecurityGroupRule, error) {
+	return d.ecs.DescribeSecurityGroupRules(ctx, sgId)
+}
+
+func (d *DryRunECS) AuthorizeSecurityGroupRules(ctx context.Context, sgId string, rules []model.SecurityGroupRule) error {
+	mtype := "AuthorizeSecurityGroup"
+	svc := getService(ctx)
+	AddEvent(ECS, util.Key(svc), sgId, "AuthorizeSecurityGroup", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("%d rules should be authorized in security group %s", len(rules), sgId))
+}
+
+func (d *DryRunECS) RevokeSecurityGroupRules(ctx context.Context, sgId string, rules []model.SecurityGroupRule) error {
+	mtype := "RevokeSecurityGroup"
+	svc := getService(ctx)
+	AddEvent(ECS, util.Key(svc), sgId, "RevokeSecurityGroup", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("%d rules should be revoked in security group %s", len(rules), sgId))
+}
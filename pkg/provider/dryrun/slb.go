@@ -284,6 +284,60 @@ func (m *DryRunSLB) DescribeServerCertificateById(ctx context.Context, serverCer
 	return m.slb.DescribeServerCertificateById(ctx, serverCertificateId)
 }
 
+func (m *DryRunSLB) ListServerCertificates(ctx context.Context) ([]model.CertAttribute, error) {
+	return m.slb.ListServerCertificates(ctx)
+}
+
+func (m *DryRunSLB) UploadServerCertificate(ctx context.Context, certName, cert, key string) (string, error) {
+	mtype := "UploadServerCertificate"
+	svc := getService(ctx)
+	AddEvent(SLB, util.Key(svc), certName, "UploadServerCertificate", ERROR, "")
+	return "", hintError(mtype, fmt.Sprintf("server certificate %s should be uploaded", certName))
+}
+
+func (m *DryRunSLB) DeleteServerCertificate(ctx context.Context, serverCertificateId string) error {
+	mtype := "DeleteServerCertificate"
+	svc := getService(ctx)
+	AddEvent(SLB, util.Key(svc), serverCertificateId, "DeleteServerCertificate", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("server certificate %s should be deleted", serverCertificateId))
+}
+
+func (m *DryRunSLB) ListCLBAccessControlLists(ctx context.Context, tags []tag.Tag) ([]model.AccessControlList, error) {
+	return m.slb.ListCLBAccessControlLists(ctx, tags)
+}
+
+func (m *DryRunSLB) CreateCLBAccessControlList(ctx context.Context, acl *model.AccessControlList) error {
+	mtype := "CreateAccessControlList"
+	svc := getService(ctx)
+	AddEvent(SLB, util.Key(svc), acl.AclName, "CreateAccessControlList", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("access control list %s should be created", acl.AclName))
+}
+
+func (m *DryRunSLB) DeleteCLBAccessControlList(ctx context.Context, aclId string) error {
+	mtype := "DeleteAccessControlList"
+	svc := getService(ctx)
+	AddEvent(SLB, util.Key(svc), aclId, "DeleteAccessControlList", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("access control list %s should be deleted", aclId))
+}
+
+func (m *DryRunSLB) DescribeCLBAccessControlListEntries(ctx context.Context, aclId string) ([]model.AclEntry, error) {
+	return m.slb.DescribeCLBAccessControlListEntries(ctx, aclId)
+}
+
+func (m *DryRunSLB) AddCLBAccessControlListEntries(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	mtype := "AddAccessControlListEntry"
+	svc := getService(ctx)
+	AddEvent(SLB, util.Key(svc), aclId, "AddAccessControlListEntry", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("%d entries should be added to access control list %s", len(entries), aclId))
+}
+
+func (m *DryRunSLB) RemoveCLBAccessControlListEntries(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	mtype := "RemoveAccessControlListEntry"
+	svc := getService(ctx)
+	AddEvent(SLB, util.Key(svc), aclId, "RemoveAccessControlListEntry", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("%d entries should be removed from access control list %s", len(entries), aclId))
+}
+
 func getTagString(tags []tag.Tag) string {
 	var ret []string
 	for _, t := range tags {
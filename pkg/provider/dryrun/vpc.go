@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/vpc"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 	"net"
 
 	servicesvpc "github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
@@ -67,3 +69,56 @@ func (m *DryRunVPC) DescribeVSwitches(ctx context.Context, vpcID string) ([]serv
 func (m *DryRunVPC) DescribeVpcCIDRBlock(ctx context.Context, vpcId string, ipVersion model.AddressIPVersionType) ([]*net.IPNet, error) {
 	return m.vpc.DescribeVpcCIDRBlock(ctx, vpcId, ipVersion)
 }
+
+func (m *DryRunVPC) ListEipAddresses(ctx context.Context, tags []tag.Tag) ([]model.EipAddress, error) {
+	return m.vpc.ListEipAddresses(ctx, tags)
+}
+
+func (m *DryRunVPC) AllocateEipAddress(ctx context.Context, eip *model.EipAddress) error {
+	mtype := "AllocateEipAddress"
+	svc := getService(ctx)
+	AddEvent(VPC, util.Key(svc), eip.Name, "AllocateEipAddress", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("eip %s should be allocated", eip.Name))
+}
+
+func (m *DryRunVPC) ReleaseEipAddress(ctx context.Context, allocationId string) error {
+	mtype := "ReleaseEipAddress"
+	svc := getService(ctx)
+	AddEvent(VPC, util.Key(svc), allocationId, "ReleaseEipAddress", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("eip %s should be released", allocationId))
+}
+
+func (m *DryRunVPC) ModifyEipAddressBandwidth(ctx context.Context, allocationId string, bandwidth int) error {
+	mtype := "ModifyEipAddressAttribute"
+	svc := getService(ctx)
+	AddEvent(VPC, util.Key(svc), allocationId, "ModifyEipAddressAttribute", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("bandwidth of eip %s should be changed to %d", allocationId, bandwidth))
+}
+
+func (m *DryRunVPC) AssociateEipAddress(ctx context.Context, allocationId, instanceType, instanceId string) error {
+	mtype := "AssociateEipAddress"
+	svc := getService(ctx)
+	AddEvent(VPC, util.Key(svc), allocationId, "AssociateEipAddress", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("eip %s should be associated with %s", allocationId, instanceId))
+}
+
+func (m *DryRunVPC) UnassociateEipAddress(ctx context.Context, allocationId, instanceType, instanceId string) error {
+	mtype := "UnassociateEipAddress"
+	svc := getService(ctx)
+	AddEvent(VPC, util.Key(svc), allocationId, "UnassociateEipAddress", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("eip %s should be unassociated from %s", allocationId, instanceId))
+}
+
+func (m *DryRunVPC) AddCommonBandwidthPackageIp(ctx context.Context, bandwidthPackageId, allocationId string) error {
+	mtype := "AddCommonBandwidthPackageIp"
+	svc := getService(ctx)
+	AddEvent(VPC, util.Key(svc), allocationId, "AddCommonBandwidthPackageIp", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("eip %s should be added to bandwidth package %s", allocationId, bandwidthPackageId))
+}
+
+func (m *DryRunVPC) RemoveCommonBandwidthPackageIp(ctx context.Context, bandwidthPackageId, allocationId string) error {
+	mtype := "RemoveCommonBandwidthPackageIp"
+	svc := getService(ctx)
+	AddEvent(VPC, util.Key(svc), allocationId, "RemoveCommonBandwidthPackageIp", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("eip %s should be removed from bandwidth package %s", allocationId, bandwidthPackageId))
+}
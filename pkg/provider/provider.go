@@ -77,6 +77,19 @@ type NodeAttribute struct {
 	SpotStrategy              string
 	PrimaryNetworkInterfaceID string
 	Tags                      map[string]string
+	InstanceTypeFamily        string
+	CPU                       int
+	// Memory is the memory size in MiB
+	Memory           int
+	GPUAmount        int
+	GPUSpec          string
+	VSwitchID        string
+	SecurityGroupIDs []string
+	// Status is the status of the instance, e.g. Running or Stopped
+	Status string
+	// LockReasons are the reasons the instance is locked for, e.g. Recycling for a spot instance
+	// which is about to be released
+	LockReasons []string
 }
 
 type EniAttribute struct {
@@ -93,6 +106,14 @@ type IInstance interface {
 	DescribeNetworkInterfaces(vpcId string, ips []string, ipVersionType model.AddressIPVersionType) (map[string]string, error)
 	DescribeNetworkInterfacesByIDs(ids []string) ([]*EniAttribute, error)
 	ModifyNetworkInterfaceSourceDestCheck(id string, enabled bool) error
+
+	// SecurityGroup
+	ListSecurityGroups(ctx context.Context, tags []tag.Tag) ([]model.SecurityGroup, error)
+	CreateSecurityGroup(ctx context.Context, sg *model.SecurityGroup) error
+	DeleteSecurityGroup(ctx context.Context, sgId string) error
+	DescribeSecurityGroupRules(ctx context.Context, sgId string) ([]model.SecurityGroupRule, error)
+	AuthorizeSecurityGroupRules(ctx context.Context, sgId string, rules []model.SecurityGroupRule) error
+	RevokeSecurityGroupRules(ctx context.Context, sgId string, rules []model.SecurityGroupRule) error
 }
 
 type RouteUpdateStatus struct {
@@ -111,6 +132,14 @@ type IVPC interface {
 	FindRoute(ctx context.Context, table, pvid, cidr string) (*model.Route, error)
 	ListRouteTables(ctx context.Context, vpcID string) ([]string, error)
 	DescribeEipAddresses(ctx context.Context, instanceType string, instanceId string) ([]string, error)
+	ListEipAddresses(ctx context.Context, tags []tag.Tag) ([]model.EipAddress, error)
+	AllocateEipAddress(ctx context.Context, eip *model.EipAddress) error
+	ReleaseEipAddress(ctx context.Context, allocationId string) error
+	ModifyEipAddressBandwidth(ctx context.Context, allocationId string, bandwidth int) error
+	AssociateEipAddress(ctx context.Context, allocationId, instanceType, instanceId string) error
+	UnassociateEipAddress(ctx context.Context, allocationId, instanceType, instanceId string) error
+	AddCommonBandwidthPackageIp(ctx context.Context, bandwidthPackageId, allocationId string) error
+	RemoveCommonBandwidthPackageIp(ctx context.Context, bandwidthPackageId, allocationId string) error
 	DescribeVSwitches(ctx context.Context, vpcID string) ([]vpc.VSwitch, error)
 	DescribeVpcCIDRBlock(ctx context.Context, vpcId string, ipVersion model.AddressIPVersionType) ([]*net.IPNet, error)
 }
@@ -159,6 +188,17 @@ type ILoadBalancer interface {
 
 	// Cert
 	DescribeServerCertificateById(ctx context.Context, serverCertificateId string) (*model.CertAttribute, error)
+	ListServerCertificates(ctx context.Context) ([]model.CertAttribute, error)
+	UploadServerCertificate(ctx context.Context, certName, cert, key string) (string, error)
+	DeleteServerCertificate(ctx context.Context, serverCertificateId string) error
+
+	// AccessControlList
+	ListCLBAccessControlLists(ctx context.Context, tags []tag.Tag) ([]model.AccessControlList, error)
+	CreateCLBAccessControlList(ctx context.Context, acl *model.AccessControlList) error
+	DeleteCLBAccessControlList(ctx context.Context, aclId string) error
+	DescribeCLBAccessControlListEntries(ctx context.Context, aclId string) ([]model.AclEntry, error)
+	AddCLBAccessControlListEntries(ctx context.Context, aclId string, entries []model.AclEntry) error
+	RemoveCLBAccessControlListEntries(ctx context.Context, aclId string, entries []model.AclEntry) error
 }
 
 type IPrivateZone interface {
@@ -175,6 +215,14 @@ type ISLS interface {
 type ICAS interface {
 	DescribeSSLCertificatePublicKeyDetail(ctx context.Context, certId string) (*model.CertificateInfo, error)
 	DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error)
+	// UploadSSLCertificate uploads the certificate with the tags marking its owner
+	UploadSSLCertificate(ctx context.Context, certName, cert, key string, tags []tag.Tag) (string, error)
+	// DescribeSSLCertificateListByTags lists the certificates carrying all the tags
+	DescribeSSLCertificateListByTags(ctx context.Context, tags []tag.Tag) ([]model.CertificateInfo, error)
+	DeleteSSLCertificate(ctx context.Context, certIdentifier string) error
+	UploadCACertificate(ctx context.Context, certName, cert string) (string, error)
+	DescribeCACertificateList(ctx context.Context, keyword string) ([]model.CertificateInfo, error)
+	DeleteCACertificate(ctx context.Context, certIdentifier string) error
 }
 
 type IALB interface {
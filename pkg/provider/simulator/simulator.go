@@ -0,0 +1,241 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultRegion    = "cn-hangzhou"
+	defaultZone      = "cn-hangzhou-k"
+	defaultVpcID     = "vpc-simulator"
+	defaultVswitchID = "vsw-simulator"
+	defaultVpcCIDR   = "192.168.0.0/16"
+	defaultTableID   = "vtb-simulator"
+
+	// JobDuration is how long the async jobs of the network load balancers take to finish.
+	JobDuration = 2 * time.Second
+)
+
+// DefaultQuotas are the quotas of a new simulated account, they can be changed in the state file.
+var DefaultQuotas = map[string]int{
+	snapshot.ResourceCLB:               60,
+	snapshot.ResourceCLBListener:       50,
+	snapshot.ResourceCLBVServerGroup:   50,
+	snapshot.ResourceNLB:               60,
+	snapshot.ResourceNLBListener:       50,
+	snapshot.ResourceNLBServerGroup:    200,
+	snapshot.ResourceALB:               60,
+	snapshot.ResourceALBListener:       50,
+	snapshot.ResourceALBRule:           100,
+	snapshot.ResourceALBServerGroup:    200,
+	snapshot.ResourceCertificate:       100,
+	snapshot.ResourceCACertificate:     100,
+	snapshot.ResourceServerCertificate: 100,
+	snapshot.ResourceRoute:             200,
+	snapshot.ResourcePrivateZoneRecord: 1000,
+}
+
+// idPrefixes are the prefixes of the ids of the resources created by the OpenAPIs.
+var idPrefixes = map[string]string{
+	snapshot.ResourceCLB:              "lb-",
+	snapshot.ResourceCLBVServerGroup:  "rsp-",
+	snapshot.ResourceNLB:              "nlb-",
+	snapshot.ResourceNLBListener:      "lsn-",
+	snapshot.ResourceNLBServerGroup:   "sgp-",
+	snapshot.ResourceALB:              "alb-",
+	snapshot.ResourceALBListener:      "lsn-",
+	snapshot.ResourceALBRule:          "rule-",
+	snapshot.ResourceALBServerGroup:   "sgp-",
+	snapshot.ResourceInstance:         "i-",
+	snapshot.ResourceNetworkInterface: "eni-",
+	snapshot.ResourceRoute:            "rte-",
+}
+
+// NewSimulatorCloud returns a simulated cloud, which keeps the cloud resources in memory instead of
+// calling the OpenAPIs. The state is loaded from the state file if it exists, and written back after
+// each change, so that it survives the restarts. An empty path keeps the state in memory only.
+// The new state is created from the global cloud config.
+func NewSimulatorCloud(path string) (*snapshot.SnapshotCloud, error) {
+	snap, err := loadState(path)
+	if err != nil {
+		return nil, err
+	}
+	opts := []snapshot.Option{
+		snapshot.WithIDGenerator(newIDGenerator(snap).newID),
+		snapshot.WithJobDuration(JobDuration),
+		snapshot.WithInstanceRegistration(),
+		snapshot.WithChangeHandler(func(s *snapshot.Snapshot, operations []snapshot.Operation) {
+			for _, op := range operations {
+				klog.Infof("cloud simulator: %s %s", op.String(), strings.Join(op.Changes, ", "))
+			}
+			if path == "" {
+				return
+			}
+			if err := saveState(path, s); err != nil {
+				klog.Errorf("cloud simulator: save state to %s error: %s", path, err.Error())
+			}
+		}),
+	}
+	return snapshot.NewSnapshotCloud(snap, opts...), nil
+}
+
+func loadState(path string) (*snapshot.Snapshot, error) {
+	if path != "" {
+		_, err := os.Stat(path)
+		if err == nil {
+			klog.Infof("cloud simulator: load state from %s", path)
+			return snapshot.LoadSnapshot(path)
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("read state %s: %s", path, err.Error())
+		}
+	}
+	snap := newState()
+	if path != "" {
+		if err := saveState(path, snap); err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
+
+// newState returns the state of a new cluster, with a vpc, a vswitch and the route tables of the cloud config.
+func newState() *snapshot.Snapshot {
+	global := ctrlCfg.CloudCFG.Global
+	snap := &snapshot.Snapshot{
+		Region:        valueOrDefault(global.Region, defaultRegion),
+		VpcID:         valueOrDefault(global.VpcID, defaultVpcID),
+		VswitchID:     valueOrDefault(global.VswitchID, defaultVswitchID),
+		ZoneID:        valueOrDefault(global.ZoneID, defaultZone),
+		ClusterID:     global.ClusterID,
+		VpcCIDRBlocks: []string{defaultVpcCIDR},
+		RouteTables:   map[string][]model.Route{},
+		Quotas:        map[string]int{},
+	}
+	snap.VSwitches = []vpc.VSwitch{{VSwitchId: snap.VswitchID, ZoneId: snap.ZoneID, VpcId: snap.VpcID}}
+	snap.ALB.Zones = []albsdk.Zone{{ZoneId: snap.ZoneID, LocalName: snap.ZoneID}}
+	tables := strings.Split(global.RouteTableIDS, ",")
+	if global.RouteTableIDS == "" {
+		tables = []string{defaultTableID}
+	}
+	for _, t := range tables {
+		snap.RouteTables[strings.TrimSpace(t)] = []model.Route{}
+	}
+	for k, v := range DefaultQuotas {
+		snap.Quotas[k] = v
+	}
+	return snap
+}
+
+// saveState writes the state to a temporary file and renames it, so that the state file is always complete.
+func saveState(path string, s *snapshot.Snapshot) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal state: %s", err.Error())
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// idGenerator generates the ids of the created resources, which do not conflict with the ids in the state
+// file or the ones generated before.
+type idGenerator struct {
+	lock sync.Mutex
+	used sets.String
+}
+
+// newIDGenerator takes all the strings in the state as used ids, so that the ids of any resource are kept.
+func newIDGenerator(s *snapshot.Snapshot) *idGenerator {
+	g := &idGenerator{used: sets.NewString()}
+	data, err := json.Marshal(s)
+	if err != nil {
+		klog.Errorf("cloud simulator: marshal state error: %s", err.Error())
+		return g
+	}
+	var state interface{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		klog.Errorf("cloud simulator: unmarshal state error: %s", err.Error())
+		return g
+	}
+	collectStrings(state, g.used)
+	return g
+}
+
+func collectStrings(v interface{}, strs sets.String) {
+	switch v := v.(type) {
+	case string:
+		strs.Insert(v)
+	case []interface{}:
+		for _, e := range v {
+			collectStrings(e, strs)
+		}
+	case map[string]interface{}:
+		for k, e := range v {
+			strs.Insert(k)
+			collectStrings(e, strs)
+		}
+	}
+}
+
+// newID returns a random id of the resource which is not used yet.
+func (g *idGenerator) newID(resource string) string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for {
+		id := newID(resource)
+		if !g.used.Has(id) {
+			g.used.Insert(id)
+			return id
+		}
+	}
+}
+
+// newID returns a random id in the format of the OpenAPIs.
+func newID(resource string) string {
+	switch resource {
+	case snapshot.ResourceCertificate, snapshot.ResourceCACertificate:
+		return fmt.Sprintf("%d-%s", rand.IntnRange(10000000, 99999999), valueOrDefault(ctrlCfg.CloudCFG.Global.Region, defaultRegion))
+	case snapshot.ResourceServerCertificate:
+		return fmt.Sprintf("%d_%s", rand.IntnRange(1000000000, 9999999999), rand.String(20))
+	case "job":
+		return rand.String(32)
+	}
+	prefix, ok := idPrefixes[resource]
+	if !ok {
+		prefix = resource + "-"
+	}
+	return prefix + rand.String(20)
+}
+
+func valueOrDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,86 @@
+package simulator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/snapshot"
+)
+
+func TestSimulatorCloud(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	cloud, err := NewSimulatorCloud(path)
+	assert.Nil(t, err)
+	_, err = os.Stat(path)
+	assert.Nil(t, err)
+
+	// the instance of an unknown node ip is registered
+	ins, err := cloud.GetInstancesByIP(context.TODO(), []string{"192.168.0.1"})
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(ins.InstanceID, "i-"))
+	providerID := defaultRegion + "." + ins.InstanceID
+
+	tables, err := cloud.ListRouteTables(context.TODO(), defaultVpcID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{defaultTableID}, tables)
+	_, err = cloud.CreateRoute(context.TODO(), defaultTableID, providerID, "172.16.0.0/24")
+	assert.Nil(t, err)
+	_, err = cloud.CreateRoute(context.TODO(), defaultTableID, providerID, "172.16.0.0/24")
+	assert.Contains(t, err.Error(), "InvalidCIDRBlock.Duplicate")
+
+	sg := &nlbmodel.ServerGroup{ServerGroupName: "k8s/80/nginx/default/clusterid"}
+	jobID, err := cloud.CreateNLBServerGroupAsync(context.TODO(), sg)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(sg.ServerGroupId, "sgp-"))
+	assert.NotNil(t, cloud.BatchWaitJobsFinish(context.TODO(), "CreateServerGroup", []string{"unknown"}))
+	assert.Nil(t, cloud.BatchWaitJobsFinish(context.TODO(), "CreateServerGroup", []string{jobID},
+		100*time.Millisecond, 2*JobDuration))
+
+	// the state is loaded from the state file after restart
+	cloud, err = NewSimulatorCloud(path)
+	assert.Nil(t, err)
+	instances, err := cloud.ListInstances(context.TODO(), []string{providerID})
+	assert.Nil(t, err)
+	assert.Equal(t, ins.InstanceID, instances[providerID].InstanceID)
+	routes, err := cloud.ListRoute(context.TODO(), defaultTableID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(routes))
+	assert.Equal(t, providerID, routes[0].ProviderId)
+	_, err = cloud.GetNLBServerGroup(context.TODO(), sg.ServerGroupId)
+	assert.Nil(t, err)
+}
+
+func TestSimulatorCloud_Quota(t *testing.T) {
+	snap := newState()
+	snap.Quotas[snapshot.ResourceCLB] = 1
+	path := filepath.Join(t.TempDir(), "state.json")
+	assert.Nil(t, saveState(path, snap))
+
+	cloud, err := NewSimulatorCloud(path)
+	assert.Nil(t, err)
+	lb := &model.LoadBalancer{}
+	assert.Nil(t, cloud.CreateLoadBalancer(context.TODO(), lb, "token"))
+	assert.True(t, strings.HasPrefix(lb.LoadBalancerAttribute.LoadBalancerId, "lb-"))
+	err = cloud.CreateLoadBalancer(context.TODO(), &model.LoadBalancer{}, "token")
+	assert.Contains(t, err.Error(), "QuotaExceeded")
+}
+
+func TestIDGenerator(t *testing.T) {
+	snap := newState()
+	snap.LoadBalancers = []model.LoadBalancer{{LoadBalancerAttribute: model.LoadBalancerAttribute{LoadBalancerId: "lb-used"}}}
+	g := newIDGenerator(snap)
+	assert.True(t, g.used.Has("lb-used"))
+	assert.True(t, g.used.Has(defaultTableID))
+
+	id := g.newID(snapshot.ResourceCLB)
+	assert.True(t, strings.HasPrefix(id, "lb-"))
+	assert.NotEqual(t, "lb-used", id)
+	assert.True(t, g.used.Has(id))
+}
@@ -0,0 +1,849 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/tracking"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+var _ prvd.IALB = &SnapshotALB{}
+
+// SnapshotALB serves the application load balancers, their listeners, rules and server groups.
+// The attributes compared by the updates follow the ones the alb provider updates.
+type SnapshotALB struct {
+	state *state
+}
+
+// alb returns the application load balancer in the snapshot, the caller must hold the lock.
+func (m *SnapshotALB) alb(lbID string) (*albmodel.AlbLoadBalancerWithTags, error) {
+	for i := range m.state.snapshot.ALB.LoadBalancers {
+		if m.state.snapshot.ALB.LoadBalancers[i].LoadBalancerId == lbID {
+			return &m.state.snapshot.ALB.LoadBalancers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("alb %s not found in snapshot", lbID)
+}
+
+// listener returns the listener in the snapshot, the caller must hold the lock.
+func (m *SnapshotALB) listener(lsID string) (*albsdk.Listener, error) {
+	for i := range m.state.snapshot.ALB.Listeners {
+		if m.state.snapshot.ALB.Listeners[i].ListenerId == lsID {
+			return &m.state.snapshot.ALB.Listeners[i], nil
+		}
+	}
+	return nil, fmt.Errorf("alb listener %s not found in snapshot", lsID)
+}
+
+// rule returns the rule in the snapshot, the caller must hold the lock.
+func (m *SnapshotALB) rule(ruleID string) (*albsdk.Rule, error) {
+	for i := range m.state.snapshot.ALB.Rules {
+		if m.state.snapshot.ALB.Rules[i].RuleId == ruleID {
+			return &m.state.snapshot.ALB.Rules[i], nil
+		}
+	}
+	return nil, fmt.Errorf("alb rule %s not found in snapshot", ruleID)
+}
+
+// serverGroup returns the server group in the snapshot, the caller must hold the lock.
+func (m *SnapshotALB) serverGroup(sgpID string) (*albmodel.ServerGroupWithTags, error) {
+	for i := range m.state.snapshot.ALB.ServerGroups {
+		if m.state.snapshot.ALB.ServerGroups[i].ServerGroupId == sgpID {
+			return &m.state.snapshot.ALB.ServerGroups[i], nil
+		}
+	}
+	return nil, fmt.Errorf("alb server group %s not found in snapshot", sgpID)
+}
+
+func (m *SnapshotALB) DescribeALBZones(request *albsdk.DescribeZonesRequest) (*albsdk.DescribeZonesResponse, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	resp := albsdk.CreateDescribeZonesResponse()
+	resp.Zones = clone(m.state.snapshot.ALB.Zones)
+	return resp, nil
+}
+
+func (m *SnapshotALB) TagALBResources(request *albsdk.TagResourcesRequest) (*albsdk.TagResourcesResponse, error) {
+	if request.ResourceId == nil || request.Tag == nil {
+		return albsdk.CreateTagResourcesResponse(), nil
+	}
+	tags := make(map[string]string)
+	for _, t := range *request.Tag {
+		tags[t.Key] = t.Value
+	}
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	for _, id := range *request.ResourceId {
+		if request.ResourceType == util.ServerGroupResourceType {
+			sgp, err := m.serverGroup(id)
+			if err != nil {
+				return nil, err
+			}
+			m.tag(ResourceALBServerGroup, id, sgp.ServerGroupName, &sgp.Tags, tags)
+			continue
+		}
+		lb, err := m.alb(id)
+		if err != nil {
+			return nil, err
+		}
+		m.tag(ResourceALB, id, lb.LoadBalancerName, &lb.Tags, tags)
+	}
+	return albsdk.CreateTagResourcesResponse(), nil
+}
+
+// tag merges the tags of the resource and records the changes, the caller must hold the lock.
+func (m *SnapshotALB) tag(resource, id, name string, current *map[string]string, tags map[string]string) {
+	if *current == nil {
+		*current = make(map[string]string)
+	}
+	var changes []string
+	for _, k := range sortedKeys(toMap(tags)) {
+		if v, ok := (*current)[k]; !ok || v != tags[k] {
+			changes = append(changes, change("Tags."+k, (*current)[k], tags[k]))
+			(*current)[k] = tags[k]
+		}
+	}
+	if len(changes) != 0 {
+		m.state.record(Operation{Action: ActionUpdate, Resource: resource, ID: id, Name: name, Changes: changes})
+	}
+}
+
+func (m *SnapshotALB) CreateALB(ctx context.Context, resLB *albmodel.AlbLoadBalancer, trackingProvider tracking.TrackingProvider) (albmodel.LoadBalancerStatus, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	if err := m.state.checkQuota(ResourceALB, len(m.state.snapshot.ALB.LoadBalancers)); err != nil {
+		return albmodel.LoadBalancerStatus{}, err
+	}
+	lb := albmodel.AlbLoadBalancerWithTags{
+		Tags: trackingProvider.ResourceTags(resLB.Stack(), resLB, tagListToMap(resLB.Spec.Tags)),
+	}
+	convert(resLB.Spec, &lb.LoadBalancer)
+	lb.LoadBalancer.Tags = nil
+	lb.LoadBalancerId = m.state.newID(ResourceALB)
+	lb.LoadBalancerStatus = util.LoadBalancerStatusActive
+	lb.DNSName = fmt.Sprintf("%s.%s.alb.aliyuncs.com", lb.LoadBalancerId, m.state.snapshot.Region)
+	m.state.snapshot.ALB.LoadBalancers = append(m.state.snapshot.ALB.LoadBalancers, lb)
+	m.state.record(Operation{
+		Action:   ActionCreate,
+		Resource: ResourceALB,
+		ID:       lb.LoadBalancerId,
+		Name:     lb.LoadBalancerName,
+		Changes: fields(resLB.Spec, "LoadBalancerId", "LoadBalancerName", "LoadBalancerStatus", "DNSName",
+			"ForceOverride", "Tags"),
+	})
+	return albmodel.LoadBalancerStatus{LoadBalancerID: lb.LoadBalancerId, DNSName: lb.DNSName}, nil
+}
+
+func tagListToMap(tags []albmodel.ALBTag) map[string]string {
+	ret := make(map[string]string)
+	for _, t := range tags {
+		ret[t.Key] = t.Value
+	}
+	return ret
+}
+
+func (m *SnapshotALB) ReuseALB(ctx context.Context, resLB *albmodel.AlbLoadBalancer, lbID string, trackingProvider tracking.TrackingProvider) (albmodel.LoadBalancerStatus, error) {
+	m.state.lock.Lock()
+	lb, err := m.alb(lbID)
+	if err != nil {
+		m.state.unlock()
+		return albmodel.LoadBalancerStatus{}, err
+	}
+	if lb.VpcId != resLB.Spec.VpcId {
+		m.state.unlock()
+		return albmodel.LoadBalancerStatus{}, fmt.Errorf("the vpc %s of reused alb %s is not same with cluster vpc %s",
+			lb.VpcId, lbID, resLB.Spec.VpcId)
+	}
+	m.tag(ResourceALB, lbID, lb.LoadBalancerName, &lb.Tags,
+		trackingProvider.ResourceTags(resLB.Stack(), resLB, tagListToMap(resLB.Spec.Tags)))
+	sdkLB := clone(lb.LoadBalancer)
+	m.state.unlock()
+
+	if resLB.Spec.ForceOverride != nil && *resLB.Spec.ForceOverride {
+		return m.UpdateALB(ctx, resLB, sdkLB)
+	}
+	return albmodel.LoadBalancerStatus{LoadBalancerID: lbID, DNSName: sdkLB.DNSName}, nil
+}
+
+func (m *SnapshotALB) UpdateALB(ctx context.Context, resLB *albmodel.AlbLoadBalancer, sdkLB albsdk.LoadBalancer) (albmodel.LoadBalancerStatus, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.alb(sdkLB.LoadBalancerId)
+	if err != nil {
+		return albmodel.LoadBalancerStatus{}, err
+	}
+	var changes []string
+	if resLB.Spec.LoadBalancerName != lb.LoadBalancerName {
+		changes = append(changes, change("LoadBalancerName", lb.LoadBalancerName, resLB.Spec.LoadBalancerName))
+		lb.LoadBalancerName = resLB.Spec.LoadBalancerName
+	}
+	if resLB.Spec.ModificationProtectionConfig.Status != lb.ModificationProtectionConfig.Status {
+		changes = append(changes, change("ModificationProtectionConfig.Status",
+			lb.ModificationProtectionConfig.Status, resLB.Spec.ModificationProtectionConfig.Status))
+		lb.ModificationProtectionConfig.Status = resLB.Spec.ModificationProtectionConfig.Status
+	}
+	if resLB.Spec.DeletionProtectionConfig.Enabled != lb.DeletionProtectionConfig.Enabled {
+		changes = append(changes, change("DeletionProtectionConfig.Enabled",
+			lb.DeletionProtectionConfig.Enabled, resLB.Spec.DeletionProtectionConfig.Enabled))
+		lb.DeletionProtectionConfig.Enabled = resLB.Spec.DeletionProtectionConfig.Enabled
+	}
+	if resLB.Spec.AccessLogConfig.LogProject != lb.AccessLogConfig.LogProject ||
+		resLB.Spec.AccessLogConfig.LogStore != lb.AccessLogConfig.LogStore {
+		changes = append(changes, change("AccessLogConfig", lb.AccessLogConfig, resLB.Spec.AccessLogConfig))
+		lb.AccessLogConfig = albsdk.AccessLogConfig(resLB.Spec.AccessLogConfig)
+	}
+	if resLB.Spec.LoadBalancerEdition != "" && !strings.EqualFold(resLB.Spec.LoadBalancerEdition, lb.LoadBalancerEdition) {
+		changes = append(changes, change("LoadBalancerEdition", lb.LoadBalancerEdition, resLB.Spec.LoadBalancerEdition))
+		lb.LoadBalancerEdition = resLB.Spec.LoadBalancerEdition
+	}
+	if len(changes) != 0 {
+		m.state.record(Operation{
+			Action:   ActionUpdate,
+			Resource: ResourceALB,
+			ID:       lb.LoadBalancerId,
+			Name:     lb.LoadBalancerName,
+			Changes:  changes,
+		})
+	}
+	return albmodel.LoadBalancerStatus{LoadBalancerID: lb.LoadBalancerId, DNSName: lb.DNSName}, nil
+}
+
+func (m *SnapshotALB) DeleteALB(ctx context.Context, lbID string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.alb(lbID)
+	if err != nil {
+		return err
+	}
+	m.state.record(Operation{Action: ActionDelete, Resource: ResourceALB, ID: lbID, Name: lb.LoadBalancerName})
+	var lbs []albmodel.AlbLoadBalancerWithTags
+	for _, l := range m.state.snapshot.ALB.LoadBalancers {
+		if l.LoadBalancerId != lbID {
+			lbs = append(lbs, l)
+		}
+	}
+	m.state.snapshot.ALB.LoadBalancers = lbs
+	return nil
+}
+
+func (m *SnapshotALB) CreateALBListener(ctx context.Context, resLS *albmodel.Listener) (albmodel.ListenerStatus, error) {
+	lbID, err := resLS.Spec.LoadBalancerID.Resolve(ctx)
+	if err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	ls, err := listenerToSDK(ctx, resLS)
+	if err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	if _, err := m.alb(lbID); err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	used := 0
+	for _, l := range m.state.snapshot.ALB.Listeners {
+		if l.LoadBalancerId == lbID {
+			used++
+		}
+	}
+	if err := m.state.checkQuota(ResourceALBListener, used); err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	ls.LoadBalancerId = lbID
+	ls.ListenerId = m.state.newID(ResourceALBListener)
+	ls.ListenerStatus = util.ListenerStatusRunning
+	m.state.snapshot.ALB.Listeners = append(m.state.snapshot.ALB.Listeners, ls)
+	certs := certificateIDs(resLS.Spec.Certificates)
+	m.setListenerCertificates(ls.ListenerId, certs)
+
+	changes := fields(ls, "ListenerId", "ListenerPort", "ListenerProtocol", "ListenerStatus", "LoadBalancerId")
+	if len(certs) != 0 {
+		changes = append(changes, fmt.Sprintf("Certificates: %s", toJson(certs)))
+	}
+	m.state.record(Operation{
+		Action:         ActionCreate,
+		Resource:       ResourceALBListener,
+		ID:             ls.ListenerId,
+		Name:           albListenerKey(ls),
+		LoadBalancerID: lbID,
+		Changes:        changes,
+	})
+	return albmodel.ListenerStatus{ListenerID: ls.ListenerId, ListenerStatus: ls.ListenerStatus}, nil
+}
+
+// setListenerCertificates stores the certificates of the listener, the caller must hold the lock.
+func (m *SnapshotALB) setListenerCertificates(lsID string, certs []string) {
+	if m.state.snapshot.ALB.ListenerCertificates == nil {
+		m.state.snapshot.ALB.ListenerCertificates = make(map[string][]string)
+	}
+	m.state.snapshot.ALB.ListenerCertificates[lsID] = certs
+}
+
+// certificateIDs returns the ids of the certificates, the default one first.
+func certificateIDs(certs []albmodel.Certificate) []string {
+	var ids []string
+	for _, c := range certs {
+		if c.IsDefault {
+			ids = append([]string{c.CertificateId}, ids...)
+			continue
+		}
+		ids = append(ids, c.CertificateId)
+	}
+	return ids
+}
+
+func albListenerKey(ls albsdk.Listener) string {
+	return fmt.Sprintf("%s:%d", ls.ListenerProtocol, ls.ListenerPort)
+}
+
+// listenerToSDK converts the listener to the form ListListeners returns.
+func listenerToSDK(ctx context.Context, resLS *albmodel.Listener) (albsdk.Listener, error) {
+	var ls albsdk.Listener
+	spec := resLS.Spec.ALBListenerSpec
+	spec.DefaultActions, spec.Certificates, spec.CaCertificates = nil, nil, nil
+	convert(spec, &ls)
+	for _, action := range resLS.Spec.DefaultActions {
+		defaultAction := albsdk.DefaultAction{Type: action.Type}
+		if action.ForwardConfig != nil {
+			tuples, err := serverGroupTuplesToSDK(ctx, action.ForwardConfig.ServerGroups)
+			if err != nil {
+				return ls, err
+			}
+			defaultAction.ForwardGroupConfig.ServerGroupTuples = tuples
+		}
+		ls.DefaultActions = append(ls.DefaultActions, defaultAction)
+	}
+	return ls, nil
+}
+
+func serverGroupTuplesToSDK(ctx context.Context, tuples []albmodel.ServerGroupTuple) ([]albsdk.ServerGroupTuple, error) {
+	var ret []albsdk.ServerGroupTuple
+	for _, t := range tuples {
+		sgpID, err := t.ServerGroupID.Resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, albsdk.ServerGroupTuple{ServerGroupId: sgpID, Weight: t.Weight})
+	}
+	return ret, nil
+}
+
+func (m *SnapshotALB) UpdateALBListener(ctx context.Context, resLS *albmodel.Listener, sdkLB *albsdk.Listener) (albmodel.ListenerStatus, error) {
+	desired, err := listenerToSDK(ctx, resLS)
+	if err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	ls, err := m.listener(sdkLB.ListenerId)
+	if err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	var changes []string
+	for _, f := range []string{"GzipEnabled", "Http2Enabled", "IdleTimeout", "RequestTimeout", "ListenerDescription",
+		"SecurityPolicyId", "QuicConfig", "XForwardedForConfig", "DefaultActions"} {
+		cur, des := reflect.ValueOf(ls).Elem().FieldByName(f), reflect.ValueOf(&desired).Elem().FieldByName(f)
+		if f == "SecurityPolicyId" && des.String() == "" {
+			continue
+		}
+		if !reflect.DeepEqual(cur.Interface(), des.Interface()) {
+			changes = append(changes, change(f, cur.Interface(), des.Interface()))
+			cur.Set(des)
+		}
+	}
+	if len(resLS.Spec.Certificates) != 0 {
+		certs := certificateIDs(resLS.Spec.Certificates)
+		current := m.state.snapshot.ALB.ListenerCertificates[ls.ListenerId]
+		if !reflect.DeepEqual(current, certs) {
+			changes = append(changes, change("Certificates", current, certs))
+			m.setListenerCertificates(ls.ListenerId, certs)
+		}
+	}
+	if len(changes) != 0 {
+		m.state.record(Operation{
+			Action:         ActionUpdate,
+			Resource:       ResourceALBListener,
+			ID:             ls.ListenerId,
+			Name:           albListenerKey(*ls),
+			LoadBalancerID: ls.LoadBalancerId,
+			Changes:        changes,
+		})
+	}
+	return albmodel.ListenerStatus{ListenerID: ls.ListenerId, ListenerStatus: ls.ListenerStatus}, nil
+}
+
+func (m *SnapshotALB) DeleteALBListener(ctx context.Context, lsID string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	ls, err := m.listener(lsID)
+	if err != nil {
+		return err
+	}
+	m.state.record(Operation{
+		Action:         ActionDelete,
+		Resource:       ResourceALBListener,
+		ID:             lsID,
+		Name:           albListenerKey(*ls),
+		LoadBalancerID: ls.LoadBalancerId,
+	})
+	var lss []albsdk.Listener
+	for _, l := range m.state.snapshot.ALB.Listeners {
+		if l.ListenerId != lsID {
+			lss = append(lss, l)
+		}
+	}
+	m.state.snapshot.ALB.Listeners = lss
+	delete(m.state.snapshot.ALB.ListenerCertificates, lsID)
+	return nil
+}
+
+func (m *SnapshotALB) ListALBListeners(ctx context.Context, lbID string) ([]albsdk.Listener, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	var ret []albsdk.Listener
+	for _, ls := range m.state.snapshot.ALB.Listeners {
+		if ls.LoadBalancerId == lbID {
+			ret = append(ret, clone(ls))
+		}
+	}
+	return ret, nil
+}
+
+// ruleToSDK converts the rule to the form ListRules returns.
+func ruleToSDK(ctx context.Context, resLR *albmodel.ListenerRule) (albsdk.Rule, error) {
+	rule := albsdk.Rule{
+		Priority:  resLR.Spec.Priority,
+		RuleName:  resLR.Spec.RuleName,
+		Direction: resLR.Spec.Direction,
+	}
+	if rule.Direction == "" {
+		rule.Direction = util.RuleDirectionRequest
+	}
+	convert(resLR.Spec.RuleConditions, &rule.RuleConditions)
+	for _, action := range resLR.Spec.RuleActions {
+		a := action
+		a.ForwardConfig = nil
+		var sdkAction albsdk.Action
+		convert(a, &sdkAction)
+		if action.ForwardConfig != nil {
+			tuples, err := serverGroupTuplesToSDK(ctx, action.ForwardConfig.ServerGroups)
+			if err != nil {
+				return rule, err
+			}
+			sdkAction.ForwardGroupConfig.ServerGroupTuples = tuples
+			if action.ForwardConfig.ServerGroupStickySession != nil {
+				sdkAction.ForwardGroupConfig.ServerGroupStickySession = albsdk.ServerGroupStickySession(*action.ForwardConfig.ServerGroupStickySession)
+			}
+		}
+		rule.RuleActions = append(rule.RuleActions, sdkAction)
+	}
+	return rule, nil
+}
+
+func (m *SnapshotALB) CreateALBListenerRule(ctx context.Context, resLR *albmodel.ListenerRule) (albmodel.ListenerRuleStatus, error) {
+	lsID, err := resLR.Spec.ListenerID.Resolve(ctx)
+	if err != nil {
+		return albmodel.ListenerRuleStatus{}, err
+	}
+	rule, err := ruleToSDK(ctx, resLR)
+	if err != nil {
+		return albmodel.ListenerRuleStatus{}, err
+	}
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	ls, err := m.listener(lsID)
+	if err != nil {
+		return albmodel.ListenerRuleStatus{}, err
+	}
+	used := 0
+	for _, r := range m.state.snapshot.ALB.Rules {
+		if r.ListenerId == lsID {
+			used++
+		}
+	}
+	if err := m.state.checkQuota(ResourceALBRule, used); err != nil {
+		return albmodel.ListenerRuleStatus{}, err
+	}
+	rule.ListenerId, rule.LoadBalancerId = lsID, ls.LoadBalancerId
+	rule.RuleId = m.state.newID(ResourceALBRule)
+	rule.RuleStatus = "Available"
+	m.state.snapshot.ALB.Rules = append(m.state.snapshot.ALB.Rules, rule)
+	m.state.record(Operation{
+		Action:         ActionCreate,
+		Resource:       ResourceALBRule,
+		ID:             rule.RuleId,
+		Name:           rule.RuleName,
+		LoadBalancerID: rule.LoadBalancerId,
+		Changes: append([]string{fmt.Sprintf("Listener: %s", albListenerKey(*ls))},
+			fields(rule, "ListenerId", "LoadBalancerId", "RuleId", "RuleName", "RuleStatus")...),
+	})
+	return albmodel.ListenerRuleStatus{RuleID: rule.RuleId}, nil
+}
+
+func (m *SnapshotALB) CreateALBListenerRules(ctx context.Context, resLR []*albmodel.ListenerRule) (map[int]albmodel.ListenerRuleStatus, error) {
+	ret := make(map[int]albmodel.ListenerRuleStatus)
+	for _, lr := range resLR {
+		status, err := m.CreateALBListenerRule(ctx, lr)
+		if err != nil {
+			return nil, err
+		}
+		ret[lr.Spec.Priority] = status
+	}
+	return ret, nil
+}
+
+func (m *SnapshotALB) UpdateALBListenerRule(ctx context.Context, resLR *albmodel.ListenerRule, sdkLR *albsdk.Rule) (albmodel.ListenerRuleStatus, error) {
+	desired, err := ruleToSDK(ctx, resLR)
+	if err != nil {
+		return albmodel.ListenerRuleStatus{}, err
+	}
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	rule, err := m.rule(sdkLR.RuleId)
+	if err != nil {
+		return albmodel.ListenerRuleStatus{}, err
+	}
+	var changes []string
+	if desired.RuleName != rule.RuleName {
+		changes = append(changes, change("RuleName", rule.RuleName, desired.RuleName))
+		rule.RuleName = desired.RuleName
+	}
+	if desired.Priority != rule.Priority {
+		changes = append(changes, change("Priority", rule.Priority, desired.Priority))
+		rule.Priority = desired.Priority
+	}
+	// the conditions and actions are compared in their json form, as the snapshot is
+	if toJson(desired.RuleConditions) != toJson(rule.RuleConditions) {
+		changes = append(changes, change("RuleConditions", rule.RuleConditions, desired.RuleConditions))
+		rule.RuleConditions = desired.RuleConditions
+	}
+	if toJson(desired.RuleActions) != toJson(rule.RuleActions) {
+		changes = append(changes, change("RuleActions", rule.RuleActions, desired.RuleActions))
+		rule.RuleActions = desired.RuleActions
+	}
+	if len(changes) != 0 {
+		m.state.record(Operation{
+			Action:         ActionUpdate,
+			Resource:       ResourceALBRule,
+			ID:             rule.RuleId,
+			Name:           rule.RuleName,
+			LoadBalancerID: rule.LoadBalancerId,
+			Changes:        changes,
+		})
+	}
+	return albmodel.ListenerRuleStatus{RuleID: rule.RuleId}, nil
+}
+
+func (m *SnapshotALB) UpdateALBListenerRules(ctx context.Context, matches []albmodel.ResAndSDKListenerRulePair) error {
+	for _, match := range matches {
+		if _, err := m.UpdateALBListenerRule(ctx, match.ResLR, match.SdkLR); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *SnapshotALB) DeleteALBListenerRule(ctx context.Context, sdkLRId string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	rule, err := m.rule(sdkLRId)
+	if err != nil {
+		return err
+	}
+	m.state.record(Operation{
+		Action:         ActionDelete,
+		Resource:       ResourceALBRule,
+		ID:             sdkLRId,
+		Name:           rule.RuleName,
+		LoadBalancerID: rule.LoadBalancerId,
+	})
+	var rules []albsdk.Rule
+	for _, r := range m.state.snapshot.ALB.Rules {
+		if r.RuleId != sdkLRId {
+			rules = append(rules, r)
+		}
+	}
+	m.state.snapshot.ALB.Rules = rules
+	return nil
+}
+
+func (m *SnapshotALB) DeleteALBListenerRules(ctx context.Context, sdkLRIds []string) error {
+	for _, id := range sdkLRIds {
+		if err := m.DeleteALBListenerRule(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *SnapshotALB) ListALBListenerRules(ctx context.Context, lsID string) ([]albsdk.Rule, error) {
+	if len(lsID) == 0 {
+		return nil, fmt.Errorf("invalid listener id: %s for listing rules", lsID)
+	}
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	var ret []albsdk.Rule
+	for _, r := range m.state.snapshot.ALB.Rules {
+		if r.ListenerId == lsID {
+			ret = append(ret, clone(r))
+		}
+	}
+	return ret, nil
+}
+
+// updateServers applies the change to the servers of the server group and records it.
+func (m *SnapshotALB) updateServers(serverGroupID string, update func(sgp *albmodel.ServerGroupWithTags) []string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	sgp, err := m.serverGroup(serverGroupID)
+	if err != nil {
+		return err
+	}
+	changes := update(sgp)
+	if len(changes) != 0 {
+		m.state.record(Operation{
+			Action:   ActionUpdate,
+			Resource: ResourceALBServerGroup,
+			ID:       serverGroupID,
+			Name:     sgp.ServerGroupName,
+			Changes:  changes,
+		})
+	}
+	return nil
+}
+
+func addALBServers(sgp *albmodel.ServerGroupWithTags, resServers []albmodel.BackendItem) []string {
+	var changes []string
+	for _, s := range resServers {
+		server := albsdk.BackendServer{
+			Description:   s.Description,
+			Port:          s.Port,
+			ServerId:      s.ServerId,
+			ServerIp:      s.ServerIp,
+			ServerType:    s.Type,
+			Status:        "Available",
+			Weight:        s.Weight,
+			ServerGroupId: sgp.ServerGroupId,
+		}
+		sgp.Servers = append(sgp.Servers, server)
+		changes = append(changes, fmt.Sprintf("add server %s weight %d", albServerString(server), server.Weight))
+	}
+	return changes
+}
+
+func removeALBServers(sgp *albmodel.ServerGroupWithTags, sdkServers []albsdk.BackendServer) []string {
+	var changes []string
+	var remain []albsdk.BackendServer
+	for _, old := range sgp.Servers {
+		removed := false
+		for _, s := range sdkServers {
+			if old.ServerId == s.ServerId && old.ServerIp == s.ServerIp && old.Port == s.Port {
+				removed = true
+				break
+			}
+		}
+		if removed {
+			changes = append(changes, fmt.Sprintf("remove server %s", albServerString(old)))
+			continue
+		}
+		remain = append(remain, old)
+	}
+	sgp.Servers = remain
+	return changes
+}
+
+func albServerString(s albsdk.BackendServer) string {
+	if s.ServerIp != "" && s.ServerIp != s.ServerId {
+		return fmt.Sprintf("%s(%s):%d", s.ServerId, s.ServerIp, s.Port)
+	}
+	return fmt.Sprintf("%s:%d", s.ServerId, s.Port)
+}
+
+func (m *SnapshotALB) RegisterALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error {
+	if len(serverGroupID) == 0 {
+		return fmt.Errorf("empty server group id when register servers error")
+	}
+	return m.updateServers(serverGroupID, func(sgp *albmodel.ServerGroupWithTags) []string {
+		return addALBServers(sgp, resServers)
+	})
+}
+
+func (m *SnapshotALB) DeregisterALBServers(ctx context.Context, serverGroupID string, sdkServers []albsdk.BackendServer) error {
+	if len(serverGroupID) == 0 {
+		return fmt.Errorf("empty server group id when deregister servers error")
+	}
+	return m.updateServers(serverGroupID, func(sgp *albmodel.ServerGroupWithTags) []string {
+		return removeALBServers(sgp, sdkServers)
+	})
+}
+
+func (m *SnapshotALB) ReplaceALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem, sdkServers []albsdk.BackendServer) error {
+	if len(serverGroupID) == 0 {
+		return fmt.Errorf("empty server group id when replace servers error")
+	}
+	return m.updateServers(serverGroupID, func(sgp *albmodel.ServerGroupWithTags) []string {
+		return append(removeALBServers(sgp, sdkServers), addALBServers(sgp, resServers)...)
+	})
+}
+
+func (m *SnapshotALB) ListALBServers(ctx context.Context, serverGroupID string) ([]albsdk.BackendServer, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	sgp, err := m.serverGroup(serverGroupID)
+	if err != nil {
+		return nil, err
+	}
+	return clone(sgp.Servers), nil
+}
+
+func (m *SnapshotALB) CreateALBServerGroup(ctx context.Context, resSGP *albmodel.ServerGroup, trackingProvider tracking.TrackingProvider) (albmodel.ServerGroupStatus, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	if err := m.state.checkQuota(ResourceALBServerGroup, len(m.state.snapshot.ALB.ServerGroups)); err != nil {
+		return albmodel.ServerGroupStatus{}, err
+	}
+	sgp := albmodel.ServerGroupWithTags{
+		Tags: trackingProvider.ResourceTags(resSGP.Stack(), resSGP, tagListToMap(resSGP.Spec.Tags)),
+	}
+	convert(resSGP.Spec.ALBServerGroupSpec, &sgp.ServerGroup)
+	sgp.ServerGroup.Tags = nil
+	sgp.ServerGroupId = m.state.newID(ResourceALBServerGroup)
+	sgp.ServerGroupStatus = "Available"
+	m.state.snapshot.ALB.ServerGroups = append(m.state.snapshot.ALB.ServerGroups, sgp)
+	m.state.record(Operation{
+		Action:   ActionCreate,
+		Resource: ResourceALBServerGroup,
+		ID:       sgp.ServerGroupId,
+		Name:     sgp.ServerGroupName,
+		Changes:  fields(resSGP.Spec.ALBServerGroupSpec, "ServerGroupId", "ServerGroupName", "ServerGroupStatus", "Tags"),
+	})
+	return albmodel.ServerGroupStatus{ServerGroupID: sgp.ServerGroupId}, nil
+}
+
+func (m *SnapshotALB) UpdateALBServerGroup(ctx context.Context, resSGP *albmodel.ServerGroup, sdkSGP albmodel.ServerGroupWithTags) (albmodel.ServerGroupStatus, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	sgp, err := m.serverGroup(sdkSGP.ServerGroupId)
+	if err != nil {
+		return albmodel.ServerGroupStatus{}, err
+	}
+	var desired albsdk.ServerGroup
+	convert(resSGP.Spec.ALBServerGroupSpec, &desired)
+	var changes []string
+	if desired.ServerGroupName != sgp.ServerGroupName {
+		changes = append(changes, change("ServerGroupName", sgp.ServerGroupName, desired.ServerGroupName))
+		sgp.ServerGroupName = desired.ServerGroupName
+	}
+	if desired.Scheduler != "" && !strings.EqualFold(desired.Scheduler, sgp.Scheduler) {
+		changes = append(changes, change("Scheduler", sgp.Scheduler, desired.Scheduler))
+		sgp.Scheduler = desired.Scheduler
+	}
+	if desired.HealthCheckConfig.HealthCheckEnabled != sgp.HealthCheckConfig.HealthCheckEnabled {
+		changes = append(changes, change("HealthCheckConfig.HealthCheckEnabled",
+			sgp.HealthCheckConfig.HealthCheckEnabled, desired.HealthCheckConfig.HealthCheckEnabled))
+		sgp.HealthCheckConfig = desired.HealthCheckConfig
+	} else if desired.HealthCheckConfig.HealthCheckEnabled {
+		healthCheckChanges := diff(sgp.HealthCheckConfig, desired.HealthCheckConfig)
+		for _, c := range healthCheckChanges {
+			changes = append(changes, "HealthCheckConfig."+c)
+		}
+		if len(healthCheckChanges) != 0 {
+			sgp.HealthCheckConfig = desired.HealthCheckConfig
+		}
+	}
+	if desired.StickySessionConfig.StickySessionEnabled != sgp.StickySessionConfig.StickySessionEnabled {
+		changes = append(changes, change("StickySessionConfig.StickySessionEnabled",
+			sgp.StickySessionConfig.StickySessionEnabled, desired.StickySessionConfig.StickySessionEnabled))
+		sgp.StickySessionConfig = desired.StickySessionConfig
+	} else if desired.StickySessionConfig.StickySessionEnabled {
+		stickySessionChanges := diff(sgp.StickySessionConfig, desired.StickySessionConfig)
+		for _, c := range stickySessionChanges {
+			changes = append(changes, "StickySessionConfig."+c)
+		}
+		if len(stickySessionChanges) != 0 {
+			sgp.StickySessionConfig = desired.StickySessionConfig
+		}
+	}
+	if len(changes) != 0 {
+		m.state.record(Operation{
+			Action:   ActionUpdate,
+			Resource: ResourceALBServerGroup,
+			ID:       sgp.ServerGroupId,
+			Name:     sgp.ServerGroupName,
+			Changes:  changes,
+		})
+	}
+	return albmodel.ServerGroupStatus{ServerGroupID: sgp.ServerGroupId}, nil
+}
+
+func (m *SnapshotALB) DeleteALBServerGroup(ctx context.Context, serverGroupID string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	sgp, err := m.serverGroup(serverGroupID)
+	if err != nil {
+		return err
+	}
+	m.state.record(Operation{
+		Action:   ActionDelete,
+		Resource: ResourceALBServerGroup,
+		ID:       serverGroupID,
+		Name:     sgp.ServerGroupName,
+	})
+	var sgps []albmodel.ServerGroupWithTags
+	for _, s := range m.state.snapshot.ALB.ServerGroups {
+		if s.ServerGroupId != serverGroupID {
+			sgps = append(sgps, s)
+		}
+	}
+	m.state.snapshot.ALB.ServerGroups = sgps
+	return nil
+}
+
+func (m *SnapshotALB) ListALBServerGroupsWithTags(ctx context.Context, tagFilters map[string]string) ([]albmodel.ServerGroupWithTags, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	var ret []albmodel.ServerGroupWithTags
+	for _, sgp := range m.state.snapshot.ALB.ServerGroups {
+		if matchTags(sgp.Tags, tagFilters) {
+			ret = append(ret, clone(sgp))
+		}
+	}
+	return ret, nil
+}
+
+func (m *SnapshotALB) ListALBsWithTags(ctx context.Context, tagFilters map[string]string) ([]albmodel.AlbLoadBalancerWithTags, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	var ret []albmodel.AlbLoadBalancerWithTags
+	for _, lb := range m.state.snapshot.ALB.LoadBalancers {
+		if matchTags(lb.Tags, tagFilters) {
+			ret = append(ret, clone(lb))
+		}
+	}
+	return ret, nil
+}
+
+func matchTags(tags, filters map[string]string) bool {
+	for k, v := range filters {
+		if tv, ok := tags[k]; !ok || tv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// convert copies the fields with the same json names, the fields failed to convert are left empty.
+func convert(from, to interface{}) {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return
+	}
+	_ = json.Unmarshal(data, to)
+}
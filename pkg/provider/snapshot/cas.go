@@ -0,0 +1,117 @@
+package snapshot
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+)
+
+var _ prvd.ICAS = &SnapshotCAS{}
+
+// SnapshotCAS serves the certificates uploaded to the certificate management service.
+type SnapshotCAS struct {
+	state *state
+}
+
+func (c *SnapshotCAS) DescribeSSLCertificatePublicKeyDetail(ctx context.Context, certId string) (*model.CertificateInfo, error) {
+	c.state.lock.Lock()
+	defer c.state.unlock()
+	for _, cert := range c.state.snapshot.Certificates {
+		if cert.CertIdentifier == certId {
+			ret := cert
+			return &ret, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *SnapshotCAS) DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
+	c.state.lock.Lock()
+	defer c.state.unlock()
+	return clone(c.state.snapshot.Certificates), nil
+}
+
+func (c *SnapshotCAS) DescribeSSLCertificateListByTags(ctx context.Context, tags []tag.Tag) ([]model.CertificateInfo, error) {
+	c.state.lock.Lock()
+	defer c.state.unlock()
+	var ret []model.CertificateInfo
+	for _, cert := range c.state.snapshot.Certificates {
+		if len(tags) != 0 && containsTags(cert.Tags, tags) {
+			ret = append(ret, clone(cert))
+		}
+	}
+	return ret, nil
+}
+
+func (c *SnapshotCAS) UploadSSLCertificate(ctx context.Context, certName, cert, key string, tags []tag.Tag) (string, error) {
+	c.state.lock.Lock()
+	defer c.state.unlock()
+	if err := c.state.checkQuota(ResourceCertificate, len(c.state.snapshot.Certificates)); err != nil {
+		return "", err
+	}
+	id := c.state.newID(ResourceCertificate)
+	c.state.snapshot.Certificates = append(c.state.snapshot.Certificates, model.CertificateInfo{
+		CertIdentifier: id,
+		CertName:       certName,
+		Tags:           tags,
+	})
+	c.state.record(Operation{Action: ActionCreate, Resource: ResourceCertificate, ID: id, Name: certName})
+	return id, nil
+}
+
+func (c *SnapshotCAS) DeleteSSLCertificate(ctx context.Context, certIdentifier string) error {
+	c.state.lock.Lock()
+	defer c.state.unlock()
+	c.state.snapshot.Certificates = c.deleteCertificate(c.state.snapshot.Certificates, ResourceCertificate, certIdentifier)
+	return nil
+}
+
+func (c *SnapshotCAS) UploadCACertificate(ctx context.Context, certName, cert string) (string, error) {
+	c.state.lock.Lock()
+	defer c.state.unlock()
+	if err := c.state.checkQuota(ResourceCACertificate, len(c.state.snapshot.CACertificates)); err != nil {
+		return "", err
+	}
+	id := c.state.newID(ResourceCACertificate)
+	c.state.snapshot.CACertificates = append(c.state.snapshot.CACertificates, model.CertificateInfo{
+		CertIdentifier: id,
+		CertName:       certName,
+	})
+	c.state.record(Operation{Action: ActionCreate, Resource: ResourceCACertificate, ID: id, Name: certName})
+	return id, nil
+}
+
+func (c *SnapshotCAS) DescribeCACertificateList(ctx context.Context, keyword string) ([]model.CertificateInfo, error) {
+	c.state.lock.Lock()
+	defer c.state.unlock()
+	var ret []model.CertificateInfo
+	for _, cert := range c.state.snapshot.CACertificates {
+		if strings.Contains(cert.CertName, keyword) {
+			ret = append(ret, cert)
+		}
+	}
+	return ret, nil
+}
+
+func (c *SnapshotCAS) DeleteCACertificate(ctx context.Context, certIdentifier string) error {
+	c.state.lock.Lock()
+	defer c.state.unlock()
+	c.state.snapshot.CACertificates = c.deleteCertificate(c.state.snapshot.CACertificates, ResourceCACertificate, certIdentifier)
+	return nil
+}
+
+// deleteCertificate records the deletion and returns the remaining certificates, the caller must hold the lock.
+func (c *SnapshotCAS) deleteCertificate(certs []model.CertificateInfo, resource, certIdentifier string) []model.CertificateInfo {
+	var ret []model.CertificateInfo
+	for _, cert := range certs {
+		if cert.CertIdentifier == certIdentifier {
+			c.state.record(Operation{Action: ActionDelete, Resource: resource, ID: certIdentifier, Name: cert.CertName})
+			continue
+		}
+		ret = append(ret, cert)
+	}
+	return ret
+}
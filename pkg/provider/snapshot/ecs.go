@@ -0,0 +1,309 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+)
+
+var _ prvd.IInstance = &SnapshotECS{}
+
+// SnapshotECS serves the instances of the nodes and the enis of the pods.
+type SnapshotECS struct {
+	state *state
+}
+
+func (e *SnapshotECS) ListInstances(ctx context.Context, ids []string) (map[string]*prvd.NodeAttribute, error) {
+	e.state.lock.Lock()
+	defer e.state.unlock()
+	ret := make(map[string]*prvd.NodeAttribute)
+	for _, id := range ids {
+		_, instanceID, err := util.NodeFromProviderID(id)
+		if err != nil {
+			return nil, err
+		}
+		ret[id] = nil
+		if ins := e.instance(instanceID); ins != nil {
+			found := clone(*ins)
+			ret[id] = &found
+		}
+	}
+	return ret, nil
+}
+
+// GetInstancesByIP returns the instance with the private ip. If the instance registration is enabled,
+// an instance is registered for the ip that does not belong to any instance.
+func (e *SnapshotECS) GetInstancesByIP(ctx context.Context, ips []string) (*prvd.NodeAttribute, error) {
+	e.state.lock.Lock()
+	defer e.state.unlock()
+	var found []*prvd.NodeAttribute
+	for i := range e.state.snapshot.Instances {
+		ins := &e.state.snapshot.Instances[i]
+		for _, addr := range ins.Addresses {
+			if addr.Type == v1.NodeInternalIP && contains(ips, addr.Address) {
+				found = append(found, ins)
+				break
+			}
+		}
+	}
+	if len(found) == 0 && len(ips) == 1 && e.state.registerInstances {
+		found = append(found, e.registerInstance(ips[0]))
+	}
+	if len(found) != 1 {
+		return nil, fmt.Errorf("find none or multiple instances by ip %s", ips)
+	}
+	ret := clone(*found[0])
+	return &ret, nil
+}
+
+func (e *SnapshotECS) instance(instanceID string) *prvd.NodeAttribute {
+	for i := range e.state.snapshot.Instances {
+		if e.state.snapshot.Instances[i].InstanceID == instanceID {
+			return &e.state.snapshot.Instances[i]
+		}
+	}
+	return nil
+}
+
+// registerInstance adds an instance in the zone of the cluster, with the ip on its primary eni.
+func (e *SnapshotECS) registerInstance(ip string) *prvd.NodeAttribute {
+	snap := e.state.snapshot
+	ins := prvd.NodeAttribute{
+		InstanceID:                e.state.newID(ResourceInstance),
+		InstanceType:              "ecs.g6.large",
+		Addresses:                 []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: ip}},
+		Zone:                      snap.ZoneID,
+		Region:                    snap.Region,
+		InstanceChargeType:        "PostPaid",
+		SpotStrategy:              "NoSpot",
+		PrimaryNetworkInterfaceID: e.state.newID(ResourceNetworkInterface),
+		Status:                    "Running",
+	}
+	if snap.NetworkInterfaces == nil {
+		snap.NetworkInterfaces = map[string]string{}
+	}
+	snap.NetworkInterfaces[ip] = ins.PrimaryNetworkInterfaceID
+	snap.Instances = append(snap.Instances, ins)
+	e.state.record(Operation{
+		Action:   ActionCreate,
+		Resource: ResourceInstance,
+		ID:       ins.InstanceID,
+		Changes:  []string{fmt.Sprintf("PrivateIpAddress: %q", ip)},
+	})
+	return &snap.Instances[len(snap.Instances)-1]
+}
+
+// DescribeNetworkInterfaces returns the enis in the snapshot, a placeholder is returned for
+// the ips not in the snapshot, as the plan does not depend on the eni id.
+func (e *SnapshotECS) DescribeNetworkInterfaces(vpcId string, ips []string, ipVersionType model.AddressIPVersionType) (map[string]string, error) {
+	e.state.lock.Lock()
+	defer e.state.unlock()
+	ret := make(map[string]string)
+	for _, ip := range ips {
+		if eni, ok := e.state.snapshot.NetworkInterfaces[ip]; ok {
+			ret[ip] = eni
+			continue
+		}
+		ret[ip] = "unknown-eni-" + ip
+	}
+	return ret, nil
+}
+
+// DescribeNetworkInterfacesByIDs returns the enis of the pods and the primary enis of the instances,
+// the unknown ids are left out like the OpenAPI.
+func (e *SnapshotECS) DescribeNetworkInterfacesByIDs(ids []string) ([]*prvd.EniAttribute, error) {
+	e.state.lock.Lock()
+	defer e.state.unlock()
+	var ret []*prvd.EniAttribute
+	for _, id := range ids {
+		ip, ok := e.networkInterfaceIP(id)
+		if !ok {
+			continue
+		}
+		check, ok := e.state.snapshot.SourceDestCheck[id]
+		ret = append(ret, &prvd.EniAttribute{
+			NetworkInterfaceID: id,
+			Status:             "InUse",
+			PrivateIPAddress:   ip,
+			SourceDestCheck:    check || !ok,
+		})
+	}
+	return ret, nil
+}
+
+func (e *SnapshotECS) networkInterfaceIP(id string) (string, bool) {
+	for ip, eni := range e.state.snapshot.NetworkInterfaces {
+		if eni == id {
+			return ip, true
+		}
+	}
+	for _, ins := range e.state.snapshot.Instances {
+		if ins.PrimaryNetworkInterfaceID != id {
+			continue
+		}
+		for _, addr := range ins.Addresses {
+			if addr.Type == v1.NodeInternalIP {
+				return addr.Address, true
+			}
+		}
+		return "", true
+	}
+	return "", false
+}
+
+func (e *SnapshotECS) ModifyNetworkInterfaceSourceDestCheck(id string, enabled bool) error {
+	e.state.lock.Lock()
+	defer e.state.unlock()
+	if _, ok := e.networkInterfaceIP(id); !ok {
+		return fmt.Errorf("eni %s not found in snapshot", id)
+	}
+	current, ok := e.state.snapshot.SourceDestCheck[id]
+	current = current || !ok
+	if current == enabled {
+		return nil
+	}
+	if e.state.snapshot.SourceDestCheck == nil {
+		e.state.snapshot.SourceDestCheck = map[string]bool{}
+	}
+	e.state.snapshot.SourceDestCheck[id] = enabled
+	e.state.record(Operation{
+		Action:   ActionUpdate,
+		Resource: ResourceNetworkInterface,
+		ID:       id,
+		Changes:  []string{change("SourceDestCheck", current, enabled)},
+	})
+	return nil
+}
+
+func (e *SnapshotECS) ListSecurityGroups(ctx context.Context, tags []tag.Tag) ([]model.SecurityGroup, error) {
+	e.state.lock.Lock()
+	defer e.state.unlock()
+	var ret []model.SecurityGroup
+	for _, sg := range e.state.snapshot.SecurityGroups {
+		if containsTags(sg.Tags, tags) {
+			ret = append(ret, clone(sg.SecurityGroup))
+		}
+	}
+	return ret, nil
+}
+
+func (e *SnapshotECS) CreateSecurityGroup(ctx context.Context, sg *model.SecurityGroup) error {
+	e.state.lock.Lock()
+	defer e.state.unlock()
+	if err := e.state.checkQuota(ResourceSecurityGroup, len(e.state.snapshot.SecurityGroups)); err != nil {
+		return err
+	}
+	sg.SecurityGroupId = e.state.newID(ResourceSecurityGroup)
+	e.state.snapshot.SecurityGroups = append(e.state.snapshot.SecurityGroups, SecurityGroup{SecurityGroup: clone(*sg)})
+	e.state.record(Operation{
+		Action:   ActionCreate,
+		Resource: ResourceSecurityGroup,
+		ID:       sg.SecurityGroupId,
+		Name:     sg.SecurityGroupName,
+		Changes:  fields(sg, "SecurityGroupId", "SecurityGroupName"),
+	})
+	return nil
+}
+
+// DeleteSecurityGroup fails if the security group is joined by a network load balancer, like the OpenAPI.
+func (e *SnapshotECS) DeleteSecurityGroup(ctx context.Context, sgId string) error {
+	e.state.lock.Lock()
+	defer e.state.unlock()
+	for _, lb := range e.state.snapshot.NetworkLoadBalancers {
+		if lb.LoadBalancerAttribute != nil && contains(lb.LoadBalancerAttribute.SecurityGroupIds, sgId) {
+			return fmt.Errorf("DependencyViolation: security group %s is joined by %s",
+				sgId, lb.LoadBalancerAttribute.LoadBalancerId)
+		}
+	}
+	var sgs []SecurityGroup
+	for _, sg := range e.state.snapshot.SecurityGroups {
+		if sg.SecurityGroupId == sgId {
+			e.state.record(Operation{Action: ActionDelete, Resource: ResourceSecurityGroup, ID: sgId, Name: sg.SecurityGroupName})
+			continue
+		}
+		sgs = append(sgs, sg)
+	}
+	e.state.snapshot.SecurityGroups = sgs
+	return nil
+}
+
+func (e *SnapshotECS) DescribeSecurityGroupRules(ctx context.Context, sgId string) ([]model.SecurityGroupRule, error) {
+	e.state.lock.Lock()
+	defer e.state.unlock()
+	sg := e.securityGroup(sgId)
+	if sg == nil {
+		return nil, fmt.Errorf("security group %s not found in snapshot", sgId)
+	}
+	return clone(sg.Rules), nil
+}
+
+func (e *SnapshotECS) AuthorizeSecurityGroupRules(ctx context.Context, sgId string, rules []model.SecurityGroupRule) error {
+	e.state.lock.Lock()
+	defer e.state.unlock()
+	sg := e.securityGroup(sgId)
+	if sg == nil {
+		return fmt.Errorf("security group %s not found in snapshot", sgId)
+	}
+	if err := e.state.checkQuota(ResourceSecurityGroupRule, len(sg.Rules)+len(rules)-1); err != nil {
+		return err
+	}
+	var changes []string
+	for _, r := range rules {
+		r.RuleId = e.state.newID(ResourceSecurityGroupRule)
+		sg.Rules = append(sg.Rules, r)
+		changes = append(changes, fmt.Sprintf("Rule: + %q", r.Key()))
+	}
+	e.state.record(Operation{
+		Action:   ActionUpdate,
+		Resource: ResourceSecurityGroup,
+		ID:       sgId,
+		Name:     sg.SecurityGroupName,
+		Changes:  changes,
+	})
+	return nil
+}
+
+func (e *SnapshotECS) RevokeSecurityGroupRules(ctx context.Context, sgId string, rules []model.SecurityGroupRule) error {
+	e.state.lock.Lock()
+	defer e.state.unlock()
+	sg := e.securityGroup(sgId)
+	if sg == nil {
+		return fmt.Errorf("security group %s not found in snapshot", sgId)
+	}
+	var ruleIds []string
+	for _, r := range rules {
+		ruleIds = append(ruleIds, r.RuleId)
+	}
+	var kept []model.SecurityGroupRule
+	var changes []string
+	for _, r := range sg.Rules {
+		if contains(ruleIds, r.RuleId) {
+			changes = append(changes, fmt.Sprintf("Rule: - %q", r.Key()))
+			continue
+		}
+		kept = append(kept, r)
+	}
+	sg.Rules = kept
+	e.state.record(Operation{
+		Action:   ActionUpdate,
+		Resource: ResourceSecurityGroup,
+		ID:       sgId,
+		Name:     sg.SecurityGroupName,
+		Changes:  changes,
+	})
+	return nil
+}
+
+func (e *SnapshotECS) securityGroup(sgId string) *SecurityGroup {
+	for i := range e.state.snapshot.SecurityGroups {
+		if e.state.snapshot.SecurityGroups[i].SecurityGroupId == sgId {
+			return &e.state.snapshot.SecurityGroups[i]
+		}
+	}
+	return nil
+}
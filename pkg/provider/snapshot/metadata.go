@@ -0,0 +1,96 @@
+package snapshot
+
+import (
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+)
+
+// NewSnapshotMetaData returns the metadata of the cluster recorded in the snapshot.
+func NewSnapshotMetaData(s *Snapshot) *SnapshotMetaData {
+	return &SnapshotMetaData{snapshot: s}
+}
+
+var _ prvd.IMetaData = &SnapshotMetaData{}
+
+// SnapshotMetaData only serves the values the controllers read when building the models.
+type SnapshotMetaData struct {
+	snapshot *Snapshot
+}
+
+func (m *SnapshotMetaData) HostName() (string, error) {
+	return "", notSupported("HostName")
+}
+
+func (m *SnapshotMetaData) ImageID() (string, error) {
+	return "", notSupported("ImageID")
+}
+
+func (m *SnapshotMetaData) InstanceID() (string, error) {
+	return "", notSupported("InstanceID")
+}
+
+func (m *SnapshotMetaData) Mac() (string, error) {
+	return "", notSupported("Mac")
+}
+
+func (m *SnapshotMetaData) NetworkType() (string, error) {
+	return "vpc", nil
+}
+
+func (m *SnapshotMetaData) OwnerAccountID() (string, error) {
+	return "", notSupported("OwnerAccountID")
+}
+
+func (m *SnapshotMetaData) PrivateIPv4() (string, error) {
+	return "", notSupported("PrivateIPv4")
+}
+
+func (m *SnapshotMetaData) Region() (string, error) {
+	return m.snapshot.Region, nil
+}
+
+func (m *SnapshotMetaData) SerialNumber() (string, error) {
+	return "", notSupported("SerialNumber")
+}
+
+func (m *SnapshotMetaData) SourceAddress() (string, error) {
+	return "", notSupported("SourceAddress")
+}
+
+func (m *SnapshotMetaData) VpcCIDRBlock() (string, error) {
+	if len(m.snapshot.VpcCIDRBlocks) == 0 {
+		return "", notSupported("VpcCIDRBlock")
+	}
+	return m.snapshot.VpcCIDRBlocks[0], nil
+}
+
+func (m *SnapshotMetaData) VpcID() (string, error) {
+	return m.snapshot.VpcID, nil
+}
+
+func (m *SnapshotMetaData) VswitchCIDRBlock() (string, error) {
+	return "", notSupported("VswitchCIDRBlock")
+}
+
+func (m *SnapshotMetaData) Zone() (string, error) {
+	return m.snapshot.ZoneID, nil
+}
+
+func (m *SnapshotMetaData) NTPConfigServers() ([]string, error) {
+	return nil, notSupported("NTPConfigServers")
+}
+
+func (m *SnapshotMetaData) RoleName() (string, error) {
+	return "", notSupported("RoleName")
+}
+
+func (m *SnapshotMetaData) RamRoleToken(role string) (prvd.RoleAuth, error) {
+	return prvd.RoleAuth{}, notSupported("RamRoleToken")
+}
+
+func (m *SnapshotMetaData) VswitchID() (string, error) {
+	return m.snapshot.VswitchID, nil
+}
+
+func (m *SnapshotMetaData) ClusterID() string {
+	return m.snapshot.ClusterID
+}
@@ -0,0 +1,723 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/nlb"
+)
+
+var _ prvd.INLB = &SnapshotNLB{}
+
+// SnapshotNLB serves the network load balancers, their listeners and server groups.
+// The async APIs apply the changes at once, and their jobs finish after the job duration.
+type SnapshotNLB struct {
+	state *state
+}
+
+// nlb returns the network load balancer in the snapshot, the caller must hold the lock.
+func (m *SnapshotNLB) nlb(lbId string) (*nlbmodel.NetworkLoadBalancer, error) {
+	for i := range m.state.snapshot.NetworkLoadBalancers {
+		lb := &m.state.snapshot.NetworkLoadBalancers[i]
+		if lb.GetLoadBalancerId() == lbId {
+			return lb, nil
+		}
+	}
+	return nil, fmt.Errorf("nlb %s not found in snapshot", lbId)
+}
+
+// serverGroup returns the server group in the snapshot, the caller must hold the lock.
+func (m *SnapshotNLB) serverGroup(sgId string) (*nlbmodel.ServerGroup, error) {
+	for i := range m.state.snapshot.NLBServerGroups {
+		if m.state.snapshot.NLBServerGroups[i].ServerGroupId == sgId {
+			return &m.state.snapshot.NLBServerGroups[i], nil
+		}
+	}
+	return nil, fmt.Errorf("nlb server group %s not found in snapshot", sgId)
+}
+
+// listener returns the listener and its load balancer, the caller must hold the lock.
+func (m *SnapshotNLB) listener(listenerId string) (*nlbmodel.NetworkLoadBalancer, int, error) {
+	for i := range m.state.snapshot.NetworkLoadBalancers {
+		lb := &m.state.snapshot.NetworkLoadBalancers[i]
+		for j := range lb.Listeners {
+			if lb.Listeners[j].ListenerId == listenerId {
+				return lb, j, nil
+			}
+		}
+	}
+	return nil, 0, fmt.Errorf("nlb listener %s not found in snapshot", listenerId)
+}
+
+func (m *SnapshotNLB) TagNLBResource(ctx context.Context, resourceId string, resourceType nlbmodel.TagResourceType, tags []tag.Tag) error {
+	if resourceType == nlbmodel.ServerGroupTagType {
+		return m.updateServerGroup(resourceId, func(sg *nlbmodel.ServerGroup) {
+			sg.Tags = mergeTags(sg.Tags, tags)
+		})
+	}
+	return m.updateAttribute(resourceId, func(attr *nlbmodel.LoadBalancerAttribute) {
+		attr.Tags = mergeTags(attr.Tags, tags)
+	})
+}
+
+func (m *SnapshotNLB) UntagNLBResources(ctx context.Context, resourceId string, resourceType nlbmodel.TagResourceType, tagKey []*string) error {
+	var keys []string
+	for _, k := range tagKey {
+		if k != nil {
+			keys = append(keys, *k)
+		}
+	}
+	if resourceType == nlbmodel.ServerGroupTagType {
+		return m.updateServerGroup(resourceId, func(sg *nlbmodel.ServerGroup) {
+			sg.Tags = removeTags(sg.Tags, keys)
+		})
+	}
+	return m.updateAttribute(resourceId, func(attr *nlbmodel.LoadBalancerAttribute) {
+		attr.Tags = removeTags(attr.Tags, keys)
+	})
+}
+
+func (m *SnapshotNLB) ListNLBTagResources(ctx context.Context, lbId string) ([]tag.Tag, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.nlb(lbId)
+	if err != nil {
+		return nil, err
+	}
+	return clone(lb.LoadBalancerAttribute.Tags), nil
+}
+
+func (m *SnapshotNLB) FindNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+
+	// 1. find by nlb id
+	if mdl.LoadBalancerAttribute.LoadBalancerId != "" {
+		lb, err := m.nlb(mdl.LoadBalancerAttribute.LoadBalancerId)
+		if err != nil {
+			return err
+		}
+		mdl.LoadBalancerAttribute = clone(lb.LoadBalancerAttribute)
+		return nil
+	}
+
+	// 2. find by tags, 3. find by name
+	for _, match := range []func(lb *nlbmodel.NetworkLoadBalancer) bool{
+		func(lb *nlbmodel.NetworkLoadBalancer) bool {
+			return len(mdl.LoadBalancerAttribute.Tags) != 0 &&
+				containsTags(lb.LoadBalancerAttribute.Tags, mdl.LoadBalancerAttribute.Tags)
+		},
+		func(lb *nlbmodel.NetworkLoadBalancer) bool {
+			return mdl.LoadBalancerAttribute.Name != "" &&
+				lb.LoadBalancerAttribute.Name == mdl.LoadBalancerAttribute.Name &&
+				strings.EqualFold(lb.LoadBalancerAttribute.LoadBalancerStatus, "Active")
+		},
+	} {
+		var found []*nlbmodel.NetworkLoadBalancer
+		for i := range m.state.snapshot.NetworkLoadBalancers {
+			if match(&m.state.snapshot.NetworkLoadBalancers[i]) {
+				found = append(found, &m.state.snapshot.NetworkLoadBalancers[i])
+			}
+		}
+		if len(found) > 1 {
+			var lbIds []string
+			for _, lb := range found {
+				lbIds = append(lbIds, lb.GetLoadBalancerId())
+			}
+			return fmt.Errorf("[%s] find multiple nlbs, lbIds[%s]", mdl.NamespacedName, strings.Join(lbIds, ","))
+		}
+		if len(found) == 1 {
+			mdl.LoadBalancerAttribute = clone(found[0].LoadBalancerAttribute)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *SnapshotNLB) DescribeNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.nlb(mdl.LoadBalancerAttribute.LoadBalancerId)
+	if err != nil {
+		return err
+	}
+	mdl.LoadBalancerAttribute = clone(lb.LoadBalancerAttribute)
+	return nil
+}
+
+func (m *SnapshotNLB) CreateNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer, clientToken string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	if err := m.state.checkQuota(ResourceNLB, len(m.state.snapshot.NetworkLoadBalancers)); err != nil {
+		return err
+	}
+	lb := nlbmodel.NetworkLoadBalancer{
+		NamespacedName:        mdl.NamespacedName,
+		LoadBalancerAttribute: clone(mdl.LoadBalancerAttribute),
+	}
+	lb.LoadBalancerAttribute.LoadBalancerId = m.state.newID(ResourceNLB)
+	lb.LoadBalancerAttribute.LoadBalancerStatus = "Active"
+	m.state.snapshot.NetworkLoadBalancers = append(m.state.snapshot.NetworkLoadBalancers, lb)
+	m.state.record(Operation{
+		Action:   ActionCreate,
+		Resource: ResourceNLB,
+		ID:       lb.LoadBalancerAttribute.LoadBalancerId,
+		Name:     lb.LoadBalancerAttribute.Name,
+		Changes:  fields(mdl.LoadBalancerAttribute, "IsUserManaged", "Name", "PreserveOnDelete"),
+	})
+	mdl.LoadBalancerAttribute.LoadBalancerId = lb.LoadBalancerAttribute.LoadBalancerId
+	return nil
+}
+
+func (m *SnapshotNLB) DeleteNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lbId := mdl.GetLoadBalancerId()
+	lb, err := m.nlb(lbId)
+	if err != nil {
+		return err
+	}
+	m.state.record(Operation{
+		Action:   ActionDelete,
+		Resource: ResourceNLB,
+		ID:       lbId,
+		Name:     lb.LoadBalancerAttribute.Name,
+	})
+	var lbs []nlbmodel.NetworkLoadBalancer
+	for _, l := range m.state.snapshot.NetworkLoadBalancers {
+		if l.GetLoadBalancerId() != lbId {
+			lbs = append(lbs, l)
+		}
+	}
+	m.state.snapshot.NetworkLoadBalancers = lbs
+	return nil
+}
+
+// updateAttribute applies the change to the attributes of the nlb and records it.
+func (m *SnapshotNLB) updateAttribute(lbId string, update func(attr *nlbmodel.LoadBalancerAttribute)) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.nlb(lbId)
+	if err != nil {
+		return err
+	}
+	desired := clone(lb.LoadBalancerAttribute)
+	update(desired)
+	changes := diff(lb.LoadBalancerAttribute, desired)
+	lb.LoadBalancerAttribute = desired
+	if len(changes) != 0 {
+		m.state.record(Operation{
+			Action:   ActionUpdate,
+			Resource: ResourceNLB,
+			ID:       lbId,
+			Name:     lb.LoadBalancerAttribute.Name,
+			Changes:  changes,
+		})
+	}
+	return nil
+}
+
+func (m *SnapshotNLB) UpdateNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	return m.updateAttribute(mdl.GetLoadBalancerId(), func(attr *nlbmodel.LoadBalancerAttribute) {
+		if mdl.LoadBalancerAttribute.Name != "" {
+			attr.Name = mdl.LoadBalancerAttribute.Name
+		}
+	})
+}
+
+func (m *SnapshotNLB) UpdateNLBAddressType(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	return m.updateAttribute(mdl.GetLoadBalancerId(), func(attr *nlbmodel.LoadBalancerAttribute) {
+		attr.AddressType = mdl.LoadBalancerAttribute.AddressType
+		// the eips of the zones are bound when the nlb turns to internet, and unbound when it turns to intranet
+		for i := range attr.ZoneMappings {
+			if attr.AddressType != nlbmodel.InternetAddressType {
+				attr.ZoneMappings[i].AllocationId = ""
+				continue
+			}
+			for _, z := range mdl.LoadBalancerAttribute.ZoneMappings {
+				if z.ZoneId == attr.ZoneMappings[i].ZoneId && z.AllocationId != "" {
+					attr.ZoneMappings[i].AllocationId = z.AllocationId
+				}
+			}
+		}
+	})
+}
+
+func (m *SnapshotNLB) UpdateNLBZones(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	return m.updateAttribute(mdl.GetLoadBalancerId(), func(attr *nlbmodel.LoadBalancerAttribute) {
+		attr.ZoneMappings = clone(mdl.LoadBalancerAttribute.ZoneMappings)
+	})
+}
+
+func (m *SnapshotNLB) UpdateNLBSecurityGroupIds(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer, added, removed []string) error {
+	return m.updateAttribute(mdl.GetLoadBalancerId(), func(attr *nlbmodel.LoadBalancerAttribute) {
+		var ids []string
+		for _, id := range attr.SecurityGroupIds {
+			if !contains(removed, id) {
+				ids = append(ids, id)
+			}
+		}
+		attr.SecurityGroupIds = append(ids, added...)
+	})
+}
+
+func (m *SnapshotNLB) UpdateLoadBalancerProtection(ctx context.Context, lbId string,
+	delCfg *nlbmodel.DeletionProtectionConfig, modCfg *nlbmodel.ModificationProtectionConfig) error {
+	return m.updateAttribute(lbId, func(attr *nlbmodel.LoadBalancerAttribute) {
+		if delCfg != nil {
+			attr.DeletionProtectionConfig = clone(delCfg)
+		}
+		if modCfg != nil {
+			attr.ModificationProtectionConfig = clone(modCfg)
+		}
+	})
+}
+
+func (m *SnapshotNLB) AttachCommonBandwidthPackageToLoadBalancer(ctx context.Context, lbId string, bandwidthPackageId string) error {
+	return m.updateAttribute(lbId, func(attr *nlbmodel.LoadBalancerAttribute) {
+		attr.BandwidthPackageId = &bandwidthPackageId
+	})
+}
+
+func (m *SnapshotNLB) DetachCommonBandwidthPackageFromLoadBalancer(ctx context.Context, lbId string, bandwidthPackageId string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.nlb(lbId)
+	if err != nil {
+		return err
+	}
+	// diff leaves out the unset fields, so the detach is recorded here
+	m.state.record(Operation{
+		Action:   ActionUpdate,
+		Resource: ResourceNLB,
+		ID:       lbId,
+		Name:     lb.LoadBalancerAttribute.Name,
+		Changes:  []string{change("BandwidthPackageId", bandwidthPackageId, "")},
+	})
+	lb.LoadBalancerAttribute.BandwidthPackageId = nil
+	return nil
+}
+
+func (m *SnapshotNLB) UpdateNLBIPv6AddressType(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	return m.updateAttribute(mdl.GetLoadBalancerId(), func(attr *nlbmodel.LoadBalancerAttribute) {
+		attr.IPv6AddressType = mdl.LoadBalancerAttribute.IPv6AddressType
+	})
+}
+
+func (m *SnapshotNLB) ListNLBServerGroups(ctx context.Context, tags []tag.Tag) ([]*nlbmodel.ServerGroup, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	var ret []*nlbmodel.ServerGroup
+	for _, sg := range m.state.snapshot.NLBServerGroups {
+		if !containsTags(sg.Tags, tags) {
+			continue
+		}
+		ret = append(ret, remoteServerGroup(sg))
+	}
+	return ret, nil
+}
+
+func (m *SnapshotNLB) GetNLBServerGroup(ctx context.Context, sgId string) (*nlbmodel.ServerGroup, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	sg, err := m.serverGroup(sgId)
+	if err != nil {
+		return nil, nil
+	}
+	return remoteServerGroup(*sg), nil
+}
+
+// remoteServerGroup returns a copy of the server group as the OpenAPI describes it.
+func remoteServerGroup(sg nlbmodel.ServerGroup) *nlbmodel.ServerGroup {
+	ret := clone(sg)
+	ret.ServicePort, ret.Weight, ret.InitialServers = nil, nil, nil
+	namedKey, err := nlbmodel.LoadNLBSGNamedKey(ret.ServerGroupName)
+	ret.NamedKey = namedKey
+	ret.IsUserManaged = err != nil
+	return &ret
+}
+
+func (m *SnapshotNLB) CreateNLBServerGroup(ctx context.Context, sg *nlbmodel.ServerGroup) error {
+	jobId, err := m.CreateNLBServerGroupAsync(ctx, sg)
+	return m.waitJob(ctx, "CreateNLBServerGroup", jobId, err)
+}
+
+func (m *SnapshotNLB) CreateNLBServerGroupAsync(ctx context.Context, sg *nlbmodel.ServerGroup) (string, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	if err := m.state.checkQuota(ResourceNLBServerGroup, len(m.state.snapshot.NLBServerGroups)); err != nil {
+		return "", err
+	}
+	sg.ServerGroupId = m.state.newID(ResourceNLBServerGroup)
+	created := remoteServerGroup(*sg)
+	created.Servers = nil
+	m.state.snapshot.NLBServerGroups = append(m.state.snapshot.NLBServerGroups, *created)
+	m.state.record(Operation{
+		Action:   ActionCreate,
+		Resource: ResourceNLBServerGroup,
+		ID:       sg.ServerGroupId,
+		Name:     sg.ServerGroupName,
+		Changes: fields(*created, "IsUserManaged", "NamedKey", "ServerGroupId", "ServerGroupName",
+			"Servers", "IgnoreWeightUpdate"),
+	})
+	return m.state.newJob(), nil
+}
+
+func (m *SnapshotNLB) DeleteNLBServerGroup(ctx context.Context, sgId string) error {
+	jobId, err := m.DeleteNLBServerGroupAsync(ctx, sgId)
+	return m.waitJob(ctx, "DeleteNLBServerGroup", jobId, err)
+}
+
+func (m *SnapshotNLB) DeleteNLBServerGroupAsync(ctx context.Context, sgId string) (string, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	sg, err := m.serverGroup(sgId)
+	if err != nil {
+		return "", err
+	}
+	m.state.record(Operation{
+		Action:   ActionDelete,
+		Resource: ResourceNLBServerGroup,
+		ID:       sgId,
+		Name:     sg.ServerGroupName,
+	})
+	var sgs []nlbmodel.ServerGroup
+	for _, s := range m.state.snapshot.NLBServerGroups {
+		if s.ServerGroupId != sgId {
+			sgs = append(sgs, s)
+		}
+	}
+	m.state.snapshot.NLBServerGroups = sgs
+	return m.state.newJob(), nil
+}
+
+// updateServerGroup applies the change to the attributes of the server group and records it.
+func (m *SnapshotNLB) updateServerGroup(sgId string, update func(sg *nlbmodel.ServerGroup)) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	sg, err := m.serverGroup(sgId)
+	if err != nil {
+		return err
+	}
+	desired := clone(*sg)
+	update(&desired)
+	changes := diff(*sg, desired, "Servers")
+	*sg = desired
+	if len(changes) != 0 {
+		m.state.record(Operation{
+			Action:   ActionUpdate,
+			Resource: ResourceNLBServerGroup,
+			ID:       sgId,
+			Name:     sg.ServerGroupName,
+			Changes:  changes,
+		})
+	}
+	return nil
+}
+
+func (m *SnapshotNLB) UpdateNLBServerGroup(ctx context.Context, sg *nlbmodel.ServerGroup) error {
+	jobId, err := m.UpdateNLBServerGroupAsync(ctx, sg)
+	return m.waitJob(ctx, "UpdateNLBServerGroup", jobId, err)
+}
+
+func (m *SnapshotNLB) UpdateNLBServerGroupAsync(ctx context.Context, sg *nlbmodel.ServerGroup) (string, error) {
+	err := m.updateServerGroup(sg.ServerGroupId, func(remote *nlbmodel.ServerGroup) {
+		local := remoteServerGroup(*sg)
+		local.Servers, local.Tags = remote.Servers, remote.Tags
+		local.NamedKey, local.IsUserManaged = remote.NamedKey, remote.IsUserManaged
+		*remote = *local
+	})
+	if err != nil {
+		return "", err
+	}
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	return m.state.newJob(), nil
+}
+
+// updateServers applies the change to the servers of the server group and records it.
+func (m *SnapshotNLB) updateServers(sgId string, backends []nlbmodel.ServerGroupServer,
+	update func(sg *nlbmodel.ServerGroup, backends []nlbmodel.ServerGroupServer) []string) (string, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	sg, err := m.serverGroup(sgId)
+	if err != nil {
+		return "", err
+	}
+	changes := update(sg, backends)
+	if len(changes) != 0 {
+		m.state.record(Operation{
+			Action:   ActionUpdate,
+			Resource: ResourceNLBServerGroup,
+			ID:       sgId,
+			Name:     sg.ServerGroupName,
+			Changes:  changes,
+		})
+	}
+	return m.state.newJob(), nil
+}
+
+func (m *SnapshotNLB) AddNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) error {
+	jobId, err := m.AddNLBServersAsync(ctx, sgId, backends)
+	return m.waitJob(ctx, "AddNLBServers", jobId, err)
+}
+
+func (m *SnapshotNLB) AddNLBServersAsync(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) (string, error) {
+	return m.updateServers(sgId, backends, func(sg *nlbmodel.ServerGroup, bs []nlbmodel.ServerGroupServer) []string {
+		var changes []string
+		for _, b := range bs {
+			s := nlbServer(b)
+			sg.Servers = append(sg.Servers, s)
+			changes = append(changes, fmt.Sprintf("add server %s weight %d", nlbServerString(s), s.Weight))
+		}
+		return changes
+	})
+}
+
+func (m *SnapshotNLB) RemoveNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) error {
+	jobId, err := m.RemoveNLBServersAsync(ctx, sgId, backends)
+	return m.waitJob(ctx, "RemoveNLBServers", jobId, err)
+}
+
+func (m *SnapshotNLB) RemoveNLBServersAsync(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) (string, error) {
+	return m.updateServers(sgId, backends, func(sg *nlbmodel.ServerGroup, bs []nlbmodel.ServerGroupServer) []string {
+		var changes []string
+		var remain []nlbmodel.ServerGroupServer
+		for _, old := range sg.Servers {
+			removed := false
+			for _, b := range bs {
+				if sameNLBServer(old, b) {
+					removed = true
+					break
+				}
+			}
+			if removed {
+				changes = append(changes, fmt.Sprintf("remove server %s", nlbServerString(old)))
+				continue
+			}
+			remain = append(remain, old)
+		}
+		sg.Servers = remain
+		return changes
+	})
+}
+
+func (m *SnapshotNLB) UpdateNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) error {
+	jobId, err := m.UpdateNLBServersAsync(ctx, sgId, backends)
+	return m.waitJob(ctx, "UpdateNLBServers", jobId, err)
+}
+
+func (m *SnapshotNLB) UpdateNLBServersAsync(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) (string, error) {
+	return m.updateServers(sgId, backends, func(sg *nlbmodel.ServerGroup, bs []nlbmodel.ServerGroupServer) []string {
+		var changes []string
+		for _, b := range bs {
+			for i := range sg.Servers {
+				old := &sg.Servers[i]
+				if !sameNLBServer(*old, b) {
+					continue
+				}
+				if old.Weight != b.Weight {
+					changes = append(changes, fmt.Sprintf("server %s weight: %d -> %d", nlbServerString(*old), old.Weight, b.Weight))
+				}
+				if old.Description != b.Description {
+					changes = append(changes, fmt.Sprintf("server %s description: %q -> %q", nlbServerString(*old), old.Description, b.Description))
+				}
+				old.Weight, old.Description = b.Weight, b.Description
+			}
+		}
+		return changes
+	})
+}
+
+// nlbServer returns the server as the OpenAPI describes it.
+func nlbServer(b nlbmodel.ServerGroupServer) nlbmodel.ServerGroupServer {
+	return nlbmodel.ServerGroupServer{
+		ServerGroupId: b.ServerGroupId,
+		Description:   b.Description,
+		ServerId:      b.ServerId,
+		ServerIp:      b.ServerIp,
+		ServerType:    b.ServerType,
+		Port:          b.Port,
+		Weight:        b.Weight,
+		ZoneId:        b.ZoneId,
+		Status:        "Available",
+	}
+}
+
+func sameNLBServer(a, b nlbmodel.ServerGroupServer) bool {
+	if a.ServerId != b.ServerId || a.Port != b.Port {
+		return false
+	}
+	return a.ServerIp == "" || b.ServerIp == "" || a.ServerIp == b.ServerIp
+}
+
+func nlbServerString(s nlbmodel.ServerGroupServer) string {
+	if s.ServerIp != "" && s.ServerIp != s.ServerId {
+		return fmt.Sprintf("%s(%s):%d", s.ServerId, s.ServerIp, s.Port)
+	}
+	return fmt.Sprintf("%s:%d", s.ServerId, s.Port)
+}
+
+func (m *SnapshotNLB) ListNLBListeners(ctx context.Context, lbId string) ([]*nlbmodel.ListenerAttribute, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.nlb(lbId)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*nlbmodel.ListenerAttribute
+	for _, l := range lb.Listeners {
+		lis := clone(l)
+		lis.ServicePort, lis.ServerGroupName = nil, ""
+		namedKey, err := nlbmodel.LoadNLBListenerNamedKey(lis.ListenerDescription)
+		lis.NamedKey = namedKey
+		lis.IsUserManaged = err != nil
+		ret = append(ret, lis)
+	}
+	return ret, nil
+}
+
+func (m *SnapshotNLB) CreateNLBListener(ctx context.Context, lbId string, lis *nlbmodel.ListenerAttribute) error {
+	jobId, err := m.CreateNLBListenerAsync(ctx, lbId, lis)
+	return m.waitJob(ctx, "CreateNLBListener", jobId, err)
+}
+
+func (m *SnapshotNLB) CreateNLBListenerAsync(ctx context.Context, lbId string, lis *nlbmodel.ListenerAttribute) (string, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.nlb(lbId)
+	if err != nil {
+		return "", err
+	}
+	if err := m.state.checkQuota(ResourceNLBListener, len(lb.Listeners)); err != nil {
+		return "", err
+	}
+	created := clone(lis)
+	created.ListenerId = m.state.newID(ResourceNLBListener)
+	created.LoadBalancerId = lbId
+	created.ListenerStatus = "Running"
+	lb.Listeners = append(lb.Listeners, created)
+	m.state.record(Operation{
+		Action:         ActionCreate,
+		Resource:       ResourceNLBListener,
+		ID:             nlbListenerKey(created),
+		LoadBalancerID: lbId,
+		Changes: fields(created, "IsUserManaged", "NamedKey", "ServicePort", "ListenerId", "LoadBalancerId",
+			"ListenerStatus", "ListenerProtocol", "ListenerPort", "StartPort", "EndPort"),
+	})
+	return m.state.newJob(), nil
+}
+
+func (m *SnapshotNLB) UpdateNLBListener(ctx context.Context, lis *nlbmodel.ListenerAttribute) error {
+	jobId, err := m.UpdateNLBListenerAsync(ctx, lis)
+	return m.waitJob(ctx, "UpdateNLBListener", jobId, err)
+}
+
+func (m *SnapshotNLB) UpdateNLBListenerAsync(ctx context.Context, lis *nlbmodel.ListenerAttribute) (string, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, i, err := m.listener(lis.ListenerId)
+	if err != nil {
+		return "", err
+	}
+	current := lb.Listeners[i]
+	desired := clone(lis)
+	desired.LoadBalancerId, desired.ListenerStatus = current.LoadBalancerId, current.ListenerStatus
+	changes := diff(current, desired, "IsUserManaged", "NamedKey", "ServicePort", "ServerGroupName")
+	lb.Listeners[i] = desired
+	if len(changes) != 0 {
+		m.state.record(Operation{
+			Action:         ActionUpdate,
+			Resource:       ResourceNLBListener,
+			ID:             nlbListenerKey(desired),
+			LoadBalancerID: lb.GetLoadBalancerId(),
+			Changes:        changes,
+		})
+	}
+	return m.state.newJob(), nil
+}
+
+func (m *SnapshotNLB) DeleteNLBListener(ctx context.Context, listenerId string) error {
+	jobId, err := m.DeleteNLBListenerAsync(ctx, listenerId)
+	return m.waitJob(ctx, "DeleteNLBListener", jobId, err)
+}
+
+func (m *SnapshotNLB) DeleteNLBListenerAsync(ctx context.Context, listenerId string) (string, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, i, err := m.listener(listenerId)
+	if err != nil {
+		return "", err
+	}
+	m.state.record(Operation{
+		Action:         ActionDelete,
+		Resource:       ResourceNLBListener,
+		ID:             nlbListenerKey(lb.Listeners[i]),
+		LoadBalancerID: lb.GetLoadBalancerId(),
+	})
+	lb.Listeners = append(lb.Listeners[:i], lb.Listeners[i+1:]...)
+	return m.state.newJob(), nil
+}
+
+func (m *SnapshotNLB) StartNLBListener(ctx context.Context, listenerId string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, i, err := m.listener(listenerId)
+	if err != nil {
+		return err
+	}
+	lis := lb.Listeners[i]
+	m.state.record(Operation{
+		Action:         ActionUpdate,
+		Resource:       ResourceNLBListener,
+		ID:             nlbListenerKey(lis),
+		LoadBalancerID: lb.GetLoadBalancerId(),
+		Changes:        []string{change("ListenerStatus", lis.ListenerStatus, "Running")},
+	})
+	lis.ListenerStatus = "Running"
+	return nil
+}
+
+func nlbListenerKey(lis *nlbmodel.ListenerAttribute) string {
+	return fmt.Sprintf("%s:%s", nlbmodel.GetListenerProtocolType(lis.ListenerProtocol), lis.PortString())
+}
+
+// waitJob waits for the job of a sync API, like the NLB provider.
+func (m *SnapshotNLB) waitJob(ctx context.Context, api, jobId string, err error) error {
+	if err != nil {
+		return err
+	}
+	return m.BatchWaitJobsFinish(ctx, api, []string{jobId})
+}
+
+// BatchWaitJobsFinish waits until the jobs finish, with the interval and the timeout in args
+// like the NLB provider.
+func (m *SnapshotNLB) BatchWaitJobsFinish(ctx context.Context, api string, jobIds []string, args ...time.Duration) error {
+	interval, timeout := nlb.DefaultRetryInterval, nlb.DefaultRetryTimeout
+	if len(args) >= 2 {
+		interval, timeout = args[0], args[1]
+	}
+	return wait.PollUntilContextTimeout(ctx, interval, timeout, true, func(ctx context.Context) (bool, error) {
+		m.state.lock.Lock()
+		defer m.state.unlock()
+		for _, id := range jobIds {
+			finish, ok := m.state.jobs[id]
+			if !ok {
+				return false, fmt.Errorf("%s job %s not found", api, id)
+			}
+			if time.Now().Before(finish) {
+				return false, nil
+			}
+		}
+		for _, id := range jobIds {
+			delete(m.state.jobs, id)
+		}
+		return true, nil
+	})
+}
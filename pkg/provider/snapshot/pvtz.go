@@ -0,0 +1,150 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+)
+
+var _ prvd.IPrivateZone = &SnapshotPVTZ{}
+
+// SnapshotPVTZ serves the records of the private zone. The records in the snapshot are
+// managed by the controller, the values of each rr and type are kept in one endpoint.
+type SnapshotPVTZ struct {
+	state *state
+}
+
+func (p *SnapshotPVTZ) ListPVTZ(ctx context.Context) ([]*model.PvtzEndpoint, error) {
+	return p.SearchPVTZ(ctx, &model.PvtzEndpoint{}, false)
+}
+
+func (p *SnapshotPVTZ) SearchPVTZ(ctx context.Context, ep *model.PvtzEndpoint, exact bool) ([]*model.PvtzEndpoint, error) {
+	p.state.lock.Lock()
+	defer p.state.unlock()
+	return p.search(ep.Rr, exact), nil
+}
+
+func (p *SnapshotPVTZ) search(rr string, exact bool) []*model.PvtzEndpoint {
+	ret := make([]*model.PvtzEndpoint, 0)
+	for _, e := range p.state.snapshot.PrivateZoneRecords {
+		if rr != "" && ((exact && e.Rr != rr) || (!exact && !strings.Contains(e.Rr, rr))) {
+			continue
+		}
+		found := clone(e)
+		ret = append(ret, &found)
+	}
+	return ret
+}
+
+// UpdatePVTZ adds the values not in the records and deletes the values not in the endpoint,
+// like the PVTZ provider.
+func (p *SnapshotPVTZ) UpdatePVTZ(ctx context.Context, ep *model.PvtzEndpoint) error {
+	p.state.lock.Lock()
+	defer p.state.unlock()
+	if ep.Rr == "" {
+		return fmt.Errorf("UpdatePVTZ query old zone records error: endpoint %s %s not found", ep.Rr, ep.Type)
+	}
+	i := p.record(ep.Rr, ep.Type)
+	if i < 0 {
+		p.state.snapshot.PrivateZoneRecords = append(p.state.snapshot.PrivateZoneRecords,
+			model.PvtzEndpoint{Rr: ep.Rr, Type: ep.Type, Ttl: ep.Ttl})
+		i = len(p.state.snapshot.PrivateZoneRecords) - 1
+	}
+	current := &p.state.snapshot.PrivateZoneRecords[i]
+	var values []model.PvtzValue
+	for _, val := range current.Values {
+		if !val.InVals(ep.Values) {
+			p.state.record(Operation{Action: ActionDelete, Resource: ResourcePrivateZoneRecord, ID: fmt.Sprint(val.RecordId), Name: ep.Rr})
+			continue
+		}
+		values = append(values, val)
+	}
+	// the records of other rrs are in use as well
+	used := p.count() - len(current.Values)
+	nextID := p.nextRecordID()
+	var err error
+	for _, val := range ep.Values {
+		if val.InVals(current.Values) {
+			continue
+		}
+		if err = p.state.checkQuota(ResourcePrivateZoneRecord, used+len(values)); err != nil {
+			break
+		}
+		created := model.PvtzValue{Data: val.Data, RecordId: nextID}
+		nextID++
+		values = append(values, created)
+		p.state.record(Operation{
+			Action:   ActionCreate,
+			Resource: ResourcePrivateZoneRecord,
+			ID:       fmt.Sprint(created.RecordId),
+			Name:     ep.Rr,
+			Changes:  []string{fmt.Sprintf("Type: %s", ep.Type), fmt.Sprintf("Value: %s", val.Data)},
+		})
+	}
+	current.Values = values
+	p.removeEmpty()
+	return err
+}
+
+// DeletePVTZ deletes the values of the first record of the rr, the type is matched if set.
+func (p *SnapshotPVTZ) DeletePVTZ(ctx context.Context, ep *model.PvtzEndpoint) error {
+	p.state.lock.Lock()
+	defer p.state.unlock()
+	if ep.Rr == "" {
+		return fmt.Errorf("DeletePVTZ query old zone records error: endpoint %s %s not found", ep.Rr, ep.Type)
+	}
+	i := p.record(ep.Rr, ep.Type)
+	if i < 0 {
+		return nil
+	}
+	current := &p.state.snapshot.PrivateZoneRecords[i]
+	for _, val := range current.Values {
+		p.state.record(Operation{Action: ActionDelete, Resource: ResourcePrivateZoneRecord, ID: fmt.Sprint(val.RecordId), Name: ep.Rr})
+	}
+	current.Values = nil
+	p.removeEmpty()
+	return nil
+}
+
+func (p *SnapshotPVTZ) record(rr, recordType string) int {
+	for i, e := range p.state.snapshot.PrivateZoneRecords {
+		if e.Rr == rr && (recordType == "" || e.Type == recordType) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *SnapshotPVTZ) removeEmpty() {
+	var records []model.PvtzEndpoint
+	for _, e := range p.state.snapshot.PrivateZoneRecords {
+		if len(e.Values) != 0 {
+			records = append(records, e)
+		}
+	}
+	p.state.snapshot.PrivateZoneRecords = records
+}
+
+func (p *SnapshotPVTZ) count() int {
+	n := 0
+	for _, e := range p.state.snapshot.PrivateZoneRecords {
+		n += len(e.Values)
+	}
+	return n
+}
+
+// nextRecordID returns a record id larger than the ids in use, the record ids are numbers.
+func (p *SnapshotPVTZ) nextRecordID() int64 {
+	var id int64
+	for _, e := range p.state.snapshot.PrivateZoneRecords {
+		for _, v := range e.Values {
+			if v.RecordId > id {
+				id = v.RecordId
+			}
+		}
+	}
+	return id + 1
+}
@@ -0,0 +1,801 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+)
+
+var _ prvd.ILoadBalancer = &SnapshotSLB{}
+
+// SnapshotSLB serves the classic load balancers, their listeners and vserver groups.
+type SnapshotSLB struct {
+	state *state
+}
+
+// clb returns the load balancer in the snapshot, the caller must hold the lock.
+func (m *SnapshotSLB) clb(lbId string) (*model.LoadBalancer, error) {
+	for i := range m.state.snapshot.LoadBalancers {
+		lb := &m.state.snapshot.LoadBalancers[i]
+		if lb.LoadBalancerAttribute.LoadBalancerId == lbId {
+			return lb, nil
+		}
+	}
+	return nil, fmt.Errorf("loadbalancer %s not found in snapshot", lbId)
+}
+
+func (m *SnapshotSLB) FindLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+
+	// 1. find by loadbalancer id
+	if mdl.LoadBalancerAttribute.LoadBalancerId != "" {
+		lb, err := m.clb(mdl.LoadBalancerAttribute.LoadBalancerId)
+		if err != nil {
+			return err
+		}
+		mdl.LoadBalancerAttribute = clone(lb.LoadBalancerAttribute)
+		return nil
+	}
+
+	// 2. find by tags, 3. find by loadbalancer name
+	for _, match := range []func(lb *model.LoadBalancer) bool{
+		func(lb *model.LoadBalancer) bool {
+			return len(mdl.LoadBalancerAttribute.Tags) != 0 &&
+				containsTags(lb.LoadBalancerAttribute.Tags, mdl.LoadBalancerAttribute.Tags)
+		},
+		func(lb *model.LoadBalancer) bool {
+			return mdl.LoadBalancerAttribute.LoadBalancerName != "" &&
+				lb.LoadBalancerAttribute.LoadBalancerName == mdl.LoadBalancerAttribute.LoadBalancerName
+		},
+	} {
+		var found []*model.LoadBalancer
+		for i := range m.state.snapshot.LoadBalancers {
+			if match(&m.state.snapshot.LoadBalancers[i]) {
+				found = append(found, &m.state.snapshot.LoadBalancers[i])
+			}
+		}
+		if len(found) > 1 {
+			var lbIds []string
+			for _, lb := range found {
+				lbIds = append(lbIds, lb.LoadBalancerAttribute.LoadBalancerId)
+			}
+			return fmt.Errorf("[%s] find multiple loadbalances, lbIds[%s]", mdl.NamespacedName, strings.Join(lbIds, ","))
+		}
+		if len(found) == 1 {
+			mdl.LoadBalancerAttribute = clone(found[0].LoadBalancerAttribute)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *SnapshotSLB) CreateLoadBalancer(ctx context.Context, mdl *model.LoadBalancer, clientToken string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	if err := m.state.checkQuota(ResourceCLB, len(m.state.snapshot.LoadBalancers)); err != nil {
+		return err
+	}
+	lb := model.LoadBalancer{
+		NamespacedName:        mdl.NamespacedName,
+		LoadBalancerAttribute: clone(mdl.LoadBalancerAttribute),
+	}
+	lb.LoadBalancerAttribute.LoadBalancerId = m.state.newID(ResourceCLB)
+	lb.LoadBalancerAttribute.LoadBalancerStatus = "active"
+	m.state.snapshot.LoadBalancers = append(m.state.snapshot.LoadBalancers, lb)
+	m.state.record(Operation{
+		Action:   ActionCreate,
+		Resource: ResourceCLB,
+		ID:       lb.LoadBalancerAttribute.LoadBalancerId,
+		Name:     lb.LoadBalancerAttribute.LoadBalancerName,
+		Changes:  fields(mdl.LoadBalancerAttribute, "IsUserManaged", "LoadBalancerName", "PreserveOnDelete"),
+	})
+	mdl.LoadBalancerAttribute.LoadBalancerId = lb.LoadBalancerAttribute.LoadBalancerId
+	return nil
+}
+
+func (m *SnapshotSLB) DescribeLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.clb(mdl.LoadBalancerAttribute.LoadBalancerId)
+	if err != nil {
+		return err
+	}
+	mdl.LoadBalancerAttribute = clone(lb.LoadBalancerAttribute)
+	return nil
+}
+
+func (m *SnapshotSLB) DeleteLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lbId := mdl.LoadBalancerAttribute.LoadBalancerId
+	lb, err := m.clb(lbId)
+	if err != nil {
+		return err
+	}
+	m.state.record(Operation{
+		Action:   ActionDelete,
+		Resource: ResourceCLB,
+		ID:       lbId,
+		Name:     lb.LoadBalancerAttribute.LoadBalancerName,
+	})
+	var lbs []model.LoadBalancer
+	for _, l := range m.state.snapshot.LoadBalancers {
+		if l.LoadBalancerAttribute.LoadBalancerId != lbId {
+			lbs = append(lbs, l)
+		}
+	}
+	m.state.snapshot.LoadBalancers = lbs
+	return nil
+}
+
+// updateAttribute applies the change to the attributes of the load balancer and records it.
+func (m *SnapshotSLB) updateAttribute(lbId string, update func(attr *model.LoadBalancerAttribute)) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.clb(lbId)
+	if err != nil {
+		return err
+	}
+	desired := clone(lb.LoadBalancerAttribute)
+	update(&desired)
+	changes := diff(lb.LoadBalancerAttribute, desired)
+	lb.LoadBalancerAttribute = desired
+	if len(changes) != 0 {
+		m.state.record(Operation{
+			Action:   ActionUpdate,
+			Resource: ResourceCLB,
+			ID:       lbId,
+			Name:     lb.LoadBalancerAttribute.LoadBalancerName,
+			Changes:  changes,
+		})
+	}
+	return nil
+}
+
+func (m *SnapshotSLB) ModifyLoadBalancerInstanceSpec(ctx context.Context, lbId string, spec string) error {
+	return m.updateAttribute(lbId, func(attr *model.LoadBalancerAttribute) {
+		attr.LoadBalancerSpec = model.LoadBalancerSpecType(spec)
+	})
+}
+
+func (m *SnapshotSLB) ModifyLoadBalancerInstanceChargeType(ctx context.Context, lbId string, instanceChargeType string, spec string) error {
+	return m.updateAttribute(lbId, func(attr *model.LoadBalancerAttribute) {
+		attr.InstanceChargeType = model.InstanceChargeType(instanceChargeType)
+		attr.LoadBalancerSpec = model.LoadBalancerSpecType(spec)
+	})
+}
+
+func (m *SnapshotSLB) SetLoadBalancerDeleteProtection(ctx context.Context, lbId string, flag string) error {
+	return m.updateAttribute(lbId, func(attr *model.LoadBalancerAttribute) {
+		attr.DeleteProtection = model.FlagType(flag)
+	})
+}
+
+func (m *SnapshotSLB) SetLoadBalancerName(ctx context.Context, lbId string, name string) error {
+	return m.updateAttribute(lbId, func(attr *model.LoadBalancerAttribute) {
+		attr.LoadBalancerName = name
+	})
+}
+
+func (m *SnapshotSLB) ModifyLoadBalancerInternetSpec(ctx context.Context, lbId string, chargeType string, bandwidth int) error {
+	return m.updateAttribute(lbId, func(attr *model.LoadBalancerAttribute) {
+		attr.InternetChargeType = model.InternetChargeType(chargeType)
+		attr.Bandwidth = bandwidth
+	})
+}
+
+func (m *SnapshotSLB) SetLoadBalancerModificationProtection(ctx context.Context, lbId string, flag string) error {
+	return m.updateAttribute(lbId, func(attr *model.LoadBalancerAttribute) {
+		if flag == string(model.OnFlag) {
+			attr.ModificationProtectionStatus = model.ConsoleProtection
+		} else {
+			attr.ModificationProtectionStatus = model.NonProtection
+		}
+	})
+}
+
+func (m *SnapshotSLB) DescribeLoadBalancerListeners(ctx context.Context, lbId string) ([]model.ListenerAttribute, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.clb(lbId)
+	if err != nil {
+		return nil, err
+	}
+	listeners := clone(lb.Listeners)
+	for i := range listeners {
+		namedKey, err := model.LoadListenerNamedKey(listeners[i].Description)
+		listeners[i].NamedKey = namedKey
+		listeners[i].IsUserManaged = err != nil
+	}
+	return listeners, nil
+}
+
+// listener returns the listener on the port, the caller must hold the lock.
+func (m *SnapshotSLB) listener(lbId string, port int, proto string) (*model.LoadBalancer, int, error) {
+	lb, err := m.clb(lbId)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range lb.Listeners {
+		if lb.Listeners[i].ListenerPort == port && (proto == "" || strings.EqualFold(lb.Listeners[i].Protocol, proto)) {
+			return lb, i, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("listener %s:%d of loadbalancer %s not found in snapshot", proto, port, lbId)
+}
+
+func (m *SnapshotSLB) setListenerStatus(lbId string, port int, proto string, status model.ListenerStatus) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, i, err := m.listener(lbId, port, proto)
+	if err != nil {
+		return err
+	}
+	lis := &lb.Listeners[i]
+	if lis.Status == status {
+		return nil
+	}
+	m.state.record(Operation{
+		Action:         ActionUpdate,
+		Resource:       ResourceCLBListener,
+		ID:             listenerKey(lis.Protocol, lis.ListenerPort),
+		LoadBalancerID: lbId,
+		Changes:        []string{change("Status", lis.Status, status)},
+	})
+	lis.Status = status
+	return nil
+}
+
+func (m *SnapshotSLB) StartLoadBalancerListener(ctx context.Context, lbId string, port int, proto string) error {
+	return m.setListenerStatus(lbId, port, proto, "running")
+}
+
+func (m *SnapshotSLB) StopLoadBalancerListener(ctx context.Context, lbId string, port int, proto string) error {
+	return m.setListenerStatus(lbId, port, proto, model.Stopped)
+}
+
+func (m *SnapshotSLB) DeleteLoadBalancerListener(ctx context.Context, lbId string, port int, proto string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, i, err := m.listener(lbId, port, proto)
+	if err != nil {
+		return err
+	}
+	m.state.record(Operation{
+		Action:         ActionDelete,
+		Resource:       ResourceCLBListener,
+		ID:             listenerKey(lb.Listeners[i].Protocol, port),
+		LoadBalancerID: lbId,
+	})
+	lb.Listeners = append(lb.Listeners[:i], lb.Listeners[i+1:]...)
+	return nil
+}
+
+func (m *SnapshotSLB) createListener(lbId, proto string, listener model.ListenerAttribute) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.clb(lbId)
+	if err != nil {
+		return err
+	}
+	if err := m.state.checkQuota(ResourceCLBListener, len(lb.Listeners)); err != nil {
+		return err
+	}
+	lis := clone(listener)
+	lis.Protocol = proto
+	// listeners are started by the controller after created
+	lis.Status = "running"
+	lb.Listeners = append(lb.Listeners, lis)
+	m.state.record(Operation{
+		Action:         ActionCreate,
+		Resource:       ResourceCLBListener,
+		ID:             listenerKey(proto, listener.ListenerPort),
+		LoadBalancerID: lbId,
+		Changes:        fields(listener, "IsUserManaged", "NamedKey", "ListenerPort", "Protocol", "Status"),
+	})
+	return nil
+}
+
+func (m *SnapshotSLB) setListener(lbId, proto string, listener model.ListenerAttribute) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, i, err := m.listener(lbId, listener.ListenerPort, proto)
+	if err != nil {
+		return err
+	}
+	desired := clone(listener)
+	desired.Protocol = proto
+	desired.Status = lb.Listeners[i].Status
+	changes := diff(lb.Listeners[i], desired, "IsUserManaged", "NamedKey")
+	lb.Listeners[i] = desired
+	if len(changes) != 0 {
+		m.state.record(Operation{
+			Action:         ActionUpdate,
+			Resource:       ResourceCLBListener,
+			ID:             listenerKey(proto, listener.ListenerPort),
+			LoadBalancerID: lbId,
+			Changes:        changes,
+		})
+	}
+	return nil
+}
+
+func (m *SnapshotSLB) CreateLoadBalancerTCPListener(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return m.createListener(lbId, model.TCP, listener)
+}
+
+func (m *SnapshotSLB) SetLoadBalancerTCPListenerAttribute(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return m.setListener(lbId, model.TCP, listener)
+}
+
+func (m *SnapshotSLB) CreateLoadBalancerUDPListener(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return m.createListener(lbId, model.UDP, listener)
+}
+
+func (m *SnapshotSLB) SetLoadBalancerUDPListenerAttribute(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return m.setListener(lbId, model.UDP, listener)
+}
+
+func (m *SnapshotSLB) CreateLoadBalancerHTTPListener(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return m.createListener(lbId, model.HTTP, listener)
+}
+
+func (m *SnapshotSLB) SetLoadBalancerHTTPListenerAttribute(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return m.setListener(lbId, model.HTTP, listener)
+}
+
+func (m *SnapshotSLB) CreateLoadBalancerHTTPSListener(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return m.createListener(lbId, model.HTTPS, listener)
+}
+
+func (m *SnapshotSLB) SetLoadBalancerHTTPSListenerAttribute(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return m.setListener(lbId, model.HTTPS, listener)
+}
+
+func (m *SnapshotSLB) DescribeVServerGroups(ctx context.Context, lbId string) ([]model.VServerGroup, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.clb(lbId)
+	if err != nil {
+		return nil, err
+	}
+	var vgs []model.VServerGroup
+	for _, v := range lb.VServerGroups {
+		vg := model.VServerGroup{
+			VGroupId:   v.VGroupId,
+			VGroupName: v.VGroupName,
+		}
+		namedKey, err := model.LoadVGroupNamedKey(v.VGroupName)
+		vg.NamedKey = namedKey
+		vg.IsUserManaged = err != nil
+		vgs = append(vgs, vg)
+	}
+	return vgs, nil
+}
+
+// vGroup returns the vserver group and its load balancer, the caller must hold the lock.
+func (m *SnapshotSLB) vGroup(vGroupId string) (*model.LoadBalancer, *model.VServerGroup, error) {
+	for i := range m.state.snapshot.LoadBalancers {
+		lb := &m.state.snapshot.LoadBalancers[i]
+		for j := range lb.VServerGroups {
+			if lb.VServerGroups[j].VGroupId == vGroupId {
+				return lb, &lb.VServerGroups[j], nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("vserver group %s not found in snapshot", vGroupId)
+}
+
+func (m *SnapshotSLB) CreateVServerGroup(ctx context.Context, vg *model.VServerGroup, lbId string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.clb(lbId)
+	if err != nil {
+		return err
+	}
+	if err := m.state.checkQuota(ResourceCLBVServerGroup, len(lb.VServerGroups)); err != nil {
+		return err
+	}
+	vg.VGroupId = m.state.newID(ResourceCLBVServerGroup)
+	lb.VServerGroups = append(lb.VServerGroups, model.VServerGroup{VGroupId: vg.VGroupId, VGroupName: vg.VGroupName})
+	m.state.record(Operation{
+		Action:         ActionCreate,
+		Resource:       ResourceCLBVServerGroup,
+		ID:             vg.VGroupId,
+		Name:           vg.VGroupName,
+		LoadBalancerID: lbId,
+	})
+	return nil
+}
+
+func (m *SnapshotSLB) DescribeVServerGroupAttribute(ctx context.Context, vGroupId string) (model.VServerGroup, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	_, vg, err := m.vGroup(vGroupId)
+	if err != nil {
+		return model.VServerGroup{}, err
+	}
+	return model.VServerGroup{
+		VGroupId:   vg.VGroupId,
+		VGroupName: vg.VGroupName,
+		Backends:   clone(vg.Backends),
+	}, nil
+}
+
+func (m *SnapshotSLB) DeleteVServerGroup(ctx context.Context, vGroupId string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, vg, err := m.vGroup(vGroupId)
+	if err != nil {
+		return err
+	}
+	m.state.record(Operation{
+		Action:         ActionDelete,
+		Resource:       ResourceCLBVServerGroup,
+		ID:             vGroupId,
+		Name:           vg.VGroupName,
+		LoadBalancerID: lb.LoadBalancerAttribute.LoadBalancerId,
+	})
+	var vgs []model.VServerGroup
+	for _, v := range lb.VServerGroups {
+		if v.VGroupId != vGroupId {
+			vgs = append(vgs, v)
+		}
+	}
+	lb.VServerGroups = vgs
+	return nil
+}
+
+// updateBackends applies the change to the backends of the vserver group and records it.
+func (m *SnapshotSLB) updateBackends(vGroupId string, backends string,
+	update func(vg *model.VServerGroup, backends []model.BackendAttribute) []string) error {
+	var bs []model.BackendAttribute
+	if err := json.Unmarshal([]byte(backends), &bs); err != nil {
+		return fmt.Errorf("parse backends %s: %s", backends, err.Error())
+	}
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, vg, err := m.vGroup(vGroupId)
+	if err != nil {
+		return err
+	}
+	changes := update(vg, bs)
+	if len(changes) != 0 {
+		m.state.record(Operation{
+			Action:         ActionUpdate,
+			Resource:       ResourceCLBVServerGroup,
+			ID:             vGroupId,
+			Name:           vg.VGroupName,
+			LoadBalancerID: lb.LoadBalancerAttribute.LoadBalancerId,
+			Changes:        changes,
+		})
+	}
+	return nil
+}
+
+func (m *SnapshotSLB) AddVServerGroupBackendServers(ctx context.Context, vGroupId string, backends string) error {
+	return m.updateBackends(vGroupId, backends, addBackends)
+}
+
+func (m *SnapshotSLB) RemoveVServerGroupBackendServers(ctx context.Context, vGroupId string, backends string) error {
+	return m.updateBackends(vGroupId, backends, removeBackends)
+}
+
+func (m *SnapshotSLB) SetVServerGroupAttribute(ctx context.Context, vGroupId string, backends string) error {
+	return m.updateBackends(vGroupId, backends, func(vg *model.VServerGroup, bs []model.BackendAttribute) []string {
+		var changes []string
+		for _, b := range bs {
+			for i := range vg.Backends {
+				old := &vg.Backends[i]
+				if !sameBackend(*old, b) {
+					continue
+				}
+				if old.Weight != b.Weight {
+					changes = append(changes, fmt.Sprintf("backend %s weight: %d -> %d", backendString(b), old.Weight, b.Weight))
+				}
+				if b.Description != "" && old.Description != b.Description {
+					changes = append(changes, fmt.Sprintf("backend %s description: %q -> %q", backendString(b), old.Description, b.Description))
+				}
+				old.Weight, old.Description = b.Weight, b.Description
+			}
+		}
+		return changes
+	})
+}
+
+func (m *SnapshotSLB) ModifyVServerGroupBackendServers(ctx context.Context, vGroupId string, old string, new string) error {
+	if err := m.updateBackends(vGroupId, old, removeBackends); err != nil {
+		return err
+	}
+	return m.updateBackends(vGroupId, new, addBackends)
+}
+
+func addBackends(vg *model.VServerGroup, bs []model.BackendAttribute) []string {
+	var changes []string
+	for _, b := range bs {
+		vg.Backends = append(vg.Backends, b)
+		changes = append(changes, fmt.Sprintf("add backend %s weight %d", backendString(b), b.Weight))
+	}
+	return changes
+}
+
+func removeBackends(vg *model.VServerGroup, bs []model.BackendAttribute) []string {
+	var changes []string
+	var remain []model.BackendAttribute
+	for _, old := range vg.Backends {
+		removed := false
+		for _, b := range bs {
+			if sameBackend(old, b) {
+				removed = true
+				break
+			}
+		}
+		if removed {
+			changes = append(changes, fmt.Sprintf("remove backend %s", backendString(old)))
+			continue
+		}
+		remain = append(remain, old)
+	}
+	vg.Backends = remain
+	return changes
+}
+
+func sameBackend(a, b model.BackendAttribute) bool {
+	if a.ServerId != b.ServerId || a.Port != b.Port {
+		return false
+	}
+	return a.ServerIp == "" || b.ServerIp == "" || a.ServerIp == b.ServerIp
+}
+
+func backendString(b model.BackendAttribute) string {
+	if b.ServerIp != "" && b.ServerIp != b.ServerId {
+		return fmt.Sprintf("%s(%s):%d", b.ServerId, b.ServerIp, b.Port)
+	}
+	return fmt.Sprintf("%s:%d", b.ServerId, b.Port)
+}
+
+func listenerKey(proto string, port int) string {
+	return fmt.Sprintf("%s:%d", strings.ToUpper(proto), port)
+}
+
+func (m *SnapshotSLB) TagCLBResource(ctx context.Context, resourceId string, tags []tag.Tag) error {
+	return m.updateAttribute(resourceId, func(attr *model.LoadBalancerAttribute) {
+		attr.Tags = mergeTags(attr.Tags, tags)
+	})
+}
+
+func (m *SnapshotSLB) UntagResources(ctx context.Context, lbId string, tagKey *[]string) error {
+	if tagKey == nil {
+		return nil
+	}
+	return m.updateAttribute(lbId, func(attr *model.LoadBalancerAttribute) {
+		attr.Tags = removeTags(attr.Tags, *tagKey)
+	})
+}
+
+func (m *SnapshotSLB) ListCLBTagResources(ctx context.Context, lbId string) ([]tag.Tag, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	lb, err := m.clb(lbId)
+	if err != nil {
+		return nil, err
+	}
+	return clone(lb.LoadBalancerAttribute.Tags), nil
+}
+
+func (m *SnapshotSLB) DescribeServerCertificateById(ctx context.Context, serverCertificateId string) (*model.CertAttribute, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	for _, c := range m.state.snapshot.ServerCertificates {
+		if c.ServerCertificateId == serverCertificateId {
+			cert := c
+			return &cert, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *SnapshotSLB) ListServerCertificates(ctx context.Context) ([]model.CertAttribute, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	return clone(m.state.snapshot.ServerCertificates), nil
+}
+
+func (m *SnapshotSLB) UploadServerCertificate(ctx context.Context, certName, cert, key string) (string, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	if err := m.state.checkQuota(ResourceServerCertificate, len(m.state.snapshot.ServerCertificates)); err != nil {
+		return "", err
+	}
+	id := m.state.newID(ResourceServerCertificate)
+	m.state.snapshot.ServerCertificates = append(m.state.snapshot.ServerCertificates, model.CertAttribute{
+		ServerCertificateId:   id,
+		ServerCertificateName: certName,
+	})
+	m.state.record(Operation{Action: ActionCreate, Resource: ResourceServerCertificate, ID: id, Name: certName})
+	return id, nil
+}
+
+func (m *SnapshotSLB) DeleteServerCertificate(ctx context.Context, serverCertificateId string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	var certs []model.CertAttribute
+	for _, c := range m.state.snapshot.ServerCertificates {
+		if c.ServerCertificateId == serverCertificateId {
+			m.state.record(Operation{
+				Action:   ActionDelete,
+				Resource: ResourceServerCertificate,
+				ID:       serverCertificateId,
+				Name:     c.ServerCertificateName,
+			})
+			continue
+		}
+		certs = append(certs, c)
+	}
+	m.state.snapshot.ServerCertificates = certs
+	return nil
+}
+
+// containsTags returns whether all the tags in want are in tags.
+func containsTags(tags, want []tag.Tag) bool {
+	for _, w := range want {
+		found := false
+		for _, t := range tags {
+			if t.Key == w.Key && t.Value == w.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeTags(tags, add []tag.Tag) []tag.Tag {
+	ret := removeTags(tags, tagKeys(add))
+	return append(ret, add...)
+}
+
+func removeTags(tags []tag.Tag, keys []string) []tag.Tag {
+	var ret []tag.Tag
+	for _, t := range tags {
+		if !contains(keys, t.Key) {
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
+
+func tagKeys(tags []tag.Tag) []string {
+	var keys []string
+	for _, t := range tags {
+		keys = append(keys, t.Key)
+	}
+	return keys
+}
+
+func (m *SnapshotSLB) ListCLBAccessControlLists(ctx context.Context, tags []tag.Tag) ([]model.AccessControlList, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	var ret []model.AccessControlList
+	for _, acl := range m.state.snapshot.AccessControlLists {
+		if containsTags(acl.Tags, tags) {
+			ret = append(ret, clone(acl.AccessControlList))
+		}
+	}
+	return ret, nil
+}
+
+func (m *SnapshotSLB) CreateCLBAccessControlList(ctx context.Context, acl *model.AccessControlList) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	if err := m.state.checkQuota(ResourceAcl, len(m.state.snapshot.AccessControlLists)); err != nil {
+		return err
+	}
+	acl.AclId = m.state.newID(ResourceAcl)
+	m.state.snapshot.AccessControlLists = append(m.state.snapshot.AccessControlLists,
+		AccessControlList{AccessControlList: clone(*acl)})
+	m.state.record(Operation{
+		Action:   ActionCreate,
+		Resource: ResourceAcl,
+		ID:       acl.AclId,
+		Name:     acl.AclName,
+		Changes:  fields(acl, "AclId", "AclName"),
+	})
+	return nil
+}
+
+// DeleteCLBAccessControlList fails if the acl is used by a listener, like the OpenAPI.
+func (m *SnapshotSLB) DeleteCLBAccessControlList(ctx context.Context, aclId string) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	for _, lb := range m.state.snapshot.LoadBalancers {
+		for _, lis := range lb.Listeners {
+			if lis.AclId == aclId && lis.AclStatus == model.OnFlag {
+				return fmt.Errorf("AclInUsed: acl %s is used by listener %d of %s",
+					aclId, lis.ListenerPort, lb.LoadBalancerAttribute.LoadBalancerId)
+			}
+		}
+	}
+	var acls []AccessControlList
+	for _, acl := range m.state.snapshot.AccessControlLists {
+		if acl.AclId == aclId {
+			m.state.record(Operation{Action: ActionDelete, Resource: ResourceAcl, ID: aclId, Name: acl.AclName})
+			continue
+		}
+		acls = append(acls, acl)
+	}
+	m.state.snapshot.AccessControlLists = acls
+	return nil
+}
+
+func (m *SnapshotSLB) DescribeCLBAccessControlListEntries(ctx context.Context, aclId string) ([]model.AclEntry, error) {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	acl := m.accessControlList(aclId)
+	if acl == nil {
+		return nil, fmt.Errorf("acl %s not found in snapshot", aclId)
+	}
+	return clone(acl.Entries), nil
+}
+
+func (m *SnapshotSLB) AddCLBAccessControlListEntries(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	acl := m.accessControlList(aclId)
+	if acl == nil {
+		return fmt.Errorf("acl %s not found in snapshot", aclId)
+	}
+	if err := m.state.checkQuota(ResourceAclEntry, len(acl.Entries)+len(entries)-1); err != nil {
+		return err
+	}
+	var changes []string
+	for _, e := range entries {
+		acl.Entries = append(acl.Entries, e)
+		changes = append(changes, fmt.Sprintf("AclEntry: + %q", e.Entry))
+	}
+	m.state.record(Operation{Action: ActionUpdate, Resource: ResourceAcl, ID: aclId, Name: acl.AclName, Changes: changes})
+	return nil
+}
+
+func (m *SnapshotSLB) RemoveCLBAccessControlListEntries(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	m.state.lock.Lock()
+	defer m.state.unlock()
+	acl := m.accessControlList(aclId)
+	if acl == nil {
+		return fmt.Errorf("acl %s not found in snapshot", aclId)
+	}
+	var removed []string
+	for _, e := range entries {
+		removed = append(removed, e.Entry)
+	}
+	var kept []model.AclEntry
+	var changes []string
+	for _, e := range acl.Entries {
+		if contains(removed, e.Entry) {
+			changes = append(changes, fmt.Sprintf("AclEntry: - %q", e.Entry))
+			continue
+		}
+		kept = append(kept, e)
+	}
+	acl.Entries = kept
+	m.state.record(Operation{Action: ActionUpdate, Resource: ResourceAcl, ID: aclId, Name: acl.AclName, Changes: changes})
+	return nil
+}
+
+func (m *SnapshotSLB) accessControlList(aclId string) *AccessControlList {
+	for i := range m.state.snapshot.AccessControlLists {
+		if m.state.snapshot.AccessControlLists[i].AclId == aclId {
+			return &m.state.snapshot.AccessControlLists[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package snapshot
+
+import (
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/sls"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+)
+
+var _ prvd.ISLS = &SnapshotSLS{}
+
+// SnapshotSLS is not supported.
+type SnapshotSLS struct{}
+
+func (s *SnapshotSLS) AnalyzeProductLog(request *sls.AnalyzeProductLogRequest) (*sls.AnalyzeProductLogResponse, error) {
+	return nil, notSupported("AnalyzeProductLog")
+}
@@ -0,0 +1,444 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+)
+
+// Snapshot is the remote state of the cloud resources, it is read by the plan command
+// and the cloud simulator instead of calling the OpenAPIs.
+type Snapshot struct {
+	Region    string `json:"region"`
+	VpcID     string `json:"vpcId"`
+	VswitchID string `json:"vswitchId"`
+	ZoneID    string `json:"zoneId"`
+	ClusterID string `json:"clusterId"`
+
+	VpcCIDRBlocks []string      `json:"vpcCIDRBlocks"`
+	VSwitches     []vpc.VSwitch `json:"vSwitches"`
+	// NetworkInterfaces maps the private ip of the pods to the id of their eni
+	NetworkInterfaces map[string]string `json:"networkInterfaces"`
+
+	LoadBalancers        []model.LoadBalancer           `json:"loadBalancers"`
+	NetworkLoadBalancers []nlbmodel.NetworkLoadBalancer `json:"networkLoadBalancers"`
+	// NLBServerGroups are not bound to a network load balancer, their servers are listed in Servers
+	NLBServerGroups []nlbmodel.ServerGroup `json:"nlbServerGroups"`
+	ALB             ALBSnapshot            `json:"alb"`
+
+	Certificates       []model.CertificateInfo `json:"certificates"`
+	CACertificates     []model.CertificateInfo `json:"caCertificates"`
+	ServerCertificates []model.CertAttribute   `json:"serverCertificates"`
+	// AccessControlLists are the clb access control lists with their entries
+	AccessControlLists []AccessControlList `json:"accessControlLists"`
+	// SecurityGroups are the security groups with their ingress rules
+	SecurityGroups []SecurityGroup `json:"securityGroups"`
+	// EipAddresses are the elastic ip addresses, the ones bound to the zone mappings of the network
+	// load balancers are not associated with an instance
+	EipAddresses []model.EipAddress `json:"eipAddresses"`
+
+	// Instances are the ecs instances of the nodes
+	Instances []prvd.NodeAttribute `json:"instances"`
+	// SourceDestCheck maps the eni id to its source/dest check, which is enabled if not set
+	SourceDestCheck map[string]bool `json:"sourceDestCheck"`
+	// RouteTables maps the route table id to its custom route entries
+	RouteTables map[string][]model.Route `json:"routeTables"`
+	// PrivateZoneRecords are the records of the private zone, one endpoint for each rr and type
+	PrivateZoneRecords []model.PvtzEndpoint `json:"privateZoneRecords"`
+
+	// Quotas limits the count of the resources, see checkQuota for the scope of each resource.
+	// Resources not in Quotas are not limited.
+	Quotas map[string]int `json:"quotas"`
+}
+
+// ALBSnapshot is the remote state of the application load balancers.
+type ALBSnapshot struct {
+	Zones         []albsdk.Zone                      `json:"zones"`
+	LoadBalancers []albmodel.AlbLoadBalancerWithTags `json:"loadBalancers"`
+	Listeners     []albsdk.Listener                  `json:"listeners"`
+	Rules         []albsdk.Rule                      `json:"rules"`
+	ServerGroups  []albmodel.ServerGroupWithTags     `json:"serverGroups"`
+	// ListenerCertificates maps the listener id to its certificate ids, the default one first
+	ListenerCertificates map[string][]string `json:"listenerCertificates"`
+}
+
+// AccessControlList is a clb access control list and its entries.
+type AccessControlList struct {
+	model.AccessControlList
+	Entries []model.AclEntry `json:"entries"`
+}
+
+// SecurityGroup is a security group and its ingress rules.
+type SecurityGroup struct {
+	model.SecurityGroup
+	Rules []model.SecurityGroupRule `json:"rules"`
+}
+
+// LoadSnapshot reads a snapshot from a json file.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot %s: %s", path, err.Error())
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parse snapshot %s: %s", path, err.Error())
+	}
+	return s, nil
+}
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+const (
+	ResourceCLB               = "clb"
+	ResourceCLBListener       = "clb-listener"
+	ResourceCLBVServerGroup   = "clb-vserver-group"
+	ResourceNLB               = "nlb"
+	ResourceNLBListener       = "nlb-listener"
+	ResourceNLBServerGroup    = "nlb-server-group"
+	ResourceALB               = "alb"
+	ResourceALBListener       = "alb-listener"
+	ResourceALBRule           = "alb-rule"
+	ResourceALBServerGroup    = "alb-server-group"
+	ResourceCertificate       = "certificate"
+	ResourceCACertificate     = "ca-certificate"
+	ResourceServerCertificate = "server-certificate"
+	ResourceAcl               = "clb-acl"
+	ResourceAclEntry          = "clb-acl-entry"
+	ResourceSecurityGroup     = "security-group"
+	ResourceSecurityGroupRule = "security-group-rule"
+	ResourceEip               = "eip"
+	ResourceInstance          = "ecs-instance"
+	ResourceNetworkInterface  = "eni"
+	ResourceRoute             = "route-entry"
+	ResourcePrivateZoneRecord = "pvtz-record"
+)
+
+// Operation is a change of a cloud resource the controller would make.
+// The changes of the same resource are merged into one operation.
+type Operation struct {
+	Action         string   `json:"action"`
+	Resource       string   `json:"resource"`
+	ID             string   `json:"id,omitempty"`
+	Name           string   `json:"name,omitempty"`
+	LoadBalancerID string   `json:"loadBalancerId,omitempty"`
+	Changes        []string `json:"changes,omitempty"`
+}
+
+func (o Operation) String() string {
+	var target []string
+	for _, s := range []string{o.Name, o.ID} {
+		if s != "" {
+			target = append(target, s)
+		}
+	}
+	desc := fmt.Sprintf("%s %s %s", o.Action, o.Resource, strings.Join(target, " "))
+	if o.LoadBalancerID != "" {
+		desc = fmt.Sprintf("%s on %s", desc, o.LoadBalancerID)
+	}
+	return desc
+}
+
+func notSupported(api string) error {
+	return fmt.Errorf("%s is not supported in plan", api)
+}
+
+// Option configures the behavior of the snapshot cloud.
+type Option func(s *state)
+
+// WithIDGenerator sets the ids of the created resources, e.g. to generate ids that do not conflict
+// with the resources created in the previous runs.
+func WithIDGenerator(newID func(resource string) string) Option {
+	return func(s *state) {
+		s.idGenerator = newID
+	}
+}
+
+// WithJobDuration sets how long the async jobs of the network load balancers take to finish.
+// The changes are applied when the jobs are created, BatchWaitJobsFinish waits for their duration.
+func WithJobDuration(d time.Duration) Option {
+	return func(s *state) {
+		s.jobDuration = d
+	}
+}
+
+// WithChangeHandler sets the handler of the changes. It is called with the snapshot and the
+// operations whenever a call changed the snapshot, and the operations are not kept for Operations.
+// The handler is called with the state locked, it must not call the cloud.
+func WithChangeHandler(handler func(snapshot *Snapshot, operations []Operation)) Option {
+	return func(s *state) {
+		s.onChange = handler
+	}
+}
+
+// WithInstanceRegistration registers an instance for the node ips that do not belong to any instance,
+// so that the nodes of a local cluster can be initialized.
+func WithInstanceRegistration() Option {
+	return func(s *state) {
+		s.registerInstances = true
+	}
+}
+
+// state is shared by the providers of all products, the model appliers call them in parallel.
+type state struct {
+	lock       sync.Mutex
+	snapshot   *Snapshot
+	ids        map[string]int
+	operations []Operation
+	// jobs maps the id of the async jobs to the time they finish
+	jobs map[string]time.Time
+
+	idGenerator       func(resource string) string
+	jobDuration       time.Duration
+	onChange          func(snapshot *Snapshot, operations []Operation)
+	registerInstances bool
+}
+
+// unlock unlocks the state, and passes the changes made while it was locked to the change handler.
+func (s *state) unlock() {
+	if s.onChange != nil && len(s.operations) != 0 {
+		s.onChange(s.snapshot, s.operations)
+		s.operations = nil
+	}
+	s.lock.Unlock()
+}
+
+// newID returns a placeholder id for the resource to be created.
+func (s *state) newID(resource string) string {
+	if s.idGenerator != nil {
+		return s.idGenerator(resource)
+	}
+	s.ids[resource]++
+	return fmt.Sprintf("planned-%s-%d", resource, s.ids[resource])
+}
+
+// newJob returns the id of an async job, which finishes after the job duration.
+func (s *state) newJob() string {
+	id := s.newID("job")
+	s.jobs[id] = time.Now().Add(s.jobDuration)
+	return id
+}
+
+// checkQuota returns an error if the quota of the resource is used up. The quotas of the listeners
+// and the vserver groups are per load balancer, the quota of the alb rules is per listener, the quota of
+// the route entries is per route table, the quotas of the acl entries and the security group rules are
+// per acl and security group, and the others are per region.
+func (s *state) checkQuota(resource string, used int) error {
+	if quota, ok := s.snapshot.Quotas[resource]; ok && used >= quota {
+		return fmt.Errorf("QuotaExceeded: the quota of %s is %d, %d in use", resource, quota, used)
+	}
+	return nil
+}
+
+// record adds the operation, or merges its changes into the recorded one of the same resource.
+func (s *state) record(op Operation) {
+	if op.Action != ActionDelete && op.ID != "" {
+		for i := range s.operations {
+			r := &s.operations[i]
+			if r.Resource == op.Resource && r.ID == op.ID && r.Name == op.Name && r.Action != ActionDelete {
+				r.Changes = append(r.Changes, op.Changes...)
+				return
+			}
+		}
+	}
+	s.operations = append(s.operations, op)
+}
+
+var _ prvd.Provider = &SnapshotCloud{}
+
+// SnapshotCloud serves the reads from a snapshot, and records the writes as operations
+// while applying them to the snapshot, so that the following reads see them.
+type SnapshotCloud struct {
+	*SnapshotECS
+	*SnapshotPVTZ
+	*SnapshotVPC
+	*SnapshotSLB
+	*SnapshotALB
+	*SnapshotSLS
+	*SnapshotCAS
+	*SnapshotNLB
+	prvd.IMetaData
+
+	state *state
+}
+
+// NewSnapshotCloud returns a provider working on a copy of the snapshot.
+func NewSnapshotCloud(snapshot *Snapshot, opts ...Option) *SnapshotCloud {
+	s := &state{snapshot: clone(snapshot), ids: map[string]int{}, jobs: map[string]time.Time{}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return &SnapshotCloud{
+		IMetaData:    NewSnapshotMetaData(s.snapshot),
+		SnapshotECS:  &SnapshotECS{state: s},
+		SnapshotPVTZ: &SnapshotPVTZ{state: s},
+		SnapshotVPC:  &SnapshotVPC{state: s},
+		SnapshotSLB:  &SnapshotSLB{state: s},
+		SnapshotALB:  &SnapshotALB{state: s},
+		SnapshotSLS:  &SnapshotSLS{},
+		SnapshotCAS:  &SnapshotCAS{state: s},
+		SnapshotNLB:  &SnapshotNLB{state: s},
+		state:        s,
+	}
+}
+
+// Operations returns the operations recorded since the last call.
+func (c *SnapshotCloud) Operations() []Operation {
+	c.state.lock.Lock()
+	defer c.state.unlock()
+	ops := c.state.operations
+	c.state.operations = nil
+	return ops
+}
+
+// clone deep copies the resources, so that the callers can not modify the snapshot.
+func clone[T any](v T) T {
+	var ret T
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("snapshot: marshal %T: %s", v, err.Error()))
+	}
+	if err := json.Unmarshal(data, &ret); err != nil {
+		panic(fmt.Sprintf("snapshot: unmarshal %T: %s", v, err.Error()))
+	}
+	return ret
+}
+
+// fields describes the fields of a resource to be created, zero values and the fields in skip are left out.
+func fields(v interface{}, skip ...string) []string {
+	m := toMap(v)
+	var ret []string
+	for _, k := range sortedKeys(m) {
+		if contains(skip, k) || isZero(m[k]) {
+			continue
+		}
+		ret = append(ret, fmt.Sprintf("%s: %s", k, toJson(prune(m[k]))))
+	}
+	return ret
+}
+
+// diff describes the fields set in the desired resource that differ from the current one,
+// nested objects are compared field by field.
+func diff(current, desired interface{}, skip ...string) []string {
+	return diffMap("", toMap(current), toMap(desired), skip)
+}
+
+func diffMap(prefix string, cm, dm map[string]interface{}, skip []string) []string {
+	var ret []string
+	for _, k := range sortedKeys(dm) {
+		if contains(skip, k) || isZero(dm[k]) || reflect.DeepEqual(cm[k], dm[k]) {
+			continue
+		}
+		cv, cok := cm[k].(map[string]interface{})
+		dv, dok := dm[k].(map[string]interface{})
+		if cok && dok {
+			ret = append(ret, diffMap(prefix+k+".", cv, dv, nil)...)
+			continue
+		}
+		ret = append(ret, change(prefix+k, cm[k], dm[k]))
+	}
+	return ret
+}
+
+func change(field string, from, to interface{}) string {
+	return fmt.Sprintf("%s: %s -> %s", field, toJson(prune(from)), toJson(prune(to)))
+}
+
+// prune removes the zero fields of the nested objects, so that the changes only show the fields set.
+func prune(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for k, i := range t {
+			if p := prune(i); !isZero(p) {
+				m[k] = p
+			}
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, 0, len(t))
+		for _, i := range t {
+			l = append(l, prune(i))
+		}
+		return l
+	}
+	return v
+}
+
+func toMap(v interface{}) map[string]interface{} {
+	m := map[string]interface{}{}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return m
+	}
+	_ = json.Unmarshal(data, &m)
+	return m
+}
+
+func toJson(v interface{}) string {
+	if v == nil {
+		return `""`
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func isZero(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case float64:
+		return t == 0
+	case bool:
+		return !t
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		for _, i := range t {
+			if !isZero(i) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, i := range list {
+		if i == s {
+			return true
+		}
+	}
+	return false
+}
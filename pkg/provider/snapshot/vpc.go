@@ -0,0 +1,448 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+)
+
+var _ prvd.IVPC = &SnapshotVPC{}
+
+// SnapshotVPC serves the vswitches and the cidr blocks of the vpc, the route entries of the route tables,
+// and the elastic ip addresses.
+type SnapshotVPC struct {
+	state *state
+}
+
+func (r *SnapshotVPC) routeTable(table string) ([]model.Route, error) {
+	routes, ok := r.state.snapshot.RouteTables[table]
+	if !ok {
+		return nil, fmt.Errorf("InvalidRouteTableId.NotFound: route table %s not found in snapshot", table)
+	}
+	return routes, nil
+}
+
+// route returns the route entry to the instance of the provider id, the next hop is checked like the OpenAPI.
+func (r *SnapshotVPC) route(providerID, cidr string) (model.Route, error) {
+	_, instance, err := util.NodeFromProviderID(providerID)
+	if err != nil {
+		return model.Route{}, fmt.Errorf("invalid provide id: %v, err: %v", providerID, err)
+	}
+	found := false
+	for _, ins := range r.state.snapshot.Instances {
+		if ins.InstanceID == instance {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return model.Route{}, fmt.Errorf("InvalidNextHopId.NotFound: instance %s not found in snapshot", instance)
+	}
+	pvid := util.ProviderIDFromInstance(r.state.snapshot.Region, instance)
+	return model.Route{
+		Name:            fmt.Sprintf("%s-%s", pvid, cidr),
+		DestinationCIDR: cidr,
+		ProviderId:      pvid,
+	}, nil
+}
+
+// addRoute adds the route entry to the table and returns its id, the failed code is returned on error.
+func (r *SnapshotVPC) addRoute(table string, route model.Route) (string, string, error) {
+	routes, err := r.routeTable(table)
+	if err != nil {
+		return "", "", err
+	}
+	for _, e := range routes {
+		if e.DestinationCIDR == route.DestinationCIDR {
+			return "", "VPC_ROUTE_ENTRY_CIDR_BLOCK_DUPLICATE",
+				fmt.Errorf("InvalidCIDRBlock.Duplicate: the route entry of %s already exists in %s", route.DestinationCIDR, table)
+		}
+	}
+	if err := r.state.checkQuota(ResourceRoute, len(routes)); err != nil {
+		return "", "VPC_ROUTE_ENTRY_STATUS_ERROR", err
+	}
+	id := r.state.newID(ResourceRoute)
+	r.state.snapshot.RouteTables[table] = append(routes, route)
+	r.state.record(Operation{
+		Action:   ActionCreate,
+		Resource: ResourceRoute,
+		ID:       id,
+		Name:     route.Name,
+		Changes:  []string{fmt.Sprintf("RouteTable: %s", table)},
+	})
+	return id, "", nil
+}
+
+// removeRoute removes the route entry from the table, and returns false if it is not found.
+func (r *SnapshotVPC) removeRoute(table string, route model.Route) (bool, error) {
+	routes, err := r.routeTable(table)
+	if err != nil {
+		return false, err
+	}
+	for i, e := range routes {
+		if e.DestinationCIDR == route.DestinationCIDR && e.ProviderId == route.ProviderId && e.NextHopId == route.NextHopId {
+			r.state.record(Operation{Action: ActionDelete, Resource: ResourceRoute, Name: e.Name})
+			r.state.snapshot.RouteTables[table] = append(routes[:i:i], routes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *SnapshotVPC) CreateRoute(ctx context.Context, table string, provideID string, destinationCIDR string) (*model.Route, error) {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	route, err := r.route(provideID, destinationCIDR)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := r.addRoute(table, route); err != nil {
+		return nil, fmt.Errorf("error create route entry for %s, %s, error: %v", provideID, destinationCIDR, err)
+	}
+	return &route, nil
+}
+
+func (r *SnapshotVPC) CreateRoutes(ctx context.Context, table string, routes []*model.Route) ([]string, []prvd.RouteUpdateStatus, error) {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	if _, err := r.routeTable(table); err != nil {
+		return nil, nil, err
+	}
+	var ids []string
+	var statuses []prvd.RouteUpdateStatus
+	for _, route := range routes {
+		created, err := r.route(route.ProviderId, route.DestinationCIDR)
+		if route.NextHopType == model.RouteNextHopTypeNetworkInterface {
+			created, err = model.Route{
+				Name:            fmt.Sprintf("%s-%s", route.NextHopId, route.DestinationCIDR),
+				DestinationCIDR: route.DestinationCIDR,
+				NextHopType:     route.NextHopType,
+				NextHopId:       route.NextHopId,
+			}, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		created.Description = route.Description
+		id, code, err := r.addRoute(table, created)
+		if err != nil {
+			statuses = append(statuses, prvd.RouteUpdateStatus{Route: route, Failed: true, FailedCode: code, FailedMessage: err.Error()})
+			continue
+		}
+		ids = append(ids, id)
+		statuses = append(statuses, prvd.RouteUpdateStatus{Route: route})
+	}
+	return ids, statuses, nil
+}
+
+// DeleteRoute ignores the route entry not found, like the VPC provider.
+func (r *SnapshotVPC) DeleteRoute(ctx context.Context, table, provideID, destinationCIDR string) error {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	_, instance, err := util.NodeFromProviderID(provideID)
+	if err != nil {
+		return fmt.Errorf("invalid provide id: %v, err: %v", provideID, err)
+	}
+	_, err = r.removeRoute(table, model.Route{
+		DestinationCIDR: destinationCIDR,
+		ProviderId:      util.ProviderIDFromInstance(r.state.snapshot.Region, instance),
+	})
+	return err
+}
+
+func (r *SnapshotVPC) DeleteRoutes(ctx context.Context, table string, routes []*model.Route) ([]prvd.RouteUpdateStatus, error) {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	var statuses []prvd.RouteUpdateStatus
+	for _, route := range routes {
+		target := model.Route{DestinationCIDR: route.DestinationCIDR, NextHopId: route.NextHopId}
+		if route.NextHopType != model.RouteNextHopTypeNetworkInterface {
+			_, instance, err := util.NodeFromProviderID(route.ProviderId)
+			if err != nil {
+				return nil, fmt.Errorf("invalid provider id: %v, err: %v", route.ProviderId, err)
+			}
+			target.ProviderId = util.ProviderIDFromInstance(r.state.snapshot.Region, instance)
+		}
+		found, err := r.removeRoute(table, target)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			statuses = append(statuses, prvd.RouteUpdateStatus{
+				Route:         route,
+				Failed:        true,
+				FailedCode:    "VPC_ROUTER_ENTRY_NOT_EXIST",
+				FailedMessage: fmt.Sprintf("the route entry of %s not found in %s", route.DestinationCIDR, table),
+			})
+			continue
+		}
+		statuses = append(statuses, prvd.RouteUpdateStatus{Route: route})
+	}
+	return statuses, nil
+}
+
+func (r *SnapshotVPC) ListRoute(ctx context.Context, table string) ([]*model.Route, error) {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	routes, err := r.routeTable(table)
+	if err != nil {
+		return nil, fmt.Errorf("table %s get route entries error ,err %s", table, err.Error())
+	}
+	var ret []*model.Route
+	for _, e := range routes {
+		route := clone(e)
+		ret = append(ret, &route)
+	}
+	return ret, nil
+}
+
+func (r *SnapshotVPC) FindRoute(ctx context.Context, table, pvid, cidr string) (*model.Route, error) {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	routes, err := r.routeTable(table)
+	if err != nil {
+		return nil, fmt.Errorf("error describe route entry list: %v", err)
+	}
+	instance := ""
+	if pvid != "" {
+		_, instance, err = util.NodeFromProviderID(pvid)
+		if err != nil {
+			return nil, fmt.Errorf("invalid provide id: %v, err: %v", pvid, err)
+		}
+	}
+	for _, e := range routes {
+		if e.NextHopType == model.RouteNextHopTypeNetworkInterface {
+			continue
+		}
+		_, hop, err := util.NodeFromProviderID(e.ProviderId)
+		if err != nil {
+			return nil, err
+		}
+		if (instance == "" || hop == instance) && (cidr == "" || e.DestinationCIDR == cidr) {
+			return &model.Route{DestinationCIDR: e.DestinationCIDR, ProviderId: e.ProviderId}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *SnapshotVPC) ListRouteTables(ctx context.Context, vpcID string) ([]string, error) {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	var tables []string
+	for table := range r.state.snapshot.RouteTables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+// DescribeEipAddresses returns the addresses of the eips associated with the instance.
+func (r *SnapshotVPC) DescribeEipAddresses(ctx context.Context, instanceType string, instanceId string) ([]string, error) {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	var ips []string
+	for _, e := range r.state.snapshot.EipAddresses {
+		if e.InstanceType == instanceType && e.InstanceId == instanceId {
+			ips = append(ips, e.IpAddress)
+		}
+	}
+	return ips, nil
+}
+
+func (r *SnapshotVPC) ListEipAddresses(ctx context.Context, tags []tag.Tag) ([]model.EipAddress, error) {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	var ret []model.EipAddress
+	for _, e := range r.state.snapshot.EipAddresses {
+		if containsTags(e.Tags, tags) {
+			ret = append(ret, clone(e))
+		}
+	}
+	return ret, nil
+}
+
+// AllocateEipAddress allocates the eip with an address of 203.0.113.0/24, which is reserved for documentation.
+func (r *SnapshotVPC) AllocateEipAddress(ctx context.Context, eip *model.EipAddress) error {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	if err := r.state.checkQuota(ResourceEip, len(r.state.snapshot.EipAddresses)); err != nil {
+		return err
+	}
+	eip.AllocationId = r.state.newID(ResourceEip)
+	r.state.ids["eip-address"]++
+	eip.IpAddress = fmt.Sprintf("203.0.113.%d", r.state.ids["eip-address"]%256)
+	r.state.snapshot.EipAddresses = append(r.state.snapshot.EipAddresses, clone(*eip))
+	r.state.record(Operation{
+		Action:   ActionCreate,
+		Resource: ResourceEip,
+		ID:       eip.AllocationId,
+		Name:     eip.Name,
+		Changes:  fields(eip, "AllocationId", "Name"),
+	})
+	return nil
+}
+
+// ReleaseEipAddress fails if the eip is associated with an instance or bound to a network load balancer,
+// like the OpenAPI.
+func (r *SnapshotVPC) ReleaseEipAddress(ctx context.Context, allocationId string) error {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	eip, err := r.eip(allocationId)
+	if err != nil {
+		return err
+	}
+	if eip.InstanceId != "" {
+		return fmt.Errorf("IncorrectEipStatus: eip %s is associated with %s", allocationId, eip.InstanceId)
+	}
+	for _, lb := range r.state.snapshot.NetworkLoadBalancers {
+		if lb.LoadBalancerAttribute == nil {
+			continue
+		}
+		for _, z := range lb.LoadBalancerAttribute.ZoneMappings {
+			if z.AllocationId == allocationId {
+				return fmt.Errorf("IncorrectEipStatus: eip %s is bound to %s", allocationId,
+					lb.LoadBalancerAttribute.LoadBalancerId)
+			}
+		}
+	}
+	var eips []model.EipAddress
+	for _, e := range r.state.snapshot.EipAddresses {
+		if e.AllocationId == allocationId {
+			r.state.record(Operation{Action: ActionDelete, Resource: ResourceEip, ID: allocationId, Name: e.Name})
+			continue
+		}
+		eips = append(eips, e)
+	}
+	r.state.snapshot.EipAddresses = eips
+	return nil
+}
+
+func (r *SnapshotVPC) ModifyEipAddressBandwidth(ctx context.Context, allocationId string, bandwidth int) error {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	eip, err := r.eip(allocationId)
+	if err != nil {
+		return err
+	}
+	r.updateEip(eip, change("Bandwidth", eip.Bandwidth, bandwidth))
+	eip.Bandwidth = bandwidth
+	return nil
+}
+
+func (r *SnapshotVPC) AssociateEipAddress(ctx context.Context, allocationId, instanceType, instanceId string) error {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	eip, err := r.eip(allocationId)
+	if err != nil {
+		return err
+	}
+	if eip.InstanceId != "" {
+		return fmt.Errorf("IncorrectEipStatus: eip %s is associated with %s", allocationId, eip.InstanceId)
+	}
+	r.updateEip(eip, change("InstanceId", "", instanceId))
+	eip.InstanceType, eip.InstanceId = instanceType, instanceId
+	return nil
+}
+
+func (r *SnapshotVPC) UnassociateEipAddress(ctx context.Context, allocationId, instanceType, instanceId string) error {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	eip, err := r.eip(allocationId)
+	if err != nil {
+		return err
+	}
+	if eip.InstanceId != instanceId {
+		return fmt.Errorf("IncorrectEipStatus: eip %s is not associated with %s", allocationId, instanceId)
+	}
+	r.updateEip(eip, change("InstanceId", instanceId, ""))
+	eip.InstanceType, eip.InstanceId = "", ""
+	return nil
+}
+
+func (r *SnapshotVPC) AddCommonBandwidthPackageIp(ctx context.Context, bandwidthPackageId, allocationId string) error {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	eip, err := r.eip(allocationId)
+	if err != nil {
+		return err
+	}
+	if eip.BandwidthPackageId != "" {
+		return fmt.Errorf("BandwidthPackageIp.AlreadyExist: eip %s is in bandwidth package %s",
+			allocationId, eip.BandwidthPackageId)
+	}
+	r.updateEip(eip, change("BandwidthPackageId", "", bandwidthPackageId))
+	eip.BandwidthPackageId = bandwidthPackageId
+	return nil
+}
+
+func (r *SnapshotVPC) RemoveCommonBandwidthPackageIp(ctx context.Context, bandwidthPackageId, allocationId string) error {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	eip, err := r.eip(allocationId)
+	if err != nil {
+		return err
+	}
+	if eip.BandwidthPackageId != bandwidthPackageId {
+		return fmt.Errorf("BandwidthPackageIp.NotFound: eip %s is not in bandwidth package %s",
+			allocationId, bandwidthPackageId)
+	}
+	r.updateEip(eip, change("BandwidthPackageId", bandwidthPackageId, ""))
+	eip.BandwidthPackageId = ""
+	return nil
+}
+
+func (r *SnapshotVPC) eip(allocationId string) (*model.EipAddress, error) {
+	for i := range r.state.snapshot.EipAddresses {
+		if r.state.snapshot.EipAddresses[i].AllocationId == allocationId {
+			return &r.state.snapshot.EipAddresses[i], nil
+		}
+	}
+	return nil, fmt.Errorf("InvalidAllocationId.NotFound: eip %s not found in snapshot", allocationId)
+}
+
+func (r *SnapshotVPC) updateEip(eip *model.EipAddress, changes ...string) {
+	r.state.record(Operation{
+		Action:   ActionUpdate,
+		Resource: ResourceEip,
+		ID:       eip.AllocationId,
+		Name:     eip.Name,
+		Changes:  changes,
+	})
+}
+
+func (r *SnapshotVPC) DescribeVSwitches(ctx context.Context, vpcID string) ([]vpc.VSwitch, error) {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	var ret []vpc.VSwitch
+	for _, v := range r.state.snapshot.VSwitches {
+		if v.VpcId == "" || v.VpcId == vpcID {
+			ret = append(ret, clone(v))
+		}
+	}
+	return ret, nil
+}
+
+func (r *SnapshotVPC) DescribeVpcCIDRBlock(ctx context.Context, vpcId string, ipVersion model.AddressIPVersionType) ([]*net.IPNet, error) {
+	r.state.lock.Lock()
+	defer r.state.unlock()
+	var cidrs []*net.IPNet
+	for _, block := range r.state.snapshot.VpcCIDRBlocks {
+		if strings.Contains(block, ":") != (ipVersion == model.IPv6) {
+			continue
+		}
+		_, cidr, err := net.ParseCIDR(block)
+		if err != nil {
+			return nil, fmt.Errorf("parse vpc cidr block %s: %s", block, err.Error())
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
+}
@@ -3,6 +3,7 @@ package vmock
 import (
 	"context"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
 
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
 )
@@ -24,3 +25,27 @@ func (c MockCAS) DescribeSSLCertificatePublicKeyDetail(ctx context.Context, cert
 func (c MockCAS) DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
 	return nil, nil
 }
+
+func (c MockCAS) DescribeSSLCertificateListByTags(ctx context.Context, tags []tag.Tag) ([]model.CertificateInfo, error) {
+	return nil, nil
+}
+
+func (c MockCAS) UploadSSLCertificate(ctx context.Context, certName, cert, key string, tags []tag.Tag) (string, error) {
+	return "", nil
+}
+
+func (c MockCAS) DeleteSSLCertificate(ctx context.Context, certIdentifier string) error {
+	return nil
+}
+
+func (c MockCAS) UploadCACertificate(ctx context.Context, certName, cert string) (string, error) {
+	return "", nil
+}
+
+func (c MockCAS) DescribeCACertificateList(ctx context.Context, keyword string) ([]model.CertificateInfo, error) {
+	return nil, nil
+}
+
+func (c MockCAS) DeleteCACertificate(ctx context.Context, certIdentifier string) error {
+	return nil
+}
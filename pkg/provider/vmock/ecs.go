@@ -4,6 +4,7 @@ import (
 	"context"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
 )
@@ -84,3 +85,28 @@ func (d *MockECS) DescribeNetworkInterfacesByIDs(ids []string) ([]*prvd.EniAttri
 func (d *MockECS) ModifyNetworkInterfaceSourceDestCheck(id string, enabled bool) error {
 	return nil
 }
+
+func (d *MockECS) ListSecurityGroups(ctx context.Context, tags []tag.Tag) ([]model.SecurityGroup, error) {
+	return nil, nil
+}
+
+func (d *MockECS) CreateSecurityGroup(ctx context.Context, sg *model.SecurityGroup) error {
+	sg.SecurityGroupId = "sg-new-created-id"
+	return nil
+}
+
+func (d *MockECS) DeleteSecurityGroup(ctx context.Context, sgId string) error {
+	return nil
+}
+
+func (d *MockECS) DescribeSecurityGroupRules(ctx context.Context, sgId string) ([]model.SecurityGroupRule, error) {
+	return nil, nil
+}
+
+func (d *MockECS) AuthorizeSecurityGroupRules(ctx context.Context, sgId string, rules []model.SecurityGroupRule) error {
+	return nil
+}
+
+func (d *MockECS) RevokeSecurityGroupRules(ctx context.Context, sgId string, rules []model.SecurityGroupRule) error {
+	return nil
+}
@@ -171,7 +171,7 @@ func (m MockNLB) ListNLBServerGroups(ctx context.Context, tags []tag.Tag) ([]*nl
 			ResourceGroupId:         "rg-id",
 			Scheduler:               "rr",
 			ConnectionDrainEnabled:  tea.Bool(true),
-			ConnectionDrainTimeout:  30,
+			ConnectionDrainTimeout:  tea.Int32(30),
 			PreserveClientIpEnabled: tea.Bool(true),
 			HealthCheckConfig: &nlbmodel.HealthCheckConfig{
 				HealthCheckEnabled:        tea.Bool(true),
@@ -225,7 +225,7 @@ func (m MockNLB) ListNLBServerGroups(ctx context.Context, tags []tag.Tag) ([]*nl
 			ResourceGroupId:         "rg-id",
 			Scheduler:               "rr",
 			ConnectionDrainEnabled:  tea.Bool(true),
-			ConnectionDrainTimeout:  30,
+			ConnectionDrainTimeout:  tea.Int32(30),
 			PreserveClientIpEnabled: tea.Bool(true),
 			HealthCheckConfig: &nlbmodel.HealthCheckConfig{
 				HealthCheckEnabled:        tea.Bool(true),
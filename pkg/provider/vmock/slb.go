@@ -457,3 +457,40 @@ func (m *MockCLB) ModifyVServerGroupBackendServers(ctx context.Context, vGroupId
 func (m *MockCLB) DescribeServerCertificateById(ctx context.Context, serverCertificateId string) (*model.CertAttribute, error) {
 	return nil, nil
 }
+
+func (m *MockCLB) ListServerCertificates(ctx context.Context) ([]model.CertAttribute, error) {
+	return nil, nil
+}
+
+func (m *MockCLB) UploadServerCertificate(ctx context.Context, certName, cert, key string) (string, error) {
+	return "", nil
+}
+
+func (m *MockCLB) DeleteServerCertificate(ctx context.Context, serverCertificateId string) error {
+	return nil
+}
+
+func (m *MockCLB) ListCLBAccessControlLists(ctx context.Context, tags []tag.Tag) ([]model.AccessControlList, error) {
+	return nil, nil
+}
+
+func (m *MockCLB) CreateCLBAccessControlList(ctx context.Context, acl *model.AccessControlList) error {
+	acl.AclId = "acl-new-created-id"
+	return nil
+}
+
+func (m *MockCLB) DeleteCLBAccessControlList(ctx context.Context, aclId string) error {
+	return nil
+}
+
+func (m *MockCLB) DescribeCLBAccessControlListEntries(ctx context.Context, aclId string) ([]model.AclEntry, error) {
+	return nil, nil
+}
+
+func (m *MockCLB) AddCLBAccessControlListEntries(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	return nil
+}
+
+func (m *MockCLB) RemoveCLBAccessControlListEntries(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	servicesvpc "github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/vpc"
@@ -74,3 +75,37 @@ func (m *MockVPC) DescribeVpcCIDRBlock(ctx context.Context, vpcId string, ipVers
 		},
 	}, nil
 }
+
+func (m *MockVPC) ListEipAddresses(ctx context.Context, tags []tag.Tag) ([]model.EipAddress, error) {
+	return nil, nil
+}
+
+func (m *MockVPC) AllocateEipAddress(ctx context.Context, eip *model.EipAddress) error {
+	eip.AllocationId = "eip-new-allocated-id"
+	eip.IpAddress = "47.0.0.10"
+	return nil
+}
+
+func (m *MockVPC) ReleaseEipAddress(ctx context.Context, allocationId string) error {
+	return nil
+}
+
+func (m *MockVPC) ModifyEipAddressBandwidth(ctx context.Context, allocationId string, bandwidth int) error {
+	return nil
+}
+
+func (m *MockVPC) AssociateEipAddress(ctx context.Context, allocationId, instanceType, instanceId string) error {
+	return nil
+}
+
+func (m *MockVPC) UnassociateEipAddress(ctx context.Context, allocationId, instanceType, instanceId string) error {
+	return nil
+}
+
+func (m *MockVPC) AddCommonBandwidthPackageIp(ctx context.Context, bandwidthPackageId, allocationId string) error {
+	return nil
+}
+
+func (m *MockVPC) RemoveCommonBandwidthPackageIp(ctx context.Context, bandwidthPackageId, allocationId string) error {
+	return nil
+}
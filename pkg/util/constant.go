@@ -192,6 +192,15 @@ const (
 	IngressTagKeyPrefix = "ingress.k8s.alibaba"
 )
 
+const (
+	GatewayTagKeyPrefix = "gateway.k8s.alibaba"
+	GatewayFinalizer    = GatewayTagKeyPrefix + "/resources"
+)
+
+const (
+	ServiceALBTagKeyPrefix = "service.k8s.alibaba"
+)
+
 const (
 	DefaultListenerFlag = "-listener-"
 )
@@ -200,6 +209,11 @@ const (
 	RuleActionTypeFixedResponse string = "FixedResponse"
 	RuleActionTypeRedirect      string = "Redirect"
 	RuleActionTypeForward       string = "ForwardGroup"
+	RuleActionTypeInsertHeader  string = "InsertHeader"
+	RuleActionTypeRemoveHeader  string = "RemoveHeader"
+	RuleActionTypeRewrite       string = "Rewrite"
+	RuleActionTypeTrafficMirror string = "TrafficMirror"
+	RuleActionTypeTrafficLimit  string = "TrafficLimit"
 )
 
 const (
@@ -209,6 +223,15 @@ const (
 	RuleConditionFieldQueryString string = "QueryString"
 	RuleConditionFieldMethod      string = "Method"
 	RuleConditionFieldCookie      string = "Cookie"
+	RuleConditionFieldSourceIp    string = "SourceIp"
+
+	RuleConditionFieldResponseHeader     string = "ResponseHeader"
+	RuleConditionFieldResponseStatusCode string = "ResponseStatusCode"
+)
+
+const (
+	RuleDirectionRequest  string = "Request"
+	RuleDirectionResponse string = "Response"
 )
 
 const (
@@ -268,6 +291,7 @@ const (
 
 	ServerGroupProtocolHTTP  = "HTTP"
 	ServerGroupProtocolHTTPS = "HTTPS"
+	ServerGroupProtocolGRPC  = "gRPC"
 
 	ServerGroupHealthCheckMethodGET     = "GET"
 	ServerGroupHealthCheckMethodHEAD    = "HEAD"
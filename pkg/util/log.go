@@ -8,11 +8,13 @@ import (
 var (
 	ServiceLog logr.Logger
 	NLBLog     logr.Logger
+	ALBLog     logr.Logger
 	NodeLog    logr.Logger
 )
 
 func init() {
 	ServiceLog = klogr.New().WithName("service-controller")
 	NLBLog = klogr.New().WithName("nlb-controller")
+	ALBLog = klogr.New().WithName("alb-service-controller")
 	NodeLog = klogr.New().WithName("node-controller")
 }
@@ -43,6 +43,15 @@ var (
 		[]string{"verb"},
 	)
 
+	// NodeLifecycleEvents counts the stopped instances and the spot interruptions found on the nodes
+	NodeLifecycleEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ccm_node_lifecycle_events_total",
+			Help: "CCM node lifecycle events for each type, e.g. shutdown or spot_interruption.",
+		},
+		[]string{"type"},
+	)
+
 	// RouteLatency reconcile route latency
 	RouteLatency = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -53,6 +62,25 @@ var (
 		},
 		[]string{"verb"},
 	)
+
+	// RouteTableEntries the custom route entries pointing to instances in each route table
+	RouteTableEntries = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ccm_route_table_entries",
+			Help: "CCM custom route entries pointing to instances in each route table.",
+		},
+		[]string{"table"},
+	)
+
+	// RouteTableQuotaUsage the ratio of the route entries to the route entry quota of each route table
+	RouteTableQuotaUsage = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ccm_route_table_quota_usage_ratio",
+			Help: "CCM route entries of each route table divided by the route entry quota.",
+		},
+		[]string{"table"},
+	)
+
 	// SLBLatency reconcile SLB latency
 	SLBLatency = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -72,6 +100,34 @@ var (
 		},
 		[]string{"type", "verb", "status"},
 	)
+
+	// CloudAPILatency cloud OpenAPI call latency
+	CloudAPILatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "ccm_cloud_api_latencies_duration_milliseconds",
+			Help: "CCM cloud OpenAPI call latency distribution in milliseconds for each product and action.",
+			Buckets: []float64{10, 50, 100, 200, 300, 400, 500, 600, 700, 800, 900, 1000,
+				1500, 2000, 3000, 4000, 5000, 6000, 7000, 8000, 9000, 10000, 20000, 30000},
+		},
+		[]string{"product", "action"},
+	)
+
+	// CloudAPIRequests counts cloud OpenAPI calls by result and error code
+	CloudAPIRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ccm_cloud_api_requests_total",
+			Help: "CCM cloud OpenAPI calls for each product, action, result and Alibaba Cloud error code.",
+		},
+		[]string{"product", "action", "result", "code"},
+	)
+
+	// TokenExpiration expiration time of the credential used by the sdk clients
+	TokenExpiration = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "ccm_cloud_token_expiration_timestamp_seconds",
+			Help: "Expiration time of the cloud credential in unix seconds, 0 if it does not expire.",
+		},
+	)
 )
 
 // MsSince returns milliseconds since start.
@@ -89,10 +145,27 @@ func UniqueServiceCnt(uid string) float64 {
 	return 1
 }
 
+// ObserveCloudAPI records a cloud OpenAPI call started at start. code is the error code
+// returned by Alibaba Cloud, empty when the call succeeded.
+func ObserveCloudAPI(product, action string, start time.Time, code string) {
+	result := ResultSuccess
+	if code != "" {
+		result = ResultFail
+	}
+	CloudAPILatency.WithLabelValues(product, action).Observe(MsSince(start))
+	CloudAPIRequests.WithLabelValues(product, action, result, code).Inc()
+}
+
 // RegisterPrometheus register metrics to prometheus server
 func RegisterPrometheus() {
 	metrics.Registry.MustRegister(RouteLatency)
+	metrics.Registry.MustRegister(RouteTableEntries)
+	metrics.Registry.MustRegister(RouteTableQuotaUsage)
 	metrics.Registry.MustRegister(NodeLatency)
+	metrics.Registry.MustRegister(NodeLifecycleEvents)
 	metrics.Registry.MustRegister(SLBLatency)
 	metrics.Registry.MustRegister(SLBOperationStatus)
+	metrics.Registry.MustRegister(CloudAPILatency)
+	metrics.Registry.MustRegister(CloudAPIRequests)
+	metrics.Registry.MustRegister(TokenExpiration)
 }
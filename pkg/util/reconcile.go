@@ -2,14 +2,17 @@ package util
 
 import (
 	"errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"time"
 )
 
 var _ error = &ReconcileNeedRequeue{}
 
 type ReconcileNeedRequeue struct {
 	reason string
+	after  time.Duration
 }
 
 func (r *ReconcileNeedRequeue) Error() string {
@@ -22,6 +25,14 @@ func NewReconcileNeedRequeue(reason string) *ReconcileNeedRequeue {
 	}
 }
 
+// NewReconcileNeedRequeueAfter requeues the request after the duration instead of the rate limited delay.
+func NewReconcileNeedRequeueAfter(reason string, after time.Duration) *ReconcileNeedRequeue {
+	return &ReconcileNeedRequeue{
+		reason: reason,
+		after:  after,
+	}
+}
+
 func HandleReconcileResult(request reconcile.Request, err error) (reconcile.Result, error) {
 	if err == nil {
 		return reconcile.Result{}, nil
@@ -30,8 +41,17 @@ func HandleReconcileResult(request reconcile.Request, err error) (reconcile.Resu
 	var needRequeue *ReconcileNeedRequeue
 	if errors.As(err, &needRequeue) {
 		klog.Infof("[%s] requeue for next reconcile: %s", request, needRequeue.reason)
+		if needRequeue.after > 0 {
+			return reconcile.Result{RequeueAfter: needRequeue.after}, nil
+		}
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	if IsThrottlingError(err) {
+		// requeue later instead of retrying right away, which makes the throttling worse
+		klog.Infof("[%s] requeue for next reconcile: throttled by openapi: %s", request, err.Error())
+		return reconcile.Result{RequeueAfter: wait.Jitter(ThrottlingRequeueDelay, 1)}, nil
+	}
+
 	return reconcile.Result{}, err
 }
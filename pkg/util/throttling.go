@@ -0,0 +1,52 @@
+package util
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	sdkerrors "github.com/aliyun/alibaba-cloud-sdk-go/sdk/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// ThrottlingErrorCodePrefix is the prefix of the OpenAPI throttling error codes,
+// e.g. Throttling, Throttling.User, Throttling.Api
+const ThrottlingErrorCodePrefix = "Throttling"
+
+// ThrottlingRequeueDelay is the base delay to requeue a request failed by throttling
+const ThrottlingRequeueDelay = 10 * time.Second
+
+func IsThrottlingCode(code string) bool {
+	return strings.HasPrefix(code, ThrottlingErrorCodePrefix)
+}
+
+func isThrottlingStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+// IsThrottlingError returns whether err is caused by OpenAPI throttling, i.e. the sdk error in its
+// chain has a Throttling code or a 429/503 status. The errors of an aggregate are checked one by one.
+func IsThrottlingError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var teaErr *tea.SDKError
+	if errors.As(err, &teaErr) {
+		return IsThrottlingCode(tea.StringValue(teaErr.Code)) || isThrottlingStatus(tea.IntValue(teaErr.StatusCode))
+	}
+	var serverErr *sdkerrors.ServerError
+	if errors.As(err, &serverErr) {
+		return IsThrottlingCode(serverErr.ErrorCode()) || isThrottlingStatus(serverErr.HttpStatus())
+	}
+	var agg utilerrors.Aggregate
+	if errors.As(err, &agg) {
+		for _, e := range agg.Errors() {
+			if IsThrottlingError(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	networking "k8s.io/api/networking/v1"
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	albconfigmanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/store"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ingressValidator validates the ALB annotations of the Ingresses served by the ALB controller.
+type ingressValidator struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = (*ingressValidator)(nil)
+
+func (v *ingressValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	ing := &networking.Ingress{}
+	if err := v.decoder.Decode(req, ing); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if !ing.DeletionTimestamp.IsZero() || !store.IsValid(ing) {
+		return admission.Allowed("")
+	}
+	warnings, err := albconfigmanager.ValidateIngress(ctx, ing)
+	if err != nil {
+		log.Info("deny ingress", "ingress", fmt.Sprintf("%s/%s", ing.Namespace, ing.Name), "error", err.Error())
+	}
+	return response(warnings, err)
+}
+
+// albConfigValidator validates the AlbConfigs.
+type albConfigValidator struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = (*albConfigValidator)(nil)
+
+func (v *albConfigValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	albconfig := &v1.AlbConfig{}
+	if err := v.decoder.Decode(req, albconfig); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	var oldAlbConfig *v1.AlbConfig
+	if req.Operation == admissionv1.Update {
+		oldAlbConfig = &v1.AlbConfig{}
+		if err := v.decoder.DecodeRaw(req.OldObject, oldAlbConfig); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	warnings, err := albconfigmanager.ValidateAlbConfig(ctx, albconfig, oldAlbConfig)
+	if err != nil {
+		log.Info("deny albconfig", "albconfig", albconfig.Name, "error", err.Error())
+	}
+	return response(warnings, err)
+}
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/clbv1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/nlbv2"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// serviceValidator validates the load balancer annotations of the services served by the CLB and the NLB controllers.
+type serviceValidator struct {
+	client  client.Client
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = (*serviceValidator)(nil)
+
+func (v *serviceValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	svc := &v1.Service{}
+	if err := v.decoder.Decode(req, svc); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	var oldSvc *v1.Service
+	if req.Operation == admissionv1.Update {
+		oldSvc = &v1.Service{}
+		if err := v.decoder.DecodeRaw(req.OldObject, oldSvc); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	warnings, err := v.validate(ctx, svc, oldSvc)
+	if err != nil {
+		log.Info("deny service", "service", fmt.Sprintf("%s/%s", svc.Namespace, svc.Name), "error", err.Error())
+	}
+	return response(warnings, err)
+}
+
+func (v *serviceValidator) validate(ctx context.Context, svc, oldSvc *v1.Service) ([]string, error) {
+	needCLB, needNLB := helper.NeedCLB(svc), helper.NeedNLB(svc)
+	if !needCLB && !needNLB {
+		return nil, nil
+	}
+
+	var warnings []string
+	anno, err := annotation.NewAnnotationRequestWithConfig(ctx, v.client, svc)
+	if err != nil {
+		// the config may be created after the service, the controllers report it until then
+		warnings = append(warnings, fmt.Sprintf("Error loading LoadBalancerConfig: %s", err.Error()))
+		anno = annotation.NewAnnotationRequest(svc)
+	}
+	var oldAnno *annotation.AnnotationRequest
+	if oldSvc != nil {
+		oldAnno, err = annotation.NewAnnotationRequestWithConfig(ctx, v.client, oldSvc)
+		if err != nil {
+			oldAnno = annotation.NewAnnotationRequest(oldSvc)
+		}
+	}
+	reqCtx := &svcCtx.RequestContext{
+		Ctx:     ctx,
+		Service: svc,
+		Anno:    anno,
+		Log:     log,
+	}
+
+	if needCLB {
+		w, err := clbv1.ValidateService(reqCtx, oldAnno)
+		warnings = append(warnings, w...)
+		if err != nil {
+			return warnings, err
+		}
+	}
+	if needNLB {
+		w, err := nlbv2.ValidateService(reqCtx, oldAnno)
+		warnings = append(warnings, w...)
+		if err != nil {
+			return warnings, err
+		}
+	}
+	return warnings, nil
+}
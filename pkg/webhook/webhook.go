@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// The paths of the validating webhooks, see deploy/v2/webhook.yaml
+const (
+	ValidateServicePath   = "/validate-service"
+	ValidateIngressPath   = "/validate-ingress"
+	ValidateAlbConfigPath = "/validate-albconfig"
+)
+
+var log = util.ServiceLog.WithName("webhook")
+
+// AddToManager registers the validating webhooks of the load balancer annotations and the AlbConfigs
+// to the webhook server of the manager, which is started with the manager.
+func AddToManager(mgr manager.Manager) {
+	decoder := admission.NewDecoder(mgr.GetScheme())
+	server := mgr.GetWebhookServer()
+	server.Register(ValidateServicePath, &admission.Webhook{
+		Handler: &serviceValidator{client: mgr.GetClient(), decoder: decoder},
+	})
+	server.Register(ValidateIngressPath, &admission.Webhook{
+		Handler: &ingressValidator{decoder: decoder},
+	})
+	server.Register(ValidateAlbConfigPath, &admission.Webhook{
+		Handler: &albConfigValidator{decoder: decoder},
+	})
+}
+
+// response returns the admission response of the validation result.
+func response(warnings []string, err error) admission.Response {
+	if err != nil {
+		return admission.Denied(err.Error()).WithWarnings(warnings...)
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
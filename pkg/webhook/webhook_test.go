@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func getServiceValidator(t *testing.T) *serviceValidator {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, albv1.SchemeBuilder.AddToScheme(scheme))
+	return &serviceValidator{
+		client:  fake.NewClientBuilder().WithScheme(scheme).Build(),
+		decoder: admission.NewDecoder(scheme),
+	}
+}
+
+func getDefaultService() *v1.Service {
+	return &v1.Service{
+		TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "svc",
+			Namespace:   "default",
+			Annotations: map[string]string{},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:       "tcp",
+					Port:       80,
+					TargetPort: intstr.FromInt(80),
+					Protocol:   v1.ProtocolTCP,
+				},
+			},
+			Type: v1.ServiceTypeLoadBalancer,
+		},
+	}
+}
+
+func getServiceRequest(t *testing.T, svc, oldSvc *v1.Service) admission.Request {
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Create}}
+	raw, err := json.Marshal(svc)
+	assert.NoError(t, err)
+	req.Object.Raw = raw
+	if oldSvc != nil {
+		req.Operation = admissionv1.Update
+		raw, err = json.Marshal(oldSvc)
+		assert.NoError(t, err)
+		req.OldObject.Raw = raw
+	}
+	return req
+}
+
+func TestServiceValidator(t *testing.T) {
+	v := getServiceValidator(t)
+
+	svc := getDefaultService()
+	resp := v.Handle(context.TODO(), getServiceRequest(t, svc, nil))
+	assert.True(t, resp.Allowed)
+
+	svc.Annotations[annotation.Annotation(annotation.HealthCheckInterval)] = "100"
+	resp = v.Handle(context.TODO(), getServiceRequest(t, svc, nil))
+	assert.False(t, resp.Allowed)
+
+	// the services of other types are not validated
+	svc.Spec.Type = v1.ServiceTypeClusterIP
+	resp = v.Handle(context.TODO(), getServiceRequest(t, svc, nil))
+	assert.True(t, resp.Allowed)
+}
+
+func TestServiceValidator_Update(t *testing.T) {
+	v := getServiceValidator(t)
+
+	oldSvc := getDefaultService()
+	oldSvc.Finalizers = []string{helper.ServiceFinalizer}
+	svc := oldSvc.DeepCopy()
+	svc.Annotations[annotation.Annotation(annotation.AddressType)] = "intranet"
+	resp := v.Handle(context.TODO(), getServiceRequest(t, svc, oldSvc))
+	assert.False(t, resp.Allowed)
+
+	svc = oldSvc.DeepCopy()
+	svc.Annotations[annotation.Annotation(annotation.CertID)] = "cert-id"
+	resp = v.Handle(context.TODO(), getServiceRequest(t, svc, oldSvc))
+	assert.True(t, resp.Allowed)
+	assert.Equal(t, 1, len(resp.Warnings))
+}
@@ -1512,7 +1512,7 @@ func buildRemoteModel(f *Framework, svc *v1.Service) (*model.LoadBalancer, error
 	}
 	builder := &clbv1.ModelBuilder{
 		LoadBalancerMgr: clbv1.NewLoadBalancerManager(f.Client.CloudClient),
-		ListenerMgr:     clbv1.NewListenerManager(f.Client.CloudClient),
+		ListenerMgr:     clbv1.NewListenerManager(f.Client.RuntimeClient, f.Client.CloudClient),
 		VGroupMgr:       vma,
 	}
 	reqCtx := &svcCtx.RequestContext{
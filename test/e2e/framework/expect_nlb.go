@@ -432,7 +432,7 @@ func buildNLBRemoteModel(f *Framework, svc *v1.Service) (*nlbmodel.NetworkLoadBa
 	}
 	builder := &nlbv2.ModelBuilder{
 		NLBMgr: nlbv2.NewNLBManager(f.Client.CloudClient),
-		LisMgr: nlbv2.NewListenerManager(f.Client.CloudClient),
+		LisMgr: nlbv2.NewListenerManager(f.Client.RuntimeClient, f.Client.CloudClient),
 		SGMgr:  sgMgr,
 	}
 
@@ -1056,8 +1056,8 @@ func serverGroupAttrEqual(reqCtx *svcCtx.RequestContext, remote *nlbmodel.Server
 			return fmt.Errorf("error convert timeout to int: %s", err.Error())
 		}
 
-		if int32(timeout) != remote.ConnectionDrainTimeout {
-			return fmt.Errorf("expected nlb listener connection drain timeout %d, got %d", timeout, remote.ConnectionDrainTimeout)
+		if int32(timeout) != tea.Int32Value(remote.ConnectionDrainTimeout) {
+			return fmt.Errorf("expected nlb listener connection drain timeout %d, got %d", timeout, tea.Int32Value(remote.ConnectionDrainTimeout))
 		}
 	}
 